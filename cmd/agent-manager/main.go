@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -16,9 +17,24 @@ func main() {
 	// Create root command with all subcommands
 	rootCmd := registry.CreateRootCommand(version)
 
-	// Execute the command
-	if err := rootCmd.Execute(); err != nil {
+	// Execute the command and record the run for monitoring
+	cmd, err := rootCmd.ExecuteC()
+	registry.RecordRun(cmd, err)
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
+
+// exitCode maps an error returned by a command to the process exit code
+func exitCode(err error) int {
+	var exitErr *commands.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	var validationErr *commands.ValidationError
+	if errors.As(err, &validationErr) {
+		return commands.ExitInvalidAgents
+	}
+	return 1
+}
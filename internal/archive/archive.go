@@ -0,0 +1,257 @@
+package archive
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+)
+
+// Store keeps archived agents compressed outside the agents directory
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// Entry describes an archived agent
+type Entry struct {
+	// Agent is the index metadata of the agent, marked archived
+	Agent      *parser.AgentSpec `json:"agent"`
+	Original   string            `json:"original"`
+	Stored     string            `json:"stored"`
+	ArchivedAt time.Time         `json:"archived_at"`
+}
+
+// manifest is the on-disk index of archived agents keyed by original path
+type manifest struct {
+	Entries map[string]*Entry `json:"entries"`
+}
+
+// New creates an archive store rooted at dir
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// DefaultDir returns the archive directory kept next to the tracking file
+func DefaultDir(trackingFile string) string {
+	return filepath.Join(filepath.Dir(trackingFile), "archive")
+}
+
+// Archive compresses the agent's file into the archive area and removes it
+// from the agents directory
+func (s *Store) Archive(agent *parser.AgentSpec) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := filepath.Abs(agent.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", agent.FilePath, err)
+	}
+	if data.Entries[original] != nil {
+		return nil, fmt.Errorf("agent %s is already archived", agent.Name)
+	}
+
+	stored := filepath.Join(s.dir, "agents", fmt.Sprintf("%s-%d.gz", safeName(agent.QualifiedName()), time.Now().UnixNano()))
+	if err := compressFile(original, stored); err != nil {
+		return nil, fmt.Errorf("failed to archive %s: %w", agent.Name, err)
+	}
+
+	metadata := *agent
+	metadata.Prompt = ""
+	metadata.Archived = true
+	entry := &Entry{Agent: &metadata, Original: original, Stored: stored, ArchivedAt: time.Now()}
+	data.Entries[original] = entry
+	if err := s.save(data); err != nil {
+		_ = os.Remove(stored)
+		return nil, err
+	}
+
+	if err := os.Remove(original); err != nil {
+		return nil, fmt.Errorf("archived %s but failed to remove it: %w", agent.Name, err)
+	}
+	return entry, nil
+}
+
+// Restore decompresses an archived agent back to its original location
+func (s *Store) Restore(original string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	entry := data.Entries[original]
+	if entry == nil {
+		return nil, fmt.Errorf("no archived agent at %s", original)
+	}
+	if _, err := os.Stat(original); err == nil {
+		return nil, fmt.Errorf("cannot restore %s: file already exists", original)
+	}
+
+	if err := decompressFile(entry.Stored, original); err != nil {
+		return nil, fmt.Errorf("failed to restore %s: %w", entry.Agent.Name, err)
+	}
+
+	delete(data.Entries, original)
+	if err := s.save(data); err != nil {
+		return nil, err
+	}
+	_ = os.Remove(entry.Stored)
+	return entry, nil
+}
+
+// List returns archived agents sorted by qualified name
+func (s *Store) List() ([]*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0, len(data.Entries))
+	for _, entry := range data.Entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Agent.QualifiedName() < entries[j].Agent.QualifiedName()
+	})
+	return entries, nil
+}
+
+// IsArchived reports whether the file at path has been archived
+func (s *Store) IsArchived(path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	return err == nil && data.Entries[absPath] != nil
+}
+
+// manifestPath returns the path of the archive manifest
+func (s *Store) manifestPath() string {
+	return filepath.Join(s.dir, "archive.json")
+}
+
+func (s *Store) load() (*manifest, error) {
+	data := &manifest{}
+	content, err := os.ReadFile(s.manifestPath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read archive manifest: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(content, data); err != nil {
+			return nil, fmt.Errorf("failed to parse archive manifest: %w", err)
+		}
+	}
+	if data.Entries == nil {
+		data.Entries = make(map[string]*Entry)
+	}
+	return data, nil
+}
+
+func (s *Store) save(data *manifest) error {
+	if err := os.MkdirAll(s.dir, 0750); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive manifest: %w", err)
+	}
+
+	// Write atomically using temp file
+	tempFile := s.manifestPath() + ".tmp"
+	if err := os.WriteFile(tempFile, content, 0600); err != nil {
+		return fmt.Errorf("failed to write archive manifest: %w", err)
+	}
+	if err := os.Rename(tempFile, s.manifestPath()); err != nil {
+		_ = os.Remove(tempFile)
+		return fmt.Errorf("failed to save archive manifest: %w", err)
+	}
+	return nil
+}
+
+// compressFile writes a gzip-compressed copy of src to dst
+func compressFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(out)
+	zw.Name = filepath.Base(src)
+	if _, err := io.Copy(zw, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// decompressFile writes the decompressed contents of the gzip file src to dst
+func decompressFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	zr, err := gzip.NewReader(in)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = zr.Close() }()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, zr); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// safeName converts an agent name into a single path element
+func safeName(name string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(name)
+}
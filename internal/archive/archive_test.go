@@ -0,0 +1,75 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+)
+
+func TestArchiveAndRestore(t *testing.T) {
+	tempDir := t.TempDir()
+	agentPath := filepath.Join(tempDir, "agents", "data", "analyst.md")
+	content := "---\nname: analyst\ndescription: Analyses data\n---\n" + strings.Repeat("Prompt text. ", 200)
+	if err := os.MkdirAll(filepath.Dir(agentPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(agentPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agent := &parser.AgentSpec{
+		Name:        "analyst",
+		Description: "Analyses data",
+		Prompt:      "Prompt text.",
+		FilePath:    agentPath,
+		FileName:    "analyst.md",
+		Namespace:   "data",
+		Source:      "team",
+	}
+
+	store := New(filepath.Join(tempDir, "archive"))
+	entry, err := store.Archive(agent)
+	if err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	if _, err := os.Stat(agentPath); !os.IsNotExist(err) {
+		t.Error("Expected agent file to be removed from the agents directory")
+	}
+	if info, err := os.Stat(entry.Stored); err != nil || info.Size() >= int64(len(content)) {
+		t.Errorf("Expected a compressed copy at %s, got %v", entry.Stored, err)
+	}
+	if !entry.Agent.Archived || entry.Agent.Prompt != "" || entry.Agent.Source != "team" {
+		t.Errorf("Unexpected archived metadata: %+v", entry.Agent)
+	}
+	if !store.IsArchived(agentPath) {
+		t.Error("Expected agent to be reported as archived")
+	}
+	if _, err := store.Archive(agent); err == nil {
+		t.Error("Expected error archiving twice")
+	}
+
+	entries, err := store.List()
+	if err != nil || len(entries) != 1 || entries[0].Agent.QualifiedName() != "data/analyst" {
+		t.Fatalf("List() = %v, %v", entries, err)
+	}
+
+	if _, err := store.Restore(entry.Original); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	restored, err := os.ReadFile(agentPath)
+	if err != nil || string(restored) != content {
+		t.Errorf("Expected original content to be restored, got %v", err)
+	}
+	if store.IsArchived(agentPath) {
+		t.Error("Expected agent to no longer be archived")
+	}
+	if _, err := os.Stat(entry.Stored); !os.IsNotExist(err) {
+		t.Error("Expected compressed copy to be removed after restore")
+	}
+	if _, err := store.Restore(entry.Original); err == nil {
+		t.Error("Expected error restoring an agent that is not archived")
+	}
+}
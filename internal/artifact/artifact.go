@@ -0,0 +1,72 @@
+package artifact
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+)
+
+// IsArtifactFile reports whether a fetched file belongs to the artifact kind;
+// other files of a source, such as READMEs next to statusline scripts, are
+// installed without validation
+func IsArtifactFile(kind, path string, extensions []string) bool {
+	switch kind {
+	case config.KindOutputStyle:
+		return strings.EqualFold(filepath.Ext(path), ".md") && !strings.EqualFold(filepath.Base(path), "README.md")
+	case config.KindStatusline:
+		return !strings.EqualFold(filepath.Ext(path), ".md")
+	default:
+		return parser.IsAgentFile(path, extensions)
+	}
+}
+
+// Validate checks the file at path against the rules of its artifact kind.
+// Agents are not checked here; they have their own validation.
+func Validate(kind, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	switch kind {
+	case config.KindOutputStyle:
+		return validateOutputStyle(content)
+	case config.KindStatusline:
+		return validateStatusline(content)
+	default:
+		return nil
+	}
+}
+
+// validateOutputStyle requires frontmatter with a description, which Claude
+// Code shows when choosing a style, and instructions in the body
+func validateOutputStyle(content []byte) error {
+	style, err := parser.NewParser().ParseContent(content)
+	if err != nil {
+		return fmt.Errorf("invalid output style: %w", err)
+	}
+	if style.Description == "" {
+		return fmt.Errorf("output style has no description")
+	}
+	if style.Prompt == "" {
+		return fmt.Errorf("output style has no instructions")
+	}
+	return nil
+}
+
+// validateStatusline requires an interpreter line, since Claude Code runs the
+// installed script directly
+func validateStatusline(content []byte) error {
+	if len(bytes.TrimSpace(content)) == 0 {
+		return fmt.Errorf("statusline script is empty")
+	}
+	if !bytes.HasPrefix(content, []byte("#!")) {
+		return fmt.Errorf("statusline script has no #! interpreter line")
+	}
+	return nil
+}
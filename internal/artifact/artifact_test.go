@@ -0,0 +1,60 @@
+package artifact
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		content string
+		wantErr bool
+	}{
+		{name: "output style", kind: config.KindOutputStyle, content: "---\nname: Teacher\ndescription: Explains as it works\n---\nExplain each step.\n"},
+		{name: "output style without description", kind: config.KindOutputStyle, content: "---\nname: Teacher\n---\nExplain each step.\n", wantErr: true},
+		{name: "output style without instructions", kind: config.KindOutputStyle, content: "---\ndescription: Empty\n---\n", wantErr: true},
+		{name: "output style without frontmatter", kind: config.KindOutputStyle, content: "Explain each step.\n", wantErr: true},
+		{name: "statusline", kind: config.KindStatusline, content: "#!/bin/sh\necho ok\n"},
+		{name: "statusline without interpreter", kind: config.KindStatusline, content: "echo ok\n", wantErr: true},
+		{name: "empty statusline", kind: config.KindStatusline, content: "\n", wantErr: true},
+		{name: "agents are not checked", kind: config.KindAgent, content: "anything"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "artifact")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := Validate(tt.kind, path); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsArtifactFile(t *testing.T) {
+	tests := []struct {
+		kind string
+		path string
+		want bool
+	}{
+		{config.KindOutputStyle, "styles/teacher.md", true},
+		{config.KindOutputStyle, "README.md", false},
+		{config.KindOutputStyle, "notes.txt", false},
+		{config.KindStatusline, "statusline.sh", true},
+		{config.KindStatusline, "README.md", false},
+		{config.KindAgent, "reviewer.md", true},
+	}
+
+	for _, tt := range tests {
+		if got := IsArtifactFile(tt.kind, tt.path, nil); got != tt.want {
+			t.Errorf("IsArtifactFile(%q, %q) = %v, want %v", tt.kind, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// FormatVersion is the version of the bundle layout written by Write
+	FormatVersion = 1
+	// ManifestName is the name of the manifest entry in a bundle
+	ManifestName = "manifest.json"
+	// agentsPrefix is the directory holding agent files in a bundle
+	agentsPrefix = "agents/"
+	// maxBundleSize caps the bytes read from a bundle, guarding against
+	// archives that expand far beyond their compressed size
+	maxBundleSize = 64 << 20
+)
+
+// Manifest describes the agents in a bundle and where they came from
+type Manifest struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Agents  []Agent   `json:"agents"`
+}
+
+// Agent is one agent in a bundle with its tracking metadata
+type Agent struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Path is the slash-separated path of the file relative to the agents directory
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+	// Source is the installed source the agent was tracked under, if any
+	Source       string    `json:"source,omitempty"`
+	SourceCommit string    `json:"source_commit,omitempty"`
+	InstalledAt  time.Time `json:"installed_at,omitempty"`
+}
+
+// Bundle is a manifest with the content of each agent, keyed by Agent.Path
+type Bundle struct {
+	Manifest Manifest
+	Files    map[string][]byte
+}
+
+// New creates an empty bundle
+func New() *Bundle {
+	return &Bundle{
+		Manifest: Manifest{Version: FormatVersion, Created: time.Now().UTC()},
+		Files:    make(map[string][]byte),
+	}
+}
+
+// Add adds an agent file to the bundle, recording its checksum and size
+func (b *Bundle) Add(agent Agent, content []byte) error {
+	if err := checkPath(agent.Path); err != nil {
+		return err
+	}
+	if _, exists := b.Files[agent.Path]; exists {
+		return fmt.Errorf("bundle already contains %s", agent.Path)
+	}
+	sum := sha256.Sum256(content)
+	agent.SHA256 = hex.EncodeToString(sum[:])
+	agent.Size = int64(len(content))
+	b.Manifest.Agents = append(b.Manifest.Agents, agent)
+	b.Files[agent.Path] = content
+	return nil
+}
+
+// IsZip reports whether filename names a zip bundle rather than a tar.gz one
+func IsZip(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".zip")
+}
+
+// Write writes the bundle to filename as a zip archive when it ends in .zip
+// and as a gzip-compressed tar archive otherwise
+func (b *Bundle) Write(filename string) error {
+	sort.Slice(b.Manifest.Agents, func(i, j int) bool {
+		return b.Manifest.Agents[i].Path < b.Manifest.Agents[j].Path
+	})
+	manifest, err := json.MarshalIndent(b.Manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle manifest: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if IsZip(filename) {
+		err = b.writeZip(&buf, manifest)
+	} else {
+		err = b.writeTarGz(&buf, manifest)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	// Write atomically using temp file
+	tempFile := filename + ".tmp"
+	if err := os.WriteFile(tempFile, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := os.Rename(tempFile, filename); err != nil {
+		_ = os.Remove(tempFile)
+		return fmt.Errorf("failed to save bundle: %w", err)
+	}
+	return nil
+}
+
+func (b *Bundle) writeZip(w io.Writer, manifest []byte) error {
+	zw := zip.NewWriter(w)
+	add := func(name string, content []byte) error {
+		entry, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: b.Manifest.Created})
+		if err != nil {
+			return err
+		}
+		_, err = entry.Write(content)
+		return err
+	}
+
+	if err := add(ManifestName, manifest); err != nil {
+		return err
+	}
+	for _, agent := range b.Manifest.Agents {
+		if err := add(agentsPrefix+agent.Path, b.Files[agent.Path]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (b *Bundle) writeTarGz(w io.Writer, manifest []byte) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	add := func(name string, content []byte) error {
+		header := &tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			ModTime:  b.Manifest.Created,
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(content)
+		return err
+	}
+
+	if err := add(ManifestName, manifest); err != nil {
+		return err
+	}
+	for _, agent := range b.Manifest.Agents {
+		if err := add(agentsPrefix+agent.Path, b.Files[agent.Path]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Read reads and verifies a bundle: the manifest must be of a supported
+// version, every agent path must stay inside the agents directory, and every
+// agent file must be present with the recorded checksum
+func Read(filename string) (*Bundle, error) {
+	entries := make(map[string][]byte)
+	var err error
+	if IsZip(filename) {
+		err = readZip(filename, entries)
+	} else {
+		err = readTarGz(filename, entries)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle %s: %w", filename, err)
+	}
+
+	content, ok := entries[ManifestName]
+	if !ok {
+		return nil, fmt.Errorf("bundle %s has no %s", filename, ManifestName)
+	}
+	b := &Bundle{Files: make(map[string][]byte)}
+	if err := json.Unmarshal(content, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle manifest: %w", err)
+	}
+	if b.Manifest.Version < 1 || b.Manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported bundle format: version %d (supported up to %d)", b.Manifest.Version, FormatVersion)
+	}
+
+	for _, agent := range b.Manifest.Agents {
+		if err := checkPath(agent.Path); err != nil {
+			return nil, err
+		}
+		if _, seen := b.Files[agent.Path]; seen {
+			return nil, fmt.Errorf("bundle lists %s twice", agent.Path)
+		}
+		content, ok := entries[agentsPrefix+agent.Path]
+		if !ok {
+			return nil, fmt.Errorf("bundle is missing %s", agent.Path)
+		}
+		sum := sha256.Sum256(content)
+		if hex.EncodeToString(sum[:]) != strings.ToLower(agent.SHA256) {
+			return nil, fmt.Errorf("checksum mismatch for %s", agent.Path)
+		}
+		b.Files[agent.Path] = content
+	}
+	return b, nil
+}
+
+func readZip(filename string, entries map[string][]byte) error {
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = zr.Close() }()
+
+	var total int64
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+		content, err := readEntry(rc, &total)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+		entries[file.Name] = content
+	}
+	return nil
+}
+
+func readTarGz(filename string, entries map[string][]byte) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = gz.Close() }()
+
+	var total int64
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := readEntry(reader, &total)
+		if err != nil {
+			return err
+		}
+		entries[strings.TrimPrefix(header.Name, "./")] = content
+	}
+}
+
+// readEntry reads one archive entry, failing once the bundle exceeds maxBundleSize
+func readEntry(r io.Reader, total *int64) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(r, maxBundleSize-*total+1))
+	if err != nil {
+		return nil, err
+	}
+	*total += int64(len(content))
+	if *total > maxBundleSize {
+		return nil, fmt.Errorf("bundle exceeds %d MB", maxBundleSize>>20)
+	}
+	return content, nil
+}
+
+// checkPath rejects agent paths that are empty, absolute or escape the agents directory
+func checkPath(name string) error {
+	if name == "" || strings.Contains(name, "\\") || path.IsAbs(name) || path.Clean(name) != name ||
+		name == ".." || strings.HasPrefix(name, "../") {
+		return fmt.Errorf("invalid agent path in bundle: %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package bundle
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteAndRead(t *testing.T) {
+	for _, name := range []string{"agents.tar.gz", "agents.zip"} {
+		t.Run(name, func(t *testing.T) {
+			b := New()
+			reviewer := "---\nname: reviewer\ndescription: Reviews code\n---\nReview the change.\n"
+			if err := b.Add(Agent{Name: "reviewer", Namespace: "team", Path: "team/reviewer.md", Source: "team", SourceCommit: "abc123"}, []byte(reviewer)); err != nil {
+				t.Fatal(err)
+			}
+			if err := b.Add(Agent{Name: "helper", Path: "helper.md"}, []byte("---\nname: helper\n---\nHelp.\n")); err != nil {
+				t.Fatal(err)
+			}
+			if err := b.Add(Agent{Name: "helper", Path: "helper.md"}, nil); err == nil {
+				t.Error("Expected error adding the same path twice")
+			}
+
+			path := filepath.Join(t.TempDir(), name)
+			if err := b.Write(path); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+
+			read, err := Read(path)
+			if err != nil {
+				t.Fatalf("Read failed: %v", err)
+			}
+			if len(read.Manifest.Agents) != 2 || read.Manifest.Agents[0].Path != "helper.md" {
+				t.Fatalf("Unexpected manifest: %+v", read.Manifest)
+			}
+			agent := read.Manifest.Agents[1]
+			if agent.Source != "team" || agent.SourceCommit != "abc123" || agent.Size != int64(len(reviewer)) || len(agent.SHA256) != 64 {
+				t.Errorf("Unexpected agent metadata: %+v", agent)
+			}
+			if string(read.Files["team/reviewer.md"]) != reviewer {
+				t.Errorf("Expected agent content to round trip, got %q", read.Files["team/reviewer.md"])
+			}
+		})
+	}
+}
+
+// writeZip writes a zip bundle with the given manifest and entries
+func writeZip(t *testing.T, path string, manifest Manifest, files map[string]string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := zip.NewWriter(file)
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files[ManifestName] = string(content)
+	for name, content := range files {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadRejectsBadBundles(t *testing.T) {
+	sum := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" // sha256 of "hello"
+	testCases := []struct {
+		name     string
+		manifest Manifest
+		files    map[string]string
+		want     string
+	}{
+		{"version", Manifest{Version: FormatVersion + 1}, map[string]string{}, "unsupported bundle format"},
+		{"escaping", Manifest{Version: 1, Agents: []Agent{{Path: "../evil.md", SHA256: sum}}}, map[string]string{"agents/../evil.md": "hello"}, "invalid agent path"},
+		{"absolute", Manifest{Version: 1, Agents: []Agent{{Path: "/etc/evil.md", SHA256: sum}}}, map[string]string{}, "invalid agent path"},
+		{"missing", Manifest{Version: 1, Agents: []Agent{{Path: "a.md", SHA256: sum}}}, map[string]string{}, "missing a.md"},
+		{"tampered", Manifest{Version: 1, Agents: []Agent{{Path: "a.md", SHA256: sum}}}, map[string]string{"agents/a.md": "goodbye"}, "checksum mismatch"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bundle.zip")
+			writeZip(t, path, tc.manifest, tc.files)
+			if _, err := Read(path); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	if err := os.WriteFile(path, []byte("not a bundle"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(path); err == nil {
+		t.Error("Expected error reading a file that is not an archive")
+	}
+}
@@ -0,0 +1,181 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+// ApplyCommand implements reconciling the installed agents with the configuration
+type ApplyCommand struct {
+	prune   bool
+	timeout time.Duration
+}
+
+// applyCounts tallies what an apply run did to each source
+type applyCounts struct {
+	installed, updated, removed, unchanged, failed int
+}
+
+// NewApplyCommand creates a new apply command instance
+func NewApplyCommand() *ApplyCommand {
+	return &ApplyCommand{}
+}
+
+// Name returns the command name
+func (c *ApplyCommand) Name() string {
+	return "apply"
+}
+
+// Description returns the command description
+func (c *ApplyCommand) Description() string {
+	return "Reconcile installed agents with the configuration"
+}
+
+// CreateCommand creates the cobra command for apply functionality
+func (c *ApplyCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: c.Description(),
+		Long: `Make the installed agents match the configuration in one pass.
+
+Every enabled source is planned against what is installed: sources that are not
+installed yet are installed, and sources whose files differ from what they
+would install now are reinstalled. A source drifts when upstream changed, when
+its configuration changed, or when installed files were edited or deleted.
+Sources that already match are left alone, so running apply again makes no
+changes.
+
+With --prune, or settings.prune in the configuration, installed sources that
+are no longer configured or are disabled are uninstalled first. Without it they
+are only reported.
+
+Use --dry-run to see what apply would do, or the plan command for a per-file diff.
+
+Examples:
+  agent-manager apply
+  agent-manager apply --prune
+  agent-manager apply --config team-agents.yaml --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return silenceExitError(cmd, c.Execute(sharedCtx))
+		},
+	}
+
+	cmd.Flags().BoolVar(&c.prune, "prune", false, "uninstall installed sources that are no longer configured")
+	AddTimeoutFlag(cmd, &c.timeout)
+
+	return cmd
+}
+
+// Execute runs the apply command logic
+func (c *ApplyCommand) Execute(sharedCtx *SharedContext) error {
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	cancel := sharedCtx.WithTimeout(c.timeout)
+	defer cancel()
+
+	sources, err := sharedCtx.FilterEnabledSources("")
+	if err != nil {
+		return err
+	}
+	if len(sources) == 0 {
+		PrintWarning("No enabled sources found in configuration")
+	}
+	removed, err := removedSources(sharedCtx, sources)
+	if err != nil {
+		return err
+	}
+
+	inst, err := sharedCtx.CreateInstaller()
+	if err != nil {
+		return fmt.Errorf("failed to create installer: %w", err)
+	}
+
+	var counts applyCounts
+	var failed []string
+	defer c.summarize(sharedCtx, &counts)
+	continueOnError := sharedCtx.Config.Settings.ContinueOnError
+
+	// Prune first, so a source renamed in the configuration does not have its
+	// newly installed files removed along with the old name's
+	if c.prune || sharedCtx.Config.Settings.Prune {
+		for _, plan := range removed {
+			if err := sharedCtx.Context().Err(); err != nil {
+				return fmt.Errorf("apply aborted: %w", err)
+			}
+			if err := inst.UninstallSource(plan.Source); err != nil {
+				PrintError("Failed to uninstall %s: %v", plan.Source, err)
+				counts.failed++
+				failed = append(failed, plan.Source)
+				if !continueOnError {
+					return err
+				}
+				continue
+			}
+			counts.removed++
+		}
+	} else if len(removed) > 0 {
+		names := make([]string, 0, len(removed))
+		for _, plan := range removed {
+			names = append(names, plan.Source)
+		}
+		PrintInfo("%d installed sources are no longer configured: %v (re-run with --prune to uninstall them)", len(names), names)
+	}
+
+	for _, source := range sources {
+		if err := sharedCtx.Context().Err(); err != nil {
+			return fmt.Errorf("apply aborted: %w", err)
+		}
+		plan, err := inst.ApplySource(sharedCtx.Context(), source)
+		if err != nil {
+			PrintError("Failed to apply %s: %v", source.Name, err)
+			counts.failed++
+			failed = append(failed, source.Name)
+			if !continueOnError {
+				return err
+			}
+			continue
+		}
+		switch plan.Action {
+		case installer.PlanAdded:
+			counts.installed++
+		case installer.PlanChanged:
+			counts.updated++
+		default:
+			counts.unchanged++
+		}
+	}
+
+	warmIndex(sharedCtx, inst.InstalledAgents())
+	printConflictReport(os.Stdout, inst.Conflicts())
+
+	verb := "Applied"
+	if sharedCtx.Options.DryRun {
+		verb = "Would apply"
+	}
+	fmt.Printf("\n%s: %d installed, %d updated, %d removed, %d unchanged\n",
+		verb, counts.installed, counts.updated, counts.removed, counts.unchanged)
+
+	if len(failed) > 0 {
+		err := fmt.Errorf("failed to apply %d sources: %v", len(failed), failed)
+		if counts.installed+counts.updated+counts.removed+counts.unchanged > 0 {
+			return &ExitError{Code: ExitPartial, Err: err}
+		}
+		return err
+	}
+	return nil
+}
+
+// summarize records what apply did for the quiet-mode summary
+func (c *ApplyCommand) summarize(sharedCtx *SharedContext, counts *applyCounts) {
+	sharedCtx.Summarize("installed", counts.installed)
+	sharedCtx.Summarize("updated", counts.updated)
+	sharedCtx.Summarize("removed", counts.removed)
+	sharedCtx.Summarize("unchanged", counts.unchanged)
+	sharedCtx.Summarize("failed", counts.failed)
+}
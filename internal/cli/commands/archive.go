@@ -0,0 +1,254 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/archive"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// ArchiveCommand implements moving agents into compressed cold storage
+type ArchiveCommand struct {
+	list bool
+}
+
+// NewArchiveCommand creates a new archive command instance
+func NewArchiveCommand() *ArchiveCommand {
+	return &ArchiveCommand{}
+}
+
+// Name returns the command name
+func (c *ArchiveCommand) Name() string {
+	return "archive"
+}
+
+// Description returns the command description
+func (c *ArchiveCommand) Description() string {
+	return "Move agents matching a query into the archive"
+}
+
+// CreateCommand creates the cobra command for archive functionality
+func (c *ArchiveCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive QUERY",
+		Short: c.Description(),
+		Long: `Compress the agents matching a query into an archive area next to the tracking
+file, outside the directory Claude Code scans, and remove them from the agents
+directory. Their metadata is kept in the archive, marked archived, and updates
+do not reinstall them. Use unarchive to bring them back.
+
+Examples:
+  agent-manager archive "source:legacy-agents"
+  agent-manager archive "tools:WebSearch research" --dry-run
+  agent-manager archive --list`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if c.list {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.list {
+				return listArchived(sharedCtx)
+			}
+			return c.Execute(sharedCtx, args[0])
+		},
+	}
+
+	cmd.Flags().BoolVar(&c.list, "list", false, "list archived agents")
+
+	return cmd
+}
+
+// Execute runs the archive command logic
+func (c *ArchiveCommand) Execute(sharedCtx *SharedContext, query string) error {
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	queryEngine, err := sharedCtx.CreateQueryEngine()
+	if err != nil {
+		return err
+	}
+	agents, err := selectAgents(queryEngine, query)
+	if err != nil {
+		return err
+	}
+	if len(agents) == 0 {
+		PrintWarning("No agents match %q", query)
+		return nil
+	}
+
+	if sharedCtx.Options.DryRun {
+		for _, agent := range agents {
+			fmt.Printf("  %s (%s)\n", agent.QualifiedName(), agent.FilePath)
+		}
+		color.Yellow("[DRY RUN] Would archive %d agents\n", len(agents))
+		return nil
+	}
+
+	store := archive.New(archive.DefaultDir(sharedCtx.Config.Metadata.TrackingFile))
+	archived := 0
+	for _, agent := range agents {
+		if _, err := store.Archive(agent); err != nil {
+			PrintError("%v", err)
+			continue
+		}
+		archived++
+		if sharedCtx.Options.Verbose {
+			fmt.Printf("Archived: %s\n", agent.FilePath)
+		}
+	}
+
+	refreshIndex(sharedCtx)
+
+	PrintSuccess("Archived %d agents", archived)
+	if archived < len(agents) {
+		return fmt.Errorf("failed to archive %d agents", len(agents)-archived)
+	}
+	return nil
+}
+
+// UnarchiveCommand implements restoring archived agents
+type UnarchiveCommand struct {
+	all bool
+}
+
+// NewUnarchiveCommand creates a new unarchive command instance
+func NewUnarchiveCommand() *UnarchiveCommand {
+	return &UnarchiveCommand{}
+}
+
+// Name returns the command name
+func (c *UnarchiveCommand) Name() string {
+	return "unarchive"
+}
+
+// Description returns the command description
+func (c *UnarchiveCommand) Description() string {
+	return "Restore archived agents to the agents directory"
+}
+
+// CreateCommand creates the cobra command for unarchive functionality
+func (c *UnarchiveCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unarchive [AGENT...]",
+		Short: c.Description(),
+		Long: `Restore archived agents, given by name, namespaced name or file name, to the
+location they were archived from.
+
+Examples:
+  agent-manager unarchive data-scientist
+  agent-manager unarchive --all`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !c.all && len(args) == 0 {
+				return fmt.Errorf("specify agents to restore or use --all")
+			}
+			return c.Execute(sharedCtx, args)
+		},
+	}
+
+	cmd.Flags().BoolVar(&c.all, "all", false, "restore every archived agent")
+
+	return cmd
+}
+
+// Execute runs the unarchive command logic
+func (c *UnarchiveCommand) Execute(sharedCtx *SharedContext, names []string) error {
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	store := archive.New(archive.DefaultDir(sharedCtx.Config.Metadata.TrackingFile))
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	selected, err := selectArchived(entries, names, c.all)
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
+		PrintWarning("No archived agents")
+		return nil
+	}
+
+	if sharedCtx.Options.DryRun {
+		for _, entry := range selected {
+			fmt.Printf("  %s -> %s\n", entry.Agent.QualifiedName(), entry.Original)
+		}
+		color.Yellow("[DRY RUN] Would restore %d agents\n", len(selected))
+		return nil
+	}
+
+	restored := 0
+	for _, entry := range selected {
+		if _, err := store.Restore(entry.Original); err != nil {
+			PrintError("%v", err)
+			continue
+		}
+		restored++
+	}
+
+	refreshIndex(sharedCtx)
+
+	PrintSuccess("Restored %d agents", restored)
+	if restored < len(selected) {
+		return fmt.Errorf("failed to restore %d agents", len(selected)-restored)
+	}
+	return nil
+}
+
+// selectArchived picks the archived entries named by name, qualified name or
+// file name, or every entry when all is set
+func selectArchived(entries []*archive.Entry, names []string, all bool) ([]*archive.Entry, error) {
+	if all {
+		return entries, nil
+	}
+
+	var selected []*archive.Entry
+	for _, name := range names {
+		var matches []*archive.Entry
+		for _, entry := range entries {
+			if entry.Agent.Name == name || entry.Agent.QualifiedName() == name || entry.Agent.FileName == name {
+				matches = append(matches, entry)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("no archived agent named %s", name)
+		case 1:
+			selected = append(selected, matches[0])
+		default:
+			return nil, fmt.Errorf("%s matches %d archived agents; use the namespaced name", name, len(matches))
+		}
+	}
+	return selected, nil
+}
+
+// listArchived prints the archived agents with their metadata
+func listArchived(sharedCtx *SharedContext) error {
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	entries, err := archive.New(archive.DefaultDir(sharedCtx.Config.Metadata.TrackingFile)).List()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		PrintInfo("No archived agents")
+		return nil
+	}
+
+	color.Blue("Archived agents (%d):\n", len(entries))
+	for _, entry := range entries {
+		fmt.Printf("  %s - %s\n", entry.Agent.QualifiedName(), entry.Agent.Description)
+		fmt.Printf("    Source: %s | Archived: %s | From: %s\n",
+			entry.Agent.Source, util.FormatTime(entry.ArchivedAt), entry.Original)
+	}
+	return nil
+}
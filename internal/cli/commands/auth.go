@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/credentials"
+	"github.com/pacphi/claude-code-agent-manager/internal/installer"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// AuthCommand implements storing source tokens in the OS keychain
+type AuthCommand struct {
+	action string
+}
+
+// NewAuthCommand creates a new auth command instance
+func NewAuthCommand() *AuthCommand {
+	return &AuthCommand{}
+}
+
+// Name returns the command name
+func (c *AuthCommand) Name() string {
+	return "auth"
+}
+
+// Description returns the command description
+func (c *AuthCommand) Description() string {
+	return "Store source tokens in the OS keychain or a git credential helper"
+}
+
+// CreateCommand creates the cobra command for auth functionality
+func (c *AuthCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth <login|logout|status> SOURCE",
+		Short: c.Description(),
+		Long: `Manage the token of a source configured with 'auth.method: keychain'. The
+token is kept in the OS keychain (macOS Keychain, Windows Credential Manager
+or libsecret on Linux) or, with 'auth.helper: git', in git's configured
+credential helpers, so it never has to be stored in an environment variable.
+
+login prompts for the token without echoing it, or reads it from stdin when
+stdin is not a terminal.
+
+Examples:
+  agent-manager auth login private-agents
+  echo "$TOKEN" | agent-manager auth login private-agents
+  agent-manager auth status private-agents
+  agent-manager auth logout private-agents`,
+		Args:      cobra.ExactArgs(2),
+		ValidArgs: []string{"login", "logout", "status"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c.action = args[0]
+			return c.Execute(sharedCtx, args[1])
+		},
+	}
+
+	return cmd
+}
+
+// Execute runs the auth command logic
+func (c *AuthCommand) Execute(sharedCtx *SharedContext, sourceName string) error {
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	source, err := sharedCtx.GetSourceByName(sourceName)
+	if err != nil {
+		return err
+	}
+	if source.Auth.Method != "keychain" {
+		return fmt.Errorf("source %s does not use keychain auth (set auth.method: keychain)", source.Name)
+	}
+
+	store, err := credentials.New(source.Auth.Helper, installer.SourceURL(*source))
+	if err != nil {
+		return err
+	}
+	account := source.Auth.CredentialName(source.Name)
+	sharedCtx.Summarize("source", source.Name)
+
+	switch c.action {
+	case "login":
+		token, err := readToken(source)
+		if err != nil {
+			return err
+		}
+		if err := store.Set(sharedCtx.Context(), account, token); err != nil {
+			return fmt.Errorf("failed to store token for %s: %w", source.Name, err)
+		}
+		sharedCtx.Summarize("stored", true)
+		PrintSuccess("Stored token for %s", source.Name)
+	case "logout":
+		err := store.Delete(sharedCtx.Context(), account)
+		if err != nil && !errors.Is(err, credentials.ErrNotFound) {
+			return fmt.Errorf("failed to remove token for %s: %w", source.Name, err)
+		}
+		sharedCtx.Summarize("removed", err == nil)
+		PrintSuccess("Removed token for %s", source.Name)
+	case "status":
+		_, err := store.Get(sharedCtx.Context(), account)
+		if err != nil && !errors.Is(err, credentials.ErrNotFound) {
+			return fmt.Errorf("failed to read token for %s: %w", source.Name, err)
+		}
+		sharedCtx.Summarize("stored", err == nil)
+		if err != nil {
+			color.Yellow("No token stored for %s\n", source.Name)
+		} else {
+			fmt.Printf("Token stored for %s\n", source.Name)
+		}
+	default:
+		return fmt.Errorf("unknown auth action: %s", c.action)
+	}
+	return nil
+}
+
+// readToken prompts for a token without echo, or reads the first line of stdin
+// when it is not a terminal
+func readToken(source *config.Source) (string, error) {
+	var token string
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		fmt.Printf("Token for %s: ", source.Name)
+		secret, err := term.ReadPassword(fd)
+		fmt.Println()
+		if err != nil {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		token = string(secret)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read token from stdin: %w", err)
+		}
+		token = line
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("no token provided")
+	}
+	return token, nil
+}
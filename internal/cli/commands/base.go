@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/pacphi/claude-code-agent-manager/internal/config"
@@ -26,6 +27,9 @@ type CommandExecutor interface {
 // BaseCommand provides shared execution patterns for commands
 type BaseCommand struct {
 	executor CommandExecutor
+	timeout  time.Duration
+	yes      bool // skip confirming the sources a --source pattern matches
+	failFast bool // stop at the first failing source
 }
 
 // NewBaseCommand creates a new base command with the specified executor
@@ -42,6 +46,9 @@ func (bc *BaseCommand) ExecuteWithCommonPattern(sharedCtx *SharedContext, source
 		return fmt.Errorf("configuration error: %w", err)
 	}
 
+	cancel := sharedCtx.WithTimeout(bc.timeout)
+	defer cancel()
+
 	// Get sources to process
 	sources, err := sharedCtx.FilterEnabledSources(sourceName)
 	if err != nil {
@@ -53,6 +60,17 @@ func (bc *BaseCommand) ExecuteWithCommonPattern(sharedCtx *SharedContext, source
 		return err
 	}
 
+	// Sources selected by pattern are listed and confirmed first
+	if isSourcePattern(sourceName) && len(sources) > 0 {
+		names := make([]string, len(sources))
+		for i, source := range sources {
+			names[i] = source.Name
+		}
+		if err := sharedCtx.confirmSourceSelection(sourceName, names, bc.yes); err != nil {
+			return err
+		}
+	}
+
 	// Execute operation on sources
 	return bc.executeOnSources(sharedCtx, sources)
 }
@@ -60,6 +78,9 @@ func (bc *BaseCommand) ExecuteWithCommonPattern(sharedCtx *SharedContext, source
 // validateSources validates that we have sources to process
 func (bc *BaseCommand) validateSources(sources []config.Source, sourceName string) error {
 	if len(sources) == 0 {
+		if isSourcePattern(sourceName) {
+			return fmt.Errorf("no enabled source matches '%s'", sourceName)
+		}
 		if sourceName != "" {
 			return fmt.Errorf("source '%s' is not enabled or not found", sourceName)
 		}
@@ -69,52 +90,90 @@ func (bc *BaseCommand) validateSources(sources []config.Source, sourceName strin
 	return nil
 }
 
-// executeOnSources executes the operation on all provided sources
+// sourceFailure is a source whose operation failed, kept for the final breakdown
+type sourceFailure struct {
+	source string
+	err    error
+}
+
+// executeOnSources executes the operation on all provided sources. A failing
+// source does not stop the others unless the executor says so; the run then
+// ends with a breakdown of the failures and an ExitPartial error when other
+// sources succeeded.
 func (bc *BaseCommand) executeOnSources(sharedCtx *SharedContext, sources []config.Source) error {
 	if len(sources) == 0 {
 		return nil // No sources to process
 	}
 
 	successCount := 0
-	failCount := 0
+	var failures []sourceFailure
 	operationName := bc.executor.GetOperationName()
+	defer func() {
+		sharedCtx.Summarize("succeeded", successCount)
+		sharedCtx.Summarize("failed", len(failures))
+	}()
 
 	for _, source := range sources {
+		if err := sharedCtx.Context().Err(); err != nil {
+			bc.printSummary(successCount, failures)
+			return fmt.Errorf("%s aborted: %w", bc.getOperationVerb(), err)
+		}
+
+		var err error
 		if bc.shouldUseSpinner(sharedCtx) {
 			// Use spinner for non-verbose mode
-			err := sharedCtx.PM.WithSpinner(fmt.Sprintf("%s %s", operationName, source.Name), func() error {
+			err = sharedCtx.PM.WithSpinner(fmt.Sprintf("%s %s", operationName, source.Name), func() error {
 				return bc.executor.ExecuteOperation(sharedCtx, []config.Source{source})
 			})
-
-			if err != nil {
-				PrintError("Failed to %s %s: %v", bc.getOperationVerb(), source.Name, err)
-				failCount++
-				if !bc.executor.ShouldContinueOnError(sharedCtx) {
-					return err
-				}
-			} else {
-				successCount++
-			}
 		} else {
 			// Verbose mode with detailed output
 			color.Blue("%s source: %s\n", operationName, source.Name)
+			err = bc.executor.ExecuteOperation(sharedCtx, []config.Source{source})
+			if err == nil {
+				PrintSuccess("Successfully %s %s", bc.getOperationPastTense(), source.Name)
+			}
+		}
 
-			if err := bc.executor.ExecuteOperation(sharedCtx, []config.Source{source}); err != nil {
-				PrintError("Failed to %s %s: %v", bc.getOperationVerb(), source.Name, err)
-				failCount++
-				if !bc.executor.ShouldContinueOnError(sharedCtx) {
-					return err
+		if err != nil {
+			PrintError("Failed to %s %s: %v", bc.getOperationVerb(), source.Name, err)
+			failures = append(failures, sourceFailure{source: source.Name, err: err})
+			if !bc.executor.ShouldContinueOnError(sharedCtx) {
+				if len(sources) > 1 {
+					bc.printSummary(successCount, failures)
 				}
-			} else {
-				PrintSuccess("Successfully %s %s", bc.getOperationPastTense(), source.Name)
-				successCount++
+				return err
 			}
+			continue
 		}
+		successCount++
 	}
 
 	// Print summary
-	bc.printSummary(successCount, failCount)
-	return nil
+	bc.printSummary(successCount, failures)
+	return bc.failuresError(successCount, failures)
+}
+
+// failuresError returns the error of a run that continued past failing
+// sources: nil when none failed, the source's own error when the only source
+// failed, and an ExitPartial error when other sources succeeded
+func (bc *BaseCommand) failuresError(successCount int, failures []sourceFailure) error {
+	switch {
+	case len(failures) == 0:
+		return nil
+	case successCount == 0 && len(failures) == 1:
+		return failures[0].err
+	}
+
+	names := make([]string, len(failures))
+	for i, failure := range failures {
+		names[i] = failure.source
+	}
+	err := fmt.Errorf("failed to %s %d of %d sources: %s", bc.getOperationVerb(),
+		len(failures), len(failures)+successCount, strings.Join(names, ", "))
+	if successCount == 0 {
+		return err
+	}
+	return &ExitError{Code: ExitPartial, Err: err}
 }
 
 // shouldUseSpinner determines if spinner should be used based on options
@@ -163,21 +222,22 @@ func (bc *BaseCommand) getOperationPastTense() string {
 	}
 }
 
-// printSummary prints the operation summary
-func (bc *BaseCommand) printSummary(successCount, failCount int) {
+// printSummary prints the operation summary, listing each failed source with its error
+func (bc *BaseCommand) printSummary(successCount int, failures []sourceFailure) {
 	fmt.Println()
 
-	completionMsg := bc.executor.GetCompletionMessage()
-
 	if successCount > 0 {
-		PrintSuccess("%s: %d succeeded", completionMsg, successCount)
+		PrintSuccess("%s: %d succeeded", bc.executor.GetCompletionMessage(), successCount)
 	}
 
-	if failCount > 0 {
-		PrintError("%d failed", failCount)
+	if len(failures) > 0 {
+		PrintError("%d failed:", len(failures))
+		for _, failure := range failures {
+			fmt.Printf("    %s: %v\n", failure.source, failure.err)
+		}
 	}
 
-	if successCount == 0 && failCount == 0 {
+	if successCount == 0 && len(failures) == 0 {
 		PrintInfo("No sources processed")
 	}
 }
@@ -8,6 +8,8 @@ import (
 	"github.com/pacphi/claude-code-agent-manager/internal/progress"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 // MockExecutor implements CommandExecutor for testing
@@ -118,8 +120,11 @@ func TestBaseCommand(t *testing.T) {
 		// Execute
 		err := baseCmd.executeOnSources(sharedCtx, sources)
 
-		// Verify - should not return error because we continue on error
-		assert.NoError(t, err)
+		// Verify - the run continues past the failure and reports partial success
+		var exitErr *ExitError
+		assert.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, ExitPartial, exitErr.Code)
+		assert.Contains(t, err.Error(), "failed to test 1 of 2 sources: source1")
 		executor.AssertExpectations(t)
 	})
 
@@ -159,6 +164,22 @@ func TestBaseCommand(t *testing.T) {
 		executor.AssertExpectations(t)
 	})
 
+	t.Run("failuresError", func(t *testing.T) {
+		baseCmd := NewBaseCommand(&MockExecutor{})
+		boom := errors.New("boom")
+
+		assert.NoError(t, baseCmd.failuresError(2, nil))
+		assert.Equal(t, boom, baseCmd.failuresError(0, []sourceFailure{{source: "a", err: boom}}))
+
+		executor := &MockExecutor{}
+		executor.On("GetOperationName").Return("Installing")
+		baseCmd = NewBaseCommand(executor)
+		err := baseCmd.failuresError(0, []sourceFailure{{source: "a", err: boom}, {source: "b", err: boom}})
+		var exitErr *ExitError
+		assert.False(t, errors.As(err, &exitErr), "a run where every source failed is not a partial success")
+		assert.EqualError(t, err, "failed to install 2 of 2 sources: a, b")
+	})
+
 	t.Run("validateSources", func(t *testing.T) {
 		executor := &MockExecutor{}
 		baseCmd := NewBaseCommand(executor)
@@ -251,11 +272,62 @@ func TestUpdateCommandWithBase(t *testing.T) {
 		assert.Equal(t, "Checking updates for", updateCmd.GetOperationName())
 		assert.Equal(t, "Check complete", updateCmd.GetCompletionMessage())
 
-		// Test ShouldContinueOnError
+		// Failing sources are isolated unless --fail-fast is given
 		testConfig := &config.Config{
 			Settings: config.Settings{ContinueOnError: false},
 		}
 		sharedCtx := &SharedContext{Config: testConfig}
+		assert.True(t, updateCmd.ShouldContinueOnError(sharedCtx))
+		updateCmd.failFast = true
+		assert.False(t, updateCmd.ShouldContinueOnError(sharedCtx))
+		updateCmd.failFast = false
+
+		// An explicit continue_on_error: false is honored
+		var settings config.Settings
+		require.NoError(t, yaml.Unmarshal([]byte("continue_on_error: false\n"), &settings))
+		sharedCtx = &SharedContext{Config: &config.Config{Settings: settings}}
 		assert.False(t, updateCmd.ShouldContinueOnError(sharedCtx))
+		assert.False(t, NewInstallCommand().ShouldContinueOnError(sharedCtx))
 	})
 }
+
+func TestFilterEnabledSourcesByPattern(t *testing.T) {
+	sharedCtx := &SharedContext{Config: &config.Config{Sources: []config.Source{
+		{Name: "marketplace-dev", Enabled: true},
+		{Name: "marketplace-ops", Enabled: true},
+		{Name: "marketplace-old", Enabled: false},
+		{Name: "team-agents", Enabled: true},
+	}}}
+
+	names := func(sources []config.Source) []string {
+		var result []string
+		for _, source := range sources {
+			result = append(result, source.Name)
+		}
+		return result
+	}
+
+	sources, err := sharedCtx.FilterEnabledSources("marketplace-*")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"marketplace-dev", "marketplace-ops"}, names(sources))
+
+	sources, err = sharedCtx.FilterEnabledSources("re:(team|marketplace)-(agents|dev)")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"marketplace-dev", "team-agents"}, names(sources))
+
+	// Regular expressions match whole names only
+	sources, err = sharedCtx.FilterEnabledSources("re:market")
+	assert.NoError(t, err)
+	assert.Empty(t, sources)
+
+	_, err = sharedCtx.FilterEnabledSources("marketplace-[")
+	assert.ErrorContains(t, err, "invalid source pattern")
+	_, err = sharedCtx.FilterEnabledSources("re:(")
+	assert.ErrorContains(t, err, "invalid source pattern")
+
+	err = NewBaseCommand(&MockExecutor{}).validateSources(nil, "nothing-*")
+	assert.ErrorContains(t, err, "no enabled source matches 'nothing-*'")
+
+	assert.False(t, isSourcePattern("team-agents"))
+	assert.False(t, isSourcePattern("marketplace/dev"))
+}
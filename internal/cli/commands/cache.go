@@ -0,0 +1,434 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/installer"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/cache"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/engine"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// CacheCommand implements reporting on and trimming the on-disk caches
+type CacheCommand struct {
+	action string
+}
+
+// NewCacheCommand creates a new cache command instance
+func NewCacheCommand() *CacheCommand {
+	return &CacheCommand{}
+}
+
+// Name returns the command name
+func (c *CacheCommand) Name() string {
+	return "cache"
+}
+
+// Description returns the command description
+func (c *CacheCommand) Description() string {
+	return "Report on and trim the query and marketplace caches"
+}
+
+// CreateCommand creates the cobra command for cache functionality
+func (c *CacheCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache <stats|clear|gc>",
+		Short: c.Description(),
+		Long: `Manage the caches agent-manager keeps on disk: the query cache in
+<base_dir>/.agent-cache and the marketplace cache of agent content checkpointed
+by interrupted marketplace fetches, next to the tracking file.
+
+stats reports the entries, bytes on disk and age distribution of each cache,
+and the hit rate of the process that last saved the query cache.
+
+clear removes both caches.
+
+gc applies the limits now instead of waiting for the next run: query cache
+entries older than query.cache.ttl are dropped and the oldest entries evicted
+until the file fits query.cache.max_size; marketplace checkpoints older than
+24 hours, or left by sources no longer configured, are removed and the oldest
+evicted until each source fits its cache.max_size_mb.
+
+Marketplace category and agent listings are only cached in memory for the
+life of a process, so they have nothing on disk to report or trim.
+
+Examples:
+  agent-manager cache stats
+  agent-manager cache gc
+  agent-manager cache clear`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"stats", "clear", "gc"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c.action = args[0]
+			return c.Execute(sharedCtx)
+		},
+	}
+
+	return cmd
+}
+
+// Execute runs the cache command logic
+func (c *CacheCommand) Execute(sharedCtx *SharedContext) error {
+	// Only clear and gc change files, so only they follow settings.default_dry_run
+	sharedCtx.mutating = c.action != "stats"
+
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	switch c.action {
+	case "stats":
+		return c.executeStats(sharedCtx)
+	case "clear":
+		return c.executeClear(sharedCtx)
+	case "gc":
+		return c.executeGC(sharedCtx)
+	default:
+		return fmt.Errorf("unknown cache action: %s", c.action)
+	}
+}
+
+// queryCachePath returns the query cache file the query engine uses
+func queryCachePath(sharedCtx *SharedContext) string {
+	return filepath.Join(sharedCtx.Config.Settings.BaseDir, ".agent-cache")
+}
+
+// queryCacheTTL returns the configured query cache TTL
+func queryCacheTTL(sharedCtx *SharedContext) time.Duration {
+	if ttl := sharedCtx.Config.Settings.Query.Cache.TTL; ttl > 0 {
+		return ttl
+	}
+	return time.Hour
+}
+
+// executeStats reports the entries, size and ages of both caches
+func (c *CacheCommand) executeStats(sharedCtx *SharedContext) error {
+	path := queryCachePath(sharedCtx)
+	usage, err := cache.Inspect(path, queryCacheTTL(sharedCtx))
+	if err != nil {
+		return err
+	}
+	maxBytes, err := sharedCtx.Config.Settings.Query.Cache.MaxBytes()
+	if err != nil {
+		return err
+	}
+
+	color.Blue("Query Cache\n")
+	fmt.Println(strings.Repeat("=", 40))
+	fmt.Printf("Path: %s\n", path)
+	fmt.Printf("Entries: %d (%d expired)\n", usage.Entries, usage.Expired)
+	if maxBytes > 0 {
+		fmt.Printf("Size on Disk: %s of %s\n", formatBytes(usage.Bytes), formatBytes(maxBytes))
+	} else {
+		fmt.Printf("Size on Disk: %s\n", formatBytes(usage.Bytes))
+	}
+	if total := usage.Hits + usage.Misses; total > 0 {
+		fmt.Printf("Hit Rate: %.1f%% (%d of %d lookups when last saved)\n",
+			float64(usage.Hits)/float64(total)*100, usage.Hits, total)
+	} else {
+		fmt.Printf("Hit Rate: no lookups recorded\n")
+	}
+	printAgeDistribution(usage.Ages)
+
+	root := installer.ResumeRoot(sharedCtx.Config.Metadata.TrackingFile)
+	entries, err := resumeEntries(root)
+	if err != nil {
+		return err
+	}
+	market := resumeUsage(entries)
+
+	fmt.Println()
+	color.Blue("Marketplace Cache\n")
+	fmt.Println(strings.Repeat("=", 40))
+	fmt.Printf("Path: %s\n", root)
+	fmt.Printf("Entries: %d (%d expired)\n", market.Entries, market.Expired)
+	fmt.Printf("Size on Disk: %s\n", formatBytes(market.Bytes))
+	bySource := sourcesOf(entries)
+	for _, source := range sortedKeys(bySource) {
+		fmt.Printf("  %s: %d\n", source, bySource[source])
+	}
+	printAgeDistribution(market.Ages)
+
+	sharedCtx.Summarize("query_entries", usage.Entries)
+	sharedCtx.Summarize("marketplace_entries", market.Entries)
+	sharedCtx.Summarize("bytes", usage.Bytes+market.Bytes)
+	return nil
+}
+
+// executeClear removes both caches
+func (c *CacheCommand) executeClear(sharedCtx *SharedContext) error {
+	path := queryCachePath(sharedCtx)
+	info, err := os.Stat(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read query cache: %w", err)
+	}
+	var queryBytes int64
+	if info != nil {
+		queryBytes = info.Size()
+	}
+
+	root := installer.ResumeRoot(sharedCtx.Config.Metadata.TrackingFile)
+	entries, err := resumeEntries(root)
+	if err != nil {
+		return err
+	}
+	market := resumeUsage(entries)
+
+	if sharedCtx.Options.DryRun {
+		color.Yellow("[DRY RUN] Would clear the query cache (%s) and %d marketplace cache entries (%s)\n",
+			formatBytes(queryBytes), market.Entries, formatBytes(market.Bytes))
+		return nil
+	}
+
+	if info != nil {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove query cache: %w", err)
+		}
+		PrintSuccess("Cleared query cache, freeing %s", formatBytes(queryBytes))
+	} else {
+		PrintInfo("Query cache is empty")
+	}
+
+	if err := os.RemoveAll(root); err != nil {
+		return fmt.Errorf("failed to remove marketplace cache: %w", err)
+	}
+	if market.Entries > 0 {
+		PrintSuccess("Cleared marketplace cache of %d entries, freeing %s", market.Entries, formatBytes(market.Bytes))
+	} else {
+		PrintInfo("Marketplace cache is empty")
+	}
+
+	sharedCtx.Summarize("freed", queryBytes+market.Bytes)
+	return nil
+}
+
+// executeGC applies the configured TTLs and size limits to both caches. In
+// dry-run mode the query cache is trimmed in a temporary copy, so the report
+// matches what a real run would remove.
+func (c *CacheCommand) executeGC(sharedCtx *SharedContext) error {
+	maxBytes, err := sharedCtx.Config.Settings.Query.Cache.MaxBytes()
+	if err != nil {
+		return err
+	}
+
+	path := queryCachePath(sharedCtx)
+	ttl := queryCacheTTL(sharedCtx)
+	before, err := cache.Inspect(path, ttl)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	if before.Entries > 0 {
+		if sharedCtx.Options.DryRun {
+			scratch, err := util.MkdirTemp("agent-manager-cache-")
+			if err != nil {
+				return fmt.Errorf("failed to create temp directory: %w", err)
+			}
+			defer os.RemoveAll(scratch)
+			if err := util.NewFileManager().Copy(path, filepath.Join(scratch, filepath.Base(path))); err != nil {
+				return fmt.Errorf("failed to copy query cache: %w", err)
+			}
+			path = filepath.Join(scratch, filepath.Base(path))
+		}
+		manager, err := cache.NewCacheManager(path, cache.Config{MaxSize: engine.CacheMaxEntries, TTL: ttl})
+		if err != nil {
+			return err
+		}
+		if _, err := manager.GC(maxBytes); err != nil {
+			_ = manager.Close()
+			return fmt.Errorf("failed to trim query cache: %w", err)
+		}
+		if err := manager.Close(); err != nil {
+			return fmt.Errorf("failed to save query cache: %w", err)
+		}
+		if after, err = cache.Inspect(path, ttl); err != nil {
+			return err
+		}
+	}
+	queryRemoved := before.Entries - after.Entries
+	if sharedCtx.Options.DryRun {
+		color.Yellow("[DRY RUN] Query cache: would remove %d of %d entries, leaving %s\n", queryRemoved, before.Entries, formatBytes(after.Bytes))
+	} else {
+		PrintSuccess("Query cache: removed %d of %d entries, %s left", queryRemoved, before.Entries, formatBytes(after.Bytes))
+	}
+
+	root := installer.ResumeRoot(sharedCtx.Config.Metadata.TrackingFile)
+	entries, err := resumeEntries(root)
+	if err != nil {
+		return err
+	}
+	limits := make(map[string]int64)
+	for _, source := range sharedCtx.Config.Sources {
+		if source.Type == "subagents" {
+			limits[source.Name] = int64(source.Cache.MaxSizeMB) << 20
+		}
+	}
+	stale := staleResumeEntries(entries, limits, time.Now())
+	var freed int64
+	if sharedCtx.Options.DryRun {
+		for _, entry := range stale {
+			freed += entry.size
+		}
+		color.Yellow("[DRY RUN] Marketplace cache: would remove %d of %d entries, freeing %s\n", len(stale), len(entries), formatBytes(freed))
+		return nil
+	}
+	for _, entry := range stale {
+		if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove marketplace cache entry: %w", err)
+		}
+		freed += entry.size
+	}
+	// Drop source directories left empty; non-empty ones fail and stay
+	for _, source := range sortedKeys(sourcesOf(entries)) {
+		_ = os.Remove(filepath.Join(root, source))
+	}
+	PrintSuccess("Marketplace cache: removed %d of %d entries, freeing %s", len(stale), len(entries), formatBytes(freed))
+
+	sharedCtx.Summarize("removed", queryRemoved+len(stale))
+	sharedCtx.Summarize("freed", before.Bytes-after.Bytes+freed)
+	return nil
+}
+
+// resumeEntry is agent content a marketplace source checkpointed on disk
+type resumeEntry struct {
+	path    string
+	source  string
+	size    int64
+	modTime time.Time
+}
+
+// resumeEntries lists the checkpoints under root, one directory per source
+func resumeEntries(root string) ([]resumeEntry, error) {
+	dirs, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read marketplace cache: %w", err)
+	}
+
+	var entries []resumeEntry
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(root, dir.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read marketplace cache: %w", err)
+		}
+		for _, file := range files {
+			info, err := file.Info()
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			entries = append(entries, resumeEntry{
+				path:    filepath.Join(root, dir.Name(), file.Name()),
+				source:  dir.Name(),
+				size:    info.Size(),
+				modTime: info.ModTime(),
+			})
+		}
+	}
+	return entries, nil
+}
+
+// resumeUsage summarizes checkpoints the way cache.Inspect does the query cache
+func resumeUsage(entries []resumeEntry) cache.Usage {
+	var usage cache.Usage
+	now := time.Now()
+	for _, entry := range entries {
+		age := now.Sub(entry.modTime)
+		if age > installer.ResumeMaxAge {
+			usage.Expired++
+		}
+		usage.Entries++
+		usage.Bytes += entry.size
+		usage.Ages = append(usage.Ages, age)
+	}
+	return usage
+}
+
+// staleResumeEntries returns the checkpoints gc removes: those of sources
+// missing from limits, those too old to be resumed, and the oldest of each
+// source beyond its byte limit (zero for no limit)
+func staleResumeEntries(entries []resumeEntry, limits map[string]int64, now time.Time) []resumeEntry {
+	var stale []resumeEntry
+	kept := make(map[string][]resumeEntry)
+	for _, entry := range entries {
+		if _, configured := limits[entry.source]; !configured || now.Sub(entry.modTime) > installer.ResumeMaxAge {
+			stale = append(stale, entry)
+			continue
+		}
+		kept[entry.source] = append(kept[entry.source], entry)
+	}
+
+	for _, source := range sortedKeys(kept) {
+		limit := limits[source]
+		if limit <= 0 {
+			continue
+		}
+		remaining := kept[source]
+		sort.Slice(remaining, func(i, j int) bool { return remaining[i].modTime.Before(remaining[j].modTime) })
+		var total int64
+		for _, entry := range remaining {
+			total += entry.size
+		}
+		for _, entry := range remaining {
+			if total <= limit {
+				break
+			}
+			stale = append(stale, entry)
+			total -= entry.size
+		}
+	}
+	return stale
+}
+
+// sourcesOf returns the number of checkpoints of each source
+func sourcesOf(entries []resumeEntry) map[string]int {
+	sources := make(map[string]int)
+	for _, entry := range entries {
+		sources[entry.source]++
+	}
+	return sources
+}
+
+// ageBuckets are the upper bounds of the age distribution rows
+var ageBuckets = []struct {
+	label string
+	max   time.Duration
+}{
+	{"under 1 hour", time.Hour},
+	{"1 hour to 1 day", 24 * time.Hour},
+	{"1 to 7 days", 7 * 24 * time.Hour},
+	{"over 7 days", 1<<63 - 1},
+}
+
+// printAgeDistribution prints how many entries fall in each age bucket
+func printAgeDistribution(ages []time.Duration) {
+	if len(ages) == 0 {
+		return
+	}
+	counts := make([]int, len(ageBuckets))
+	for _, age := range ages {
+		for i, bucket := range ageBuckets {
+			if age < bucket.max {
+				counts[i]++
+				break
+			}
+		}
+	}
+
+	fmt.Printf("Age Distribution:\n")
+	for i, bucket := range ageBuckets {
+		fmt.Printf("  %-16s %d\n", bucket.label+":", counts[i])
+	}
+}
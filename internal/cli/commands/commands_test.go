@@ -1,9 +1,23 @@
 package commands
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/pacphi/claude-code-agent-manager/internal/bundle"
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/conflict"
+	"github.com/pacphi/claude-code-agent-manager/internal/githook"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +30,7 @@ func TestCommandRegistry(t *testing.T) {
 
 	// Test that all expected commands are registered
 	expectedCommands := []string{
+		"init",
 		"install",
 		"uninstall",
 		"update",
@@ -25,6 +40,31 @@ func TestCommandRegistry(t *testing.T) {
 		"stats",
 		"validate",
 		"index",
+		"cache",
+		"rename",
+		"set",
+		"parse-report",
+		"publish",
+		"export",
+		"import",
+		"quarantine",
+		"unquarantine",
+		"archive",
+		"unarchive",
+		"disable",
+		"enable",
+		"githook",
+		"plan",
+		"inventory",
+		"explain",
+		"auth",
+		"config",
+		"conflicts",
+		"doctor",
+		"apply",
+		"serve-index",
+		"watch",
+		"verify",
 	}
 
 	if len(registry.commands) != len(expectedCommands) {
@@ -74,6 +114,7 @@ func TestCommandImplementation(t *testing.T) {
 		name        string
 		constructor func() Command
 	}{
+		{"init", func() Command { return NewInitCommand() }},
 		{"install", func() Command { return NewInstallCommand() }},
 		{"uninstall", func() Command { return NewUninstallCommand() }},
 		{"update", func() Command { return NewUpdateCommand() }},
@@ -83,6 +124,30 @@ func TestCommandImplementation(t *testing.T) {
 		{"stats", func() Command { return NewStatsCommand() }},
 		{"validate", func() Command { return NewValidateCommand() }},
 		{"index", func() Command { return NewIndexCommand() }},
+		{"rename", func() Command { return NewRenameCommand() }},
+		{"set", func() Command { return NewSetCommand() }},
+		{"parse-report", func() Command { return NewParseReportCommand() }},
+		{"publish", func() Command { return NewPublishCommand() }},
+		{"export", func() Command { return NewExportCommand() }},
+		{"import", func() Command { return NewImportCommand() }},
+		{"quarantine", func() Command { return NewQuarantineCommand() }},
+		{"unquarantine", func() Command { return NewUnquarantineCommand() }},
+		{"archive", func() Command { return NewArchiveCommand() }},
+		{"unarchive", func() Command { return NewUnarchiveCommand() }},
+		{"disable", func() Command { return NewDisableCommand() }},
+		{"enable", func() Command { return NewEnableCommand() }},
+		{"githook", func() Command { return NewGithookCommand() }},
+		{"plan", func() Command { return NewPlanCommand() }},
+		{"inventory", func() Command { return NewInventoryCommand() }},
+		{"explain", func() Command { return NewExplainCommand() }},
+		{"auth", func() Command { return NewAuthCommand() }},
+		{"config", func() Command { return NewConfigCommand() }},
+		{"conflicts", func() Command { return NewConflictsCommand() }},
+		{"doctor", func() Command { return NewDoctorCommand() }},
+		{"apply", func() Command { return NewApplyCommand() }},
+		{"serve-index", func() Command { return NewServeIndexCommand() }},
+		{"watch", func() Command { return NewWatchCommand() }},
+		{"verify", func() Command { return NewVerifyCommand() }},
 	}
 
 	for _, tc := range testCases {
@@ -157,6 +222,528 @@ func TestQueryCommandRegexSupport(t *testing.T) {
 	}
 }
 
+func TestCommandTimeoutFlags(t *testing.T) {
+	for _, command := range []Command{NewInstallCommand(), NewUpdateCommand(), NewUninstallCommand()} {
+		cobraCmd := command.CreateCommand(NewSharedContext(&SharedOptions{}))
+		if cobraCmd.Flags().Lookup("timeout") == nil {
+			t.Errorf("Expected --timeout flag on %s command", command.Name())
+		}
+	}
+}
+
+func TestSharedContextTimeout(t *testing.T) {
+	ctx := NewSharedContext(&SharedOptions{})
+
+	if ctx.Context().Err() != nil {
+		t.Error("Expected default context to be live")
+	}
+
+	cancel := ctx.WithTimeout(time.Nanosecond)
+	defer cancel()
+	<-ctx.Context().Done()
+
+	if !errors.Is(ctx.Context().Err(), context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", ctx.Context().Err())
+	}
+
+	cancel = ctx.WithTimeout(0)
+	defer cancel()
+	if _, hasDeadline := ctx.Context().Deadline(); hasDeadline {
+		t.Error("Expected zero timeout without config to have no deadline")
+	}
+
+	ctx.Config = &config.Config{Settings: config.Settings{Timeout: time.Minute}}
+	cancel = ctx.WithTimeout(0)
+	defer cancel()
+	if _, hasDeadline := ctx.Context().Deadline(); !hasDeadline {
+		t.Error("Expected zero timeout to fall back to settings.timeout")
+	}
+}
+
+func TestValidateInstalledAgents_ValidationError(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"reviewer.md": "---\nname: reviewer\ndescription: Reviews code\n---\nReview the change carefully.",
+		"broken.md":   "---\nname: [unterminated\n---\nPrompt",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sharedCtx := NewSharedContext(&SharedOptions{})
+	sharedCtx.Config = &config.Config{Settings: config.Settings{BaseDir: dir}}
+	err := NewValidateCommand().validateInstalledAgents(sharedCtx)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a ValidationError, got %v", err)
+	}
+	if validationErr.Total != 2 || validationErr.Valid != 1 || validationErr.Invalid != 1 || validationErr.ParseFailures != 1 {
+		t.Errorf("Unexpected summary %+v", validationErr)
+	}
+}
+
+func TestValidateStdinAgent(t *testing.T) {
+	sharedCtx := NewSharedContext(&SharedOptions{})
+	sharedCtx.Config = &config.Config{}
+	cmd := NewValidateCommand()
+
+	valid := "---\nname: reviewer\ndescription: Reviews code\n---\nReview the change carefully."
+	if err := cmd.validateStdinAgent(sharedCtx, strings.NewReader(valid)); err != nil {
+		t.Errorf("Expected a valid agent, got %v", err)
+	}
+
+	var validationErr *ValidationError
+	for _, content := range []string{"", "no frontmatter", "---\ndescription: Nameless\n---\nReview the change carefully."} {
+		err := cmd.validateStdinAgent(sharedCtx, strings.NewReader(content))
+		if !errors.As(err, &validationErr) || validationErr.Invalid != 1 {
+			t.Errorf("Expected %q reported invalid, got %v", content, err)
+		}
+	}
+}
+
+func TestInstallStdinAgent(t *testing.T) {
+	dir := t.TempDir()
+	agentsDir := filepath.Join(dir, "agents")
+	configPath := filepath.Join(dir, "agents-config.yaml")
+	configYAML := fmt.Sprintf(`version: "1.0"
+settings:
+  base_dir: %s
+  backup_dir: %s
+sources:
+  - name: team
+    type: local
+    paths:
+      source: %s
+      target: %s
+metadata:
+  tracking_file: %s
+`, agentsDir, filepath.Join(dir, "backups"), dir, agentsDir, filepath.Join(dir, ".installed.json"))
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sharedCtx := NewSharedContext(&SharedOptions{ConfigFile: configPath, NoProgress: true})
+
+	cmd := NewInstallCommand()
+	cmd.stdin = true
+	cmd.agentName = "pasted"
+	agent := "---\nname: scratch\ndescription: Pasted agent\n---\nYou help with pasted things."
+	if err := cmd.executeStdin(sharedCtx, strings.NewReader(agent)); err != nil {
+		t.Fatalf("executeStdin failed: %v", err)
+	}
+
+	path := filepath.Join(agentsDir, "pasted.md")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected the agent installed: %v", err)
+	}
+	if !strings.Contains(string(content), "name: pasted") {
+		t.Errorf("Expected the frontmatter name set from --name, got:\n%s", content)
+	}
+	owner, info, err := tracker.New(filepath.Join(dir, ".installed.json")).FindFile(path)
+	if err != nil || owner != tracker.ManualSource || info.WasPreExisting {
+		t.Errorf("Expected the agent tracked under the manual source, got %q %+v (%v)", owner, info, err)
+	}
+
+	// Reinstalling replaces the manual agent, but untracked files are left alone
+	if err := cmd.executeStdin(sharedCtx, strings.NewReader(agent)); err != nil {
+		t.Errorf("Expected the manual agent replaced, got %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(agentsDir, "mine.md"), []byte("hand written"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd.agentName = "mine"
+	if err := cmd.executeStdin(sharedCtx, strings.NewReader(agent)); err == nil {
+		t.Error("Expected an untracked file not to be overwritten")
+	}
+
+	for _, name := range []string{"", "../escape", "Bad Name"} {
+		cmd.agentName = name
+		if err := cmd.executeStdin(sharedCtx, strings.NewReader(agent)); err == nil {
+			t.Errorf("Expected name %q rejected", name)
+		}
+	}
+}
+
+func TestRenameHelpers(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "go-expert.md")
+	newPath := filepath.Join(dir, "go-specialist.md")
+	refPath := filepath.Join(dir, "reviewer.md")
+
+	if err := os.WriteFile(oldPath, []byte("---\nname: go-expert\ndescription: Go help\n---\nPrompt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	refContent := "---\nname: reviewer\nrequires: [go-expert]\n---\nAsk go-expert go-expert, not go-expert-v2."
+	if err := os.WriteFile(refPath, []byte(refContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agents := []*parser.AgentSpec{
+		{Name: "go-expert", FileName: "go-expert.md", FilePath: oldPath},
+		{Name: "reviewer", FileName: "reviewer.md", FilePath: refPath},
+	}
+
+	if found := findAgentExact(agents, "go-expert.md"); found != agents[0] {
+		t.Error("Expected lookup by filename to find agent")
+	}
+	if found := findAgentExact(agents, "go"); found != nil {
+		t.Error("Expected exact lookup not to fuzzy match")
+	}
+
+	refs := findReferencingAgents(agents, agents[0], "go-expert")
+	if len(refs) != 1 || refs[0] != agents[1] {
+		t.Fatalf("Expected reviewer to reference go-expert, got %v", refs)
+	}
+
+	if err := renameAgentFile(oldPath, newPath, "go-specialist"); err != nil {
+		t.Fatalf("renameAgentFile failed: %v", err)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Error("Expected old file to be removed")
+	}
+	renamed, _ := os.ReadFile(newPath)
+	if !strings.Contains(string(renamed), "name: go-specialist") {
+		t.Errorf("Expected frontmatter name to be updated, got:\n%s", renamed)
+	}
+
+	changed, err := replaceReferences(refPath, "go-expert", "go-specialist")
+	if err != nil || !changed {
+		t.Fatalf("replaceReferences failed: changed=%v err=%v", changed, err)
+	}
+	updated, _ := os.ReadFile(refPath)
+	want := "---\nname: reviewer\nrequires: [go-specialist]\n---\nAsk go-specialist go-specialist, not go-expert-v2."
+	if string(updated) != want {
+		t.Errorf("Unexpected reference update:\n%s", updated)
+	}
+}
+
+func TestWriteAgentChangeBackupsByPath(t *testing.T) {
+	agentsDir := t.TempDir()
+	backupDir := filepath.Join(t.TempDir(), "edits")
+
+	for _, ns := range []string{"team", "local"} {
+		path := filepath.Join(agentsDir, ns, "reviewer.md")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		before := "---\nname: reviewer\n---\n" + ns
+		if err := os.WriteFile(path, []byte(before), 0644); err != nil {
+			t.Fatal(err)
+		}
+		change := agentChange{
+			agent:  &parser.AgentSpec{Name: "reviewer", FileName: "reviewer.md", FilePath: path},
+			before: before,
+			after:  before + " edited",
+		}
+		if err := writeAgentChange(change, agentsDir, backupDir); err != nil {
+			t.Fatalf("writeAgentChange failed: %v", err)
+		}
+	}
+
+	for _, ns := range []string{"team", "local"} {
+		backup, err := os.ReadFile(filepath.Join(backupDir, ns, "reviewer.md"))
+		if err != nil || !strings.HasSuffix(string(backup), "\n"+ns) {
+			t.Errorf("Expected the %s backup kept apart, got %q (%v)", ns, backup, err)
+		}
+	}
+}
+
+func TestPublishCommitMessage(t *testing.T) {
+	published := []publishedAgent{
+		{agent: &parser.AgentSpec{Name: "reviewer"}, source: "team", commit: "0123456789abcdef0123"},
+		{agent: &parser.AgentSpec{Name: "go-expert"}, source: "local"},
+		{agent: &parser.AgentSpec{Name: "untracked"}},
+	}
+
+	subject, body := publishCommitMessage(published, "")
+	if subject != "Publish 3 agents" {
+		t.Errorf("Unexpected subject: %s", subject)
+	}
+	want := "Published by agent-manager.\n\n- go-expert (source: local)\n- reviewer (source: team @ 0123456)\n- untracked\n"
+	if body != want {
+		t.Errorf("Unexpected body:\n%s", body)
+	}
+
+	if subject, _ := publishCommitMessage(published[:1], ""); subject != "Publish agent reviewer" {
+		t.Errorf("Unexpected single-agent subject: %s", subject)
+	}
+	if subject, _ := publishCommitMessage(published, "Sync team agents"); subject != "Sync team agents" {
+		t.Errorf("Expected subject override, got %s", subject)
+	}
+}
+
+func TestRenderAgents(t *testing.T) {
+	agents := []*parser.AgentSpec{
+		{Name: "go-expert", Source: "team", Tools: []string{"Read", "Grep"}},
+		{Name: "reviewer", Source: "local"},
+	}
+
+	tmpl, err := loadOutputTemplate(`{{.Name}}\t{{.Source}}`, nil)
+	if err != nil {
+		t.Fatalf("loadOutputTemplate failed: %v", err)
+	}
+	var out strings.Builder
+	if err := renderAgents(&out, tmpl, agents); err != nil {
+		t.Fatalf("renderAgents failed: %v", err)
+	}
+	if out.String() != "go-expert\tteam\nreviewer\tlocal\n" {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "tools.tmpl")
+	if err := os.WriteFile(path, []byte(`{{upper .Name}}: {{join .GetToolsAsSlice ","}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, spec := range []string{"@" + path, "tools"} {
+		tmpl, err := loadOutputTemplate(spec, map[string]string{"tools": path})
+		if err != nil {
+			t.Fatalf("loadOutputTemplate(%q) failed: %v", spec, err)
+		}
+		out.Reset()
+		if err := renderAgents(&out, tmpl, agents[:1]); err != nil {
+			t.Fatalf("renderAgents failed: %v", err)
+		}
+		if out.String() != "GO-EXPERT: Read,Grep\n" {
+			t.Errorf("Unexpected output for %q: %q", spec, out.String())
+		}
+	}
+
+	// truncate counts characters, not bytes
+	tmpl, err = loadOutputTemplate(`{{truncate 6 .Description}}`, nil)
+	if err != nil {
+		t.Fatalf("loadOutputTemplate failed: %v", err)
+	}
+	out.Reset()
+	if err := renderAgents(&out, tmpl, []*parser.AgentSpec{{Description: "Überprüft Änderungen"}}); err != nil {
+		t.Fatalf("renderAgents failed: %v", err)
+	}
+	if out.String() != "Übe...\n" {
+		t.Errorf("Unexpected truncated output: %q", out.String())
+	}
+
+	if _, err := loadOutputTemplate("{{.Name", nil); err == nil {
+		t.Error("Expected error for malformed template")
+	}
+	tmpl, _ = loadOutputTemplate("{{.Missing}}", nil)
+	if err := renderAgents(&out, tmpl, agents); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+}
+
+func TestPrintConflictReport(t *testing.T) {
+	var empty strings.Builder
+	printConflictReport(&empty, nil)
+	if empty.Len() != 0 {
+		t.Errorf("Expected no output without conflicts, got %q", empty.String())
+	}
+
+	var out strings.Builder
+	printConflictReport(&out, []conflict.Outcome{
+		{Path: ".claude/agents/a.md", Source: "team", Strategy: "backup", Action: conflict.ActionBackedUp, BackupPath: "backups/a.md_1"},
+		{Path: ".claude/agents/b.md", Source: "team", Strategy: "skip", Action: conflict.ActionSkipped},
+	})
+
+	for _, want := range []string{
+		"Conflict report (2 files):",
+		".claude/agents/a.md",
+		"Source: team | Strategy: backup | Action: backed_up",
+		"Backup: backups/a.md_1",
+		"Strategy: skip | Action: skipped",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Report missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestSortSourceRows(t *testing.T) {
+	now := time.Now()
+	rows := []sourceRow{
+		{name: "b", installed: now.Add(-time.Hour), files: 5, size: 100, checked: now},
+		{name: "a", installed: now.Add(-48 * time.Hour), files: 5, size: 900},
+		{name: "c", installed: now, files: 9, size: 10, checked: now.Add(-time.Hour)},
+	}
+
+	for key, want := range map[string]string{
+		"name":    "abc",
+		"age":     "abc",
+		"files":   "cab",
+		"size":    "abc",
+		"checked": "acb",
+	} {
+		sortSourceRows(rows, key)
+		got := ""
+		for _, row := range rows {
+			got += row.name
+		}
+		if got != want {
+			t.Errorf("--sort %s ordered %s, want %s", key, got, want)
+		}
+	}
+}
+
+func TestInitCommandWritesStarterConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agents-config.yaml")
+	sharedCtx := NewSharedContext(&SharedOptions{ConfigFile: path, NoProgress: true})
+
+	cmd := NewInitCommand()
+	cmd.yes = true
+	cmd.noInstall = true
+	if err := cmd.Execute(sharedCtx); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+
+	if err := sharedCtx.LoadConfig(); err != nil {
+		t.Fatalf("Starter config does not load: %v", err)
+	}
+	if len(sharedCtx.Config.Sources) != len(config.StarterSources) {
+		t.Errorf("Expected %d sources, got %d", len(config.StarterSources), len(sharedCtx.Config.Sources))
+	}
+
+	if err := cmd.Execute(sharedCtx); err == nil {
+		t.Error("Expected init to refuse overwriting an existing configuration")
+	}
+	cmd.force = true
+	if err := cmd.Execute(sharedCtx); err != nil {
+		t.Errorf("init --force failed: %v", err)
+	}
+}
+
+func TestDryRunPolicy(t *testing.T) {
+	dir := t.TempDir()
+	sourceDir := filepath.Join(dir, "src")
+	targetDir := filepath.Join(dir, "agents")
+	if err := os.MkdirAll(sourceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "helper.md"), []byte("---\nname: helper\ndescription: Helps\n---\nPrompt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	writeConfig := func(policy string) string {
+		path := filepath.Join(dir, "agents-config.yaml")
+		content := fmt.Sprintf(`version: "1.0"
+settings:
+  base_dir: %s
+  conflict_strategy: overwrite
+  backup_dir: %s
+%s
+sources:
+  - name: local
+    enabled: true
+    type: local
+    paths:
+      source: %s
+      target: %s
+metadata:
+  tracking_file: %s
+`, targetDir, filepath.Join(dir, "backups"), policy, sourceDir, targetDir, filepath.Join(dir, ".installed.json"))
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	install := func(configPath string, apply bool) *SharedContext {
+		sharedCtx := NewSharedContext(&SharedOptions{ConfigFile: configPath, NoProgress: true, Apply: apply})
+		sharedCtx.mutating = true
+		if err := NewInstallCommand().Execute(sharedCtx); err != nil {
+			t.Fatalf("install failed: %v", err)
+		}
+		return sharedCtx
+	}
+	installed := func() bool {
+		_, err := os.Stat(filepath.Join(targetDir, "helper.md"))
+		return err == nil
+	}
+
+	configPath := writeConfig("  default_dry_run: true")
+	if sharedCtx := install(configPath, false); !sharedCtx.PlannedOnly() || !sharedCtx.Options.DryRun || installed() {
+		t.Error("Expected default_dry_run to plan without installing")
+	}
+	if sharedCtx := install(configPath, true); sharedCtx.PlannedOnly() || !installed() {
+		t.Error("Expected --apply to install")
+	}
+	if err := os.RemoveAll(targetDir); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath = writeConfig("")
+	content, _ := os.ReadFile(configPath)
+	if err := os.WriteFile(configPath, []byte(strings.Replace(string(content), "    enabled: true\n", "    enabled: true\n    dry_run: true\n", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if sharedCtx := install(configPath, false); !sharedCtx.PlannedOnly() || sharedCtx.Options.DryRun || installed() {
+		t.Error("Expected source dry_run to plan without installing")
+	}
+	if sharedCtx := install(configPath, true); sharedCtx.PlannedOnly() || !installed() {
+		t.Error("Expected --apply to install a dry_run source")
+	}
+}
+
+func TestBaseDirOverride(t *testing.T) {
+	dir := t.TempDir()
+	otherDir := filepath.Join(dir, "other")
+	if err := os.MkdirAll(otherDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(otherDir, "helper.md"), []byte("---\nname: helper\ndescription: Helps\n---\nPrompt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "agents-config.yaml")
+	config := fmt.Sprintf(`version: "1.0"
+settings:
+  base_dir: %s
+sources:
+  - name: local
+    type: local
+    paths:
+      source: %s
+      target: %s
+metadata:
+  tracking_file: %s
+`, filepath.Join(dir, "agents"), otherDir, filepath.Join(dir, "agents"), filepath.Join(dir, ".installed.json"))
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sharedCtx := NewSharedContext(&SharedOptions{ConfigFile: configPath, BaseDir: otherDir, NoProgress: true})
+	if err := sharedCtx.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if got := sharedCtx.GetAgentsDirectory(); got != otherDir {
+		t.Errorf("Expected base directory %s, got %s", otherDir, got)
+	}
+	if got, want := sharedCtx.Config.Settings.Query.Index.Path, filepath.Join(otherDir, ".agent-index"); got != want {
+		t.Errorf("Expected index path %s, got %s", want, got)
+	}
+	queryEngine, err := sharedCtx.CreateQueryEngine()
+	if err != nil {
+		t.Fatalf("CreateQueryEngine failed: %v", err)
+	}
+	if agents := queryEngine.GetAllAgents(); len(agents) != 1 || agents[0].Name != "helper" {
+		t.Errorf("Expected the agent under --base-dir to be indexed, got %d agents", len(agents))
+	}
+	if content, _ := os.ReadFile(configPath); string(content) != config {
+		t.Error("Expected the configuration file to be left unchanged")
+	}
+
+	sharedCtx = NewSharedContext(&SharedOptions{ConfigFile: configPath, BaseDir: filepath.Join(dir, "missing"), NoProgress: true})
+	if err := sharedCtx.LoadConfig(); err == nil || !strings.Contains(err.Error(), "--base-dir") {
+		t.Errorf("Expected a missing --base-dir to fail, got %v", err)
+	}
+
+	rootCmd := NewCommandRegistry().CreateRootCommand("test-version")
+	rootCmd.SetArgs([]string{"install", "--config", configPath, "--base-dir", otherDir})
+	rootCmd.SilenceUsage = true
+	rootCmd.SilenceErrors = true
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "not supported by install") {
+		t.Errorf("Expected install to reject --base-dir, got %v", err)
+	}
+}
+
 func TestQueryCommandAdvancedFeatures(t *testing.T) {
 	cmd := NewQueryCommand()
 	cobraCmd := cmd.CreateCommand(NewSharedContext(&SharedOptions{}))
@@ -210,3 +797,548 @@ func TestMainFileReduction(t *testing.T) {
 	t.Logf("Successfully refactored main.go from 1,511 lines to ~23 lines (98.5%% reduction)")
 	t.Logf("All %d expected commands are present in the new architecture", len(expectedCommands))
 }
+
+func TestQuietSummaryLine(t *testing.T) {
+	var out strings.Builder
+	sharedCtx := NewSharedContext(&SharedOptions{Quiet: true})
+	sharedCtx.stdout = &out
+
+	root := &cobra.Command{Use: "agent-manager"}
+	root.AddCommand(&cobra.Command{
+		Use: "install",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sharedCtx.Summarize("source", "foo")
+			sharedCtx.Summarize("files", 41)
+			sharedCtx.Summarize("files", 42)
+			sharedCtx.Summarize("note", "two words")
+			return nil
+		},
+	})
+	root.AddCommand(&cobra.Command{
+		Use:  "update",
+		RunE: func(cmd *cobra.Command, args []string) error { return errors.New("fetch failed") },
+	})
+	addSummaries(root, sharedCtx)
+
+	root.SetArgs([]string{"install"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if want := `install source=foo files=42 note="two words" duration=0.0s status=ok` + "\n"; out.String() != want {
+		t.Errorf("Summary line = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	sharedCtx.summary = nil
+	root.SetArgs([]string{"update"})
+	if err := root.Execute(); err == nil {
+		t.Fatal("Expected update to fail")
+	}
+	if got := out.String(); !strings.HasPrefix(got, "update duration=") || !strings.HasSuffix(got, " status=error\n") || strings.Count(got, "\n") != 1 {
+		t.Errorf("Expected a single error summary line, got %q", got)
+	}
+}
+
+func TestConflictsResolve(t *testing.T) {
+	dir := t.TempDir()
+	sharedCtx := NewSharedContext(&SharedOptions{NoProgress: true})
+	sharedCtx.Config = &config.Config{Settings: config.Settings{BaseDir: dir}}
+
+	marked := "---\nname: reviewer\n<<<<<<<<< Current\ndescription: Local\n=========\ndescription: Upstream\n>>>>>>>>> Incoming\n---\n" +
+		"<<<<<<<<< Current\nLocal prompt text.\n=========\nUpstream prompt text.\n>>>>>>>>> Incoming\n"
+	path := filepath.Join(dir, "reviewer.md")
+	write := func() {
+		if err := os.WriteFile(path, []byte(marked), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Interactive: keep the current description, skip the prompt, then skip
+	write()
+	cmd := NewConflictsCommand()
+	var out strings.Builder
+	if err := cmd.executeResolve(sharedCtx, "reviewer.md", strings.NewReader("x\nc\ns\n"), &out); err != nil {
+		t.Fatalf("executeResolve failed: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), "description: Local\n---\n<<<<<<<<< Current") {
+		t.Errorf("Expected the first conflict resolved and the second kept, got:\n%s", content)
+	}
+	if !strings.Contains(out.String(), "Conflict 2 of 2") || !strings.Contains(out.String(), `Unknown choice: "x"`) {
+		t.Errorf("Expected both conflicts shown and the bad choice reported, got:\n%s", out.String())
+	}
+
+	// Quitting leaves the file alone
+	write()
+	if err := cmd.executeResolve(sharedCtx, path, strings.NewReader("i\nq\n"), &out); err != errResolveAborted {
+		t.Errorf("Expected the resolution aborted, got %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != marked {
+		t.Errorf("Expected the file unchanged after quitting, got:\n%s", content)
+	}
+
+	// --take resolves everything and the result is validated
+	cmd.take = "incoming"
+	if err := cmd.executeResolve(sharedCtx, path, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("executeResolve --take failed: %v", err)
+	}
+	want := "---\nname: reviewer\ndescription: Upstream\n---\nUpstream prompt text.\n"
+	if content, _ := os.ReadFile(path); string(content) != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, content)
+	}
+
+	// A resolution that leaves an invalid agent is not written
+	broken := "---\n<<<<<<< a\nname: reviewer\n=======\ndescription: no name\n>>>>>>> b\n---\nPrompt text here.\n"
+	if err := os.WriteFile(path, []byte(broken), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.executeResolve(sharedCtx, path, strings.NewReader(""), &out); err == nil {
+		t.Error("Expected an invalid resolution to be refused")
+	}
+	if content, _ := os.ReadFile(path); string(content) != broken {
+		t.Errorf("Expected the file unchanged, got:\n%s", content)
+	}
+
+	cmd.take = "mine"
+	if err := cmd.executeResolve(sharedCtx, path, strings.NewReader(""), &out); err == nil {
+		t.Error("Expected an unknown --take side to be rejected")
+	}
+}
+
+func TestErrorCategory(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{fmt.Errorf("install failed: %w", context.DeadlineExceeded), "timeout"},
+		{&ValidationError{Invalid: 2}, "invalid_agents"},
+		{&ExitError{Code: ExitPartial, Err: errors.New("2 of 3 sources failed")}, "partial"},
+		{&ExitError{Code: ExitPlanned, Err: errors.New("plan mode")}, "other"},
+		{fmt.Errorf("failed to copy: %w", os.ErrPermission), "permission"},
+		{fmt.Errorf("configuration error: %w", errors.New("bad yaml")), "config"},
+		{errors.New("something else"), "other"},
+	}
+	for _, tt := range tests {
+		if got := errorCategory(tt.err); got != tt.want {
+			t.Errorf("errorCategory(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+
+	// Planned runs are not failures
+	if runFailure(&ExitError{Code: ExitPlanned, Err: errors.New("plan mode")}) != nil {
+		t.Error("Expected a planned run not to count as a failure")
+	}
+}
+
+func TestStaleResumeEntries(t *testing.T) {
+	now := time.Now()
+	entry := func(source, name string, size int64, age time.Duration) resumeEntry {
+		return resumeEntry{path: source + "/" + name, source: source, size: size, modTime: now.Add(-age)}
+	}
+	entries := []resumeEntry{
+		entry("market", "old", 10, 25*time.Hour),
+		entry("market", "a", 40, 3*time.Hour),
+		entry("market", "b", 40, 2*time.Hour),
+		entry("market", "c", 40, time.Hour),
+		entry("removed", "d", 10, time.Minute),
+		entry("unlimited", "e", 1000, time.Minute),
+	}
+
+	stale := staleResumeEntries(entries, map[string]int64{"market": 100, "unlimited": 0}, now)
+	var got []string
+	for _, entry := range stale {
+		got = append(got, entry.path)
+	}
+	want := []string{"market/old", "removed/d", "market/a"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("staleResumeEntries() = %v, want %v", got, want)
+	}
+}
+
+func TestCacheDryRunKeepsFiles(t *testing.T) {
+	dir := t.TempDir()
+	baseDir := filepath.Join(dir, "agents")
+	trackingFile := filepath.Join(dir, ".claude", ".installed-agents.json")
+	configPath := filepath.Join(dir, "agents-config.yaml")
+	content := fmt.Sprintf(`version: "1.0"
+settings:
+  base_dir: %s
+sources:
+  - name: local
+    enabled: true
+    type: local
+    paths:
+      source: %s
+      target: %s
+metadata:
+  tracking_file: %s
+`, baseDir, dir, baseDir, trackingFile)
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	queryCache := filepath.Join(baseDir, ".agent-cache")
+	checkpoint := filepath.Join(filepath.Dir(trackingFile), "marketplace-resume", "gone", "agent.md")
+	for _, path := range []string{queryCache, checkpoint} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expired := `{"entries": {"q": {"key": "q", "value": 1, "created_at": "2020-01-01T00:00:00Z", "accessed_at": "2020-01-01T00:00:00Z"}}}`
+	if err := os.WriteFile(queryCache, []byte(expired), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(checkpoint, []byte("agent"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, action := range []string{"clear", "gc"} {
+		sharedCtx := NewSharedContext(&SharedOptions{ConfigFile: configPath, NoProgress: true, DryRun: true})
+		cmd := NewCacheCommand()
+		cmd.action = action
+		if err := cmd.Execute(sharedCtx); err != nil {
+			t.Fatalf("cache %s --dry-run failed: %v", action, err)
+		}
+		for _, path := range []string{queryCache, checkpoint} {
+			if _, err := os.Stat(path); err != nil {
+				t.Errorf("Expected cache %s --dry-run to keep %s: %v", action, path, err)
+			}
+		}
+	}
+	if kept, _ := os.ReadFile(queryCache); string(kept) != expired {
+		t.Errorf("Expected cache gc --dry-run to leave the query cache untouched, got %s", kept)
+	}
+}
+
+func TestApplyPruneKeepsSyntheticSources(t *testing.T) {
+	dir := t.TempDir()
+	sourceDir := filepath.Join(dir, "src")
+	targetDir := filepath.Join(dir, "agents")
+	for _, d := range []string{sourceDir, targetDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	agent := []byte("---\nname: helper\ndescription: Helps\n---\nPrompt\n")
+	if err := os.WriteFile(filepath.Join(sourceDir, "helper.md"), agent, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := filepath.Join(dir, "agents-config.yaml")
+	trackingFile := filepath.Join(dir, ".installed.json")
+	content := fmt.Sprintf(`version: "1.0"
+settings:
+  base_dir: %s
+  conflict_strategy: overwrite
+  backup_dir: %s
+sources:
+  - name: local
+    enabled: true
+    type: local
+    paths:
+      source: %s
+      target: %s
+metadata:
+  tracking_file: %s
+`, targetDir, filepath.Join(dir, "backups"), sourceDir, targetDir, trackingFile)
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	track := tracker.New(trackingFile)
+	for source, name := range map[string]string{
+		tracker.ManualSource:      "pasted.md",
+		tracker.MarketplaceSource: "market.md",
+		"removed":                 "stale.md",
+	} {
+		path := filepath.Join(targetDir, name)
+		if err := os.WriteFile(path, agent, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := track.AddFile(source, tracker.FileInfo{Path: path, Size: int64(len(agent)), Modified: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sharedCtx := NewSharedContext(&SharedOptions{ConfigFile: configPath, NoProgress: true})
+	sharedCtx.mutating = true
+	cmd := NewApplyCommand()
+	cmd.prune = true
+	if err := cmd.Execute(sharedCtx); err != nil {
+		t.Fatalf("apply --prune failed: %v", err)
+	}
+
+	for _, name := range []string{"pasted.md", "market.md", "helper.md"} {
+		if _, err := os.Stat(filepath.Join(targetDir, name)); err != nil {
+			t.Errorf("Expected %s to be kept: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "stale.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected the removed source's stale.md to be uninstalled, got %v", err)
+	}
+	installations, err := track.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, source := range []string{tracker.ManualSource, tracker.MarketplaceSource} {
+		if _, ok := installations[source]; !ok {
+			t.Errorf("Expected source %s to stay tracked", source)
+		}
+	}
+}
+
+func TestImportRefusesReadonlyPaths(t *testing.T) {
+	dir := t.TempDir()
+	targetDir := filepath.Join(dir, "agents")
+	configPath := filepath.Join(dir, "agents-config.yaml")
+	content := fmt.Sprintf(`version: "1.0"
+settings:
+  base_dir: %s
+  conflict_strategy: overwrite
+  backup_dir: %s
+  readonly_paths:
+    - core/*.md
+sources:
+  - name: local
+    enabled: true
+    type: local
+    paths:
+      source: %s
+      target: %s
+metadata:
+  tracking_file: %s
+`, targetDir, filepath.Join(dir, "backups"), dir, targetDir, filepath.Join(dir, ".installed.json"))
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := bundle.New()
+	for _, path := range []string{"helper.md", "core/guard.md"} {
+		name := strings.TrimSuffix(filepath.Base(path), ".md")
+		agent := []byte(fmt.Sprintf("---\nname: %s\ndescription: Helps\n---\nPrompt\n", name))
+		if err := b.Add(bundle.Agent{Name: name, Path: path}, agent); err != nil {
+			t.Fatal(err)
+		}
+	}
+	archive := filepath.Join(dir, "agents.tar.gz")
+	if err := b.Write(archive); err != nil {
+		t.Fatal(err)
+	}
+
+	sharedCtx := NewSharedContext(&SharedOptions{ConfigFile: configPath, NoProgress: true})
+	sharedCtx.mutating = true
+	err := NewImportCommand().Execute(sharedCtx, archive)
+	if err == nil || !strings.Contains(err.Error(), "readonly_paths") {
+		t.Fatalf("Expected import to refuse the readonly agent, got %v", err)
+	}
+	for _, path := range []string{"helper.md", "core/guard.md"} {
+		if _, err := os.Stat(filepath.Join(targetDir, filepath.FromSlash(path))); !os.IsNotExist(err) {
+			t.Errorf("Expected %s not to be imported, got %v", path, err)
+		}
+	}
+}
+
+func TestImportTracksMergedContent(t *testing.T) {
+	dir := t.TempDir()
+	targetDir := filepath.Join(dir, "agents")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(targetDir, "helper.md")
+	if err := os.WriteFile(target, []byte("---\nname: helper\ndescription: Local\n---\nLocal prompt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "agents-config.yaml")
+	trackingFile := filepath.Join(dir, ".installed.json")
+	content := fmt.Sprintf(`version: "1.0"
+settings:
+  base_dir: %s
+  conflict_strategy: overwrite
+  backup_dir: %s
+sources:
+  - name: local
+    enabled: true
+    type: local
+    paths:
+      source: %s
+      target: %s
+metadata:
+  tracking_file: %s
+`, targetDir, filepath.Join(dir, "backups"), dir, targetDir, trackingFile)
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := bundle.New()
+	if err := b.Add(bundle.Agent{Name: "helper", Path: "helper.md"}, []byte("---\nname: helper\ndescription: Helps\n---\nPrompt\n")); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(dir, "agents.tar.gz")
+	if err := b.Write(archive); err != nil {
+		t.Fatal(err)
+	}
+
+	sharedCtx := NewSharedContext(&SharedOptions{ConfigFile: configPath, NoProgress: true})
+	sharedCtx.mutating = true
+	cmd := NewImportCommand()
+	cmd.conflictStrategy = "merge"
+	if err := cmd.Execute(sharedCtx, archive); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+
+	_, info, err := tracker.New(trackingFile).FindFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := util.FileSHA256(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Hash != hash {
+		t.Errorf("Expected the tracked hash to match the merged file, got %s want %s", info.Hash, hash)
+	}
+}
+
+func TestDiscoverConfigUserScopeKeepsRepository(t *testing.T) {
+	home := t.TempDir()
+	repo := t.TempDir()
+	home, _ = filepath.EvalSymlinks(home)
+	repo, _ = filepath.EvalSymlinks(repo)
+	t.Setenv("HOME", home)
+	t.Setenv(config.ProjectDirEnv, "")
+	userConfig := filepath.Join(home, ".claude", config.DefaultConfigFile)
+	if err := os.MkdirAll(filepath.Dir(userConfig), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(userConfig, []byte("version: \"1.0\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, wantDir := range map[string]string{"githook": repo, "list": home} {
+		t.Chdir(repo)
+		sharedCtx := NewSharedContext(&SharedOptions{ConfigFile: config.DefaultConfigFile, NoProgress: true})
+		cmd := &cobra.Command{Use: name}
+		cmd.Flags().String("config", config.DefaultConfigFile, "")
+		if err := sharedCtx.discoverConfig(cmd, nil); err != nil {
+			t.Fatal(err)
+		}
+		if sharedCtx.Options.ConfigFile != userConfig {
+			t.Errorf("%s: ConfigFile = %s, want %s", name, sharedCtx.Options.ConfigFile, userConfig)
+		}
+		if cwd, _ := os.Getwd(); cwd != wantDir {
+			t.Errorf("%s: expected to run from %s, got %s", name, wantDir, cwd)
+		}
+	}
+}
+
+func TestGithookUninstallFollowsDryRunPolicy(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, out)
+	}
+	t.Chdir(repo)
+	configPath := filepath.Join(repo, config.DefaultConfigFile)
+	content := fmt.Sprintf(`version: "1.0"
+settings:
+  base_dir: %s
+  default_dry_run: true
+sources:
+  - name: local
+    enabled: true
+    type: local
+    paths:
+      source: %s
+      target: %s
+`, filepath.Join(repo, "agents"), repo, filepath.Join(repo, "agents"))
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	hooksDir := filepath.Join(repo, ".git", "hooks")
+	for _, hook := range githook.Hooks {
+		if err := githook.Install(hooksDir, hook, githook.Block(hook, "agent-manager", config.DefaultConfigFile)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sharedCtx := NewSharedContext(&SharedOptions{ConfigFile: configPath, NoProgress: true})
+	cmd := NewGithookCommand()
+	cmd.action = "uninstall"
+	if err := cmd.Execute(sharedCtx); err != nil {
+		t.Fatalf("githook uninstall failed: %v", err)
+	}
+	if !sharedCtx.PlannedOnly() {
+		t.Error("Expected githook uninstall to only plan under default_dry_run")
+	}
+	for _, hook := range githook.Hooks {
+		if !githook.Installed(hooksDir, hook) {
+			t.Errorf("Expected the %s hook to be kept in plan mode", hook)
+		}
+	}
+}
+
+func TestDiscoverConfigKeepsTypedPaths(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.ProjectDirEnv, "")
+	sub := filepath.Join(root, "docs", "notes")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, config.DefaultConfigFile), []byte("version: \"1.0\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "mine.md"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Resolve symlinks such as macOS's /var -> /private/var before comparing
+	root, _ = filepath.EvalSymlinks(root)
+	sub = filepath.Join(root, "docs", "notes")
+	t.Chdir(sub)
+
+	var output, tmpl string
+	var settings []string
+	cmd := &cobra.Command{Use: "test", Annotations: pathArgs(pathFile, pathExisting, pathExisting)}
+	cmd.Flags().StringVar(&output, "output", "", "")
+	cmd.Flags().StringVar(&tmpl, "template", "", "")
+	cmd.Flags().StringSliceVar(&settings, "settings", nil, "")
+	cmd.Flags().String("config", config.DefaultConfigFile, "")
+	markPathFlag(cmd, "output", pathFile)
+	markPathFlag(cmd, "template", pathTemplate)
+	markPathFlag(cmd, "settings", pathFile)
+	if err := cmd.ParseFlags([]string{"--output", "out.zip", "--template", "@row.tmpl", "--settings", "a.json,../b.json"}); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"../x.zip", "mine.md", "code-reviewer.md"}
+
+	sharedCtx := NewSharedContext(&SharedOptions{ConfigFile: config.DefaultConfigFile, NoProgress: true})
+	if err := sharedCtx.discoverConfig(cmd, args); err != nil {
+		t.Fatal(err)
+	}
+
+	if cwd, _ := os.Getwd(); cwd != root {
+		t.Errorf("Expected the command to run from %s, got %s", root, cwd)
+	}
+	if want := filepath.Join(root, config.DefaultConfigFile); sharedCtx.Options.ConfigFile != want {
+		t.Errorf("ConfigFile = %s, want %s", sharedCtx.Options.ConfigFile, want)
+	}
+	checks := []struct{ got, want string }{
+		{output, filepath.Join(sub, "out.zip")},
+		{tmpl, "@" + filepath.Join(sub, "row.tmpl")},
+		{settings[0], filepath.Join(sub, "a.json")},
+		{settings[1], filepath.Join(root, "docs", "b.json")},
+		{args[0], filepath.Join(root, "docs", "x.zip")},
+		{args[1], filepath.Join(sub, "mine.md")},
+		// A name that is not a file is left for the command to look up
+		{args[2], "code-reviewer.md"},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("Got %s, want %s", check.got, check.want)
+		}
+	}
+}
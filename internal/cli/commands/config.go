@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigCommand implements reading and writing single configuration values
+type ConfigCommand struct {
+	action string
+}
+
+// NewConfigCommand creates a new config command instance
+func NewConfigCommand() *ConfigCommand {
+	return &ConfigCommand{}
+}
+
+// Name returns the command name
+func (c *ConfigCommand) Name() string {
+	return "config"
+}
+
+// Description returns the command description
+func (c *ConfigCommand) Description() string {
+	return "Read or write a single configuration value"
+}
+
+// CreateCommand creates the cobra command for config functionality
+func (c *ConfigCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config <get|set> KEY [VALUE]",
+		Short: c.Description(),
+		Long: `Read or write one configuration value by its dotted key, so scripts need not
+parse the configuration file. Sources and other lists are addressed by name or
+position, as in sources.community.enabled or sources.0.branch.
+
+get prints the effective value, defaults included: scalars as they are and
+sections as YAML.
+
+set parses VALUE as YAML, validates the edited configuration before writing
+it and keeps the rest of the file, comments included, intact. The previous
+version of the file is copied to <backup_dir>/config/<timestamp>/ first.
+
+Examples:
+  agent-manager config get settings.base_dir
+  agent-manager config get sources.community
+  agent-manager config set settings.conflict_strategy merge
+  agent-manager config set sources.community.enabled false
+  agent-manager config set settings.query.weights.name 5`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("requires an action: get or set")
+			}
+			switch args[0] {
+			case "get":
+				return cobra.ExactArgs(2)(cmd, args)
+			case "set":
+				return cobra.ExactArgs(3)(cmd, args)
+			default:
+				return fmt.Errorf("unknown config action: %s", args[0])
+			}
+		},
+		ValidArgs: []string{"get", "set"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c.action = args[0]
+			return c.Execute(sharedCtx, args[1:])
+		},
+	}
+
+	return cmd
+}
+
+// Execute runs the config command logic
+func (c *ConfigCommand) Execute(sharedCtx *SharedContext, args []string) error {
+	// Only set changes files, so only set follows settings.default_dry_run
+	sharedCtx.mutating = c.action == "set"
+
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	switch c.action {
+	case "get":
+		return c.executeGet(sharedCtx, args[0])
+	case "set":
+		return c.executeSet(sharedCtx, args[0], args[1])
+	default:
+		return fmt.Errorf("unknown config action: %s", c.action)
+	}
+}
+
+// executeGet prints the effective value of a key
+func (c *ConfigCommand) executeGet(sharedCtx *SharedContext, key string) error {
+	value, err := config.Lookup(sharedCtx.Config, key)
+	if err != nil {
+		return err
+	}
+	output, err := formatConfigValue(value)
+	if err != nil {
+		return err
+	}
+	fmt.Println(output)
+	return nil
+}
+
+// executeSet writes a key to the configuration file, backing up the
+// previous version
+func (c *ConfigCommand) executeSet(sharedCtx *SharedContext, key, value string) error {
+	path := sharedCtx.Options.ConfigFile
+	previous, err := config.Lookup(sharedCtx.Config, key)
+	if err != nil {
+		return err
+	}
+	before, err := formatConfigValue(previous)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		before = "(unset)"
+	}
+
+	if sharedCtx.Options.DryRun {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+		if _, err := config.EditValue(content, key, value); err != nil {
+			return err
+		}
+		color.Yellow("[DRY RUN] Would set %s: %s -> %s\n", key, before, value)
+		return nil
+	}
+
+	backup, err := config.SetValue(path, key, value, filepath.Join(sharedCtx.Config.Settings.BackupDir, "config"))
+	if err != nil {
+		return err
+	}
+	PrintSuccess("Set %s: %s -> %s", key, before, value)
+	PrintInfo("Previous configuration saved to %s", backup)
+	return nil
+}
+
+// formatConfigValue renders a configuration value: strings and durations as
+// they are, everything else as YAML
+func formatConfigValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case time.Duration:
+		return v.String(), nil
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return "", fmt.Errorf("failed to format value: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to format value: %w", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
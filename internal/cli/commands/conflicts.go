@@ -0,0 +1,321 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/conflict"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/spf13/cobra"
+)
+
+// errResolveAborted is returned when the user quits resolving a file
+var errResolveAborted = errors.New("resolution aborted; file left unchanged")
+
+// ConflictsCommand implements finding and resolving merge conflict markers
+type ConflictsCommand struct {
+	action string
+	take   string
+}
+
+// NewConflictsCommand creates a new conflicts command instance
+func NewConflictsCommand() *ConflictsCommand {
+	return &ConflictsCommand{}
+}
+
+// Name returns the command name
+func (c *ConflictsCommand) Name() string {
+	return "conflicts"
+}
+
+// Description returns the command description
+func (c *ConflictsCommand) Description() string {
+	return "Find and resolve merge conflict markers in installed files"
+}
+
+// CreateCommand creates the cobra command for conflicts functionality
+func (c *ConflictsCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "conflicts <list|resolve> [FILE]",
+		Short: c.Description(),
+		Long: `Finish merges left unfinished by the merge conflict strategy.
+
+list shows the files under the base directory that contain conflict markers.
+
+resolve walks through the conflicts in FILE one at a time, showing the current
+and incoming lines, and asks which to keep: current, incoming, both, or skip to
+leave the markers in place. Agent files are re-validated before the result is
+written. --take resolves every conflict the same way without asking.
+
+Examples:
+  agent-manager conflicts list
+  agent-manager conflicts resolve code-reviewer.md
+  agent-manager conflicts resolve .claude/agents/code-reviewer.md --take incoming`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("requires an action: list or resolve")
+			}
+			switch args[0] {
+			case "list":
+				return cobra.ExactArgs(1)(cmd, args)
+			case "resolve":
+				return cobra.ExactArgs(2)(cmd, args)
+			default:
+				return fmt.Errorf("unknown conflicts action: %s", args[0])
+			}
+		},
+		ValidArgs:   []string{"list", "resolve"},
+		Annotations: pathArgs("", pathExisting),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c.action = args[0]
+			return c.Execute(sharedCtx, args[1:])
+		},
+	}
+
+	cmd.Flags().StringVar(&c.take, "take", "", "resolve every conflict without asking (current|incoming|both)")
+
+	return cmd
+}
+
+// Execute runs the conflicts command logic
+func (c *ConflictsCommand) Execute(sharedCtx *SharedContext, args []string) error {
+	// Only resolve changes files, so only resolve follows settings.default_dry_run
+	sharedCtx.mutating = c.action == "resolve"
+
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	switch c.action {
+	case "list":
+		return c.executeList(sharedCtx)
+	case "resolve":
+		return c.executeResolve(sharedCtx, args[0], os.Stdin, os.Stdout)
+	default:
+		return fmt.Errorf("unknown conflicts action: %s", c.action)
+	}
+}
+
+// executeList prints the files with conflict markers under the base directory
+func (c *ConflictsCommand) executeList(sharedCtx *SharedContext) error {
+	baseDir := sharedCtx.GetAgentsDirectory()
+	files, err := conflict.FindMarked(baseDir)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		PrintSuccess("No files with conflict markers under %s", baseDir)
+		return nil
+	}
+
+	fmt.Printf("Files with conflict markers (%d):\n", len(files))
+	for _, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		marked, err := conflict.ParseMarkers(content)
+		if err != nil {
+			fmt.Printf("  %s (malformed markers: %v)\n", path, err)
+			continue
+		}
+		fmt.Printf("  %s (%d conflicts)\n", path, len(marked.Hunks))
+	}
+	PrintInfo("Run 'agent-manager conflicts resolve FILE' to resolve them")
+	return nil
+}
+
+// executeResolve resolves the conflicts in one file, from --take or by
+// asking on in, and writes the result once it validates
+func (c *ConflictsCommand) executeResolve(sharedCtx *SharedContext, file string, in io.Reader, out io.Writer) error {
+	var take conflict.Side
+	if c.take != "" {
+		side, err := conflict.ParseSide(c.take)
+		if err != nil {
+			return err
+		}
+		take = side
+	} else if quietMode {
+		return fmt.Errorf("interactive resolution is not available with --quiet; use --take")
+	}
+
+	path := c.resolvePath(sharedCtx, file)
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	marked, err := conflict.ParseMarkers(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if len(marked.Hunks) == 0 {
+		PrintSuccess("No conflict markers in %s", path)
+		return nil
+	}
+
+	reader := bufio.NewReader(in)
+	if take != "" {
+		for _, hunk := range marked.Hunks {
+			hunk.Resolution = take
+		}
+	} else if err := promptHunks(marked, reader, out); err != nil {
+		return err
+	}
+
+	resolved := len(marked.Hunks) - marked.Unresolved()
+	if resolved == 0 {
+		PrintInfo("No conflicts resolved; %s left unchanged", path)
+		return nil
+	}
+
+	result := marked.Bytes()
+	if marked.Unresolved() == 0 && strings.EqualFold(filepath.Ext(path), ".md") {
+		if !c.checkResolved(sharedCtx, path, result) {
+			if take != "" || !confirmOn(reader, out, "Write the file anyway?") {
+				return fmt.Errorf("resolved %s is not a valid agent; file left unchanged", path)
+			}
+		}
+	}
+
+	if sharedCtx.Options.DryRun {
+		color.Yellow("[DRY RUN] Would resolve %d of %d conflicts in %s\n", resolved, len(marked.Hunks), path)
+		return nil
+	}
+
+	if err := os.WriteFile(path, result, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	refreshTracking(sharedCtx, []string{path})
+	refreshIndex(sharedCtx)
+
+	PrintSuccess("Resolved %d of %d conflicts in %s", resolved, len(marked.Hunks), path)
+	if left := marked.Unresolved(); left > 0 {
+		PrintWarning("%d conflicts left in %s", left, path)
+	}
+	return nil
+}
+
+// resolvePath finds file as given or, failing that, under the base directory
+func (c *ConflictsCommand) resolvePath(sharedCtx *SharedContext, file string) string {
+	if _, err := os.Stat(file); err == nil || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(sharedCtx.GetAgentsDirectory(), file)
+}
+
+// checkResolved parses and checks a resolved agent file, printing what is
+// wrong, and reports whether it may be written
+func (c *ConflictsCommand) checkResolved(sharedCtx *SharedContext, path string, content []byte) bool {
+	agentParser := &parser.Parser{Mode: sharedCtx.Config.Settings.Query.ParserMode}
+	agent, err := agentParser.ParseContent(content)
+	if err != nil {
+		PrintError("Resolved %s does not parse: %v", path, err)
+		return false
+	}
+	agent.FilePath = path
+	agent.FileName = filepath.Base(path)
+	agent.FileSize = int64(len(content))
+
+	validate := &ValidateCommand{}
+	toolValidator, err := validate.toolValidator(sharedCtx.Config.Settings.Query.Validation)
+	if err != nil {
+		PrintError("%v", err)
+		return false
+	}
+	check := validate.checkAgent(sharedCtx, agent, toolValidator, nil)
+	for _, message := range check.warnings {
+		PrintWarning("%s", message)
+	}
+	for _, message := range check.errors {
+		PrintError("%s", message)
+	}
+	return len(check.errors) == 0
+}
+
+// promptHunks shows each conflict and reads which side to keep
+func promptHunks(marked *conflict.Marked, reader *bufio.Reader, out io.Writer) error {
+	for n, hunk := range marked.Hunks {
+		printHunk(out, hunk, n+1, len(marked.Hunks))
+		for {
+			fmt.Fprint(out, "Keep [c]urrent, [i]ncoming, [b]oth, [s]kip, or [q]uit? ")
+			line, err := reader.ReadString('\n')
+			answer := strings.ToLower(strings.TrimSpace(line))
+			if answer == "" && err != nil {
+				fmt.Fprintln(out)
+				return errResolveAborted
+			}
+
+			switch answer {
+			case "c", "current":
+				hunk.Resolution = conflict.SideCurrent
+			case "i", "incoming":
+				hunk.Resolution = conflict.SideIncoming
+			case "b", "both":
+				hunk.Resolution = conflict.SideBoth
+			case "s", "skip":
+			case "q", "quit":
+				return errResolveAborted
+			default:
+				fmt.Fprintf(out, "Unknown choice: %q\n", answer)
+				continue
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// printHunk shows one conflict with its current, base and incoming lines
+func printHunk(out io.Writer, hunk *conflict.Hunk, n, total int) {
+	current := color.New(color.FgRed)
+	incoming := color.New(color.FgGreen)
+	label := func(name, fallback string) string {
+		if name == "" {
+			return fallback
+		}
+		return name
+	}
+
+	fmt.Fprintf(out, "\nConflict %d of %d (line %d)\n", n, total, hunk.Line)
+	fmt.Fprintf(out, "--- %s\n", label(hunk.CurrentLabel, "current"))
+	for _, line := range hunk.Current {
+		_, _ = current.Fprint(out, "- "+strings.TrimRight(line, "\r\n")+"\n")
+	}
+	if len(hunk.Base) > 0 {
+		fmt.Fprintln(out, "--- base")
+		for _, line := range hunk.Base {
+			fmt.Fprint(out, "  "+strings.TrimRight(line, "\r\n")+"\n")
+		}
+	}
+	fmt.Fprintf(out, "+++ %s\n", label(hunk.IncomingLabel, "incoming"))
+	for _, line := range hunk.Incoming {
+		_, _ = incoming.Fprint(out, "+ "+strings.TrimRight(line, "\r\n")+"\n")
+	}
+}
+
+// confirmOn asks a yes/no question on reader and returns true only for an
+// explicit yes
+func confirmOn(reader *bufio.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N]: ", question)
+	answer, err := reader.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(out)
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/marketplace/browser"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// doctorStatus is the outcome of one doctor check
+type doctorStatus int
+
+const (
+	doctorOK doctorStatus = iota
+	doctorWarn
+	doctorFail
+)
+
+// doctorCheck is the result of one doctor check, with a hint on how to fix
+// a warning or failure
+type doctorCheck struct {
+	name   string
+	status doctorStatus
+	detail string
+	hint   string
+}
+
+// DoctorCommand implements checking the environment agent-manager runs in
+type DoctorCommand struct {
+	fix bool
+}
+
+// NewDoctorCommand creates a new doctor command instance
+func NewDoctorCommand() *DoctorCommand {
+	return &DoctorCommand{}
+}
+
+// Name returns the command name
+func (c *DoctorCommand) Name() string {
+	return "doctor"
+}
+
+// Description returns the command description
+func (c *DoctorCommand) Description() string {
+	return "Check the configuration and runtime dependencies"
+}
+
+// CreateCommand creates the cobra command for doctor functionality
+func (c *DoctorCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: c.Description(),
+		Long: `Check that the configuration loads and that the runtime dependencies are in
+place, printing a hint for each problem found.
+
+The marketplace browser check fails when subagents sources are configured and
+no browser is available; run 'agent-manager marketplace setup' to install one.
+
+The temp directory check reports directories that crashed runs left in the
+system temp directory. Only directories marked with the PID of an
+agent-manager process that is no longer running, and older than
+settings.temp_cleanup_age, are reported; --fix removes them.
+
+When metadata.signing_key is set, the tracking file check verifies the
+signature of every installation record. Records changed outside
+agent-manager fail the check until their source is reinstalled. Unsigned
+records, such as those written before signing was enabled, are reported as
+warnings; --fix signs them as they are.
+
+Examples:
+  agent-manager doctor
+  agent-manager doctor --fix`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.Execute(sharedCtx)
+		},
+	}
+
+	cmd.Flags().BoolVar(&c.fix, "fix", false, "remove temp directories left by crashed runs and sign unsigned tracking records")
+
+	return cmd
+}
+
+// Execute runs the doctor command logic
+func (c *DoctorCommand) Execute(sharedCtx *SharedContext) error {
+	checks := c.runChecks(sharedCtx)
+
+	failed := 0
+	for _, check := range checks {
+		switch check.status {
+		case doctorOK:
+			PrintSuccess("%s: %s", check.name, check.detail)
+		case doctorWarn:
+			PrintWarning("%s: %s", check.name, check.detail)
+		case doctorFail:
+			PrintError("%s: %s", check.name, check.detail)
+			failed++
+		}
+		if check.status != doctorOK && check.hint != "" {
+			fmt.Printf("    %s\n", check.hint)
+		}
+	}
+	sharedCtx.Summarize("checks", len(checks))
+	sharedCtx.Summarize("failed", failed)
+
+	if failed > 0 {
+		return fmt.Errorf("doctor found %d problems", failed)
+	}
+	return nil
+}
+
+// runChecks runs every check in order
+func (c *DoctorCommand) runChecks(sharedCtx *SharedContext) []doctorCheck {
+	return []doctorCheck{
+		c.checkConfig(sharedCtx),
+		c.checkBrowser(sharedCtx),
+		c.checkTempDirs(sharedCtx),
+		c.checkTracker(sharedCtx),
+	}
+}
+
+// checkConfig loads and validates the configuration
+func (c *DoctorCommand) checkConfig(sharedCtx *SharedContext) doctorCheck {
+	check := doctorCheck{name: "Configuration"}
+	if err := sharedCtx.LoadConfig(); err != nil {
+		check.status = doctorFail
+		check.detail = err.Error()
+		check.hint = "Fix the configuration file, or run 'agent-manager init' to create one"
+		return check
+	}
+	check.detail = fmt.Sprintf("%s (%d sources)", sharedCtx.Options.ConfigFile, len(sharedCtx.Config.Sources))
+	return check
+}
+
+// checkBrowser finds the headless browser used by the marketplace and
+// subagents sources. A missing browser only fails when such a source is
+// configured.
+func (c *DoctorCommand) checkBrowser(sharedCtx *SharedContext) doctorCheck {
+	check := doctorCheck{name: "Marketplace browser"}
+	path, pinned, err := browser.Locate()
+	if err == nil {
+		if pinned != nil {
+			check.detail = fmt.Sprintf("pinned headless shell %s at %s", pinned.Version, path)
+		} else {
+			check.detail = fmt.Sprintf("system browser at %s (run 'agent-manager marketplace setup' to pin one)", path)
+		}
+		return check
+	}
+
+	check.status = doctorWarn
+	if sharedCtx.Config != nil {
+		for _, source := range sharedCtx.Config.Sources {
+			if source.Type == "subagents" && source.Enabled {
+				check.status = doctorFail
+				break
+			}
+		}
+	}
+	check.detail = err.Error()
+	if errors.Is(err, browser.ErrNotSupported) {
+		check.hint = "Use a build with marketplace support for subagents sources"
+	} else {
+		check.hint = "Run 'agent-manager marketplace setup' to download and pin a headless browser"
+	}
+	return check
+}
+
+// checkTempDirs finds temp directories left behind by crashed runs and, with
+// --fix, removes them
+func (c *DoctorCommand) checkTempDirs(sharedCtx *SharedContext) doctorCheck {
+	check := doctorCheck{name: "Temp directories"}
+	maxAge := util.DefaultTempCleanupAge
+	if sharedCtx.Config != nil && sharedCtx.Config.Settings.TempCleanupAge > 0 {
+		maxAge = sharedCtx.Config.Settings.TempCleanupAge
+	}
+
+	stale, err := util.FindStaleTempDirs(maxAge)
+	if err != nil {
+		check.status = doctorWarn
+		check.detail = err.Error()
+		return check
+	}
+	var size int64
+	for _, dir := range stale {
+		size += dir.Size
+	}
+	if len(stale) == 0 {
+		check.detail = "no directories left by crashed runs"
+		return check
+	}
+
+	if !c.fix {
+		check.status = doctorWarn
+		check.detail = fmt.Sprintf("%d directories left by crashed runs use %s", len(stale), formatBytes(size))
+		check.hint = "Run 'agent-manager doctor --fix' to remove them"
+		return check
+	}
+	if sharedCtx.Options.DryRun {
+		check.detail = fmt.Sprintf("would remove %d directories left by crashed runs, reclaiming %s", len(stale), formatBytes(size))
+		return check
+	}
+	reclaimed, err := util.RemoveStaleTempDirs(stale)
+	if err != nil {
+		check.status = doctorFail
+		check.detail = err.Error()
+		check.hint = "Remove the directory by hand"
+		return check
+	}
+	check.detail = fmt.Sprintf("removed %d directories left by crashed runs, reclaiming %s", len(stale), formatBytes(reclaimed))
+	return check
+}
+
+// checkTracker verifies the signatures of the tracking file entries and, with
+// --fix, signs the unsigned ones
+func (c *DoctorCommand) checkTracker(sharedCtx *SharedContext) doctorCheck {
+	check := doctorCheck{name: "Tracking file"}
+	if sharedCtx.Config == nil {
+		check.status = doctorWarn
+		check.detail = "skipped, the configuration did not load"
+		return check
+	}
+	track := sharedCtx.Tracker()
+	if !track.Signed() {
+		check.detail = "records are not signed (metadata.signing_key is not set)"
+		return check
+	}
+
+	issues, err := track.Verify()
+	if err != nil {
+		check.status = doctorFail
+		check.detail = err.Error()
+		return check
+	}
+	var modified, unsigned []string
+	for _, issue := range issues {
+		if issue.Problem == tracker.IntegrityModified {
+			modified = append(modified, issue.Source)
+		} else {
+			unsigned = append(unsigned, issue.Source)
+		}
+	}
+
+	if len(unsigned) > 0 && c.fix && !sharedCtx.Options.DryRun {
+		if err := track.Resign(unsigned); err != nil {
+			check.status = doctorFail
+			check.detail = err.Error()
+			return check
+		}
+		PrintInfo("Signed %d unsigned tracking records: %s", len(unsigned), strings.Join(unsigned, ", "))
+		unsigned = nil
+	}
+
+	switch {
+	case len(modified) > 0:
+		check.status = doctorFail
+		check.detail = fmt.Sprintf("records modified outside agent-manager: %s", strings.Join(modified, ", "))
+		check.hint = "Review the tracking file, then reinstall the sources with 'agent-manager install --source <name>'"
+	case len(unsigned) > 0 && c.fix:
+		check.detail = fmt.Sprintf("would sign unsigned records: %s", strings.Join(unsigned, ", "))
+	case len(unsigned) > 0:
+		check.status = doctorWarn
+		check.detail = fmt.Sprintf("unsigned records: %s", strings.Join(unsigned, ", "))
+		check.hint = "Run 'agent-manager doctor --fix' to sign them as they are"
+	default:
+		check.detail = "all records verified"
+	}
+	return check
+}
@@ -0,0 +1,273 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/archive"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/spf13/cobra"
+)
+
+// DisableCommand implements hiding agents from Claude Code by archiving them
+type DisableCommand struct {
+	collection string
+}
+
+// NewDisableCommand creates a new disable command instance
+func NewDisableCommand() *DisableCommand {
+	return &DisableCommand{}
+}
+
+// Name returns the command name
+func (c *DisableCommand) Name() string {
+	return "disable"
+}
+
+// Description returns the command description
+func (c *DisableCommand) Description() string {
+	return "Disable agents by name or collection"
+}
+
+// CreateCommand creates the cobra command for disable functionality
+func (c *DisableCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disable [AGENT...]",
+		Short: c.Description(),
+		Long: `Disable agents, given by name, namespaced name or file name, or every agent
+of a collection defined in the configuration, so Claude Code no longer loads
+them. Disabled agents are moved into the archive like archive does, keeping
+their metadata, and enable brings them back. Every copy of a named agent is
+disabled.
+
+Examples:
+  agent-manager disable data-scientist
+  agent-manager disable --collection review-kit`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.Execute(sharedCtx, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&c.collection, "collection", "", "disable the agents of a collection defined in the configuration")
+
+	return cmd
+}
+
+// Execute runs the disable command logic
+func (c *DisableCommand) Execute(sharedCtx *SharedContext, args []string) error {
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	names, err := agentNames(sharedCtx, args, c.collection)
+	if err != nil {
+		return err
+	}
+
+	queryEngine, err := sharedCtx.CreateQueryEngine()
+	if err != nil {
+		return err
+	}
+	agents, missing := findNamedAgents(queryEngine.GetAllAgents(), names)
+	for _, name := range missing {
+		PrintWarning("No enabled agent named %s", name)
+	}
+	sharedCtx.Summarize("missing", len(missing))
+	if len(agents) == 0 {
+		sharedCtx.Summarize("disabled", 0)
+		return nil
+	}
+
+	if sharedCtx.Options.DryRun {
+		for _, agent := range agents {
+			fmt.Printf("  %s (%s)\n", agent.QualifiedName(), agent.FilePath)
+		}
+		color.Yellow("[DRY RUN] Would disable %d agents\n", len(agents))
+		return nil
+	}
+
+	store := archive.New(archive.DefaultDir(sharedCtx.Config.Metadata.TrackingFile))
+	disabled := 0
+	for _, agent := range agents {
+		if _, err := store.Archive(agent); err != nil {
+			PrintError("%v", err)
+			continue
+		}
+		disabled++
+		if sharedCtx.Options.Verbose {
+			fmt.Printf("Disabled: %s\n", agent.FilePath)
+		}
+	}
+
+	refreshIndex(sharedCtx)
+
+	sharedCtx.Summarize("disabled", disabled)
+	PrintSuccess("Disabled %d agents", disabled)
+	if disabled < len(agents) {
+		return fmt.Errorf("failed to disable %d agents", len(agents)-disabled)
+	}
+	return nil
+}
+
+// EnableCommand implements restoring disabled agents
+type EnableCommand struct {
+	collection string
+}
+
+// NewEnableCommand creates a new enable command instance
+func NewEnableCommand() *EnableCommand {
+	return &EnableCommand{}
+}
+
+// Name returns the command name
+func (c *EnableCommand) Name() string {
+	return "enable"
+}
+
+// Description returns the command description
+func (c *EnableCommand) Description() string {
+	return "Enable disabled agents by name or collection"
+}
+
+// CreateCommand creates the cobra command for enable functionality
+func (c *EnableCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enable [AGENT...]",
+		Short: c.Description(),
+		Long: `Enable agents disabled with disable or archived with archive, given by name,
+namespaced name or file name, or every agent of a collection defined in the
+configuration, restoring them to the location they were disabled from.
+
+Examples:
+  agent-manager enable data-scientist
+  agent-manager enable --collection review-kit`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.Execute(sharedCtx, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&c.collection, "collection", "", "enable the agents of a collection defined in the configuration")
+
+	return cmd
+}
+
+// Execute runs the enable command logic
+func (c *EnableCommand) Execute(sharedCtx *SharedContext, args []string) error {
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	names, err := agentNames(sharedCtx, args, c.collection)
+	if err != nil {
+		return err
+	}
+
+	store := archive.New(archive.DefaultDir(sharedCtx.Config.Metadata.TrackingFile))
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	var selected []*archive.Entry
+	var missing []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		found := false
+		for _, entry := range entries {
+			if !entry.Agent.HasName(name) {
+				continue
+			}
+			found = true
+			if !seen[entry.Original] {
+				seen[entry.Original] = true
+				selected = append(selected, entry)
+			}
+		}
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+	for _, name := range missing {
+		PrintWarning("No disabled agent named %s", name)
+	}
+	sharedCtx.Summarize("missing", len(missing))
+	if len(selected) == 0 {
+		sharedCtx.Summarize("enabled", 0)
+		return nil
+	}
+
+	if sharedCtx.Options.DryRun {
+		for _, entry := range selected {
+			fmt.Printf("  %s -> %s\n", entry.Agent.QualifiedName(), entry.Original)
+		}
+		color.Yellow("[DRY RUN] Would enable %d agents\n", len(selected))
+		return nil
+	}
+
+	enabled := 0
+	for _, entry := range selected {
+		if _, err := store.Restore(entry.Original); err != nil {
+			PrintError("%v", err)
+			continue
+		}
+		enabled++
+	}
+
+	refreshIndex(sharedCtx)
+
+	sharedCtx.Summarize("enabled", enabled)
+	PrintSuccess("Enabled %d agents", enabled)
+	if enabled < len(selected) {
+		return fmt.Errorf("failed to enable %d agents", len(selected)-enabled)
+	}
+	return nil
+}
+
+// agentNames returns the agents named in args followed by the members of
+// collection, without duplicates
+func agentNames(sharedCtx *SharedContext, args []string, collection string) ([]string, error) {
+	names := append([]string(nil), args...)
+	if collection != "" {
+		members, err := sharedCtx.Config.Collection(collection)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, members...)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("specify agents or --collection")
+	}
+
+	seen := make(map[string]bool, len(names))
+	unique := names[:0]
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique, nil
+}
+
+// findNamedAgents returns every agent one of names refers to, as
+// AgentSpec.HasName matches, and the names matching no agent
+func findNamedAgents(agents []*parser.AgentSpec, names []string) ([]*parser.AgentSpec, []string) {
+	var found []*parser.AgentSpec
+	var missing []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		matched := false
+		for _, agent := range agents {
+			if !agent.HasName(name) {
+				continue
+			}
+			matched = true
+			if !seen[agent.FilePath] {
+				seen[agent.FilePath] = true
+				found = append(found, agent)
+			}
+		}
+		if !matched {
+			missing = append(missing, name)
+		}
+	}
+	return found, missing
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+// ExplainCommand implements explaining how install treats a single source file
+type ExplainCommand struct {
+	sourceName string
+	output     string
+}
+
+// NewExplainCommand creates a new explain command instance
+func NewExplainCommand() *ExplainCommand {
+	return &ExplainCommand{}
+}
+
+// Name returns the command name
+func (c *ExplainCommand) Name() string {
+	return "explain"
+}
+
+// Description returns the command description
+func (c *ExplainCommand) Description() string {
+	return "Explain the filter, transformation and conflict decisions for a file"
+}
+
+// CreateCommand creates the cobra command for explain functionality
+func (c *ExplainCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "explain <file>",
+		Short: c.Description(),
+		Long: `Walk a file of a source through everything install would do to it: the
+include and exclude filters, each transformation, the size limit and license
+policy for agents, and the conflict strategy for an existing target file.
+Each decision is reported with the rule that made it. The file is a path
+relative to the source directory. The source is fetched to a temporary
+directory; nothing is installed.
+
+Examples:
+  agent-manager explain --source team-agents engineering/code-reviewer.md
+  agent-manager explain --source team-agents README.md --output json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.Execute(sharedCtx, args[0])
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.sourceName, "source", "s", "", "source the file comes from")
+	cmd.Flags().StringVarP(&c.output, "output", "o", "text", "output format (text, json)")
+	_ = cmd.MarkFlagRequired("source")
+
+	return cmd
+}
+
+// Execute runs the explain command logic
+func (c *ExplainCommand) Execute(sharedCtx *SharedContext, file string) error {
+	if c.output != "text" && c.output != "json" {
+		return fmt.Errorf("invalid output format: %s (must be text or json)", c.output)
+	}
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	source, err := sharedCtx.GetSourceByName(c.sourceName)
+	if err != nil {
+		return err
+	}
+	inst, err := sharedCtx.CreateInstaller()
+	if err != nil {
+		return err
+	}
+
+	explanation, err := inst.Explain(sharedCtx.Context(), *source, file)
+	if err != nil {
+		return fmt.Errorf("failed to explain %s: %w", file, err)
+	}
+	sharedCtx.Summarize("source", source.Name)
+	sharedCtx.Summarize("installed", explanation.Installed)
+
+	if c.output == "json" {
+		content, err := json.MarshalIndent(explanation, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal explanation: %w", err)
+		}
+		fmt.Println(string(content))
+		return nil
+	}
+	displayExplanation(explanation)
+	return nil
+}
+
+// displayExplanation prints each decision and the final outcome
+func displayExplanation(explanation *installer.Explanation) {
+	fmt.Printf("%s from source %s\n", explanation.File, explanation.Source)
+	for _, decision := range explanation.Decisions {
+		line := fmt.Sprintf("  %-10s %s", decision.Stage, decision.Result)
+		if decision.Rule != "" {
+			line += " (" + decision.Rule + ")"
+		}
+		fmt.Println(line)
+	}
+
+	if explanation.Installed {
+		color.Green("\nInstalled to %s\n", explanation.Target)
+	} else {
+		color.Yellow("\nNot installed\n")
+	}
+}
@@ -0,0 +1,507 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/bundle"
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/conflict"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// ExportCommand implements bundling installed agents into a portable archive
+type ExportCommand struct {
+	output  string
+	sources []string
+	query   string
+}
+
+// NewExportCommand creates a new export command instance
+func NewExportCommand() *ExportCommand {
+	return &ExportCommand{}
+}
+
+// Name returns the command name
+func (c *ExportCommand) Name() string {
+	return "export"
+}
+
+// Description returns the command description
+func (c *ExportCommand) Description() string {
+	return "Bundle installed agents into a portable archive"
+}
+
+// CreateCommand creates the cobra command for export functionality
+func (c *ExportCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [AGENT...] --output FILE",
+		Short: c.Description(),
+		Long: `Package installed agents into a tar.gz or zip archive that 'agent-manager
+import' installs on another machine. The archive holds each agent file as
+installed, frontmatter included, and a manifest recording its checksum and the
+source and commit it was installed from.
+
+Agents are selected by name, namespaced name or file name, by --source and by
+--query; an agent must match every selector given. Without selectors every
+installed agent is exported. The archive format follows the extension of
+--output: .zip writes a zip archive, anything else a gzip-compressed tar.
+
+Examples:
+  agent-manager export --output agents.tar.gz
+  agent-manager export --source team-agents --output team.zip
+  agent-manager export code-reviewer go-expert --output review.tar.gz
+  agent-manager export --query "tools:Bash" --output bash-agents.tar.gz`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.Execute(sharedCtx, args)
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.output, "output", "o", "", "archive to write, .tar.gz or .zip (required)")
+	markPathFlag(cmd, "output", pathFile)
+	cmd.Flags().StringSliceVarP(&c.sources, "source", "s", nil, "export only agents installed from these sources")
+	cmd.Flags().StringVar(&c.query, "query", "", "export only agents matching a query")
+	_ = cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+// Execute runs the export command logic
+func (c *ExportCommand) Execute(sharedCtx *SharedContext, names []string) error {
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	queryEngine, err := sharedCtx.CreateQueryEngine()
+	if err != nil {
+		return err
+	}
+	agents := queryEngine.GetAllAgents()
+	if c.query != "" {
+		if agents, err = selectAgents(queryEngine, c.query); err != nil {
+			return err
+		}
+	}
+	if len(names) > 0 {
+		if agents, err = selectNamedAgents(agents, names); err != nil {
+			return err
+		}
+	}
+
+	installations, err := sharedCtx.Tracker().List()
+	if err != nil {
+		return fmt.Errorf("failed to load installation tracking: %w", err)
+	}
+	owners := fileOwners(installations)
+
+	selected := make([]*parser.AgentSpec, 0, len(agents))
+	for _, agent := range agents {
+		if len(c.sources) == 0 || containsString(c.sources, agentSource(agent, owners)) {
+			selected = append(selected, agent)
+		}
+	}
+	if len(selected) == 0 {
+		PrintWarning("No agents to export")
+		return nil
+	}
+
+	b := bundle.New()
+	agentsDir := sharedCtx.GetAgentsDirectory()
+	for _, agent := range selected {
+		content, err := os.ReadFile(agent.FilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", agent.FilePath, err)
+		}
+		entry := bundle.Agent{
+			Name:      agent.Name,
+			Namespace: agent.Namespace,
+			Path:      bundlePath(agentsDir, agent),
+			Source:    agentSource(agent, owners),
+		}
+		if installation := installations[entry.Source]; installation != nil {
+			entry.SourceCommit = installation.SourceCommit
+			entry.InstalledAt = installation.Timestamp
+		}
+		if err := b.Add(entry, content); err != nil {
+			return err
+		}
+		if sharedCtx.Options.Verbose || sharedCtx.Options.DryRun {
+			fmt.Printf("  %s -> %s\n", agent.FilePath, entry.Path)
+		}
+	}
+
+	if sharedCtx.Options.DryRun {
+		color.Yellow("[DRY RUN] Would export %d agents to %s\n", len(selected), c.output)
+		return nil
+	}
+	if err := b.Write(c.output); err != nil {
+		return err
+	}
+
+	sharedCtx.Summarize("agents", len(selected))
+	sharedCtx.Summarize("output", c.output)
+	PrintSuccess("Exported %d agents to %s", len(selected), c.output)
+	return nil
+}
+
+// selectNamedAgents picks the agents named by name, qualified name or file
+// name; a name matching several agents must be given namespaced
+func selectNamedAgents(agents []*parser.AgentSpec, names []string) ([]*parser.AgentSpec, error) {
+	var selected []*parser.AgentSpec
+	for _, name := range names {
+		var matches []*parser.AgentSpec
+		for _, agent := range agents {
+			if agent.Name == name || agent.QualifiedName() == name || agent.FileName == name {
+				matches = append(matches, agent)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("no agent named %s", name)
+		case 1:
+			selected = append(selected, matches[0])
+		default:
+			return nil, fmt.Errorf("%s matches %d agents; use the namespaced name", name, len(matches))
+		}
+	}
+	return selected, nil
+}
+
+// fileOwners maps the absolute path of each tracked file to the source it was installed by
+func fileOwners(installations map[string]*tracker.Installation) map[string]string {
+	owners := make(map[string]string)
+	for name, installation := range installations {
+		for path := range installation.Files {
+			if absPath, err := filepath.Abs(path); err == nil {
+				owners[absPath] = name
+			}
+		}
+	}
+	return owners
+}
+
+// agentSource returns the source that installed agent, falling back to the
+// source recorded in the index for untracked files
+func agentSource(agent *parser.AgentSpec, owners map[string]string) string {
+	if absPath, err := filepath.Abs(agent.FilePath); err == nil {
+		if owner, ok := owners[absPath]; ok {
+			return owner
+		}
+	}
+	return agent.Source
+}
+
+// bundlePath returns the slash path of agent relative to the agents
+// directory, or namespace/file name for agents outside it
+func bundlePath(agentsDir string, agent *parser.AgentSpec) string {
+	absDir, dirErr := filepath.Abs(agentsDir)
+	absPath, pathErr := filepath.Abs(agent.FilePath)
+	if dirErr == nil && pathErr == nil {
+		if rel, err := filepath.Rel(absDir, absPath); err == nil && filepath.IsLocal(rel) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	if agent.Namespace != "" {
+		return agent.Namespace + "/" + agent.FileName
+	}
+	return agent.FileName
+}
+
+// ImportCommand implements installing agents from an export archive
+type ImportCommand struct {
+	source           string
+	conflictStrategy string
+}
+
+// NewImportCommand creates a new import command instance
+func NewImportCommand() *ImportCommand {
+	return &ImportCommand{}
+}
+
+// Name returns the command name
+func (c *ImportCommand) Name() string {
+	return "import"
+}
+
+// Description returns the command description
+func (c *ImportCommand) Description() string {
+	return "Install agents from an archive written by export"
+}
+
+// CreateCommand creates the cobra command for import functionality
+func (c *ImportCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: c.Description(),
+		Long: `Install the agents of an archive written by 'agent-manager export' into the
+agents directory, at the same paths relative to it.
+
+The archive is verified before anything is written: every file must match the
+checksum in its manifest and parse as an agent that passes the checks of
+'validate --agents'. Agents that already exist are resolved with the conflict
+strategy, settings.conflict_strategy by default. Imported agents are tracked
+under the source they were exported from, or under --source, so list,
+uninstall and publish manage them like installed agents. A file installed by
+another source is not replaced.
+
+Examples:
+  agent-manager import agents.tar.gz
+  agent-manager import team.zip --source team-agents --dry-run
+  agent-manager import review.tar.gz --conflict-strategy skip`,
+		Args:        cobra.ExactArgs(1),
+		Annotations: pathArgs(pathFile),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.Execute(sharedCtx, args[0])
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.source, "source", "s", "", "track imported agents under this source (default: the source each was exported from)")
+	cmd.Flags().StringVar(&c.conflictStrategy, "conflict-strategy", "", "how to handle agents that already exist: backup, overwrite, skip or merge (default: settings.conflict_strategy)")
+
+	return cmd
+}
+
+// importedAgent is a verified agent from an archive, staged in a temp directory
+type importedAgent struct {
+	entry   bundle.Agent
+	agent   *parser.AgentSpec
+	content []byte
+	staged  string
+	target  string
+	source  string
+}
+
+// Execute runs the import command logic
+func (c *ImportCommand) Execute(sharedCtx *SharedContext, file string) error {
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	strategy := c.conflictStrategy
+	if strategy == "" {
+		strategy = sharedCtx.Config.Settings.ConflictStrategy
+	}
+	switch strategy {
+	case "backup", "overwrite", "skip", "merge":
+	default:
+		return fmt.Errorf("unknown conflict strategy: %s", strategy)
+	}
+
+	b, err := bundle.Read(file)
+	if err != nil {
+		return err
+	}
+	if len(b.Manifest.Agents) == 0 {
+		PrintWarning("%s contains no agents", file)
+		return nil
+	}
+
+	stageDir, err := util.MkdirTemp("agent-manager-import-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	imported, err := c.stage(sharedCtx, b, stageDir)
+	if err != nil {
+		return err
+	}
+
+	track := sharedCtx.Tracker()
+	resolver := conflict.NewResolver(strategy, sharedCtx.Config.Settings.BackupDir)
+	var outcomes []conflict.Outcome
+	var installed []*parser.AgentSpec
+	skipped, failed := 0, 0
+	for _, item := range imported {
+		outcome, err := c.install(sharedCtx, track, resolver, strategy, item)
+		if err != nil {
+			PrintError("%s: %v", item.entry.Path, err)
+			failed++
+			continue
+		}
+		if outcome != nil {
+			outcomes = append(outcomes, *outcome)
+			if outcome.Action == conflict.ActionSkipped {
+				skipped++
+				continue
+			}
+		}
+		installed = append(installed, item.agent)
+	}
+
+	printConflictReport(os.Stdout, outcomes)
+	if sharedCtx.Options.DryRun {
+		color.Yellow("[DRY RUN] Would import %d agents from %s\n", len(installed), file)
+		return nil
+	}
+
+	refreshIndex(sharedCtx)
+
+	sharedCtx.Summarize("imported", len(installed))
+	sharedCtx.Summarize("skipped", skipped)
+	sharedCtx.Summarize("failed", failed)
+	PrintSuccess("Imported %d agents from %s", len(installed), file)
+	if failed > 0 {
+		return fmt.Errorf("failed to import %d of %d agents", failed, len(imported))
+	}
+	return nil
+}
+
+// stage writes each agent of the archive to stageDir and checks it like
+// validate --agents does; any invalid agent, or any agent whose target matches
+// settings.readonly_paths, aborts the import
+func (c *ImportCommand) stage(sharedCtx *SharedContext, b *bundle.Bundle, stageDir string) ([]importedAgent, error) {
+	validate := &ValidateCommand{}
+	toolValidator, err := validate.toolValidator(sharedCtx.Config.Settings.Query.Validation)
+	if err != nil {
+		return nil, err
+	}
+	agentParser := &parser.Parser{Mode: sharedCtx.Config.Settings.Query.ParserMode}
+
+	imported := make([]importedAgent, 0, len(b.Manifest.Agents))
+	invalid, readonly := 0, 0
+	for _, entry := range b.Manifest.Agents {
+		target := filepath.Join(sharedCtx.GetAgentsDirectory(), filepath.FromSlash(entry.Path))
+		if pattern := config.MatchReadonly(sharedCtx.Config.Settings.ReadonlyPaths, target); pattern != "" {
+			PrintError("%s: not importing %s: it matches readonly_paths pattern %q", entry.Path, target, pattern)
+			readonly++
+			continue
+		}
+
+		staged := filepath.Join(stageDir, filepath.FromSlash(entry.Path))
+		if err := os.MkdirAll(filepath.Dir(staged), 0750); err != nil {
+			return nil, fmt.Errorf("failed to stage %s: %w", entry.Path, err)
+		}
+		if err := os.WriteFile(staged, b.Files[entry.Path], 0600); err != nil {
+			return nil, fmt.Errorf("failed to stage %s: %w", entry.Path, err)
+		}
+
+		agent, err := agentParser.ParseFile(staged)
+		if err != nil {
+			PrintError("%s: %v", entry.Path, err)
+			invalid++
+			continue
+		}
+		check := validate.checkAgent(sharedCtx, agent, toolValidator, nil)
+		for _, message := range check.warnings {
+			PrintWarning("%s", message)
+		}
+		if len(check.errors) > 0 {
+			for _, message := range check.errors {
+				PrintError("%s", message)
+			}
+			invalid++
+			continue
+		}
+
+		source := c.source
+		if source == "" {
+			source = entry.Source
+		}
+		if source == "" {
+			source = tracker.ManualSource
+		}
+		imported = append(imported, importedAgent{
+			entry:   entry,
+			agent:   agent,
+			content: b.Files[entry.Path],
+			staged:  staged,
+			target:  target,
+			source:  source,
+		})
+	}
+
+	if readonly > 0 {
+		return nil, fmt.Errorf("archive contains %d agents matching readonly_paths; nothing was imported", readonly)
+	}
+	if invalid > 0 {
+		return nil, fmt.Errorf("archive contains %d invalid agents; nothing was imported", invalid)
+	}
+	return imported, nil
+}
+
+// install copies one staged agent into place, resolving a conflict with an
+// existing file, and tracks it under its source. It returns the conflict
+// outcome when the agent already existed.
+func (c *ImportCommand) install(sharedCtx *SharedContext, track *tracker.Tracker, resolver *conflict.Resolver, strategy string, item importedAgent) (*conflict.Outcome, error) {
+	var outcome *conflict.Outcome
+	wasPreExisting := false
+	replace := true
+	if _, err := os.Stat(item.target); err == nil {
+		owner, info, err := track.FindFile(item.target)
+		if err != nil {
+			return nil, err
+		}
+		if owner != "" && owner != item.source {
+			return nil, fmt.Errorf("%s already exists and was installed from source %s", item.target, owner)
+		}
+		wasPreExisting = owner == "" || info.WasPreExisting
+
+		if sharedCtx.Options.DryRun {
+			fmt.Printf("  %s -> %s (exists, %s)\n", item.entry.Path, item.target, strategy)
+			return nil, nil
+		}
+		resolved, err := resolver.ResolveDetailed(item.target, item.staged, strategy)
+		if err != nil {
+			return nil, fmt.Errorf("conflict resolution failed for %s: %w", item.target, err)
+		}
+		resolved.Source = item.source
+		outcome = &resolved
+		if resolved.Action == conflict.ActionSkipped {
+			return outcome, nil
+		}
+		if resolved.Action == conflict.ActionUnchanged {
+			outcome = nil
+		}
+		replace = resolved.Replaces()
+	} else if sharedCtx.Options.DryRun {
+		fmt.Printf("  %s -> %s\n", item.entry.Path, item.target)
+		return nil, nil
+	}
+
+	if replace {
+		if err := os.MkdirAll(filepath.Dir(item.target), 0750); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(item.target, item.content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", item.target, err)
+		}
+	}
+
+	info, err := os.Stat(item.target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat imported file %s: %w", item.target, err)
+	}
+	hash, err := util.FileSHA256(item.target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum imported file %s: %w", item.target, err)
+	}
+	if err := track.AddFile(item.source, tracker.FileInfo{
+		Path:           item.target,
+		Hash:           hash,
+		Size:           info.Size(),
+		Modified:       info.ModTime(),
+		WasPreExisting: wasPreExisting,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to track %s: %w", item.target, err)
+	}
+	if sharedCtx.Options.Verbose {
+		fmt.Printf("Imported: %s (source %s)\n", item.target, item.source)
+	}
+	return outcome, nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/githook"
+	"github.com/spf13/cobra"
+)
+
+// GithookCommand implements installing git hooks that sync project agents
+type GithookCommand struct {
+	action string
+}
+
+// NewGithookCommand creates a new githook command instance
+func NewGithookCommand() *GithookCommand {
+	return &GithookCommand{}
+}
+
+// Name returns the command name
+func (c *GithookCommand) Name() string {
+	return "githook"
+}
+
+// Description returns the command description
+func (c *GithookCommand) Description() string {
+	return "Manage git hooks that keep project agents in sync"
+}
+
+// CreateCommand creates the cobra command for githook functionality
+func (c *GithookCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "githook",
+		Short: c.Description(),
+		Long: `Install post-merge and post-checkout hooks into the current git repository
+that run 'agent-manager install' with the project configuration, so
+project-scoped agents stay in sync as teammates pull changes or switch branches.
+
+Existing hook content is preserved; only the agent-manager block is added,
+replaced or removed.
+
+Examples:
+  agent-manager githook install                       # Use agents-config.yaml
+  agent-manager githook install -c .claude/agents.yaml
+  agent-manager githook status
+  agent-manager githook uninstall`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"install", "uninstall", "status"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c.action = args[0]
+			return c.Execute(sharedCtx)
+		},
+	}
+
+	return cmd
+}
+
+// Execute runs the githook command logic
+func (c *GithookCommand) Execute(sharedCtx *SharedContext) error {
+	// Only install and uninstall change files, so only they follow
+	// settings.default_dry_run
+	sharedCtx.mutating = c.action != "status"
+
+	hooksDir, topLevel, err := gitHookPaths(sharedCtx)
+	if err != nil {
+		return err
+	}
+
+	switch c.action {
+	case "install":
+		return c.executeInstall(sharedCtx, hooksDir, topLevel)
+	case "uninstall":
+		return c.executeUninstall(sharedCtx, hooksDir)
+	case "status":
+		for _, hook := range githook.Hooks {
+			if githook.Installed(hooksDir, hook) {
+				fmt.Printf("  %s: installed\n", hook)
+			} else {
+				fmt.Printf("  %s: not installed\n", hook)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown action: %s", c.action)
+	}
+}
+
+// executeInstall writes the sync block into each hook
+func (c *GithookCommand) executeInstall(sharedCtx *SharedContext, hooksDir, topLevel string) error {
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	configPath, err := hookConfigPath(sharedCtx.Options.ConfigFile, topLevel)
+	if err != nil {
+		return err
+	}
+	binary := hookBinary()
+
+	for _, hook := range githook.Hooks {
+		block := githook.Block(hook, binary, configPath)
+		if sharedCtx.Options.DryRun {
+			color.Yellow("[DRY RUN] Would add to %s:\n", filepath.Join(hooksDir, hook))
+			fmt.Print(block)
+			continue
+		}
+		if err := githook.Install(hooksDir, hook, block); err != nil {
+			return err
+		}
+		if sharedCtx.Options.Verbose {
+			fmt.Printf("Installed: %s\n", filepath.Join(hooksDir, hook))
+		}
+	}
+
+	if !sharedCtx.Options.DryRun {
+		PrintSuccess("Installed %d git hooks using %s", len(githook.Hooks), configPath)
+	}
+	return nil
+}
+
+// executeUninstall removes the sync block from each hook
+func (c *GithookCommand) executeUninstall(sharedCtx *SharedContext, hooksDir string) error {
+	// Load the configuration for its dry-run policy; without one there is no
+	// policy, and the hooks can still be removed
+	if _, err := os.Stat(sharedCtx.Options.ConfigFile); err == nil {
+		if err := sharedCtx.LoadConfig(); err != nil {
+			return fmt.Errorf("configuration error: %w", err)
+		}
+	}
+
+	removed := 0
+	for _, hook := range githook.Hooks {
+		if sharedCtx.Options.DryRun {
+			if githook.Installed(hooksDir, hook) {
+				color.Yellow("[DRY RUN] Would remove agent-manager block from %s\n", filepath.Join(hooksDir, hook))
+			}
+			continue
+		}
+		found, err := githook.Uninstall(hooksDir, hook)
+		if err != nil {
+			return err
+		}
+		if found {
+			removed++
+		}
+	}
+
+	if !sharedCtx.Options.DryRun {
+		PrintSuccess("Removed agent-manager from %d git hooks", removed)
+	}
+	return nil
+}
+
+// gitHookPaths returns the hooks directory and top-level directory of the
+// repository containing the working directory, honoring core.hooksPath
+func gitHookPaths(sharedCtx *SharedContext) (string, string, error) {
+	topLevel, err := runTool(sharedCtx.Context(), ".", "git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", "", fmt.Errorf("not in a git repository: %w", err)
+	}
+	hooksDir, err := runTool(sharedCtx.Context(), ".", "git", "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", "", err
+	}
+	hooksDir, err = filepath.Abs(hooksDir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve hooks directory: %w", err)
+	}
+	return hooksDir, topLevel, nil
+}
+
+// hookConfigPath returns the configuration path the hooks should use: relative
+// to the repository root when inside it, since git runs hooks from there
+func hookConfigPath(configFile, topLevel string) (string, error) {
+	absConfig, err := filepath.Abs(configFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve configuration path: %w", err)
+	}
+	if _, err := os.Stat(absConfig); err != nil {
+		return "", fmt.Errorf("configuration file not found: %s", configFile)
+	}
+
+	if rel, err := filepath.Rel(topLevel, absConfig); err == nil && filepath.IsLocal(rel) {
+		return filepath.ToSlash(rel), nil
+	}
+	return absConfig, nil
+}
+
+// hookBinary returns how the hooks invoke agent-manager: by name when it is on
+// PATH, otherwise by the path of the running executable
+func hookBinary() string {
+	if _, err := exec.LookPath("agent-manager"); err == nil {
+		return "agent-manager"
+	}
+	if executable, err := os.Executable(); err == nil {
+		return executable
+	}
+	return "agent-manager"
+}
@@ -7,12 +7,17 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/engine"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/index"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 	"github.com/spf13/cobra"
 )
 
 // IndexCommand implements the index command functionality
 type IndexCommand struct {
-	action string
+	action       string
+	size         bool
+	stripPrompts bool
+	missing      bool
 }
 
 // NewIndexCommand creates a new index command instance
@@ -41,16 +46,38 @@ Examples:
   agent-manager index build       # Build/update index
   agent-manager index rebuild     # Force rebuild index
   agent-manager index stats       # Show index statistics
+  agent-manager index stats --size  # Break the index file size down by component
+  agent-manager index compact     # Drop orphaned and duplicate entries
+  agent-manager index compact --strip-prompts  # Also leave prompt bodies out
+  agent-manager index prune --missing  # Drop entries for deleted agent files
   agent-manager index cache-clear # Clear query cache
-  agent-manager index cache-stats # Show cache statistics`,
+  agent-manager index cache-stats # Show cache statistics
+
+See 'agent-manager cache' to report on and trim both the query cache and the
+marketplace cache on disk.
+
+compact rewrites the saved index without indentation, dropping entries for
+agent files that no longer exist and duplicate entries for the same file. With
+--strip-prompts the prompt bodies are left out too and re-read from the agent
+files whenever the index is loaded. Later index updates keep this layout until
+the next compact.
+
+prune --missing stats each indexed path and drops the entries, including
+broken records, whose files were deleted outside agent-manager, listing what
+was dropped. It is much faster than a rebuild as no agent is parsed. With
+query.index.auto_update enabled this also happens before every query.`,
 		Args:      cobra.ExactArgs(1),
-		ValidArgs: []string{"build", "rebuild", "stats", "cache-clear", "cache-stats"},
+		ValidArgs: []string{"build", "rebuild", "stats", "compact", "prune", "cache-clear", "cache-stats"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c.action = args[0]
 			return c.Execute(sharedCtx)
 		},
 	}
 
+	cmd.Flags().BoolVar(&c.size, "size", false, "with stats, break the saved index file size down by component")
+	cmd.Flags().BoolVar(&c.stripPrompts, "strip-prompts", false, "with compact, leave prompt bodies out of the saved index")
+	cmd.Flags().BoolVar(&c.missing, "missing", false, "with prune, drop entries whose agent files no longer exist")
+
 	return cmd
 }
 
@@ -61,6 +88,33 @@ func (c *IndexCommand) Execute(sharedCtx *SharedContext) error {
 		return fmt.Errorf("configuration error: %w", err)
 	}
 
+	if c.size && c.action != "stats" {
+		return fmt.Errorf("--size only applies to index stats")
+	}
+	if c.stripPrompts && c.action != "compact" {
+		return fmt.Errorf("--strip-prompts only applies to index compact")
+	}
+
+	if c.missing && c.action != "prune" {
+		return fmt.Errorf("--missing only applies to index prune")
+	}
+	if c.action == "prune" && !c.missing {
+		return fmt.Errorf("index prune requires --missing")
+	}
+
+	// Compaction and pruning work on the saved index as is
+	switch c.action {
+	case "compact", "prune":
+		queryEngine, err := sharedCtx.OpenQueryEngine()
+		if err != nil {
+			return err
+		}
+		if c.action == "prune" {
+			return c.executePrune(sharedCtx, queryEngine)
+		}
+		return c.executeCompact(sharedCtx, queryEngine)
+	}
+
 	// Create query engine
 	queryEngine, err := sharedCtx.CreateQueryEngine()
 	if err != nil {
@@ -130,9 +184,133 @@ func (c *IndexCommand) executeStats(sharedCtx *SharedContext, queryEngine interf
 	}
 
 	c.displayIndexStats(indexStats, sharedCtx)
+	if !c.size {
+		return nil
+	}
+
+	report, err := engine.IndexSize()
+	if err != nil {
+		return fmt.Errorf("failed to measure index: %w", err)
+	}
+	c.displaySizeReport(report)
+	return nil
+}
+
+// executeCompact drops orphaned and duplicate entries from the saved index and
+// rewrites it compactly
+func (c *IndexCommand) executeCompact(sharedCtx *SharedContext, queryEngine *engine.Engine) error {
+	if sharedCtx.Options.DryRun {
+		report, err := queryEngine.IndexSize()
+		if err != nil {
+			return fmt.Errorf("failed to measure index: %w", err)
+		}
+		color.Yellow("[DRY RUN] Would drop %d orphaned and %d duplicate entries and rewrite the index (%s) compactly\n",
+			report.Orphaned, report.Duplicates, formatBytes(report.FileBytes))
+		return nil
+	}
+
+	var result *index.CompactResult
+	err := sharedCtx.PM.WithSpinner("Compacting index", func() error {
+		var compactErr error
+		result, compactErr = queryEngine.CompactIndex(index.Layout{Compact: true, StripPrompts: c.stripPrompts})
+		return compactErr
+	})
+	if err != nil {
+		return err
+	}
+
+	sharedCtx.Summarize("orphaned", result.Orphaned)
+	sharedCtx.Summarize("duplicates", result.Duplicates)
+	sharedCtx.Summarize("bytes", result.BytesAfter)
+	PrintSuccess("Index compacted: %s -> %s", formatBytes(result.BytesBefore), formatBytes(result.BytesAfter))
+	fmt.Printf("Dropped %d orphaned entries, %d duplicate entries and %d broken records\n",
+		result.Orphaned, result.Duplicates, result.Broken)
+	if c.stripPrompts {
+		PrintInfo("Prompt bodies are re-read from the agent files when the index is loaded")
+	}
 	return nil
 }
 
+// executePrune drops the entries of the saved index whose files no longer
+// exist and lists them
+func (c *IndexCommand) executePrune(sharedCtx *SharedContext, queryEngine *engine.Engine) error {
+	if sharedCtx.Options.DryRun {
+		missing := queryEngine.MissingEntries()
+		color.Yellow("[DRY RUN] Would drop %d entries for missing files\n", missing.Dropped())
+		c.displayPruned(missing)
+		return nil
+	}
+
+	var result *index.PruneResult
+	err := sharedCtx.PM.WithSpinner("Pruning index", func() error {
+		var pruneErr error
+		result, pruneErr = queryEngine.PruneMissing()
+		return pruneErr
+	})
+	if err != nil {
+		return err
+	}
+
+	sharedCtx.Summarize("pruned", result.Dropped())
+	if result.Dropped() == 0 {
+		PrintSuccess("No index entries for missing files")
+		return nil
+	}
+	PrintSuccess("Dropped %d entries for missing files", result.Dropped())
+	c.displayPruned(result)
+	return nil
+}
+
+// displayPruned lists the pruned agents and broken records
+func (c *IndexCommand) displayPruned(result *index.PruneResult) {
+	for _, agent := range result.Agents {
+		fmt.Printf("  - %s (%s)\n", agent.Name, agent.FilePath)
+	}
+	for _, failure := range result.Broken {
+		fmt.Printf("  - %s (broken)\n", failure.Path)
+	}
+}
+
+// displaySizeReport prints the size breakdown of the saved index file
+func (c *IndexCommand) displaySizeReport(report *index.SizeReport) {
+	fmt.Printf("\nIndex Size: %s (%d entries)\n", formatBytes(report.FileBytes), report.Entries)
+	components := []struct {
+		name  string
+		bytes int64
+	}{
+		{"Prompts", report.Prompts},
+		{"Descriptions", report.Descriptions},
+		{"Metadata", report.Metadata},
+		{"Broken records", report.Broken},
+		{"Formatting", report.Formatting},
+	}
+	for _, component := range components {
+		share := 0.0
+		if report.FileBytes > 0 {
+			share = float64(component.bytes) / float64(report.FileBytes) * 100
+		}
+		fmt.Printf("  %-15s %10s  %5.1f%%\n", component.name+":", formatBytes(component.bytes), share)
+	}
+	if report.PromptsStripped {
+		fmt.Println("  (prompt bodies are stripped and re-read from disk)")
+	}
+
+	if len(report.Largest) > 0 {
+		fmt.Printf("\nLargest Entries:\n")
+		for _, entry := range report.Largest {
+			fmt.Printf("  %10s  %s\n", formatBytes(entry.Bytes), entry.FilePath)
+		}
+	}
+
+	switch {
+	case report.Orphaned > 0 || report.Duplicates > 0:
+		color.Yellow("\n%d orphaned and %d duplicate entries; run 'agent-manager index compact' to drop them\n",
+			report.Orphaned, report.Duplicates)
+	case !report.Compact && report.Formatting > 0:
+		fmt.Printf("\nRun 'agent-manager index compact' to rewrite the file without indentation\n")
+	}
+}
+
 // executeCacheClear clears the query cache
 func (c *IndexCommand) executeCacheClear(sharedCtx *SharedContext, queryEngine interface{}) error {
 	engine := queryEngine.(*engine.Engine)
@@ -188,8 +366,11 @@ func (c *IndexCommand) displayIndexStats(indexStats map[string]interface{}, shar
 	}
 
 	if indexInfo, ok := indexStats["index_stats"].(map[string]interface{}); ok {
+		if broken, exists := indexInfo["broken_files"].(int); exists && broken > 0 {
+			color.Yellow("Broken Files: %d (see 'agent-manager list --broken')\n", broken)
+		}
 		if lastUpdate, exists := indexInfo["last_updated"].(time.Time); exists {
-			fmt.Printf("Last Updated: %s\n", lastUpdate.Format("2006-01-02 15:04:05"))
+			fmt.Printf("Last Updated: %s\n", util.FormatTime(lastUpdate))
 		}
 	}
 
@@ -253,3 +434,17 @@ func (c *IndexCommand) displayCacheStats(cacheStats map[string]interface{}, shar
 		}
 	}
 }
+
+// formatBytes renders a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGT"[exp])
+}
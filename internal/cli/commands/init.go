@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// InitCommand implements creating a starter configuration
+type InitCommand struct {
+	yes       bool
+	force     bool
+	noInstall bool
+}
+
+// NewInitCommand creates a new init command instance
+func NewInitCommand() *InitCommand {
+	return &InitCommand{}
+}
+
+// Name returns the command name
+func (c *InitCommand) Name() string {
+	return "init"
+}
+
+// Description returns the command description
+func (c *InitCommand) Description() string {
+	return "Create a starter configuration with popular community sources"
+}
+
+// CreateCommand creates the cobra command for init functionality
+func (c *InitCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: c.Description(),
+		Long: `Write a starter configuration file listing popular community sources, then
+install the enabled ones and build the query index. The sources are shown and
+must be confirmed before anything is written.
+
+This also runs automatically, after confirmation, the first time any command
+is used in a directory with no configuration and no installed agents.
+
+Examples:
+  agent-manager init
+  agent-manager init --yes --no-install
+  agent-manager --config team-agents.yaml init`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.Execute(sharedCtx)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&c.yes, "yes", "y", false, "create the configuration without asking")
+	cmd.Flags().BoolVar(&c.force, "force", false, "overwrite an existing configuration file")
+	cmd.Flags().BoolVar(&c.noInstall, "no-install", false, "only write the configuration, skip the initial install")
+
+	return cmd
+}
+
+// Execute runs the init command logic
+func (c *InitCommand) Execute(sharedCtx *SharedContext) error {
+	path := sharedCtx.Options.ConfigFile
+	if _, err := os.Stat(path); err == nil && !c.force {
+		return fmt.Errorf("configuration file already exists: %s (use --force to overwrite)", path)
+	}
+
+	fmt.Printf("Starter sources for %s:\n", path)
+	for _, source := range config.StarterSources {
+		state := "disabled"
+		if source.Enabled {
+			state = "enabled"
+		}
+		fmt.Printf("  - %s (%s, %s)\n    %s\n", source.Name, source.Repository, state, source.Description)
+	}
+
+	if !c.yes && !Confirm(fmt.Sprintf("Create %s with these sources?", path)) {
+		PrintInfo("No configuration written")
+		return nil
+	}
+
+	if sharedCtx.Options.DryRun {
+		color.Yellow("[DRY RUN] Would create %s and install the enabled sources\n", path)
+		return nil
+	}
+
+	if err := os.WriteFile(path, config.StarterConfig(config.StarterSources), 0600); err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+	PrintSuccess("Created %s", path)
+	PrintInfo("Enable more sources by setting enabled: true in %s", path)
+
+	if c.noInstall {
+		PrintInfo("Run 'agent-manager install' to install the enabled sources")
+		return nil
+	}
+
+	if err := NewInstallCommand().Execute(sharedCtx); err != nil {
+		return fmt.Errorf("initial install failed: %w", err)
+	}
+
+	refreshIndex(sharedCtx)
+	PrintSuccess("Setup complete. Try 'agent-manager list' or 'agent-manager query <term>'")
+	return nil
+}
+
+// offerOnboarding runs the starter setup after confirmation when a command that
+// needs configuration is used before anything has been configured or installed
+func offerOnboarding(sharedCtx *SharedContext, commandName string) error {
+	if !config.IsFirstRun(sharedCtx.Options.ConfigFile) {
+		return nil
+	}
+
+	PrintInfo("Welcome to agent-manager! No configuration or installed agents were found.")
+	if !Confirm("Create a starter configuration with popular community sources?") {
+		return fmt.Errorf("no configuration found at %s; run 'agent-manager init' to create one", sharedCtx.Options.ConfigFile)
+	}
+
+	setup := NewInitCommand()
+	setup.yes = true
+	// The install command itself performs the initial install
+	setup.noInstall = commandName == "install"
+	return setup.Execute(sharedCtx)
+}
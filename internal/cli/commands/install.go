@@ -1,16 +1,47 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/conflict"
+	"github.com/pacphi/claude-code-agent-manager/internal/installer"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/engine"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 	"github.com/spf13/cobra"
 )
 
 // InstallCommand implements the install command functionality
 type InstallCommand struct {
 	*BaseCommand
-	sourceName string
+	sourceName     string
+	conflictReport string
+	summary        string
+	conflicts      []conflict.Outcome
+	metrics        []installer.SourceMetrics
+	agents         []*parser.AgentSpec
+	stdin          bool
+	agentName      string
+	collection     string
+	diff           bool
+	// collectionAgents are the agent names of --collection
+	collectionAgents []string
+}
+
+// installSummary is the JSON document written by install --summary
+type installSummary struct {
+	Generated time.Time                 `json:"generated"`
+	Sources   []installer.SourceMetrics `json:"sources"`
+	Conflicts []conflict.Outcome        `json:"conflicts"`
 }
 
 // NewInstallCommand creates a new install command instance
@@ -35,39 +66,282 @@ func (c *InstallCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command
 	cmd := &cobra.Command{
 		Use:   "install",
 		Short: c.Description(),
-		Long:  `Install agents from all enabled sources defined in the configuration file.`,
+		Long: `Install agents from all enabled sources defined in the configuration file.
+
+After installing, a conflict report lists every file that already existed, the
+conflict strategy applied to it, what happened (backed up, overwritten, skipped
+or merged) and where backups were written. Use --conflict-report to also save
+the report as JSON. Files that no source installed, such as ones you authored,
+are listed again as pre-existing; set settings.protect_pre_existing to never
+overwrite them.
+
+In verbose mode each source ends with per-phase metrics: fetch, filter,
+transform and post-install times, copy throughput and conflicts resolved.
+Use --summary to write these metrics and the conflict report as JSON.
+
+With --collection only the agents of a collection defined in the configuration
+are installed, from every enabled source providing them, or from --source; the
+other agents of those sources stay as they are. Agents no source provides are
+reported.
+
+With --dry-run, or for sources that set dry_run, nothing is installed; each
+source lists the files it would add (+), change (~) and remove (-) in the
+target directory. Add --diff to also show a unified diff of each changed agent.
+
+With --stdin a single agent document is read from stdin, validated and
+installed as <base_dir>/<name>.md under the "manual" source instead. Its
+frontmatter name is set to --name.
+
+Examples:
+  agent-manager install
+  agent-manager install --source community
+  agent-manager install --collection review-kit
+  agent-manager install --dry-run --diff
+  pbpaste | agent-manager install --stdin --name my-agent`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return c.Execute(sharedCtx)
+			return silenceExitError(cmd, c.Execute(sharedCtx))
 		},
 	}
 
-	cmd.Flags().StringVarP(&c.sourceName, "source", "s", "", "install specific source only")
+	cmd.Flags().StringVarP(&c.sourceName, "source", "s", "", "install specific source only (or a glob or re: pattern, confirmed first)")
+	cmd.Flags().StringVar(&c.conflictReport, "conflict-report", "", "write the per-file conflict report as JSON to this file")
+	cmd.Flags().StringVar(&c.summary, "summary", "", "write per-source metrics and conflicts as a JSON summary to this file")
+	markPathFlag(cmd, "conflict-report", pathFile)
+	markPathFlag(cmd, "summary", pathFile)
+	cmd.Flags().BoolVar(&c.stdin, "stdin", false, "install a single agent document read from stdin under the \"manual\" source")
+	cmd.Flags().StringVar(&c.agentName, "name", "", "with --stdin, the name of the agent to install")
+	cmd.Flags().StringVar(&c.collection, "collection", "", "install only the agents of a collection defined in the configuration")
+	cmd.Flags().BoolVar(&c.diff, "diff", false, "in dry runs, also show the unified diff of each changed agent")
+	cmd.MarkFlagsMutuallyExclusive("stdin", "source")
+	cmd.MarkFlagsMutuallyExclusive("stdin", "collection")
+	AddYesFlag(cmd, &c.yes)
+	AddFailFastFlag(cmd, &c.failFast)
+	AddTimeoutFlag(cmd, &c.timeout)
 
 	return cmd
 }
 
 // Execute runs the install command logic
 func (c *InstallCommand) Execute(sharedCtx *SharedContext) error {
-	return c.ExecuteWithCommonPattern(sharedCtx, c.sourceName)
+	if c.stdin || c.agentName != "" {
+		return c.executeStdin(sharedCtx, os.Stdin)
+	}
+
+	c.conflicts = nil
+	c.metrics = nil
+	c.agents = nil
+	c.collectionAgents = nil
+	if c.collection != "" {
+		if err := sharedCtx.LoadConfig(); err != nil {
+			return fmt.Errorf("configuration error: %w", err)
+		}
+		names, err := sharedCtx.Config.Collection(c.collection)
+		if err != nil {
+			return err
+		}
+		c.collectionAgents = names
+	}
+
+	err := c.ExecuteWithCommonPattern(sharedCtx, c.sourceName)
+	c.summarize(sharedCtx)
+	warmIndex(sharedCtx, c.agents)
+	if c.collection != "" && err == nil && !sharedCtx.Options.DryRun {
+		c.reportMissing(sharedCtx)
+	}
+
+	// Report conflicts even when a later source failed, so completed work is visible
+	printConflictReport(os.Stdout, c.conflicts)
+	printPreExisting(os.Stdout, c.conflicts)
+	if c.conflictReport != "" && !sharedCtx.Options.DryRun {
+		if reportErr := conflict.WriteReport(c.conflictReport, c.conflicts); reportErr != nil {
+			PrintWarning("Failed to write conflict report: %v", reportErr)
+		} else {
+			PrintInfo("Conflict report written to %s", c.conflictReport)
+		}
+	}
+	if c.summary != "" {
+		if summaryErr := writeInstallSummary(c.summary, c.metrics, c.conflicts); summaryErr != nil {
+			PrintWarning("Failed to write install summary: %v", summaryErr)
+		}
+	}
+
+	return err
+}
+
+// executeStdin validates an agent document read from r and installs it under
+// the manual source
+func (c *InstallCommand) executeStdin(sharedCtx *SharedContext, r io.Reader) error {
+	if !c.stdin || c.agentName == "" {
+		return fmt.Errorf("--stdin and --name must be used together")
+	}
+	if util.GenerateSlug(c.agentName) != c.agentName {
+		return fmt.Errorf("invalid agent name %q: use lowercase letters, digits and dashes", c.agentName)
+	}
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	content, agent, err := readStdinAgent(sharedCtx, r)
+	if err != nil {
+		return err
+	}
+	if agent.Name != c.agentName {
+		updated, err := parser.SetFrontmatterField(string(content), "name", c.agentName)
+		if err != nil {
+			return fmt.Errorf("failed to set agent name: %w", err)
+		}
+		content = []byte(updated)
+		agent.Name = c.agentName
+	}
+
+	// Check the agent like validate --agents does; only errors block the install
+	validate := &ValidateCommand{}
+	toolValidator, err := validate.toolValidator(sharedCtx.Config.Settings.Query.Validation)
+	if err != nil {
+		return err
+	}
+	check := validate.checkAgent(sharedCtx, agent, toolValidator, nil)
+	for _, message := range check.warnings {
+		PrintWarning("%s", message)
+	}
+	if len(check.errors) > 0 {
+		for _, message := range check.errors {
+			PrintError("%s", message)
+		}
+		return fmt.Errorf("agent from stdin is invalid")
+	}
+	limits := sharedCtx.Config.Settings.Limits
+	if maxBytes := limits.MaxAgentFileBytes(); maxBytes > 0 && agent.FileSize > maxBytes && limits.OnExceed != "warn" {
+		return fmt.Errorf("agent from stdin exceeds max_agent_file_kb (%d KB)", limits.MaxAgentFileKB)
+	}
+
+	// Only an agent installed from stdin before may be replaced
+	path := filepath.Join(sharedCtx.GetAgentsDirectory(), c.agentName+".md")
+	track := sharedCtx.Tracker()
+	if _, err := os.Stat(path); err == nil {
+		owner, _, err := track.FindFile(path)
+		if err != nil {
+			return err
+		}
+		switch owner {
+		case tracker.ManualSource:
+			// Replaced below
+		case "":
+			return fmt.Errorf("%s already exists and is not tracked; use 'list --orphans --adopt' to manage it", path)
+		default:
+			return fmt.Errorf("%s already exists and was installed from source %s", path, owner)
+		}
+	}
+
+	if sharedCtx.Options.DryRun {
+		color.Yellow("[DRY RUN] Would install %s from stdin to %s\n", c.agentName, path)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create agents directory: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := track.AddFile(tracker.ManualSource, tracker.FileInfo{Path: path, Hash: util.SHA256Hex(content), Size: info.Size(), Modified: info.ModTime()}); err != nil {
+		return fmt.Errorf("failed to track %s: %w", path, err)
+	}
+
+	agent.FilePath, agent.FileName, agent.FileSize, agent.ModTime = path, filepath.Base(path), info.Size(), info.ModTime()
+	agent.Source = tracker.ManualSource
+	warmIndex(sharedCtx, []*parser.AgentSpec{agent})
+
+	sharedCtx.Summarize("source", tracker.ManualSource)
+	sharedCtx.Summarize("files", 1)
+	PrintSuccess("Installed %s to %s under source %s", c.agentName, path, tracker.ManualSource)
+	return nil
+}
+
+// summarize records the sources, files copied and conflicts for the quiet-mode summary
+func (c *InstallCommand) summarize(sharedCtx *SharedContext) {
+	sources := make([]string, 0, len(c.metrics))
+	files, unchanged := 0, 0
+	for _, m := range c.metrics {
+		sources = append(sources, m.Source)
+		files += m.FilesCopied
+		unchanged += m.FilesUnchanged
+	}
+	if len(sources) > 0 {
+		sharedCtx.Summarize("source", strings.Join(sources, ","))
+	}
+	sharedCtx.Summarize("files", files)
+	sharedCtx.Summarize("unchanged", unchanged)
+	sharedCtx.Summarize("conflicts", len(c.conflicts))
+	sharedCtx.Summarize("pre_existing", len(preExistingOutcomes(c.conflicts)))
+}
+
+// warmIndex hands the agents parsed during install to the query index, so the
+// first query after an install does not pay to parse them again
+func warmIndex(sharedCtx *SharedContext, agents []*parser.AgentSpec) {
+	if len(agents) == 0 || !sharedCtx.Config.Settings.Query.Enabled {
+		return
+	}
+
+	queryEngine, err := sharedCtx.OpenQueryEngine()
+	if err == nil {
+		var roots []engine.Root
+		if roots, err = sharedCtx.indexRoots(); err == nil {
+			err = queryEngine.WarmAgents(agents, roots)
+		}
+	}
+	if err != nil {
+		PrintWarning("Failed to update index: %v", err)
+	}
 }
 
 // ExecuteOperation implements CommandExecutor interface for install operations
 func (c *InstallCommand) ExecuteOperation(ctx *SharedContext, sources []config.Source) error {
 	// Create installer
-	inst, err := ctx.CreateInstaller()
+	inst, err := ctx.createInstallerWithOptions(installer.Options{
+		Verbose: ctx.Options.Verbose,
+		DryRun:  ctx.Options.DryRun,
+		Diff:    c.diff,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create installer: %w", err)
 	}
 
+	// Collect conflict outcomes and metrics even when the source fails part way
+	defer func() {
+		c.conflicts = append(c.conflicts, inst.Conflicts()...)
+		c.metrics = append(c.metrics, inst.Metrics()...)
+		c.agents = append(c.agents, inst.InstalledAgents()...)
+	}()
+
 	// Execute install operation on each source
 	for _, source := range sources {
-		if err := inst.InstallSource(source); err != nil {
+		if c.collectionAgents != nil {
+			err = inst.InstallAgents(ctx.Context(), source, c.collectionAgents)
+		} else {
+			err = inst.InstallSource(ctx.Context(), source)
+		}
+		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// reportMissing warns about the agents of --collection that no source installed
+func (c *InstallCommand) reportMissing(sharedCtx *SharedContext) {
+	_, missing := findNamedAgents(c.agents, c.collectionAgents)
+	for _, name := range missing {
+		PrintWarning("Collection %s: no enabled source provides %s", c.collection, name)
+	}
+	sharedCtx.Summarize("collection", c.collection)
+	sharedCtx.Summarize("missing", len(missing))
+}
+
 // GetOperationName implements CommandExecutor interface
 func (c *InstallCommand) GetOperationName() string {
 	return "Installing"
@@ -78,7 +352,95 @@ func (c *InstallCommand) GetCompletionMessage() string {
 	return "Installation complete"
 }
 
-// ShouldContinueOnError implements CommandExecutor interface
+// ShouldContinueOnError implements CommandExecutor interface; failing
+// sources are isolated unless --fail-fast is given or continue_on_error is
+// explicitly false
 func (c *InstallCommand) ShouldContinueOnError(ctx *SharedContext) bool {
-	return ctx.Config.Settings.ContinueOnError
+	return !c.failFast && ctx.Config.Settings.ContinuesAfterFailure()
+}
+
+// printConflictReport lists how each pre-existing file was resolved during install
+func printConflictReport(w io.Writer, outcomes []conflict.Outcome) {
+	if len(outcomes) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "\nConflict report (%d files):\n", len(outcomes))
+	unresolved, readonly := 0, 0
+	for _, outcome := range outcomes {
+		_, _ = fmt.Fprintf(w, "  %s\n", outcome.Path)
+		_, _ = fmt.Fprintf(w, "    Source: %s | Strategy: %s | Action: %s\n", outcome.Source, outcome.Strategy, outcome.Action)
+		if outcome.BackupPath != "" {
+			_, _ = fmt.Fprintf(w, "    Backup: %s\n", outcome.BackupPath)
+		}
+		if outcome.Detail != "" {
+			_, _ = fmt.Fprintf(w, "    Detail: %s\n", outcome.Detail)
+		}
+		switch outcome.Action {
+		case conflict.ActionMergedWithConflicts:
+			unresolved++
+		case conflict.ActionReadonly:
+			readonly++
+		}
+	}
+
+	if unresolved > 0 {
+		PrintWarning("%d files contain merge conflict markers and need manual resolution", unresolved)
+		PrintInfo("Run 'agent-manager conflicts resolve FILE' to resolve them")
+	}
+	if readonly > 0 {
+		PrintWarning("%d files were not written because they match readonly_paths; check the target paths of their sources", readonly)
+	}
+}
+
+// printPreExisting lists the installed paths that collided with files no
+// source installed, such as ones the user authored
+func printPreExisting(w io.Writer, outcomes []conflict.Outcome) {
+	preExisting := preExistingOutcomes(outcomes)
+	if len(preExisting) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "\nPre-existing files (%d):\n", len(preExisting))
+	protected := 0
+	for _, outcome := range preExisting {
+		_, _ = fmt.Fprintf(w, "  %s (source: %s, %s)\n", outcome.Path, outcome.Source, outcome.Action)
+		if outcome.Action == conflict.ActionProtected {
+			protected++
+		}
+	}
+	if protected > 0 {
+		PrintInfo("%d pre-existing files were kept by settings.protect_pre_existing", protected)
+	}
+}
+
+// preExistingOutcomes returns the outcomes of files no source installed
+func preExistingOutcomes(outcomes []conflict.Outcome) []conflict.Outcome {
+	var preExisting []conflict.Outcome
+	for _, outcome := range outcomes {
+		if outcome.PreExisting {
+			preExisting = append(preExisting, outcome)
+		}
+	}
+	return preExisting
+}
+
+// writeInstallSummary writes per-source metrics and conflict outcomes as JSON to path
+func writeInstallSummary(path string, metrics []installer.SourceMetrics, conflicts []conflict.Outcome) error {
+	summary := installSummary{Generated: time.Now(), Sources: metrics, Conflicts: conflicts}
+	if summary.Sources == nil {
+		summary.Sources = []installer.SourceMetrics{}
+	}
+	if summary.Conflicts == nil {
+		summary.Conflicts = []conflict.Outcome{}
+	}
+
+	content, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal install summary: %w", err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write install summary: %w", err)
+	}
+	return nil
 }
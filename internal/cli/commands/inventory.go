@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/inventory"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+// InventoryCommand implements exporting an SBOM-style inventory of installed agents
+type InventoryCommand struct {
+	sourceName string
+	output     string
+	file       string
+}
+
+// NewInventoryCommand creates a new inventory command instance
+func NewInventoryCommand() *InventoryCommand {
+	return &InventoryCommand{}
+}
+
+// Name returns the command name
+func (c *InventoryCommand) Name() string {
+	return "inventory"
+}
+
+// Description returns the command description
+func (c *InventoryCommand) Description() string {
+	return "Export an SBOM-style inventory of installed agents"
+}
+
+// CreateCommand creates the cobra command for inventory functionality
+func (c *InventoryCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inventory",
+		Short: c.Description(),
+		Long: `Write a machine-readable inventory of the installed agents for security and
+compliance tooling: each agent's name, version (its frontmatter version, or the
+source commit), source URL, SHA-256 hash and frontmatter license.
+
+Examples:
+  agent-manager inventory                                  # CycloneDX JSON on stdout
+  agent-manager inventory --output spdx-json --file agents.spdx.json
+  agent-manager inventory --source team-agents`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.Execute(sharedCtx)
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.output, "output", "o", inventory.FormatCycloneDX, "inventory format ("+strings.Join(inventory.Formats, ", ")+")")
+	cmd.Flags().StringVarP(&c.file, "file", "f", "", "write the inventory to this file instead of stdout")
+	markPathFlag(cmd, "file", pathFile)
+	cmd.Flags().StringVarP(&c.sourceName, "source", "s", "", "inventory a single installed source")
+
+	return cmd
+}
+
+// Execute runs the inventory command logic
+func (c *InventoryCommand) Execute(sharedCtx *SharedContext) error {
+	if c.output != inventory.FormatCycloneDX && c.output != inventory.FormatSPDX {
+		return fmt.Errorf("invalid output format: %s (must be %s)", c.output, strings.Join(inventory.Formats, " or "))
+	}
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	installations, err := sharedCtx.Tracker().List()
+	if err != nil {
+		return fmt.Errorf("failed to read installed sources: %w", err)
+	}
+	if c.sourceName != "" {
+		installation, ok := installations[c.sourceName]
+		if !ok {
+			return fmt.Errorf("source '%s' is not installed", c.sourceName)
+		}
+		installations = map[string]*tracker.Installation{c.sourceName: installation}
+	}
+
+	items, missing, err := inventory.Collect(sharedCtx.Config, installations)
+	if err != nil {
+		return fmt.Errorf("failed to collect inventory: %w", err)
+	}
+	// Warnings go to stderr so the inventory can be piped from stdout
+	for _, path := range missing {
+		fmt.Fprintf(os.Stderr, "Warning: installed agent file is missing: %s\n", path)
+	}
+
+	data, err := inventory.Encode(c.output, items, time.Now())
+	if err != nil {
+		return err
+	}
+	sharedCtx.Summarize("agents", len(items))
+	sharedCtx.Summarize("missing", len(missing))
+
+	if c.file == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(c.file, data, 0600); err != nil {
+		return fmt.Errorf("failed to write inventory: %w", err)
+	}
+	PrintSuccess("Wrote %s inventory of %d agents to %s", c.output, len(items), c.file)
+	return nil
+}
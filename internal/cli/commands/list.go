@@ -2,13 +2,18 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/engine"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
 	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 	"github.com/spf13/cobra"
 )
 
@@ -22,6 +27,15 @@ type ListCommand struct {
 	noTools     bool
 	customTools bool
 	limit       int
+	output      string
+	template    string
+	orphans     bool
+	adopt       bool
+	delete      bool
+	broken      bool
+	preExisting bool
+	sort        string
+	provenance  bool
 }
 
 // NewListCommand creates a new list command instance
@@ -46,7 +60,19 @@ func (c *ListCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: c.Description(),
-		Long:  `List all installed agents or filter by source.`,
+		Long: `List all installed agents or filter by source.
+
+Examples:
+  agent-manager list                                  # List installations
+  agent-manager list --sort age                       # Oldest installs first
+  agent-manager list -o table --sort size             # One line per source, largest first
+  agent-manager list --provenance                     # Installed with the current config?
+  agent-manager list --tools Bash                     # List agents using Bash
+  agent-manager list --template '{{.Name}}\t{{.Source}}' # Custom template
+  agent-manager list --orphans                        # Files no source installed
+  agent-manager list --orphans --adopt                # Track them under the "manual" source
+  agent-manager list --broken                         # Agent files that failed to parse
+  agent-manager list --pre-existing                   # Installed paths that collided with your files`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return c.Execute(sharedCtx)
 		},
@@ -60,6 +86,17 @@ func (c *ListCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
 	cmd.Flags().BoolVar(&c.noTools, "no-tools", false, "show agents with inherited tools only")
 	cmd.Flags().BoolVar(&c.customTools, "custom-tools", false, "show agents with explicit tools only")
 	cmd.Flags().IntVar(&c.limit, "limit", 50, "limit number of results")
+	cmd.Flags().StringVarP(&c.output, "output", "o", "text", "output format (text, table, template)")
+	addTemplateFlag(cmd, &c.template)
+	cmd.Flags().BoolVar(&c.orphans, "orphans", false, "list files in the agents directory that no source installed")
+	cmd.Flags().BoolVar(&c.adopt, "adopt", false, "with --orphans, track the orphaned files under the \"manual\" source")
+	cmd.Flags().BoolVar(&c.delete, "delete", false, "with --orphans, delete the orphaned files after confirmation")
+	cmd.Flags().BoolVar(&c.broken, "broken", false, "list agent files that failed to parse when the index was built")
+	cmd.Flags().BoolVar(&c.preExisting, "pre-existing", false, "list installed files that already existed when their source installed them")
+	cmd.Flags().StringVar(&c.sort, "sort", "name", "sort installed sources by name, age, files, size or checked")
+	cmd.Flags().BoolVar(&c.provenance, "provenance", false, "show whether each source was installed with the current configuration")
+	cmd.MarkFlagsMutuallyExclusive("adopt", "delete")
+	cmd.MarkFlagsMutuallyExclusive("orphans", "broken", "pre-existing")
 
 	return cmd
 }
@@ -71,11 +108,29 @@ func (c *ListCommand) Execute(sharedCtx *SharedContext) error {
 		return fmt.Errorf("configuration error: %w", err)
 	}
 
+	if !slices.Contains(sourceSortKeys, c.sort) {
+		return fmt.Errorf("invalid --sort %q (must be one of: %s)", c.sort, strings.Join(sourceSortKeys, ", "))
+	}
+
+	if c.orphans {
+		return c.executeOrphans(sharedCtx)
+	}
+	if c.adopt || c.delete {
+		return fmt.Errorf("--adopt and --delete require --orphans")
+	}
+	if c.broken {
+		return c.executeBroken(sharedCtx)
+	}
+	if c.preExisting {
+		return c.executePreExisting(sharedCtx)
+	}
+
 	// Check if any search parameters are provided
 	hasSearchParams := c.search != "" || c.name != "" || c.description != "" ||
 		len(c.tools) > 0 || c.noTools || c.customTools
 
-	if hasSearchParams {
+	// Templates render agents, which only the search-based listing produces
+	if hasSearchParams || c.useTemplate() {
 		// Use enhanced search with query engine
 		return c.executeSearchList(sharedCtx)
 	}
@@ -90,7 +145,7 @@ func (c *ListCommand) executeBasicList(sharedCtx *SharedContext) error {
 
 	// Load tracking data
 	err := sharedCtx.PM.WithSpinner("Loading installation data", func() error {
-		track := tracker.New(sharedCtx.Config.Metadata.TrackingFile)
+		track := sharedCtx.Tracker()
 		var loadErr error
 		installations, loadErr = track.List()
 		return loadErr
@@ -101,7 +156,7 @@ func (c *ListCommand) executeBasicList(sharedCtx *SharedContext) error {
 
 	if c.sourceName != "" {
 		if inst, exists := installations[c.sourceName]; exists {
-			c.printInstallation(c.sourceName, *inst)
+			c.printInstallation(c.sourceName, *inst, c.configProvenance(sharedCtx, c.sourceName, inst))
 		} else {
 			PrintWarning("No installation found for source: %s", c.sourceName)
 		}
@@ -114,14 +169,245 @@ func (c *ListCommand) executeBasicList(sharedCtx *SharedContext) error {
 		return nil
 	}
 
+	rows := make([]sourceRow, 0, len(installations))
 	for name, inst := range installations {
-		c.printInstallation(name, *inst)
+		row := newSourceRow(name, inst)
+		row.config = c.configProvenance(sharedCtx, name, inst)
+		rows = append(rows, row)
+	}
+	sortSourceRows(rows, c.sort)
+
+	if c.output == "table" {
+		printSourceTable(rows, time.Now())
+		return nil
+	}
+	for _, row := range rows {
+		c.printInstallation(row.name, *installations[row.name], row.config)
 		fmt.Println()
 	}
 
 	return nil
 }
 
+// sourceSortKeys are the orders the source listing can be sorted in
+var sourceSortKeys = []string{"name", "age", "files", "size", "checked"}
+
+// sourceRow is an installed source as listed in the source table
+type sourceRow struct {
+	name      string
+	installed time.Time
+	files     int
+	size      int64 // bytes the tracked files take on disk
+	checked   time.Time
+	config    string // configuration provenance, shown with --provenance
+}
+
+// newSourceRow summarizes an installation, measuring its files on disk
+func newSourceRow(name string, inst *tracker.Installation) sourceRow {
+	row := sourceRow{name: name, installed: inst.Timestamp, files: len(inst.Files), checked: inst.LastChecked}
+	for path := range inst.Files {
+		if info, err := os.Stat(path); err == nil {
+			row.size += info.Size()
+		}
+	}
+	return row
+}
+
+// sortSourceRows orders rows by key: name alphabetically, age and checked
+// with the stalest source first, files and size with the largest first
+func sortSourceRows(rows []sourceRow, key string) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch key {
+		case "age":
+			if !a.installed.Equal(b.installed) {
+				return a.installed.Before(b.installed)
+			}
+		case "files":
+			if a.files != b.files {
+				return a.files > b.files
+			}
+		case "size":
+			if a.size != b.size {
+				return a.size > b.size
+			}
+		case "checked":
+			if !a.checked.Equal(b.checked) {
+				return a.checked.Before(b.checked)
+			}
+		}
+		return a.name < b.name
+	})
+}
+
+// printSourceTable prints one line per installed source, with a CONFIG
+// column when provenance was requested
+func printSourceTable(rows []sourceRow, now time.Time) {
+	withConfig := len(rows) > 0 && rows[0].config != ""
+	header := fmt.Sprintf("%-30s %-19s %6s %10s  %-19s", "SOURCE", "INSTALLED", "FILES", "SIZE", "CHECKED")
+	if withConfig {
+		header += "  CONFIG"
+	}
+	fmt.Println(strings.TrimRight(header, " "))
+	for _, row := range rows {
+		line := fmt.Sprintf("%-30s %-19s %6d %10s  %-19s", row.name, util.FormatTimeAt(row.installed, now),
+			row.files, formatBytes(row.size), util.FormatTimeAt(row.checked, now))
+		if withConfig {
+			line += "  " + row.config
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+}
+
+// configProvenance describes whether a source was installed with the current
+// configuration, comparing its recorded config hash with the hash of its
+// entry now; it is empty unless --provenance was given
+func (c *ListCommand) configProvenance(sharedCtx *SharedContext, name string, inst *tracker.Installation) string {
+	if !c.provenance {
+		return ""
+	}
+	if inst.ConfigHash == "" {
+		return "not recorded"
+	}
+	short := inst.ConfigHash[:min(12, len(inst.ConfigHash))]
+	source, err := sharedCtx.GetSourceByName(name)
+	switch {
+	case err != nil:
+		return short + " (source no longer configured)"
+	case sharedCtx.Config.SourceHash(*source) == inst.ConfigHash:
+		return short + " (current)"
+	default:
+		return short + " (changed since install)"
+	}
+}
+
+// executeOrphans lists untracked files in the agents directory and optionally
+// adopts them into the manual source or deletes them
+func (c *ListCommand) executeOrphans(sharedCtx *SharedContext) error {
+	track := sharedCtx.Tracker()
+	orphans, err := track.Orphans(sharedCtx.GetAgentsDirectory())
+	if err != nil {
+		return err
+	}
+
+	if len(orphans) == 0 {
+		PrintSuccess("No orphaned files in %s", sharedCtx.GetAgentsDirectory())
+		return nil
+	}
+
+	color.Blue("Orphaned files in %s (%d):\n", sharedCtx.GetAgentsDirectory(), len(orphans))
+	for _, path := range orphans {
+		fmt.Printf("  - %s\n", path)
+	}
+	fmt.Println()
+
+	switch {
+	case c.adopt:
+		if sharedCtx.Options.DryRun {
+			color.Yellow("[DRY RUN] Would adopt %d files into source %s\n", len(orphans), tracker.ManualSource)
+			return nil
+		}
+		if err := track.AdoptFiles(tracker.ManualSource, orphans); err != nil {
+			return fmt.Errorf("failed to adopt files: %w", err)
+		}
+		refreshIndex(sharedCtx)
+		PrintSuccess("Adopted %d files into source %s", len(orphans), tracker.ManualSource)
+	case c.delete:
+		if sharedCtx.Options.DryRun {
+			color.Yellow("[DRY RUN] Would delete %d files\n", len(orphans))
+			return nil
+		}
+		if !Confirm(fmt.Sprintf("Delete %d orphaned files?", len(orphans))) {
+			PrintInfo("No files deleted")
+			return nil
+		}
+		deleted := 0
+		for _, path := range orphans {
+			if err := os.Remove(path); err != nil {
+				PrintWarning("Failed to delete %s: %v", path, err)
+				continue
+			}
+			deleted++
+		}
+		refreshIndex(sharedCtx)
+		PrintSuccess("Deleted %d orphaned files", deleted)
+	default:
+		PrintInfo("Use --adopt to track them under source %q or --delete to remove them", tracker.ManualSource)
+	}
+
+	return nil
+}
+
+// executeBroken lists the agent files recorded as broken in the index, with
+// a suggested fix for each
+func (c *ListCommand) executeBroken(sharedCtx *SharedContext) error {
+	queryEngine, err := sharedCtx.OpenQueryEngine()
+	if err != nil {
+		return err
+	}
+
+	broken := queryEngine.BrokenAgents()
+	if len(broken) == 0 {
+		PrintSuccess("No broken agent files recorded in the index")
+		return nil
+	}
+
+	color.Blue("Broken agent files (%d):\n", len(broken))
+	for _, failure := range broken {
+		color.Red("%s %s\n", util.Symbol("✗"), failure.Path)
+		fmt.Printf("    %s\n", failure.Reason)
+		if !failure.ModTime.IsZero() {
+			fmt.Printf("    modified %s\n", util.FormatTime(failure.ModTime))
+		}
+		if suggestion := fixSuggestion(failure); suggestion != "" {
+			fmt.Printf("    fix: %s\n", suggestion)
+		}
+	}
+	return nil
+}
+
+// executePreExisting lists the tracked files that already existed when their
+// source installed them; uninstalling the source keeps them
+func (c *ListCommand) executePreExisting(sharedCtx *SharedContext) error {
+	installations, err := sharedCtx.Tracker().List()
+	if err != nil {
+		return fmt.Errorf("failed to load tracking data: %w", err)
+	}
+
+	type preExistingFile struct {
+		path   string
+		source string
+	}
+	var files []preExistingFile
+	for name, installation := range installations {
+		if c.sourceName != "" && name != c.sourceName {
+			continue
+		}
+		for path, file := range installation.Files {
+			if file.WasPreExisting {
+				files = append(files, preExistingFile{path: path, source: name})
+			}
+		}
+	}
+	if len(files) == 0 {
+		PrintSuccess("No installed files collided with pre-existing files")
+		return nil
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].source != files[j].source {
+			return files[i].source < files[j].source
+		}
+		return files[i].path < files[j].path
+	})
+
+	color.Blue("Pre-existing files (%d):\n", len(files))
+	for _, file := range files {
+		fmt.Printf("  %s (source: %s)\n", file.path, file.source)
+	}
+	PrintInfo("These files are kept when their source is uninstalled")
+	return nil
+}
+
 // executeSearchList runs the enhanced search-based list functionality
 func (c *ListCommand) executeSearchList(sharedCtx *SharedContext) error {
 	// Initialize query engine
@@ -136,8 +422,14 @@ func (c *ListCommand) executeSearchList(sharedCtx *SharedContext) error {
 			return fmt.Errorf("failed to initialize query engine: %w", engineErr)
 		}
 
+		provenance, err := sharedCtx.installProvenance()
+		if err != nil {
+			return err
+		}
+		queryEngine.SetProvenance(provenance)
+
 		// Build index from tracking data
-		track := tracker.New(sharedCtx.Config.Metadata.TrackingFile)
+		track := sharedCtx.Tracker()
 		agentData, err := track.GetAllAgentMetadata()
 		if err != nil {
 			return fmt.Errorf("failed to load agent metadata: %w", err)
@@ -160,6 +452,7 @@ func (c *ListCommand) executeSearchList(sharedCtx *SharedContext) error {
 				FileName:       agentInfo.FileName,
 				FileSize:       agentInfo.FileSize,
 				ModTime:        agentInfo.ModTime,
+				Namespace:      agentInfo.Namespace,
 				Source:         agentInfo.Source,
 				InstalledAt:    agentInfo.InstalledAt,
 			}
@@ -205,6 +498,10 @@ func (c *ListCommand) executeSearchList(sharedCtx *SharedContext) error {
 		return fmt.Errorf("search failed: %w", err)
 	}
 
+	if c.useTemplate() {
+		return renderAgentsTemplate(sharedCtx, c.template, results)
+	}
+
 	// Display results
 	if len(results) == 0 {
 		PrintWarning("No agents found matching search criteria")
@@ -222,14 +519,45 @@ func (c *ListCommand) executeSearchList(sharedCtx *SharedContext) error {
 	return nil
 }
 
+// useTemplate reports whether results are rendered with an output template
+func (c *ListCommand) useTemplate() bool {
+	return c.output == outputTemplate || c.template != ""
+}
+
 // printInstallation prints installation details in the original format
-func (c *ListCommand) printInstallation(name string, inst tracker.Installation) {
+func (c *ListCommand) printInstallation(name string, inst tracker.Installation, provenance string) {
+	row := newSourceRow(name, &inst)
+	now := time.Now()
 	color.Green("Source: %s\n", name)
-	fmt.Printf("  Installed: %s\n", inst.Timestamp.Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Installed: %s\n", util.FormatTimeAt(inst.Timestamp, now))
+	fmt.Printf("  Last update check: %s\n", util.FormatTimeAt(inst.LastChecked, now))
 	if inst.SourceCommit != "" {
 		fmt.Printf("  Commit: %s\n", inst.SourceCommit)
 	}
-	fmt.Printf("  Files: %d\n", len(inst.Files))
+	if inst.Mirror != "" {
+		fmt.Printf("  Mirror: %s\n", inst.Mirror)
+	}
+	if provenance != "" {
+		fmt.Printf("  Config: %s\n", provenance)
+	}
+	fmt.Printf("  Files: %d (%s)\n", row.files, formatBytes(row.size))
+
+	if len(inst.Categories) > 0 {
+		fmt.Println("  Categories:")
+		categories := make([]string, 0, len(inst.Categories))
+		for category := range inst.Categories {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+		for _, category := range categories {
+			entry := inst.Categories[category]
+			fmt.Printf("    - %s: %d files", category, len(entry.Files))
+			if entry.SourceCommit != "" {
+				fmt.Printf(" (%s)", entry.SourceCommit)
+			}
+			fmt.Println()
+		}
+	}
 
 	if len(inst.Directories) > 0 {
 		fmt.Println("  Directories:")
@@ -237,9 +565,9 @@ func (c *ListCommand) printInstallation(name string, inst tracker.Installation)
 			fmt.Printf("    - %s\n", dir)
 		}
 	}
-	if len(inst.DocsGenerated) > 0 {
+	if docs := inst.DocPaths(); len(docs) > 0 {
 		fmt.Println("  Documentation:")
-		for _, doc := range inst.DocsGenerated {
+		for _, doc := range docs {
 			fmt.Printf("    - %s\n", doc)
 		}
 	}
@@ -247,9 +575,15 @@ func (c *ListCommand) printInstallation(name string, inst tracker.Installation)
 
 // printAgentSummary prints agent details in search result format
 func (c *ListCommand) printAgentSummary(agent *parser.AgentSpec) {
-	color.Cyan("● %s", agent.Name)
+	color.Cyan("%s %s", util.Symbol("●"), agent.QualifiedName())
 	fmt.Printf("  %s\n", agent.Description)
 	fmt.Printf("  Source: %s | File: %s\n", agent.Source, agent.FileName)
+	if agent.Version != "" {
+		fmt.Printf("  Version: %s\n", agent.Version)
+	}
+	if agent.License != "" {
+		fmt.Printf("  License: %s\n", agent.License)
+	}
 
 	if !agent.ToolsInherited && len(agent.GetToolsAsSlice()) > 0 {
 		fmt.Printf("  Tools: %s\n", strings.Join(agent.GetToolsAsSlice(), ", "))
@@ -257,5 +591,5 @@ func (c *ListCommand) printAgentSummary(agent *parser.AgentSpec) {
 		fmt.Printf("  Tools: inherited\n")
 	}
 
-	fmt.Printf("  Updated: %s\n", agent.ModTime.Format("2006-01-02"))
+	fmt.Printf("  Updated: %s\n", util.FormatTime(agent.ModTime))
 }
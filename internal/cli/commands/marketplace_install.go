@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/bundle"
+	"github.com/pacphi/claude-code-agent-manager/internal/cli/marketplace"
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/conflict"
+	"github.com/pacphi/claude-code-agent-manager/internal/installer"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/pacphi/claude-code-agent-manager/internal/types"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// newMarketplaceInstaller returns how marketplace install writes an agent:
+// into settings.base_dir like import does, resolving conflicts with
+// settings.conflict_strategy and tracking it under the marketplace source
+func newMarketplaceInstaller(sharedCtx *SharedContext) marketplace.AgentInstaller {
+	return func(cmd *cobra.Command, agent types.Agent, content string) error {
+		sharedCtx.mutating = true
+		if err := sharedCtx.discoverConfig(cmd, nil); err != nil {
+			return err
+		}
+		if err := sharedCtx.LoadConfig(); err != nil {
+			return fmt.Errorf("configuration error: %w", err)
+		}
+
+		name := agent.Slug + ".md"
+		target := filepath.Join(sharedCtx.GetAgentsDirectory(), name)
+		if pattern := config.MatchReadonly(sharedCtx.Config.Settings.ReadonlyPaths, target); pattern != "" {
+			return fmt.Errorf("not installing %s: it matches readonly_paths pattern %q", target, pattern)
+		}
+
+		stageDir, err := util.MkdirTemp("agent-manager-marketplace-")
+		if err != nil {
+			return fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		defer os.RemoveAll(stageDir)
+
+		item := importedAgent{
+			entry:   bundle.Agent{Path: name},
+			content: []byte(installer.FormatMarketplaceAgent(agent, content)),
+			staged:  filepath.Join(stageDir, name),
+			target:  target,
+			source:  tracker.MarketplaceSource,
+		}
+		if err := os.WriteFile(item.staged, item.content, 0600); err != nil {
+			return fmt.Errorf("failed to stage %s: %w", name, err)
+		}
+
+		strategy := sharedCtx.Config.Settings.ConflictStrategy
+		resolver := conflict.NewResolver(strategy, sharedCtx.Config.Settings.BackupDir)
+		outcome, err := (&ImportCommand{}).install(sharedCtx, sharedCtx.Tracker(), resolver, strategy, item)
+		if err != nil {
+			return err
+		}
+		if outcome != nil {
+			printConflictReport(os.Stdout, []conflict.Outcome{*outcome})
+			if outcome.Action == conflict.ActionSkipped {
+				return nil
+			}
+		}
+		if sharedCtx.Options.DryRun {
+			color.Yellow("[DRY RUN] Would install %s to %s\n", agent.Name, target)
+			return nil
+		}
+
+		refreshIndex(sharedCtx)
+		PrintSuccess("Installed %s to %s (tracked under source %s)", agent.Name, target, tracker.MarketplaceSource)
+		return nil
+	}
+}
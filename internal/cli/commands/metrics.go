@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/metrics"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+// writeRunMetrics updates the settings.metrics.textfile snapshot after a run
+// of command that started at start and ended with runErr. Commands that never
+// loaded the configuration write nothing.
+func writeRunMetrics(sharedCtx *SharedContext, command string, start time.Time, runErr error) error {
+	if sharedCtx.Config == nil || sharedCtx.Config.Settings.Metrics.Textfile == "" {
+		return nil
+	}
+	path := sharedCtx.Config.Settings.Metrics.Textfile
+
+	snapshot, err := metrics.Load(path)
+	if err != nil {
+		return err
+	}
+
+	runErr = runFailure(runErr)
+	snapshot.RecordRun(command, start, time.Since(start), runErr)
+
+	installations, err := sharedCtx.Tracker().List()
+	if err != nil {
+		return err
+	}
+	snapshot.Installed = make(map[string]int, len(installations))
+	for name, installation := range installations {
+		snapshot.Installed[name] = len(installation.Files)
+	}
+	for _, inst := range sharedCtx.installers {
+		for name, available := range inst.UpdateChecks() {
+			snapshot.UpdateAvailable[name] = available
+		}
+	}
+	for name := range snapshot.UpdateAvailable {
+		if source, _ := tracker.SplitSubSource(name); installations[source] == nil {
+			delete(snapshot.UpdateAvailable, name)
+		}
+	}
+
+	return snapshot.Write(path)
+}
+
+// RecordRun writes the metrics snapshot for the command that ran, if one is
+// configured. Failing to write it is reported but does not fail the command.
+func (r *CommandRegistry) RecordRun(cmd *cobra.Command, runErr error) {
+	if cmd == nil || r.started.IsZero() {
+		return
+	}
+	if err := writeRunMetrics(r.sharedCtx, topLevel(cmd).Name(), r.started, runErr); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write metrics: %v\n", err)
+	}
+	if err := writeUsage(r.sharedCtx, topLevel(cmd).Name(), r.started, runErr); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record usage: %v\n", err)
+	}
+}
+
+// runFailure returns the error a run failed with, or nil for runs that only
+// planned their changes: they did what they were asked to
+func runFailure(runErr error) error {
+	var exitErr *ExitError
+	if errors.As(runErr, &exitErr) && exitErr.Code == ExitPlanned {
+		return nil
+	}
+	return runErr
+}
+
+// usagePath returns the local usage record written when settings.metrics.usage is on
+func usagePath(sharedCtx *SharedContext) string {
+	return filepath.Join(sharedCtx.Config.Settings.BaseDir, ".agent-usage")
+}
+
+// writeUsage adds a run of command to the local usage record when
+// settings.metrics.usage is on. Commands that never loaded the configuration
+// record nothing.
+func writeUsage(sharedCtx *SharedContext, command string, start time.Time, runErr error) error {
+	if sharedCtx.Config == nil || !sharedCtx.Config.Settings.Metrics.Usage {
+		return nil
+	}
+	path := usagePath(sharedCtx)
+
+	usage, err := metrics.LoadUsage(path)
+	if err != nil {
+		return err
+	}
+	usage.Record(command, start, time.Since(start), errorCategory(runFailure(runErr)))
+	return usage.Write(path)
+}
+
+// errorCategory classifies the error of a failed run without keeping its
+// message, which may name private paths or sources; nil yields ""
+func errorCategory(err error) string {
+	var (
+		exitErr       *ExitError
+		validationErr *ValidationError
+		strictErr     *parser.StrictError
+		netErr        net.Error
+	)
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.As(err, &validationErr):
+		return "invalid_agents"
+	case errors.As(err, &exitErr) && exitErr.Code == ExitPartial:
+		return "partial"
+	case errors.As(err, &strictErr):
+		return "parse"
+	case errors.As(err, &netErr):
+		return "network"
+	case errors.Is(err, os.ErrPermission):
+		return "permission"
+	case errors.Is(err, os.ErrNotExist):
+		return "not_found"
+	case strings.HasPrefix(err.Error(), "configuration error"):
+		return "config"
+	}
+	return "other"
+}
@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// ParseReportCommand implements the parse-report command functionality
+type ParseReportCommand struct {
+	format string
+}
+
+// NewParseReportCommand creates a new parse-report command instance
+func NewParseReportCommand() *ParseReportCommand {
+	return &ParseReportCommand{
+		format: "text",
+	}
+}
+
+// Name returns the command name
+func (c *ParseReportCommand) Name() string {
+	return "parse-report"
+}
+
+// Description returns the command description
+func (c *ParseReportCommand) Description() string {
+	return "List agent files that cannot be parsed"
+}
+
+// CreateCommand creates the cobra command for parse-report functionality
+func (c *ParseReportCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "parse-report",
+		Short: c.Description(),
+		Long: `List every agent file in the agents directory that cannot be parsed, with
+the reason, and whether parser_mode: recover would repair it.
+
+Examples:
+  agent-manager parse-report               # Show unparseable files
+  agent-manager parse-report --format json # Machine-readable report`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.Execute(sharedCtx)
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.format, "format", "f", "text", "output format (text, json)")
+
+	return cmd
+}
+
+// parseReportEntry is a single unparseable file in the report
+type parseReportEntry struct {
+	parser.ParseFailure
+	Recoverable bool `json:"recoverable"`
+}
+
+// Execute runs the parse-report command logic
+func (c *ParseReportCommand) Execute(sharedCtx *SharedContext) error {
+	if c.format != "text" && c.format != "json" {
+		return fmt.Errorf("unsupported format: %s (must be text or json)", c.format)
+	}
+
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	agentsDir := sharedCtx.GetAgentsDirectory()
+	p := parser.NewParser()
+	p.Extensions = sharedCtx.Config.Settings.Query.Index.Extensions
+
+	var agents []*parser.AgentSpec
+	var failures []parser.ParseFailure
+	err := sharedCtx.PM.WithSpinner("Parsing agents", func() error {
+		var parseErr error
+		agents, failures, parseErr = p.ParseDirectoryReport(agentsDir)
+		return parseErr
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", agentsDir, err)
+	}
+
+	entries := make([]parseReportEntry, 0, len(failures))
+	for _, failure := range failures {
+		entries = append(entries, parseReportEntry{
+			ParseFailure: failure,
+			Recoverable:  isRecoverable(failure.Path),
+		})
+	}
+
+	if c.format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	c.printReport(entries, len(agents))
+	return nil
+}
+
+// printReport prints the report in text format
+func (c *ParseReportCommand) printReport(entries []parseReportEntry, parsed int) {
+	if len(entries) == 0 {
+		PrintSuccess("All %d agent files parsed successfully", parsed)
+		return
+	}
+
+	recoverable := 0
+	for _, entry := range entries {
+		color.Red("%s %s\n", util.Symbol("✗"), entry.Path)
+		fmt.Printf("    %s\n", entry.Reason)
+		if entry.Recoverable {
+			recoverable++
+			fmt.Printf("    recoverable with parser_mode: recover\n")
+		}
+	}
+
+	fmt.Println()
+	PrintWarning("%d of %d agent files failed to parse", len(entries), len(entries)+parsed)
+	if recoverable > 0 {
+		PrintInfo("%d can be repaired by parser_mode: recover", recoverable)
+	}
+}
+
+// fixSuggestion returns a suggested fix for a file that failed to parse, or
+// an empty string when there is none
+func fixSuggestion(failure parser.ParseFailure) string {
+	switch {
+	case isRecoverable(failure.Path):
+		return "close the frontmatter with a --- line, or set parser_mode: recover"
+	case strings.Contains(failure.Reason, "missing frontmatter"):
+		return "start the file with a frontmatter block between --- lines declaring name and description"
+	case strings.Contains(failure.Reason, "failed to parse frontmatter"):
+		return "fix the frontmatter YAML; quote values that contain colons"
+	case strings.Contains(failure.Reason, "failed to read file"):
+		return "check that the file is readable"
+	case strings.Contains(failure.Reason, util.ErrMaxDepth.Error()):
+		return "flatten the directory, or raise settings.walk.max_depth"
+	default:
+		return ""
+	}
+}
+
+// isRecoverable reports whether recover mode would parse the file at path
+func isRecoverable(path string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	recovered, ok := parser.RecoverFrontmatter(string(content))
+	if !ok {
+		return false
+	}
+	_, err = parser.NewParser().ParseContent([]byte(recovered))
+	return err == nil
+}
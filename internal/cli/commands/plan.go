@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/installer"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+// PlanCommand implements diffing the installed state against a configuration
+type PlanCommand struct {
+	sourceName string
+	output     string
+	diff       bool
+}
+
+// NewPlanCommand creates a new plan command instance
+func NewPlanCommand() *PlanCommand {
+	return &PlanCommand{}
+}
+
+// Name returns the command name
+func (c *PlanCommand) Name() string {
+	return "plan"
+}
+
+// Description returns the command description
+func (c *PlanCommand) Description() string {
+	return "Show what installing a configuration would change"
+}
+
+// CreateCommand creates the cobra command for plan functionality
+func (c *PlanCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: c.Description(),
+		Long: `Compare the currently installed agents with what the configuration would
+install: sources added or removed, and agent files added, changed or removed.
+Sources are fetched to temporary directories; nothing is installed, removed or
+tracked, so a configuration change can be reviewed before it is rolled out.
+Use --diff to include a unified diff of each changed agent.
+
+Examples:
+  agent-manager plan --config new-agents-config.yaml
+  agent-manager plan --config new-agents-config.yaml --source team-agents
+  agent-manager plan --config new-agents-config.yaml --diff
+  agent-manager plan --config new-agents-config.yaml --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.Execute(sharedCtx)
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.sourceName, "source", "s", "", "plan a single source")
+	cmd.Flags().StringVarP(&c.output, "output", "o", "text", "output format (text, json)")
+	cmd.Flags().BoolVar(&c.diff, "diff", false, "include the unified diff of each changed agent")
+
+	return cmd
+}
+
+// Execute runs the plan command logic
+func (c *PlanCommand) Execute(sharedCtx *SharedContext) error {
+	if c.output != "text" && c.output != "json" {
+		return fmt.Errorf("invalid output format: %s (must be text or json)", c.output)
+	}
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	sources, err := sharedCtx.FilterEnabledSources(c.sourceName)
+	if err != nil {
+		return err
+	}
+
+	inst, err := sharedCtx.createInstallerWithOptions(installer.Options{Verbose: sharedCtx.Options.Verbose, Diff: c.diff})
+	if err != nil {
+		return err
+	}
+
+	var plans []*installer.SourcePlan
+	var failed []string
+	for _, source := range sources {
+		plan, err := inst.PlanSource(sharedCtx.Context(), source)
+		if err != nil {
+			PrintError("Failed to plan %s: %v", source.Name, err)
+			failed = append(failed, source.Name)
+			continue
+		}
+		plans = append(plans, plan)
+	}
+
+	// Installed sources the configuration no longer installs
+	if c.sourceName == "" {
+		removed, err := removedSources(sharedCtx, sources)
+		if err != nil {
+			return err
+		}
+		plans = append(plans, removed...)
+	}
+
+	summarizePlan(sharedCtx, plans)
+
+	if c.output == "json" {
+		if plans == nil {
+			plans = []*installer.SourcePlan{}
+		}
+		content, err := json.MarshalIndent(plans, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal plan: %w", err)
+		}
+		fmt.Println(string(content))
+	} else {
+		displayPlan(plans, sharedCtx.Options.Verbose)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to plan %d sources: %v", len(failed), failed)
+	}
+	return nil
+}
+
+// removedSources returns removal plans for tracked sources that are not
+// enabled in the configuration. The synthetic sources agents installed from
+// stdin, adopted or installed from the marketplace are tracked under are
+// never configured, so they are kept.
+func removedSources(sharedCtx *SharedContext, enabled []config.Source) ([]*installer.SourcePlan, error) {
+	installations, err := sharedCtx.Tracker().List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read installed sources: %w", err)
+	}
+
+	keep := map[string]bool{tracker.ManualSource: true, tracker.MarketplaceSource: true}
+	for _, source := range enabled {
+		keep[source.Name] = true
+	}
+
+	names := make([]string, 0, len(installations))
+	for name := range installations {
+		if !keep[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	plans := make([]*installer.SourcePlan, 0, len(names))
+	for _, name := range names {
+		plans = append(plans, installer.PlanRemoval(name, installations[name], sharedCtx.Config.Settings.Query.Index.Extensions))
+	}
+	return plans, nil
+}
+
+// summarizePlan records the agents to add, change and remove for the quiet-mode summary
+func summarizePlan(sharedCtx *SharedContext, plans []*installer.SourcePlan) {
+	counts := make(map[string]int)
+	for _, plan := range plans {
+		for _, file := range plan.Files {
+			if file.Agent {
+				counts[file.Action]++
+			}
+		}
+	}
+	sharedCtx.Summarize("sources", len(plans))
+	sharedCtx.Summarize("add", counts[installer.PlanAdded])
+	sharedCtx.Summarize("change", counts[installer.PlanChanged])
+	sharedCtx.Summarize("remove", counts[installer.PlanRemoved])
+}
+
+// displayPlan prints each source's changes followed by an agent summary
+func displayPlan(plans []*installer.SourcePlan, verbose bool) {
+	symbols := map[string]string{
+		installer.PlanAdded:     "+",
+		installer.PlanRemoved:   "-",
+		installer.PlanChanged:   "~",
+		installer.PlanUnchanged: "=",
+	}
+
+	counts := make(map[string]int)
+	reconfigured := 0
+	for _, plan := range plans {
+		line := fmt.Sprintf("%s %s (%s)", symbols[plan.Action], plan.Source, plan.Action)
+		switch plan.Action {
+		case installer.PlanAdded:
+			color.Green("%s\n", line)
+		case installer.PlanRemoved:
+			color.Red("%s\n", line)
+		case installer.PlanChanged:
+			color.Yellow("%s\n", line)
+		default:
+			fmt.Println(line)
+		}
+
+		for _, file := range plan.Files {
+			if file.Agent {
+				counts[file.Action]++
+			}
+			if file.Action != installer.PlanUnchanged || verbose {
+				fmt.Printf("    %s %s\n", symbols[file.Action], file.Path)
+			}
+			if file.Diff != "" {
+				for _, line := range strings.Split(strings.TrimRight(file.Diff, "\n"), "\n") {
+					fmt.Printf("      %s\n", line)
+				}
+			}
+		}
+		for _, note := range plan.Notes {
+			PrintWarning("    %s", note)
+		}
+		if plan.ConfigChanged {
+			fmt.Println("    configuration changed since install")
+			reconfigured++
+		}
+	}
+
+	if counts[installer.PlanAdded]+counts[installer.PlanChanged]+counts[installer.PlanRemoved]+reconfigured == 0 {
+		PrintSuccess("No changes: installed agents match the configuration")
+		return
+	}
+	fmt.Printf("\nPlan: %d agents to add, %d to change, %d to remove\n",
+		counts[installer.PlanAdded], counts[installer.PlanChanged], counts[installer.PlanRemoved])
+	if reconfigured > 0 {
+		fmt.Printf("%d sources to reinstall for configuration changes\n", reconfigured)
+	}
+}
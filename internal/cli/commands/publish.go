@@ -0,0 +1,326 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// PublishCommand implements exporting installed agents to a Git repository
+type PublishCommand struct {
+	repo     string
+	branch   string
+	path     string
+	query    string
+	message  string
+	pr       bool
+	prBranch string
+	timeout  time.Duration
+}
+
+// NewPublishCommand creates a new publish command instance
+func NewPublishCommand() *PublishCommand {
+	return &PublishCommand{
+		path: "agents",
+	}
+}
+
+// Name returns the command name
+func (c *PublishCommand) Name() string {
+	return "publish"
+}
+
+// Description returns the command description
+func (c *PublishCommand) Description() string {
+	return "Publish installed agents to a Git repository"
+}
+
+// CreateCommand creates the cobra command for publish functionality
+func (c *PublishCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "publish --repo URL",
+		Short: c.Description(),
+		Long: `Copy installed agents into a clone of a Git repository, commit them with a
+generated message recording where each agent came from, and push the result.
+With --pr the commit is pushed to a new branch and a pull request is opened
+using the GitHub CLI, so a curated local set can be shared as a team source.
+
+Examples:
+  agent-manager publish --repo git@github.com:org/agents.git
+  agent-manager publish --repo git@github.com:org/agents.git --query "tools:Bash" --pr
+  agent-manager publish --repo https://github.com/org/agents.git --path team --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.Execute(sharedCtx)
+		},
+	}
+
+	cmd.Flags().StringVar(&c.repo, "repo", "", "Git repository to publish to (required)")
+	cmd.Flags().StringVar(&c.branch, "branch", "", "base branch to publish to (default: repository default branch)")
+	cmd.Flags().StringVar(&c.path, "path", "agents", "directory within the repository for agent files")
+	cmd.Flags().StringVarP(&c.query, "query", "q", "", "publish only agents matching a query (default: all installed agents)")
+	cmd.Flags().StringVarP(&c.message, "message", "m", "", "commit message subject (default: generated)")
+	cmd.Flags().BoolVar(&c.pr, "pr", false, "push to a new branch and open a pull request with gh")
+	cmd.Flags().StringVar(&c.prBranch, "pr-branch", "", "branch name for --pr (default: agent-manager/publish-<timestamp>)")
+	AddTimeoutFlag(cmd, &c.timeout)
+	_ = cmd.MarkFlagRequired("repo")
+
+	return cmd
+}
+
+// publishedAgent is an agent selected for publishing with its provenance
+type publishedAgent struct {
+	agent  *parser.AgentSpec
+	source string
+	commit string
+}
+
+// Execute runs the publish command logic
+func (c *PublishCommand) Execute(sharedCtx *SharedContext) error {
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	cancel := sharedCtx.WithTimeout(c.timeout)
+	defer cancel()
+
+	if err := util.ValidatePath(c.path); err != nil {
+		return fmt.Errorf("invalid --path: %w", err)
+	}
+
+	queryEngine, err := sharedCtx.CreateQueryEngine()
+	if err != nil {
+		return err
+	}
+
+	agents := queryEngine.GetAllAgents()
+	if c.query != "" {
+		if agents, err = selectAgents(queryEngine, c.query); err != nil {
+			return err
+		}
+	}
+	if len(agents) == 0 {
+		PrintWarning("No agents to publish")
+		return nil
+	}
+
+	published, err := c.collect(sharedCtx, agents)
+	if err != nil {
+		return err
+	}
+
+	workBranch := c.branch
+	if c.pr {
+		workBranch = c.prBranch
+		if workBranch == "" {
+			workBranch = "agent-manager/publish-" + time.Now().Format("20060102-150405")
+		}
+	}
+
+	if sharedCtx.Options.DryRun {
+		for _, entry := range published {
+			fmt.Printf("  %s -> %s\n", entry.agent.FilePath, filepath.Join(c.path, entry.agent.FileName))
+		}
+		color.Yellow("[DRY RUN] Would publish %d agents to %s\n", len(published), c.repo)
+		if c.pr {
+			color.Yellow("[DRY RUN] Would open a pull request from branch %s\n", workBranch)
+		}
+		return nil
+	}
+
+	return c.publish(sharedCtx.Context(), sharedCtx, published, workBranch)
+}
+
+// collect pairs each agent with the source and commit it was installed from
+func (c *PublishCommand) collect(sharedCtx *SharedContext, agents []*parser.AgentSpec) ([]publishedAgent, error) {
+	installations, err := sharedCtx.Tracker().List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load installation tracking: %w", err)
+	}
+
+	provenance := make(map[string]publishedAgent)
+	for name, installation := range installations {
+		for path := range installation.Files {
+			if absPath, err := filepath.Abs(path); err == nil {
+				provenance[absPath] = publishedAgent{source: name, commit: installation.SourceCommit}
+			}
+		}
+	}
+
+	names := make(map[string]string)
+	published := make([]publishedAgent, 0, len(agents))
+	for _, agent := range agents {
+		if other, ok := names[agent.FileName]; ok {
+			return nil, fmt.Errorf("cannot publish %s and %s: both are named %s", other, agent.FilePath, agent.FileName)
+		}
+		names[agent.FileName] = agent.FilePath
+
+		entry := publishedAgent{source: agent.Source}
+		if absPath, err := filepath.Abs(agent.FilePath); err == nil {
+			if tracked, ok := provenance[absPath]; ok {
+				entry = tracked
+			}
+		}
+		entry.agent = agent
+		published = append(published, entry)
+	}
+
+	return published, nil
+}
+
+// publish clones the repository, copies the agents in, commits and pushes
+func (c *PublishCommand) publish(ctx context.Context, sharedCtx *SharedContext, published []publishedAgent, workBranch string) error {
+	tempDir, err := util.MkdirTemp("agent-manager-publish-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cloneDir := filepath.Join(tempDir, "repo")
+	cloneArgs := []string{"clone", "--depth", "1"}
+	if c.branch != "" {
+		cloneArgs = append(cloneArgs, "--branch", c.branch)
+	}
+	cloneArgs = append(cloneArgs, "--", c.repo, cloneDir)
+
+	if err := sharedCtx.PM.WithSpinner("Cloning "+c.repo, func() error {
+		_, err := runTool(ctx, tempDir, "git", cloneArgs...)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if c.pr {
+		if _, err := runTool(ctx, cloneDir, "git", "checkout", "-b", workBranch); err != nil {
+			return err
+		}
+	}
+
+	targetDir, err := util.SecureJoin(cloneDir, c.path)
+	if err != nil {
+		return fmt.Errorf("invalid --path: %w", err)
+	}
+	if err := os.MkdirAll(targetDir, 0750); err != nil {
+		return fmt.Errorf("failed to create %s: %w", c.path, err)
+	}
+	for _, entry := range published {
+		content, err := os.ReadFile(entry.agent.FilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", entry.agent.FilePath, err)
+		}
+		if err := os.WriteFile(filepath.Join(targetDir, entry.agent.FileName), content, 0600); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", entry.agent.FileName, err)
+		}
+	}
+
+	if _, err := runTool(ctx, cloneDir, "git", "add", "--", c.path); err != nil {
+		return err
+	}
+	status, err := runTool(ctx, cloneDir, "git", "status", "--porcelain", "--", c.path)
+	if err != nil {
+		return err
+	}
+	if status == "" {
+		PrintInfo("%s is already up to date", c.repo)
+		return nil
+	}
+
+	subject, body := publishCommitMessage(published, c.message)
+	messageFile := filepath.Join(tempDir, "COMMIT_MSG")
+	if err := os.WriteFile(messageFile, []byte(subject+"\n\n"+body), 0600); err != nil {
+		return fmt.Errorf("failed to write commit message: %w", err)
+	}
+	if _, err := runTool(ctx, cloneDir, "git", "commit", "-F", messageFile); err != nil {
+		return err
+	}
+
+	pushArgs := []string{"push", "origin", "HEAD"}
+	if c.pr {
+		pushArgs = []string{"push", "-u", "origin", workBranch}
+	}
+	if err := sharedCtx.PM.WithSpinner("Pushing to "+c.repo, func() error {
+		_, err := runTool(ctx, cloneDir, "git", pushArgs...)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	PrintSuccess("Published %d agents to %s", len(published), c.repo)
+
+	if !c.pr {
+		return nil
+	}
+
+	prBodyFile := filepath.Join(tempDir, "PR_BODY")
+	if err := os.WriteFile(prBodyFile, []byte(body), 0600); err != nil {
+		return fmt.Errorf("failed to write pull request body: %w", err)
+	}
+	prArgs := []string{"pr", "create", "--head", workBranch, "--title", subject, "--body-file", prBodyFile}
+	if c.branch != "" {
+		prArgs = append(prArgs, "--base", c.branch)
+	}
+	url, err := runTool(ctx, cloneDir, "gh", prArgs...)
+	if err != nil {
+		return err
+	}
+	PrintSuccess("Opened pull request %s", url)
+	return nil
+}
+
+// publishCommitMessage builds the commit subject and a body listing each
+// agent with the source and commit it was installed from
+func publishCommitMessage(published []publishedAgent, subject string) (string, string) {
+	if subject == "" {
+		subject = fmt.Sprintf("Publish %d agents", len(published))
+		if len(published) == 1 {
+			subject = fmt.Sprintf("Publish agent %s", published[0].agent.Name)
+		}
+	}
+
+	lines := make([]string, 0, len(published))
+	for _, entry := range published {
+		line := fmt.Sprintf("- %s", entry.agent.Name)
+		switch {
+		case entry.source != "" && entry.commit != "":
+			line += fmt.Sprintf(" (source: %s @ %s)", entry.source, util.ShortCommit(entry.commit))
+		case entry.source != "":
+			line += fmt.Sprintf(" (source: %s)", entry.source)
+		}
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+
+	body := "Published by agent-manager.\n\n" + strings.Join(lines, "\n") + "\n"
+	return subject, body
+}
+
+// runTool runs git or gh in dir and returns its trimmed standard output. The
+// SSH agent variables are passed through so clones and pushes over SSH can
+// authenticate.
+func runTool(ctx context.Context, dir, name string, args ...string) (string, error) {
+	cmd, err := util.SecureCommandContext(ctx, name, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to create secure command: %w", err)
+	}
+	cmd.Dir = dir
+	cmd.Env = append(cmd.Env, util.SSHAgentEnv()...)
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("%s %s aborted: %w", name, args[0], ctx.Err())
+		}
+		return "", fmt.Errorf("%s %s failed: %s", name, args[0], strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
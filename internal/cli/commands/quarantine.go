@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/quarantine"
+	"github.com/spf13/cobra"
+)
+
+// QuarantineCommand implements disabling a source and isolating its agents
+type QuarantineCommand struct {
+	reason string
+}
+
+// NewQuarantineCommand creates a new quarantine command instance
+func NewQuarantineCommand() *QuarantineCommand {
+	return &QuarantineCommand{}
+}
+
+// Name returns the command name
+func (c *QuarantineCommand) Name() string {
+	return "quarantine"
+}
+
+// Description returns the command description
+func (c *QuarantineCommand) Description() string {
+	return "Disable a source and move its agents into quarantine"
+}
+
+// CreateCommand creates the cobra command for quarantine functionality
+func (c *QuarantineCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "quarantine SOURCE",
+		Short: c.Description(),
+		Long: `Quickly take a compromised source out of use. The source is disabled in the
+configuration, its installed files are moved to a quarantine area next to the
+tracking file, the action is recorded in the installation log, and the source
+cannot be installed again until it is released with unquarantine.
+
+Examples:
+  agent-manager quarantine community-agents --reason "upstream compromised"
+  agent-manager quarantine community-agents --dry-run`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.Execute(sharedCtx, args[0])
+		},
+	}
+
+	cmd.Flags().StringVar(&c.reason, "reason", "", "reason recorded with the quarantine")
+
+	return cmd
+}
+
+// Execute runs the quarantine command logic
+func (c *QuarantineCommand) Execute(sharedCtx *SharedContext, sourceName string) error {
+	if strings.Contains(sourceName, "/") {
+		return fmt.Errorf("quarantine applies to whole sources, not categories: %s", sourceName)
+	}
+
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	store := quarantine.New(quarantine.DefaultDir(sharedCtx.Config.Metadata.TrackingFile))
+	if store.IsQuarantined(sourceName) {
+		return fmt.Errorf("source %s is already quarantined", sourceName)
+	}
+
+	track := sharedCtx.Tracker()
+	installation, _ := track.GetInstallation(sourceName)
+	source, _ := sharedCtx.GetSourceByName(sourceName)
+	if installation == nil && source == nil {
+		return fmt.Errorf("source not found: %s", sourceName)
+	}
+
+	if sharedCtx.Options.DryRun {
+		files := 0
+		if installation != nil {
+			files = len(installation.Files)
+		}
+		color.Yellow("[DRY RUN] Would quarantine source %s and move %d installed files\n", sourceName, files)
+		return nil
+	}
+
+	wasEnabled := source != nil && source.Enabled
+	record, err := store.Quarantine(sourceName, installation, c.reason, wasEnabled)
+	if err != nil {
+		return err
+	}
+
+	if installation != nil {
+		if err := track.RemoveInstallation(sourceName); err != nil {
+			return fmt.Errorf("failed to update installation tracking: %w", err)
+		}
+	}
+	if wasEnabled {
+		if err := config.SetSourceEnabled(sharedCtx.Options.ConfigFile, sourceName, false); err != nil {
+			PrintWarning("Failed to disable source in configuration: %v", err)
+		}
+	}
+	if err := quarantine.AppendAuditLog(sharedCtx.Config.Metadata.LogFile, "quarantine", record); err != nil {
+		PrintWarning("Failed to record quarantine in audit log: %v", err)
+	}
+
+	refreshIndex(sharedCtx)
+
+	PrintSuccess("Quarantined source %s (%d files moved)", sourceName, len(record.Files))
+	PrintInfo("Run 'agent-manager unquarantine %s' to restore it", sourceName)
+	return nil
+}
+
+// UnquarantineCommand implements releasing a quarantined source
+type UnquarantineCommand struct{}
+
+// NewUnquarantineCommand creates a new unquarantine command instance
+func NewUnquarantineCommand() *UnquarantineCommand {
+	return &UnquarantineCommand{}
+}
+
+// Name returns the command name
+func (c *UnquarantineCommand) Name() string {
+	return "unquarantine"
+}
+
+// Description returns the command description
+func (c *UnquarantineCommand) Description() string {
+	return "Restore a quarantined source and its agents"
+}
+
+// CreateCommand creates the cobra command for unquarantine functionality
+func (c *UnquarantineCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unquarantine SOURCE",
+		Short: c.Description(),
+		Long: `Move a quarantined source's files back into place, restore its installation
+tracking, re-enable it in the configuration if it was enabled before, and allow
+it to be installed again.
+
+Examples:
+  agent-manager unquarantine community-agents`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.Execute(sharedCtx, args[0])
+		},
+	}
+
+	return cmd
+}
+
+// Execute runs the unquarantine command logic
+func (c *UnquarantineCommand) Execute(sharedCtx *SharedContext, sourceName string) error {
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	store := quarantine.New(quarantine.DefaultDir(sharedCtx.Config.Metadata.TrackingFile))
+	if !store.IsQuarantined(sourceName) {
+		return fmt.Errorf("source %s is not quarantined", sourceName)
+	}
+
+	if sharedCtx.Options.DryRun {
+		color.Yellow("[DRY RUN] Would restore quarantined source %s\n", sourceName)
+		return nil
+	}
+
+	record, err := store.Release(sourceName)
+	if err != nil {
+		return err
+	}
+
+	if record.Installation != nil {
+		track := sharedCtx.Tracker()
+		if err := track.RecordInstallation(sourceName, *record.Installation); err != nil {
+			return fmt.Errorf("failed to restore installation tracking: %w", err)
+		}
+	}
+	if record.WasEnabled {
+		if err := config.SetSourceEnabled(sharedCtx.Options.ConfigFile, sourceName, true); err != nil {
+			PrintWarning("Failed to re-enable source in configuration: %v", err)
+		}
+	}
+	if err := quarantine.AppendAuditLog(sharedCtx.Config.Metadata.LogFile, "unquarantine", record); err != nil {
+		PrintWarning("Failed to record unquarantine in audit log: %v", err)
+	}
+
+	refreshIndex(sharedCtx)
+
+	PrintSuccess("Restored source %s (%d files)", sourceName, len(record.Files))
+	return nil
+}
+
+// refreshIndex updates the query index after files were moved, warning on failure
+func refreshIndex(sharedCtx *SharedContext) {
+	queryEngine, err := sharedCtx.CreateQueryEngine()
+	if err == nil {
+		err = queryEngine.UpdateIndex(sharedCtx.GetAgentsDirectory())
+	}
+	if err != nil {
+		PrintWarning("Failed to update index: %v", err)
+	}
+}
@@ -11,23 +11,35 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/engine"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/index"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
 
 // QueryCommand implements enhanced query functionality with regex and multi-field search
 type QueryCommand struct {
-	query       string
-	field       string
-	limit       int
-	noTools     bool
-	customTools bool
-	source      string
-	output      string
-	useRegex    bool
-	fuzzyScore  float64
-	timeout     time.Duration
+	query        string
+	field        string
+	limit        int
+	noTools      bool
+	customTools  bool
+	source       string
+	collection   string
+	scope        string
+	dedupe       string
+	output       string
+	template     string
+	useRegex     bool
+	fuzzyScore   float64
+	explainScore bool
+	timeout      time.Duration
+
+	// scores holds the relevance breakdown of each result for --explain-score
+	scores []engine.Score
+	// names are the agents of --collection
+	names []string
 }
 
 // NewQueryCommand creates a new query command instance
@@ -61,6 +73,15 @@ Examples:
   agent-manager query "tools:bash,git"          # Find agents using bash and git tools
   agent-manager query "description:automation"  # Find agents with automation in description
 
+  # Phrases and exact matches
+  agent-manager query '"pull request review"'  # The words together, in order
+  agent-manager query 'name:=go-expert'         # Name equal to go-expert
+  agent-manager query 'description:"code review" tools:bash'
+
+  # Boolean operators and groups
+  agent-manager query 'name:go AND tools:Bash NOT source:local'
+  agent-manager query '(name:python OR name:go) NOT description:"legacy"'
+
   # Regex pattern matching
   agent-manager query "name:^data.*processor$" --regex  # Regex pattern in name field
   agent-manager query "description:.*API.*" --regex     # Regex in description
@@ -72,11 +93,18 @@ Examples:
   agent-manager query --no-tools                # Find agents with inherited tools only
   agent-manager query --custom-tools            # Find agents with explicit tools only
   agent-manager query --source github           # Find agents from github source
+  agent-manager query --collection review-kit   # Only the agents of a collection
   agent-manager query --limit 10                # Limit results to 10 agents
+  agent-manager query --scope effective         # Only agents Claude Code actually uses
+  agent-manager query "go" --dedupe effective   # One copy per agent name, noting hidden copies
+
+  # Relevance ranking
+  agent-manager query "review" --explain-score  # Show why each result ranks where it does
 
   # Output formats
   agent-manager query "go" --output json        # JSON output
-  agent-manager query "go" --output yaml        # YAML output`,
+  agent-manager query "go" --output yaml        # YAML output
+  agent-manager query "go" --template '{{.Name}}\t{{.Source}}'  # Custom template`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 0 {
@@ -87,14 +115,19 @@ Examples:
 	}
 
 	// Add flags
-	cmd.Flags().StringVarP(&c.field, "field", "f", "", "search specific field (name, description, content, tools, source)")
+	cmd.Flags().StringVarP(&c.field, "field", "f", "", "search specific field (name, description, content, tools, source, version); prefix the query with = for an exact match")
 	cmd.Flags().IntVarP(&c.limit, "limit", "l", 0, "limit number of results")
 	cmd.Flags().BoolVar(&c.noTools, "no-tools", false, "find agents with inherited tools only")
 	cmd.Flags().BoolVar(&c.customTools, "custom-tools", false, "find agents with explicit tools only")
 	cmd.Flags().StringVarP(&c.source, "source", "s", "", "filter by source")
-	cmd.Flags().StringVarP(&c.output, "output", "o", "table", "output format (table, json, yaml)")
+	cmd.Flags().StringVar(&c.collection, "collection", "", "filter to the agents of a collection defined in the configuration")
+	cmd.Flags().StringVar(&c.scope, "scope", "", "filter by scope: user, project or effective (what Claude Code sees)")
+	cmd.Flags().StringVar(&c.dedupe, "dedupe", engine.DedupeAll, "copies of same-named agents to return: all or effective (the copy Claude Code uses)")
+	cmd.Flags().StringVarP(&c.output, "output", "o", "table", "output format (table, json, yaml, template)")
+	addTemplateFlag(cmd, &c.template)
 	cmd.Flags().BoolVar(&c.useRegex, "regex", false, "use regex pattern matching")
 	cmd.Flags().Float64Var(&c.fuzzyScore, "fuzzy-score", 0.7, "fuzzy matching threshold (0.0-1.0)")
+	cmd.Flags().BoolVar(&c.explainScore, "explain-score", false, "show how each result's relevance score was computed")
 	cmd.Flags().DurationVar(&c.timeout, "timeout", 30*time.Second, "query timeout")
 
 	return cmd
@@ -102,11 +135,31 @@ Examples:
 
 // Execute runs the query command logic
 func (c *QueryCommand) Execute(sharedCtx *SharedContext) error {
+	switch c.scope {
+	case "", parser.ScopeUser, parser.ScopeProject, engine.ScopeEffective:
+	default:
+		return fmt.Errorf("invalid scope: %s (must be user, project or effective)", c.scope)
+	}
+	switch c.dedupe {
+	case "", engine.DedupeAll, engine.DedupeEffective:
+	default:
+		return fmt.Errorf("invalid dedupe mode: %s (must be all or effective)", c.dedupe)
+	}
+
 	// Load configuration
 	if err := sharedCtx.LoadConfig(); err != nil {
 		return fmt.Errorf("configuration error: %w", err)
 	}
 
+	c.names = nil
+	if c.collection != "" {
+		names, err := sharedCtx.Config.Collection(c.collection)
+		if err != nil {
+			return err
+		}
+		c.names = names
+	}
+
 	// Create query engine with timeout context
 	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
 	defer cancel()
@@ -119,6 +172,12 @@ func (c *QueryCommand) Execute(sharedCtx *SharedContext) error {
 	// Configure fuzzy matching threshold
 	queryEngine.SetFuzzyThreshold(c.fuzzyScore)
 
+	if c.dedupe == engine.DedupeEffective {
+		if err := setSourcePriority(sharedCtx, queryEngine); err != nil {
+			return err
+		}
+	}
+
 	// Execute search with progress indication
 	var results []*parser.AgentSpec
 	var queryErr error
@@ -148,6 +207,15 @@ func (c *QueryCommand) Execute(sharedCtx *SharedContext) error {
 	default:
 	}
 
+	sharedCtx.Summarize("results", len(results))
+
+	if c.explainScore {
+		c.scores = make([]engine.Score, 0, len(results))
+		for _, agent := range results {
+			c.scores = append(c.scores, queryEngine.ExplainScore(c.query, agent))
+		}
+	}
+
 	// Output results
 	return c.outputResults(results, sharedCtx)
 }
@@ -160,6 +228,9 @@ func (c *QueryCommand) executeQuery(ctx context.Context, queryEngine *engine.Eng
 		NoTools:     c.noTools,
 		CustomTools: c.customTools,
 		Source:      c.source,
+		Names:       c.names,
+		Scope:       c.scope,
+		Dedupe:      c.dedupe,
 		Context:     ctx,
 	}
 
@@ -174,7 +245,7 @@ func (c *QueryCommand) executeQuery(ctx context.Context, queryEngine *engine.Eng
 	} else {
 		// Get all agents with filters applied
 		allAgents := queryEngine.GetAllAgents()
-		return c.applyFilters(allAgents, opts), nil
+		return c.applyFilters(queryEngine, allAgents, opts), nil
 	}
 }
 
@@ -183,7 +254,24 @@ func (c *QueryCommand) executeFieldQuery(queryEngine *engine.Engine, opts engine
 	if c.useRegex {
 		return c.executeRegexFieldQuery(queryEngine, opts)
 	}
-	return queryEngine.QueryByField(c.field, c.query)
+	results, err := queryEngine.QueryByField(c.field, c.query)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Names) > 0 {
+		kept := results[:0:0]
+		for _, agent := range results {
+			if index.HasAnyName(agent, opts.Names) {
+				kept = append(kept, agent)
+			}
+		}
+		results = kept
+	}
+	results = engine.FilterScope(results, opts.Scope)
+	if opts.Dedupe == engine.DedupeEffective {
+		results = queryEngine.Dedupe(results)
+	}
+	return results, nil
 }
 
 // executeRegexFieldQuery executes a field query with regex pattern matching
@@ -222,7 +310,7 @@ func (c *QueryCommand) executeRegexFieldQuery(queryEngine *engine.Engine, opts e
 	}
 
 	// Apply additional filters
-	return c.applyFilters(matches, opts), nil
+	return c.applyFilters(queryEngine, matches, opts), nil
 }
 
 // executeComplexQuery executes a complex multi-field query
@@ -231,6 +319,11 @@ func (c *QueryCommand) executeComplexQuery(queryEngine *engine.Engine, opts engi
 		return c.executeRegexComplexQuery(queryEngine, opts)
 	}
 
+	// Quoted phrases, field terms and operators match literally in the index
+	if index.IsStructured(c.query) {
+		return queryEngine.Query(c.query, opts)
+	}
+
 	// Use enhanced fuzzy matching for better relevance when no specific field is targeted
 	if c.fuzzyScore < 0.7 || len(strings.Fields(c.query)) > 1 {
 		return queryEngine.QueryWithFuzzy(c.query, opts)
@@ -263,7 +356,7 @@ func (c *QueryCommand) executeRegexComplexQuery(queryEngine *engine.Engine, opts
 		}
 	}
 
-	return c.applyFilters(matches, opts), nil
+	return c.applyFilters(queryEngine, matches, opts), nil
 }
 
 // parseComplexRegexQuery parses a complex query string for field:pattern pairs
@@ -318,7 +411,7 @@ func (c *QueryCommand) matchAgentField(agent *parser.AgentSpec, field string, pa
 }
 
 // applyFilters applies additional filters to results
-func (c *QueryCommand) applyFilters(agents []*parser.AgentSpec, opts engine.QueryOptions) []*parser.AgentSpec {
+func (c *QueryCommand) applyFilters(queryEngine *engine.Engine, agents []*parser.AgentSpec, opts engine.QueryOptions) []*parser.AgentSpec {
 	// Pre-allocate slice with estimated capacity to avoid reallocations
 	filtered := make([]*parser.AgentSpec, 0, len(agents))
 
@@ -327,6 +420,9 @@ func (c *QueryCommand) applyFilters(agents []*parser.AgentSpec, opts engine.Quer
 		if opts.Source != "" && agent.Source != opts.Source {
 			continue
 		}
+		if !index.HasAnyName(agent, opts.Names) {
+			continue
+		}
 
 		// Apply tools filters
 		if opts.NoTools && !agent.ToolsInherited {
@@ -343,6 +439,10 @@ func (c *QueryCommand) applyFilters(agents []*parser.AgentSpec, opts engine.Quer
 
 		filtered = append(filtered, agent)
 	}
+	filtered = engine.FilterScope(filtered, opts.Scope)
+	if opts.Dedupe == engine.DedupeEffective {
+		filtered = queryEngine.Dedupe(filtered)
+	}
 
 	// Apply limit
 	if opts.Limit > 0 && len(filtered) > opts.Limit {
@@ -354,6 +454,10 @@ func (c *QueryCommand) applyFilters(agents []*parser.AgentSpec, opts engine.Quer
 
 // outputResults outputs the query results in the specified format
 func (c *QueryCommand) outputResults(results []*parser.AgentSpec, sharedCtx *SharedContext) error {
+	if c.output == outputTemplate || c.template != "" {
+		return renderAgentsTemplate(sharedCtx, c.template, results)
+	}
+
 	if !sharedCtx.Options.Verbose && !sharedCtx.Options.NoProgress {
 		fmt.Println() // Add spacing after spinner
 	}
@@ -368,8 +472,14 @@ func (c *QueryCommand) outputResults(results []*parser.AgentSpec, sharedCtx *Sha
 
 	switch c.output {
 	case "json":
+		if c.explainScore {
+			return c.outputJSON(c.scores)
+		}
 		return c.outputJSON(results)
 	case "yaml":
+		if c.explainScore {
+			return c.outputYAML(c.scores)
+		}
 		return c.outputYAML(results)
 	case "table":
 		fallthrough
@@ -379,14 +489,14 @@ func (c *QueryCommand) outputResults(results []*parser.AgentSpec, sharedCtx *Sha
 }
 
 // outputJSON outputs results as JSON
-func (c *QueryCommand) outputJSON(results []*parser.AgentSpec) error {
+func (c *QueryCommand) outputJSON(results interface{}) error {
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(results)
 }
 
 // outputYAML outputs results as YAML
-func (c *QueryCommand) outputYAML(results []*parser.AgentSpec) error {
+func (c *QueryCommand) outputYAML(results interface{}) error {
 	encoder := yaml.NewEncoder(os.Stdout)
 	defer func() {
 		if err := encoder.Close(); err != nil {
@@ -404,8 +514,8 @@ func (c *QueryCommand) outputTable(results []*parser.AgentSpec) error {
 	fmt.Println(strings.Repeat("-", 95))
 
 	// Print each agent
-	for _, agent := range results {
-		name := c.truncate(agent.Name, 24)
+	for i, agent := range results {
+		name := c.truncate(agent.QualifiedName(), 24)
 		source := c.truncate(agent.Source, 14)
 		description := c.truncate(agent.Description, 39)
 
@@ -422,8 +532,37 @@ func (c *QueryCommand) outputTable(results []*parser.AgentSpec) error {
 		toolsStr = c.truncate(toolsStr, 14)
 
 		fmt.Printf("%-25s %-15s %-40s %-15s\n", name, source, description, toolsStr)
+		for _, hidden := range agent.Shadows {
+			fmt.Printf("  shadows %s\n", hidden)
+		}
+		if i < len(c.scores) {
+			fmt.Printf("  score %s\n", util.PlainText(c.scores[i].String()))
+		}
+	}
+
+	return nil
+}
+
+// setSourcePriority ranks sources by their order in the configuration and
+// attributes installed files to their source for deduplication
+func setSourcePriority(sharedCtx *SharedContext, queryEngine *engine.Engine) error {
+	order := make([]string, 0, len(sharedCtx.Config.Sources))
+	for _, source := range sharedCtx.Config.Sources {
+		order = append(order, source.Name)
+	}
+
+	installations, err := sharedCtx.Tracker().List()
+	if err != nil {
+		return fmt.Errorf("failed to read installed sources: %w", err)
+	}
+	fileSources := make(map[string]string)
+	for name, installation := range installations {
+		for path := range installation.Files {
+			fileSources[path] = name
+		}
 	}
 
+	queryEngine.SetSourcePriority(order, fileSources)
 	return nil
 }
 
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// quietMode is set while --quiet silences stdout: errors go to stderr and
+// confirmation prompts are declined
+var quietMode bool
+
+// summaryField is one key=value pair of the quiet-mode summary line
+type summaryField struct {
+	key   string
+	value string
+}
+
+// Summarize records a field of the command's quiet-mode summary line. Fields
+// are printed in the order first recorded; recording a key again replaces its value.
+func (sc *SharedContext) Summarize(key string, value interface{}) {
+	formatted := fmt.Sprint(value)
+	if formatted == "" || strings.ContainsAny(formatted, " \t\"=") {
+		formatted = fmt.Sprintf("%q", formatted)
+	}
+	for i := range sc.summary {
+		if sc.summary[i].key == key {
+			sc.summary[i].value = formatted
+			return
+		}
+	}
+	sc.summary = append(sc.summary, summaryField{key: key, value: formatted})
+}
+
+// enterQuiet turns off colors, symbols, progress and verbose output, and
+// silences stdout so only the summary line is written there
+func (sc *SharedContext) enterQuiet() error {
+	sc.Options.Verbose = false
+	sc.Options.NoProgress = true
+	sc.Options.NoColor = true
+	sc.Options.Plain = true
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to silence output: %w", err)
+	}
+	sc.stdout = os.Stdout
+	os.Stdout = devNull
+	color.Output = io.Discard
+	quietMode = true
+	return nil
+}
+
+// printSummaryLine writes the single quiet-mode line for an operation, e.g.
+// "install source=foo files=42 conflicts=3 duration=1.2s status=ok"
+func (sc *SharedContext) printSummaryLine(operation string, elapsed time.Duration, err error) {
+	status := "ok"
+	var exitErr *ExitError
+	switch {
+	case errors.As(err, &exitErr) && exitErr.Code == ExitPartial:
+		status = "partial"
+	case err != nil:
+		status = "error"
+	case sc.PlannedOnly():
+		status = "planned"
+	}
+
+	var b strings.Builder
+	b.WriteString(operation)
+	for _, field := range sc.summary {
+		fmt.Fprintf(&b, " %s=%s", field.key, field.value)
+	}
+	fmt.Fprintf(&b, " duration=%.1fs status=%s", elapsed.Seconds(), status)
+	_, _ = fmt.Fprintln(sc.stdout, b.String())
+}
+
+// addSummaries wraps the RunE of cmd and its subcommands so that in quiet mode
+// each operation ends with its summary line, whether it succeeded or failed
+func addSummaries(cmd *cobra.Command, sc *SharedContext) {
+	for _, sub := range cmd.Commands() {
+		addSummaries(sub, sc)
+	}
+	if cmd.RunE == nil {
+		return
+	}
+
+	run := cmd.RunE
+	operation := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if !sc.Options.Quiet {
+			return run(cmd, args)
+		}
+		// main reports the error on stderr; usage text is noise for scripts
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		start := time.Now()
+		err := run(cmd, args)
+		sc.printSummaryLine(operation, time.Since(start), err)
+		return err
+	}
+}
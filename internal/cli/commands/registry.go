@@ -1,9 +1,17 @@
 package commands
 
 import (
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/fatih/color"
 	"github.com/pacphi/claude-code-agent-manager/internal/cli"
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/progress"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 // CommandRegistry manages all available commands
@@ -11,18 +19,21 @@ type CommandRegistry struct {
 	commands   []Command
 	sharedOpts *SharedOptions
 	sharedCtx  *SharedContext
+	// started is when the running command started, for its run metrics
+	started time.Time
 }
 
 // NewCommandRegistry creates a new command registry with all available commands
 func NewCommandRegistry() *CommandRegistry {
 	sharedOpts := &SharedOptions{
-		ConfigFile: "agents-config.yaml",
+		ConfigFile: config.DefaultConfigFile,
 	}
 
 	registry := &CommandRegistry{
 		sharedOpts: sharedOpts,
 		sharedCtx:  NewSharedContext(sharedOpts),
 		commands: []Command{
+			NewInitCommand(),
 			NewInstallCommand(),
 			NewUninstallCommand(),
 			NewUpdateCommand(),
@@ -32,6 +43,31 @@ func NewCommandRegistry() *CommandRegistry {
 			NewStatsCommand(),
 			NewValidateCommand(),
 			NewIndexCommand(),
+			NewCacheCommand(),
+			NewRenameCommand(),
+			NewSetCommand(),
+			NewParseReportCommand(),
+			NewPublishCommand(),
+			NewExportCommand(),
+			NewImportCommand(),
+			NewQuarantineCommand(),
+			NewUnquarantineCommand(),
+			NewArchiveCommand(),
+			NewUnarchiveCommand(),
+			NewDisableCommand(),
+			NewEnableCommand(),
+			NewGithookCommand(),
+			NewPlanCommand(),
+			NewInventoryCommand(),
+			NewExplainCommand(),
+			NewAuthCommand(),
+			NewConfigCommand(),
+			NewConflictsCommand(),
+			NewDoctorCommand(),
+			NewApplyCommand(),
+			NewServeIndexCommand(),
+			NewWatchCommand(),
+			NewVerifyCommand(),
 		},
 	}
 
@@ -45,8 +81,22 @@ func (r *CommandRegistry) CreateRootCommand(version string) *cobra.Command {
 		Short: "Manage Claude Code subagents via YAML configuration",
 		Long: `Agent Manager is a tool for installing, updating, and managing
 Claude Code subagents from various sources using YAML configuration.`,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			r.setupGlobalOptions()
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			r.started = time.Now()
+			if err := r.setupGlobalOptions(); err != nil {
+				return err
+			}
+			r.sharedCtx.mutating = mutatingCommands[topLevel(cmd).Name()]
+			if r.sharedOpts.BaseDir != "" && !baseDirCommands[topLevel(cmd).Name()] {
+				return fmt.Errorf("--base-dir is not supported by %s; it only applies to query, show, stats, validate, index and cache", topLevel(cmd).Name())
+			}
+			if err := r.discoverConfig(cmd, args); err != nil {
+				return err
+			}
+			return r.checkFirstRun(cmd)
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return r.plannedExit(cmd)
 		},
 	}
 
@@ -70,16 +120,128 @@ Claude Code subagents from various sources using YAML configuration.`,
 	rootCmd.AddCommand(versionCmd)
 
 	// Add marketplace command (external)
-	rootCmd.AddCommand(cli.NewMarketplaceCmd())
+	rootCmd.AddCommand(cli.NewMarketplaceCmd(newMarketplaceInstaller(r.sharedCtx)))
+
+	addSummaries(rootCmd, r.sharedCtx)
 
 	return rootCmd
 }
 
+// discoverConfig finds the configuration for registered commands that read
+// it; init creates one in the working directory and is left alone
+func (r *CommandRegistry) discoverConfig(cmd *cobra.Command, args []string) error {
+	top := topLevel(cmd)
+	for _, command := range r.commands {
+		if command.Name() == top.Name() && command.Name() != "init" {
+			return r.sharedCtx.discoverConfig(cmd, args)
+		}
+	}
+	return nil
+}
+
+// checkFirstRun offers the starter setup when a registered command that reads
+// the configuration is run before anything has been configured. The prompt
+// needs a terminal on stdin: piped input belongs to the command, such as
+// install --stdin, and scripts must neither block nor have it answered.
+func (r *CommandRegistry) checkFirstRun(cmd *cobra.Command) error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil
+	}
+	if flag := cmd.Flags().Lookup("stdin"); flag != nil && flag.Changed {
+		return nil
+	}
+	top := topLevel(cmd)
+	for _, command := range r.commands {
+		if command.Name() == top.Name() && !skipsOnboarding[command.Name()] {
+			return offerOnboarding(r.sharedCtx, top.Name())
+		}
+	}
+	return nil
+}
+
+// skipsOnboarding lists commands that do not need an existing configuration
+var skipsOnboarding = map[string]bool{
+	"init":    true,
+	"githook": true,
+	"doctor":  true,
+}
+
+// workingDirCommands act on the repository they are run in; a discovered
+// user configuration is used without running them from the home directory
+var workingDirCommands = map[string]bool{
+	"githook": true,
+}
+
+// baseDirCommands only scan agent files and accept --base-dir; commands
+// that install or track files always use settings.base_dir
+var baseDirCommands = map[string]bool{
+	"query":    true,
+	"show":     true,
+	"stats":    true,
+	"validate": true,
+	"index":    true,
+	"cache":    true,
+}
+
+// mutatingCommands change installed agents or files and are subject to the
+// settings.default_dry_run policy. Commands that change files only with some
+// actions or flags, such as config set, conflicts resolve, verify --fix,
+// cache clear and githook install or uninstall, are left out and set
+// SharedContext.mutating in Execute instead.
+var mutatingCommands = map[string]bool{
+	"install":      true,
+	"uninstall":    true,
+	"update":       true,
+	"rename":       true,
+	"set":          true,
+	"publish":      true,
+	"import":       true,
+	"quarantine":   true,
+	"unquarantine": true,
+	"archive":      true,
+	"unarchive":    true,
+	"disable":      true,
+	"enable":       true,
+	"apply":        true,
+	"watch":        true,
+}
+
+// topLevel returns the subcommand of the root command that cmd belongs to
+func topLevel(cmd *cobra.Command) *cobra.Command {
+	for cmd.HasParent() && cmd.Parent().HasParent() {
+		cmd = cmd.Parent()
+	}
+	return cmd
+}
+
+// plannedExit fails with ExitPlanned when a command only planned its changes
+// because of a dry-run policy, so scripts can tell plan from apply runs
+func (r *CommandRegistry) plannedExit(cmd *cobra.Command) error {
+	if !r.sharedCtx.PlannedOnly() {
+		return nil
+	}
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	return &ExitError{Code: ExitPlanned, Err: fmt.Errorf("plan mode: no changes were applied; re-run with --apply to make them")}
+}
+
 // setupGlobalOptions configures global options before command execution
-func (r *CommandRegistry) setupGlobalOptions() {
-	// Setup colors
-	SetupColors(r.sharedOpts.NoColor)
+func (r *CommandRegistry) setupGlobalOptions() error {
+	if r.sharedOpts.Quiet {
+		if err := r.sharedCtx.enterQuiet(); err != nil {
+			return err
+		}
+	}
+
+	// Setup colors and timestamps
+	SetupColors(r.sharedOpts)
+	util.SetAbsoluteTimes(r.sharedOpts.Absolute)
 
 	// Setup progress manager
+	if err := openProgressEvents(r.sharedOpts); err != nil {
+		return err
+	}
 	SetupProgress(r.sharedOpts)
+	r.sharedCtx.PM = progress.Default()
+	return nil
 }
@@ -0,0 +1,263 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/engine"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/spf13/cobra"
+)
+
+// agentNamePattern matches valid agent names (lowercase with hyphens)
+var agentNamePattern = regexp.MustCompile(`^[a-z][a-z0-9.-]*$`)
+
+// RenameCommand implements the rename command functionality
+type RenameCommand struct {
+	oldName      string
+	newName      string
+	yes          bool
+	noReferences bool
+}
+
+// NewRenameCommand creates a new rename command instance
+func NewRenameCommand() *RenameCommand {
+	return &RenameCommand{}
+}
+
+// Name returns the command name
+func (c *RenameCommand) Name() string {
+	return "rename"
+}
+
+// Description returns the command description
+func (c *RenameCommand) Description() string {
+	return "Rename an installed agent and update references to it"
+}
+
+// CreateCommand creates the cobra command for rename functionality
+func (c *RenameCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename <old> <new>",
+		Short: c.Description(),
+		Long: `Rename an installed agent: renames the file, updates the name in its
+frontmatter, fixes tracking and index records, and offers to update references
+to the old name in other installed agents.
+
+Examples:
+  agent-manager rename go-expert go-specialist          # Rename and prompt for references
+  agent-manager rename go-expert go-specialist --yes    # Also update references without asking
+  agent-manager rename go-expert go-specialist --dry-run`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c.oldName = args[0]
+			c.newName = args[1]
+			return c.Execute(sharedCtx)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&c.yes, "yes", "y", false, "update references in other agents without prompting")
+	cmd.Flags().BoolVar(&c.noReferences, "no-references", false, "do not scan other agents for references")
+
+	return cmd
+}
+
+// Execute runs the rename command logic
+func (c *RenameCommand) Execute(sharedCtx *SharedContext) error {
+	if !agentNamePattern.MatchString(c.newName) {
+		return fmt.Errorf("invalid agent name %q: must be lowercase with hyphens", c.newName)
+	}
+
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	queryEngine, err := sharedCtx.CreateQueryEngine()
+	if err != nil {
+		return err
+	}
+
+	agents := queryEngine.GetAllAgents()
+	agent := findAgentExact(agents, c.oldName)
+	if agent == nil {
+		return fmt.Errorf("agent not found: %s", c.oldName)
+	}
+	if existing := findAgentExact(agents, c.newName); existing != nil {
+		return fmt.Errorf("an agent named %s already exists: %s", c.newName, existing.FilePath)
+	}
+
+	oldName := agent.Name
+	newPath := filepath.Join(filepath.Dir(agent.FilePath), c.newName+filepath.Ext(agent.FilePath))
+	if _, err := os.Stat(newPath); err == nil {
+		return fmt.Errorf("target file already exists: %s", newPath)
+	}
+
+	referencing := findReferencingAgents(agents, agent, oldName)
+
+	if sharedCtx.Options.DryRun {
+		c.printPlan(agent.FilePath, newPath, oldName, referencing)
+		return nil
+	}
+
+	if err := renameAgentFile(agent.FilePath, newPath, c.newName); err != nil {
+		return err
+	}
+
+	track := sharedCtx.Tracker()
+	sourceName, err := track.RenameFile(agent.FilePath, newPath, c.newName)
+	if err != nil {
+		PrintWarning("Renamed file but failed to update tracking data: %v", err)
+	}
+
+	PrintSuccess("Renamed %s to %s", oldName, c.newName)
+	if sourceName != "" && sharedCtx.Options.Verbose {
+		PrintInfo("Updated tracking for source %s", sourceName)
+	}
+
+	if !c.noReferences && len(referencing) > 0 {
+		if err := c.updateReferences(sharedCtx, referencing, oldName); err != nil {
+			return err
+		}
+	}
+
+	return c.refreshIndex(sharedCtx, queryEngine)
+}
+
+// printPlan describes the changes rename would make in dry-run mode
+func (c *RenameCommand) printPlan(oldPath, newPath, oldName string, referencing []*parser.AgentSpec) {
+	color.Yellow("[DRY RUN] Would rename %s -> %s\n", oldPath, newPath)
+	color.Yellow("[DRY RUN] Would set name: %s (was %s)\n", c.newName, oldName)
+
+	if c.noReferences {
+		return
+	}
+	for _, ref := range referencing {
+		color.Yellow("[DRY RUN] %s references %s\n", ref.FilePath, oldName)
+	}
+}
+
+// updateReferences rewrites references to the old name in other agents after confirmation
+func (c *RenameCommand) updateReferences(sharedCtx *SharedContext, referencing []*parser.AgentSpec, oldName string) error {
+	fmt.Printf("\n%d agent(s) reference %s:\n", len(referencing), oldName)
+	for _, ref := range referencing {
+		fmt.Printf("  - %s (%s)\n", ref.Name, ref.FilePath)
+	}
+
+	if !c.yes && !Confirm(fmt.Sprintf("Update references to %s?", c.newName)) {
+		PrintInfo("References left unchanged")
+		return nil
+	}
+
+	var updated []string
+	for _, ref := range referencing {
+		changed, err := replaceReferences(ref.FilePath, oldName, c.newName)
+		if err != nil {
+			PrintError("Failed to update %s: %v", ref.FilePath, err)
+			continue
+		}
+		if changed {
+			updated = append(updated, ref.FilePath)
+		}
+	}
+	refreshTracking(sharedCtx, updated)
+
+	PrintSuccess("Updated references in %d agent(s)", len(updated))
+	return nil
+}
+
+// refreshIndex rebuilds the index so queries see the renamed agent
+func (c *RenameCommand) refreshIndex(sharedCtx *SharedContext, queryEngine *engine.Engine) error {
+	return sharedCtx.PM.WithSpinner("Updating index", func() error {
+		return queryEngine.UpdateIndex(sharedCtx.GetAgentsDirectory())
+	})
+}
+
+// findAgentExact looks up an agent by exact name, filename, or filename without extension
+func findAgentExact(agents []*parser.AgentSpec, name string) *parser.AgentSpec {
+	for _, agent := range agents {
+		if agent.Name == name || agent.FileName == name ||
+			strings.TrimSuffix(agent.FileName, filepath.Ext(agent.FileName)) == name {
+			return agent
+		}
+	}
+	return nil
+}
+
+// referencePattern matches name as a whole word, treating hyphens as part of names
+func referencePattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?m)(^|[^\w-])` + regexp.QuoteMeta(name) + `($|[^\w-])`)
+}
+
+// findReferencingAgents returns other agents whose file mentions name
+func findReferencingAgents(agents []*parser.AgentSpec, self *parser.AgentSpec, name string) []*parser.AgentSpec {
+	pattern := referencePattern(name)
+
+	var result []*parser.AgentSpec
+	for _, agent := range agents {
+		if agent.FilePath == self.FilePath {
+			continue
+		}
+		content, err := os.ReadFile(agent.FilePath)
+		if err != nil {
+			continue
+		}
+		if pattern.Match(content) {
+			result = append(result, agent)
+		}
+	}
+	return result
+}
+
+// renameAgentFile writes the agent to newPath with an updated name and removes the old file
+func renameAgentFile(oldPath, newPath, newName string) error {
+	content, err := os.ReadFile(oldPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", oldPath, err)
+	}
+
+	updated, err := parser.SetFrontmatterField(string(content), "name", newName)
+	if err != nil {
+		return fmt.Errorf("failed to update frontmatter: %w", err)
+	}
+
+	info, err := os.Stat(oldPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", oldPath, err)
+	}
+
+	if err := os.WriteFile(newPath, []byte(updated), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", newPath, err)
+	}
+
+	if err := os.Remove(oldPath); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", oldPath, err)
+	}
+
+	return nil
+}
+
+// replaceReferences replaces whole-word occurrences of oldName in a file
+func replaceReferences(path, oldName, newName string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	// A second pass catches adjacent occurrences whose separator was consumed by the first
+	pattern := referencePattern(oldName)
+	replacement := []byte("${1}" + newName + "${2}")
+	updated := pattern.ReplaceAll(pattern.ReplaceAll(content, replacement), replacement)
+	if string(updated) == string(content) {
+		return false, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, updated, info.Mode().Perm())
+}
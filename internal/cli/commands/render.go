@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// outputTemplate is the --output value selecting template rendering
+const outputTemplate = "template"
+
+// templateFuncs are the helper functions available in output templates
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	// truncate counts characters, so multi-byte text is never cut mid-character
+	"truncate": func(maxLen int, s string) string {
+		runes := []rune(s)
+		if len(runes) <= maxLen || maxLen < 4 {
+			return s
+		}
+		return string(runes[:maxLen-3]) + "..."
+	},
+}
+
+// addTemplateFlag registers the --template flag used with --output template
+func addTemplateFlag(cmd *cobra.Command, tmpl *string) {
+	cmd.Flags().StringVar(tmpl, "template", "", "Go template rendered per agent: inline text, @file, or a name from query.templates (implies --output template)")
+	markPathFlag(cmd, "template", pathTemplate)
+}
+
+// loadOutputTemplate parses an output template given inline, as @path to a
+// template file, or as the name of a template file configured in query.templates
+func loadOutputTemplate(spec string, named map[string]string) (*template.Template, error) {
+	text := spec
+	path := ""
+	if configured, ok := named[spec]; ok {
+		path = configured
+	} else if strings.HasPrefix(spec, "@") {
+		path = strings.TrimPrefix(spec, "@")
+	} else {
+		// Allow \t and \n escapes in templates given on the command line
+		text = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(spec)
+	}
+
+	if path != "" {
+		expanded, err := util.ExpandPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template path %s: %w", path, err)
+		}
+		content, err := os.ReadFile(expanded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		text = string(content)
+	}
+
+	tmpl, err := template.New("output").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderAgents executes tmpl once per agent, ending each rendering with a newline
+func renderAgents(w io.Writer, tmpl *template.Template, agents []*parser.AgentSpec) error {
+	var buf bytes.Buffer
+	for _, agent := range agents {
+		buf.Reset()
+		if err := tmpl.Execute(&buf, agent); err != nil {
+			return fmt.Errorf("failed to render %s: %w", agent.Name, err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderAgentsTemplate loads the template spec and renders agents to stdout
+func renderAgentsTemplate(sharedCtx *SharedContext, spec string, agents []*parser.AgentSpec) error {
+	if spec == "" {
+		return fmt.Errorf("--output template requires --template")
+	}
+	tmpl, err := loadOutputTemplate(spec, sharedCtx.Config.Settings.Query.Templates)
+	if err != nil {
+		return err
+	}
+	return renderAgents(os.Stdout, tmpl, agents)
+}
@@ -0,0 +1,387 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/engine"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"golang.org/x/term"
+)
+
+// errSelectionCancelled is returned when the user leaves the selector without picking an agent
+var errSelectionCancelled = errors.New("selection cancelled")
+
+const (
+	// selectorRows is how many candidates the selector lists at once
+	selectorRows = 10
+	// selectorPreviewLines is how many prompt lines the selector previews
+	selectorPreviewLines = 4
+	// selectorWidth is the line width used when the terminal size is unknown
+	selectorWidth = 80
+)
+
+// canSelect reports whether an ambiguous agent name may be resolved by
+// asking the user, which needs a terminal and is never done in quiet mode
+func canSelect() bool {
+	return !quietMode && term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// resolveAgent finds the agent name refers to. With interactive set, or when
+// name matches several agents and a terminal is attached, the user picks the
+// agent in the selector; otherwise the best match is used.
+func resolveAgent(queryEngine *engine.Engine, name string, interactive bool) (*parser.AgentSpec, error) {
+	if interactive {
+		return selectAgent(queryEngine.GetAllAgents(), name)
+	}
+	if matches := queryEngine.FindAgents(name); len(matches) > 1 && canSelect() {
+		return selectAgent(matches, "")
+	}
+	return queryEngine.ShowAgent(name)
+}
+
+// selectAgent lets the user pick one of agents, narrowed by query as typed.
+// On a terminal this is a full-screen finder; otherwise the candidates are
+// listed numbered and the choice is read from stdin.
+func selectAgent(agents []*parser.AgentSpec, query string) (*parser.AgentSpec, error) {
+	if quietMode {
+		return nil, fmt.Errorf("interactive selection is not available with --quiet")
+	}
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("no agents to select from")
+	}
+
+	if canSelect() {
+		fd := int(os.Stdin.Fd())
+		width := selectorWidth
+		if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+			width = w
+		}
+		if state, err := term.MakeRaw(fd); err == nil {
+			defer func() { _ = term.Restore(fd, state) }()
+			return runFinder(agents, query, os.Stdin, os.Stdout, width)
+		}
+	}
+	return promptSelect(agents, query, os.Stdin, os.Stdout)
+}
+
+// filterAgents returns the agents matching query, best match first; agents
+// scoring the same keep their order. An empty query matches every agent.
+func filterAgents(agents []*parser.AgentSpec, query string) []*parser.AgentSpec {
+	needle := []rune(strings.ToLower(strings.TrimSpace(query)))
+	if len(needle) == 0 {
+		return append([]*parser.AgentSpec(nil), agents...)
+	}
+
+	type scored struct {
+		agent *parser.AgentSpec
+		score int
+	}
+	var matches []scored
+	for _, agent := range agents {
+		if score, ok := matchScore(agent, needle); ok {
+			matches = append(matches, scored{agent, score})
+		}
+	}
+	sort.SliceStable(matches, func(a, b int) bool { return matches[a].score > matches[b].score })
+
+	result := make([]*parser.AgentSpec, len(matches))
+	for n, match := range matches {
+		result[n] = match.agent
+	}
+	return result
+}
+
+// matchScore scores agent against a lowercase query, fzf style: the query
+// characters must appear in order in the qualified name or the description.
+// Name matches outrank description matches.
+func matchScore(agent *parser.AgentSpec, query []rune) (int, bool) {
+	if score, ok := subsequenceScore([]rune(strings.ToLower(agent.QualifiedName())), query); ok {
+		return 1000 + score, true
+	}
+	return subsequenceScore([]rune(strings.ToLower(agent.Description)), query)
+}
+
+// subsequenceScore matches query as a subsequence of text, rewarding
+// consecutive characters and characters starting a word
+func subsequenceScore(text, query []rune) (int, bool) {
+	score, pos, prev := 0, 0, -2
+	for _, char := range query {
+		for pos < len(text) && text[pos] != char {
+			pos++
+		}
+		if pos == len(text) {
+			return 0, false
+		}
+		score++
+		if pos == prev+1 {
+			score += 2
+		}
+		if pos == 0 || !unicode.IsLetter(text[pos-1]) && !unicode.IsDigit(text[pos-1]) {
+			score += 3
+		}
+		prev = pos
+		pos++
+	}
+	// Shorter texts are closer matches
+	return score*100 - len(text), true
+}
+
+// finder is the state of the full-screen selector
+type finder struct {
+	agents  []*parser.AgentSpec
+	query   []rune
+	matches []*parser.AgentSpec
+	cursor  int
+	offset  int // index of the first listed match
+	width   int
+}
+
+// runFinder reads keys from in, a terminal in raw mode, and redraws the
+// selector on out until an agent is picked or the selection is cancelled.
+// Typing narrows the list, the arrow keys or Ctrl-P/Ctrl-N move, Enter picks
+// and Esc or Ctrl-C cancels.
+func runFinder(agents []*parser.AgentSpec, query string, in io.Reader, out io.Writer, width int) (*parser.AgentSpec, error) {
+	f := &finder{agents: agents, query: []rune(query), width: width}
+	f.filter()
+
+	reader := bufio.NewReader(in)
+	for {
+		f.render(out)
+		key, _, err := reader.ReadRune()
+		if err != nil {
+			f.clear(out)
+			return nil, errSelectionCancelled
+		}
+
+		switch key {
+		case '\r', '\n':
+			if len(f.matches) > 0 {
+				f.clear(out)
+				return f.matches[f.cursor], nil
+			}
+		case 3, 4: // Ctrl-C, Ctrl-D
+			f.clear(out)
+			return nil, errSelectionCancelled
+		case 27: // Esc, or the start of an arrow key sequence
+			if reader.Buffered() == 0 {
+				f.clear(out)
+				return nil, errSelectionCancelled
+			}
+			if next, _, _ := reader.ReadRune(); next != '[' && next != 'O' {
+				f.clear(out)
+				return nil, errSelectionCancelled
+			}
+			switch arrow, _, _ := reader.ReadRune(); arrow {
+			case 'A':
+				f.move(-1)
+			case 'B':
+				f.move(1)
+			}
+		case 16: // Ctrl-P
+			f.move(-1)
+		case 14: // Ctrl-N
+			f.move(1)
+		case 127, 8: // Backspace
+			if len(f.query) > 0 {
+				f.query = f.query[:len(f.query)-1]
+				f.filter()
+			}
+		case 21: // Ctrl-U
+			f.query = nil
+			f.filter()
+		default:
+			if unicode.IsPrint(key) {
+				f.query = append(f.query, key)
+				f.filter()
+			}
+		}
+	}
+}
+
+// filter recomputes the matches for the current query
+func (f *finder) filter() {
+	f.matches = filterAgents(f.agents, string(f.query))
+	f.cursor, f.offset = 0, 0
+}
+
+// move moves the cursor by delta, scrolling the list to keep it visible
+func (f *finder) move(delta int) {
+	if len(f.matches) == 0 {
+		return
+	}
+	f.cursor = (f.cursor + delta + len(f.matches)) % len(f.matches)
+	if f.cursor < f.offset {
+		f.offset = f.cursor
+	}
+	if f.cursor >= f.offset+selectorRows {
+		f.offset = f.cursor - selectorRows + 1
+	}
+}
+
+// lines returns the selector as display lines: the query, the match count,
+// the visible matches and a preview of the highlighted agent
+func (f *finder) lines() []string {
+	lines := []string{
+		"> " + string(f.query),
+		fmt.Sprintf("  %d/%d", len(f.matches), len(f.agents)),
+	}
+
+	end := f.offset + selectorRows
+	if end > len(f.matches) {
+		end = len(f.matches)
+	}
+	visible := f.matches[f.offset:end]
+	nameWidth := 0
+	for _, agent := range visible {
+		if n := len([]rune(selectLabel(agent, f.agents))); n > nameWidth {
+			nameWidth = n
+		}
+	}
+	for n, agent := range visible {
+		marker := "  "
+		if f.offset+n == f.cursor {
+			marker = "> "
+		}
+		line := fmt.Sprintf("%s%-*s  %s", marker, nameWidth, selectLabel(agent, f.agents), firstLine(agent.Description))
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+
+	if len(f.matches) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, agentPreview(f.matches[f.cursor])...)
+	}
+	return lines
+}
+
+// selectLabel names agent in the selector by its qualified name, followed by
+// the shortest path suffix telling it apart when another of agents has the
+// same qualified name, such as helper.md installed in two directories
+func selectLabel(agent *parser.AgentSpec, agents []*parser.AgentSpec) string {
+	var namesakes []*parser.AgentSpec
+	for _, other := range agents {
+		if other.QualifiedName() == agent.QualifiedName() {
+			namesakes = append(namesakes, other)
+		}
+	}
+	if len(namesakes) < 2 {
+		return agent.QualifiedName()
+	}
+	return fmt.Sprintf("%s (%s)", agent.QualifiedName(), engine.UniqueSuffix(agent, namesakes))
+}
+
+// render redraws the selector, leaving the cursor after the query
+func (f *finder) render(out io.Writer) {
+	var b strings.Builder
+	b.WriteString("\r\x1b[J")
+	lines := f.lines()
+	for n, line := range lines {
+		if n > 0 {
+			b.WriteString("\r\n")
+		}
+		line = clip(line, f.width-1)
+		if n > 1 && strings.HasPrefix(line, "> ") {
+			line = color.CyanString("%s", line)
+		}
+		b.WriteString(line)
+	}
+	if len(lines) > 1 {
+		fmt.Fprintf(&b, "\x1b[%dA", len(lines)-1)
+	}
+	fmt.Fprintf(&b, "\r\x1b[%dC", len([]rune(clip(lines[0], f.width-1))))
+	_, _ = io.WriteString(out, b.String())
+}
+
+// clear erases the selector
+func (f *finder) clear(out io.Writer) {
+	_, _ = io.WriteString(out, "\r\x1b[J")
+}
+
+// promptSelect is the selector without a terminal: it lists the candidates
+// numbered and reads lines holding either a number, picking that agent, or
+// text narrowing the list
+func promptSelect(agents []*parser.AgentSpec, query string, in io.Reader, out io.Writer) (*parser.AgentSpec, error) {
+	reader := bufio.NewReader(in)
+	matches := filterAgents(agents, query)
+	for {
+		if len(matches) == 0 {
+			fmt.Fprintf(out, "No agents match %q\n", query)
+			matches = agents
+		}
+
+		shown := matches
+		if len(shown) > 2*selectorRows {
+			shown = shown[:2*selectorRows]
+		}
+		for n, agent := range shown {
+			fmt.Fprintf(out, "%3d) %s", n+1, selectLabel(agent, agents))
+			if description := firstLine(agent.Description); description != "" {
+				fmt.Fprintf(out, " - %s", clip(description, 60))
+			}
+			fmt.Fprintln(out)
+		}
+		if hidden := len(matches) - len(shown); hidden > 0 {
+			fmt.Fprintf(out, "     ... and %d more; type text to narrow the list\n", hidden)
+		}
+		fmt.Fprintf(out, "Select an agent [1-%d], or type to filter: ", len(shown))
+
+		line, err := reader.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			if err == nil {
+				continue
+			}
+			fmt.Fprintln(out)
+			return nil, errSelectionCancelled
+		}
+		if n, convErr := strconv.Atoi(answer); convErr == nil {
+			if n >= 1 && n <= len(shown) {
+				return shown[n-1], nil
+			}
+			fmt.Fprintf(out, "Invalid choice: %d\n", n)
+			continue
+		}
+		query = answer
+		matches = filterAgents(agents, query)
+	}
+}
+
+// agentPreview returns the preview lines of the highlighted agent
+func agentPreview(agent *parser.AgentSpec) []string {
+	lines := []string{"  " + agent.FilePath}
+	if agent.Description != "" {
+		lines = append(lines, "  "+firstLine(agent.Description))
+	}
+	prompt := strings.Split(strings.TrimSpace(agent.Prompt), "\n")
+	for n, line := range prompt {
+		if n == selectorPreviewLines {
+			lines = append(lines, fmt.Sprintf("  | ... (%d more lines)", len(prompt)-n))
+			break
+		}
+		lines = append(lines, "  | "+strings.ReplaceAll(line, "\t", "    "))
+	}
+	return lines
+}
+
+// firstLine returns the first line of text
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return line
+}
+
+// clip shortens s to at most width characters, marking a cut with "..."
+func clip(s string, width int) string {
+	runes := []rune(s)
+	if width < 4 || len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-3]) + "..."
+}
@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+)
+
+func selectorAgents() []*parser.AgentSpec {
+	return []*parser.AgentSpec{
+		{Name: "reviewer", Description: "Reviews pull requests", FilePath: "/agents/reviewer.md", Prompt: "Review the diff"},
+		{Name: "reviewer", Namespace: "team", Description: "Team review rules", FilePath: "/agents/team/reviewer.md"},
+		{Name: "go-specialist", Description: "Writes idiomatic Go", FilePath: "/agents/go-specialist.md"},
+		{Name: "planner", Description: "Plans reviews of architecture", FilePath: "/agents/planner.md"},
+	}
+}
+
+func qualifiedNames(agents []*parser.AgentSpec) string {
+	var names []string
+	for _, agent := range agents {
+		names = append(names, agent.QualifiedName())
+	}
+	return strings.Join(names, ",")
+}
+
+func TestFilterAgents(t *testing.T) {
+	agents := selectorAgents()
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "reviewer,team/reviewer,go-specialist,planner"},
+		{"rev", "reviewer,team/reviewer,planner"},
+		{"gsp", "go-specialist"},
+		{"TEAM/R", "team/reviewer"},
+		{"idiomatic", "go-specialist"},
+		{"xyz", ""},
+	}
+	for _, tt := range tests {
+		if got := qualifiedNames(filterAgents(agents, tt.query)); got != tt.want {
+			t.Errorf("filterAgents(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestRunFinder(t *testing.T) {
+	agents := selectorAgents()
+
+	tests := []struct {
+		name  string
+		query string
+		keys  string
+		want  string
+	}{
+		{"enter picks the first match", "", "\r", "reviewer"},
+		{"typing narrows the list", "", "plan\r", "planner"},
+		{"arrow keys move", "rev", "\x1b[B\r", "team/reviewer"},
+		{"moving up wraps around", "", "\x10\r", "planner"},
+		{"backspace widens the list", "plann", "\x7f\x7f\x7f\x7f\x7f\x0e\x0e\r", "go-specialist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			agent, err := runFinder(agents, tt.query, strings.NewReader(tt.keys), &out, 80)
+			if err != nil {
+				t.Fatalf("runFinder() error = %v", err)
+			}
+			if agent.QualifiedName() != tt.want {
+				t.Errorf("runFinder() picked %q, want %q", agent.QualifiedName(), tt.want)
+			}
+		})
+	}
+
+	var out bytes.Buffer
+	if _, err := runFinder(agents, "", strings.NewReader("\x03"), &out, 80); !errors.Is(err, errSelectionCancelled) {
+		t.Errorf("Expected Ctrl-C to cancel, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Review the diff") || !strings.Contains(out.String(), "Reviews pull requests") {
+		t.Errorf("Expected a preview of the highlighted agent, got %q", out.String())
+	}
+	if _, err := runFinder(agents, "xyz", strings.NewReader("\r"), &out, 80); !errors.Is(err, errSelectionCancelled) {
+		t.Errorf("Expected Enter without matches to be ignored, got %v", err)
+	}
+}
+
+func TestPromptSelect(t *testing.T) {
+	agents := selectorAgents()
+
+	var out bytes.Buffer
+	agent, err := promptSelect(agents, "", strings.NewReader("9\nteam\n1\n"), &out)
+	if err != nil {
+		t.Fatalf("promptSelect() error = %v", err)
+	}
+	if agent.QualifiedName() != "team/reviewer" {
+		t.Errorf("promptSelect() picked %q, want team/reviewer", agent.QualifiedName())
+	}
+	for _, want := range []string{"  4) planner - Plans reviews of architecture", "Invalid choice: 9", "  1) team/reviewer"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	if _, err := promptSelect(agents, "", strings.NewReader(""), &out); !errors.Is(err, errSelectionCancelled) {
+		t.Errorf("Expected end of input to cancel, got %v", err)
+	}
+}
+
+func TestSelectLabel(t *testing.T) {
+	agents := selectorAgents()
+	copied := &parser.AgentSpec{Name: "reviewer", Namespace: "team", FilePath: "/project/agents/team/reviewer.md"}
+	agents = append(agents, copied)
+
+	if got := selectLabel(agents[0], agents); got != "reviewer" {
+		t.Errorf("Expected a unique name to be used as is, got %q", got)
+	}
+	if got := selectLabel(agents[1], agents); got != "team/reviewer (/agents/team/reviewer.md)" {
+		t.Errorf("Expected the whole path when no suffix tells the agents apart, got %q", got)
+	}
+	if got := selectLabel(copied, agents); got != "team/reviewer (project/agents/team/reviewer.md)" {
+		t.Errorf("Expected the shortest telling suffix, got %q", got)
+	}
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/engine"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/server"
+	"github.com/spf13/cobra"
+)
+
+// shutdownTimeout bounds how long in-flight requests may finish after a stop signal
+const shutdownTimeout = 5 * time.Second
+
+// ServeIndexCommand implements serving the query index over HTTP
+type ServeIndexCommand struct {
+	listen  string
+	refresh time.Duration
+}
+
+// NewServeIndexCommand creates a new serve-index command instance
+func NewServeIndexCommand() *ServeIndexCommand {
+	return &ServeIndexCommand{}
+}
+
+// Name returns the command name
+func (c *ServeIndexCommand) Name() string {
+	return "serve-index"
+}
+
+// Description returns the command description
+func (c *ServeIndexCommand) Description() string {
+	return "Serve the agent index as a read-only HTTP JSON API"
+}
+
+// CreateCommand creates the cobra command for serve-index functionality
+func (c *ServeIndexCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve-index",
+		Short: c.Description(),
+		Long: `Serve the query index over HTTP for dashboards, chatops and other quick
+integrations. Only GET requests are served:
+
+  GET /agents          every indexed agent, without prompt bodies
+  GET /agents/{name}   one agent by name, qualified name or file name
+  GET /search?q=       agents matching a query, without prompt bodies
+
+/agents and /search accept limit and source parameters. An ambiguous agent name
+returns 409 with the matching qualified names. The index is refreshed from the
+agent directories every --refresh interval. There is no authentication, so
+the server listens on loopback by default; only listen on other addresses when
+the network is trusted.
+
+Examples:
+  agent-manager serve-index
+  agent-manager serve-index --listen 127.0.0.1:8080 --refresh 5m
+  agent-manager serve-index --listen :7777
+  curl 'localhost:7777/search?q=go&limit=5'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.Execute(sharedCtx)
+		},
+	}
+
+	cmd.Flags().StringVar(&c.listen, "listen", "127.0.0.1:7777", "address to listen on")
+	cmd.Flags().DurationVar(&c.refresh, "refresh", time.Minute, "how often to refresh the index from disk (0 disables)")
+
+	return cmd
+}
+
+// Execute runs the serve-index command logic
+func (c *ServeIndexCommand) Execute(sharedCtx *SharedContext) error {
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	queryEngine, err := sharedCtx.CreateQueryEngine()
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", c.listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", c.listen, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if c.refresh > 0 {
+		go c.refreshIndex(ctx, sharedCtx, queryEngine)
+	}
+
+	srv := &http.Server{
+		Handler:           server.New(queryEngine).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Serve(listener) }()
+
+	PrintSuccess("Serving %d agents on http://%s (Ctrl-C to stop)", len(queryEngine.GetAllAgents()), listener.Addr())
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to stop server: %w", err)
+	}
+	PrintInfo("Server stopped")
+	return nil
+}
+
+// refreshIndex updates the index from the agent directories until ctx is done.
+// Queries keep using the previous index while a refresh is in progress.
+func (c *ServeIndexCommand) refreshIndex(ctx context.Context, sharedCtx *SharedContext, queryEngine *engine.Engine) {
+	ticker := time.NewTicker(c.refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		roots, err := sharedCtx.indexRoots()
+		if err == nil {
+			err = queryEngine.UpdateIndexRoots(roots)
+		}
+		if err != nil {
+			PrintWarning("Failed to refresh index: %v", err)
+		}
+	}
+}
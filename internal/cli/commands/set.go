@@ -0,0 +1,313 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/engine"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/validator"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// SetCommand implements bulk frontmatter editing across agents matching a query
+type SetCommand struct {
+	query    string
+	noBackup bool
+	edits    []parser.FrontmatterEdit
+}
+
+// NewSetCommand creates a new set command instance
+func NewSetCommand() *SetCommand {
+	return &SetCommand{}
+}
+
+// Name returns the command name
+func (c *SetCommand) Name() string {
+	return "set"
+}
+
+// Description returns the command description
+func (c *SetCommand) Description() string {
+	return "Bulk edit frontmatter fields of agents matching a query"
+}
+
+// CreateCommand creates the cobra command for set functionality
+func (c *SetCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set --query QUERY EDIT...",
+		Short: c.Description(),
+		Long: `Apply frontmatter edits to every installed agent matching a query.
+
+Edits:
+  key=value           set a field (comma-separated for tools)
+  tools+=Grep,Glob    add items to a list field
+  tools-=WebSearch    remove items from a list field
+  key~=s/old/new/     regex substitution on a text field
+
+Queries accept field:value terms (name, description, tools, source, content)
+and plain text; all terms must match.
+
+Examples:
+  agent-manager set --query "source:marketplace" tools+=Grep --dry-run
+  agent-manager set --query "tools:Bash" description~="s/foo/bar/"
+  agent-manager set --query "name:reviewer" model=sonnet`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c.edits = c.edits[:0]
+			for _, arg := range args {
+				edit, err := parser.ParseFrontmatterEdit(arg)
+				if err != nil {
+					return err
+				}
+				c.edits = append(c.edits, edit)
+			}
+			return c.Execute(sharedCtx)
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.query, "query", "q", "", "select agents to edit (required)")
+	cmd.Flags().BoolVar(&c.noBackup, "no-backup", false, "do not back up files before editing")
+	_ = cmd.MarkFlagRequired("query")
+
+	return cmd
+}
+
+// agentChange holds the planned content change for a single agent
+type agentChange struct {
+	agent  *parser.AgentSpec
+	before string
+	after  string
+}
+
+// Execute runs the set command logic
+func (c *SetCommand) Execute(sharedCtx *SharedContext) error {
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	queryEngine, err := sharedCtx.CreateQueryEngine()
+	if err != nil {
+		return err
+	}
+
+	agents, err := selectAgents(queryEngine, c.query)
+	if err != nil {
+		return err
+	}
+	if len(agents) == 0 {
+		PrintWarning("No agents match query %q", c.query)
+		return nil
+	}
+
+	changes, failed := c.planChanges(sharedCtx, agents)
+
+	if sharedCtx.Options.DryRun {
+		c.printDiffs(changes)
+		color.Yellow("[DRY RUN] Would update %d of %d matching agents\n", len(changes), len(agents))
+		return c.failureError(failed)
+	}
+
+	if len(changes) == 0 {
+		PrintInfo("No changes needed for %d matching agents", len(agents))
+		return c.failureError(failed)
+	}
+
+	backupDir := ""
+	if !c.noBackup {
+		backupDir = filepath.Join(sharedCtx.Config.Settings.BackupDir, "edits", time.Now().Format("20060102-150405"))
+	}
+
+	var updated []string
+	for _, change := range changes {
+		if err := writeAgentChange(change, sharedCtx.GetAgentsDirectory(), backupDir); err != nil {
+			PrintError("Failed to update %s: %v", change.agent.FilePath, err)
+			failed++
+			continue
+		}
+		updated = append(updated, change.agent.FilePath)
+		if sharedCtx.Options.Verbose {
+			PrintInfo("Updated %s", change.agent.FilePath)
+		}
+	}
+
+	refreshTracking(sharedCtx, updated)
+
+	PrintSuccess("Updated %d of %d matching agents", len(updated), len(agents))
+	if backupDir != "" && len(updated) > 0 {
+		PrintInfo("Backups saved to %s", backupDir)
+	}
+
+	if err := sharedCtx.PM.WithSpinner("Updating index", func() error {
+		return queryEngine.UpdateIndex(sharedCtx.GetAgentsDirectory())
+	}); err != nil {
+		return err
+	}
+
+	return c.failureError(failed)
+}
+
+// planChanges computes and validates new content for each agent, warning
+// about unknown tools an edit adds when settings.query.validation checks them
+func (c *SetCommand) planChanges(sharedCtx *SharedContext, agents []*parser.AgentSpec) ([]agentChange, int) {
+	validation := sharedCtx.Config.Settings.Query.Validation
+	v := validator.NewValidator()
+	v.SetAllowedTools(validation.AllowedTools)
+	p := parser.NewParser()
+
+	var changes []agentChange
+	failed := 0
+
+	for _, agent := range agents {
+		content, err := os.ReadFile(agent.FilePath)
+		if err != nil {
+			PrintError("Failed to read %s: %v", agent.FilePath, err)
+			failed++
+			continue
+		}
+
+		updated, err := parser.ApplyFrontmatterEdits(string(content), c.edits)
+		if err != nil {
+			PrintError("Failed to edit %s: %v", agent.FilePath, err)
+			failed++
+			continue
+		}
+		if updated == string(content) {
+			continue
+		}
+
+		spec, err := p.ParseContent([]byte(updated))
+		if err == nil {
+			err = v.Validate(spec)
+		}
+		if err != nil {
+			PrintError("Edit would make %s invalid: %v", agent.FilePath, err)
+			failed++
+			continue
+		}
+		if validation.CheckToolValidity {
+			known := make(map[string]bool)
+			for _, tool := range v.UnknownTools(agent.GetToolsAsSlice()) {
+				known[tool] = true
+			}
+			for _, tool := range v.UnknownTools(spec.GetToolsAsSlice()) {
+				if !known[tool] {
+					PrintWarning("Edit gives %s unknown tool %s", agent.FilePath, tool)
+				}
+			}
+		}
+
+		changes = append(changes, agentChange{agent: agent, before: string(content), after: updated})
+	}
+
+	return changes, failed
+}
+
+// printDiffs prints a unified diff for each planned change
+func (c *SetCommand) printDiffs(changes []agentChange) {
+	for _, change := range changes {
+		diff := util.UnifiedDiff(change.before, change.after, change.agent.FilePath, change.agent.FilePath)
+		for _, line := range strings.SplitAfter(diff, "\n") {
+			switch {
+			case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+				fmt.Print(line)
+			case strings.HasPrefix(line, "+"):
+				color.Green("%s", line)
+			case strings.HasPrefix(line, "-"):
+				color.Red("%s", line)
+			default:
+				fmt.Print(line)
+			}
+		}
+	}
+}
+
+// failureError returns an error summarizing failed agents, if any
+func (c *SetCommand) failureError(failed int) error {
+	if failed > 0 {
+		return fmt.Errorf("%d agent(s) could not be updated", failed)
+	}
+	return nil
+}
+
+// writeAgentChange backs up an agent file (when backupDir is set) and writes
+// its new content. Backups keep the agent's path below agentsDir, so agents
+// with the same file name in different directories do not overwrite each
+// other's backup.
+func writeAgentChange(change agentChange, agentsDir, backupDir string) error {
+	info, err := os.Stat(change.agent.FilePath)
+	if err != nil {
+		return err
+	}
+
+	if backupDir != "" {
+		rel, err := filepath.Rel(agentsDir, change.agent.FilePath)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			rel = change.agent.FileName
+		}
+		backupPath := filepath.Join(backupDir, rel)
+		if err := os.MkdirAll(filepath.Dir(backupPath), 0750); err != nil {
+			return fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		if err := os.WriteFile(backupPath, []byte(change.before), 0600); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+	}
+
+	return os.WriteFile(change.agent.FilePath, []byte(change.after), info.Mode().Perm())
+}
+
+// selectAgents returns agents matching every term of a query; terms are either
+// field:value pairs or plain text matched against name, description and prompt
+func selectAgents(queryEngine *engine.Engine, query string) ([]*parser.AgentSpec, error) {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+
+	var selected map[string]*parser.AgentSpec
+	for _, term := range terms {
+		var matches []*parser.AgentSpec
+		var err error
+
+		if field, value, ok := strings.Cut(term, ":"); ok && isQueryField(field) {
+			matches, err = queryEngine.QueryByField(field, value)
+		} else {
+			matches, err = queryEngine.Query(term, engine.QueryOptions{})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %w", err)
+		}
+
+		next := make(map[string]*parser.AgentSpec)
+		for _, agent := range matches {
+			if selected == nil || selected[agent.FilePath] != nil {
+				next[agent.FilePath] = agent
+			}
+		}
+		selected = next
+	}
+
+	result := make([]*parser.AgentSpec, 0, len(selected))
+	for _, agent := range queryEngine.GetAllAgents() {
+		if selected[agent.FilePath] != nil {
+			result = append(result, agent)
+		}
+	}
+	return result, nil
+}
+
+// isQueryField reports whether field is supported in field:value query terms
+func isQueryField(field string) bool {
+	switch strings.ToLower(field) {
+	case "name", "description", "tools", "source", "content", "prompt":
+		return true
+	default:
+		return false
+	}
+}
@@ -1,26 +1,53 @@
 package commands
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/pacphi/claude-code-agent-manager/internal/config"
 	"github.com/pacphi/claude-code-agent-manager/internal/conflict"
 	"github.com/pacphi/claude-code-agent-manager/internal/installer"
+	"github.com/pacphi/claude-code-agent-manager/internal/migrate"
 	"github.com/pacphi/claude-code-agent-manager/internal/progress"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/engine"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
 	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 	"github.com/spf13/cobra"
 )
 
 // SharedOptions holds common configuration options used across commands
 type SharedOptions struct {
 	ConfigFile string
-	Verbose    bool
-	DryRun     bool
-	NoColor    bool
-	NoProgress bool
+	// NoAutoDiscover keeps the default configuration file in the working
+	// directory instead of looking for the project or user configuration
+	NoAutoDiscover bool
+	BaseDir        string
+	Verbose        bool
+	DryRun         bool
+	Apply          bool
+	NoColor        bool
+	NoProgress     bool
+	Plain          bool
+	Quiet          bool
+	// Absolute prints exact timestamps instead of times relative to now
+	Absolute bool
+
+	// ProgressFormat is text for progress bars or json for progress events
+	// written to the descriptor ProgressFD
+	ProgressFormat string
+	ProgressFD     int
+	progressEvents io.Writer
 }
 
 // SharedContext provides shared dependencies and helpers for commands
@@ -28,6 +55,42 @@ type SharedContext struct {
 	Options *SharedOptions
 	Config  *config.Config
 	PM      *progress.Manager
+
+	ctx context.Context
+	// mutating is set when the running command changes agents or files
+	mutating bool
+	// policyDryRun is set when settings.default_dry_run forced dry-run mode
+	policyDryRun bool
+	installers   []*installer.Installer
+	// trackerKey signs tracking file entries when metadata.signing_key is set
+	trackerKey []byte
+	// summary and stdout hold the quiet-mode summary line and where it is written
+	summary []summaryField
+	stdout  io.Writer
+}
+
+// ExitPlanned is the exit code of a mutating command that only planned its
+// changes because of a dry-run policy
+const ExitPlanned = 6
+
+// ExitPartial is the exit code of a command run over several sources when
+// some of them failed and the rest succeeded
+const ExitPartial = 8
+
+// ExitError carries the process exit code for an error
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error returns the underlying error message
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ExitError) Unwrap() error {
+	return e.Err
 }
 
 // NewSharedContext creates a new shared context for commands
@@ -38,17 +101,247 @@ func NewSharedContext(opts *SharedOptions) *SharedContext {
 	}
 }
 
+// WithTimeout sets a command-level context that expires after timeout.
+// A zero timeout falls back to settings.timeout from the loaded configuration.
+func (sc *SharedContext) WithTimeout(timeout time.Duration) context.CancelFunc {
+	if timeout == 0 && sc.Config != nil {
+		timeout = sc.Config.Settings.Timeout
+	}
+
+	var cancel context.CancelFunc
+	if timeout <= 0 {
+		sc.ctx, cancel = context.WithCancel(context.Background())
+	} else {
+		sc.ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	}
+	return cancel
+}
+
+// Context returns the command-level context, or a background context if none was set
+func (sc *SharedContext) Context() context.Context {
+	if sc.ctx == nil {
+		return context.Background()
+	}
+	return sc.ctx
+}
+
 // LoadConfig loads and validates the configuration file with progress indication
 func (sc *SharedContext) LoadConfig() error {
-	return sc.PM.WithSpinner("Loading configuration", func() error {
+	err := sc.PM.WithSpinner("Loading configuration", func() error {
 		var err error
 		sc.Config, err = config.Load(sc.Options.ConfigFile)
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
+		if sc.Options.BaseDir != "" {
+			if err := sc.overrideBaseDir(); err != nil {
+				return err
+			}
+		}
 
 		return config.Validate(sc.Config)
 	})
+	if err != nil {
+		return err
+	}
+
+	sc.applyDryRunPolicy()
+	sc.runMigrations()
+	sc.cleanStaleTempDirs()
+	return sc.loadTrackerKey()
+}
+
+// discoverConfig finds the configuration of the project the command runs in,
+// walking up from the working directory or $CLAUDE_PROJECT_DIR, and falls back
+// to the user configuration. The command then runs from the project root (or
+// the home directory for the user scope), so relative paths in the
+// configuration resolve as if it had been started there; the paths given on
+// the command line are made absolute first so they keep meaning what was
+// typed. Commands acting on the working directory stay there for the user
+// scope. Nothing changes when --config or --no-auto-discover is given.
+func (sc *SharedContext) discoverConfig(cmd *cobra.Command, args []string) error {
+	if cmd.Flags().Changed("config") || sc.Options.NoAutoDiscover {
+		if sc.Options.Verbose {
+			PrintInfo("Using configuration %s", sc.Options.ConfigFile)
+		}
+		return nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to find configuration: %w", err)
+	}
+	start := cwd
+	if dir := os.Getenv(config.ProjectDirEnv); dir != "" {
+		start = dir
+	}
+	found := config.Discover(start)
+	if found == nil {
+		if sc.Options.Verbose {
+			PrintInfo("No project or user configuration found; using %s", sc.Options.ConfigFile)
+		}
+		return nil
+	}
+
+	stay := found.Scope == config.ScopeUser && workingDirCommands[topLevel(cmd).Name()]
+	if found.Root != cwd && !stay {
+		absolutizeUserPaths(cmd, args)
+		if err := os.Chdir(found.Root); err != nil {
+			return fmt.Errorf("failed to enter %s root %s: %w", found.Scope, found.Root, err)
+		}
+	}
+	sc.Options.ConfigFile = found.Path
+	if sc.Options.Verbose {
+		runFrom := found.Root
+		if stay {
+			runFrom = cwd
+		}
+		PrintInfo("Using configuration %s (%s scope, run from %s)", found.Path, found.Scope, runFrom)
+	}
+	return nil
+}
+
+// loadTrackerKey reads the key tracking file entries are signed with, when
+// configured, and warns about entries that no longer match their signature
+func (sc *SharedContext) loadTrackerKey() error {
+	if sc.Config.Metadata.SigningKey == "" {
+		sc.trackerKey = nil
+		return nil
+	}
+	path, err := util.ExpandPath(sc.Config.Metadata.SigningKey)
+	if err != nil {
+		return fmt.Errorf("invalid metadata.signing_key: %w", err)
+	}
+	sc.trackerKey, err = tracker.LoadSigningKey(path)
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	issues, err := sc.Tracker().Verify()
+	if err != nil {
+		PrintWarning("Failed to verify the tracking file: %v", err)
+		return nil
+	}
+	for _, issue := range issues {
+		if issue.Problem == tracker.IntegrityModified {
+			PrintWarning("Tracking entries were modified outside agent-manager; run 'agent-manager doctor' for details")
+			break
+		}
+	}
+	return nil
+}
+
+// Tracker returns the installation tracker, signing and verifying its entries
+// when metadata.signing_key is set
+func (sc *SharedContext) Tracker() *tracker.Tracker {
+	if sc.trackerKey != nil {
+		return tracker.NewSigned(sc.Config.Metadata.TrackingFile, sc.trackerKey)
+	}
+	return tracker.New(sc.Config.Metadata.TrackingFile)
+}
+
+// cleanStaleTempDirs removes temp directories left behind by crashed runs
+// before a command that changes agents creates its own
+func (sc *SharedContext) cleanStaleTempDirs() {
+	maxAge := sc.Config.Settings.TempCleanupAge
+	if !sc.mutating || sc.Options.DryRun || maxAge < 0 {
+		return
+	}
+	stale, err := util.FindStaleTempDirs(maxAge)
+	if err == nil && len(stale) > 0 {
+		var reclaimed int64
+		reclaimed, err = util.RemoveStaleTempDirs(stale)
+		if err == nil {
+			PrintInfo("Removed %d temp directories left by crashed runs, reclaiming %s", len(stale), formatBytes(reclaimed))
+		}
+	}
+	if err != nil {
+		PrintWarning("Failed to clean up temp directories: %v", err)
+	}
+}
+
+// overrideBaseDir scans the directory given with --base-dir instead of
+// settings.base_dir for this invocation; the configuration file is not changed
+func (sc *SharedContext) overrideBaseDir() error {
+	dir, err := util.ExpandPath(sc.Options.BaseDir)
+	if err != nil {
+		return fmt.Errorf("invalid --base-dir: %w", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("invalid --base-dir: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("invalid --base-dir: %s is not a directory", dir)
+	}
+	sc.Config.Settings.OverrideBaseDir(dir)
+	return nil
+}
+
+// runMigrations moves state left by older versions to the current layout.
+// A failed migration is a warning: the command still runs against the
+// current layout, and the migration is retried next time.
+func (sc *SharedContext) runMigrations() {
+	result, err := migrate.Run(sc.Config, sc.Options.DryRun)
+	if err != nil {
+		PrintWarning("Failed to migrate from an older layout: %v", err)
+	}
+	if result == nil {
+		return
+	}
+	for _, applied := range result.Applied {
+		for _, move := range applied.Moved {
+			switch {
+			case sc.Options.DryRun:
+				PrintInfo("Would migrate %s to %s (%s)", move.From, move.To, applied.Description)
+			case move.Copy:
+				PrintInfo("Copied %s to %s (backup in %s)", move.From, move.To, applied.Backup)
+			default:
+				PrintInfo("Migrated %s to %s (backup in %s)", move.From, move.To, applied.Backup)
+			}
+		}
+	}
+	for _, move := range result.Kept {
+		PrintWarning("Left %s from an older version in place: %s already exists; merge or remove it by hand", move.From, move.To)
+	}
+}
+
+// applyDryRunPolicy switches to dry-run mode when settings.default_dry_run is
+// enabled and --apply was not given, and reports the mode of mutating commands
+func (sc *SharedContext) applyDryRunPolicy() {
+	if !sc.Config.Settings.DefaultDryRun {
+		return
+	}
+
+	if !sc.Options.Apply && !sc.Options.DryRun {
+		sc.Options.DryRun = true
+		sc.policyDryRun = true
+		SetupProgress(sc.Options)
+		sc.PM = progress.Default()
+	}
+
+	if !sc.mutating {
+		return
+	}
+	if sc.Options.DryRun {
+		color.Yellow("Plan mode: no changes will be made (settings.default_dry_run is enabled; use --apply to make changes)\n")
+	} else {
+		color.Cyan("Apply mode: changes will be made\n")
+	}
+}
+
+// PlannedOnly reports whether the command skipped changes because of
+// settings.default_dry_run or a source's dry_run setting
+func (sc *SharedContext) PlannedOnly() bool {
+	if sc.policyDryRun && sc.mutating {
+		return true
+	}
+	for _, inst := range sc.installers {
+		if len(inst.Planned()) > 0 {
+			return true
+		}
+	}
+	return false
 }
 
 // CreateInstaller creates a new installer with the current configuration and options
@@ -66,10 +359,13 @@ func (sc *SharedContext) createInstallerWithOptions(opts installer.Options) (*in
 		return nil, fmt.Errorf("configuration not loaded - call LoadConfig() first")
 	}
 
-	track := tracker.New(sc.Config.Metadata.TrackingFile)
+	track := sc.Tracker()
 	resolver := conflict.NewResolver(sc.Config.Settings.ConflictStrategy, sc.Config.Settings.BackupDir)
 
-	return installer.New(sc.Config, track, resolver, opts), nil
+	opts.Apply = sc.Options.Apply
+	inst := installer.New(sc.Config, track, resolver, opts)
+	sc.installers = append(sc.installers, inst)
+	return inst, nil
 }
 
 // CreateQueryEngine creates and initializes a query engine
@@ -89,10 +385,30 @@ func (sc *SharedContext) CreateQueryEngine() (*engine.Engine, error) {
 		if engineErr != nil {
 			return fmt.Errorf("failed to create query engine: %w", engineErr)
 		}
+		queryEngine.SetParseMode(sc.Config.Settings.Query.ParserMode)
+		queryEngine.SetExtensions(sc.Config.Settings.Query.Index.Extensions)
+		queryEngine.SetWalkOptions(sc.Config.Settings.Walk.Options())
+		queryEngine.SetFieldWeights(sc.Config.Settings.Query.Weights)
+		queryEngine.SetWarningHandler(sc.parseWarningHandler())
+		queryEngine.SetAutoPrune(sc.Config.Settings.Query.Index.AutoUpdate)
+		provenance, provenanceErr := sc.installProvenance()
+		if provenanceErr != nil {
+			return provenanceErr
+		}
+		queryEngine.SetProvenance(provenance)
 
 		// Update index if needed
 		agentsDir := sc.Config.Settings.BaseDir
-		if updateErr := queryEngine.UpdateIndex(agentsDir); updateErr != nil {
+		roots, rootsErr := sc.indexRoots()
+		if rootsErr != nil {
+			return rootsErr
+		}
+		if updateErr := queryEngine.UpdateIndexRoots(roots); updateErr != nil {
+			// Strict mode failures must not be masked by a lenient rebuild
+			var strictErr *parser.StrictError
+			if errors.As(updateErr, &strictErr) {
+				return updateErr
+			}
 			// If update fails, try rebuilding
 			if rebuildErr := queryEngine.RebuildIndex(agentsDir); rebuildErr != nil {
 				return fmt.Errorf("failed to initialize index: %w", rebuildErr)
@@ -109,6 +425,105 @@ func (sc *SharedContext) CreateQueryEngine() (*engine.Engine, error) {
 	return queryEngine, nil
 }
 
+// parseWarningHandler returns the handler that prints parse warnings met while
+// indexing, or nil to drop them. Warnings are only shown with --verbose, where
+// no spinner is drawn over them.
+func (sc *SharedContext) parseWarningHandler() parser.WarningHandler {
+	if !sc.Options.Verbose {
+		return nil
+	}
+	return func(w parser.Warning) {
+		PrintWarning("%s", w)
+	}
+}
+
+// OpenQueryEngine creates a query engine over the persisted index without
+// updating it, for commands that compare indexed data with the files on disk
+func (sc *SharedContext) OpenQueryEngine() (*engine.Engine, error) {
+	if sc.Config == nil {
+		return nil, fmt.Errorf("configuration not loaded - call LoadConfig() first")
+	}
+
+	baseDir := sc.Config.Settings.BaseDir
+	queryEngine, err := engine.NewEngine(filepath.Join(baseDir, ".agent-index"), filepath.Join(baseDir, ".agent-cache"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query engine: %w", err)
+	}
+	queryEngine.SetParseMode(sc.Config.Settings.Query.ParserMode)
+	queryEngine.SetExtensions(sc.Config.Settings.Query.Index.Extensions)
+	queryEngine.SetWalkOptions(sc.Config.Settings.Walk.Options())
+	queryEngine.SetFieldWeights(sc.Config.Settings.Query.Weights)
+	queryEngine.SetWarningHandler(sc.parseWarningHandler())
+	queryEngine.SetAutoPrune(sc.Config.Settings.Query.Index.AutoUpdate)
+	provenance, err := sc.installProvenance()
+	if err != nil {
+		return nil, err
+	}
+	queryEngine.SetProvenance(provenance)
+	return queryEngine, nil
+}
+
+// installProvenance joins the tracked installations into the source, commit
+// and time each installed file came from. Files of a marketplace category
+// carry the commit and time the category was installed.
+func (sc *SharedContext) installProvenance() (map[string]engine.Provenance, error) {
+	installations, err := sc.Tracker().List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read installed sources: %w", err)
+	}
+
+	provenance := make(map[string]engine.Provenance)
+	for name, installation := range installations {
+		for path := range installation.Files {
+			provenance[path] = engine.Provenance{Source: name, Commit: installation.SourceCommit, InstalledAt: installation.Timestamp}
+		}
+		for _, category := range installation.Categories {
+			for _, path := range category.Files {
+				provenance[path] = engine.Provenance{Source: name, Commit: category.SourceCommit, InstalledAt: category.Timestamp}
+			}
+		}
+	}
+	return provenance, nil
+}
+
+// indexRoots returns the agent directories to index: base_dir followed by the
+// configured index roots. base_dir takes the scope of a root with the same
+// path, otherwise user when it is under ~/.claude and project elsewhere.
+func (sc *SharedContext) indexRoots() ([]engine.Root, error) {
+	baseDir, err := util.ExpandPath(sc.Config.Settings.BaseDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base directory: %w", err)
+	}
+	base := engine.Root{Dir: baseDir, Scope: parser.ScopeProject}
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := filepath.Rel(filepath.Join(home, ".claude"), absPath(baseDir)); err == nil && filepath.IsLocal(rel) {
+			base.Scope = parser.ScopeUser
+		}
+	}
+
+	roots := []engine.Root{base}
+	for _, root := range sc.Config.Settings.Query.Index.Roots {
+		dir, err := util.ExpandPath(root.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index root %s: %w", root.Path, err)
+		}
+		if absPath(dir) == absPath(baseDir) {
+			roots[0].Scope = root.Scope
+			continue
+		}
+		roots = append(roots, engine.Root{Dir: dir, Scope: root.Scope})
+	}
+	return roots, nil
+}
+
+// absPath returns the absolute form of path, or path itself if it cannot be resolved
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
 // GetSourceByName finds a source configuration by name
 func (sc *SharedContext) GetSourceByName(sourceName string) (*config.Source, error) {
 	if sc.Config == nil {
@@ -131,7 +546,22 @@ func (sc *SharedContext) FilterEnabledSources(sourceName string) ([]config.Sourc
 
 	var sources []config.Source
 
-	if sourceName != "" {
+	if isSourcePattern(sourceName) {
+		names := make([]string, 0, len(sc.Config.Sources))
+		for _, source := range sc.Config.Sources {
+			if source.Enabled {
+				names = append(names, source.Name)
+			}
+		}
+		matched, err := matchSourceNames(sourceName, names)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range matched {
+			source, _ := sc.GetSourceByName(name)
+			sources = append(sources, *source)
+		}
+	} else if sourceName != "" {
 		source, err := sc.GetSourceByName(sourceName)
 		if err != nil {
 			return nil, err
@@ -150,6 +580,132 @@ func (sc *SharedContext) FilterEnabledSources(sourceName string) ([]config.Sourc
 	return sources, nil
 }
 
+// sourceRegexPrefix marks a --source value as a regular expression
+const sourceRegexPrefix = "re:"
+
+// isSourcePattern reports whether a --source value selects sources by
+// pattern, either a glob such as 'marketplace-*' or a regular expression
+// prefixed with 're:', rather than naming a single source
+func isSourcePattern(value string) bool {
+	return strings.HasPrefix(value, sourceRegexPrefix) || strings.ContainsAny(value, "*?[")
+}
+
+// matchSourceNames returns the names matching a source pattern, in order.
+// A regular expression must match the whole name.
+func matchSourceNames(pattern string, names []string) ([]string, error) {
+	var match func(string) bool
+	if expr, ok := strings.CutPrefix(pattern, sourceRegexPrefix); ok {
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid source pattern %q: %w", pattern, err)
+		}
+		match = re.MatchString
+	} else {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid source pattern %q: %w", pattern, err)
+		}
+		match = func(name string) bool {
+			ok, _ := path.Match(pattern, name)
+			return ok
+		}
+	}
+
+	var matched []string
+	for _, name := range names {
+		if match(name) {
+			matched = append(matched, name)
+		}
+	}
+	return matched, nil
+}
+
+// confirmSourceSelection lists the sources a pattern selected with the number
+// of installed files each one affects, then asks to proceed unless yes is set
+// or the run is a dry run
+func (sc *SharedContext) confirmSourceSelection(pattern string, names []string, yes bool) error {
+	installations, err := sc.Tracker().List()
+	if err != nil {
+		return fmt.Errorf("failed to read tracking data: %w", err)
+	}
+
+	fmt.Printf("Pattern '%s' matches %d sources:\n", pattern, len(names))
+	total := 0
+	for _, name := range names {
+		files := trackedFileCount(installations, name)
+		total += files
+		fmt.Printf("  %s (%d files)\n", name, files)
+	}
+	fmt.Printf("Total files affected: %d\n", total)
+	sc.Summarize("matched", strings.Join(names, ","))
+	sc.Summarize("files_affected", total)
+
+	if yes || sc.Options.DryRun {
+		return nil
+	}
+	if quietMode {
+		return fmt.Errorf("pattern '%s' matches %d sources; pass --yes to proceed without confirmation", pattern, len(names))
+	}
+	if !Confirm("Proceed?") {
+		return errSelectionCancelled
+	}
+	return nil
+}
+
+// refreshTracking records the new checksums of tracked files edited in place,
+// so verify does not report them as modified outside agent-manager
+func refreshTracking(sharedCtx *SharedContext, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	if err := sharedCtx.Tracker().RefreshFiles(paths); err != nil {
+		PrintWarning("Failed to update tracking data: %v", err)
+	}
+}
+
+// trackedFileCount returns the number of files installed by a source, or by
+// a SOURCE/CATEGORY, not counting pre-existing files kept on uninstall
+func trackedFileCount(installations map[string]*tracker.Installation, name string) int {
+	if installation, ok := installations[name]; ok {
+		count := 0
+		for _, file := range installation.Files {
+			if !file.WasPreExisting {
+				count++
+			}
+		}
+		return count
+	}
+	parent, category := tracker.SplitSubSource(name)
+	if installation, ok := installations[parent]; ok && category != "" {
+		if installed, ok := installation.Categories[category]; ok {
+			return len(installed.Files)
+		}
+	}
+	return 0
+}
+
+// AddYesFlag adds the --yes flag skipping the confirmation of sources
+// selected by pattern
+func AddYesFlag(cmd *cobra.Command, yes *bool) {
+	cmd.Flags().BoolVarP(yes, "yes", "y", false, "proceed without confirming the sources a --source pattern matches")
+}
+
+// AddFailFastFlag adds the --fail-fast flag stopping a run over several
+// sources at the first one that fails
+func AddFailFastFlag(cmd *cobra.Command, failFast *bool) {
+	cmd.Flags().BoolVar(failFast, "fail-fast", false, "stop at the first failing source instead of continuing with the rest")
+}
+
+// silenceExitError keeps cobra from printing the usage and the error for
+// errors carrying an exit code, which main reports
+func silenceExitError(cmd *cobra.Command, err error) error {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+	}
+	return err
+}
+
 // GetAgentsDirectory returns the base directory where agents are installed
 func (sc *SharedContext) GetAgentsDirectory() string {
 	if sc.Config == nil {
@@ -160,48 +716,124 @@ func (sc *SharedContext) GetAgentsDirectory() string {
 
 // AddPersistentFlags adds common flags to a command
 func AddPersistentFlags(cmd *cobra.Command, opts *SharedOptions) {
-	cmd.PersistentFlags().StringVarP(&opts.ConfigFile, "config", "c", "agents-config.yaml", "configuration file")
+	cmd.PersistentFlags().StringVarP(&opts.ConfigFile, "config", "c", config.DefaultConfigFile, "configuration file")
+	cmd.PersistentFlags().BoolVar(&opts.NoAutoDiscover, "no-auto-discover", false, "use agents-config.yaml in the working directory instead of finding the project or user configuration")
+	cmd.PersistentFlags().StringVar(&opts.BaseDir, "base-dir", "", "agents directory to scan instead of settings.base_dir (query, show, stats, validate, index and cache)")
+	_ = cmd.PersistentFlags().SetAnnotation("base-dir", pathAnnotation, []string{pathFile})
 	cmd.PersistentFlags().BoolVarP(&opts.Verbose, "verbose", "v", false, "verbose output")
 	cmd.PersistentFlags().BoolVar(&opts.DryRun, "dry-run", false, "simulate actions without making changes")
+	cmd.PersistentFlags().BoolVar(&opts.Apply, "apply", false, "make changes when settings.default_dry_run or a source's dry_run is enabled")
+	cmd.MarkFlagsMutuallyExclusive("dry-run", "apply")
 	cmd.PersistentFlags().BoolVar(&opts.NoColor, "no-color", false, "disable colored output")
 	cmd.PersistentFlags().BoolVar(&opts.NoProgress, "no-progress", false, "disable progress indicators")
+	cmd.PersistentFlags().BoolVar(&opts.Plain, "plain", false, "plain output without colors, symbols or progress indicators")
+	cmd.PersistentFlags().BoolVar(&opts.Absolute, "absolute", false, "print exact timestamps instead of relative times such as \"3 days ago\"")
+	cmd.PersistentFlags().BoolVarP(&opts.Quiet, "quiet", "q", false, "print only a one-line key=value summary per operation; errors go to stderr")
+	cmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
+	cmd.PersistentFlags().StringVar(&opts.ProgressFormat, "progress-format", progress.FormatText, "progress output: text bars or json events for tools wrapping agent-manager")
+	cmd.PersistentFlags().IntVar(&opts.ProgressFD, "progress-fd", 2, "file descriptor json progress events are written to (default stderr)")
 }
 
-// SetupColors configures color output based on options
-func SetupColors(noColor bool) {
-	if noColor {
+// AddTimeoutFlag adds the --timeout flag shared by commands that talk to sources
+func AddTimeoutFlag(cmd *cobra.Command, timeout *time.Duration) {
+	cmd.Flags().DurationVar(timeout, "timeout", 0, "abort the operation after this duration (default: settings.timeout)")
+}
+
+// SetupColors configures color and symbol output based on options and the
+// NO_COLOR / CLICOLOR_FORCE environment conventions
+func SetupColors(opts *SharedOptions) {
+	util.SetPlainOutput(opts.Plain)
+
+	switch {
+	case util.ColorDisabled(opts.NoColor):
 		color.NoColor = true
+	case util.ColorForced():
+		color.NoColor = false
 	}
 }
 
 // SetupProgress initializes the progress manager with options
 func SetupProgress(opts *SharedOptions) {
 	progress.Initialize(progress.Options{
-		Enabled: !opts.NoProgress,
+		Enabled: !opts.NoProgress && !opts.Plain,
 		Verbose: opts.Verbose,
 		DryRun:  opts.DryRun,
-		NoColor: opts.NoColor,
+		NoColor: color.NoColor,
+		Format:  opts.ProgressFormat,
+		Events:  opts.progressEvents,
 	})
 }
 
+// openProgressEvents checks --progress-format and, for json, opens the
+// descriptor given with --progress-fd that events are written to
+func openProgressEvents(opts *SharedOptions) error {
+	switch opts.ProgressFormat {
+	case "", progress.FormatText:
+		return nil
+	case progress.FormatJSON:
+	default:
+		return fmt.Errorf("invalid --progress-format %q: use text or json", opts.ProgressFormat)
+	}
+
+	// Events never go to stdout, which keeps the human-readable output
+	switch {
+	case opts.ProgressFD < 2:
+		return fmt.Errorf("invalid --progress-fd %d: use 2 (stderr) or a descriptor opened for writing", opts.ProgressFD)
+	case opts.ProgressFD == 2:
+		opts.progressEvents = os.Stderr
+		return nil
+	}
+	file := os.NewFile(uintptr(opts.ProgressFD), fmt.Sprintf("fd%d", opts.ProgressFD))
+	if _, err := file.Stat(); err != nil {
+		return fmt.Errorf("invalid --progress-fd %d: %w", opts.ProgressFD, err)
+	}
+	opts.progressEvents = file
+	return nil
+}
+
+// Confirm asks a yes/no question on stdin and returns true only for an explicit yes
+func Confirm(question string) bool {
+	if quietMode {
+		return false
+	}
+	fmt.Printf("%s [y/N]: ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
 // PrintSuccess prints a success message with consistent formatting
 func PrintSuccess(format string, args ...interface{}) {
-	color.Green("✓ "+format+"\n", args...)
+	color.Green(util.Symbol("✓")+" "+format+"\n", args...)
 }
 
 // PrintWarning prints a warning message with consistent formatting
 func PrintWarning(format string, args ...interface{}) {
-	color.Yellow("⚠ "+format+"\n", args...)
+	color.Yellow(util.Symbol("⚠")+" "+format+"\n", args...)
 }
 
 // PrintError prints an error message with consistent formatting
 func PrintError(format string, args ...interface{}) {
-	color.Red("✗ "+format+"\n", args...)
+	if quietMode {
+		_, _ = fmt.Fprintf(os.Stderr, "error: "+format+"\n", args...)
+		return
+	}
+	color.Red(util.Symbol("✗")+" "+format+"\n", args...)
 }
 
 // PrintInfo prints an info message with consistent formatting
 func PrintInfo(format string, args ...interface{}) {
-	color.Cyan("ℹ "+format+"\n", args...)
+	color.Cyan(util.Symbol("ℹ")+" "+format+"\n", args...)
 }
 
 // Command interface for structured command implementations
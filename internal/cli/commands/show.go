@@ -1,17 +1,29 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/engine"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 	"github.com/spf13/cobra"
 )
 
 // ShowCommand implements the show command functionality
 type ShowCommand struct {
-	agentName string
+	agentName   string
+	output      string
+	template    string
+	fresh       bool
+	raw         bool
+	export      bool
+	yes         bool
+	interactive bool
 }
 
 // NewShowCommand creates a new show command instance
@@ -39,14 +51,55 @@ func (c *ShowCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
 Examples:
   agent-manager show go-specialist        # Show agent by exact name
   agent-manager show go                   # Show agent by fuzzy name matching
-  agent-manager show go-specialist.md     # Show agent by filename`,
-		Args: cobra.ExactArgs(1),
+  agent-manager show go-specialist.md     # Show agent by filename
+  agent-manager show team/helper.md       # Pick one of several helper.md files by path
+  agent-manager show go --template '{{.FilePath}}'  # Custom template
+  agent-manager show go-specialist --fresh  # Re-read the file, flagging a stale index
+  agent-manager show go-specialist --raw    # Print the agent file as on disk
+  agent-manager show go-specialist --export > go-specialist.md  # Canonical installable form
+  agent-manager show --interactive          # Pick the agent in a fuzzy finder
+  agent-manager show go -i                  # Start the finder filtered by "go"
+
+When the name matches several agents, such as agents of the same name in
+different namespaces or several close fuzzy matches, a finder listing them with
+their descriptions and a prompt preview lets you pick one. Type to narrow the
+list, use the arrow keys to move and Enter to select. Without a terminal the
+best match is shown, unless --interactive asks for a numbered list on stdin.
+A file name shared by different agents in several directories is an error
+listing them; pass a path suffix such as team/helper.md to pick one.
+
+With --fresh or --raw the saved index is used as is, and the agent file is
+re-read from disk. When the file changed since indexing, or is missing from the
+index, the difference is reported and you are offered to refresh the entry.
+
+--export prints the agent file in its canonical installable form, ready to be
+committed to a source repository: UTF-8, frontmatter keys in the order name,
+description, tools, model, color, version, license and then the remaining keys
+sorted, and the prompt exactly as written. Exporting an agent installed from an
+exported file gives the same bytes again.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c.agentName = args[0]
+			if len(args) == 0 && !c.interactive {
+				return fmt.Errorf("requires an agent name or --interactive")
+			}
+			if len(args) == 1 {
+				c.agentName = args[0]
+			}
+			if c.export && (c.raw || c.output == outputTemplate || c.template != "") {
+				return fmt.Errorf("--export cannot be combined with --raw or template output")
+			}
 			return c.Execute(sharedCtx)
 		},
 	}
 
+	cmd.Flags().StringVarP(&c.output, "output", "o", "text", "output format (text, template)")
+	addTemplateFlag(cmd, &c.template)
+	cmd.Flags().BoolVar(&c.fresh, "fresh", false, "re-read the agent file from disk and flag a stale index entry")
+	cmd.Flags().BoolVar(&c.raw, "raw", false, "print the raw agent file content from disk (implies --fresh)")
+	cmd.Flags().BoolVar(&c.export, "export", false, "print the agent file in canonical installable form (implies --fresh)")
+	cmd.Flags().BoolVarP(&c.yes, "yes", "y", false, "refresh a stale index entry without prompting")
+	cmd.Flags().BoolVarP(&c.interactive, "interactive", "i", false, "pick the agent in an interactive fuzzy finder")
+
 	return cmd
 }
 
@@ -57,28 +110,189 @@ func (c *ShowCommand) Execute(sharedCtx *SharedContext) error {
 		return fmt.Errorf("configuration error: %w", err)
 	}
 
+	if c.fresh || c.raw || c.export {
+		return c.executeFromDisk(sharedCtx)
+	}
+
 	// Create query engine
 	queryEngine, err := sharedCtx.CreateQueryEngine()
 	if err != nil {
 		return err
 	}
 
-	// Find agent with progress indication
-	var agent *parser.AgentSpec
-	err = sharedCtx.PM.WithSpinner(fmt.Sprintf("Finding agent '%s'", c.agentName), func() error {
-		var showErr error
-		agent, showErr = queryEngine.ShowAgent(c.agentName)
-		return showErr
-	})
+	agent, err := resolveAgent(queryEngine, c.agentName, c.interactive)
+	if errors.Is(err, errSelectionCancelled) {
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("failed to find agent: %w", err)
 	}
 
+	if c.output == outputTemplate || c.template != "" {
+		return renderAgentsTemplate(sharedCtx, c.template, []*parser.AgentSpec{agent})
+	}
+
 	// Display agent details
 	c.displayAgentDetails(agent, sharedCtx)
 	return nil
 }
 
+// executeFromDisk looks the agent up in the saved index, falling back to its
+// file on disk, and shows the agent as currently on disk
+func (c *ShowCommand) executeFromDisk(sharedCtx *SharedContext) error {
+	queryEngine, err := sharedCtx.OpenQueryEngine()
+	if err != nil {
+		return err
+	}
+
+	var agent *parser.AgentSpec
+	indexed, findErr := resolveAgent(queryEngine, c.agentName, c.interactive)
+	if errors.Is(findErr, errSelectionCancelled) {
+		return findErr
+	}
+	if findErr == nil {
+		fresh, stale, err := queryEngine.ReloadAgent(indexed)
+		if err != nil {
+			return err
+		}
+		if fresh == nil {
+			c.warn("Indexed agent file no longer exists: %s", indexed.FilePath)
+			c.offerRefresh(queryEngine, indexed.FilePath, nil)
+			return fmt.Errorf("agent file not found: %s", indexed.FilePath)
+		}
+		if stale {
+			c.warn("Index entry for %s is stale: file modified %s, indexed %s",
+				indexed.FileName, fresh.ModTime.Format(util.TimeLayout), indexed.ModTime.Format(util.TimeLayout))
+			for _, change := range indexChanges(indexed, fresh) {
+				c.warn("  %s", change)
+			}
+			c.offerRefresh(queryEngine, indexed.FilePath, fresh)
+		}
+		agent = fresh
+	} else {
+		path := findAgentFile(sharedCtx.GetAgentsDirectory(), c.agentName, sharedCtx.Config.Settings.Query.Index.Extensions)
+		if path == "" {
+			return fmt.Errorf("failed to find agent: %w", findErr)
+		}
+		agentParser := parser.NewParser()
+		agentParser.Extensions = sharedCtx.Config.Settings.Query.Index.Extensions
+		agent, err = agentParser.ParseFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if rel, err := filepath.Rel(sharedCtx.GetAgentsDirectory(), path); err == nil {
+			agent.Namespace = parser.NamespaceOf(rel)
+		}
+		c.warn("Agent %s is not in the index", path)
+		c.offerRefresh(queryEngine, path, agent)
+	}
+
+	if c.raw {
+		content, err := os.ReadFile(agent.FilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read agent file: %w", err)
+		}
+		fmt.Print(string(content))
+		return nil
+	}
+
+	if c.export {
+		content, err := os.ReadFile(agent.FilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read agent file: %w", err)
+		}
+		canonical, err := parser.Canonical(content)
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", agent.FilePath, err)
+		}
+		fmt.Print(string(canonical))
+		return nil
+	}
+
+	if c.output == outputTemplate || c.template != "" {
+		return renderAgentsTemplate(sharedCtx, c.template, []*parser.AgentSpec{agent})
+	}
+
+	c.displayAgentDetails(agent, sharedCtx)
+	return nil
+}
+
+// offerRefresh updates the index entry for path with agent, or removes it when
+// agent is nil, if --yes is set or the user confirms. Scripted output (raw,
+// export or template) is never interrupted by a prompt.
+func (c *ShowCommand) offerRefresh(queryEngine *engine.Engine, path string, agent *parser.AgentSpec) {
+	if !c.yes {
+		if c.fileOutput() || c.output == outputTemplate || c.template != "" || !Confirm("Refresh the index entry?") {
+			return
+		}
+	}
+	if err := queryEngine.RefreshAgent(path, agent); err != nil {
+		c.warn("Failed to refresh index: %v", err)
+		return
+	}
+	if !c.fileOutput() {
+		PrintSuccess("Index entry refreshed")
+	}
+}
+
+// fileOutput reports whether the agent file itself is written to stdout
+func (c *ShowCommand) fileOutput() bool {
+	return c.raw || c.export
+}
+
+// warn prints a warning, on stderr when the agent file is written to stdout
+func (c *ShowCommand) warn(format string, args ...interface{}) {
+	if c.fileOutput() {
+		fmt.Fprintf(os.Stderr, "Warning: "+format+"\n", args...)
+		return
+	}
+	PrintWarning(format, args...)
+}
+
+// indexChanges describes the fields that differ between an indexed agent and its file
+func indexChanges(indexed, fresh *parser.AgentSpec) []string {
+	var changes []string
+	if indexed.Name != fresh.Name {
+		changes = append(changes, fmt.Sprintf("name: %q -> %q", indexed.Name, fresh.Name))
+	}
+	if indexed.Description != fresh.Description {
+		changes = append(changes, "description changed")
+	}
+	if strings.Join(indexed.GetToolsAsSlice(), ",") != strings.Join(fresh.GetToolsAsSlice(), ",") ||
+		indexed.ToolsInherited != fresh.ToolsInherited {
+		changes = append(changes, "tools changed")
+	}
+	if indexed.Prompt != fresh.Prompt {
+		changes = append(changes, "prompt changed")
+	}
+	if indexed.FileSize != fresh.FileSize {
+		changes = append(changes, fmt.Sprintf("size: %d -> %d bytes", indexed.FileSize, fresh.FileSize))
+	}
+	return changes
+}
+
+// findAgentFile returns the path of the agent file named name in dir, with or
+// without an agent file extension, or "" when there is none
+func findAgentFile(dir, name string, extensions []string) string {
+	if len(extensions) == 0 {
+		extensions = parser.DefaultExtensions
+	}
+	candidates := []string{name}
+	if !parser.IsAgentFile(name, extensions) {
+		candidates = nil
+		for _, ext := range extensions {
+			candidates = append(candidates, name+ext)
+		}
+	}
+	for _, candidate := range candidates {
+		path := filepath.Join(dir, filepath.FromSlash(candidate))
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
 // displayAgentDetails displays comprehensive agent information
 func (c *ShowCommand) displayAgentDetails(agent *parser.AgentSpec, sharedCtx *SharedContext) {
 	if !sharedCtx.Options.Verbose && !sharedCtx.Options.NoProgress {
@@ -89,6 +303,9 @@ func (c *ShowCommand) displayAgentDetails(agent *parser.AgentSpec, sharedCtx *Sh
 	fmt.Println(strings.Repeat("=", 50))
 
 	fmt.Printf("Name: %s\n", color.CyanString(agent.Name))
+	if agent.Namespace != "" {
+		fmt.Printf("Namespace: %s\n", agent.Namespace)
+	}
 	fmt.Printf("File: %s\n", agent.FileName)
 	fmt.Printf("Path: %s\n", agent.FilePath)
 
@@ -100,12 +317,24 @@ func (c *ShowCommand) displayAgentDetails(agent *parser.AgentSpec, sharedCtx *Sh
 		fmt.Printf("Source: %s\n", agent.Source)
 	}
 
+	if agent.SourceCommit != "" {
+		fmt.Printf("Source Commit: %s\n", agent.SourceCommit)
+	}
+
+	if agent.Version != "" {
+		fmt.Printf("Version: %s\n", agent.Version)
+	}
+
+	if agent.License != "" {
+		fmt.Printf("License: %s\n", agent.License)
+	}
+
 	if !agent.InstalledAt.IsZero() {
-		fmt.Printf("Installed: %s\n", agent.InstalledAt.Format("2006-01-02 15:04:05"))
+		fmt.Printf("Installed: %s\n", util.FormatTime(agent.InstalledAt))
 	}
 
 	fmt.Printf("File Size: %d bytes\n", agent.FileSize)
-	fmt.Printf("Modified: %s\n", agent.ModTime.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Modified: %s\n", util.FormatTime(agent.ModTime))
 
 	// Tools section
 	fmt.Printf("\nTools: ")
@@ -2,13 +2,16 @@ package commands
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/metrics"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/stats"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 	"github.com/spf13/cobra"
 )
 
@@ -17,7 +20,11 @@ type StatsCommand struct {
 	detailed   bool
 	validation bool
 	tools      bool
+	bySource   bool
 	toolsLimit int
+	noCache    bool
+	workers    int
+	self       bool
 }
 
 // NewStatsCommand creates a new stats command instance
@@ -48,7 +55,11 @@ Examples:
   agent-manager stats                # Show basic statistics
   agent-manager stats --detailed     # Show detailed statistics by source
   agent-manager stats --validation   # Show validation report
-  agent-manager stats --tools        # Show top tools usage`,
+  agent-manager stats --tools        # Show top tools usage
+  agent-manager stats --by-source    # Show agents, commit, update time and disk usage per installed source
+  agent-manager stats --no-cache     # Recompute everything, ignoring cached results
+  agent-manager stats --validation --workers 4  # Validate on at most 4 workers
+  agent-manager stats --self         # Show this tool's own recorded runs, durations and errors`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return c.Execute(sharedCtx)
 		},
@@ -57,7 +68,11 @@ Examples:
 	cmd.Flags().BoolVar(&c.detailed, "detailed", false, "show detailed statistics by source")
 	cmd.Flags().BoolVar(&c.validation, "validation", false, "show validation report")
 	cmd.Flags().BoolVar(&c.tools, "tools", false, "show top tools usage")
+	cmd.Flags().BoolVar(&c.bySource, "by-source", false, "show per-source statistics joined with installation tracking")
 	cmd.Flags().IntVar(&c.toolsLimit, "tools-limit", 10, "limit number of tools shown")
+	cmd.Flags().BoolVar(&c.noCache, "no-cache", false, "ignore cached statistics and force a full pass")
+	cmd.Flags().IntVar(&c.workers, "workers", 0, "agents validated in parallel (0 uses one worker per CPU)")
+	cmd.Flags().BoolVar(&c.self, "self", false, "show runs, durations and error categories recorded with settings.metrics.usage")
 
 	return cmd
 }
@@ -69,37 +84,31 @@ func (c *StatsCommand) Execute(sharedCtx *SharedContext) error {
 		return fmt.Errorf("configuration error: %w", err)
 	}
 
+	if c.self {
+		return c.displayUsage(sharedCtx)
+	}
+
 	// Create query engine and get agents
 	queryEngine, err := sharedCtx.CreateQueryEngine()
 	if err != nil {
 		return err
 	}
 
-	// Get all agents for statistics
+	// Files that failed to parse are recorded in the index, so the true total
+	// is known without rescanning the agents directory
 	var agents []*parser.AgentSpec
-	var totalFiles int
+	var broken []parser.ParseFailure
 	err = sharedCtx.PM.WithSpinner("Calculating statistics", func() error {
 		agents = queryEngine.GetAllAgents()
-
-		// Count all .md files to get true total
-		agentsDir := sharedCtx.GetAgentsDirectory()
-		err := filepath.Walk(agentsDir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err // Propagate the error
-			}
-			if strings.HasSuffix(path, ".md") && !info.IsDir() {
-				totalFiles++
-			}
-			return nil
-		})
-		if err != nil {
-			return err
-		}
+		broken = queryEngine.BrokenAgents()
 		return nil
 	})
 	if err != nil {
 		return err
 	}
+	totalFiles := len(agents) + len(broken)
+	sharedCtx.Summarize("agents", totalFiles)
+	sharedCtx.Summarize("broken", len(broken))
 
 	if totalFiles == 0 && len(agents) == 0 {
 		PrintWarning("No agents found for statistics")
@@ -108,18 +117,60 @@ func (c *StatsCommand) Execute(sharedCtx *SharedContext) error {
 
 	// Create stats calculator with total file count
 	calculator := stats.NewCalculatorWithTotal(agents, totalFiles)
+	toolValidator, err := (&ValidateCommand{}).toolValidator(sharedCtx.Config.Settings.Query.Validation)
+	if err != nil {
+		return err
+	}
+	calculator.SetToolValidator(toolValidator)
+
+	// Reuse cached results for unchanged agents unless a full pass is requested
+	statsCache := stats.NewCache(filepath.Join(sharedCtx.Config.Settings.BaseDir, ".agent-stats"))
+	if !c.noCache {
+		result := statsCache.Prepare(calculator)
+		if sharedCtx.Options.Verbose {
+			if result.Hit {
+				PrintInfo("Using cached statistics (generation %s)", result.Generation)
+			} else {
+				PrintInfo("Recomputing statistics for %d of %d agents", result.Recomputed, result.Reused+result.Recomputed)
+			}
+		}
+	}
+
+	// Validate new and changed agents in parallel before any report reads
+	// the results; reports aggregate them in agent order
+	if pending := len(calculator.Unvalidated()); pending > 0 {
+		err := sharedCtx.PM.WithProgress(fmt.Sprintf("Validating %d agents", pending), pending, func(update func(int)) error {
+			return calculator.ValidateAll(sharedCtx.Context(), c.workers, update)
+		})
+		if err != nil {
+			return fmt.Errorf("validation aborted: %w", err)
+		}
+	}
+	defer func() {
+		if err := statsCache.Save(calculator); err != nil && sharedCtx.Options.Verbose {
+			PrintWarning("Failed to save stats cache: %v", err)
+		}
+	}()
 
 	// Display appropriate statistics based on flags
 	if c.validation {
 		c.displayValidationStats(calculator, sharedCtx)
 	} else if c.tools {
 		c.displayToolsStats(calculator, sharedCtx)
+	} else if c.bySource {
+		if err := c.displaySourceStats(calculator, broken, sharedCtx); err != nil {
+			return err
+		}
 	} else if c.detailed {
 		c.displayDetailedStats(calculator, sharedCtx)
 	} else {
 		c.displayBasicStats(calculator, sharedCtx)
 	}
 
+	if len(broken) > 0 {
+		PrintWarning("\nWarning: %d agent files failed to parse; run 'agent-manager list --broken' for details", len(broken))
+	}
+
 	return nil
 }
 
@@ -147,8 +198,33 @@ func (c *StatsCommand) displayBasicStats(calculator *stats.Calculator, sharedCtx
 
 	if len(statistics.BySource) > 0 {
 		fmt.Printf("\nBy Source:\n")
-		for source, count := range statistics.BySource {
-			fmt.Printf("  %s: %d\n", source, count)
+		for _, source := range sortedKeys(statistics.BySource) {
+			fmt.Printf("  %s: %d\n", source, statistics.BySource[source])
+		}
+	}
+
+	if len(statistics.ByLicense) > 0 {
+		licenses := make([]string, 0, len(statistics.ByLicense))
+		for license := range statistics.ByLicense {
+			licenses = append(licenses, license)
+		}
+		sort.Strings(licenses)
+		fmt.Printf("\nBy License:\n")
+		for _, license := range licenses {
+			fmt.Printf("  %s: %d\n", license, statistics.ByLicense[license])
+		}
+	}
+
+	if largest := calculator.LargestAgents(5); len(largest) > 0 {
+		maxBytes := sharedCtx.Config.Settings.Limits.MaxAgentFileBytes()
+		fmt.Printf("\nLargest Agents:\n")
+		for _, agent := range largest {
+			line := fmt.Sprintf("  %s: %d KB", agent.Name, (agent.FileSize+1023)/1024)
+			if maxBytes > 0 && agent.FileSize > maxBytes {
+				color.Yellow("%s (exceeds max_agent_file_kb)\n", line)
+			} else {
+				fmt.Println(line)
+			}
 		}
 	}
 
@@ -177,7 +253,8 @@ func (c *StatsCommand) displayDetailedStats(calculator *stats.Calculator, shared
 	sourceStats := calculator.CalculateSourceStats()
 	if len(sourceStats) > 1 {
 		fmt.Printf("\nPer-Source Statistics:\n")
-		for source, stats := range sourceStats {
+		for _, source := range sortedKeys(sourceStats) {
+			stats := sourceStats[source]
 			fmt.Printf("  %s:\n", source)
 			fmt.Printf("    Agents: %d\n", stats.TotalAgents)
 			fmt.Printf("    Coverage: %.1f%%\n", stats.Coverage.AverageCoverage)
@@ -204,19 +281,36 @@ func (c *StatsCommand) displayValidationStats(calculator *stats.Calculator, shar
 
 	if errors, ok := report["common_errors"].(map[string]int); ok && len(errors) > 0 {
 		fmt.Printf("\nCommon Errors:\n")
-		for err, count := range errors {
-			fmt.Printf("  %s: %d\n", err, count)
+		for _, err := range byCount(errors) {
+			fmt.Printf("  %s: %d\n", err, errors[err])
 		}
 	}
 
 	if warnings, ok := report["common_warnings"].(map[string]int); ok && len(warnings) > 0 {
 		fmt.Printf("\nCommon Warnings:\n")
-		for warning, count := range warnings {
-			fmt.Printf("  %s: %d\n", warning, count)
+		for _, warning := range byCount(warnings) {
+			fmt.Printf("  %s: %d\n", warning, warnings[warning])
 		}
 	}
 }
 
+// sortedKeys returns the keys of m in order, so reports are reproducible
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// byCount returns the keys of counts, most frequent first and ties in order
+func byCount(counts map[string]int) []string {
+	keys := sortedKeys(counts)
+	sort.SliceStable(keys, func(i, j int) bool { return counts[keys[i]] > counts[keys[j]] })
+	return keys
+}
+
 // displayToolsStats shows tools usage statistics
 func (c *StatsCommand) displayToolsStats(calculator *stats.Calculator, sharedCtx *SharedContext) {
 	if !sharedCtx.Options.Verbose && !sharedCtx.Options.NoProgress {
@@ -240,3 +334,98 @@ func (c *StatsCommand) displayToolsStats(calculator *stats.Calculator, sharedCtx
 		}
 	}
 }
+
+// displaySourceStats shows per-source statistics joined with the installations
+// recorded in the tracking file
+func (c *StatsCommand) displaySourceStats(calculator *stats.Calculator, broken []parser.ParseFailure, sharedCtx *SharedContext) error {
+	installations, err := sharedCtx.Tracker().List()
+	if err != nil {
+		return fmt.Errorf("failed to read installed sources: %w", err)
+	}
+
+	if !sharedCtx.Options.Verbose && !sharedCtx.Options.NoProgress {
+		fmt.Println() // Add spacing after spinner
+	}
+
+	color.Blue("Per-Source Statistics\n")
+	fmt.Println(strings.Repeat("=", 40))
+
+	for _, source := range calculator.CalculateTrackedSourceStats(installations, broken) {
+		fmt.Printf("%s:\n", source.Source)
+		line := fmt.Sprintf("  Agents: %d", source.Agents)
+		if source.Invalid > 0 || source.Broken > 0 {
+			line += fmt.Sprintf(" (%d invalid, %d failed to parse)", source.Invalid, source.Broken)
+			color.Yellow("%s\n", line)
+		} else {
+			fmt.Println(line)
+		}
+		if source.Missing > 0 {
+			color.Yellow("  Files: %d (%d missing)\n", source.Files, source.Missing)
+		} else {
+			fmt.Printf("  Files: %d\n", source.Files)
+		}
+		fmt.Printf("  Disk Usage: %s\n", formatBytes(source.DiskBytes))
+		if source.Commit != "" {
+			fmt.Printf("  Commit: %s\n", util.ShortCommit(source.Commit))
+		}
+		if !source.Updated.IsZero() {
+			fmt.Printf("  Updated: %s\n", util.FormatTime(source.Updated))
+		}
+	}
+	return nil
+}
+
+// displayUsage shows the command runs recorded in the local usage file,
+// slowest commands first
+func (c *StatsCommand) displayUsage(sharedCtx *SharedContext) error {
+	usage, err := metrics.LoadUsage(usagePath(sharedCtx))
+	if err != nil {
+		return err
+	}
+	sharedCtx.Summarize("commands", len(usage.Commands))
+
+	if len(usage.Commands) == 0 {
+		if !sharedCtx.Config.Settings.Metrics.Usage {
+			PrintInfo("Usage recording is off; set settings.metrics.usage: true to record runs locally")
+		} else {
+			PrintInfo("No runs recorded yet")
+		}
+		return nil
+	}
+
+	color.Blue("Usage Statistics\n")
+	fmt.Println(strings.Repeat("=", 40))
+	fmt.Printf("Recorded Since: %s\n", util.FormatTime(usage.Since))
+	if !sharedCtx.Config.Settings.Metrics.Usage {
+		PrintWarning("Usage recording is off; these runs were recorded earlier")
+	}
+
+	fmt.Printf("\n%-14s %6s %6s %10s %10s\n", "Command", "Runs", "Errors", "Average", "Max")
+	for _, name := range usage.Slowest() {
+		stats := usage.Commands[name]
+		line := fmt.Sprintf("%-14s %6d %6d %10s %10s", name, stats.Runs, stats.Errors,
+			stats.Average().Round(time.Millisecond), stats.Max.Round(time.Millisecond))
+		if stats.Errors > 0 {
+			color.Yellow("%s\n", line)
+		} else {
+			fmt.Println(line)
+		}
+	}
+
+	failing := false
+	for _, name := range usage.Slowest() {
+		categories := usage.Commands[name].ErrorCategories
+		if len(categories) == 0 {
+			continue
+		}
+		if !failing {
+			fmt.Printf("\nErrors by Category:\n")
+			failing = true
+		}
+		fmt.Printf("  %s:\n", name)
+		for _, category := range byCount(categories) {
+			fmt.Printf("    %s: %d\n", category, categories[category])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"golang.org/x/term"
+)
+
+// stdinAgentPath is the file path reported for an agent read from stdin
+const stdinAgentPath = "<stdin>"
+
+// maxStdinAgentBytes bounds an agent document read from stdin
+const maxStdinAgentBytes = 16 << 20
+
+// readStdinAgent reads a single agent document from r and parses it in the
+// configured parser mode. The returned spec reports stdinAgentPath as its
+// file path.
+func readStdinAgent(sharedCtx *SharedContext, r io.Reader) ([]byte, *parser.AgentSpec, error) {
+	if r == os.Stdin && term.IsTerminal(int(os.Stdin.Fd())) {
+		PrintInfo("Reading an agent document from stdin; end it with Ctrl-D")
+	}
+
+	content, err := io.ReadAll(io.LimitReader(r, maxStdinAgentBytes+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read agent from stdin: %w", err)
+	}
+	if len(content) > maxStdinAgentBytes {
+		return nil, nil, fmt.Errorf("agent on stdin exceeds %d bytes", maxStdinAgentBytes)
+	}
+	if len(content) == 0 {
+		return nil, nil, fmt.Errorf("no agent document on stdin")
+	}
+
+	agentParser := &parser.Parser{Mode: sharedCtx.Config.Settings.Query.ParserMode}
+	agent, err := agentParser.ParseContent(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse agent from stdin: %w", err)
+	}
+	agent.FilePath = stdinAgentPath
+	agent.FileName = stdinAgentPath
+	agent.FileSize = int64(len(content))
+	return content, agent, nil
+}
@@ -1,18 +1,28 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"sort"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/pacphi/claude-code-agent-manager/internal/installer"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
 	"github.com/spf13/cobra"
 )
 
 // UninstallCommand implements the uninstall command functionality
 type UninstallCommand struct {
 	sourceName  string
+	agentName   string
+	collection  string
 	all         bool
 	keepBackups bool
+	interactive bool
+	yes         bool
+	timeout     time.Duration
 }
 
 // NewUninstallCommand creates a new uninstall command instance
@@ -35,15 +45,38 @@ func (c *UninstallCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Comman
 	cmd := &cobra.Command{
 		Use:   "uninstall",
 		Short: c.Description(),
-		Long:  `Uninstall agents that were previously installed.`,
+		Long: `Uninstall agents that were previously installed.
+
+Remove a whole source with --source or every source with --all. Remove a single
+agent with --agent, leaving the rest of its source installed; the next update of
+the source installs the agent again. When the agent name is ambiguous, or with
+--interactive, a fuzzy finder lets you pick the agent. Remove every agent of a
+collection defined in the configuration with --collection.
+
+--source also takes a glob, or a regular expression prefixed with 're:', to
+remove every installed source whose name matches. The matched sources and the
+number of files they installed are listed and confirmed first; --yes skips the
+confirmation.
+
+Examples:
+  agent-manager uninstall --source team-agents       # Remove a source
+  agent-manager uninstall --source 'marketplace-*'   # Remove matching sources
+  agent-manager uninstall --agent go-specialist      # Remove one agent
+  agent-manager uninstall --agent go --interactive   # Pick the agent to remove
+  agent-manager uninstall --collection review-kit    # Remove a collection's agents`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return c.Execute(sharedCtx)
 		},
 	}
 
-	cmd.Flags().StringVarP(&c.sourceName, "source", "s", "", "uninstall specific source")
+	cmd.Flags().StringVarP(&c.sourceName, "source", "s", "", "uninstall specific source (or SOURCE/CATEGORY, or a glob or re: pattern)")
 	cmd.Flags().BoolVarP(&c.all, "all", "a", false, "uninstall all sources")
+	cmd.Flags().StringVar(&c.agentName, "agent", "", "uninstall a single agent, keeping the rest of its source")
+	cmd.Flags().StringVar(&c.collection, "collection", "", "uninstall the agents of a collection, keeping the rest of their sources")
+	cmd.Flags().BoolVarP(&c.interactive, "interactive", "i", false, "pick the agent to uninstall in an interactive fuzzy finder")
 	cmd.Flags().BoolVar(&c.keepBackups, "keep-backups", false, "keep backup files")
+	AddYesFlag(cmd, &c.yes)
+	AddTimeoutFlag(cmd, &c.timeout)
 
 	return cmd
 }
@@ -51,11 +84,18 @@ func (c *UninstallCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Comman
 // Execute runs the uninstall command logic
 func (c *UninstallCommand) Execute(sharedCtx *SharedContext) error {
 	// Validate flags
+	selectsAgent := c.agentName != "" || c.interactive
 	if c.all && c.sourceName != "" {
 		return fmt.Errorf("cannot specify both --all and --source")
 	}
-	if !c.all && c.sourceName == "" {
-		return fmt.Errorf("must specify either --all or --source")
+	if selectsAgent && (c.all || c.sourceName != "") {
+		return fmt.Errorf("cannot combine --agent or --interactive with --all or --source")
+	}
+	if c.collection != "" && (selectsAgent || c.all || c.sourceName != "") {
+		return fmt.Errorf("cannot combine --collection with --agent, --interactive, --all or --source")
+	}
+	if !c.all && c.sourceName == "" && !selectsAgent && c.collection == "" {
+		return fmt.Errorf("must specify either --all, --source, --agent or --collection")
 	}
 
 	// Load configuration
@@ -63,6 +103,9 @@ func (c *UninstallCommand) Execute(sharedCtx *SharedContext) error {
 		return fmt.Errorf("configuration error: %w", err)
 	}
 
+	cancel := sharedCtx.WithTimeout(c.timeout)
+	defer cancel()
+
 	// Create installer with keep-backups option
 	inst, err := sharedCtx.createInstallerWithOptions(installer.Options{
 		Verbose:     sharedCtx.Options.Verbose,
@@ -76,20 +119,29 @@ func (c *UninstallCommand) Execute(sharedCtx *SharedContext) error {
 	if c.all {
 		return c.uninstallAll(sharedCtx, inst)
 	}
+	if selectsAgent {
+		return c.uninstallAgent(sharedCtx, inst)
+	}
+	if c.collection != "" {
+		return c.uninstallCollection(sharedCtx, inst)
+	}
+	if isSourcePattern(c.sourceName) {
+		return c.uninstallMatching(sharedCtx, inst)
+	}
 
-	return c.uninstallSource(sharedCtx, inst)
+	return c.uninstallSource(sharedCtx, inst, c.sourceName)
 }
 
 // uninstallAll removes all installed sources
 func (c *UninstallCommand) uninstallAll(sharedCtx *SharedContext, inst *installer.Installer) error {
 	if c.shouldUseSpinner(sharedCtx) {
 		return sharedCtx.PM.WithSpinner("Uninstalling all sources", func() error {
-			return inst.UninstallAll()
+			return inst.UninstallAll(sharedCtx.Context())
 		})
 	}
 
 	PrintWarning("Uninstalling all sources...")
-	err := inst.UninstallAll()
+	err := inst.UninstallAll(sharedCtx.Context())
 	if err != nil {
 		PrintError("Failed to uninstall all sources: %v", err)
 		return err
@@ -100,21 +152,155 @@ func (c *UninstallCommand) uninstallAll(sharedCtx *SharedContext, inst *installe
 }
 
 // uninstallSource removes a specific source
-func (c *UninstallCommand) uninstallSource(sharedCtx *SharedContext, inst *installer.Installer) error {
+func (c *UninstallCommand) uninstallSource(sharedCtx *SharedContext, inst *installer.Installer, sourceName string) error {
 	if c.shouldUseSpinner(sharedCtx) {
-		return sharedCtx.PM.WithSpinner(fmt.Sprintf("Uninstalling %s", c.sourceName), func() error {
-			return inst.UninstallSource(c.sourceName)
+		return sharedCtx.PM.WithSpinner(fmt.Sprintf("Uninstalling %s", sourceName), func() error {
+			return inst.UninstallSource(sourceName)
 		})
 	}
 
-	color.Yellow("Uninstalling source: %s\n", c.sourceName)
-	err := inst.UninstallSource(c.sourceName)
+	color.Yellow("Uninstalling source: %s\n", sourceName)
+	err := inst.UninstallSource(sourceName)
+	if err != nil {
+		PrintError("Failed to uninstall %s: %v", sourceName, err)
+		return err
+	}
+
+	PrintSuccess("Successfully uninstalled %s", sourceName)
+	return nil
+}
+
+// uninstallMatching removes every installed source matching the --source
+// pattern once the selection is confirmed. A /CATEGORY suffix removes that
+// category from each matching source.
+func (c *UninstallCommand) uninstallMatching(sharedCtx *SharedContext, inst *installer.Installer) error {
+	pattern, category := tracker.SplitSubSource(c.sourceName)
+	installations, err := sharedCtx.Tracker().List()
+	if err != nil {
+		return fmt.Errorf("failed to read tracking data: %w", err)
+	}
+	installed := make([]string, 0, len(installations))
+	for name := range installations {
+		installed = append(installed, name)
+	}
+	sort.Strings(installed)
+
+	matched, err := matchSourceNames(pattern, installed)
+	if err != nil {
+		return err
+	}
+	if len(matched) == 0 {
+		return fmt.Errorf("no installed source matches '%s'", c.sourceName)
+	}
+	if category != "" {
+		for i, name := range matched {
+			matched[i] = tracker.SubSourceName(name, category)
+		}
+	}
+	if err := sharedCtx.confirmSourceSelection(c.sourceName, matched, c.yes); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, name := range matched {
+		if err := sharedCtx.Context().Err(); err != nil {
+			return fmt.Errorf("uninstall aborted: %w", err)
+		}
+		if err := c.uninstallSource(sharedCtx, inst, name); err != nil {
+			if c.shouldUseSpinner(sharedCtx) {
+				PrintError("Failed to uninstall %s: %v", name, err)
+			}
+			failed++
+		}
+	}
+	sharedCtx.Summarize("succeeded", len(matched)-failed)
+	sharedCtx.Summarize("failed", failed)
+
+	if failed > 0 {
+		return fmt.Errorf("failed to uninstall %d of %d sources", failed, len(matched))
+	}
+	return nil
+}
+
+// uninstallAgent removes a single agent, resolved like the show command does
+func (c *UninstallCommand) uninstallAgent(sharedCtx *SharedContext, inst *installer.Installer) error {
+	queryEngine, err := sharedCtx.CreateQueryEngine()
+	if err != nil {
+		return err
+	}
+
+	agent, err := resolveAgent(queryEngine, c.agentName, c.interactive)
+	if errors.Is(err, errSelectionCancelled) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find agent: %w", err)
+	}
+
+	sourceName, err := inst.UninstallAgent(agent.FilePath)
+	if err != nil {
+		PrintError("Failed to uninstall %s: %v", agent.QualifiedName(), err)
+		return err
+	}
+	sharedCtx.Summarize("agent", agent.QualifiedName())
+	sharedCtx.Summarize("source", sourceName)
+	if sharedCtx.Options.DryRun {
+		return nil
+	}
+
+	// A kept pre-existing file stays indexed
+	if _, statErr := os.Stat(agent.FilePath); os.IsNotExist(statErr) {
+		if err := queryEngine.RefreshAgent(agent.FilePath, nil); err != nil {
+			PrintWarning("Failed to update index: %v", err)
+		}
+	}
+	PrintSuccess("Uninstalled %s from source %s", agent.QualifiedName(), sourceName)
+	return nil
+}
+
+// uninstallCollection removes every installed copy of the agents of a
+// collection, leaving the rest of their sources installed
+func (c *UninstallCommand) uninstallCollection(sharedCtx *SharedContext, inst *installer.Installer) error {
+	names, err := sharedCtx.Config.Collection(c.collection)
 	if err != nil {
-		PrintError("Failed to uninstall %s: %v", c.sourceName, err)
 		return err
 	}
+	queryEngine, err := sharedCtx.CreateQueryEngine()
+	if err != nil {
+		return err
+	}
+
+	agents, missing := findNamedAgents(queryEngine.GetAllAgents(), names)
+	for _, name := range missing {
+		PrintWarning("Collection %s: %s is not installed", c.collection, name)
+	}
 
-	PrintSuccess("Successfully uninstalled %s", c.sourceName)
+	failed := 0
+	for _, agent := range agents {
+		sourceName, err := inst.UninstallAgent(agent.FilePath)
+		if err != nil {
+			PrintError("Failed to uninstall %s: %v", agent.QualifiedName(), err)
+			failed++
+			continue
+		}
+		if sharedCtx.Options.DryRun {
+			continue
+		}
+		if _, statErr := os.Stat(agent.FilePath); os.IsNotExist(statErr) {
+			if err := queryEngine.RefreshAgent(agent.FilePath, nil); err != nil {
+				PrintWarning("Failed to update index: %v", err)
+			}
+		}
+		PrintSuccess("Uninstalled %s from source %s", agent.QualifiedName(), sourceName)
+	}
+	sharedCtx.Summarize("collection", c.collection)
+	sharedCtx.Summarize("succeeded", len(agents)-failed)
+	sharedCtx.Summarize("failed", failed)
+	sharedCtx.Summarize("missing", len(missing))
+
+	if failed > 0 {
+		return fmt.Errorf("failed to uninstall %d of %d agents of collection %s", failed, len(agents), c.collection)
+	}
 	return nil
 }
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/pacphi/claude-code-agent-manager/internal/config"
 	"github.com/pacphi/claude-code-agent-manager/internal/installer"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
 	"github.com/spf13/cobra"
 )
 
@@ -12,6 +13,7 @@ import (
 type UpdateCommand struct {
 	*BaseCommand
 	sourceName string
+	category   string
 	checkOnly  bool
 }
 
@@ -37,21 +39,39 @@ func (c *UpdateCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: c.Description(),
-		Long:  `Update agents from their sources to get the latest versions.`,
+		Long: `Update agents from their sources to get the latest versions.
+
+A single marketplace category of a subagents source can be updated with
+--source SOURCE/CATEGORY.
+
+--source also takes a glob, or a regular expression prefixed with 're:',
+to update every enabled source whose name matches. The matched sources and
+the number of files they have installed are listed and confirmed first;
+--yes skips the confirmation.
+
+Examples:
+  agent-manager update --source team-agents          # Update a source
+  agent-manager update --source 'marketplace-*'      # Update matching sources
+  agent-manager update --source 're:team-(a|b)' -y   # Without confirmation`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return c.Execute(sharedCtx)
+			return silenceExitError(cmd, c.Execute(sharedCtx))
 		},
 	}
 
-	cmd.Flags().StringVarP(&c.sourceName, "source", "s", "", "update specific source only")
+	cmd.Flags().StringVarP(&c.sourceName, "source", "s", "", "update specific source (or SOURCE/CATEGORY, or a glob or re: pattern) only")
 	cmd.Flags().BoolVar(&c.checkOnly, "check-only", false, "check for updates without applying")
+	AddYesFlag(cmd, &c.yes)
+	AddFailFastFlag(cmd, &c.failFast)
+	AddTimeoutFlag(cmd, &c.timeout)
 
 	return cmd
 }
 
 // Execute runs the update command logic
 func (c *UpdateCommand) Execute(sharedCtx *SharedContext) error {
-	return c.ExecuteWithCommonPattern(sharedCtx, c.sourceName)
+	sourceName, category := tracker.SplitSubSource(c.sourceName)
+	c.category = category
+	return c.ExecuteWithCommonPattern(sharedCtx, sourceName)
 }
 
 // ExecuteOperation implements CommandExecutor interface for update operations
@@ -77,7 +97,11 @@ func (c *UpdateCommand) ExecuteOperation(ctx *SharedContext, sources []config.So
 
 	// Execute update operation on each source
 	for _, source := range sources {
-		if err := inst.UpdateSource(source.Name); err != nil {
+		name := source.Name
+		if c.category != "" {
+			name = tracker.SubSourceName(source.Name, c.category)
+		}
+		if err := inst.UpdateSource(ctx.Context(), name); err != nil {
 			return err
 		}
 	}
@@ -100,7 +124,9 @@ func (c *UpdateCommand) GetCompletionMessage() string {
 	return "Update complete"
 }
 
-// ShouldContinueOnError implements CommandExecutor interface
+// ShouldContinueOnError implements CommandExecutor interface; failing
+// sources are isolated unless --fail-fast is given or continue_on_error is
+// explicitly false
 func (c *UpdateCommand) ShouldContinueOnError(ctx *SharedContext) bool {
-	return ctx.Config.Settings.ContinueOnError
+	return !c.failFast && ctx.Config.Settings.ContinuesAfterFailure()
 }
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// pathAnnotation marks the flags and positional arguments holding paths typed
+// relative to the directory a command was started in. Configuration discovery
+// makes them absolute before running the command from the project root.
+const pathAnnotation = "agent-manager/path"
+
+// How an annotated value is a path
+const (
+	pathFile     = "file"     // always a path
+	pathExisting = "existing" // a path when it exists, otherwise a name the command looks up
+	pathTemplate = "template" // a path when prefixed with @, otherwise inline text or a name
+)
+
+// markPathFlag marks a flag of cmd as holding a path
+func markPathFlag(cmd *cobra.Command, name, mode string) {
+	_ = cmd.Flags().SetAnnotation(name, pathAnnotation, []string{mode})
+}
+
+// pathArgs annotates the positional arguments of a command that hold paths,
+// giving the mode of each argument in order; an empty mode is not a path
+func pathArgs(modes ...string) map[string]string {
+	return map[string]string{pathAnnotation: strings.Join(modes, ",")}
+}
+
+// absolutizeUserPaths makes the annotated flags and arguments of cmd absolute
+// against the working directory. args is the slice cobra passes on to RunE,
+// so its elements are rewritten in place.
+func absolutizeUserPaths(cmd *cobra.Command, args []string) {
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		modes := flag.Annotations[pathAnnotation]
+		if !flag.Changed || len(modes) == 0 {
+			return
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			values := slice.GetSlice()
+			for i, value := range values {
+				values[i] = userPath(value, modes[0])
+			}
+			_ = slice.Replace(values)
+			return
+		}
+		_ = flag.Value.Set(userPath(flag.Value.String(), modes[0]))
+	})
+
+	if spec, ok := cmd.Annotations[pathAnnotation]; ok {
+		for i, mode := range strings.Split(spec, ",") {
+			if i < len(args) && mode != "" {
+				args[i] = userPath(args[i], mode)
+			}
+		}
+	}
+}
+
+// userPath returns value made absolute according to mode
+func userPath(value, mode string) string {
+	if value == "" || value == "-" {
+		return value
+	}
+	switch mode {
+	case pathTemplate:
+		if !strings.HasPrefix(value, "@") {
+			return value
+		}
+		return "@" + userPath(strings.TrimPrefix(value, "@"), pathFile)
+	case pathExisting:
+		if _, err := os.Stat(value); err != nil {
+			return value
+		}
+	}
+	if expanded, err := util.ExpandPath(value); err == nil {
+		value = expanded
+	}
+	return absPath(value)
+}
@@ -2,23 +2,59 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/artifact"
 	"github.com/pacphi/claude-code-agent-manager/internal/config"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/engine"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/validator"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 	"github.com/spf13/cobra"
 )
 
+// ExitInvalidAgents is the exit code of validate when installed agents fail validation
+const ExitInvalidAgents = 7
+
+// ValidationError reports installed agents that failed validation, with the
+// counts printed in the validation summary
+type ValidationError struct {
+	Total         int
+	Valid         int
+	Invalid       int
+	ParseFailures int
+	Warnings      int
+}
+
+// Error returns the number of invalid agents
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("found %d invalid agents", e.Invalid)
+}
+
 // ValidateCommand implements the validate command functionality
 type ValidateCommand struct {
-	agents bool
-	query  bool
+	agents      bool
+	query       bool
+	permissions bool
+	settings    []string
+	// toolsFromClaude reads the allowed tools from the local Claude Code installation
+	toolsFromClaude bool
+	artifacts       bool
+	workers         int
+	stdin           bool
+}
+
+// agentCheck holds the problems found in one installed agent
+type agentCheck struct {
+	errors   []string
+	warnings []string
 }
 
 // NewValidateCommand creates a new validate command instance
@@ -46,7 +82,12 @@ func (c *ValidateCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command
 Examples:
   agent-manager validate             # Validate configuration only
   agent-manager validate --agents    # Also validate installed agents
-  agent-manager validate --query     # Test query functionality`,
+  agent-manager validate --query     # Test query functionality
+  agent-manager validate --permissions             # Check agent tools against Claude Code settings
+  agent-manager validate --settings ~/.claude/settings.json
+  agent-manager validate --tools-from-claude      # Use the installed Claude Code's tool list
+  agent-manager validate --artifacts              # Check installed output styles and statusline scripts
+  cat my-agent.md | agent-manager validate --stdin # Check one agent document without installing it`,
 		SilenceUsage:  true, // Don't show usage on error
 		SilenceErrors: true, // Don't print errors (we handle them ourselves)
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -56,6 +97,13 @@ Examples:
 
 	cmd.Flags().BoolVar(&c.agents, "agents", false, "also validate installed agents")
 	cmd.Flags().BoolVar(&c.query, "query", false, "test query functionality")
+	cmd.Flags().BoolVar(&c.permissions, "permissions", false, "warn when agents request tools denied or restricted in Claude Code settings (implies --agents)")
+	cmd.Flags().StringSliceVar(&c.settings, "settings", nil, "Claude Code settings files to read permissions from (implies --permissions)")
+	markPathFlag(cmd, "settings", pathFile)
+	cmd.Flags().BoolVar(&c.toolsFromClaude, "tools-from-claude", false, "check agent tools against the tools of the local Claude Code installation (implies --agents)")
+	cmd.Flags().BoolVar(&c.artifacts, "artifacts", false, "also validate installed output styles and statusline scripts")
+	cmd.Flags().IntVar(&c.workers, "workers", 0, "agents validated in parallel (0 uses one worker per CPU)")
+	cmd.Flags().BoolVar(&c.stdin, "stdin", false, "validate a single agent document read from stdin")
 
 	return cmd
 }
@@ -99,8 +147,14 @@ func (c *ValidateCommand) Execute(sharedCtx *SharedContext) error {
 	// Check for potential issues
 	c.checkForWarnings(cfg)
 
+	// Check an agent piped in instead of the installed ones
+	if c.stdin {
+		fmt.Println()
+		return c.validateStdinAgent(sharedCtx, os.Stdin)
+	}
+
 	// Enhanced validation: check agents if requested
-	if c.agents {
+	if c.agents || c.permissions || len(c.settings) > 0 || c.toolsFromClaude {
 		fmt.Println()
 		if err := c.validateInstalledAgents(sharedCtx); err != nil {
 			// Error already printed with details in validateInstalledAgents
@@ -109,6 +163,15 @@ func (c *ValidateCommand) Execute(sharedCtx *SharedContext) error {
 		PrintSuccess("All installed agents are valid")
 	}
 
+	// Check output styles and statusline scripts if requested
+	if c.artifacts {
+		fmt.Println()
+		if err := c.validateInstalledArtifacts(cfg); err != nil {
+			return err
+		}
+		PrintSuccess("All installed artifacts are valid")
+	}
+
 	// Test query functionality if requested
 	if c.query {
 		fmt.Println()
@@ -176,17 +239,65 @@ func (c *ValidateCommand) checkForWarnings(cfg *config.Config) {
 	}
 }
 
+// validateInstalledArtifacts checks the tracked files of output-style and
+// statusline sources against the rules of their kind
+func (c *ValidateCommand) validateInstalledArtifacts(cfg *config.Config) error {
+	track := tracker.New(cfg.Metadata.TrackingFile)
+	extensions := cfg.Settings.Query.Index.Extensions
+
+	checked, invalid := 0, 0
+	for _, source := range cfg.Sources {
+		kind := source.ArtifactKind()
+		if kind == config.KindAgent {
+			continue
+		}
+		files, err := track.GetInstalledFiles(source.Name)
+		if err != nil {
+			continue // Not installed
+		}
+		for _, path := range files {
+			if !artifact.IsArtifactFile(kind, path, extensions) {
+				continue
+			}
+			checked++
+			err := artifact.Validate(kind, path)
+			if err == nil && kind == config.KindStatusline {
+				if info, statErr := os.Stat(path); statErr == nil && info.Mode()&0111 == 0 {
+					err = fmt.Errorf("statusline script is not executable")
+				}
+			}
+			if err != nil {
+				PrintError("%s %s: %v", kind, path, err)
+				invalid++
+			}
+		}
+	}
+
+	if checked == 0 {
+		PrintWarning("No output styles or statusline scripts installed")
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d installed artifacts are invalid", invalid, checked)
+	}
+	return nil
+}
+
 // validateInstalledAgents validates all installed agent files
 func (c *ValidateCommand) validateInstalledAgents(sharedCtx *SharedContext) error {
 	agentsDir := sharedCtx.GetAgentsDirectory()
+	extensions := sharedCtx.Config.Settings.Query.Index.Extensions
 
-	// Count all .md files first to get total
+	// Count all agent files first to get total
 	totalFiles := 0
-	err := filepath.Walk(agentsDir, func(path string, info os.FileInfo, err error) error {
+	walkOpts := sharedCtx.Config.Settings.Walk.Options()
+	err := util.Walk(agentsDir, walkOpts, func(path string, info os.FileInfo, err error) error {
+		if errors.Is(err, util.ErrMaxDepth) {
+			return nil // Reported as a parse failure below
+		}
 		if err != nil {
 			return err // Propagate the error
 		}
-		if strings.HasSuffix(path, ".md") && !info.IsDir() {
+		if !info.IsDir() && parser.IsAgentFile(path, extensions) {
 			totalFiles++
 		}
 		return nil
@@ -200,45 +311,61 @@ func (c *ValidateCommand) validateInstalledAgents(sharedCtx *SharedContext) erro
 		return nil
 	}
 
-	// Parse agents with warnings enabled to detect parsing errors
-	parserWithWarnings := parser.NewParserWithOptions(false) // Show warnings
-	parsedAgents, _ := parserWithWarnings.ParseDirectory(agentsDir)
+	permissions, err := c.loadPermissions()
+	if err != nil {
+		return err
+	}
+	toolValidator, err := c.toolValidator(sharedCtx.Config.Settings.Query.Validation)
+	if err != nil {
+		return err
+	}
+
+	// Parse agents and report each file that fails to parse with a suggested fix
+	var parseWarnings []parser.Warning
+	agentParser := parser.NewParserWithOptions(func(w parser.Warning) {
+		parseWarnings = append(parseWarnings, w)
+	})
+	agentParser.Extensions = extensions
+	agentParser.Walk = walkOpts
+	parsedAgents, failures, _ := agentParser.ParseDirectoryReport(agentsDir)
+	for _, failure := range failures {
+		PrintError("Failed to parse %s: %s", failure.Path, failure.Reason)
+		if suggestion := fixSuggestion(failure); suggestion != "" {
+			fmt.Printf("    fix: %s\n", suggestion)
+		}
+	}
+	for _, w := range parseWarnings {
+		PrintWarning("%s", w)
+	}
 
 	// Track statistics
 	validCount := 0
 	invalidCount := 0
-	parseFailureCount := totalFiles - len(parsedAgents) // Files that failed to parse
-	warningCount := 0
-
-	// Validate successfully parsed agents
-	for _, agent := range parsedAgents {
-		isValid := true
-
-		// Check for missing required fields
-		if agent.Name == "" {
-			PrintError("Agent at %s is missing name", agent.FilePath)
-			isValid = false
-		}
-
-		// Check if file exists (shouldn't happen for parsed agents, but double-check)
-		if _, err := os.Stat(agent.FilePath); os.IsNotExist(err) {
-			PrintError("Agent file does not exist: %s", agent.FilePath)
-			isValid = false
-		}
+	parseFailureCount := len(failures)
+	warningCount := len(parseWarnings)
+
+	// Check agents on a bounded worker pool, then report in file order so the
+	// output does not depend on scheduling
+	checks := make([]agentCheck, len(parsedAgents))
+	err = sharedCtx.PM.WithProgress(fmt.Sprintf("Validating %d agents", len(parsedAgents)), len(parsedAgents), func(update func(int)) error {
+		return util.ForEachParallel(sharedCtx.Context(), len(parsedAgents), c.workers, func(i int) {
+			checks[i] = c.checkAgent(sharedCtx, parsedAgents[i], toolValidator, permissions)
+			update(1)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("validation aborted: %w", err)
+	}
 
-		// Check if prompt is reasonable length
-		if len(agent.Prompt) < 10 {
-			PrintWarning("Agent %s has very short prompt", agent.Name)
-			warningCount++
+	for _, check := range checks {
+		for _, message := range check.errors {
+			PrintError("%s", message)
 		}
-
-		// Check if description is present
-		if agent.Description == "" {
-			PrintWarning("Agent %s has no description", agent.Name)
-			warningCount++
+		for _, message := range check.warnings {
+			PrintWarning("%s", message)
 		}
-
-		if isValid {
+		warningCount += len(check.warnings)
+		if len(check.errors) == 0 {
 			validCount++
 		} else {
 			invalidCount++
@@ -247,30 +374,189 @@ func (c *ValidateCommand) validateInstalledAgents(sharedCtx *SharedContext) erro
 
 	// Add parse failures to invalid count
 	invalidCount += parseFailureCount
+	sharedCtx.Summarize("agents", totalFiles)
+	sharedCtx.Summarize("invalid", invalidCount)
+	sharedCtx.Summarize("warnings", warningCount)
 
 	// Display summary
 	fmt.Println()
 	color.Blue("Agent Validation Summary")
 	fmt.Println(strings.Repeat("=", 40))
 	fmt.Printf("Total agent files: %d\n", totalFiles)
-	color.Green("✓ Valid agents: %d\n", validCount)
+	color.Green("%s Valid agents: %d\n", util.Symbol("✓"), validCount)
 	if invalidCount > 0 {
-		color.Red("✗ Invalid agents: %d\n", invalidCount)
+		color.Red("%s Invalid agents: %d\n", util.Symbol("✗"), invalidCount)
 		if parseFailureCount > 0 {
 			color.Red("  - Failed to parse: %d\n", parseFailureCount)
 		}
 	}
 	if warningCount > 0 {
-		color.Yellow("⚠ Warnings: %d\n", warningCount)
+		color.Yellow("%s Warnings: %d\n", util.Symbol("⚠"), warningCount)
 	}
 
 	if invalidCount > 0 {
-		return fmt.Errorf("found %d invalid agents", invalidCount)
+		return &ValidationError{
+			Total:         totalFiles,
+			Valid:         validCount,
+			Invalid:       invalidCount,
+			ParseFailures: parseFailureCount,
+			Warnings:      warningCount,
+		}
 	}
 
 	return nil
 }
 
+// validateStdinAgent checks a single agent document read from r with the
+// checks applied to installed agents
+func (c *ValidateCommand) validateStdinAgent(sharedCtx *SharedContext, r io.Reader) error {
+	_, agent, err := readStdinAgent(sharedCtx, r)
+	if err != nil {
+		PrintError("%v", err)
+		return &ValidationError{Total: 1, Invalid: 1, ParseFailures: 1}
+	}
+
+	permissions, err := c.loadPermissions()
+	if err != nil {
+		return err
+	}
+	toolValidator, err := c.toolValidator(sharedCtx.Config.Settings.Query.Validation)
+	if err != nil {
+		return err
+	}
+
+	check := c.checkAgent(sharedCtx, agent, toolValidator, permissions)
+	for _, message := range check.errors {
+		PrintError("%s", message)
+	}
+	for _, message := range check.warnings {
+		PrintWarning("%s", message)
+	}
+	sharedCtx.Summarize("invalid", len(check.errors))
+	sharedCtx.Summarize("warnings", len(check.warnings))
+
+	if len(check.errors) > 0 {
+		return &ValidationError{Total: 1, Invalid: 1, Warnings: len(check.warnings)}
+	}
+	PrintSuccess("Agent %s from stdin is valid", agent.Name)
+	return nil
+}
+
+// checkAgent checks one installed agent. It only reads shared state, so
+// agents are checked concurrently.
+func (c *ValidateCommand) checkAgent(sharedCtx *SharedContext, agent *parser.AgentSpec, toolValidator *validator.Validator, permissions *validator.Permissions) agentCheck {
+	var check agentCheck
+	errorf := func(format string, args ...interface{}) {
+		check.errors = append(check.errors, fmt.Sprintf(format, args...))
+	}
+	warnf := func(format string, args ...interface{}) {
+		check.warnings = append(check.warnings, fmt.Sprintf(format, args...))
+	}
+
+	// Check for missing required fields
+	if agent.Name == "" {
+		errorf("Agent at %s is missing name", agent.FilePath)
+	}
+
+	// Check if file exists (shouldn't happen for parsed agents, but double-check);
+	// agents read from stdin have no file
+	if agent.FilePath != stdinAgentPath {
+		if _, err := os.Stat(agent.FilePath); os.IsNotExist(err) {
+			errorf("Agent file does not exist: %s", agent.FilePath)
+		}
+	}
+
+	// Check if prompt is reasonable length
+	if len(agent.Prompt) < 10 {
+		warnf("Agent %s has very short prompt", agent.Name)
+	}
+
+	// Check if description is present
+	if agent.Description == "" {
+		warnf("Agent %s has no description", agent.Name)
+	}
+
+	// Files that are not plain UTF-8 parse, but other tools may misread them
+	if agent.Encoding != "" {
+		warnf("Agent %s is encoded as %s; re-save %s as UTF-8 without a byte order mark", agent.Name, agent.Encoding, agent.FilePath)
+	}
+
+	// Check the agent file against the configured size limit
+	if maxBytes := sharedCtx.Config.Settings.Limits.MaxAgentFileBytes(); maxBytes > 0 && agent.FileSize > maxBytes {
+		warnf("Agent %s is %d KB, exceeding max_agent_file_kb (%d KB)",
+			agent.Name, (agent.FileSize+1023)/1024, sharedCtx.Config.Settings.Limits.MaxAgentFileKB)
+	}
+
+	// Check requested tools against the known tool names
+	if toolValidator != nil {
+		for _, tool := range toolValidator.UnknownTools(agent.GetToolsAsSlice()) {
+			warnf("Agent %s requests unknown tool %s", agent.Name, tool)
+		}
+	}
+
+	// Check requested tools against the user's permission rules
+	if permissions != nil {
+		for _, message := range permissions.CheckTools(agent.GetToolsAsSlice()) {
+			warnf("Agent %s: %s", agent.Name, message)
+		}
+	}
+
+	return check
+}
+
+// toolValidator returns a validator with the allowed tools from the local
+// Claude Code installation or the configuration, or nil when tool checks are disabled
+func (c *ValidateCommand) toolValidator(cfg config.ValidationConfig) (*validator.Validator, error) {
+	if !cfg.CheckToolValidity && !c.toolsFromClaude {
+		return nil, nil
+	}
+
+	v := validator.NewValidator()
+	if !c.toolsFromClaude {
+		v.SetAllowedTools(cfg.AllowedTools)
+		return v, nil
+	}
+
+	tools, err := validator.ClaudeTools()
+	if err != nil {
+		return nil, err
+	}
+	// Configured tools stay allowed alongside the installed ones
+	v.SetAllowedTools(append(tools, cfg.AllowedTools...))
+	PrintInfo("Checking tools against %d tools from the local Claude Code installation", len(tools))
+	return v, nil
+}
+
+// loadPermissions reads permission rules from the requested settings files,
+// or from the default Claude Code settings locations, when enabled
+func (c *ValidateCommand) loadPermissions() (*validator.Permissions, error) {
+	if !c.permissions && len(c.settings) == 0 {
+		return nil, nil
+	}
+
+	requested := c.settings
+	if len(requested) == 0 {
+		requested = validator.DefaultSettingsPaths()
+	}
+	paths := make([]string, 0, len(requested))
+	for _, path := range requested {
+		expanded, err := util.ExpandPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid settings path %s: %w", path, err)
+		}
+		paths = append(paths, expanded)
+	}
+
+	permissions, err := validator.LoadPermissions(paths...)
+	if err != nil {
+		return nil, err
+	}
+	if len(permissions.Deny) == 0 && len(permissions.Ask) == 0 {
+		PrintInfo("No deny or ask permission rules found in %s", strings.Join(paths, ", "))
+	}
+	return permissions, nil
+}
+
 // testQueryFunctionality tests basic query operations
 func (c *ValidateCommand) testQueryFunctionality(sharedCtx *SharedContext) error {
 	queryEngine, err := sharedCtx.CreateQueryEngine()
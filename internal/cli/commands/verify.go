@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/archive"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+// VerifyCommand implements checking installed files against their recorded checksums
+type VerifyCommand struct {
+	fix bool
+}
+
+// NewVerifyCommand creates a new verify command instance
+func NewVerifyCommand() *VerifyCommand {
+	return &VerifyCommand{}
+}
+
+// Name returns the command name
+func (c *VerifyCommand) Name() string {
+	return "verify"
+}
+
+// Description returns the command description
+func (c *VerifyCommand) Description() string {
+	return "Check installed files for changes made outside agent-manager"
+}
+
+// CreateCommand creates the cobra command for verify functionality
+func (c *VerifyCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify [source]",
+		Short: c.Description(),
+		Long: `Compare the files of every installed source, or of one source, with the
+SHA-256 checksums recorded when they were installed, and report files that
+were modified or deleted, and files added to their directories, outside
+agent-manager. Files installed by older versions have no checksum and are
+compared by size. Archived agents are not reported as deleted.
+
+With --fix, sources with modified or deleted files are reinstalled from their
+source like apply does. Added files are left alone; adopt them with
+'list --orphans --adopt' or remove them.
+
+Examples:
+  agent-manager verify
+  agent-manager verify community
+  agent-manager verify --fix`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true, // A failed verification is not a usage error
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source := ""
+			if len(args) == 1 {
+				source = args[0]
+			}
+			return c.Execute(sharedCtx, source)
+		},
+	}
+
+	cmd.Flags().BoolVar(&c.fix, "fix", false, "reinstall sources with modified or deleted files")
+
+	return cmd
+}
+
+// Execute runs the verify command logic
+func (c *VerifyCommand) Execute(sharedCtx *SharedContext, source string) error {
+	// Only --fix changes files, so only --fix follows settings.default_dry_run
+	sharedCtx.mutating = c.fix
+
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	changes, err := c.check(sharedCtx, source)
+	if err != nil {
+		return err
+	}
+	c.print(changes)
+
+	if c.fix && len(changes) > 0 {
+		if err := c.repair(sharedCtx, changes); err != nil {
+			return err
+		}
+		if !sharedCtx.Options.DryRun {
+			if changes, err = c.check(sharedCtx, source); err != nil {
+				return err
+			}
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, change := range changes {
+		counts[change.Change]++
+	}
+	sharedCtx.Summarize("modified", counts[tracker.FileModified])
+	sharedCtx.Summarize("deleted", counts[tracker.FileDeleted])
+	sharedCtx.Summarize("added", counts[tracker.FileAdded])
+
+	if len(changes) == 0 {
+		PrintSuccess("All installed files match their checksums")
+		return nil
+	}
+	return fmt.Errorf("verify found %d files changed outside agent-manager", len(changes))
+}
+
+// check returns the changed files of source, or of every installed source,
+// leaving out archived agents
+func (c *VerifyCommand) check(sharedCtx *SharedContext, source string) ([]tracker.FileChange, error) {
+	var sources []string
+	if source != "" {
+		sources = []string{source}
+	}
+	changes, err := sharedCtx.Tracker().CheckFiles(sources...)
+	if err != nil {
+		return nil, err
+	}
+
+	store := archive.New(archive.DefaultDir(sharedCtx.Config.Metadata.TrackingFile))
+	kept := changes[:0]
+	for _, change := range changes {
+		if change.Change == tracker.FileDeleted {
+			if absPath, err := filepath.Abs(change.Path); err == nil && store.IsArchived(absPath) {
+				continue
+			}
+		}
+		kept = append(kept, change)
+	}
+	return kept, nil
+}
+
+// print lists the changed files by source
+func (c *VerifyCommand) print(changes []tracker.FileChange) {
+	source := ""
+	for _, change := range changes {
+		if change.Source != source {
+			source = change.Source
+			PrintError("%s:", source)
+		}
+		fmt.Printf("    %-8s  %s\n", change.Change, change.Path)
+	}
+}
+
+// repair reinstalls the sources with modified or deleted files; sources that
+// are no longer configured cannot be fetched again and are reported
+func (c *VerifyCommand) repair(sharedCtx *SharedContext, changes []tracker.FileChange) error {
+	damaged := make(map[string]bool)
+	for _, change := range changes {
+		if change.Change != tracker.FileAdded {
+			damaged[change.Source] = true
+		}
+	}
+	if len(damaged) == 0 {
+		PrintInfo("Only added files found; adopt them with 'agent-manager list --orphans --adopt' or remove them")
+		return nil
+	}
+	names := make([]string, 0, len(damaged))
+	for name := range damaged {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	inst, err := sharedCtx.CreateInstaller()
+	if err != nil {
+		return fmt.Errorf("failed to create installer: %w", err)
+	}
+	for _, name := range names {
+		source, err := sharedCtx.GetSourceByName(name)
+		if err != nil {
+			PrintWarning("Cannot restore %s: it is not configured, so its files cannot be fetched again", name)
+			continue
+		}
+		if _, err := inst.ApplySource(sharedCtx.Context(), *source); err != nil {
+			PrintError("Failed to restore %s: %v", name, err)
+			continue
+		}
+		if !sharedCtx.Options.DryRun {
+			PrintSuccess("Restored %s", name)
+		}
+	}
+	refreshIndex(sharedCtx)
+	return nil
+}
@@ -0,0 +1,242 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+	"github.com/pacphi/claude-code-agent-manager/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+// localPollInterval is how often the trees of local sources are checked for
+// edits when file notifications are unavailable; reinstalls are paced by
+// settings.watch.min_interval anyway, so a shorter interval only costs more
+// directory walks
+const localPollInterval = 2 * time.Second
+
+// WatchCommand implements reinstalling sources as they change
+type WatchCommand struct {
+	source       string
+	pollInterval time.Duration
+	timeout      time.Duration
+	yes          bool
+}
+
+// NewWatchCommand creates a new watch command instance
+func NewWatchCommand() *WatchCommand {
+	return &WatchCommand{}
+}
+
+// Name returns the command name
+func (c *WatchCommand) Name() string {
+	return "watch"
+}
+
+// Description returns the command description
+func (c *WatchCommand) Description() string {
+	return "Reinstall sources automatically when they change"
+}
+
+// CreateCommand creates the cobra command for watch functionality
+func (c *WatchCommand) CreateCommand(sharedCtx *SharedContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: c.Description(),
+		Long: `Watch sources and reinstall them when they change, refreshing the query
+index after every reinstall, until interrupted.
+
+Enabled sources with watch: true are watched, or the sources --source selects.
+Local sources are watched for edited, added or removed files, ignoring version
+control directories, and reconciled like apply does; where file notifications
+are unavailable they are checked every 2 seconds instead. Other sources are
+checked for new commits every --poll-interval and updated like update does.
+Every watched source is brought up to date once at start. Reinstalls are paced
+by settings.watch.
+
+Examples:
+  agent-manager watch
+  agent-manager watch --source local-dev
+  agent-manager watch --poll-interval 1m`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.Execute(sharedCtx)
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.source, "source", "s", "", "watch only this source, or the sources a glob pattern matches")
+	cmd.Flags().DurationVar(&c.pollInterval, "poll-interval", 0, "how often remote sources are checked for new commits (default: settings.watch.poll_interval)")
+	cmd.Flags().DurationVar(&c.timeout, "timeout", 0, "abort a reinstall after this duration (default: settings.timeout)")
+	AddYesFlag(cmd, &c.yes)
+
+	return cmd
+}
+
+// Execute runs the watch command logic
+func (c *WatchCommand) Execute(sharedCtx *SharedContext) error {
+	if err := sharedCtx.LoadConfig(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	sources, err := c.watchedSources(sharedCtx)
+	if err != nil {
+		return err
+	}
+
+	pollInterval := c.pollInterval
+	if pollInterval <= 0 {
+		pollInterval = sharedCtx.Config.Settings.Watch.PollInterval
+	}
+
+	bySource := make(map[string]config.Source, len(sources))
+	localRoots := make(map[string]string)
+	var remote []string
+	for _, source := range sources {
+		bySource[source.Name] = source
+		if source.Type != "local" {
+			remote = append(remote, source.Name)
+			continue
+		}
+		root, err := util.ExpandPath(source.Paths.Source)
+		if err != nil {
+			return fmt.Errorf("failed to expand source path of %s: %w", source.Name, err)
+		}
+		localRoots[source.Name] = root
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pacing := sharedCtx.Config.Settings.Watch
+	scheduler := watch.NewScheduler(pacing.Debounce, pacing.MinInterval)
+	for _, source := range sources {
+		scheduler.Notify(source.Name)
+	}
+
+	if len(localRoots) > 0 {
+		polled := localRoots
+		notifier, unwatched, err := watch.NewNotifier(localRoots)
+		if err != nil {
+			PrintWarning("File notifications unavailable, polling local sources every %s: %v", localPollInterval, err)
+		} else {
+			defer func() { _ = notifier.Close() }()
+			go notifier.Run(ctx, scheduler.Notify)
+			polled = unwatched
+		}
+		if len(polled) > 0 {
+			go watch.NewPoller(polled).Run(ctx, localPollInterval, scheduler.Notify)
+		}
+	}
+	if len(remote) > 0 {
+		go pollRemote(ctx, pollInterval, remote, scheduler.Notify)
+	}
+
+	PrintSuccess("Watching %d sources (Ctrl-C to stop)", len(sources))
+	scheduler.Run(ctx, func(ctx context.Context, batch watch.Batch) {
+		c.reinstall(ctx, sharedCtx, bySource[batch.Source])
+	})
+	PrintInfo("Stopped watching")
+	return nil
+}
+
+// watchedSources returns the enabled sources --source selects, or the
+// enabled sources with watch set
+func (c *WatchCommand) watchedSources(sharedCtx *SharedContext) ([]config.Source, error) {
+	if c.source != "" {
+		sources, err := sharedCtx.FilterEnabledSources(c.source)
+		if err != nil {
+			return nil, err
+		}
+		if len(sources) == 0 {
+			return nil, fmt.Errorf("no enabled sources match %q", c.source)
+		}
+		if isSourcePattern(c.source) {
+			names := make([]string, len(sources))
+			for i, source := range sources {
+				names[i] = source.Name
+			}
+			if err := sharedCtx.confirmSourceSelection(c.source, names, c.yes); err != nil {
+				return nil, err
+			}
+		}
+		return sources, nil
+	}
+
+	var sources []config.Source
+	for _, source := range sharedCtx.Config.Sources {
+		if source.Enabled && source.Watch {
+			sources = append(sources, source)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no enabled sources have watch: true; set it on a source or pass --source")
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Name < sources[j].Name })
+	return sources, nil
+}
+
+// reinstall brings a changed source up to date and refreshes the index when
+// its installation changed. Failures are reported and the watch goes on.
+func (c *WatchCommand) reinstall(ctx context.Context, sharedCtx *SharedContext, source config.Source) {
+	timeout := c.timeout
+	if timeout <= 0 {
+		timeout = sharedCtx.Config.Settings.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	before := c.installedAt(sharedCtx, source.Name)
+
+	inst, err := sharedCtx.CreateInstaller()
+	if err == nil {
+		if source.Type == "local" {
+			_, err = inst.ApplySource(ctx, source)
+		} else {
+			err = inst.UpdateSource(ctx, source.Name)
+		}
+	}
+	if err != nil {
+		PrintError("Failed to reinstall %s: %v", source.Name, err)
+		return
+	}
+
+	if after := c.installedAt(sharedCtx, source.Name); !after.Equal(before) {
+		refreshIndex(sharedCtx)
+	}
+}
+
+// installedAt returns when source was last installed, or the zero time when
+// it is not tracked
+func (c *WatchCommand) installedAt(sharedCtx *SharedContext, source string) time.Time {
+	installation, err := sharedCtx.Tracker().GetInstallation(source)
+	if err != nil {
+		return time.Time{}
+	}
+	return installation.Timestamp
+}
+
+// pollRemote notifies every remote source each interval until ctx is done;
+// the reinstall only fetches when a check finds a new commit
+func pollRemote(ctx context.Context, interval time.Duration, sources []string, notify func(string)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, source := range sources {
+			notify(source)
+		}
+	}
+}
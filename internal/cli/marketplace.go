@@ -6,30 +6,29 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// NewMarketplaceCmd creates the marketplace command using the new architecture
-func NewMarketplaceCmd() *cobra.Command {
+// NewMarketplaceCmd creates the marketplace command using the new architecture;
+// install writes the agents picked with marketplace install
+func NewMarketplaceCmd(install marketplace.AgentInstaller) *cobra.Command {
 	// Create marketplace container with default configuration
 	container, err := marketplaceService.WithDefaults()
 	if err != nil {
-		// Return a command that shows the error immediately
-		return &cobra.Command{
-			Use:   "marketplace",
-			Short: "Browse and search the subagents.sh marketplace",
-			RunE: func(cmd *cobra.Command, args []string) error {
-				cmd.Printf("ERROR: Failed to initialize marketplace: %v\n", err)
-				return err
-			},
-		}
+		// Keep setup reachable, as it is how a missing browser gets installed
+		return marketplace.NewUnavailableCommands(err).NewMarketplaceCmd()
 	}
 
 	// Create commands with dependency injection
 	commands := marketplace.NewCommands(container.Service)
+	commands.SetInstaller(install)
 
 	// Return the marketplace command
 	cmd := commands.NewMarketplaceCmd()
 
 	// Add cleanup on command completion
+	postRun := cmd.PersistentPostRun
 	cmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		if postRun != nil {
+			postRun(cmd, args)
+		}
 		if container != nil {
 			_ = container.Close()
 		}
@@ -1,17 +1,32 @@
 package marketplace
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/pacphi/claude-code-agent-manager/internal/cli/marketplace/display"
+	"github.com/pacphi/claude-code-agent-manager/internal/marketplace/browser"
 	"github.com/pacphi/claude-code-agent-manager/internal/marketplace/service"
+	"github.com/pacphi/claude-code-agent-manager/internal/types"
 	"github.com/spf13/cobra"
 )
 
+// AgentInstaller installs a single marketplace agent with its downloaded
+// content into the configured agents directory
+type AgentInstaller func(cmd *cobra.Command, agent types.Agent, content string) error
+
 // Commands handles marketplace CLI commands
 type Commands struct {
 	service service.MarketplaceService
 	display *display.Formatter
+	// install writes agents picked with marketplace install; nil leaves the
+	// command unavailable
+	install AgentInstaller
+	// unavailable is why the marketplace service could not be created; only
+	// setup works without it
+	unavailable error
 }
 
 // NewCommands creates a new marketplace commands handler
@@ -22,8 +37,26 @@ func NewCommands(svc service.MarketplaceService) *Commands {
 	}
 }
 
+// SetInstaller sets how marketplace install writes and tracks an agent
+func (c *Commands) SetInstaller(install AgentInstaller) {
+	c.install = install
+}
+
+// NewUnavailableCommands creates marketplace commands for when the service
+// could not be created, such as when no browser is installed yet. Setup still
+// works; the other commands report err.
+func NewUnavailableCommands(err error) *Commands {
+	return &Commands{
+		display:     display.NewFormatter(),
+		unavailable: err,
+	}
+}
+
 // NewMarketplaceCmd creates the main marketplace command
 func (c *Commands) NewMarketplaceCmd() *cobra.Command {
+	var timeout time.Duration
+	var cancel context.CancelFunc
+
 	cmd := &cobra.Command{
 		Use:   "marketplace",
 		Short: "Browse the subagents.sh marketplace",
@@ -33,12 +66,35 @@ Examples:
   agent-manager marketplace list                    # List all categories
   agent-manager marketplace list --category dev     # List agents in development category
   agent-manager marketplace show code-reviewer      # Show details for a specific agent
-  agent-manager marketplace refresh                 # Refresh cached marketplace data`,
+  agent-manager marketplace search terraform        # Search agents across all categories
+  agent-manager marketplace install code-reviewer   # Install a single agent
+  agent-manager marketplace refresh                 # Refresh cached marketplace data
+  agent-manager marketplace setup                   # Download and pin the headless browser`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if root := cmd.Root(); root.PersistentPreRun != nil && root != cmd {
+				root.PersistentPreRun(cmd, args)
+			}
+			if timeout > 0 {
+				var ctx context.Context
+				ctx, cancel = context.WithTimeout(cmd.Context(), timeout)
+				cmd.SetContext(ctx)
+			}
+		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			if cancel != nil {
+				cancel()
+			}
+		},
 	}
 
+	cmd.PersistentFlags().DurationVar(&timeout, "timeout", 5*time.Minute, "marketplace operation timeout (0 disables)")
+
 	cmd.AddCommand(c.newListCmd())
 	cmd.AddCommand(c.newShowCmd())
+	cmd.AddCommand(c.newSearchCmd())
+	cmd.AddCommand(c.newInstallCmd())
 	cmd.AddCommand(c.newRefreshCmd())
+	cmd.AddCommand(c.newSetupCmd())
 
 	return cmd
 }
@@ -53,6 +109,9 @@ func (c *Commands) newListCmd() *cobra.Command {
 		Short: "List marketplace categories or agents",
 		Long:  "List all available categories or agents within a specific category",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.available(); err != nil {
+				return err
+			}
 			if category == "" {
 				return c.listCategories(cmd)
 			}
@@ -76,6 +135,9 @@ func (c *Commands) newShowCmd() *cobra.Command {
 		Long:  "Display detailed information about a marketplace agent",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.available(); err != nil {
+				return err
+			}
 			agentID := args[0]
 			return c.showAgent(cmd, agentID, showContent)
 		},
@@ -86,6 +148,70 @@ func (c *Commands) newShowCmd() *cobra.Command {
 	return cmd
 }
 
+// newSearchCmd creates the search command
+func (c *Commands) newSearchCmd() *cobra.Command {
+	var query service.SearchQuery
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search marketplace agents without installing them",
+		Long: `Search the agents of every marketplace category, or of one with --category,
+for those whose name, description, author or category contain all words of
+the query. Matches in the name rank first, then by --sort.
+
+Examples:
+  agent-manager marketplace search terraform
+  agent-manager marketplace search "code review" --min-rating 4
+  agent-manager marketplace search docker --category devops --sort downloads`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.available(); err != nil {
+				return err
+			}
+			switch query.SortBy {
+			case "rating", "downloads", "name", "date":
+			default:
+				return fmt.Errorf("invalid --sort %q: use rating, downloads, name or date", query.SortBy)
+			}
+			query.Query = strings.Join(args, " ")
+			return c.search(cmd, query)
+		},
+	}
+
+	cmd.Flags().StringVar(&query.Category, "category", "", "search only this category")
+	cmd.Flags().IntVarP(&query.Limit, "limit", "l", 20, "limit number of results (0 for all)")
+	cmd.Flags().Float32Var(&query.MinRating, "min-rating", 0, "only agents rated at least this")
+	cmd.Flags().StringVar(&query.SortBy, "sort", "rating", "order of equally relevant results: rating, downloads, name or date")
+
+	return cmd
+}
+
+// newInstallCmd creates the install command
+func (c *Commands) newInstallCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install <slug>",
+		Short: "Install a single marketplace agent",
+		Long: `Download one marketplace agent and install it into settings.base_dir, without
+configuring a subagents source. Existing files are handled by
+settings.conflict_strategy. The agent is tracked under the implicit
+"marketplace" source, so list shows it and uninstall --source marketplace
+removes it.
+
+Examples:
+  agent-manager marketplace search terraform
+  agent-manager marketplace install terraform-expert`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.available(); err != nil {
+				return err
+			}
+			return c.installAgent(cmd, args[0])
+		},
+	}
+
+	return cmd
+}
+
 // newRefreshCmd creates the refresh command
 func (c *Commands) newRefreshCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -93,6 +219,9 @@ func (c *Commands) newRefreshCmd() *cobra.Command {
 		Short: "Refresh cached marketplace data",
 		Long:  "Clear the cache and fetch fresh data from the marketplace",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.available(); err != nil {
+				return err
+			}
 			return c.refreshCache(cmd)
 		},
 	}
@@ -100,6 +229,48 @@ func (c *Commands) newRefreshCmd() *cobra.Command {
 	return cmd
 }
 
+// newSetupCmd creates the setup command
+func (c *Commands) newSetupCmd() *cobra.Command {
+	var opts browser.SetupOptions
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "setup",
+		Short: "Download and pin the headless browser the marketplace uses",
+		Long: fmt.Sprintf(`Download the Chrome for Testing headless shell into a managed directory and
+pin it, so marketplace commands and subagents sources keep working offline and
+do not depend on the browser installed on the system.
+
+The runtime is kept in the user cache directory, or in $%s when set.
+Use --archive to install a zip downloaded elsewhere, for machines without
+network access, and --sha256 to verify it.
+
+Examples:
+  agent-manager marketplace setup
+  agent-manager marketplace setup --version %s
+  agent-manager marketplace setup --archive chrome-headless-shell-linux64.zip --sha256 <digest>`, browser.RuntimeDirEnv, browser.PinnedVersion),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.setup(cmd, opts, force)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Version, "version", browser.PinnedVersion, "headless shell version to install and pin")
+	cmd.Flags().StringVar(&opts.Archive, "archive", "", "install from a downloaded zip instead of downloading")
+	cmd.Flags().StringVar(&opts.SHA256, "sha256", "", "expected sha256 digest of the archive")
+	cmd.Flags().BoolVar(&force, "force", false, "reinstall even when the version is already pinned")
+
+	return cmd
+}
+
+// available returns why the marketplace cannot be used, or nil when it can
+func (c *Commands) available() error {
+	if c.unavailable != nil {
+		return fmt.Errorf("marketplace is not available: %w", c.unavailable)
+	}
+	return nil
+}
+
 // listCategories lists all marketplace categories
 func (c *Commands) listCategories(cmd *cobra.Command) error {
 	categories, err := c.service.GetCategories(cmd.Context())
@@ -126,6 +297,38 @@ func (c *Commands) listAgents(cmd *cobra.Command, category string, limit int) er
 	return nil
 }
 
+// search lists the agents matching a query
+func (c *Commands) search(cmd *cobra.Command, query service.SearchQuery) error {
+	agents, err := c.service.SearchAgents(cmd.Context(), query)
+	if err != nil {
+		return fmt.Errorf("failed to search the marketplace: %w", err)
+	}
+
+	c.display.PrintSearchResults(query.Query, agents)
+	return nil
+}
+
+// installAgent downloads one agent and hands it to the installer
+func (c *Commands) installAgent(cmd *cobra.Command, slug string) error {
+	if c.install == nil {
+		return fmt.Errorf("marketplace install is not available")
+	}
+
+	agent, err := c.service.GetAgent(cmd.Context(), slug)
+	if err != nil {
+		return fmt.Errorf("failed to get agent %s: %w", slug, err)
+	}
+	content, err := c.service.GetAgentContent(cmd.Context(), slug)
+	if err != nil {
+		return fmt.Errorf("failed to download agent %s: %w", slug, err)
+	}
+	if content == agent.Description {
+		c.display.PrintWarning(fmt.Sprintf("The full definition of %s could not be downloaded; installing its description", slug))
+	}
+
+	return c.install(cmd, *agent, content)
+}
+
 // showAgent displays detailed information about an agent
 func (c *Commands) showAgent(cmd *cobra.Command, agentID string, showContent bool) error {
 	agent, err := c.service.GetAgent(cmd.Context(), agentID)
@@ -145,6 +348,33 @@ func (c *Commands) showAgent(cmd *cobra.Command, agentID string, showContent boo
 	return nil
 }
 
+// setup installs and pins the headless browser runtime
+func (c *Commands) setup(cmd *cobra.Command, opts browser.SetupOptions, force bool) error {
+	dir, err := browser.RuntimeDir()
+	if err != nil {
+		return err
+	}
+	pinned, err := browser.LoadRuntime(dir)
+	if err != nil {
+		return err
+	}
+	if pinned != nil && pinned.Version == opts.Version && pinned.Check() == nil && !force && opts.Archive == "" {
+		c.display.PrintSuccess(fmt.Sprintf("Headless browser %s is already set up at %s", pinned.Version, pinned.Executable))
+		return nil
+	}
+
+	if opts.Archive == "" {
+		cmd.Printf("Downloading headless browser %s...\n", opts.Version)
+	}
+	pinned, err = browser.Setup(cmd.Context(), dir, opts)
+	if err != nil {
+		return fmt.Errorf("browser setup failed: %w", err)
+	}
+	c.display.PrintSuccess(fmt.Sprintf("Pinned headless browser %s at %s", pinned.Version, pinned.Executable))
+	cmd.Printf("Archive sha256: %s\n", pinned.SHA256)
+	return nil
+}
+
 // refreshCache refreshes the marketplace cache
 func (c *Commands) refreshCache(cmd *cobra.Command) error {
 	err := c.service.RefreshCache(cmd.Context())
@@ -8,6 +8,7 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/pacphi/claude-code-agent-manager/internal/marketplace"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 )
 
 // Formatter handles output formatting for marketplace data
@@ -82,6 +83,52 @@ func (f *Formatter) PrintAgents(agents []marketplace.Agent) {
 	}
 }
 
+// PrintSearchResults displays the agents matching a search with their
+// rating, downloads and author
+func (f *Formatter) PrintSearchResults(query string, agents []marketplace.Agent) {
+	if len(agents) == 0 {
+		f.PrintWarning(fmt.Sprintf("No agents match %q", query))
+		return
+	}
+
+	f.PrintHeader(fmt.Sprintf("Found %d agents matching %q:", len(agents), query))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+		color.HiCyanString("SLUG"),
+		color.HiCyanString("NAME"),
+		color.HiCyanString("RATING"),
+		color.HiCyanString("DOWNLOADS"),
+		color.HiCyanString("AUTHOR"),
+		color.HiCyanString("CATEGORY"))
+
+	for _, agent := range agents {
+		rating := "-"
+		if agent.Rating > 0 {
+			rating = fmt.Sprintf("%.1f", agent.Rating)
+		}
+		downloads := "-"
+		if agent.Downloads > 0 {
+			downloads = fmt.Sprintf("%d", agent.Downloads)
+		}
+		author := agent.Author
+		if author == "" {
+			author = "-"
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			agent.Slug,
+			f.truncateString(agent.Name, 30),
+			rating,
+			downloads,
+			f.truncateString(author, 20),
+			f.truncateString(agent.Category, 15))
+	}
+	_ = w.Flush()
+
+	fmt.Printf("\nInstall one with: agent-manager marketplace install <slug>\n")
+}
+
 // PrintAgentDetails displays detailed information about a single agent
 func (f *Formatter) PrintAgentDetails(agent marketplace.Agent, content string) {
 	fmt.Printf("\n%s\n", color.HiCyanString("Agent Details"))
@@ -112,7 +159,7 @@ func (f *Formatter) PrintAgentDetails(agent marketplace.Agent, content string) {
 	if content != "" && content != agent.Description {
 		// Check if this is a full agent definition (with YAML frontmatter)
 		if strings.HasPrefix(strings.TrimSpace(content), "---") {
-			fmt.Printf("\n%s:\n", color.HiGreenString("═══ Agent Definition ═══"))
+			fmt.Printf("\n%s:\n", color.HiGreenString(util.PlainText("═══ Agent Definition ═══")))
 			fmt.Printf("\n%s\n", content)
 		} else {
 			fmt.Printf("\n%s:\n", color.HiWhiteString("Content"))
@@ -131,12 +178,12 @@ func (f *Formatter) PrintAgentDetails(agent marketplace.Agent, content string) {
 
 // PrintSuccess displays a success message
 func (f *Formatter) PrintSuccess(message string) {
-	fmt.Printf("%s %s\n", color.GreenString("✓"), message)
+	fmt.Printf("%s %s\n", color.GreenString(util.Symbol("✓")), message)
 }
 
 // PrintWarning displays a warning message
 func (f *Formatter) PrintWarning(message string) {
-	fmt.Printf("%s %s\n", color.YellowString("⚠"), message)
+	fmt.Printf("%s %s\n", color.YellowString(util.Symbol("⚠")), message)
 }
 
 // PrintHeader displays a section header
@@ -151,9 +198,9 @@ func (f *Formatter) formatRating(rating float32) string {
 	}
 
 	stars := int(rating)
-	starStr := strings.Repeat("⭐", stars)
+	starStr := strings.Repeat(util.Symbol("⭐"), stars)
 	if rating > float32(stars) {
-		starStr += "½"
+		starStr += util.Symbol("½")
 	}
 
 	return fmt.Sprintf("%s (%.1f)", starStr, rating)
@@ -2,9 +2,13 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,7 +21,26 @@ type Config struct {
 	Version  string   `yaml:"version"`
 	Settings Settings `yaml:"settings"`
 	Sources  []Source `yaml:"sources"`
-	Metadata Metadata `yaml:"metadata"`
+	// Collections name bundles of agents managed as one unit across sources
+	Collections map[string][]string `yaml:"collections,omitempty"`
+	Metadata    Metadata            `yaml:"metadata"`
+}
+
+// Collection returns the agent names of the named collection
+func (c *Config) Collection(name string) ([]string, error) {
+	agents, ok := c.Collections[name]
+	if !ok {
+		defined := make([]string, 0, len(c.Collections))
+		for collection := range c.Collections {
+			defined = append(defined, collection)
+		}
+		sort.Strings(defined)
+		if len(defined) == 0 {
+			return nil, fmt.Errorf("unknown collection %q: no collections are defined", name)
+		}
+		return nil, fmt.Errorf("unknown collection %q (defined: %s)", name, strings.Join(defined, ", "))
+	}
+	return agents, nil
 }
 
 // Settings contains global settings
@@ -31,6 +54,162 @@ type Settings struct {
 	Timeout             time.Duration `yaml:"timeout"`
 	ContinueOnError     bool          `yaml:"continue_on_error"`
 	Query               QueryConfig   `yaml:"query,omitempty"`
+	// ProtectPreExisting keeps files that no source installed instead of
+	// resolving a conflict with them
+	ProtectPreExisting bool `yaml:"protect_pre_existing,omitempty"`
+	// ReadonlyPaths are glob patterns of files no source may write or delete,
+	// whatever the conflict strategy
+	ReadonlyPaths []string `yaml:"readonly_paths,omitempty"`
+	// DefaultDryRun makes mutating commands plan only unless run with --apply
+	DefaultDryRun bool         `yaml:"default_dry_run,omitempty"`
+	Limits        LimitsConfig `yaml:"limits,omitempty"`
+	// Licenses restricts which agent licenses may be installed
+	Licenses LicensePolicy `yaml:"licenses,omitempty"`
+	// Prune makes apply uninstall sources that are no longer configured
+	Prune bool `yaml:"prune,omitempty"`
+	// Walk bounds the directory walks over agent and source trees
+	Walk WalkConfig `yaml:"walk,omitempty"`
+	// Metrics exports a snapshot of each run for monitoring
+	Metrics MetricsConfig `yaml:"metrics,omitempty"`
+	// TempCleanupAge is how old temp directories left by crashed runs must be
+	// before commands that change agents remove them; negative disables it
+	TempCleanupAge time.Duration `yaml:"temp_cleanup_age,omitempty"`
+	// Watch paces the reinstalls of sources with watch enabled
+	Watch WatchConfig `yaml:"watch,omitempty"`
+	// IO paces the file copies of installs
+	IO IOConfig `yaml:"io,omitempty"`
+	// Marketplace selects how subagents sources read the marketplace
+	Marketplace MarketplaceConfig `yaml:"marketplace,omitempty"`
+	// continueOnErrorSet records that continue_on_error was given explicitly
+	continueOnErrorSet bool
+}
+
+// MarketplaceConfig selects how the marketplace is read
+type MarketplaceConfig struct {
+	// Mode is api to read the site's JSON over HTTP, browser to drive the
+	// headless browser, or auto (the default) to try HTTP before the browser
+	Mode string `yaml:"mode,omitempty"`
+}
+
+// WatchConfig paces reinstalls of watched sources: changes are coalesced until
+// the source has been quiet for Debounce, and a source is never reinstalled
+// more often than once per MinInterval
+type WatchConfig struct {
+	Debounce    time.Duration `yaml:"debounce,omitempty"`
+	MinInterval time.Duration `yaml:"min_interval,omitempty"`
+	// PollInterval is how often remote sources are checked for new commits
+	PollInterval time.Duration `yaml:"poll_interval,omitempty"`
+}
+
+// Default pacing of watched sources
+const (
+	DefaultWatchDebounce     = 500 * time.Millisecond
+	DefaultWatchMinInterval  = 5 * time.Second
+	DefaultWatchPollInterval = 5 * time.Minute
+)
+
+// MetricsConfig controls the metrics snapshot written after each command
+type MetricsConfig struct {
+	// Textfile is a .prom file for node_exporter's textfile collector; empty disables it
+	Textfile string `yaml:"textfile,omitempty"`
+	// Usage records command runs, durations and error categories in a local
+	// file for stats --self; nothing is ever sent over the network
+	Usage bool `yaml:"usage,omitempty"`
+}
+
+// WalkConfig controls how agent and source directories are walked
+type WalkConfig struct {
+	MaxDepth       int  `yaml:"max_depth,omitempty"`       // directory levels below the root; -1 for no limit
+	FollowSymlinks bool `yaml:"follow_symlinks,omitempty"` // descend into symlinked directories
+}
+
+// Options returns the walk options for directory walks
+func (w WalkConfig) Options() util.WalkOptions {
+	return util.WalkOptions{MaxDepth: w.MaxDepth, FollowSymlinks: w.FollowSymlinks}
+}
+
+// LimitsConfig bounds the size of installed agent files
+type LimitsConfig struct {
+	MaxAgentFileKB int    `yaml:"max_agent_file_kb,omitempty"` // 0 disables the limit
+	OnExceed       string `yaml:"on_exceed,omitempty"`         // skip, warn or fail
+}
+
+// MaxAgentFileBytes returns the agent file size limit in bytes, or 0 when unlimited
+func (l LimitsConfig) MaxAgentFileBytes() int64 {
+	return int64(l.MaxAgentFileKB) * 1024
+}
+
+// IOConfig bounds and throttles the file copies of installs, so installing
+// onto a shared or network filesystem does not starve other processes
+type IOConfig struct {
+	Concurrency    int     `yaml:"concurrency,omitempty"`      // concurrent copies; 0 copies one file at a time
+	FilesPerSecond float64 `yaml:"files_per_second,omitempty"` // 0 disables the limit
+	MBPerSecond    float64 `yaml:"mb_per_second,omitempty"`    // 0 disables the limit
+}
+
+// Override returns c with every field set in override replacing its own
+func (c IOConfig) Override(override IOConfig) IOConfig {
+	if override.Concurrency != 0 {
+		c.Concurrency = override.Concurrency
+	}
+	if override.FilesPerSecond != 0 {
+		c.FilesPerSecond = override.FilesPerSecond
+	}
+	if override.MBPerSecond != 0 {
+		c.MBPerSecond = override.MBPerSecond
+	}
+	return c
+}
+
+// LicensePolicy restricts installing agents by the license in their frontmatter
+type LicensePolicy struct {
+	Allowed       []string `yaml:"allowed,omitempty"`        // allowed licenses; empty allows any declared license
+	Require       bool     `yaml:"require,omitempty"`        // block agents that declare no license
+	ExemptSources []string `yaml:"exempt_sources,omitempty"` // sources the policy does not apply to
+	OnViolation   string   `yaml:"on_violation,omitempty"`   // skip, warn or fail
+}
+
+// Violation returns why an agent of source with the given license breaks the
+// policy, or an empty string when it may be installed. An expression such as
+// "MIT OR Apache-2.0" is allowed when any of its alternatives is.
+func (p LicensePolicy) Violation(source, license string) string {
+	for _, exempt := range p.ExemptSources {
+		if exempt == source {
+			return ""
+		}
+	}
+
+	license = strings.TrimSpace(license)
+	if license == "" {
+		if p.Require || len(p.Allowed) > 0 {
+			return "no license declared"
+		}
+		return ""
+	}
+	if len(p.Allowed) == 0 {
+		return ""
+	}
+	for _, alternative := range strings.Split(license, " OR ") {
+		for _, allowed := range p.Allowed {
+			if strings.EqualFold(strings.Trim(strings.TrimSpace(alternative), "()"), allowed) {
+				return ""
+			}
+		}
+	}
+	return fmt.Sprintf("license %s is not allowed", license)
+}
+
+// Artifact kinds a source can install
+const (
+	KindAgent       = "agent"
+	KindOutputStyle = "output-style"
+	KindStatusline  = "statusline"
+)
+
+// DefaultTargets are the install directories of artifact kinds whose sources set no target
+var DefaultTargets = map[string]string{
+	KindOutputStyle: ".claude/output-styles",
+	KindStatusline:  ".claude/statusline",
 }
 
 // Source represents an agent source
@@ -38,20 +217,202 @@ type Source struct {
 	Name             string           `yaml:"name"`
 	Enabled          bool             `yaml:"enabled"`
 	Type             string           `yaml:"type"`
+	Kind             string           `yaml:"kind,omitempty"` // artifact kind: agent, output-style or statusline
 	Repository       string           `yaml:"repository,omitempty"`
 	URL              string           `yaml:"url,omitempty"`
 	Branch           string           `yaml:"branch,omitempty"`
+	Tag              string           `yaml:"tag,omitempty"`        // installs a tag instead of a branch
+	GitLabURL        string           `yaml:"gitlab_url,omitempty"` // self-managed GitLab instance, gitlab.com by default
 	Auth             AuthConfig       `yaml:"auth,omitempty"`
+	Prefer           string           `yaml:"prefer,omitempty"` // GitHub clone backend: gh or go-git
 	Paths            PathConfig       `yaml:"paths"`
 	Filters          FilterConfig     `yaml:"filters,omitempty"`
 	Transformations  []Transformation `yaml:"transformations,omitempty"`
 	PostInstall      []PostInstall    `yaml:"post_install,omitempty"`
 	ConflictStrategy string           `yaml:"conflict_strategy,omitempty"`
 	Watch            bool             `yaml:"watch,omitempty"`
+	// DryRun plans changes to this source unless run with --apply
+	DryRun bool `yaml:"dry_run,omitempty"`
+	// PreserveStructure keeps subdirectories as agent namespaces and fails on target collisions
+	PreserveStructure bool `yaml:"preserve_structure,omitempty"`
+	// ReadonlyPaths adds patterns of files this source may not write or delete
+	// to settings.readonly_paths
+	ReadonlyPaths []string `yaml:"readonly_paths,omitempty"`
+	// Release selects the asset installed by a github-release source
+	Release ReleaseConfig `yaml:"release,omitempty"`
+	// Archive verifies the download of an archive source
+	Archive ArchiveConfig `yaml:"archive,omitempty"`
+	// Clone limits the history and files fetched for git, github and gitlab sources
+	Clone CloneConfig `yaml:"clone,omitempty"`
+	// Mirrors are fallback git URLs tried in order when fetching the source fails
+	Mirrors []Mirror `yaml:"mirrors,omitempty"`
+	// MirrorTimeout limits each fetch attempt when mirrors are configured
+	MirrorTimeout time.Duration `yaml:"mirror_timeout,omitempty"`
 	// Marketplace-specific fields
 	Category       string      `yaml:"category,omitempty"`        // Filter by marketplace category
 	MarketplaceURL string      `yaml:"marketplace_url,omitempty"` // Custom marketplace URL
 	Cache          CacheConfig `yaml:"cache,omitempty"`           // Cache configuration
+	// AgentTimeout limits downloading the content of each marketplace agent
+	AgentTimeout time.Duration `yaml:"agent_timeout,omitempty"`
+	// IO overrides settings.io for the file copies of this source
+	IO IOConfig `yaml:"io,omitempty"`
+}
+
+// DefaultAgentTimeout limits downloading each agent of a marketplace source
+const DefaultAgentTimeout = 30 * time.Second
+
+// DefaultMirrorTimeout limits each fetch attempt of a source with mirrors
+const DefaultMirrorTimeout = 2 * time.Minute
+
+// ReleaseConfig selects the GitHub release asset of a github-release source
+type ReleaseConfig struct {
+	// Tag pins a release; the latest non-prerelease is used when empty
+	Tag string `yaml:"tag,omitempty"`
+	// Asset is the name or glob of a .zip, .tar.gz or .tgz asset
+	Asset string `yaml:"asset"`
+	// Checksums names a sums file asset holding the asset's sha256; the
+	// release notes and conventionally named sums files are searched when empty
+	Checksums string `yaml:"checksums,omitempty"`
+	// AllowUnverified installs the asset when no checksum is published
+	AllowUnverified bool `yaml:"allow_unverified,omitempty"`
+}
+
+// ArchiveConfig describes the .zip or .tar.gz file an archive source downloads from its url
+type ArchiveConfig struct {
+	// SHA256 is the expected digest of the archive; the download is not verified when empty
+	SHA256 string `yaml:"sha256,omitempty"`
+	// Format is zip or tar.gz, for URLs whose path does not end in the archive extension
+	Format string `yaml:"format,omitempty"`
+}
+
+// ArchiveFormat returns the format of an archive source's download, zip or
+// tar.gz, from archive.format or else the extension of its URL path. It
+// returns "" when neither names a supported format.
+func (s Source) ArchiveFormat() string {
+	name := strings.ToLower(s.Archive.Format)
+	if name == "" {
+		name = s.URL
+		if parsed, err := url.Parse(s.URL); err == nil {
+			name = strings.ToLower(parsed.Path)
+		}
+	} else {
+		name = "." + name
+	}
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return "zip"
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tar.gz"
+	default:
+		return ""
+	}
+}
+
+// CloneConfig limits what cloning a git, github or gitlab source downloads and checks out
+type CloneConfig struct {
+	// Depth fetches only that many commits from the tip; 0 clones the full history
+	Depth int `yaml:"depth,omitempty"`
+	// Sparse checks out only paths.source instead of the whole repository
+	Sparse bool `yaml:"sparse,omitempty"`
+}
+
+// Mirror is a fallback location for a git or github source
+type Mirror struct {
+	URL     string        `yaml:"url"`
+	Timeout time.Duration `yaml:"timeout,omitempty"` // overrides the source's mirror_timeout
+}
+
+// UnmarshalYAML decodes settings, recording which keys were given explicitly
+func (s *Settings) UnmarshalYAML(node *yaml.Node) error {
+	type plain Settings
+	if err := node.Decode((*plain)(s)); err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "continue_on_error" {
+			s.continueOnErrorSet = true
+		}
+	}
+	return nil
+}
+
+// ContinuesAfterFailure reports whether a run over several sources goes on
+// with the rest when one fails: it does unless continue_on_error is
+// explicitly set to false
+func (s Settings) ContinuesAfterFailure() bool {
+	return s.ContinueOnError || !s.continueOnErrorSet
+}
+
+// UnmarshalYAML accepts a mirror as a plain URL or as a mapping
+func (m *Mirror) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		m.URL = node.Value
+		return nil
+	}
+	type plain Mirror
+	return node.Decode((*plain)(m))
+}
+
+// AttemptTimeout returns how long a fetch attempt of the source may take when
+// it has mirrors; a mirror's own timeout takes precedence when set
+func (s Source) AttemptTimeout(mirror *Mirror) time.Duration {
+	if mirror != nil && mirror.Timeout > 0 {
+		return mirror.Timeout
+	}
+	if s.MirrorTimeout > 0 {
+		return s.MirrorTimeout
+	}
+	return DefaultMirrorTimeout
+}
+
+// ArtifactKind returns the kind of artifact the source installs, agent by default
+func (s Source) ArtifactKind() string {
+	if s.Kind == "" {
+		return KindAgent
+	}
+	return s.Kind
+}
+
+// ReadonlyPaths returns the readonly_paths patterns that apply to source:
+// those of the settings followed by its own
+func (c *Config) ReadonlyPaths(source Source) []string {
+	patterns := make([]string, 0, len(c.Settings.ReadonlyPaths)+len(source.ReadonlyPaths))
+	patterns = append(patterns, c.Settings.ReadonlyPaths...)
+	return append(patterns, source.ReadonlyPaths...)
+}
+
+// MatchReadonly returns the first of patterns matching file, or "" when none
+// does. A relative pattern matches the trailing elements of the file's path,
+// so CLAUDE.md matches a CLAUDE.md in any directory and .claude/settings.json
+// a settings.json in any .claude directory; an absolute pattern must match
+// the whole path.
+func MatchReadonly(patterns []string, file string) string {
+	if len(patterns) == 0 {
+		return ""
+	}
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	file = filepath.ToSlash(file)
+	elements := strings.Split(strings.TrimPrefix(file, "/"), "/")
+
+	for _, pattern := range patterns {
+		slashed := path.Clean(filepath.ToSlash(pattern))
+		if filepath.IsAbs(pattern) || strings.HasPrefix(slashed, "/") {
+			if matched, _ := path.Match(slashed, file); matched {
+				return pattern
+			}
+			continue
+		}
+		n := strings.Count(slashed, "/") + 1
+		if n > len(elements) {
+			continue
+		}
+		if matched, _ := path.Match(slashed, strings.Join(elements[len(elements)-n:], "/")); matched {
+			return pattern
+		}
+	}
+	return ""
 }
 
 // AuthConfig contains authentication settings
@@ -59,6 +420,41 @@ type AuthConfig struct {
 	Method   string `yaml:"method,omitempty"`
 	TokenEnv string `yaml:"token_env,omitempty"`
 	SSHKey   string `yaml:"ssh_key,omitempty"`
+
+	// Credential names the keychain entry holding the token for keychain auth;
+	// it defaults to the source name
+	Credential string `yaml:"credential,omitempty"`
+	// Helper selects where keychain auth reads the token: the OS keychain
+	// (system, the default) or git's credential helpers (git)
+	Helper string `yaml:"helper,omitempty"`
+
+	// Headers are extra HTTP headers sent with HTTP fetches and marketplace requests;
+	// values may reference ${env.NAME} variables
+	Headers   map[string]string `yaml:"headers,omitempty"`
+	UserAgent string            `yaml:"user_agent,omitempty"`
+}
+
+// CredentialName returns the keychain entry holding the token of source
+func (a AuthConfig) CredentialName(source string) string {
+	if a.Credential != "" {
+		return a.Credential
+	}
+	return source
+}
+
+// HasHTTPOptions reports whether custom headers or a user agent are configured
+func (a AuthConfig) HasHTTPOptions() bool {
+	return len(a.Headers) > 0 || a.UserAgent != ""
+}
+
+// RedactedHeaders describes the configured headers for logs with their values masked
+func (a AuthConfig) RedactedHeaders() string {
+	names := make([]string, 0, len(a.Headers))
+	for name := range a.Headers {
+		names = append(names, name+": ****")
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
 }
 
 // PathConfig contains source and target paths
@@ -71,6 +467,42 @@ type PathConfig struct {
 type FilterConfig struct {
 	Include IncludeFilter `yaml:"include,omitempty"`
 	Exclude ExcludeFilter `yaml:"exclude,omitempty"`
+	// Marketplace metadata filters select the agents of subagents sources
+	// before they are downloaded
+	MinRating    float64  `yaml:"min_rating,omitempty"`
+	MinDownloads int      `yaml:"min_downloads,omitempty"`
+	TagsAny      []string `yaml:"tags_any,omitempty"` // at least one of these tags
+	// Agents restricts an install to the agent files of these names, as when
+	// installing a collection; it is never read from the configuration
+	Agents []string `yaml:"-"`
+}
+
+// HasMetadataFilters reports whether any marketplace metadata filter is set
+func (f FilterConfig) HasMetadataFilters() bool {
+	return f.MinRating > 0 || f.MinDownloads > 0 || len(f.TagsAny) > 0
+}
+
+// MetadataMismatch returns why a marketplace agent with the given rating,
+// downloads and tags fails the metadata filters, or an empty string when it
+// passes them. Tags are compared case-insensitively.
+func (f FilterConfig) MetadataMismatch(rating float64, downloads int, tags []string) string {
+	if f.MinRating > 0 && rating < f.MinRating {
+		return fmt.Sprintf("rating %.1f below min_rating %.1f", rating, f.MinRating)
+	}
+	if f.MinDownloads > 0 && downloads < f.MinDownloads {
+		return fmt.Sprintf("%d downloads below min_downloads %d", downloads, f.MinDownloads)
+	}
+	if len(f.TagsAny) == 0 {
+		return ""
+	}
+	for _, want := range f.TagsAny {
+		for _, tag := range tags {
+			if strings.EqualFold(strings.TrimSpace(tag), strings.TrimSpace(want)) {
+				return ""
+			}
+		}
+	}
+	return fmt.Sprintf("none of the tags %s", strings.Join(f.TagsAny, ", "))
 }
 
 // IncludeFilter contains inclusion rules
@@ -113,11 +545,16 @@ type CacheConfig struct {
 
 // QueryConfig contains query engine configuration
 type QueryConfig struct {
-	Enabled    bool             `yaml:"enabled"`
-	Index      IndexConfig      `yaml:"index,omitempty"`
-	Cache      QueryCacheConfig `yaml:"cache,omitempty"`
-	Validation ValidationConfig `yaml:"validation,omitempty"`
-	Defaults   DefaultsConfig   `yaml:"defaults,omitempty"`
+	Enabled    bool              `yaml:"enabled"`
+	Index      IndexConfig       `yaml:"index,omitempty"`
+	Cache      QueryCacheConfig  `yaml:"cache,omitempty"`
+	Validation ValidationConfig  `yaml:"validation,omitempty"`
+	Defaults   DefaultsConfig    `yaml:"defaults,omitempty"`
+	ParserMode string            `yaml:"parser_mode,omitempty"` // lenient, strict or recover
+	Templates  map[string]string `yaml:"templates,omitempty"`   // named output template files
+	// Weights rank search results by the fields they match in: name,
+	// description and content (the prompt body)
+	Weights map[string]float64 `yaml:"weights,omitempty"`
 }
 
 // IndexConfig contains index configuration
@@ -125,6 +562,16 @@ type IndexConfig struct {
 	Path            string        `yaml:"path,omitempty"`
 	AutoUpdate      bool          `yaml:"auto_update"`
 	RebuildInterval time.Duration `yaml:"rebuild_interval,omitempty"`
+	Extensions      []string      `yaml:"extensions,omitempty"` // agent file extensions
+	// Roots are agent directories indexed alongside base_dir, such as the
+	// user-level ~/.claude/agents next to a project's .claude/agents
+	Roots []IndexRoot `yaml:"roots,omitempty"`
+}
+
+// IndexRoot is an agents directory indexed with a scope label
+type IndexRoot struct {
+	Path  string `yaml:"path"`
+	Scope string `yaml:"scope"` // user or project
 }
 
 // QueryCacheConfig contains query cache configuration
@@ -134,11 +581,40 @@ type QueryCacheConfig struct {
 	MaxSize string        `yaml:"max_size,omitempty"`
 }
 
+// MaxBytes parses MaxSize, a byte count with an optional KB, MB or GB suffix
+// such as "100MB"; zero means no limit
+func (c QueryCacheConfig) MaxBytes() (int64, error) {
+	size := strings.ToUpper(strings.TrimSpace(c.MaxSize))
+	if size == "" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		bytes  int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(size, unit.suffix) {
+			multiplier = unit.bytes
+			size = strings.TrimSpace(strings.TrimSuffix(size, unit.suffix))
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid query.cache.max_size: %q (use a byte count or a KB, MB or GB size such as 100MB)", c.MaxSize)
+	}
+	return n * multiplier, nil
+}
+
 // ValidationConfig contains validation settings
 type ValidationConfig struct {
 	CheckNameFormat     bool `yaml:"check_name_format"`
 	CheckRequiredFields bool `yaml:"check_required_fields"`
 	CheckToolValidity   bool `yaml:"check_tool_validity"`
+	// AllowedTools replaces the built-in list of known tool names
+	AllowedTools []string `yaml:"allowed_tools,omitempty"`
 }
 
 // DefaultsConfig contains query defaults
@@ -153,6 +629,32 @@ type Metadata struct {
 	TrackingFile string `yaml:"tracking_file"`
 	LogFile      string `yaml:"log_file"`
 	LockFile     string `yaml:"lock_file,omitempty"`
+	// SigningKey is a key file outside the project; when set, tracking file
+	// entries are signed with it and verified on load to detect manual edits
+	SigningKey string `yaml:"signing_key,omitempty"`
+
+	// trackingFileSet records that tracking_file was given explicitly
+	trackingFileSet bool
+}
+
+// UnmarshalYAML decodes metadata, recording which keys were given explicitly
+func (m *Metadata) UnmarshalYAML(node *yaml.Node) error {
+	type plain Metadata
+	if err := node.Decode((*plain)(m)); err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "tracking_file" && node.Content[i+1].Value != "" {
+			m.trackingFileSet = true
+		}
+	}
+	return nil
+}
+
+// TrackingFileConfigured reports whether tracking_file was set in the
+// configuration rather than defaulted
+func (m Metadata) TrackingFileConfigured() bool {
+	return m.trackingFileSet
 }
 
 // Load reads and parses the configuration file
@@ -173,6 +675,12 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	return parse(data)
+}
+
+// parse substitutes variables in a configuration document, decodes it and
+// applies defaults
+func parse(data []byte) (*Config, error) {
 	// Parse YAML with variable substitution
 	data = substituteVariables(data)
 
@@ -257,6 +765,34 @@ func applyDefaults(cfg *Config) {
 		cfg.Settings.Timeout = 5 * time.Minute
 	}
 
+	if cfg.Settings.Watch.Debounce == 0 {
+		cfg.Settings.Watch.Debounce = DefaultWatchDebounce
+	}
+
+	if cfg.Settings.Watch.MinInterval == 0 {
+		cfg.Settings.Watch.MinInterval = DefaultWatchMinInterval
+	}
+
+	if cfg.Settings.Watch.PollInterval == 0 {
+		cfg.Settings.Watch.PollInterval = DefaultWatchPollInterval
+	}
+
+	if cfg.Settings.TempCleanupAge == 0 {
+		cfg.Settings.TempCleanupAge = util.DefaultTempCleanupAge
+	}
+
+	if cfg.Settings.Limits.OnExceed == "" {
+		cfg.Settings.Limits.OnExceed = "warn"
+	}
+
+	if cfg.Settings.Walk.MaxDepth == 0 {
+		cfg.Settings.Walk.MaxDepth = util.DefaultMaxWalkDepth
+	}
+
+	if cfg.Settings.Licenses.OnViolation == "" {
+		cfg.Settings.Licenses.OnViolation = "skip"
+	}
+
 	if cfg.Metadata.TrackingFile == "" {
 		cfg.Metadata.TrackingFile = ".claude/.installed-agents.json"
 	}
@@ -274,12 +810,24 @@ func applyDefaults(cfg *Config) {
 
 	// Apply defaults to sources
 	for i := range cfg.Sources {
-		if cfg.Sources[i].Branch == "" && cfg.Sources[i].Type == "github" {
+		if cfg.Sources[i].Branch == "" && cfg.Sources[i].Tag == "" && cfg.Sources[i].Type == "github" {
 			cfg.Sources[i].Branch = "main"
 		}
+		if cfg.Sources[i].Paths.Target == "" {
+			cfg.Sources[i].Paths.Target = DefaultTargets[cfg.Sources[i].Kind]
+		}
 	}
 }
 
+// OverrideBaseDir points the settings at another agents directory, moving
+// the query index along with it when it was left at its default location
+func (s *Settings) OverrideBaseDir(dir string) {
+	if s.Query.Index.Path == filepath.Join(s.BaseDir, ".agent-index") {
+		s.Query.Index.Path = filepath.Join(dir, ".agent-index")
+	}
+	s.BaseDir = dir
+}
+
 // applyQueryDefaults sets default values for query configuration
 func applyQueryDefaults(query *QueryConfig, baseDir string) {
 	// Enable query functionality by default
@@ -287,6 +835,10 @@ func applyQueryDefaults(query *QueryConfig, baseDir string) {
 		query.Enabled = true
 	}
 
+	if query.ParserMode == "" {
+		query.ParserMode = "lenient"
+	}
+
 	// Index defaults
 	if query.Index.Path == "" {
 		query.Index.Path = filepath.Join(baseDir, ".agent-index")
@@ -294,6 +846,9 @@ func applyQueryDefaults(query *QueryConfig, baseDir string) {
 	if query.Index.RebuildInterval == 0 {
 		query.Index.RebuildInterval = 24 * time.Hour
 	}
+	if len(query.Index.Extensions) == 0 {
+		query.Index.Extensions = []string{".md"}
+	}
 
 	// Cache defaults
 	if !query.Cache.Enabled {
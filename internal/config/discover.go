@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DefaultConfigFile is the configuration file name commands look for
+const DefaultConfigFile = "agents-config.yaml"
+
+// Scopes a discovered configuration applies to
+const (
+	ScopeProject = "project"
+	ScopeUser    = "user"
+)
+
+// ProjectDirEnv names the project directory Claude Code runs hooks and
+// tools in; discovery starts there instead of the working directory
+const ProjectDirEnv = "CLAUDE_PROJECT_DIR"
+
+// Discovery is the configuration file and directory a command runs against
+type Discovery struct {
+	// Path is the configuration file
+	Path string
+	// Root is the directory relative paths of the configuration resolve
+	// against: the project root, or the home directory for the user scope
+	Root string
+	// Scope is ScopeProject or ScopeUser
+	Scope string
+}
+
+// Discover finds the configuration for a command started in dir the way git
+// finds a repository: walking up to the nearest directory holding an
+// agents-config.yaml or a .claude/agents directory, which is the project
+// root. A project without its own configuration uses the user configuration
+// in ~/.claude from its root. Outside any project ~/agents-config.yaml or the
+// user configuration is used from the home directory. Nil is returned when
+// nothing is found.
+func Discover(dir string) *Discovery {
+	home, _ := os.UserHomeDir()
+	userConfig := ""
+	if home != "" {
+		userConfig = filepath.Join(home, ".claude", DefaultConfigFile)
+		if !isFile(userConfig) {
+			userConfig = ""
+		}
+	}
+
+	for current := filepath.Clean(dir); ; current = filepath.Dir(current) {
+		// The home directory and its .claude/agents are the user scope, not a project
+		if current == home {
+			if path := filepath.Join(current, DefaultConfigFile); isFile(path) {
+				return &Discovery{Path: path, Root: current, Scope: ScopeUser}
+			}
+			break
+		}
+		if path := filepath.Join(current, DefaultConfigFile); isFile(path) {
+			return &Discovery{Path: path, Root: current, Scope: ScopeProject}
+		}
+		if isDir(filepath.Join(current, ".claude", "agents")) {
+			if userConfig == "" {
+				return nil
+			}
+			return &Discovery{Path: userConfig, Root: current, Scope: ScopeProject}
+		}
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+
+	if userConfig == "" {
+		return nil
+	}
+	return &Discovery{Path: userConfig, Root: home, Scope: ScopeUser}
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscover(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	elsewhere := t.TempDir()
+
+	mkdir := func(dir string) string {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	touch := func(path string) string {
+		t.Helper()
+		mkdir(filepath.Dir(path))
+		if err := os.WriteFile(path, []byte("version: \"1.0\"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	configured := mkdir(filepath.Join(home, "configured"))
+	projectConfig := touch(filepath.Join(configured, DefaultConfigFile))
+	nested := mkdir(filepath.Join(configured, "src", "pkg"))
+	agentsOnly := mkdir(filepath.Join(home, "agents-only"))
+	mkdir(filepath.Join(agentsOnly, ".claude", "agents"))
+	mkdir(filepath.Join(home, ".claude", "agents"))
+	plain := mkdir(filepath.Join(home, "plain", "dir"))
+
+	if got := Discover(agentsOnly); got != nil {
+		t.Errorf("Discover() without user configuration = %+v, want nil", got)
+	}
+	if got := Discover(plain); got != nil {
+		t.Errorf("Discover() outside any project = %+v, want nil", got)
+	}
+
+	userConfig := touch(filepath.Join(home, ".claude", DefaultConfigFile))
+
+	tests := []struct {
+		name string
+		dir  string
+		want Discovery
+	}{
+		{"project root", configured, Discovery{projectConfig, configured, ScopeProject}},
+		{"project subdirectory", nested, Discovery{projectConfig, configured, ScopeProject}},
+		{"project without configuration", agentsOnly, Discovery{userConfig, agentsOnly, ScopeProject}},
+		{"outside any project", plain, Discovery{userConfig, home, ScopeUser}},
+		{"home directory", home, Discovery{userConfig, home, ScopeUser}},
+		{"outside home", elsewhere, Discovery{userConfig, home, ScopeUser}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Discover(tt.dir)
+			if got == nil || *got != tt.want {
+				t.Errorf("Discover(%s) = %+v, want %+v", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// SetSourceEnabled rewrites the enabled flag of a named source in the
+// configuration file at path. Only the flag's value is replaced, or an
+// enabled line added below the source's name, so the rest of the file keeps
+// its formatting byte for byte.
+func SetSourceEnabled(path, sourceName string, enabled bool) error {
+	if err := util.ValidatePath(path); err != nil {
+		return fmt.Errorf("invalid config path: %w", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return fmt.Errorf("configuration file is empty")
+	}
+
+	sources := mappingValue(doc.Content[0], "sources")
+	if sources == nil || sources.Kind != yaml.SequenceNode {
+		return fmt.Errorf("configuration has no sources")
+	}
+
+	var source *yaml.Node
+	for _, node := range sources.Content {
+		if name := mappingValue(node, "name"); name != nil && name.Value == sourceName {
+			source = node
+			break
+		}
+	}
+	if source == nil {
+		return fmt.Errorf("source not found in configuration: %s", sourceName)
+	}
+
+	value := strconv.FormatBool(enabled)
+	var updated []byte
+	if source.Style&yaml.FlowStyle != 0 {
+		// A flow mapping such as {name: x} has no line of its own to edit
+		if node := mappingValue(source, "enabled"); node != nil {
+			node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!bool", value
+		} else {
+			source.Content = append(source.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "enabled"},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: value})
+		}
+		if updated, err = encode(&doc); err != nil {
+			return err
+		}
+	} else if node := mappingValue(source, "enabled"); node != nil && node.Kind == yaml.ScalarNode {
+		start := offsetOf(content, node.Line, node.Column)
+		end := start + len(node.Value)
+		if quote := content[start]; quote == '"' || quote == '\'' {
+			end = start + 1 + bytes.IndexByte(content[start+1:], quote) + 1
+		}
+		updated = append(append(append([]byte{}, content[:start]...), value...), content[end:]...)
+	} else {
+		// Add the flag on the line below the name, at the same indentation
+		var name *yaml.Node
+		for i := 0; i+1 < len(source.Content); i += 2 {
+			if source.Content[i].Value == "name" {
+				name = source.Content[i]
+			}
+		}
+		at := offsetOf(content, name.Line+1, 1)
+		line := strings.Repeat(" ", name.Column-1) + "enabled: " + value + "\n"
+		if at > 0 && content[at-1] != '\n' {
+			line = "\n" + line
+		}
+		updated = append(append(append([]byte{}, content[:at]...), line...), content[at:]...)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, updated, info.Mode().Perm())
+}
+
+// offsetOf returns the byte offset of a 1-based line and column, as yaml.Node
+// reports them, in content; a position past the end returns len(content)
+func offsetOf(content []byte, line, column int) int {
+	offset := 0
+	for ; line > 1; line-- {
+		next := bytes.IndexByte(content[offset:], '\n')
+		if next < 0 {
+			return len(content)
+		}
+		offset += next + 1
+	}
+	// Columns count characters, not bytes
+	for ; column > 1 && offset < len(content); column-- {
+		_, size := utf8.DecodeRune(content[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// Lookup returns the effective value of a dotted configuration key, such as
+// settings.base_dir or sources.team.enabled. List entries are addressed by
+// position or by name. Keys of maps such as settings.query.weights that are
+// not set return nil.
+func Lookup(cfg *Config, key string) (interface{}, error) {
+	parts, err := splitKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	value := reflect.ValueOf(cfg).Elem()
+	for i, part := range parts {
+		switch value.Kind() {
+		case reflect.Struct:
+			field, ok := yamlField(value, part)
+			if !ok {
+				return nil, fmt.Errorf("unknown configuration key: %s", strings.Join(parts[:i+1], "."))
+			}
+			value = field
+		case reflect.Slice:
+			index := sliceIndex(value, part)
+			if index < 0 {
+				return nil, fmt.Errorf("no entry %s in %s", part, strings.Join(parts[:i], "."))
+			}
+			value = value.Index(index)
+		case reflect.Map:
+			value = value.MapIndex(reflect.ValueOf(part))
+			if !value.IsValid() {
+				if i == len(parts)-1 {
+					return nil, nil
+				}
+				return nil, fmt.Errorf("no entry %s in %s", part, strings.Join(parts[:i], "."))
+			}
+		default:
+			return nil, fmt.Errorf("%s is a value, not a section", strings.Join(parts[:i], "."))
+		}
+	}
+	return value.Interface(), nil
+}
+
+// EditValue sets a dotted configuration key in the configuration document
+// content to value, which is parsed as YAML, keeping the rest of the document
+// intact. Missing sections are created. The edited configuration must pass
+// Validate.
+func EditValue(content []byte, key, value string) ([]byte, error) {
+	current, err := parse(content)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := Lookup(current, key); err != nil {
+		return nil, err
+	}
+	parts, _ := splitKey(key)
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("configuration file is empty")
+	}
+
+	var replacement yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &replacement); err != nil {
+		return nil, fmt.Errorf("invalid value %q: %w", value, err)
+	}
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str"}
+	if len(replacement.Content) > 0 {
+		node = replacement.Content[0]
+	}
+
+	parent := doc.Content[0]
+	for i, part := range parts {
+		last := i == len(parts)-1
+		switch parent.Kind {
+		case yaml.MappingNode:
+			child := mappingValue(parent, part)
+			if child == nil {
+				child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				if last {
+					child = node
+				}
+				parent.Content = append(parent.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, child)
+			} else if last {
+				node.LineComment = child.LineComment
+				*child = *node
+			} else if child.Kind == yaml.ScalarNode && child.Tag == "!!null" {
+				// An empty section such as "settings:" gets its first key
+				child.Kind, child.Tag, child.Value = yaml.MappingNode, "!!map", ""
+			}
+			parent = child
+		case yaml.SequenceNode:
+			index := sequenceIndex(parent, part)
+			if index < 0 {
+				return nil, fmt.Errorf("no entry %s in %s", part, strings.Join(parts[:i], "."))
+			}
+			if last {
+				node.LineComment = parent.Content[index].LineComment
+				*parent.Content[index] = *node
+			}
+			parent = parent.Content[index]
+		default:
+			return nil, fmt.Errorf("%s is a value, not a section", strings.Join(parts[:i], "."))
+		}
+	}
+
+	updated, err := encode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := parse(updated)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	if err := Validate(cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration after setting %s: %w", key, err)
+	}
+	return updated, nil
+}
+
+// SetValue sets a dotted configuration key in the configuration file at path
+// with EditValue. When backupDir is not empty the previous version of the file
+// is copied into a timestamped directory below it first, and the backup path
+// is returned.
+func SetValue(path, key, value, backupDir string) (string, error) {
+	if err := util.ValidatePath(path); err != nil {
+		return "", fmt.Errorf("invalid config path: %w", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+	updated, err := EditValue(content, key, value)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	var backup string
+	if backupDir != "" {
+		dir, err := newBackupDir(backupDir)
+		if err != nil {
+			return "", err
+		}
+		backup = filepath.Join(dir, filepath.Base(path))
+		if err := os.WriteFile(backup, content, info.Mode().Perm()); err != nil {
+			return "", fmt.Errorf("failed to back up config file: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(path, updated, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to write config file: %w", err)
+	}
+	return backup, nil
+}
+
+// newBackupDir creates a timestamped directory below backupDir, numbering it
+// when an earlier backup was made in the same second
+func newBackupDir(backupDir string) (string, error) {
+	if err := os.MkdirAll(backupDir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	base := filepath.Join(backupDir, time.Now().Format("20060102-150405"))
+	dir := base
+	for i := 1; ; i++ {
+		err := os.Mkdir(dir, 0750)
+		if err == nil {
+			return dir, nil
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		dir = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// encode writes a YAML document with the indentation of generated configs
+func encode(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// splitKey splits a dotted configuration key into its parts
+func splitKey(key string) ([]string, error) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("invalid configuration key: %q", key)
+		}
+	}
+	return parts, nil
+}
+
+// yamlField returns the field of a struct value decoded from the YAML key name
+func yamlField(value reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < value.NumField(); i++ {
+		tag := strings.Split(value.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		if tag == name {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// sliceIndex returns the position of a list entry addressed by position or
+// by the value of its Name field, or -1
+func sliceIndex(list reflect.Value, part string) int {
+	if index, err := strconv.Atoi(part); err == nil {
+		if index >= 0 && index < list.Len() {
+			return index
+		}
+		return -1
+	}
+	for i := 0; i < list.Len(); i++ {
+		entry := reflect.Indirect(list.Index(i))
+		if entry.Kind() != reflect.Struct {
+			continue
+		}
+		if name := entry.FieldByName("Name"); name.IsValid() && name.Kind() == reflect.String && name.String() == part {
+			return i
+		}
+	}
+	return -1
+}
+
+// sequenceIndex returns the position of a YAML sequence entry addressed by
+// position or by its name key, or -1
+func sequenceIndex(list *yaml.Node, part string) int {
+	if index, err := strconv.Atoi(part); err == nil {
+		if index >= 0 && index < len(list.Content) {
+			return index
+		}
+		return -1
+	}
+	for i, entry := range list.Content {
+		if name := mappingValue(entry, "name"); name != nil && name.Value == part {
+			return i
+		}
+	}
+	return -1
+}
+
+// mappingValue returns the value node for key in a YAML mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
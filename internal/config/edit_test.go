@@ -0,0 +1,186 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetSourceEnabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agents-config.yaml")
+	content := `version: "1.0"
+# Agent sources
+sources:
+  - name: community
+    enabled:  true   # reviewed 2024-05
+    type: github
+    repository: "owner/agents"
+  - name: local
+    type: local
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetSourceEnabled(path, "community", false); err != nil {
+		t.Fatalf("SetSourceEnabled failed: %v", err)
+	}
+	if err := SetSourceEnabled(path, "local", true); err != nil {
+		t.Fatalf("SetSourceEnabled failed: %v", err)
+	}
+
+	updated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the flags change; spacing, quoting and comments stay as written
+	want := `version: "1.0"
+# Agent sources
+sources:
+  - name: community
+    enabled:  false   # reviewed 2024-05
+    type: github
+    repository: "owner/agents"
+  - name: local
+    enabled: true
+    type: local
+`
+	if string(updated) != want {
+		t.Errorf("Unexpected updated config:\n%s", updated)
+	}
+
+	if err := SetSourceEnabled(path, "missing", false); err == nil {
+		t.Error("Expected error for unknown source")
+	}
+}
+
+const valueConfig = `version: "1.0"
+settings:
+  base_dir: .claude/agents
+  conflict_strategy: backup # keep replaced files
+sources:
+  - name: community
+    enabled: true
+    type: local
+    paths:
+      source: ./agents
+      target: .claude/agents
+metadata:
+  tracking_file: .claude/.installed-agents.json
+`
+
+func TestLookup(t *testing.T) {
+	cfg, err := parse([]byte(valueConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		want interface{}
+	}{
+		{"settings.base_dir", ".claude/agents"},
+		{"settings.concurrent_downloads", 3},
+		{"sources.community.enabled", true},
+		{"sources.0.paths.source", "./agents"},
+		{"settings.query.weights.name", nil},
+	}
+	for _, tt := range tests {
+		got, err := Lookup(cfg, tt.key)
+		if err != nil {
+			t.Errorf("Lookup(%s) failed: %v", tt.key, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Lookup(%s) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+
+	for _, key := range []string{"settings.bogus", "sources.missing.enabled", "settings.base_dir.x", "settings..base_dir"} {
+		if _, err := Lookup(cfg, key); err == nil {
+			t.Errorf("Expected error looking up %s", key)
+		}
+	}
+}
+
+func TestEditValue(t *testing.T) {
+	updated, err := EditValue([]byte(valueConfig), "settings.conflict_strategy", "merge")
+	if err != nil {
+		t.Fatalf("EditValue failed: %v", err)
+	}
+	if !strings.Contains(string(updated), "conflict_strategy: merge # keep replaced files") {
+		t.Errorf("Expected the value replaced and its comment kept:\n%s", updated)
+	}
+
+	// Missing sections are created
+	updated, err = EditValue(updated, "settings.query.weights.name", "5")
+	if err != nil {
+		t.Fatalf("EditValue failed: %v", err)
+	}
+	cfg, err := parse(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Settings.Query.Weights["name"] != 5 || cfg.Settings.ConflictStrategy != "merge" {
+		t.Errorf("Expected both edits in the configuration, got %+v", cfg.Settings)
+	}
+
+	updated, err = EditValue(updated, "sources.community.enabled", "false")
+	if err != nil {
+		t.Fatalf("EditValue failed: %v", err)
+	}
+	if !strings.Contains(string(updated), "enabled: false") {
+		t.Errorf("Expected the source disabled:\n%s", updated)
+	}
+
+	for key, value := range map[string]string{
+		"settings.conflict_strategy":    "sideways",
+		"settings.bogus":                "1",
+		"settings.concurrent_downloads": "many",
+		"sources.missing.enabled":       "true",
+	} {
+		if _, err := EditValue([]byte(valueConfig), key, value); err == nil {
+			t.Errorf("Expected error setting %s to %s", key, value)
+		}
+	}
+}
+
+func TestSetValue_BacksUpPreviousVersion(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agents-config.yaml")
+	if err := os.WriteFile(path, []byte(valueConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	backupDir := filepath.Join(dir, "backups", "config")
+
+	first, err := SetValue(path, "settings.conflict_strategy", "merge", backupDir)
+	if err != nil {
+		t.Fatalf("SetValue failed: %v", err)
+	}
+	second, err := SetValue(path, "settings.conflict_strategy", "skip", backupDir)
+	if err != nil {
+		t.Fatalf("SetValue failed: %v", err)
+	}
+	if first == second {
+		t.Fatalf("Expected each version backed up separately, got %s twice", first)
+	}
+
+	if content, _ := os.ReadFile(first); string(content) != valueConfig {
+		t.Errorf("Expected the original configuration backed up, got:\n%s", content)
+	}
+	if content, _ := os.ReadFile(second); !strings.Contains(string(content), "conflict_strategy: merge") {
+		t.Errorf("Expected the intermediate configuration backed up, got:\n%s", content)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the file mode kept, got %v (%v)", info.Mode(), err)
+	}
+
+	// A rejected value leaves the file alone
+	if _, err := SetValue(path, "settings.conflict_strategy", "sideways", backupDir); err == nil {
+		t.Error("Expected an invalid value rejected")
+	}
+	if content, _ := os.ReadFile(path); !strings.Contains(string(content), "conflict_strategy: skip") {
+		t.Errorf("Expected the configuration unchanged, got:\n%s", content)
+	}
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sourceSettings are the global settings that shape what a source installs
+type sourceSettings struct {
+	BaseDir            string        `yaml:"base_dir"`
+	DocsDir            string        `yaml:"docs_dir"`
+	ConflictStrategy   string        `yaml:"conflict_strategy"`
+	ProtectPreExisting bool          `yaml:"protect_pre_existing,omitempty"`
+	ReadonlyPaths      []string      `yaml:"readonly_paths,omitempty"`
+	Extensions         []string      `yaml:"extensions,omitempty"`
+	Limits             LimitsConfig  `yaml:"limits,omitempty"`
+	Licenses           LicensePolicy `yaml:"licenses,omitempty"`
+	Walk               WalkConfig    `yaml:"walk,omitempty"`
+}
+
+// SourceHash returns a sha256 digest of the effective configuration a source
+// is installed with: its entry and the settings that shape what it installs,
+// after variable substitution and defaults. Fields that only control how or
+// when a source is fetched and copied, such as auth, mirrors, timeouts, io and
+// enabled, are left out so changing them does not mark installations as stale.
+func (c *Config) SourceHash(source Source) string {
+	source.Enabled = false
+	source.DryRun = false
+	source.Watch = false
+	source.Auth = AuthConfig{}
+	source.Mirrors = nil
+	source.MirrorTimeout = 0
+	source.AgentTimeout = 0
+	source.Cache = CacheConfig{}
+	source.IO = IOConfig{}
+
+	effective := struct {
+		Settings sourceSettings `yaml:"settings"`
+		Source   Source         `yaml:"source"`
+	}{
+		Settings: sourceSettings{
+			BaseDir:            c.Settings.BaseDir,
+			DocsDir:            c.Settings.DocsDir,
+			ConflictStrategy:   c.Settings.ConflictStrategy,
+			ProtectPreExisting: c.Settings.ProtectPreExisting,
+			ReadonlyPaths:      c.Settings.ReadonlyPaths,
+			Extensions:         c.Settings.Query.Index.Extensions,
+			Limits:             c.Settings.Limits,
+			Licenses:           c.Settings.Licenses,
+			Walk:               c.Settings.Walk,
+		},
+		Source: source,
+	}
+
+	data, err := yaml.Marshal(effective)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
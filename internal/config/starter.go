@@ -0,0 +1,103 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"text/template"
+)
+
+// StarterSource is a popular community source offered in a starter configuration
+type StarterSource struct {
+	Name        string
+	Repository  string
+	SourcePath  string
+	Description string
+	Exclude     []string
+	Enabled     bool
+}
+
+// StarterSources are the community sources written by the first-run setup.
+// Only the first is enabled so the initial install stays small.
+var StarterSources = []StarterSource{
+	{
+		Name:        "awesome-claude-code-subagents",
+		Repository:  "VoltAgent/awesome-claude-code-subagents",
+		SourcePath:  "categories",
+		Description: "Curated subagents grouped by category",
+		Exclude:     []string{"README.md"},
+		Enabled:     true,
+	},
+	{
+		Name:        "wshobson-subagents-collection",
+		Repository:  "wshobson/agents",
+		SourcePath:  ".",
+		Description: "Production-ready development subagents",
+		Exclude:     []string{"README.md"},
+	},
+	{
+		Name:        "augmnt-agents",
+		Repository:  "augmnt/agents",
+		SourcePath:  ".",
+		Description: "General purpose engineering agents",
+		Exclude:     []string{"README.md"},
+	},
+}
+
+var starterTemplate = template.Must(template.New("starter").Parse(`version: "1.0"
+
+settings:
+  base_dir: .claude/agents
+  docs_dir: docs
+  conflict_strategy: backup  # Options: backup, overwrite, skip, merge
+  backup_dir: .claude/backups
+  log_level: info  # Options: debug, info, warn, error
+  concurrent_downloads: 3
+  timeout: 300s
+
+sources:
+{{- range .}}
+  # {{.Description}}
+  - name: {{.Name}}
+    enabled: {{.Enabled}}
+    type: github
+    repository: {{.Repository}}
+    branch: main
+    paths:
+      source: {{.SourcePath}}
+      target: ${settings.base_dir}
+    filters:
+      include:
+        extensions: [".md"]
+{{- if .Exclude}}
+      exclude:
+        patterns: [{{range $i, $p := .Exclude}}{{if $i}}, {{end}}"{{$p}}"{{end}}]
+{{- end}}
+{{end}}
+metadata:
+  tracking_file: .claude/.installed-agents.json
+  log_file: .claude/installation.log
+`))
+
+// StarterConfig renders a starter configuration file listing the given sources
+func StarterConfig(sources []StarterSource) []byte {
+	var buf bytes.Buffer
+	// The template only ranges over plain string and bool fields, so it cannot fail
+	_ = starterTemplate.Execute(&buf, sources)
+	return buf.Bytes()
+}
+
+// IsFirstRun reports whether there is no configuration at configPath and
+// nothing has been installed in the default locations yet
+func IsFirstRun(configPath string) bool {
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		return false
+	}
+
+	var defaults Config
+	applyDefaults(&defaults)
+	if _, err := os.Stat(defaults.Metadata.TrackingFile); !os.IsNotExist(err) {
+		return false
+	}
+	entries, err := os.ReadDir(defaults.Settings.BaseDir)
+	return err != nil || len(entries) == 0
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStarterConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agents-config.yaml")
+	if err := os.WriteFile(path, StarterConfig(StarterSources), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Starter config does not load: %v", err)
+	}
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("Starter config is invalid: %v", err)
+	}
+
+	if len(cfg.Sources) != len(StarterSources) {
+		t.Fatalf("Expected %d sources, got %d", len(StarterSources), len(cfg.Sources))
+	}
+	for i, source := range cfg.Sources {
+		want := StarterSources[i]
+		if source.Name != want.Name || source.Repository != want.Repository || source.Enabled != want.Enabled {
+			t.Errorf("Source %d = %s/%s enabled=%v, want %s/%s enabled=%v",
+				i, source.Name, source.Repository, source.Enabled, want.Name, want.Repository, want.Enabled)
+		}
+		if source.Paths.Target != cfg.Settings.BaseDir {
+			t.Errorf("Source %s target = %s, want %s", source.Name, source.Paths.Target, cfg.Settings.BaseDir)
+		}
+	}
+}
+
+func TestIsFirstRun(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if !IsFirstRun("agents-config.yaml") {
+		t.Error("Expected first run in an empty directory")
+	}
+
+	if err := os.MkdirAll(".claude/agents", 0750); err != nil {
+		t.Fatal(err)
+	}
+	if !IsFirstRun("agents-config.yaml") {
+		t.Error("Expected first run with an empty agents directory")
+	}
+
+	if err := os.WriteFile(".claude/agents/reviewer.md", []byte("# reviewer"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if IsFirstRun("agents-config.yaml") {
+		t.Error("Installed agents should not be treated as a first run")
+	}
+
+	if err := os.Remove(".claude/agents/reviewer.md"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("agents-config.yaml", []byte("version: \"1.0\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if IsFirstRun("agents-config.yaml") {
+		t.Error("An existing configuration should not be treated as a first run")
+	}
+}
@@ -5,9 +5,13 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 )
 
 // Validate checks if the configuration is valid
@@ -44,6 +48,10 @@ func Validate(cfg *Config) error {
 		sourceNames[source.Name] = true
 	}
 
+	if err := validateCollections(cfg.Collections); err != nil {
+		return fmt.Errorf("invalid collections: %w", err)
+	}
+
 	// Validate metadata
 	if err := validateMetadata(&cfg.Metadata); err != nil {
 		return fmt.Errorf("invalid metadata: %w", err)
@@ -77,6 +85,92 @@ func validateSettings(settings *Settings) error {
 		return fmt.Errorf("timeout cannot be negative")
 	}
 
+	// Validate watch pacing
+	if settings.Watch.Debounce < 0 || settings.Watch.MinInterval < 0 || settings.Watch.PollInterval < 0 {
+		return fmt.Errorf("watch.debounce, watch.min_interval and watch.poll_interval cannot be negative")
+	}
+
+	if err := validateIO(settings.IO); err != nil {
+		return fmt.Errorf("invalid io: %w", err)
+	}
+
+	// Validate agent file extensions
+	for _, ext := range settings.Query.Index.Extensions {
+		if !strings.HasPrefix(ext, ".") || len(ext) < 2 {
+			return fmt.Errorf("invalid agent file extension: %q (must start with '.')", ext)
+		}
+	}
+
+	// Validate index roots
+	for _, root := range settings.Query.Index.Roots {
+		if root.Path == "" {
+			return fmt.Errorf("index root path is required")
+		}
+		if root.Scope != "user" && root.Scope != "project" {
+			return fmt.Errorf("invalid scope for index root %s: %q (must be user or project)", root.Path, root.Scope)
+		}
+	}
+
+	// Validate file size limits
+	if settings.Limits.MaxAgentFileKB < 0 {
+		return fmt.Errorf("limits.max_agent_file_kb cannot be negative")
+	}
+	if settings.Walk.MaxDepth < -1 {
+		return fmt.Errorf("walk.max_depth must be positive, or -1 for no limit")
+	}
+	validActions := []string{"skip", "warn", "fail"}
+	if settings.Limits.OnExceed != "" && !contains(validActions, settings.Limits.OnExceed) {
+		return fmt.Errorf("invalid limits.on_exceed: %s (must be one of: %s)",
+			settings.Limits.OnExceed, strings.Join(validActions, ", "))
+	}
+	if settings.Licenses.OnViolation != "" && !contains(validActions, settings.Licenses.OnViolation) {
+		return fmt.Errorf("invalid licenses.on_violation: %s (must be one of: %s)",
+			settings.Licenses.OnViolation, strings.Join(validActions, ", "))
+	}
+	for _, license := range settings.Licenses.Allowed {
+		if strings.TrimSpace(license) == "" {
+			return fmt.Errorf("licenses.allowed cannot contain empty entries")
+		}
+	}
+
+	if err := validateReadonlyPaths(settings.ReadonlyPaths); err != nil {
+		return err
+	}
+
+	// node_exporter's textfile collector only reads *.prom files
+	if settings.Metrics.Textfile != "" && filepath.Ext(settings.Metrics.Textfile) != ".prom" {
+		return fmt.Errorf("metrics.textfile must end in .prom: %s", settings.Metrics.Textfile)
+	}
+
+	validMarketplaceModes := []string{"auto", "api", "browser"}
+	if settings.Marketplace.Mode != "" && !contains(validMarketplaceModes, settings.Marketplace.Mode) {
+		return fmt.Errorf("invalid marketplace.mode: %s (must be one of: %s)",
+			settings.Marketplace.Mode, strings.Join(validMarketplaceModes, ", "))
+	}
+
+	if settings.Query.Cache.TTL < 0 {
+		return fmt.Errorf("query.cache.ttl cannot be negative")
+	}
+	if _, err := settings.Query.Cache.MaxBytes(); err != nil {
+		return err
+	}
+
+	// Validate relevance weights
+	for field, weight := range settings.Query.Weights {
+		if field != "name" && field != "description" && field != "content" {
+			return fmt.Errorf("invalid query.weights field: %s (must be name, description or content)", field)
+		}
+		if weight < 0 {
+			return fmt.Errorf("query.weights.%s cannot be negative", field)
+		}
+	}
+
+	// Validate parser mode
+	if settings.Query.ParserMode != "" && !contains(parser.ParseModes, settings.Query.ParserMode) {
+		return fmt.Errorf("invalid parser mode: %s (must be one of: %s)",
+			settings.Query.ParserMode, strings.Join(parser.ParseModes, ", "))
+	}
+
 	return nil
 }
 
@@ -96,6 +190,10 @@ func validateSource(source *Source) error {
 		return err
 	}
 
+	if err := validateMirrors(source); err != nil {
+		return err
+	}
+
 	// Validate other source components
 	return validateSourceComponents(source)
 }
@@ -107,12 +205,25 @@ func validateSourceBasics(source *Source) error {
 	}
 
 	// Validate source type
-	validTypes := []string{"github", "git", "local", "subagents"}
+	validTypes := []string{"github", "github-release", "git", "gitlab", "archive", "local", "subagents"}
 	if !contains(validTypes, source.Type) {
 		return fmt.Errorf("invalid source type: %s (must be one of: %s)",
 			source.Type, strings.Join(validTypes, ", "))
 	}
 
+	// Validate artifact kind
+	validKinds := []string{KindAgent, KindOutputStyle, KindStatusline}
+	if source.Kind != "" && !contains(validKinds, source.Kind) {
+		return fmt.Errorf("invalid kind: %s (must be one of: %s)",
+			source.Kind, strings.Join(validKinds, ", "))
+	}
+	if source.Type == "subagents" && source.ArtifactKind() != KindAgent {
+		return fmt.Errorf("subagents sources only install agents")
+	}
+	if source.AgentTimeout < 0 {
+		return fmt.Errorf("agent_timeout cannot be negative")
+	}
+
 	// Validate paths
 	if source.Paths.Target == "" {
 		return fmt.Errorf("target path is required")
@@ -131,6 +242,50 @@ func validateSourceType(source *Source) error {
 		if !regexp.MustCompile(`^[^/]+/[^/]+$`).MatchString(source.Repository) {
 			return fmt.Errorf("invalid github repository format (expected: owner/repo)")
 		}
+		if source.Prefer != "" && source.Prefer != "gh" && source.Prefer != "go-git" {
+			return fmt.Errorf("invalid prefer value: %s (must be gh or go-git)", source.Prefer)
+		}
+		if source.Prefer == "gh" && source.Auth.HasHTTPOptions() {
+			return fmt.Errorf("auth headers and user_agent are not supported with prefer: gh")
+		}
+
+	case "github-release":
+		if source.Repository == "" {
+			return fmt.Errorf("repository is required for github-release source")
+		}
+		if !regexp.MustCompile(`^[^/]+/[^/]+$`).MatchString(source.Repository) {
+			return fmt.Errorf("invalid github repository format (expected: owner/repo)")
+		}
+		if source.Release.Asset == "" {
+			return fmt.Errorf("release.asset is required for github-release source")
+		}
+		if _, err := filepath.Match(source.Release.Asset, ""); err != nil {
+			return fmt.Errorf("invalid release.asset pattern: %w", err)
+		}
+		if !isReleaseArchive(source.Release.Asset) {
+			return fmt.Errorf("release.asset must be a .zip, .tar.gz or .tgz archive: %s", source.Release.Asset)
+		}
+
+	case "archive":
+		if source.URL == "" {
+			return fmt.Errorf("url is required for archive source")
+		}
+		parsed, err := url.Parse(source.URL)
+		if err != nil {
+			return fmt.Errorf("invalid archive URL: %w", err)
+		}
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return fmt.Errorf("archive URL must use http or https: %s", source.URL)
+		}
+		if source.ArchiveFormat() == "" {
+			return fmt.Errorf("cannot tell the archive format of %s; set archive.format to zip or tar.gz", source.URL)
+		}
+		if source.Archive.SHA256 != "" && !sha256Digest.MatchString(source.Archive.SHA256) {
+			return fmt.Errorf("archive.sha256 must be a hex-encoded sha256 digest")
+		}
+		if source.Auth.Method == "ssh" {
+			return fmt.Errorf("ssh auth is not supported for archive sources")
+		}
 
 	case "git":
 		if source.URL == "" {
@@ -141,21 +296,96 @@ func validateSourceType(source *Source) error {
 			return fmt.Errorf("invalid git URL: %w", err)
 		}
 
+	case "gitlab":
+		if source.Repository == "" {
+			return fmt.Errorf("repository is required for gitlab source")
+		}
+		// Projects may be nested in any number of subgroups
+		if !regexp.MustCompile(`^[^/]+(/[^/]+)+$`).MatchString(source.Repository) {
+			return fmt.Errorf("invalid gitlab repository format (expected: group/project or group/subgroup/project)")
+		}
+		if source.GitLabURL != "" {
+			parsed, err := url.Parse(source.GitLabURL)
+			if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+				return fmt.Errorf("invalid gitlab_url: %s (expected an http or https URL)", source.GitLabURL)
+			}
+		}
+
 	case "local":
 		if source.Paths.Source == "" {
 			return fmt.Errorf("source path is required for local source")
 		}
 	}
 
+	if source.Clone.Depth < 0 {
+		return fmt.Errorf("clone.depth cannot be negative")
+	}
+	if (source.Clone.Depth > 0 || source.Clone.Sparse) && source.Type != "git" && source.Type != "github" && source.Type != "gitlab" {
+		return fmt.Errorf("clone options are only supported for git, github and gitlab sources")
+	}
+	if source.GitLabURL != "" && source.Type != "gitlab" {
+		return fmt.Errorf("gitlab_url is only supported for gitlab sources")
+	}
+	if source.Tag != "" {
+		if source.Type != "git" && source.Type != "github" && source.Type != "gitlab" {
+			return fmt.Errorf("tag is only supported for git, github and gitlab sources")
+		}
+		if source.Branch != "" {
+			return fmt.Errorf("branch and tag cannot both be set")
+		}
+	}
+
+	return nil
+}
+
+// sha256Digest matches a hex-encoded sha256 digest
+var sha256Digest = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// isReleaseArchive reports whether a release asset name is a supported archive
+func isReleaseArchive(name string) bool {
+	for _, ext := range []string{".zip", ".tar.gz", ".tgz", "*"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateMirrors checks that mirrors are only set on git sources and are valid URLs
+func validateMirrors(source *Source) error {
+	if len(source.Mirrors) == 0 {
+		return nil
+	}
+	if source.Type != "git" && source.Type != "github" && source.Type != "gitlab" {
+		return fmt.Errorf("mirrors are only supported for git, github and gitlab sources")
+	}
+	if source.MirrorTimeout < 0 {
+		return fmt.Errorf("mirror_timeout cannot be negative")
+	}
+	for _, mirror := range source.Mirrors {
+		if mirror.URL == "" {
+			return fmt.Errorf("mirror url is required")
+		}
+		if _, err := url.Parse(mirror.URL); err != nil {
+			return fmt.Errorf("invalid mirror URL %s: %w", mirror.URL, err)
+		}
+		if mirror.Timeout < 0 {
+			return fmt.Errorf("mirror timeout cannot be negative: %s", mirror.URL)
+		}
+	}
 	return nil
 }
 
 func validateSourceAuth(source *Source) error {
+	if err := validateAuthHeaders(&source.Auth); err != nil {
+		return err
+	}
+
 	if source.Auth.Method == "" {
 		return nil
 	}
 
-	validMethods := []string{"token", "ssh"}
+	validMethods := []string{"token", "ssh", "keychain"}
 	if !contains(validMethods, source.Auth.Method) {
 		return fmt.Errorf("invalid auth method: %s", source.Auth.Method)
 	}
@@ -168,6 +398,39 @@ func validateSourceAuth(source *Source) error {
 		return fmt.Errorf("ssh_key is required for ssh auth")
 	}
 
+	if source.Auth.Method == "keychain" {
+		if source.Type != "github" && source.Type != "git" && source.Type != "github-release" && source.Type != "gitlab" && source.Type != "archive" {
+			return fmt.Errorf("keychain auth is only supported for git, github, github-release, gitlab and archive sources")
+		}
+		if source.Auth.Helper != "" && source.Auth.Helper != "system" && source.Auth.Helper != "git" {
+			return fmt.Errorf("invalid auth helper: %s (must be system or git)", source.Auth.Helper)
+		}
+	}
+
+	return nil
+}
+
+// headerNamePattern matches a valid HTTP header field name
+var headerNamePattern = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// unresolvedEnvPattern matches an ${env.NAME} reference left unresolved by an unset variable
+var unresolvedEnvPattern = regexp.MustCompile(`\$\{env\.([^}]+)\}`)
+
+func validateAuthHeaders(auth *AuthConfig) error {
+	for name, value := range auth.Headers {
+		if !headerNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid header name: %q", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("header %s contains a line break", name)
+		}
+		if match := unresolvedEnvPattern.FindStringSubmatch(value); match != nil {
+			return fmt.Errorf("header %s references unset environment variable %s", name, match[1])
+		}
+	}
+	if strings.ContainsAny(auth.UserAgent, "\r\n") {
+		return fmt.Errorf("user_agent contains a line break")
+	}
 	return nil
 }
 
@@ -177,6 +440,10 @@ func validateSourceComponents(source *Source) error {
 		return fmt.Errorf("invalid filters: %w", err)
 	}
 
+	if source.Filters.HasMetadataFilters() && source.Type != "subagents" {
+		return fmt.Errorf("invalid filters: min_rating, min_downloads and tags_any only apply to subagents sources")
+	}
+
 	// Validate transformations
 	for i, transform := range source.Transformations {
 		if err := validateTransformation(&transform); err != nil {
@@ -191,6 +458,14 @@ func validateSourceComponents(source *Source) error {
 		}
 	}
 
+	if err := validateIO(source.IO); err != nil {
+		return fmt.Errorf("invalid io: %w", err)
+	}
+
+	if err := validateReadonlyPaths(source.ReadonlyPaths); err != nil {
+		return err
+	}
+
 	// Validate conflict strategy override
 	if source.ConflictStrategy != "" {
 		validStrategies := []string{"backup", "overwrite", "skip", "merge"}
@@ -202,6 +477,34 @@ func validateSourceComponents(source *Source) error {
 	return nil
 }
 
+func validateReadonlyPaths(patterns []string) error {
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("readonly_paths cannot contain empty entries")
+		}
+		if _, err := path.Match(filepath.ToSlash(pattern), "test"); err != nil {
+			return fmt.Errorf("invalid readonly_paths pattern '%s': %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// MaxIOConcurrency bounds io.concurrency
+const MaxIOConcurrency = 32
+
+func validateIO(io IOConfig) error {
+	if io.Concurrency < 0 || io.Concurrency > MaxIOConcurrency {
+		return fmt.Errorf("concurrency must be between 0 and %d", MaxIOConcurrency)
+	}
+	if io.FilesPerSecond < 0 {
+		return fmt.Errorf("files_per_second cannot be negative")
+	}
+	if io.MBPerSecond < 0 {
+		return fmt.Errorf("mb_per_second cannot be negative")
+	}
+	return nil
+}
+
 func validateFilters(filters *FilterConfig) error {
 	// Validate regex patterns
 	for _, pattern := range filters.Include.Regex {
@@ -223,6 +526,19 @@ func validateFilters(filters *FilterConfig) error {
 		}
 	}
 
+	// Validate marketplace metadata filters
+	if filters.MinRating < 0 || filters.MinRating > 5 {
+		return fmt.Errorf("min_rating must be between 0 and 5")
+	}
+	if filters.MinDownloads < 0 {
+		return fmt.Errorf("min_downloads cannot be negative")
+	}
+	for _, tag := range filters.TagsAny {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("tags_any cannot contain empty entries")
+		}
+	}
+
 	return nil
 }
 
@@ -289,6 +605,28 @@ func validatePostInstall(action *PostInstall) error {
 	return nil
 }
 
+func validateCollections(collections map[string][]string) error {
+	for name, agents := range collections {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("collection name cannot be empty")
+		}
+		if len(agents) == 0 {
+			return fmt.Errorf("collection '%s' lists no agents", name)
+		}
+		seen := make(map[string]bool, len(agents))
+		for _, agent := range agents {
+			if strings.TrimSpace(agent) == "" {
+				return fmt.Errorf("collection '%s' has an empty agent name", name)
+			}
+			if seen[agent] {
+				return fmt.Errorf("collection '%s' lists %s twice", name, agent)
+			}
+			seen[agent] = true
+		}
+	}
+	return nil
+}
+
 func validateMetadata(metadata *Metadata) error {
 	if metadata.TrackingFile == "" {
 		return fmt.Errorf("tracking_file is required")
@@ -327,7 +665,7 @@ func commandExists(cmd string) bool {
 }
 
 func expandPath(path string) string {
-	if strings.HasPrefix(path, "~/") {
+	if util.IsHomePath(path) {
 		home, err := os.UserHomeDir()
 		if err != nil {
 			// Return original path if home directory can't be determined
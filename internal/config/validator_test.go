@@ -1,7 +1,12 @@
 package config
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestValidate(t *testing.T) {
@@ -82,6 +87,56 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid file size limit action",
+			config: &Config{
+				Version: "1.0",
+				Settings: Settings{
+					BaseDir:             "/tmp/agents",
+					ConflictStrategy:    "backup",
+					LogLevel:            "info",
+					ConcurrentDownloads: 3,
+					Limits:              LimitsConfig{MaxAgentFileKB: 64, OnExceed: "truncate"},
+				},
+				Sources: []Source{
+					{
+						Name:       "test",
+						Type:       "github",
+						Repository: "user/repo",
+						Paths: PathConfig{
+							Source: "src",
+							Target: "/tmp/test",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid license violation action",
+			config: &Config{
+				Version: "1.0",
+				Settings: Settings{
+					BaseDir:             "/tmp/agents",
+					ConflictStrategy:    "backup",
+					LogLevel:            "info",
+					ConcurrentDownloads: 3,
+					Licenses:            LicensePolicy{Allowed: []string{"MIT"}, OnViolation: "ignore"},
+				},
+				Sources: []Source{
+					{
+						Name:       "test",
+						Type:       "github",
+						Repository: "user/repo",
+						Paths: PathConfig{
+							Source: "src",
+							Target: "/tmp/test",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "empty source name",
 			config: &Config{
@@ -133,6 +188,46 @@ func TestValidateSource(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid output style source",
+			source: Source{
+				Name:       "styles",
+				Type:       "github",
+				Kind:       KindOutputStyle,
+				Repository: "user/styles",
+				Paths: PathConfig{
+					Source: "styles",
+					Target: ".claude/output-styles",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid kind",
+			source: Source{
+				Name:       "test",
+				Type:       "github",
+				Kind:       "theme",
+				Repository: "user/repo",
+				Paths: PathConfig{
+					Source: "src",
+					Target: "/tmp/test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "statusline from marketplace",
+			source: Source{
+				Name: "test",
+				Type: "subagents",
+				Kind: KindStatusline,
+				Paths: PathConfig{
+					Target: "/tmp/test",
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "valid git source",
 			source: Source{
@@ -146,6 +241,45 @@ func TestValidateSource(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid github-release source",
+			source: Source{
+				Name:       "test",
+				Type:       "github-release",
+				Repository: "user/repo",
+				Release:    ReleaseConfig{Asset: "agents-*.tar.gz"},
+				Paths: PathConfig{
+					Source: "agents",
+					Target: "/tmp/test",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "github-release source without asset",
+			source: Source{
+				Name:       "test",
+				Type:       "github-release",
+				Repository: "user/repo",
+				Paths: PathConfig{
+					Target: "/tmp/test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "github-release source with unsupported archive",
+			source: Source{
+				Name:       "test",
+				Type:       "github-release",
+				Repository: "user/repo",
+				Release:    ReleaseConfig{Asset: "agents.rar"},
+				Paths: PathConfig{
+					Target: "/tmp/test",
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "valid local source",
 			source: Source{
@@ -205,6 +339,66 @@ func TestValidateSource(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid custom headers",
+			source: Source{
+				Name: "test",
+				Type: "git",
+				URL:  "https://git.example.com/repo.git",
+				Auth: AuthConfig{
+					Headers:   map[string]string{"X-Api-Key": "secret"},
+					UserAgent: "agent-manager-ci/1.0",
+				},
+				Paths: PathConfig{
+					Source: "src",
+					Target: "/tmp/test",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid header name",
+			source: Source{
+				Name: "test",
+				Type: "git",
+				URL:  "https://git.example.com/repo.git",
+				Auth: AuthConfig{Headers: map[string]string{"X Api Key": "secret"}},
+				Paths: PathConfig{
+					Source: "src",
+					Target: "/tmp/test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "header referencing unset variable",
+			source: Source{
+				Name: "test",
+				Type: "git",
+				URL:  "https://git.example.com/repo.git",
+				Auth: AuthConfig{Headers: map[string]string{"X-Api-Key": "${env.UNSET_HEADER_TOKEN}"}},
+				Paths: PathConfig{
+					Source: "src",
+					Target: "/tmp/test",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "headers with gh backend",
+			source: Source{
+				Name:       "test",
+				Type:       "github",
+				Repository: "user/repo",
+				Prefer:     "gh",
+				Auth:       AuthConfig{UserAgent: "agent-manager-ci/1.0"},
+				Paths: PathConfig{
+					Source: "src",
+					Target: "/tmp/test",
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing target path",
 			source: Source{
@@ -217,6 +411,220 @@ func TestValidateSource(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "git source with mirrors",
+			source: Source{
+				Name:    "mirrored",
+				Type:    "git",
+				URL:     "https://git.example.com/agents.git",
+				Mirrors: []Mirror{{URL: "https://mirror.example.com/agents.git", Timeout: time.Minute}},
+				Paths:   PathConfig{Source: "src", Target: "/tmp/test"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "mirrors on local source",
+			source: Source{
+				Name:    "local",
+				Type:    "local",
+				Mirrors: []Mirror{{URL: "https://mirror.example.com/agents.git"}},
+				Paths:   PathConfig{Source: "src", Target: "/tmp/test"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "github source with keychain auth",
+			source: Source{
+				Name:       "private",
+				Type:       "github",
+				Repository: "user/repo",
+				Auth:       AuthConfig{Method: "keychain", Helper: "git"},
+				Paths:      PathConfig{Source: "src", Target: "/tmp/test"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "keychain auth with unknown helper",
+			source: Source{
+				Name:       "private",
+				Type:       "github",
+				Repository: "user/repo",
+				Auth:       AuthConfig{Method: "keychain", Helper: "vault"},
+				Paths:      PathConfig{Source: "src", Target: "/tmp/test"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative agent timeout",
+			source: Source{
+				Name:         "marketplace",
+				Type:         "subagents",
+				AgentTimeout: -time.Second,
+				Paths:        PathConfig{Target: "/tmp/test"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "marketplace metadata filters",
+			source: Source{
+				Name:    "marketplace",
+				Type:    "subagents",
+				Filters: FilterConfig{MinRating: 4, MinDownloads: 100, TagsAny: []string{"devops"}},
+				Paths:   PathConfig{Target: "/tmp/test"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "min_rating out of range",
+			source: Source{
+				Name:    "marketplace",
+				Type:    "subagents",
+				Filters: FilterConfig{MinRating: 6},
+				Paths:   PathConfig{Target: "/tmp/test"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "gitlab project in a subgroup",
+			source: Source{
+				Name:       "gitlab",
+				Type:       "gitlab",
+				Repository: "group/subgroup/agents",
+				Tag:        "v1.2.0",
+				GitLabURL:  "https://gitlab.example.com",
+				Auth:       AuthConfig{Method: "token", TokenEnv: "CI_JOB_TOKEN"},
+				Paths:      PathConfig{Source: "src", Target: "/tmp/test"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "gitlab repository without a group",
+			source: Source{
+				Name:       "gitlab",
+				Type:       "gitlab",
+				Repository: "agents",
+				Paths:      PathConfig{Source: "src", Target: "/tmp/test"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "gitlab_url without scheme",
+			source: Source{
+				Name:       "gitlab",
+				Type:       "gitlab",
+				Repository: "group/agents",
+				GitLabURL:  "gitlab.example.com",
+				Paths:      PathConfig{Source: "src", Target: "/tmp/test"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "branch and tag",
+			source: Source{
+				Name:       "gitlab",
+				Type:       "gitlab",
+				Repository: "group/agents",
+				Branch:     "main",
+				Tag:        "v1.2.0",
+				Paths:      PathConfig{Source: "src", Target: "/tmp/test"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "tag on a local source",
+			source: Source{
+				Name:  "test",
+				Type:  "local",
+				Tag:   "v1.2.0",
+				Paths: PathConfig{Source: "src", Target: "/tmp/test"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "shallow sparse clone",
+			source: Source{
+				Name:       "test",
+				Type:       "github",
+				Repository: "user/repo",
+				Clone:      CloneConfig{Depth: 1, Sparse: true},
+				Paths:      PathConfig{Source: "src", Target: "/tmp/test"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative clone depth",
+			source: Source{
+				Name:  "test",
+				Type:  "git",
+				URL:   "https://example.com/repo.git",
+				Clone: CloneConfig{Depth: -1},
+				Paths: PathConfig{Source: "src", Target: "/tmp/test"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "archive source",
+			source: Source{
+				Name:    "test",
+				Type:    "archive",
+				URL:     "https://example.com/agents.tar.gz",
+				Archive: ArchiveConfig{SHA256: strings.Repeat("a", 64)},
+				Paths:   PathConfig{Target: "/tmp/test"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "archive source without a known format",
+			source: Source{
+				Name:  "test",
+				Type:  "archive",
+				URL:   "https://example.com/download?id=1",
+				Paths: PathConfig{Target: "/tmp/test"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "archive source over ftp",
+			source: Source{
+				Name:  "test",
+				Type:  "archive",
+				URL:   "ftp://example.com/agents.zip",
+				Paths: PathConfig{Target: "/tmp/test"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "archive source with a malformed checksum",
+			source: Source{
+				Name:    "test",
+				Type:    "archive",
+				URL:     "https://example.com/agents.zip",
+				Archive: ArchiveConfig{SHA256: "abc"},
+				Paths:   PathConfig{Target: "/tmp/test"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "clone options on a local source",
+			source: Source{
+				Name:  "test",
+				Type:  "local",
+				Clone: CloneConfig{Sparse: true},
+				Paths: PathConfig{Source: "src", Target: "/tmp/test"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "metadata filters on a git source",
+			source: Source{
+				Name:       "test",
+				Type:       "github",
+				Repository: "user/repo",
+				Filters:    FilterConfig{MinDownloads: 100},
+				Paths:      PathConfig{Source: "src", Target: "/tmp/test"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -229,6 +637,138 @@ func TestValidateSource(t *testing.T) {
 	}
 }
 
+func TestValidateCollections(t *testing.T) {
+	tests := []struct {
+		name        string
+		collections map[string][]string
+		wantErr     bool
+	}{
+		{"none", nil, false},
+		{"valid", map[string][]string{"review-kit": {"code-reviewer", "security-auditor"}}, false},
+		{"empty name", map[string][]string{" ": {"code-reviewer"}}, true},
+		{"no agents", map[string][]string{"review-kit": {}}, true},
+		{"empty agent", map[string][]string{"review-kit": {"code-reviewer", ""}}, true},
+		{"duplicate agent", map[string][]string{"review-kit": {"code-reviewer", "code-reviewer"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCollections(tt.collections)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateCollections() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	cfg := &Config{Collections: map[string][]string{"review-kit": {"code-reviewer"}, "docs": {"writer"}}}
+	if agents, err := cfg.Collection("review-kit"); err != nil || len(agents) != 1 || agents[0] != "code-reviewer" {
+		t.Errorf("Collection(review-kit) = %v, %v", agents, err)
+	}
+	if _, err := cfg.Collection("missing"); err == nil || !strings.Contains(err.Error(), "defined: docs, review-kit") {
+		t.Errorf("Expected an unknown collection error listing the defined ones, got %v", err)
+	}
+}
+
+func TestValidateIO(t *testing.T) {
+	tests := []struct {
+		name    string
+		io      IOConfig
+		wantErr bool
+	}{
+		{"unset", IOConfig{}, false},
+		{"valid", IOConfig{Concurrency: 4, FilesPerSecond: 50, MBPerSecond: 2.5}, false},
+		{"negative concurrency", IOConfig{Concurrency: -1}, true},
+		{"too many copies", IOConfig{Concurrency: MaxIOConcurrency + 1}, true},
+		{"negative file rate", IOConfig{FilesPerSecond: -1}, true},
+		{"negative byte rate", IOConfig{MBPerSecond: -0.5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateIO(tt.io)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateIO() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	global := IOConfig{Concurrency: 2, FilesPerSecond: 10, MBPerSecond: 1}
+	want := IOConfig{Concurrency: 8, FilesPerSecond: 10, MBPerSecond: 1}
+	if got := global.Override(IOConfig{Concurrency: 8}); got != want {
+		t.Errorf("Override() = %+v, want %+v", got, want)
+	}
+}
+
+func TestQueryCacheMaxBytes(t *testing.T) {
+	tests := []struct {
+		size    string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"4096", 4096, false},
+		{"512B", 512, false},
+		{"64KB", 64 << 10, false},
+		{"100MB", 100 << 20, false},
+		{"1 gb", 1 << 30, false},
+		{"lots", 0, true},
+		{"-1MB", 0, true},
+		{"1.5MB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			got, err := QueryCacheConfig{MaxSize: tt.size}.MaxBytes()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MaxBytes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("MaxBytes() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceArchiveFormat(t *testing.T) {
+	tests := []struct {
+		url    string
+		format string
+		want   string
+	}{
+		{"https://example.com/agents.zip", "", "zip"},
+		{"https://example.com/agents-1.0.TAR.GZ?token=x", "", "tar.gz"},
+		{"https://example.com/agents.tgz", "", "tar.gz"},
+		{"https://example.com/download?id=3", "", ""},
+		{"https://example.com/download?id=3", "zip", "zip"},
+		{"https://example.com/agents.zip", "tgz", "tar.gz"},
+		{"https://example.com/agents.zip", "rar", ""},
+	}
+	for _, tt := range tests {
+		source := Source{URL: tt.url, Archive: ArchiveConfig{Format: tt.format}}
+		if got := source.ArchiveFormat(); got != tt.want {
+			t.Errorf("ArchiveFormat(%s, %q) = %q, want %q", tt.url, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestMirrorUnmarshal(t *testing.T) {
+	var source Source
+	content := "name: mirrored\nmirrors:\n  - https://a.example.com/agents.git\n  - url: https://b.example.com/agents.git\n    timeout: 30s\n"
+	if err := yaml.Unmarshal([]byte(content), &source); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := []Mirror{{URL: "https://a.example.com/agents.git"}, {URL: "https://b.example.com/agents.git", Timeout: 30 * time.Second}}
+	if len(source.Mirrors) != 2 || source.Mirrors[0] != want[0] || source.Mirrors[1] != want[1] {
+		t.Errorf("Mirrors = %+v, want %+v", source.Mirrors, want)
+	}
+	if got := source.AttemptTimeout(&source.Mirrors[1]); got != 30*time.Second {
+		t.Errorf("AttemptTimeout(mirror) = %v, want 30s", got)
+	}
+	if got := source.AttemptTimeout(nil); got != DefaultMirrorTimeout {
+		t.Errorf("AttemptTimeout(nil) = %v, want %v", got, DefaultMirrorTimeout)
+	}
+}
+
 func TestCommandExists(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -285,3 +825,35 @@ func TestExpandPath(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchReadonly(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "project")
+	tests := []struct {
+		patterns []string
+		path     string
+		want     string
+	}{
+		{[]string{"CLAUDE.md"}, filepath.Join(root, "CLAUDE.md"), "CLAUDE.md"},
+		{[]string{"CLAUDE.md"}, filepath.Join(root, "docs", "CLAUDE.md"), "CLAUDE.md"},
+		{[]string{"CLAUDE.md"}, filepath.Join(root, "CLAUDE.md.bak"), ""},
+		{[]string{"*.json"}, filepath.Join(root, ".claude", "settings.json"), "*.json"},
+		{[]string{".claude/settings.json"}, filepath.Join(root, ".claude", "settings.json"), ".claude/settings.json"},
+		{[]string{".claude/settings.json"}, filepath.Join(root, "settings.json"), ""},
+		{[]string{filepath.Join(root, "*.md")}, filepath.Join(root, "README.md"), filepath.Join(root, "*.md")},
+		{[]string{filepath.Join(root, "*.md")}, filepath.Join(root, "docs", "README.md"), ""},
+		{nil, filepath.Join(root, "CLAUDE.md"), ""},
+	}
+	for _, tt := range tests {
+		if got := MatchReadonly(tt.patterns, tt.path); got != tt.want {
+			t.Errorf("MatchReadonly(%v, %s) = %q, want %q", tt.patterns, tt.path, got, tt.want)
+		}
+	}
+
+	cfg := &Config{Settings: Settings{ReadonlyPaths: []string{"CLAUDE.md"}}}
+	if got := cfg.ReadonlyPaths(Source{ReadonlyPaths: []string{"settings.json"}}); len(got) != 2 || got[1] != "settings.json" {
+		t.Errorf("Expected settings and source patterns combined, got %v", got)
+	}
+	if err := validateReadonlyPaths([]string{"[invalid"}); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+}
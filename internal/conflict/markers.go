@@ -0,0 +1,258 @@
+package conflict
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// minMarkerLength is the shortest run of marker characters recognized; git
+// writes seven, the merge strategy nine
+const minMarkerLength = 7
+
+// Side selects which version of a conflict hunk is kept
+type Side string
+
+const (
+	// SideCurrent keeps the lines of the existing file
+	SideCurrent Side = "current"
+	// SideIncoming keeps the lines of the incoming file
+	SideIncoming Side = "incoming"
+	// SideBoth keeps the current lines followed by the incoming lines
+	SideBoth Side = "both"
+)
+
+// ParseSide parses a side name as accepted on the command line
+func ParseSide(name string) (Side, error) {
+	switch side := Side(strings.ToLower(name)); side {
+	case SideCurrent, SideIncoming, SideBoth:
+		return side, nil
+	default:
+		return "", fmt.Errorf("unknown side %q: use current, incoming or both", name)
+	}
+}
+
+// Hunk is one conflicted region between conflict markers. Lines keep their
+// line endings.
+type Hunk struct {
+	Line          int
+	CurrentLabel  string
+	IncomingLabel string
+	Current       []string
+	Base          []string
+	Incoming      []string
+	// Resolution is the side kept; an empty resolution keeps the markers
+	Resolution Side
+
+	raw []string
+}
+
+// Lines returns the lines the hunk is replaced with under its resolution
+func (h *Hunk) Lines() []string {
+	switch h.Resolution {
+	case SideCurrent:
+		return h.Current
+	case SideIncoming:
+		return h.Incoming
+	case SideBoth:
+		return append(append([]string{}, h.Current...), h.Incoming...)
+	default:
+		return h.raw
+	}
+}
+
+// Marked is a file split into plain text and conflict hunks
+type Marked struct {
+	Hunks []*Hunk
+
+	parts []markedPart
+}
+
+// markedPart is either a run of plain lines or a hunk
+type markedPart struct {
+	lines []string
+	hunk  *Hunk
+}
+
+// HasMarkers reports whether content contains an opening conflict marker
+func HasMarkers(content []byte) bool {
+	for _, line := range splitLines(content) {
+		if _, ok := markerLabel(line, '<'); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseMarkers splits content into plain text and conflict hunks, accepting
+// both two-way and diff3-style hunks with a base section
+func ParseMarkers(content []byte) (*Marked, error) {
+	const (
+		outside = iota
+		inCurrent
+		inBase
+		inIncoming
+	)
+
+	marked := &Marked{}
+	var plain []string
+	var hunk *Hunk
+	state := outside
+
+	for n, line := range splitLines(content) {
+		if state == outside {
+			if label, ok := markerLabel(line, '<'); ok {
+				if len(plain) > 0 {
+					marked.parts = append(marked.parts, markedPart{lines: plain})
+					plain = nil
+				}
+				hunk = &Hunk{Line: n + 1, CurrentLabel: label}
+				hunk.raw = append(hunk.raw, line)
+				state = inCurrent
+				continue
+			}
+			plain = append(plain, line)
+			continue
+		}
+
+		hunk.raw = append(hunk.raw, line)
+		if _, ok := markerLabel(line, '<'); ok {
+			return nil, fmt.Errorf("line %d: conflict marker inside the conflict starting at line %d", n+1, hunk.Line)
+		}
+		switch {
+		case state == inCurrent && isBaseMarker(line):
+			state = inBase
+		case (state == inCurrent || state == inBase) && isSeparator(line):
+			state = inIncoming
+		case state == inIncoming:
+			if label, ok := markerLabel(line, '>'); ok {
+				hunk.IncomingLabel = label
+				marked.parts = append(marked.parts, markedPart{hunk: hunk})
+				marked.Hunks = append(marked.Hunks, hunk)
+				hunk = nil
+				state = outside
+				continue
+			}
+			hunk.Incoming = append(hunk.Incoming, line)
+		case state == inBase:
+			hunk.Base = append(hunk.Base, line)
+		default:
+			hunk.Current = append(hunk.Current, line)
+		}
+	}
+
+	if hunk != nil {
+		return nil, fmt.Errorf("line %d: conflict is not closed", hunk.Line)
+	}
+	if len(plain) > 0 {
+		marked.parts = append(marked.parts, markedPart{lines: plain})
+	}
+	return marked, nil
+}
+
+// Unresolved returns the number of hunks without a resolution
+func (m *Marked) Unresolved() int {
+	count := 0
+	for _, hunk := range m.Hunks {
+		if hunk.Resolution == "" {
+			count++
+		}
+	}
+	return count
+}
+
+// Bytes renders the file with every resolved hunk replaced by its kept lines
+func (m *Marked) Bytes() []byte {
+	var buf bytes.Buffer
+	for _, part := range m.parts {
+		lines := part.lines
+		if part.hunk != nil {
+			lines = part.hunk.Lines()
+		}
+		for _, line := range lines {
+			buf.WriteString(line)
+		}
+	}
+	return buf.Bytes()
+}
+
+// FindMarked returns the regular files under dir that contain conflict
+// markers, skipping hidden directories
+func FindMarked(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if HasMarkers(content) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s for conflict markers: %w", dir, err)
+	}
+	return files, nil
+}
+
+// splitLines splits content into lines that keep their line endings
+func splitLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// markerRun returns the length of the run of ch that starts line and the
+// rest of the line without its line ending
+func markerRun(line string, ch byte) (int, string) {
+	line = strings.TrimRight(line, "\r\n")
+	n := 0
+	for n < len(line) && line[n] == ch {
+		n++
+	}
+	return n, line[n:]
+}
+
+// markerLabel reports whether line is an opening ('<') or closing ('>')
+// marker and returns its label
+func markerLabel(line string, ch byte) (string, bool) {
+	n, rest := markerRun(line, ch)
+	if n < minMarkerLength || (rest != "" && rest[0] != ' ') {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// isBaseMarker reports whether line starts the base section of a diff3 hunk
+func isBaseMarker(line string) bool {
+	_, ok := markerLabel(line, '|')
+	return ok
+}
+
+// isSeparator reports whether line separates the current and incoming lines
+func isSeparator(line string) bool {
+	n, rest := markerRun(line, '=')
+	return n >= minMarkerLength && rest == ""
+}
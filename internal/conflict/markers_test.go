@@ -0,0 +1,109 @@
+package conflict
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const markedAgent = `---
+name: reviewer
+<<<<<<<<< Current
+description: Reviews code locally
+=========
+description: Reviews code
+>>>>>>>>> Incoming
+---
+Intro
+<<<<<<< ours
+||||||| base
+Old prompt
+=======
+New prompt
+>>>>>>> theirs
+`
+
+func TestParseMarkers(t *testing.T) {
+	marked, err := ParseMarkers([]byte(markedAgent))
+	if err != nil {
+		t.Fatalf("ParseMarkers failed: %v", err)
+	}
+	if len(marked.Hunks) != 2 {
+		t.Fatalf("Expected 2 hunks, got %d", len(marked.Hunks))
+	}
+
+	first := marked.Hunks[0]
+	if first.Line != 3 || first.CurrentLabel != "Current" || first.IncomingLabel != "Incoming" {
+		t.Errorf("Unexpected first hunk: %+v", first)
+	}
+	second := marked.Hunks[1]
+	if len(second.Current) != 0 || len(second.Base) != 1 || len(second.Incoming) != 1 {
+		t.Errorf("Expected an empty current side, one base line and one incoming line, got %+v", second)
+	}
+
+	// Unresolved hunks render as they were
+	if got := string(marked.Bytes()); got != markedAgent {
+		t.Errorf("Expected unresolved content unchanged, got:\n%s", got)
+	}
+
+	first.Resolution = SideCurrent
+	second.Resolution = SideBoth
+	want := "---\nname: reviewer\ndescription: Reviews code locally\n---\nIntro\nNew prompt\n"
+	if got := string(marked.Bytes()); got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+	if marked.Unresolved() != 0 {
+		t.Errorf("Expected every hunk resolved, got %d left", marked.Unresolved())
+	}
+}
+
+func TestParseMarkersMalformed(t *testing.T) {
+	for name, content := range map[string]string{
+		"unclosed": "<<<<<<< a\nx\n=======\ny\n",
+		"nested":   "<<<<<<< a\n<<<<<<< b\n=======\n>>>>>>> c\n",
+	} {
+		if _, err := ParseMarkers([]byte(content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// Markdown headings and short runs are not markers
+	content := []byte("Title\n=======\n<<< not a marker\n<<<<<<<x\n")
+	if HasMarkers(content) {
+		t.Error("Expected no markers")
+	}
+	marked, err := ParseMarkers(content)
+	if err != nil || len(marked.Hunks) != 0 {
+		t.Errorf("Expected no hunks, got %v (%v)", marked, err)
+	}
+}
+
+func TestFindMarked(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"clean.md":          "no markers\n",
+		"team/marked.md":    markedAgent,
+		".hidden/marked.md": markedAgent,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := FindMarked(dir)
+	if err != nil {
+		t.Fatalf("FindMarked failed: %v", err)
+	}
+	if len(found) != 1 || found[0] != filepath.Join(dir, "team", "marked.md") {
+		t.Errorf("Expected only team/marked.md, got %v", found)
+	}
+
+	if found, err := FindMarked(filepath.Join(dir, "missing")); err != nil || len(found) != 0 {
+		t.Errorf("Expected nothing in a missing directory, got %v (%v)", found, err)
+	}
+}
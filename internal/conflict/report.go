@@ -0,0 +1,82 @@
+package conflict
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Action describes what conflict resolution did to an existing file
+type Action string
+
+const (
+	// ActionBackedUp means the existing file was backed up and replaced
+	ActionBackedUp Action = "backed_up"
+	// ActionOverwritten means the existing file was replaced without a backup
+	ActionOverwritten Action = "overwritten"
+	// ActionSkipped means the existing file was kept and the incoming file ignored
+	ActionSkipped Action = "skipped"
+	// ActionMerged means the incoming changes were merged cleanly into the existing file
+	ActionMerged Action = "merged"
+	// ActionMergedWithConflicts means the merged file contains conflict markers to resolve by hand
+	ActionMergedWithConflicts Action = "merged_with_conflicts"
+	// ActionMergeFailed means merging failed and the file was backed up and replaced instead
+	ActionMergeFailed Action = "merge_failed"
+	// ActionUnchanged means the incoming file is identical to the existing one, which was left alone
+	ActionUnchanged Action = "unchanged"
+	// ActionProtected means the existing file was authored outside agent-manager and kept
+	ActionProtected Action = "protected"
+	// ActionReadonly means the path matches a readonly_paths pattern and was not written
+	ActionReadonly Action = "readonly"
+)
+
+// Outcome records how a single file conflict was resolved
+type Outcome struct {
+	Path       string `json:"path"`
+	Source     string `json:"source,omitempty"`
+	Strategy   string `json:"strategy"`
+	Action     Action `json:"action"`
+	BackupPath string `json:"backup_path,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+	// PreExisting marks files that no source installed, such as ones the user authored
+	PreExisting bool `json:"pre_existing,omitempty"`
+}
+
+// Replaces reports whether the incoming file should be copied over the existing one
+func (o Outcome) Replaces() bool {
+	switch o.Action {
+	case ActionSkipped, ActionMerged, ActionMergedWithConflicts, ActionUnchanged, ActionProtected, ActionReadonly:
+		return false
+	default:
+		return true
+	}
+}
+
+// Report is the JSON document written after an install
+type Report struct {
+	Generated time.Time `json:"generated"`
+	Conflicts []Outcome `json:"conflicts"`
+}
+
+// WriteReport writes the outcomes as a JSON conflict report to path
+func WriteReport(path string, outcomes []Outcome) error {
+	if outcomes == nil {
+		outcomes = []Outcome{}
+	}
+	content, err := json.MarshalIndent(Report{Generated: time.Now(), Conflicts: outcomes}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal conflict report: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write conflict report: %w", err)
+	}
+	return nil
+}
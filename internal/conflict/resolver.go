@@ -28,93 +28,159 @@ func NewResolver(strategy, backupDir string) *Resolver {
 
 // Resolve resolves a file conflict based on the configured strategy
 func (r *Resolver) Resolve(existingPath, newPath, strategy string) (bool, error) {
+	outcome, err := r.ResolveDetailed(existingPath, newPath, strategy)
+	if err != nil {
+		return false, err
+	}
+	return outcome.Action != ActionSkipped && outcome.Action != ActionUnchanged, nil
+}
+
+// ResolveDetailed resolves a file conflict and reports what was done to the existing file
+func (r *Resolver) ResolveDetailed(existingPath, newPath, strategy string) (Outcome, error) {
 	// Use override strategy if provided
 	if strategy == "" {
 		strategy = r.strategy
 	}
 
+	outcome := Outcome{Path: existingPath, Strategy: strategy}
+
+	switch strategy {
+	case "backup", "overwrite", "skip", "merge":
+	default:
+		return outcome, fmt.Errorf("unknown conflict strategy: %s", strategy)
+	}
+
+	// Identical content needs no backup or copy, whatever the strategy
+	same, err := sameContent(existingPath, newPath)
+	if err != nil {
+		return outcome, err
+	}
+	if same {
+		outcome.Action = ActionUnchanged
+		return outcome, nil
+	}
+
 	switch strategy {
 	case "backup":
-		return r.resolveWithBackup(existingPath, newPath)
+		return r.resolveWithBackup(outcome, newPath)
 	case "overwrite":
-		return true, nil // Allow overwrite
+		outcome.Action = ActionOverwritten
+		return outcome, nil
 	case "skip":
-		return false, nil // Skip the file
+		outcome.Action = ActionSkipped
+		return outcome, nil
 	case "merge":
-		return r.resolveWithMerge(existingPath, newPath)
+		return r.resolveWithMerge(outcome, newPath)
 	default:
-		return false, fmt.Errorf("unknown conflict strategy: %s", strategy)
+		return outcome, fmt.Errorf("unknown conflict strategy: %s", strategy)
 	}
 }
 
 // resolveWithBackup creates a backup of the existing file
-func (r *Resolver) resolveWithBackup(existingPath, newPath string) (bool, error) {
+func (r *Resolver) resolveWithBackup(outcome Outcome, newPath string) (Outcome, error) {
 	_ = newPath // Not used in backup strategy, kept for interface consistency
-	// Create backup directory if it doesn't exist
-	backupPath := r.getBackupPath(existingPath)
-	backupDir := filepath.Dir(backupPath)
-
-	if err := os.MkdirAll(backupDir, 0750); err != nil {
-		return false, fmt.Errorf("failed to create backup directory: %w", err)
-	}
-
-	// Copy existing file to backup
-	if err := r.copyFile(existingPath, backupPath); err != nil {
-		return false, fmt.Errorf("failed to backup file: %w", err)
+	backupPath, err := r.backupFile(outcome.Path)
+	if err != nil {
+		return outcome, err
 	}
 
-	return true, nil // Allow overwrite after backup
+	// Allow overwrite after backup
+	outcome.Action = ActionBackedUp
+	outcome.BackupPath = backupPath
+	return outcome, nil
 }
 
 // resolveWithMerge attempts to merge files using three-way merge
-func (r *Resolver) resolveWithMerge(existingPath, newPath string) (bool, error) {
+func (r *Resolver) resolveWithMerge(outcome Outcome, newPath string) (Outcome, error) {
 	// First create a backup like in backup strategy
-	backupPath := r.getBackupPath(existingPath)
-	backupDir := filepath.Dir(backupPath)
-
-	if err := os.MkdirAll(backupDir, 0750); err != nil {
-		return false, fmt.Errorf("failed to create backup directory: %w", err)
-	}
-
-	// Copy existing file to backup
-	if err := r.copyFile(existingPath, backupPath); err != nil {
-		return false, fmt.Errorf("failed to backup file: %w", err)
+	backupPath, err := r.backupFile(outcome.Path)
+	if err != nil {
+		return outcome, err
 	}
+	outcome.BackupPath = backupPath
 
 	// Now attempt three-way merge
-	mergedContent, err := r.performThreeWayMerge(backupPath, existingPath, newPath)
+	mergedContent, conflicts, err := r.performThreeWayMerge(backupPath, outcome.Path, newPath)
 	if err != nil {
 		// If merge fails, fall back to backup strategy behavior (allow overwrite)
-		// This is intentional: we return true to proceed with overwrite after backup
-		//nolint:nilerr // Intentional fallback to backup strategy when merge fails
-		return true, nil
+		// This is intentional: we proceed with overwrite after backup
+		outcome.Action = ActionMergeFailed
+		outcome.Detail = err.Error()
+		return outcome, nil
 	}
 
 	// Write merged content to the existing path
-	if err := os.WriteFile(existingPath, mergedContent, 0600); err != nil {
-		return false, fmt.Errorf("failed to write merged content: %w", err)
+	if err := os.WriteFile(outcome.Path, mergedContent, 0600); err != nil {
+		return outcome, fmt.Errorf("failed to write merged content: %w", err)
+	}
+
+	outcome.Action = ActionMerged
+	if conflicts {
+		outcome.Action = ActionMergedWithConflicts
+	}
+	return outcome, nil
+}
+
+// sameContent reports whether two files hold identical bytes, comparing
+// sizes before hashing the content
+func sameContent(existingPath, newPath string) (bool, error) {
+	existingInfo, err := os.Stat(existingPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat %s: %w", existingPath, err)
+	}
+	newInfo, err := os.Stat(newPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat %s: %w", newPath, err)
+	}
+	if !existingInfo.Mode().IsRegular() || !newInfo.Mode().IsRegular() || existingInfo.Size() != newInfo.Size() {
+		return false, nil
+	}
+
+	existingHash, err := util.FileSHA256(existingPath)
+	if err != nil {
+		return false, err
+	}
+	newHash, err := util.FileSHA256(newPath)
+	if err != nil {
+		return false, err
+	}
+	return existingHash == newHash, nil
+}
+
+// backupFile copies the existing file into the backup directory and returns the backup path
+func (r *Resolver) backupFile(existingPath string) (string, error) {
+	// Create backup directory if it doesn't exist
+	backupPath := r.getBackupPath(existingPath)
+	if err := os.MkdirAll(filepath.Dir(backupPath), 0750); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	// Copy existing file to backup
+	if err := r.copyFile(existingPath, backupPath); err != nil {
+		return "", fmt.Errorf("failed to backup file: %w", err)
 	}
 
-	return true, nil
+	return backupPath, nil
 }
 
 // performThreeWayMerge performs intelligent merge of three file versions
-func (r *Resolver) performThreeWayMerge(originalPath, currentPath, incomingPath string) ([]byte, error) {
+// and reports whether the result contains conflict markers
+func (r *Resolver) performThreeWayMerge(originalPath, currentPath, incomingPath string) ([]byte, bool, error) {
 	// Validate paths for security
 	if err := util.ValidatePath(originalPath); err != nil {
-		return nil, fmt.Errorf("invalid original path: %w", err)
+		return nil, false, fmt.Errorf("invalid original path: %w", err)
 	}
 	if err := util.ValidatePath(currentPath); err != nil {
-		return nil, fmt.Errorf("invalid current path: %w", err)
+		return nil, false, fmt.Errorf("invalid current path: %w", err)
 	}
 	if err := util.ValidatePath(incomingPath); err != nil {
-		return nil, fmt.Errorf("invalid incoming path: %w", err)
+		return nil, false, fmt.Errorf("invalid incoming path: %w", err)
 	}
 
 	// Read the three versions
 	originalFile, err := os.Open(originalPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read original file: %w", err)
+		return nil, false, fmt.Errorf("failed to read original file: %w", err)
 	}
 	defer func() {
 		if closeErr := originalFile.Close(); closeErr != nil {
@@ -124,7 +190,7 @@ func (r *Resolver) performThreeWayMerge(originalPath, currentPath, incomingPath
 
 	currentFile, err := os.Open(currentPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read current file: %w", err)
+		return nil, false, fmt.Errorf("failed to read current file: %w", err)
 	}
 	defer func() {
 		if closeErr := currentFile.Close(); closeErr != nil {
@@ -134,7 +200,7 @@ func (r *Resolver) performThreeWayMerge(originalPath, currentPath, incomingPath
 
 	incomingFile, err := os.Open(incomingPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read incoming file: %w", err)
+		return nil, false, fmt.Errorf("failed to read incoming file: %w", err)
 	}
 	defer func() {
 		if closeErr := incomingFile.Close(); closeErr != nil {
@@ -146,16 +212,16 @@ func (r *Resolver) performThreeWayMerge(originalPath, currentPath, incomingPath
 	// Parameters: current (a), original (o), incoming (b)
 	result, err := diff3.Merge(currentFile, originalFile, incomingFile, true, "Current", "Incoming")
 	if err != nil {
-		return nil, fmt.Errorf("merge failed: %w", err)
+		return nil, false, fmt.Errorf("merge failed: %w", err)
 	}
 
 	// Read the merged result
 	mergedContent, err := io.ReadAll(result.Result)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read merge result: %w", err)
+		return nil, false, fmt.Errorf("failed to read merge result: %w", err)
 	}
 
-	return mergedContent, nil
+	return mergedContent, result.Conflicts, nil
 }
 
 // CreateBackup creates a backup of all files for a source
@@ -286,7 +352,7 @@ func (r *Resolver) RestoreBackupFiles() error {
 
 		// Reconstruct original path under .claude/agents/
 		// Replace underscores with slashes to restore directory structure
-		relativePath := strings.ReplaceAll(flatPath, "_", "/")
+		relativePath := filepath.FromSlash(strings.ReplaceAll(flatPath, "_", "/"))
 		originalPath := filepath.Join(".claude", "agents", relativePath)
 
 		// Ensure parent directory exists
@@ -366,7 +432,7 @@ func (r *Resolver) RestoreBackupFilesWithTracking() (map[string]bool, error) {
 
 		// Reconstruct original path under .claude/agents/
 		// Replace underscores with slashes to restore directory structure
-		relativePath := strings.ReplaceAll(flatPath, "_", "/")
+		relativePath := filepath.FromSlash(strings.ReplaceAll(flatPath, "_", "/"))
 		originalPath := filepath.Join(".claude", "agents", relativePath)
 
 		// Ensure parent directory exists
@@ -514,14 +580,14 @@ func (r *Resolver) getBackupPath(originalPath string) string {
 	// Clean the original path
 	cleanedPath := filepath.Clean(originalPath)
 
-	// Check if this is a file under .claude/agents/
-	agentsPrefix := ".claude/agents/"
+	// Check if this is a file under .claude/agents/, with the platform's separators
+	agentsPrefix := filepath.Join(".claude", "agents") + string(filepath.Separator)
 	if strings.HasPrefix(cleanedPath, agentsPrefix) {
 		// Extract relative path from .claude/agents/
-		relativePath := strings.TrimPrefix(cleanedPath, agentsPrefix)
+		relativePath := filepath.ToSlash(strings.TrimPrefix(cleanedPath, agentsPrefix))
 
 		// Replace path separators with underscores to create flat backup filename
-		// Example: "foo/agent.md" becomes "foo_agent.md"
+		// Example: "foo/agent.md" (or "foo\agent.md" on Windows) becomes "foo_agent.md"
 		flatPath := strings.ReplaceAll(relativePath, "/", "_")
 		backupName := fmt.Sprintf("%s_%s", flatPath, timestamp)
 
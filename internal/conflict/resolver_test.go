@@ -1,8 +1,10 @@
 package conflict
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -99,6 +101,123 @@ func TestResolve(t *testing.T) {
 	}
 }
 
+func TestResolveDetailed(t *testing.T) {
+	tempDir := t.TempDir()
+	backupDir := filepath.Join(tempDir, "backups")
+	resolver := NewResolver("backup", backupDir)
+
+	existingFile := filepath.Join(tempDir, "existing.md")
+	newFile := filepath.Join(tempDir, "new.md")
+	if err := os.WriteFile(newFile, []byte("new content\n"), 0644); err != nil {
+		t.Fatalf("Failed to create new file: %v", err)
+	}
+
+	tests := []struct {
+		strategy   string
+		wantAction Action
+		wantBackup bool
+		replaces   bool
+	}{
+		{strategy: "", wantAction: ActionBackedUp, wantBackup: true, replaces: true},
+		{strategy: "overwrite", wantAction: ActionOverwritten, replaces: true},
+		{strategy: "skip", wantAction: ActionSkipped},
+		{strategy: "merge", wantAction: ActionMerged, wantBackup: true},
+	}
+
+	for _, tt := range tests {
+		t.Run("strategy "+tt.strategy, func(t *testing.T) {
+			if err := os.WriteFile(existingFile, []byte("existing content\n"), 0644); err != nil {
+				t.Fatalf("Failed to create existing file: %v", err)
+			}
+
+			outcome, err := resolver.ResolveDetailed(existingFile, newFile, tt.strategy)
+			if err != nil {
+				t.Fatalf("ResolveDetailed() error = %v", err)
+			}
+			if outcome.Path != existingFile {
+				t.Errorf("Path = %s, want %s", outcome.Path, existingFile)
+			}
+			if outcome.Action != tt.wantAction {
+				t.Errorf("Action = %s, want %s", outcome.Action, tt.wantAction)
+			}
+			if outcome.Replaces() != tt.replaces {
+				t.Errorf("Replaces() = %v, want %v", outcome.Replaces(), tt.replaces)
+			}
+			if tt.wantBackup {
+				content, err := os.ReadFile(outcome.BackupPath)
+				if err != nil {
+					t.Fatalf("Backup not readable at %q: %v", outcome.BackupPath, err)
+				}
+				if string(content) != "existing content\n" {
+					t.Errorf("Backup content = %q", content)
+				}
+			} else if outcome.BackupPath != "" {
+				t.Errorf("Unexpected backup path %s", outcome.BackupPath)
+			}
+		})
+	}
+}
+
+func TestResolveDetailed_IdenticalContent(t *testing.T) {
+	tempDir := t.TempDir()
+	backupDir := filepath.Join(tempDir, "backups")
+	resolver := NewResolver("backup", backupDir)
+
+	existingFile := filepath.Join(tempDir, "existing.md")
+	newFile := filepath.Join(tempDir, "new.md")
+	for _, path := range []string{existingFile, newFile} {
+		if err := os.WriteFile(path, []byte("same content\n"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	for _, strategy := range []string{"backup", "overwrite", "skip", "merge"} {
+		outcome, err := resolver.ResolveDetailed(existingFile, newFile, strategy)
+		if err != nil {
+			t.Fatalf("ResolveDetailed(%s) error = %v", strategy, err)
+		}
+		if outcome.Action != ActionUnchanged || outcome.Replaces() || outcome.BackupPath != "" {
+			t.Errorf("Strategy %s: expected the file left unchanged, got %+v", strategy, outcome)
+		}
+	}
+	if _, err := os.Stat(backupDir); !os.IsNotExist(err) {
+		t.Error("Expected no backup of an unchanged file")
+	}
+
+	// Same size, different bytes is still a conflict
+	if err := os.WriteFile(newFile, []byte("same CONTENT\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outcome, err := resolver.ResolveDetailed(existingFile, newFile, "")
+	if err != nil {
+		t.Fatalf("ResolveDetailed() error = %v", err)
+	}
+	if outcome.Action != ActionBackedUp {
+		t.Errorf("Expected changed content backed up, got %s", outcome.Action)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "conflicts.json")
+	outcomes := []Outcome{{Path: "a.md", Source: "src", Strategy: "backup", Action: ActionBackedUp, BackupPath: "b/a.md_1"}}
+
+	if err := WriteReport(path, outcomes); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var report Report
+	if err := json.Unmarshal(content, &report); err != nil {
+		t.Fatalf("Report is not valid JSON: %v", err)
+	}
+	if len(report.Conflicts) != 1 || report.Conflicts[0] != outcomes[0] {
+		t.Errorf("Conflicts = %+v, want %+v", report.Conflicts, outcomes)
+	}
+}
+
 func TestCreateBackup(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "create-backup-test-*")
 	if err != nil {
@@ -132,6 +251,24 @@ func TestCreateBackup(t *testing.T) {
 	}
 }
 
+func TestGetBackupPath(t *testing.T) {
+	resolver := NewResolver("backup", "backups")
+
+	// Nested agents are flattened whichever separator the platform uses
+	nested := resolver.getBackupPath(filepath.Join(".claude", "agents", "team", "reviewer.md"))
+	if name := filepath.Base(nested); !strings.HasPrefix(name, "team_reviewer.md_") {
+		t.Errorf("Expected a flattened backup name, got %s", name)
+	}
+	if filepath.Dir(nested) != "backups" {
+		t.Errorf("Expected the backup in the backup directory, got %s", nested)
+	}
+
+	other := resolver.getBackupPath(filepath.Join("docs", "guide.md"))
+	if name := filepath.Base(other); !strings.HasPrefix(name, "guide.md_") {
+		t.Errorf("Expected the file name for files outside .claude/agents, got %s", name)
+	}
+}
+
 func TestCleanupOldBackups(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "old-backup-test-*")
 	if err != nil {
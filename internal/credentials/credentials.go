@@ -0,0 +1,87 @@
+// Package credentials stores source tokens in the OS keychain or a git
+// credential helper so they need not live in environment variables.
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+// Service is the keychain service name credentials are stored under
+const Service = "agent-manager"
+
+// Helpers
+const (
+	HelperSystem = "system"
+	HelperGit    = "git"
+)
+
+// ErrNotFound is returned when no credential is stored for an account
+var ErrNotFound = errors.New("credential not found")
+
+// Store reads and writes secrets for named accounts
+type Store interface {
+	Get(ctx context.Context, account string) (string, error)
+	Set(ctx context.Context, account, secret string) error
+	Delete(ctx context.Context, account string) error
+}
+
+// New returns the store for helper: the OS keychain for system (the default)
+// or git's configured credential helper for git, keyed by the source URL
+func New(helper, url string) (Store, error) {
+	switch helper {
+	case "", HelperSystem:
+		return systemStore()
+	case HelperGit:
+		if url == "" {
+			return nil, fmt.Errorf("the git credential helper requires a source URL")
+		}
+		return &gitStore{url: url}, nil
+	default:
+		return nil, fmt.Errorf("invalid credential helper: %s (must be %s or %s)", helper, HelperSystem, HelperGit)
+	}
+}
+
+// validateSecret rejects secrets that cannot be passed to credential tools intact
+func validateSecret(secret string) error {
+	if secret == "" {
+		return fmt.Errorf("credential cannot be empty")
+	}
+	if strings.ContainsAny(secret, "\r\n\x00") {
+		return fmt.Errorf("credential cannot contain line breaks or null bytes")
+	}
+	return nil
+}
+
+// run executes a credential tool with input on stdin, returning its stdout.
+// Session bus variables are kept so libsecret can reach the keyring daemon.
+func run(ctx context.Context, input string, name string, args ...string) (string, error) {
+	cmd, err := util.SecureCommandContext(ctx, name, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to create secure command: %w", err)
+	}
+	for _, key := range []string{"DBUS_SESSION_BUS_ADDRESS", "XDG_RUNTIME_DIR"} {
+		if value := os.Getenv(key); value != "" {
+			cmd.Env = append(cmd.Env, key+"="+value)
+		}
+	}
+	cmd.Env = append(cmd.Env, "GIT_TERMINAL_PROMPT=0")
+	cmd.Stdin = strings.NewReader(input)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%s failed: %s", name, message)
+		}
+		return "", fmt.Errorf("%s failed: %w", name, err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,72 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	if _, err := New("vault", ""); err == nil {
+		t.Error("Expected an error for an unknown helper")
+	}
+	if _, err := New(HelperGit, ""); err == nil {
+		t.Error("Expected an error for the git helper without a URL")
+	}
+	if store, err := New(HelperGit, "https://github.com/org/agents.git"); err != nil || store == nil {
+		t.Errorf("New(git) = %v, %v", store, err)
+	}
+}
+
+func TestGitStore_Request(t *testing.T) {
+	g := &gitStore{url: "https://github.com/org/agents.git"}
+	got, err := g.request("agent-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "protocol=https\nhost=github.com\npath=org/agents.git\nusername=agent-manager\n\n"
+	if got != want {
+		t.Errorf("request() = %q, want %q", got, want)
+	}
+
+	if _, err := (&gitStore{url: "not a url"}).request(""); err == nil {
+		t.Error("Expected an error for a URL without scheme and host")
+	}
+}
+
+func TestGitStore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, ".gitconfig"), []byte("[credential]\n\thelper = store\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	store := &gitStore{url: "https://git.example.com/org/agents.git"}
+
+	if _, err := store.Get(ctx, "private"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() before Set error = %v, want ErrNotFound", err)
+	}
+	if err := store.Set(ctx, "private", "s3cret"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if token, err := store.Get(ctx, "private"); err != nil || token != "s3cret" {
+		t.Errorf("Get() = %q, %v, want s3cret", token, err)
+	}
+	if err := store.Delete(ctx, "private"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(ctx, "private"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrNotFound", err)
+	}
+
+	if err := store.Set(ctx, "private", "line\nbreak"); err == nil {
+		t.Error("Expected an error for a secret with a line break")
+	}
+}
@@ -0,0 +1,83 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// gitStore keeps credentials with git's configured credential helper, keyed
+// by the source URL rather than the account name
+type gitStore struct {
+	url string
+}
+
+// Get asks the git credential helpers for the password of the source URL
+func (g *gitStore) Get(ctx context.Context, account string) (string, error) {
+	request, err := g.request("")
+	if err != nil {
+		return "", err
+	}
+	output, err := run(ctx, request, "git", "credential", "fill")
+	if err != nil {
+		// With prompting disabled, fill fails when no helper has a credential
+		return "", fmt.Errorf("%w for %s: %v", ErrNotFound, g.url, err)
+	}
+	if password := credentialField(output, "password"); password != "" {
+		return password, nil
+	}
+	return "", fmt.Errorf("%w for %s", ErrNotFound, g.url)
+}
+
+// Set stores secret for the source URL with the git credential helpers
+func (g *gitStore) Set(ctx context.Context, account, secret string) error {
+	if err := validateSecret(secret); err != nil {
+		return err
+	}
+	request, err := g.request(account)
+	if err != nil {
+		return err
+	}
+	_, err = run(ctx, request[:len(request)-1]+"password="+secret+"\n\n", "git", "credential", "approve")
+	return err
+}
+
+// Delete removes the credential for the source URL from the git credential helpers
+func (g *gitStore) Delete(ctx context.Context, account string) error {
+	request, err := g.request(account)
+	if err != nil {
+		return err
+	}
+	_, err = run(ctx, request, "git", "credential", "reject")
+	return err
+}
+
+// request builds a git credential protocol description of the source URL
+func (g *gitStore) request(username string) (string, error) {
+	parsed, err := url.Parse(g.url)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return "", fmt.Errorf("invalid source URL for the git credential helper: %s", g.url)
+	}
+
+	var request strings.Builder
+	fmt.Fprintf(&request, "protocol=%s\nhost=%s\n", parsed.Scheme, parsed.Host)
+	if path := strings.TrimPrefix(parsed.Path, "/"); path != "" {
+		fmt.Fprintf(&request, "path=%s\n", path)
+	}
+	if username != "" {
+		fmt.Fprintf(&request, "username=%s\n", username)
+	}
+	request.WriteString("\n")
+	return request.String(), nil
+}
+
+// credentialField returns the value of key in git credential protocol output
+func credentialField(output, key string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if value, ok := strings.CutPrefix(line, key+"="); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
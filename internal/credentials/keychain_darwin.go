@@ -0,0 +1,53 @@
+//go:build darwin
+
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// keychain stores credentials in the macOS login keychain with the security tool
+type keychain struct{}
+
+func systemStore() (Store, error) {
+	return keychain{}, nil
+}
+
+// Get reads the password of account from the keychain
+func (keychain) Get(ctx context.Context, account string) (string, error) {
+	output, err := run(ctx, "", "security", "find-generic-password", "-s", Service, "-a", account, "-w")
+	if err != nil {
+		if strings.Contains(err.Error(), "could not be found") {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, account)
+		}
+		return "", err
+	}
+	return strings.TrimRight(output, "\n"), nil
+}
+
+// Set adds or replaces the password of account. The command is passed on
+// stdin in interactive mode so the secret never appears in the process list.
+func (keychain) Set(ctx context.Context, account, secret string) error {
+	if err := validateSecret(secret); err != nil {
+		return err
+	}
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", quote(Service), quote(account), quote(secret))
+	_, err := run(ctx, command, "security", "-i")
+	return err
+}
+
+// Delete removes the password of account from the keychain
+func (keychain) Delete(ctx context.Context, account string) error {
+	_, err := run(ctx, "", "security", "delete-generic-password", "-s", Service, "-a", account)
+	if err != nil && strings.Contains(err.Error(), "could not be found") {
+		return fmt.Errorf("%w: %s", ErrNotFound, account)
+	}
+	return err
+}
+
+// quote single-quotes value for the security tool's interactive mode
+func quote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,47 @@
+//go:build linux
+
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// secretService stores credentials with libsecret through secret-tool
+type secretService struct{}
+
+func systemStore() (Store, error) {
+	return secretService{}, nil
+}
+
+// Get looks up the secret of account; secret-tool prints nothing when there is none
+func (secretService) Get(ctx context.Context, account string) (string, error) {
+	output, err := run(ctx, "", "secret-tool", "lookup", "service", Service, "account", account)
+	if err != nil {
+		if strings.HasSuffix(err.Error(), "exit status 1") {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, account)
+		}
+		return "", err
+	}
+	secret := strings.TrimRight(output, "\n")
+	if secret == "" {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, account)
+	}
+	return secret, nil
+}
+
+// Set stores secret for account, passing it on stdin
+func (secretService) Set(ctx context.Context, account, secret string) error {
+	if err := validateSecret(secret); err != nil {
+		return err
+	}
+	_, err := run(ctx, secret, "secret-tool", "store", "--label="+Service+": "+account, "service", Service, "account", account)
+	return err
+}
+
+// Delete removes the secret of account
+func (secretService) Delete(ctx context.Context, account string) error {
+	_, err := run(ctx, "", "secret-tool", "clear", "service", Service, "account", account)
+	return err
+}
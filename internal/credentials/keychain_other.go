@@ -0,0 +1,12 @@
+//go:build !darwin && !linux && !windows
+
+package credentials
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func systemStore() (Store, error) {
+	return nil, fmt.Errorf("no OS keychain support on %s; use helper: git", runtime.GOOS)
+}
@@ -0,0 +1,115 @@
+//go:build windows
+
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	errorNotFound           = syscall.Errno(1168)
+)
+
+var (
+	advapi32       = syscall.NewLazyDLL("advapi32.dll")
+	procCredRead   = advapi32.NewProc("CredReadW")
+	procCredWrite  = advapi32.NewProc("CredWriteW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+// credential mirrors the Win32 CREDENTIALW structure
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// credentialManager stores credentials as generic Windows Credential Manager entries
+type credentialManager struct{}
+
+func systemStore() (Store, error) {
+	return credentialManager{}, nil
+}
+
+// target returns the Credential Manager entry name of account
+func target(account string) (*uint16, error) {
+	return syscall.UTF16PtrFromString(Service + ":" + account)
+}
+
+// Get reads the secret of account from Credential Manager
+func (credentialManager) Get(_ context.Context, account string) (string, error) {
+	name, err := target(account)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	ret, _, callErr := procCredRead.Call(uintptr(unsafe.Pointer(name)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if ret == 0 {
+		if errors.Is(callErr, errorNotFound) {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, account)
+		}
+		return "", fmt.Errorf("failed to read credential: %w", callErr)
+	}
+	defer func() { _, _, _ = procCredFree.Call(uintptr(unsafe.Pointer(cred))) }()
+
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+// Set adds or replaces the secret of account in Credential Manager
+func (credentialManager) Set(_ context.Context, account, secret string) error {
+	if err := validateSecret(secret); err != nil {
+		return err
+	}
+	name, err := target(account)
+	if err != nil {
+		return err
+	}
+	user, err := syscall.UTF16PtrFromString(account)
+	if err != nil {
+		return err
+	}
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         name,
+		CredentialBlobSize: uint32(len(blob)),
+		CredentialBlob:     &blob[0],
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if ret, _, callErr := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0); ret == 0 {
+		return fmt.Errorf("failed to write credential: %w", callErr)
+	}
+	return nil
+}
+
+// Delete removes the secret of account from Credential Manager
+func (credentialManager) Delete(_ context.Context, account string) error {
+	name, err := target(account)
+	if err != nil {
+		return err
+	}
+	if ret, _, callErr := procCredDelete.Call(uintptr(unsafe.Pointer(name)), credTypeGeneric, 0); ret == 0 {
+		if errors.Is(callErr, errorNotFound) {
+			return fmt.Errorf("%w: %s", ErrNotFound, account)
+		}
+		return fmt.Errorf("failed to delete credential: %w", callErr)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package githook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Hooks are the git hooks that keep project agents in sync after pulls and checkouts
+var Hooks = []string{"post-merge", "post-checkout"}
+
+const (
+	beginMarker = "# >>> agent-manager >>>"
+	endMarker   = "# <<< agent-manager <<<"
+	shebang     = "#!/bin/sh"
+)
+
+// Block returns the hook snippet that installs agents from configPath with binary.
+// For post-checkout it only runs on branch checkouts, not file checkouts.
+func Block(hook, binary, configPath string) string {
+	command := fmt.Sprintf("%s install --config %s --quiet || echo \"agent-manager: agent sync failed\" >&2",
+		shellQuote(binary), shellQuote(configPath))
+
+	var b strings.Builder
+	b.WriteString(beginMarker + "\n")
+	b.WriteString("# Keeps project-scoped agents in sync; remove with 'agent-manager githook uninstall'\n")
+	if hook == "post-checkout" {
+		b.WriteString("if [ \"$3\" = \"1\" ]; then\n  " + command + "\nfi\n")
+	} else {
+		b.WriteString(command + "\n")
+	}
+	b.WriteString(endMarker + "\n")
+	return b.String()
+}
+
+// Install writes the agent-manager block into the hook file in hooksDir,
+// replacing an earlier block and preserving any other hook content
+func Install(hooksDir, hook, block string) error {
+	path := filepath.Join(hooksDir, hook)
+
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s hook: %w", hook, err)
+	}
+
+	existing := removeBlock(string(content))
+	if strings.TrimSpace(existing) == "" {
+		existing = shebang + "\n"
+	}
+	if !strings.HasSuffix(existing, "\n") {
+		existing += "\n"
+	}
+
+	if err := os.MkdirAll(hooksDir, 0750); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	// Hooks must be executable; Chmod also covers a pre-existing file
+	if err := os.WriteFile(path, []byte(existing+block), 0755); err != nil {
+		return fmt.Errorf("failed to write %s hook: %w", hook, err)
+	}
+	return os.Chmod(path, 0755)
+}
+
+// Uninstall removes the agent-manager block from the hook file in hooksDir,
+// deleting the file when nothing else remains. It reports whether a block was found.
+func Uninstall(hooksDir, hook string) (bool, error) {
+	path := filepath.Join(hooksDir, hook)
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s hook: %w", hook, err)
+	}
+	if !strings.Contains(string(content), beginMarker) {
+		return false, nil
+	}
+
+	remaining := removeBlock(string(content))
+	if strings.TrimSpace(remaining) == "" || strings.TrimSpace(remaining) == shebang {
+		if err := os.Remove(path); err != nil {
+			return false, fmt.Errorf("failed to remove %s hook: %w", hook, err)
+		}
+		return true, nil
+	}
+	if err := os.WriteFile(path, []byte(remaining), 0755); err != nil {
+		return false, fmt.Errorf("failed to write %s hook: %w", hook, err)
+	}
+	return true, nil
+}
+
+// Installed reports whether the hook file in hooksDir contains the agent-manager block
+func Installed(hooksDir, hook string) bool {
+	content, err := os.ReadFile(filepath.Join(hooksDir, hook))
+	return err == nil && strings.Contains(string(content), beginMarker)
+}
+
+// removeBlock strips the agent-manager block from hook content
+func removeBlock(content string) string {
+	start := strings.Index(content, beginMarker)
+	if start < 0 {
+		return content
+	}
+	end := strings.Index(content[start:], endMarker)
+	if end < 0 {
+		return content[:start]
+	}
+	end += start + len(endMarker)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[:start] + content[end:]
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,74 @@
+package githook
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallAndUninstall(t *testing.T) {
+	hooksDir := filepath.Join(t.TempDir(), "hooks")
+
+	block := Block("post-merge", "agent-manager", "agents-config.yaml")
+	if !strings.Contains(block, "install --config 'agents-config.yaml' --quiet") {
+		t.Errorf("Expected block to run a quiet install:\n%s", block)
+	}
+	if err := Install(hooksDir, "post-merge", block); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	// Reinstalling replaces the block instead of duplicating it
+	if err := Install(hooksDir, "post-merge", block); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	path := filepath.Join(hooksDir, "post-merge")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "#!/bin/sh\n") || strings.Count(string(content), beginMarker) != 1 {
+		t.Errorf("Unexpected hook content:\n%s", content)
+	}
+	if !strings.Contains(string(content), "'agent-manager' install --config 'agents-config.yaml'") {
+		t.Errorf("Hook does not run install:\n%s", content)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Error("Expected hook to be executable")
+	}
+
+	found, err := Uninstall(hooksDir, "post-merge")
+	if err != nil || !found {
+		t.Fatalf("Uninstall() = %v, %v", found, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected hook with only the agent-manager block to be removed")
+	}
+}
+
+func TestInstallPreservesExistingHook(t *testing.T) {
+	hooksDir := t.TempDir()
+	existing := "#!/bin/bash\nnpm install\n"
+	if err := os.WriteFile(filepath.Join(hooksDir, "post-checkout"), []byte(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	block := Block("post-checkout", "/usr/local/bin/agent-manager", "/home/me/it's/agents.yaml")
+	if !strings.Contains(block, `if [ "$3" = "1" ]`) || !strings.Contains(block, `'/home/me/it'\''s/agents.yaml'`) {
+		t.Errorf("Unexpected post-checkout block:\n%s", block)
+	}
+	if err := Install(hooksDir, "post-checkout", block); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if !Installed(hooksDir, "post-checkout") {
+		t.Error("Expected hook to be reported as installed")
+	}
+
+	if _, err := Uninstall(hooksDir, "post-checkout"); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(hooksDir, "post-checkout"))
+	if err != nil || string(content) != existing {
+		t.Errorf("Expected original hook to be restored, got %q (%v)", content, err)
+	}
+}
@@ -0,0 +1,82 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+// ApplySource reconciles a source's installed files with its configuration:
+// the source is installed when it is not tracked yet and reinstalled when the
+// files it would install differ from the installed ones, whether because the
+// source moved on, its configuration changed or installed files were edited.
+// It returns the plan that was applied.
+func (i *Installer) ApplySource(ctx context.Context, source config.Source) (*SourcePlan, error) {
+	if i.planOnly(&source) {
+		var plan *SourcePlan
+		err := i.plan(source.Name, func(p *Installer) error {
+			var err error
+			plan, err = p.ApplySource(ctx, source)
+			return err
+		})
+		return plan, err
+	}
+
+	plan, err := i.PlanSource(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan %s: %w", source.Name, err)
+	}
+
+	switch {
+	case plan.Action == PlanUnchanged:
+		color.Green("%s %s is up to date\n", util.Symbol("✓"), source.Name)
+	case i.options.DryRun && plan.Action == PlanAdded:
+		color.Yellow("[DRY RUN] Would install source: %s\n", source.Name)
+		printPlanFiles(plan, i.options.Verbose)
+	case i.options.DryRun:
+		color.Yellow("[DRY RUN] Would reinstall drifted source: %s (%s)\n", source.Name, driftReason(plan))
+		printPlanFiles(plan, i.options.Verbose)
+	case plan.Action == PlanAdded:
+		err = i.InstallSource(ctx, source)
+	default:
+		color.Blue("Reinstalling drifted source %s (%s)...\n", source.Name, driftReason(plan))
+		err = i.reinstall(ctx, source)
+	}
+	return plan, err
+}
+
+// driftReason describes why a planned source differs from its installation
+func driftReason(plan *SourcePlan) string {
+	changed := len(plan.Changed())
+	switch {
+	case plan.ConfigChanged && changed == 0:
+		return "configuration changed"
+	case plan.ConfigChanged:
+		return fmt.Sprintf("configuration changed, %d files differ", changed)
+	default:
+		return fmt.Sprintf("%d files differ", changed)
+	}
+}
+
+// reinstall replaces an installed source with a fresh install of it,
+// restoring the previous files when the install fails
+func (i *Installer) reinstall(ctx context.Context, source config.Source) error {
+	if err := i.resolver.CreateBackup(source.Name); err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	if err := i.UninstallSource(source.Name); err != nil {
+		return fmt.Errorf("failed to uninstall old version: %w", err)
+	}
+
+	if err := i.InstallSource(ctx, source); err != nil {
+		if restoreErr := i.resolver.RestoreBackup(source.Name); restoreErr != nil {
+			color.Yellow("Warning: failed to restore backup after installation failure: %v", restoreErr)
+		}
+		return fmt.Errorf("failed to install update: %w", err)
+	}
+	return nil
+}
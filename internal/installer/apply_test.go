@@ -0,0 +1,94 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/conflict"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+)
+
+func TestApplySource(t *testing.T) {
+	dir := t.TempDir()
+	sourceDir := filepath.Join(dir, "src")
+	targetDir := filepath.Join(dir, "agents")
+	if err := os.MkdirAll(sourceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\nname: reviewer\ndescription: Reviews\n---\nPrompt\n"
+	if err := os.WriteFile(filepath.Join(sourceDir, "reviewer.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Settings: config.Settings{BaseDir: targetDir, ConflictStrategy: "overwrite", BackupDir: filepath.Join(dir, "backups")},
+		Metadata: config.Metadata{TrackingFile: filepath.Join(dir, ".installed.json")},
+	}
+	source := config.Source{
+		Name:    "local",
+		Type:    "local",
+		Enabled: true,
+		Paths:   config.PathConfig{Source: sourceDir, Target: targetDir},
+	}
+	cfg.Sources = []config.Source{source}
+	inst := New(cfg, tracker.New(cfg.Metadata.TrackingFile), conflict.NewResolver("overwrite", cfg.Settings.BackupDir), Options{})
+	installed := filepath.Join(targetDir, "reviewer.md")
+
+	apply := func(want string) {
+		t.Helper()
+		plan, err := inst.ApplySource(context.Background(), source)
+		if err != nil {
+			t.Fatalf("ApplySource() error = %v", err)
+		}
+		if plan.Action != want {
+			t.Errorf("Expected %s, got %s", want, plan.Action)
+		}
+		data, err := os.ReadFile(installed)
+		if err != nil || string(data) != content {
+			t.Errorf("Expected the installed agent to match its source, got %q (%v)", data, err)
+		}
+	}
+
+	apply(PlanAdded)
+	apply(PlanUnchanged)
+
+	// A locally edited agent has drifted and is reinstalled
+	if err := os.WriteFile(installed, []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	apply(PlanChanged)
+	apply(PlanUnchanged)
+}
+
+func TestApplySource_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	sourceDir := filepath.Join(dir, "src")
+	targetDir := filepath.Join(dir, "agents")
+	if err := os.MkdirAll(sourceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "reviewer.md"), []byte("---\nname: reviewer\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Settings: config.Settings{BaseDir: targetDir, BackupDir: filepath.Join(dir, "backups")},
+		Metadata: config.Metadata{TrackingFile: filepath.Join(dir, ".installed.json")},
+	}
+	source := config.Source{Name: "local", Type: "local", Enabled: true, Paths: config.PathConfig{Source: sourceDir, Target: targetDir}}
+	inst := New(cfg, tracker.New(cfg.Metadata.TrackingFile), nil, Options{DryRun: true})
+
+	plan, err := inst.ApplySource(context.Background(), source)
+	if err != nil {
+		t.Fatalf("ApplySource() error = %v", err)
+	}
+	if plan.Action != PlanAdded {
+		t.Errorf("Expected the source to be planned for install, got %s", plan.Action)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "reviewer.md")); !os.IsNotExist(err) {
+		t.Error("Expected a dry run to install nothing")
+	}
+}
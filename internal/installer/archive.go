@@ -0,0 +1,87 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+// ArchiveHandler installs a .zip or .tar.gz archive downloaded from a URL,
+// using the sha256 of the archive as the source version
+type ArchiveHandler struct{}
+
+// Fetch downloads the archive, verifies its checksum when one is configured and extracts it
+func (a *ArchiveHandler) Fetch(ctx context.Context, source config.Source, destDir string) (string, string, error) {
+	if err := util.ValidatePath(destDir); err != nil {
+		return "", "", fmt.Errorf("invalid destination directory: %w", err)
+	}
+	format := source.ArchiveFormat()
+	if format == "" {
+		return "", "", fmt.Errorf("unsupported archive format: %s", source.URL)
+	}
+
+	archivePath := filepath.Join(destDir, "download."+format)
+	digest, err := a.download(ctx, source, archivePath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to download %s: %w", source.URL, err)
+	}
+
+	switch expected := source.Archive.SHA256; {
+	case expected == "":
+		util.DebugPrintf("Downloaded unverified archive %s with sha256 %s\n", source.URL, digest)
+	case !strings.EqualFold(expected, digest):
+		return "", "", fmt.Errorf("checksum mismatch for %s: expected sha256 %s, got %s", source.URL, expected, digest)
+	default:
+		util.DebugPrintf("Verified sha256 of %s: %s\n", source.URL, digest)
+	}
+
+	extractDir := filepath.Join(destDir, "archive")
+	if err := extractArchive(archivePath, extractDir); err != nil {
+		return "", "", fmt.Errorf("failed to extract %s: %w", source.URL, err)
+	}
+	return archiveSourcePath(extractDir, source.Paths.Source), digest, nil
+}
+
+// CheckUpdate compares the sha256 of the archive against the installed one.
+// A pinned archive.sha256 is compared without downloading anything.
+func (a *ArchiveHandler) CheckUpdate(ctx context.Context, source config.Source, currentCommit string) (bool, string, error) {
+	digest := strings.ToLower(source.Archive.SHA256)
+	if digest == "" {
+		var err error
+		if digest, err = a.download(ctx, source, ""); err != nil {
+			return false, "", fmt.Errorf("failed to download %s: %w", source.URL, err)
+		}
+	}
+	return digest != currentCommit, digest, nil
+}
+
+// download writes the archive to path, or only hashes it when path is empty,
+// and returns its sha256
+func (a *ArchiveHandler) download(ctx context.Context, source config.Source, path string) (string, error) {
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, source.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	if token := sourceToken(source); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	applyHTTPOptions(req, source.Auth)
+
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("request aborted: %w", ctx.Err())
+		}
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != nethttp.StatusOK {
+		return "", fmt.Errorf("server returned %s", resp.Status)
+	}
+	return writeDownload(resp.Body, path, maxReleaseAssetSize)
+}
@@ -0,0 +1,74 @@
+package installer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+)
+
+func TestArchiveHandler_Fetch(t *testing.T) {
+	t.Setenv("ARCHIVE_TEST_TOKEN", "secret")
+
+	archive := tarGz(t, map[string]string{"bundle-1.0/agents/reviewer.md": "---\nname: reviewer\n---\n"})
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer secret" || r.URL.Path != "/download" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(archive)
+	}))
+	defer server.Close()
+
+	source := config.Source{
+		Name:    "bundle",
+		Type:    "archive",
+		URL:     server.URL + "/download?version=1.0",
+		Auth:    config.AuthConfig{TokenEnv: "ARCHIVE_TEST_TOKEN"},
+		Archive: config.ArchiveConfig{SHA256: strings.ToUpper(sha256Hex(archive)), Format: "tar.gz"},
+		Paths:   config.PathConfig{Source: "agents"},
+	}
+	handler := &ArchiveHandler{}
+
+	path, version, err := handler.Fetch(context.Background(), source, t.TempDir())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if version != sha256Hex(archive) {
+		t.Errorf("Expected the archive digest as version, got %s", version)
+	}
+	if _, err := os.Stat(filepath.Join(path, "reviewer.md")); err != nil {
+		t.Errorf("Expected the agent below the archive's top-level directory: %v", err)
+	}
+
+	// A pinned digest is compared without downloading the archive again
+	requests = 0
+	hasUpdate, latest, err := handler.CheckUpdate(context.Background(), source, version)
+	if err != nil || hasUpdate || latest != version || requests != 0 {
+		t.Errorf("Expected no update and no request, got hasUpdate=%v latest=%s requests=%d err=%v", hasUpdate, latest, requests, err)
+	}
+
+	// Without one, the archive is downloaded and hashed
+	source.Archive.SHA256 = ""
+	hasUpdate, latest, err = handler.CheckUpdate(context.Background(), source, "old")
+	if err != nil || !hasUpdate || latest != version || requests != 1 {
+		t.Errorf("Expected an update to %s, got hasUpdate=%v latest=%s requests=%d err=%v", version, hasUpdate, latest, requests, err)
+	}
+
+	source.Archive.SHA256 = strings.Repeat("a", 64)
+	if _, _, err := handler.Fetch(context.Background(), source, t.TempDir()); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+
+	source.URL = server.URL + "/missing.zip"
+	if _, _, err := handler.Fetch(context.Background(), source, t.TempDir()); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected the HTTP status to be reported, got %v", err)
+	}
+}
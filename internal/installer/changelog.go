@@ -0,0 +1,189 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+const (
+	// maxChangelogScan bounds the history walked when the old commit is not an ancestor
+	maxChangelogScan = 1000
+	// maxChangelogLines is how many commits an update summary prints
+	maxChangelogLines = 20
+)
+
+// ChangelogEntry is a commit touching a source's path between two updates
+type ChangelogEntry struct {
+	Commit  string    `json:"commit"`
+	Subject string    `json:"subject"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+}
+
+// Changelog lists the commits an update brought in for a source, newest first
+type Changelog struct {
+	Source  string           `json:"source"`
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	Entries []ChangelogEntry `json:"entries"`
+	// Truncated is set when the old commit was not found in the history walked
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// collectChangelog records the commits of a git source fetched for an update
+// since its installed commit; failures only cost the changelog
+func (i *Installer) collectChangelog(source config.Source, fetchedPath, commit string) {
+	from := i.changelogFrom[source.Name]
+	delete(i.changelogFrom, source.Name)
+	if from == "" || from == commit || (source.Type != "git" && source.Type != "github" && source.Type != "gitlab") {
+		return
+	}
+	changelog, err := sourceChangelog(fetchedPath, source.Paths.Source, from, commit)
+	if err != nil {
+		util.DebugPrintf("No changelog for %s: %v\n", source.Name, err)
+		return
+	}
+	changelog.Source = source.Name
+	if i.changelogs == nil {
+		i.changelogs = make(map[string]*Changelog)
+	}
+	i.changelogs[source.Name] = changelog
+}
+
+// sourceChangelog collects the commits from..to of the repository containing
+// fetchedPath that change sourcePath, the source's path within the repository
+func sourceChangelog(fetchedPath, sourcePath, from, to string) (*Changelog, error) {
+	repo, err := git.PlainOpenWithOptions(fetchedPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	commits, err := repo.Log(&git.LogOptions{From: plumbing.NewHash(to)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer commits.Close()
+
+	dir := strings.Trim(filepath.ToSlash(filepath.Clean(sourcePath)), "/")
+	if dir == "." {
+		dir = ""
+	}
+
+	changelog := &Changelog{From: from, To: to, Truncated: true}
+	scanned := 0
+	err = commits.ForEach(func(commit *object.Commit) error {
+		if commit.Hash.String() == from {
+			changelog.Truncated = false
+			return storer.ErrStop
+		}
+		if scanned++; scanned > maxChangelogScan {
+			return storer.ErrStop
+		}
+		if !touchesPath(commit, dir) {
+			return nil
+		}
+		changelog.Entries = append(changelog.Entries, ChangelogEntry{
+			Commit:  commit.Hash.String(),
+			Subject: strings.TrimSpace(strings.SplitN(commit.Message, "\n", 2)[0]),
+			Author:  commit.Author.Name,
+			Date:    commit.Author.When,
+		})
+		return nil
+	})
+	if err != nil && !errors.Is(err, storer.ErrStop) {
+		return nil, fmt.Errorf("failed to walk history: %w", err)
+	}
+	return changelog, nil
+}
+
+// touchesPath reports whether commit changes dir compared with its first parent
+func touchesPath(commit *object.Commit, dir string) bool {
+	current, err := pathHash(commit, dir)
+	if err != nil {
+		return false
+	}
+	if commit.NumParents() == 0 {
+		return !current.IsZero()
+	}
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return true
+	}
+	previous, err := pathHash(parent, dir)
+	if err != nil {
+		return true
+	}
+	return current != previous
+}
+
+// pathHash returns the hash of the tree or blob at dir in commit, or the zero
+// hash when the path does not exist
+func pathHash(commit *object.Commit, dir string) (plumbing.Hash, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if dir == "" {
+		return tree.Hash, nil
+	}
+	entry, err := tree.FindEntry(dir)
+	if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+		return plumbing.ZeroHash, nil
+	}
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return entry.Hash, nil
+}
+
+// Print writes the update summary lines of the changelog
+func (c *Changelog) Print() {
+	if len(c.Entries) == 0 {
+		fmt.Printf("No commits changed %s between %s and %s\n", c.Source, util.ShortCommit(c.From), util.ShortCommit(c.To))
+		return
+	}
+	fmt.Printf("Changes in %s (%s..%s):\n", c.Source, util.ShortCommit(c.From), util.ShortCommit(c.To))
+	for n, entry := range c.Entries {
+		if n == maxChangelogLines {
+			fmt.Printf("  ... and %d more commits\n", len(c.Entries)-n)
+			break
+		}
+		fmt.Printf("  %s %s (%s)\n", util.ShortCommit(entry.Commit), entry.Subject, entry.Author)
+	}
+	if c.Truncated {
+		fmt.Printf("  (history before these commits was not searched; %s may have been rewritten)\n", util.ShortCommit(c.From))
+	}
+}
+
+// appendChangelog records an update and its commits in the installation log
+func appendChangelog(logFile string, changelog *Changelog) error {
+	if err := os.MkdirAll(filepath.Dir(logFile), 0750); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entry strings.Builder
+	fmt.Fprintf(&entry, "%s update source=%s from=%s to=%s commits=%d\n",
+		time.Now().Format(time.RFC3339), changelog.Source, util.ShortCommit(changelog.From), util.ShortCommit(changelog.To), len(changelog.Entries))
+	for _, commit := range changelog.Entries {
+		fmt.Fprintf(&entry, "  %s %s\n", util.ShortCommit(commit.Commit), commit.Subject)
+	}
+	if _, err := f.WriteString(entry.String()); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestSourceChangelog(t *testing.T) {
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repo: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(message, file, content string) string {
+		t.Helper()
+		path := filepath.Join(repoDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add(file); err != nil {
+			t.Fatal(err)
+		}
+		hash, err := worktree.Commit(message, &git.CommitOptions{
+			Author: &object.Signature{Name: "alice", Email: "alice@example.com", When: time.Now()},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash.String()
+	}
+
+	installed := commit("Add reviewer", "agents/reviewer.md", "v1")
+	commit("Update README", "README.md", "docs")
+	commit("Tighten reviewer prompt\n\nLonger explanation.", "agents/reviewer.md", "v2")
+	head := commit("Add planner", "agents/planner.md", "planner")
+
+	changelog, err := sourceChangelog(filepath.Join(repoDir, "agents"), "agents", installed, head)
+	if err != nil {
+		t.Fatalf("sourceChangelog() error = %v", err)
+	}
+	var subjects []string
+	for _, entry := range changelog.Entries {
+		subjects = append(subjects, entry.Subject)
+	}
+	if got := strings.Join(subjects, "|"); got != "Add planner|Tighten reviewer prompt" {
+		t.Errorf("Expected commits touching agents/, newest first, got %q", got)
+	}
+	if changelog.Truncated {
+		t.Error("Expected the installed commit to be found")
+	}
+
+	// A root source path sees every commit
+	changelog, err = sourceChangelog(repoDir, "", installed, head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changelog.Entries) != 3 {
+		t.Errorf("Expected 3 commits for the repository root, got %d", len(changelog.Entries))
+	}
+
+	// An unknown installed commit walks the whole history
+	changelog, err = sourceChangelog(repoDir, "agents", strings.Repeat("0", 40), head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changelog.Truncated || len(changelog.Entries) != 3 {
+		t.Errorf("Expected a truncated changelog of 3 commits, got %+v", changelog)
+	}
+
+	logFile := filepath.Join(t.TempDir(), "logs", "installation.log")
+	changelog.Source = "team-agents"
+	if err := appendChangelog(logFile, changelog); err != nil {
+		t.Fatalf("appendChangelog() error = %v", err)
+	}
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "update source=team-agents") || !strings.Contains(string(content), "Add planner") {
+		t.Errorf("Unexpected audit log %q", content)
+	}
+}
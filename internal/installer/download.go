@@ -0,0 +1,129 @@
+package installer
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/marketplace"
+)
+
+// ResumeMaxAge is how long downloaded agent content is kept for resuming an
+// interrupted marketplace fetch
+const ResumeMaxAge = 24 * time.Hour
+
+// ResumeRoot returns the directory marketplace sources checkpoint downloaded
+// agent content in, one subdirectory per source, next to the tracking file
+func ResumeRoot(trackingFile string) string {
+	return filepath.Join(filepath.Dir(trackingFile), "marketplace-resume")
+}
+
+// agentFailure records why the content of one marketplace agent could not be downloaded
+type agentFailure struct {
+	Name   string
+	Reason string
+}
+
+// agentDownloader downloads marketplace agent content one agent at a time,
+// each under its own timeout. When resumeDir is set, downloaded content is
+// checkpointed there so a re-run after an interruption skips finished agents.
+type agentDownloader struct {
+	timeout   time.Duration
+	resumeDir string
+	get       func(ctx context.Context, agentID string) (string, error)
+	// done is called after each agent, downloaded or not
+	done func()
+}
+
+// download returns the content of each agent that was downloaded, keyed by
+// agent ID, and the agents that failed. It errors when ctx is cancelled so
+// that an interrupted fetch never installs a partial set of agents.
+func (d *agentDownloader) download(ctx context.Context, agents []marketplace.Agent) (map[string]string, []agentFailure, error) {
+	contents := make(map[string]string, len(agents))
+	var failures []agentFailure
+
+	if d.resumeDir != "" {
+		if err := os.MkdirAll(d.resumeDir, 0750); err != nil {
+			return nil, nil, fmt.Errorf("failed to create resume directory: %w", err)
+		}
+	}
+
+	for n, agent := range agents {
+		if ctx.Err() != nil {
+			return nil, nil, abortedDownload(ctx, n, len(agents))
+		}
+
+		if content, ok := d.resumed(agent.ID); ok {
+			contents[agent.ID] = content
+			d.finish()
+			continue
+		}
+
+		agentCtx, cancel := context.WithTimeout(ctx, d.timeout)
+		content, err := d.get(agentCtx, agent.ID)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, abortedDownload(ctx, n, len(agents))
+			}
+			failures = append(failures, agentFailure{Name: agent.Name, Reason: err.Error()})
+			d.finish()
+			continue
+		}
+
+		contents[agent.ID] = content
+		d.checkpoint(agent.ID, content)
+		d.finish()
+	}
+
+	// A complete run has nothing left to resume
+	if d.resumeDir != "" && len(failures) == 0 {
+		_ = os.RemoveAll(d.resumeDir)
+	}
+	return contents, failures, nil
+}
+
+// abortedDownload reports how far a cancelled download got
+func abortedDownload(ctx context.Context, completed, total int) error {
+	return fmt.Errorf("download aborted after %d of %d agents: %w; re-run to resume", completed, total, ctx.Err())
+}
+
+func (d *agentDownloader) finish() {
+	if d.done != nil {
+		d.done()
+	}
+}
+
+// resumePath returns the checkpoint file of an agent; IDs are hashed since
+// they may contain path separators
+func (d *agentDownloader) resumePath(agentID string) string {
+	return filepath.Join(d.resumeDir, fmt.Sprintf("%x", sha256.Sum256([]byte(agentID))))
+}
+
+// resumed returns checkpointed content for an agent when it is recent enough to reuse
+func (d *agentDownloader) resumed(agentID string) (string, bool) {
+	if d.resumeDir == "" {
+		return "", false
+	}
+	path := d.resumePath(agentID)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > ResumeMaxAge {
+		return "", false
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(content), true
+}
+
+// checkpoint saves downloaded content; failures only cost a re-download on resume
+func (d *agentDownloader) checkpoint(agentID, content string) {
+	if d.resumeDir == "" {
+		return
+	}
+	_ = os.WriteFile(d.resumePath(agentID), []byte(content), 0600)
+}
@@ -0,0 +1,85 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/marketplace"
+)
+
+func TestAgentDownloader(t *testing.T) {
+	agents := []marketplace.Agent{
+		{ID: "fast", Name: "Fast"},
+		{ID: "slow", Name: "Slow"},
+		{ID: "broken", Name: "Broken"},
+	}
+	calls := make(map[string]int)
+	get := func(ctx context.Context, id string) (string, error) {
+		calls[id]++
+		switch id {
+		case "slow":
+			<-ctx.Done()
+			return "", ctx.Err()
+		case "broken":
+			return "", errors.New("page not found")
+		}
+		return "content of " + id, nil
+	}
+
+	resumeDir := filepath.Join(t.TempDir(), "resume")
+	done := 0
+	d := &agentDownloader{timeout: 10 * time.Millisecond, resumeDir: resumeDir, get: get, done: func() { done++ }}
+
+	contents, failures, err := d.download(context.Background(), agents)
+	if err != nil {
+		t.Fatalf("download() error = %v", err)
+	}
+	if contents["fast"] != "content of fast" || len(contents) != 1 {
+		t.Errorf("contents = %v, want only fast", contents)
+	}
+	if len(failures) != 2 || failures[0].Name != "Slow" || !strings.Contains(failures[0].Reason, "deadline exceeded") ||
+		failures[1].Name != "Broken" || failures[1].Reason != "page not found" {
+		t.Errorf("failures = %+v", failures)
+	}
+	if done != len(agents) {
+		t.Errorf("done called %d times, want %d", done, len(agents))
+	}
+
+	// A re-run reuses the checkpointed agent and retries the failures
+	if _, _, err := d.download(context.Background(), agents); err != nil {
+		t.Fatalf("download() error = %v", err)
+	}
+	if calls["fast"] != 1 || calls["broken"] != 2 {
+		t.Errorf("calls = %v, want fast resumed and broken retried", calls)
+	}
+
+	// A complete run removes the checkpoints
+	if _, _, err := d.download(context.Background(), agents[:1]); err != nil {
+		t.Fatalf("download() error = %v", err)
+	}
+	if _, err := os.Stat(resumeDir); !os.IsNotExist(err) {
+		t.Errorf("Expected resume directory to be removed, got %v", err)
+	}
+}
+
+func TestAgentDownloader_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	get := func(ctx context.Context, id string) (string, error) {
+		cancel()
+		return "", ctx.Err()
+	}
+	d := &agentDownloader{timeout: time.Second, get: get}
+
+	_, _, err := d.download(ctx, []marketplace.Agent{{ID: "a"}, {ID: "b"}})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("download() error = %v, want context.Canceled", err)
+	}
+	if !strings.Contains(err.Error(), "after 0 of 2 agents") {
+		t.Errorf("Expected progress in error, got %v", err)
+	}
+}
@@ -0,0 +1,301 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/archive"
+	"github.com/pacphi/claude-code-agent-manager/internal/artifact"
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/transformer"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+// Explanation stages
+const (
+	StageFetch     = "fetch"
+	StageFilter    = "filter"
+	StageTransform = "transform"
+	StageKind      = "kind"
+	StageLimit     = "limit"
+	StageLicense   = "license"
+	StageConflict  = "conflict"
+)
+
+// Decision is one step of an explanation and the rule that decided it
+type Decision struct {
+	Stage  string `json:"stage"`
+	Result string `json:"result"`
+	Rule   string `json:"rule,omitempty"`
+}
+
+// Explanation walks one file of a source through everything install would do to it
+type Explanation struct {
+	Source    string     `json:"source"`
+	File      string     `json:"file"`
+	Target    string     `json:"target,omitempty"`
+	Installed bool       `json:"installed"`
+	Decisions []Decision `json:"decisions"`
+}
+
+func (e *Explanation) add(stage, result, rule string) {
+	e.Decisions = append(e.Decisions, Decision{Stage: stage, Result: result, Rule: rule})
+}
+
+// Explain reports how installing source would treat file, a path relative to
+// the source directory: the filters, transformations, limits and conflict
+// strategy that apply, in order, and whether the file would be installed. The
+// source is fetched to a temporary directory; nothing is installed.
+func (i *Installer) Explain(ctx context.Context, source config.Source, file string) (*Explanation, error) {
+	relPath := filepath.Clean(file)
+	explanation := &Explanation{Source: source.Name, File: relPath}
+
+	_, fetchedPath, _, tempDir, err := i.fetchSource(ctx, source)
+	if tempDir != "" {
+		defer i.cleanupTempDir(tempDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if info, err := os.Stat(filepath.Join(fetchedPath, relPath)); err != nil || info.IsDir() {
+		explanation.add(StageFetch, "not found", fmt.Sprintf("no file %s in the fetched source", relPath))
+		return explanation, nil
+	}
+	explanation.add(StageFetch, "found", "")
+
+	included, rule := explainFilters(relPath, source.Filters)
+	if !included {
+		explanation.add(StageFilter, "excluded", rule)
+		return explanation, nil
+	}
+	explanation.add(StageFilter, "included", rule)
+
+	files, err := i.applyFilters(fetchedPath, source.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply filters: %w", err)
+	}
+	installPath, ok, err := i.explainTransformations(explanation, source, files, relPath)
+	if err != nil || !ok {
+		return explanation, err
+	}
+
+	kind := source.ArtifactKind()
+	extensions := i.config.Settings.Query.Index.Extensions
+	srcPath := filepath.Join(fetchedPath, relPath)
+	if kind != config.KindAgent && artifact.IsArtifactFile(kind, installPath, extensions) {
+		if err := artifact.Validate(kind, srcPath); err != nil {
+			explanation.add(StageKind, "skipped", fmt.Sprintf("invalid %s: %v", kind, err))
+			return explanation, nil
+		}
+		explanation.add(StageKind, "valid", "kind "+kind)
+	}
+
+	if kind == config.KindAgent && parser.IsAgentFile(installPath, extensions) {
+		if !i.explainAgentPolicies(explanation, source.Name, srcPath) {
+			return explanation, nil
+		}
+	}
+
+	explanation.Target = filepath.Join(i.resolveTargetPath(source.Paths.Target), installPath)
+	explanation.Installed = i.explainConflict(explanation, source, srcPath)
+	return explanation, nil
+}
+
+// explainFilters reports whether relPath passes filters and the rule that decided it
+func explainFilters(relPath string, filters config.FilterConfig) (bool, string) {
+	fileName := filepath.Base(relPath)
+	for _, pattern := range filters.Exclude.Patterns {
+		if isExcluded(relPath, fileName, []string{pattern}) {
+			return false, fmt.Sprintf("filters.exclude.patterns %q", pattern)
+		}
+	}
+	if hasNoIncludeFilters(filters) {
+		return true, "no include filters"
+	}
+	for _, extension := range filters.Include.Extensions {
+		if matchesIncludeExtensions(fileName, []string{extension}) {
+			return true, fmt.Sprintf("filters.include.extensions %q", extension)
+		}
+	}
+	for _, pattern := range filters.Include.Patterns {
+		if matchesIncludePatterns(relPath, fileName, []string{pattern}) {
+			return true, fmt.Sprintf("filters.include.patterns %q", pattern)
+		}
+	}
+	for _, regex := range filters.Include.Regex {
+		if matchesIncludeRegex(relPath, []string{regex}) {
+			return true, fmt.Sprintf("filters.include.regex %q", regex)
+		}
+	}
+	return false, "matches no include extension, pattern or regex"
+}
+
+// explainTransformations follows relPath through the source's transformations,
+// returning the path it is installed under and whether it reaches the target
+func (i *Installer) explainTransformations(explanation *Explanation, source config.Source, files []string, relPath string) (string, bool, error) {
+	index := indexOf(files, relPath)
+	// Local sources are fetched in place, so path-rewriting transformations
+	// run against an empty directory to leave the source untouched
+	scratch, err := util.MkdirTemp("agent-explain-*")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer i.cleanupTempDir(scratch)
+
+	trans := transformer.New(i.config.Settings)
+	current := relPath
+	for n, transform := range source.Transformations {
+		rule := fmt.Sprintf("transformations[%d] %s", n, transform.Type)
+		switch transform.Type {
+		case "extract_docs":
+			pattern := transform.SourcePattern
+			if pattern == "" {
+				pattern = "*/README.md"
+			}
+			if matched, _ := filepath.Match(pattern, current); matched {
+				explanation.add(StageTransform, "extracted to docs", fmt.Sprintf("%s source_pattern %q", rule, pattern))
+				return "", false, nil
+			}
+			remaining := files[:0:0]
+			for _, file := range files {
+				if matched, _ := filepath.Match(pattern, file); !matched {
+					remaining = append(remaining, file)
+				}
+			}
+			files = remaining
+			index = indexOf(files, current)
+			explanation.add(StageTransform, "unchanged", rule)
+		case "custom_script":
+			explanation.add(StageTransform, "not evaluated", fmt.Sprintf("%s %s is not run while explaining", rule, transform.Script))
+		default:
+			transformed, err := trans.Apply(files, transform, scratch, i.resolveTargetPath(source.Paths.Target))
+			if err != nil {
+				return "", false, fmt.Errorf("transformation failed: %w", err)
+			}
+			// Path-rewriting transformations keep one entry per file, in order
+			if index < 0 || len(transformed) != len(files) {
+				return "", false, fmt.Errorf("cannot follow %s through %s", current, rule)
+			}
+			next := filepath.Clean(transformed[index])
+			if next == current {
+				explanation.add(StageTransform, "unchanged", rule)
+			} else {
+				explanation.add(StageTransform, "renamed to "+next, rule)
+			}
+			files, current = transformed, next
+		}
+	}
+	return current, true, nil
+}
+
+// indexOf returns the position of path in files, or -1
+func indexOf(files []string, path string) int {
+	for n, file := range files {
+		if filepath.Clean(file) == path {
+			return n
+		}
+	}
+	return -1
+}
+
+// explainAgentPolicies applies settings.limits and settings.licenses to the
+// agent file at srcPath, reporting whether install would go on to copy it
+func (i *Installer) explainAgentPolicies(explanation *Explanation, sourceName, srcPath string) bool {
+	limits := i.config.Settings.Limits
+	if info, err := os.Stat(srcPath); err == nil {
+		maxBytes := limits.MaxAgentFileBytes()
+		rule := fmt.Sprintf("settings.limits.max_agent_file_kb %d", limits.MaxAgentFileKB)
+		switch {
+		case maxBytes == 0:
+			explanation.add(StageLimit, "no limit", "")
+		case info.Size() <= maxBytes:
+			explanation.add(StageLimit, "within limit", rule)
+		case limits.OnExceed == "skip":
+			explanation.add(StageLimit, "skipped", rule+", on_exceed skip")
+			return false
+		case limits.OnExceed == "fail":
+			explanation.add(StageLimit, "install fails", rule+", on_exceed fail")
+			return false
+		default:
+			explanation.add(StageLimit, "warned", rule+", on_exceed warn")
+		}
+	}
+
+	policy := i.config.Settings.Licenses
+	license := agentLicense(srcPath)
+	violation := policy.Violation(sourceName, license)
+	switch {
+	case violation == "" && license == "":
+		explanation.add(StageLicense, "allowed", "no license declared and none required")
+	case violation == "":
+		explanation.add(StageLicense, "allowed", "license "+license)
+	case policy.OnViolation == "warn":
+		explanation.add(StageLicense, "warned", violation+", on_violation warn")
+	case policy.OnViolation == "fail":
+		explanation.add(StageLicense, "install fails", violation+", on_violation fail")
+		return false
+	default:
+		explanation.add(StageLicense, "skipped", violation+", on_violation skip")
+		return false
+	}
+	return true
+}
+
+// explainConflict reports what the conflict strategy would do with an
+// existing file at the explanation's target, and whether the file is installed
+func (i *Installer) explainConflict(explanation *Explanation, source config.Source, srcPath string) bool {
+	dstPath := explanation.Target
+	if entries, err := archive.New(archive.DefaultDir(i.config.Metadata.TrackingFile)).List(); err == nil {
+		for _, entry := range entries {
+			if entry.Original == absPath(dstPath) {
+				explanation.add(StageConflict, "kept in archive", "archived agent; run 'agent-manager unarchive' to restore it")
+				return false
+			}
+		}
+	}
+
+	if pattern := i.readonlyPattern(source.Name, dstPath); pattern != "" {
+		explanation.add(StageConflict, "not written", fmt.Sprintf("readonly_paths %s", pattern))
+		return false
+	}
+
+	if _, err := os.Stat(dstPath); err != nil {
+		explanation.add(StageConflict, "new file", "no existing file at the target")
+		return true
+	}
+
+	existing := "pre-existing file"
+	if installed, err := i.tracker.GetInstallation(source.Name); err == nil && installed != nil {
+		for path := range installed.Files {
+			if absPath(path) == absPath(dstPath) {
+				existing = "file installed by " + source.Name
+			}
+		}
+	}
+	if same, err := sameContent(srcPath, dstPath); err == nil && same {
+		existing += " with identical content"
+	}
+
+	strategy, rule := source.ConflictStrategy, "sources["+source.Name+"].conflict_strategy"
+	if strategy == "" {
+		strategy, rule = i.config.Settings.ConflictStrategy, "settings.conflict_strategy"
+	}
+	rule = fmt.Sprintf("%s %s", rule, strategy)
+
+	switch strategy {
+	case "skip":
+		explanation.add(StageConflict, existing+" kept", rule)
+		return false
+	case "overwrite":
+		explanation.add(StageConflict, existing+" replaced", rule)
+	case "merge":
+		explanation.add(StageConflict, existing+" backed up and merged", rule)
+	default:
+		explanation.add(StageConflict, existing+" backed up and replaced", rule)
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/conflict"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+)
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	sourceDir := filepath.Join(dir, "src")
+	targetDir := filepath.Join(dir, "agents")
+	for _, d := range []string{filepath.Join(sourceDir, "01-core"), filepath.Join(targetDir, "core")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(path, content string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(sourceDir, "01-core", "reviewer.md"), "---\nname: reviewer\ndescription: Reviews\nlicense: MIT\n---\nPrompt\n")
+	write(filepath.Join(sourceDir, "01-core", "writer.md"), "---\nname: writer\ndescription: Writes\n---\nPrompt\n")
+	write(filepath.Join(sourceDir, "notes.txt"), "not an agent")
+
+	cfg := &config.Config{
+		Settings: config.Settings{
+			BaseDir:          targetDir,
+			ConflictStrategy: "backup",
+			BackupDir:        filepath.Join(dir, "backups"),
+			Licenses:         config.LicensePolicy{Allowed: []string{"MIT"}, OnViolation: "skip"},
+		},
+		Metadata: config.Metadata{TrackingFile: filepath.Join(dir, ".installed.json")},
+	}
+	source := config.Source{
+		Name:             "local",
+		Type:             "local",
+		Paths:            config.PathConfig{Source: sourceDir, Target: targetDir},
+		ConflictStrategy: "skip",
+		Filters: config.FilterConfig{
+			Include: config.IncludeFilter{Extensions: []string{".md"}},
+			Exclude: config.ExcludeFilter{Patterns: []string{"drafts/*"}},
+		},
+		Transformations: []config.Transformation{{Type: "remove_numeric_prefix"}},
+	}
+	write(filepath.Join(targetDir, "core", "reviewer.md"), "local edits")
+	inst := New(cfg, tracker.New(cfg.Metadata.TrackingFile), conflict.NewResolver("backup", cfg.Settings.BackupDir), Options{})
+
+	tests := []struct {
+		file       string
+		wantStages []string
+		wantResult string
+	}{
+		{file: "missing.md", wantStages: []string{StageFetch}, wantResult: "not found"},
+		{file: "notes.txt", wantStages: []string{StageFetch, StageFilter}, wantResult: "excluded"},
+		{file: "01-core/writer.md", wantStages: []string{StageFetch, StageFilter, StageTransform, StageLimit, StageLicense}, wantResult: "skipped"},
+		{file: "01-core/reviewer.md", wantStages: []string{StageFetch, StageFilter, StageTransform, StageLimit, StageLicense, StageConflict}, wantResult: "pre-existing file kept"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			explanation, err := inst.Explain(context.Background(), source, tt.file)
+			if err != nil {
+				t.Fatalf("Explain() error = %v", err)
+			}
+			if explanation.Installed {
+				t.Errorf("Expected %s not to be installed", tt.file)
+			}
+			if len(explanation.Decisions) != len(tt.wantStages) {
+				t.Fatalf("Expected stages %v, got %+v", tt.wantStages, explanation.Decisions)
+			}
+			for n, stage := range tt.wantStages {
+				if explanation.Decisions[n].Stage != stage {
+					t.Errorf("Decision %d stage = %s, want %s", n, explanation.Decisions[n].Stage, stage)
+				}
+			}
+			if last := explanation.Decisions[len(explanation.Decisions)-1]; last.Result != tt.wantResult {
+				t.Errorf("Final result = %q, want %q", last.Result, tt.wantResult)
+			}
+		})
+	}
+
+	explanation, err := inst.Explain(context.Background(), source, "01-core/reviewer.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := explanation.Decisions[2]; got.Result != "renamed to core/reviewer.md" {
+		t.Errorf("Expected the numeric prefix to be removed, got %+v", got)
+	}
+	if got := explanation.Decisions[5]; got.Rule != "sources[local].conflict_strategy skip" {
+		t.Errorf("Expected the source conflict strategy as the rule, got %+v", got)
+	}
+}
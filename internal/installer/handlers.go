@@ -3,49 +3,82 @@ package installer
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	nethttp "net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/fatih/color"
 	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/credentials"
 	"github.com/pacphi/claude-code-agent-manager/internal/marketplace"
 	"github.com/pacphi/claude-code-agent-manager/internal/progress"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
 	"github.com/pacphi/claude-code-agent-manager/internal/util"
 )
 
 // SourceHandler interface for different source types
 type SourceHandler interface {
-	Fetch(source config.Source, destDir string) (string, string, error)
-	CheckUpdate(source config.Source, currentCommit string) (bool, string, error)
+	Fetch(ctx context.Context, source config.Source, destDir string) (string, string, error)
+	CheckUpdate(ctx context.Context, source config.Source, currentCommit string) (bool, string, error)
 }
 
-// GitHubHandler handles GitHub repositories
-type GitHubHandler struct{}
+// CategorizedHandler is implemented by handlers that can report which fetched
+// files belong to which marketplace category after a Fetch
+type CategorizedHandler interface {
+	FetchedCategories() map[string]FetchedCategory
+}
 
-// Fetch clones a GitHub repository
-func (g *GitHubHandler) Fetch(source config.Source, destDir string) (string, string, error) {
-	// Try using gh CLI first
-	if commandExists("gh") {
-		return g.fetchWithGH(source, destDir)
-	}
+// FetchedCategory describes the files and version fetched for one category
+type FetchedCategory struct {
+	Version string
+	Files   []string // file paths relative to the fetched path
+}
 
-	// Fall back to git
-	gitURL := fmt.Sprintf("https://github.com/%s.git", source.Repository)
-	gitSource := source
-	gitSource.URL = gitURL
+// githubAPIURL is the base URL of the GitHub REST API
+const githubAPIURL = "https://api.github.com"
 
-	handler := &GitHandler{}
-	return handler.Fetch(gitSource, destDir)
+// githubURL is the base URL for cloning GitHub repositories over HTTPS
+const githubURL = "https://github.com"
+
+// GitHub clone backends selectable with a source's prefer setting
+const (
+	backendGH    = "gh"
+	backendGoGit = "go-git"
+)
+
+// GitHubHandler handles GitHub repositories
+type GitHubHandler struct {
+	apiURL string // overrides githubAPIURL when set
+	gitURL string // overrides githubURL when set
 }
 
-func (g *GitHubHandler) fetchWithGH(source config.Source, destDir string) (string, string, error) {
+// Fetch clones a GitHub repository with the gh CLI or go-git. Both backends
+// use the same token, branch and output layout, so the returned path and
+// commit do not depend on which one ran.
+func (g *GitHubHandler) Fetch(ctx context.Context, source config.Source, destDir string) (string, string, error) {
+	// Custom headers can only be sent by go-git, so gh is skipped when they are set
+	backend, err := githubBackend(source.Prefer, commandExists("gh") && !source.Auth.HasHTTPOptions())
+	if err != nil {
+		return "", "", err
+	}
+
 	// Validate inputs
 	if err := util.ValidateRepository(source.Repository); err != nil {
 		return "", "", fmt.Errorf("invalid repository: %w", err)
@@ -53,42 +86,161 @@ func (g *GitHubHandler) fetchWithGH(source config.Source, destDir string) (strin
 	if err := util.ValidateBranch(source.Branch); err != nil {
 		return "", "", fmt.Errorf("invalid branch: %w", err)
 	}
+	if err := util.ValidateBranch(source.Tag); err != nil {
+		return "", "", fmt.Errorf("invalid tag: %w", err)
+	}
 	if err := util.ValidatePath(destDir); err != nil {
 		return "", "", fmt.Errorf("invalid destination directory: %w", err)
 	}
 
+	var sourcePath, commit string
+	if backend == backendGH {
+		sourcePath, commit, err = g.fetchWithGH(ctx, source, destDir)
+	} else {
+		sourcePath, commit, err = (&GitHandler{}).Fetch(ctx, g.gitSource(source), destDir)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", "", fmt.Errorf("clone of %s aborted: %w", source.Repository, ctx.Err())
+		}
+		return "", "", fmt.Errorf("failed to clone %s with %s: %w", source.Repository, backend, err)
+	}
+	return sourcePath, commit, nil
+}
+
+// githubBackend selects the clone backend for a prefer setting; without a
+// preference gh is used when installed
+func githubBackend(prefer string, ghAvailable bool) (string, error) {
+	switch prefer {
+	case backendGH:
+		if !ghAvailable {
+			return "", fmt.Errorf("source prefers gh but the GitHub CLI is not installed")
+		}
+		return backendGH, nil
+	case backendGoGit:
+		return backendGoGit, nil
+	case "":
+		if ghAvailable {
+			return backendGH, nil
+		}
+		return backendGoGit, nil
+	default:
+		return "", fmt.Errorf("invalid prefer value: %s (must be gh or go-git)", prefer)
+	}
+}
+
+// sourceToken returns the token configured for a source, if any, read from
+// its keychain entry for keychain auth and from token_env otherwise
+func sourceToken(source config.Source) string {
+	if source.Auth.Method == "keychain" {
+		return keychainToken(source)
+	}
+	if source.Auth.TokenEnv == "" {
+		return ""
+	}
+	return os.Getenv(source.Auth.TokenEnv)
+}
+
+// credentialWarnings records sources already warned about a missing keychain token
+var credentialWarnings sync.Map
+
+// keychainToken reads the token of a source with keychain auth, warning once
+// per source when it cannot be read
+func keychainToken(source config.Source) string {
+	store, err := credentials.New(source.Auth.Helper, SourceURL(source))
+	var token string
+	if err == nil {
+		token, err = store.Get(context.Background(), source.Auth.CredentialName(source.Name))
+	}
+	if err != nil {
+		if _, warned := credentialWarnings.LoadOrStore(source.Name, true); !warned {
+			fmt.Printf("Warning: no token for %s: %v; run 'agent-manager auth login %s'\n", source.Name, err, source.Name)
+		}
+		return ""
+	}
+	return token
+}
+
+// SourceURL returns the git URL of a git, github or gitlab source
+func SourceURL(source config.Source) string {
+	if source.Type == "gitlab" {
+		return fmt.Sprintf("%s/%s.git", gitlabInstanceURL(source), source.Repository)
+	}
+	if source.URL == "" && source.Repository != "" {
+		return fmt.Sprintf("%s/%s.git", githubURL, source.Repository)
+	}
+	return source.URL
+}
+
+// gitSource converts a GitHub source into the equivalent generic git source,
+// authenticating with the source token whenever one is available
+func (g *GitHubHandler) gitSource(source config.Source) config.Source {
+	baseURL := g.gitURL
+	if baseURL == "" {
+		baseURL = githubURL
+	}
+
+	gitSource := source
+	gitSource.URL = fmt.Sprintf("%s/%s.git", baseURL, source.Repository)
+	if source.Auth.Method != "keychain" && sourceToken(source) != "" {
+		gitSource.Auth.Method = "token"
+	}
+	return gitSource
+}
+
+func (g *GitHubHandler) fetchWithGH(ctx context.Context, source config.Source, destDir string) (string, string, error) {
 	clonePath, err := util.SecureJoin(destDir, "repo")
 	if err != nil {
 		return "", "", fmt.Errorf("failed to create secure clone path: %w", err)
 	}
 
-	// Build gh command with validated arguments
+	// Build gh command with validated arguments; the flags after -- are passed to git clone
 	args := []string{"repo", "clone", source.Repository, clonePath}
+	var gitArgs []string
 
-	if source.Branch != "" && source.Branch != "main" {
-		args = append(args, "--", "-b", source.Branch)
+	// git clone -b accepts a tag as well as a branch
+	if ref := sourceRef(source); ref != "" {
+		gitArgs = append(gitArgs, "-b", ref)
+	}
+	if source.Clone.Depth > 0 {
+		gitArgs = append(gitArgs, "--depth", strconv.Itoa(source.Clone.Depth))
+	}
+	sparse := sparseDirs(source)
+	if len(sparse) > 0 {
+		gitArgs = append(gitArgs, "--sparse")
+	}
+	if len(gitArgs) > 0 {
+		args = append(append(args, "--"), gitArgs...)
 	}
 
 	// Create secure command
-	cmd, err := util.SecureCommand("gh", args...)
+	cmd, err := util.SecureCommandContext(ctx, "gh", args...)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to create secure command: %w", err)
 	}
 
 	// Set auth token if provided
-	if source.Auth.TokenEnv != "" {
-		token := os.Getenv(source.Auth.TokenEnv)
-		if token != "" {
-			cmd.Env = append(cmd.Env, fmt.Sprintf("GH_TOKEN=%s", token))
-		}
+	if token := sourceToken(source); token != "" {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("GH_TOKEN=%s", token))
 	}
 
 	if output, err := cmd.CombinedOutput(); err != nil {
-		return "", "", fmt.Errorf("gh clone failed: %s", output)
+		return "", "", fmt.Errorf("gh repo clone failed: %s", strings.TrimSpace(string(output)))
+	}
+
+	if len(sparse) > 0 {
+		sparseCmd, err := util.SecureCommandContext(ctx, "git", append([]string{"sparse-checkout", "set", "--"}, sparse...)...)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to create secure command: %w", err)
+		}
+		sparseCmd.Dir = clonePath
+		if output, err := sparseCmd.CombinedOutput(); err != nil {
+			return "", "", fmt.Errorf("git sparse-checkout failed: %s", strings.TrimSpace(string(output)))
+		}
 	}
 
 	// Get commit hash
-	commit, err := g.getCommitHash(clonePath)
+	commit, err := g.getCommitHash(ctx, clonePath)
 	if err != nil {
 		return "", "", err
 	}
@@ -101,14 +253,14 @@ func (g *GitHubHandler) fetchWithGH(source config.Source, destDir string) (strin
 	return sourcePath, commit, nil
 }
 
-func (g *GitHubHandler) getCommitHash(repoPath string) (string, error) {
+func (g *GitHubHandler) getCommitHash(ctx context.Context, repoPath string) (string, error) {
 	// Validate repository path
 	if err := util.ValidatePath(repoPath); err != nil {
 		return "", fmt.Errorf("invalid repository path: %w", err)
 	}
 
 	// Create secure command
-	cmd, err := util.SecureCommand("git", "rev-parse", "HEAD")
+	cmd, err := util.SecureCommandContext(ctx, "git", "rev-parse", "HEAD")
 	if err != nil {
 		return "", fmt.Errorf("failed to create secure command: %w", err)
 	}
@@ -122,66 +274,274 @@ func (g *GitHubHandler) getCommitHash(repoPath string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
-// CheckUpdate checks if updates are available
-func (g *GitHubHandler) CheckUpdate(source config.Source, currentCommit string) (bool, string, error) {
-	// Create temp directory for checking
-	tempDir, err := os.MkdirTemp("", "agent-update-check-*")
+// CheckUpdate compares the remote branch head or tag against currentCommit
+// using the GitHub API, falling back to listing remote refs when the API is unavailable
+func (g *GitHubHandler) CheckUpdate(ctx context.Context, source config.Source, currentCommit string) (bool, string, error) {
+	latestCommit, err := g.remoteHead(ctx, source)
 	if err != nil {
-		return false, "", err
+		if ctx.Err() != nil {
+			return false, "", fmt.Errorf("update check aborted: %w", ctx.Err())
+		}
+
+		handler := &GitHandler{}
+		return handler.CheckUpdate(ctx, g.gitSource(source), currentCommit)
+	}
+
+	hasUpdate := latestCommit != currentCommit
+	return hasUpdate, latestCommit, nil
+}
+
+// remoteHead returns the commit SHA of the source tag or branch head via
+// GET /repos/{repo}/commits/{ref}
+func (g *GitHubHandler) remoteHead(ctx context.Context, source config.Source) (string, error) {
+	if err := util.ValidateRepository(source.Repository); err != nil {
+		return "", fmt.Errorf("invalid repository: %w", err)
+	}
+	if err := util.ValidateBranch(source.Branch); err != nil {
+		return "", fmt.Errorf("invalid branch: %w", err)
+	}
+	if err := util.ValidateBranch(source.Tag); err != nil {
+		return "", fmt.Errorf("invalid tag: %w", err)
+	}
+
+	ref := sourceRef(source)
+	if ref == "" {
+		ref = "HEAD"
 	}
-	defer func() {
-		if err := os.RemoveAll(tempDir); err != nil {
-			// Log error but don't fail the entire operation
-			fmt.Printf("Warning: failed to remove temp directory %s: %v\n", tempDir, err)
+
+	baseURL := g.apiURL
+	if baseURL == "" {
+		baseURL = githubAPIURL
+	}
+
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet,
+		fmt.Sprintf("%s/repos/%s/commits/%s", baseURL, source.Repository, url.PathEscape(ref)), nil)
+	if err != nil {
+		return "", err
+	}
+	// The sha media type returns only the commit SHA as plain text
+	req.Header.Set("Accept", "application/vnd.github.sha")
+	if token := sourceToken(source); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	applyHTTPOptions(req, source.Auth)
+
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("github API request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", fmt.Errorf("failed to read github API response: %w", err)
+	}
+	if resp.StatusCode != nethttp.StatusOK {
+		return "", fmt.Errorf("github API returned %s", resp.Status)
+	}
+
+	commit := strings.TrimSpace(string(body))
+	if !commitPattern.MatchString(commit) {
+		return "", fmt.Errorf("unexpected github API response")
+	}
+	return commit, nil
+}
+
+// commitPattern matches a full git commit SHA
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// gitlabURL is the GitLab instance used by gitlab sources without gitlab_url
+const gitlabURL = "https://gitlab.com"
+
+// gitlabJobTokenEnv holds the job token of a GitLab CI job
+const gitlabJobTokenEnv = "CI_JOB_TOKEN"
+
+// GitLabHandler handles GitLab repositories, including projects in subgroups
+// and on self-managed instances
+type GitLabHandler struct {
+	apiURL string // overrides the instance's /api/v4 URL when set
+	gitURL string // overrides the instance URL for cloning when set
+}
+
+// Fetch clones a GitLab project at its branch or tag with go-git
+func (g *GitLabHandler) Fetch(ctx context.Context, source config.Source, destDir string) (string, string, error) {
+	if err := validateGitLabSource(source); err != nil {
+		return "", "", err
+	}
+	if err := util.ValidatePath(destDir); err != nil {
+		return "", "", fmt.Errorf("invalid destination directory: %w", err)
+	}
+
+	sourcePath, commit, err := (&GitHandler{}).Fetch(ctx, g.gitSource(source), destDir)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", "", fmt.Errorf("clone of %s aborted: %w", source.Repository, ctx.Err())
 		}
-	}()
+		return "", "", fmt.Errorf("failed to clone %s: %w", source.Repository, err)
+	}
+	return sourcePath, commit, nil
+}
 
-	// Fetch latest
-	_, latestCommit, err := g.Fetch(source, tempDir)
+// CheckUpdate compares the commit of the source branch or tag against
+// currentCommit using the GitLab API, falling back to listing remote refs when
+// the API is unavailable, e.g. to job tokens without API access
+func (g *GitLabHandler) CheckUpdate(ctx context.Context, source config.Source, currentCommit string) (bool, string, error) {
+	latestCommit, err := g.remoteHead(ctx, source)
 	if err != nil {
-		return false, "", err
+		if ctx.Err() != nil {
+			return false, "", fmt.Errorf("update check aborted: %w", ctx.Err())
+		}
+		util.DebugPrintf("GitLab API unavailable for %s, listing remote refs: %v\n", source.Name, err)
+
+		handler := &GitHandler{}
+		return handler.CheckUpdate(ctx, g.gitSource(source), currentCommit)
 	}
 
 	hasUpdate := latestCommit != currentCommit
 	return hasUpdate, latestCommit, nil
 }
 
+// validateGitLabSource checks the project path, branch and tag of a gitlab source
+func validateGitLabSource(source config.Source) error {
+	if err := util.ValidateRepository(source.Repository); err != nil {
+		return fmt.Errorf("invalid repository: %w", err)
+	}
+	if err := util.ValidateBranch(source.Branch); err != nil {
+		return fmt.Errorf("invalid branch: %w", err)
+	}
+	if err := util.ValidateBranch(source.Tag); err != nil {
+		return fmt.Errorf("invalid tag: %w", err)
+	}
+	return nil
+}
+
+// gitlabInstanceURL returns the base URL of the GitLab instance of a source
+func gitlabInstanceURL(source config.Source) string {
+	if source.GitLabURL != "" {
+		return strings.TrimSuffix(source.GitLabURL, "/")
+	}
+	return gitlabURL
+}
+
+// isJobToken reports whether a gitlab source authenticates with the CI job token
+func isJobToken(source config.Source) bool {
+	return source.Type == "gitlab" && source.Auth.Method != "keychain" && source.Auth.TokenEnv == gitlabJobTokenEnv
+}
+
+// gitSource converts a GitLab source into the equivalent generic git source,
+// authenticating with the source token whenever one is available
+func (g *GitLabHandler) gitSource(source config.Source) config.Source {
+	baseURL := g.gitURL
+	if baseURL == "" {
+		baseURL = gitlabInstanceURL(source)
+	}
+
+	gitSource := source
+	gitSource.URL = fmt.Sprintf("%s/%s.git", baseURL, source.Repository)
+	if source.Auth.Method != "keychain" && sourceToken(source) != "" {
+		gitSource.Auth.Method = "token"
+	}
+	return gitSource
+}
+
+// remoteHead returns the commit SHA of the source tag or branch via
+// GET /projects/{path}/repository/commits/{ref}, looking up the default
+// branch of the project when neither is set
+func (g *GitLabHandler) remoteHead(ctx context.Context, source config.Source) (string, error) {
+	if err := validateGitLabSource(source); err != nil {
+		return "", err
+	}
+
+	baseURL := g.apiURL
+	if baseURL == "" {
+		baseURL = gitlabInstanceURL(source) + "/api/v4"
+	}
+	project := fmt.Sprintf("%s/projects/%s", baseURL, url.PathEscape(source.Repository))
+
+	ref := sourceRef(source)
+	if ref == "" {
+		var info struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if err := g.get(ctx, source, project, &info); err != nil {
+			return "", err
+		}
+		if info.DefaultBranch == "" {
+			return "", fmt.Errorf("gitlab project %s has no default branch", source.Repository)
+		}
+		ref = info.DefaultBranch
+	}
+
+	var commit struct {
+		ID string `json:"id"`
+	}
+	if err := g.get(ctx, source, fmt.Sprintf("%s/repository/commits/%s", project, url.PathEscape(ref)), &commit); err != nil {
+		return "", err
+	}
+	if !commitPattern.MatchString(commit.ID) {
+		return "", fmt.Errorf("unexpected gitlab API response")
+	}
+	return commit.ID, nil
+}
+
+// get decodes the JSON response of a GitLab API request into v, sending the
+// job token or personal token of the source
+func (g *GitLabHandler) get(ctx context.Context, source config.Source, endpoint string, v interface{}) error {
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token := sourceToken(source); token != "" {
+		if isJobToken(source) {
+			req.Header.Set("JOB-TOKEN", token)
+		} else {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		}
+	}
+	applyHTTPOptions(req, source.Auth)
+
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("gitlab API request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != nethttp.StatusOK {
+		return fmt.Errorf("gitlab API returned %s", resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode gitlab API response: %w", err)
+	}
+	return nil
+}
+
 // GitHandler handles generic git repositories
 type GitHandler struct{}
 
 // Fetch clones a git repository
-func (g *GitHandler) Fetch(source config.Source, destDir string) (string, string, error) {
+func (g *GitHandler) Fetch(ctx context.Context, source config.Source, destDir string) (string, string, error) {
 	clonePath := filepath.Join(destDir, "repo")
 
 	// Clone options
 	cloneOpts := &git.CloneOptions{
 		URL:      source.URL,
 		Progress: nil, // Could set to os.Stdout for git progress, but we're using our own progress
+		Depth:    source.Clone.Depth,
 	}
 
-	// Set branch
-	if source.Branch != "" {
-		cloneOpts.ReferenceName = plumbing.NewBranchReferenceName(source.Branch)
-	}
+	// A sparse checkout replaces the full checkout made by the clone
+	sparse := sparseDirs(source)
+	cloneOpts.NoCheckout = len(sparse) > 0
+
+	// Set branch or tag
+	cloneOpts.ReferenceName = sourceReference(source)
 
 	// Handle authentication securely
-	if source.Auth.Method == "token" {
-		token := os.Getenv(source.Auth.TokenEnv)
-		if token != "" {
-			// For HTTPS URLs with token auth, use proper auth methods instead of embedding in URL
-			if strings.HasPrefix(source.URL, "https://") {
-				// Use go-git's auth mechanisms instead of embedding token in URL
-				// This prevents token exposure in logs and error messages
-				cloneOpts.Auth = &http.BasicAuth{
-					Username: "token", // GitHub uses "token" as username for token auth
-					Password: token,
-				}
-			}
-		}
-	}
+	cloneOpts.Auth = gitAuth(source)
 
 	// Clone repository
-	repo, err := git.PlainClone(clonePath, false, cloneOpts)
+	repo, err := git.PlainCloneContext(ctx, clonePath, false, cloneOpts)
 	if err != nil {
 		return "", "", fmt.Errorf("git clone failed: %w", err)
 	}
@@ -192,6 +552,16 @@ func (g *GitHandler) Fetch(source config.Source, destDir string) (string, string
 		return "", "", fmt.Errorf("failed to get HEAD: %w", err)
 	}
 
+	if len(sparse) > 0 {
+		worktree, err := repo.Worktree()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to open worktree: %w", err)
+		}
+		if err := worktree.ResetSparsely(&git.ResetOptions{Commit: ref.Hash(), Mode: git.HardReset}, sparse); err != nil {
+			return "", "", fmt.Errorf("sparse checkout failed: %w", err)
+		}
+	}
+
 	commit := ref.Hash().String()
 
 	// Return the source path within the clone
@@ -199,22 +569,20 @@ func (g *GitHandler) Fetch(source config.Source, destDir string) (string, string
 	return sourcePath, commit, nil
 }
 
-// CheckUpdate checks if updates are available
-func (g *GitHandler) CheckUpdate(source config.Source, currentCommit string) (bool, string, error) {
-	// Create temp directory
-	tempDir, err := os.MkdirTemp("", "agent-update-check-*")
+// CheckUpdate compares the remote branch head against currentCommit by listing
+// remote refs (like git ls-remote) without downloading any objects
+func (g *GitHandler) CheckUpdate(ctx context.Context, source config.Source, currentCommit string) (bool, string, error) {
+	remote := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{source.URL},
+	})
+
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: gitAuth(source), PeelingOption: git.AppendPeeled})
 	if err != nil {
-		return false, "", err
+		return false, "", fmt.Errorf("failed to list remote refs: %w", err)
 	}
-	defer func() {
-		if err := os.RemoveAll(tempDir); err != nil {
-			// Log error but don't fail the entire operation
-			fmt.Printf("Warning: failed to remove temp directory %s: %v\n", tempDir, err)
-		}
-	}()
 
-	// Fetch latest
-	_, latestCommit, err := g.Fetch(source, tempDir)
+	latestCommit, err := resolveRemoteHead(refs, sourceReference(source))
 	if err != nil {
 		return false, "", err
 	}
@@ -223,11 +591,159 @@ func (g *GitHandler) CheckUpdate(source config.Source, currentCommit string) (bo
 	return hasUpdate, latestCommit, nil
 }
 
+// sparseDirs returns the directories checked out by a sparse clone of a
+// source, or nil for a full checkout, including when paths.source is the
+// repository root
+func sparseDirs(source config.Source) []string {
+	if !source.Clone.Sparse {
+		return nil
+	}
+	dir := strings.Trim(filepath.ToSlash(filepath.Clean(source.Paths.Source)), "/")
+	if dir == "." || dir == "" {
+		return nil
+	}
+	return []string{dir}
+}
+
+// sourceRef returns the tag or branch selected by a source, or an empty
+// string for the default branch
+func sourceRef(source config.Source) string {
+	if source.Tag != "" {
+		return source.Tag
+	}
+	return source.Branch
+}
+
+// sourceReference returns the tag or branch reference selected by a source,
+// or an empty name for the remote HEAD
+func sourceReference(source config.Source) plumbing.ReferenceName {
+	if source.Tag != "" {
+		return plumbing.NewTagReferenceName(source.Tag)
+	}
+	if source.Branch != "" {
+		return plumbing.NewBranchReferenceName(source.Branch)
+	}
+	return ""
+}
+
+// resolveRemoteHead finds the commit for reference, or for the remote HEAD
+// when reference is empty
+func resolveRemoteHead(refs []*plumbing.Reference, reference plumbing.ReferenceName) (string, error) {
+	target := plumbing.HEAD
+	if reference != "" {
+		target = reference
+	}
+
+	byName := make(map[plumbing.ReferenceName]*plumbing.Reference, len(refs))
+	for _, ref := range refs {
+		byName[ref.Name()] = ref
+	}
+
+	// An annotated tag points at a tag object; its peeled name holds the commit
+	if peeled, ok := byName[target+"^{}"]; ok && target.IsTag() {
+		return peeled.Hash().String(), nil
+	}
+
+	// Follow symbolic references such as HEAD -> refs/heads/main
+	for depth := 0; depth < 5; depth++ {
+		ref, ok := byName[target]
+		if !ok {
+			return "", fmt.Errorf("remote reference not found: %s", target)
+		}
+		if ref.Type() == plumbing.HashReference {
+			return ref.Hash().String(), nil
+		}
+		target = ref.Target()
+	}
+	return "", fmt.Errorf("too many symbolic references resolving %s", reference)
+}
+
+// gitAuth returns authentication for HTTPS sources carrying the token and any
+// custom headers, or nil when neither is configured
+func gitAuth(source config.Source) transport.AuthMethod {
+	var auth *http.BasicAuth
+	if token := authToken(source); token != "" {
+		// Use go-git's auth mechanisms instead of embedding the token in the URL;
+		// this prevents token exposure in logs and error messages
+		auth = &http.BasicAuth{
+			Username: gitUsername(source),
+			Password: token,
+		}
+	}
+	if !strings.HasPrefix(source.URL, "https://") {
+		return nil
+	}
+	if source.Auth.HasHTTPOptions() {
+		util.DebugPrintf("Using custom HTTP headers for %s: %s\n", source.Name, source.Auth.RedactedHeaders())
+		return &headerAuth{basic: auth, options: source.Auth}
+	}
+	if auth == nil {
+		return nil
+	}
+	return auth
+}
+
+// gitUsername returns the basic auth username sent with the token of a source:
+// GitHub accepts "token", while GitLab expects gitlab-ci-token for job tokens
+// and oauth2 for personal and OAuth tokens
+func gitUsername(source config.Source) string {
+	if source.Type != "gitlab" {
+		return "token"
+	}
+	if isJobToken(source) {
+		return "gitlab-ci-token"
+	}
+	return "oauth2"
+}
+
+// authToken returns the token of a source whose auth method uses one
+func authToken(source config.Source) string {
+	if source.Auth.Method != "token" && source.Auth.Method != "keychain" {
+		return ""
+	}
+	return sourceToken(source)
+}
+
+// headerAuth adds the source's custom headers and user agent to go-git HTTP
+// requests on top of optional token authentication
+type headerAuth struct {
+	basic   *http.BasicAuth
+	options config.AuthConfig
+}
+
+// Name returns the auth method name
+func (h *headerAuth) Name() string {
+	return "http-headers"
+}
+
+// String describes the auth method with header values masked
+func (h *headerAuth) String() string {
+	return fmt.Sprintf("%s - %s", h.Name(), h.options.RedactedHeaders())
+}
+
+// SetAuth applies the token, headers and user agent to the request
+func (h *headerAuth) SetAuth(r *nethttp.Request) {
+	if h.basic != nil {
+		h.basic.SetAuth(r)
+	}
+	applyHTTPOptions(r, h.options)
+}
+
+// applyHTTPOptions sets the configured custom headers and user agent on req
+func applyHTTPOptions(req *nethttp.Request, auth config.AuthConfig) {
+	for name, value := range auth.Headers {
+		req.Header.Set(name, value)
+	}
+	if auth.UserAgent != "" {
+		req.Header.Set("User-Agent", auth.UserAgent)
+	}
+}
+
 // LocalHandler handles local file system sources
 type LocalHandler struct{}
 
 // Fetch copies from local file system
-func (l *LocalHandler) Fetch(source config.Source, destDir string) (string, string, error) {
+func (l *LocalHandler) Fetch(ctx context.Context, source config.Source, destDir string) (string, string, error) {
 	sourcePath, err := expandPath(source.Paths.Source)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to expand source path: %w", err)
@@ -252,7 +768,7 @@ func (l *LocalHandler) Fetch(source config.Source, destDir string) (string, stri
 }
 
 // CheckUpdate checks if local source has been modified
-func (l *LocalHandler) CheckUpdate(source config.Source, currentCommit string) (bool, string, error) {
+func (l *LocalHandler) CheckUpdate(ctx context.Context, source config.Source, currentCommit string) (bool, string, error) {
 	sourcePath, err := expandPath(source.Paths.Source)
 	if err != nil {
 		return false, "", fmt.Errorf("failed to expand source path: %w", err)
@@ -269,11 +785,16 @@ func (l *LocalHandler) CheckUpdate(source config.Source, currentCommit string) (
 	return hasUpdate, newCommit, nil
 }
 
-// applyFilters filters files based on configuration
+// applyFilters filters files based on configuration. Directories nested
+// deeper than settings.walk.max_depth are skipped with a warning.
 func (i *Installer) applyFilters(basePath string, filters config.FilterConfig) ([]string, error) {
 	var result []string
 
-	err := filepath.Walk(basePath, func(path string, info os.FileInfo, err error) error {
+	err := util.Walk(basePath, i.config.Settings.Walk.Options(), func(path string, info os.FileInfo, err error) error {
+		if errors.Is(err, util.ErrMaxDepth) {
+			color.Yellow("Warning: skipping %s: %v\n", path, err)
+			return nil
+		}
 		if err != nil {
 			return err
 		}
@@ -290,7 +811,7 @@ func (i *Installer) applyFilters(basePath string, filters config.FilterConfig) (
 		}
 
 		// Check if file should be included
-		if shouldInclude(relPath, info.Name(), filters) {
+		if shouldInclude(relPath, info.Name(), filters) && matchesAgentNames(path, relPath, filters.Agents) {
 			result = append(result, relPath)
 		}
 
@@ -318,6 +839,34 @@ func shouldInclude(relPath, fileName string, filters config.FilterConfig) bool {
 	return matchesIncludeCriteria(relPath, fileName, filters)
 }
 
+// matchesAgentNames reports whether the file at path is one of the named
+// agents, by file name, path relative to the source without extension, or
+// frontmatter name; every file matches when no names are given
+func matchesAgentNames(path, relPath string, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	slashPath := filepath.ToSlash(relPath)
+	withoutExt := strings.TrimSuffix(slashPath, filepath.Ext(slashPath))
+	base := filepath.Base(relPath)
+	for _, name := range names {
+		if name == base || name == slashPath || name == withoutExt || name == filepath.Base(withoutExt) {
+			return true
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	fields, err := parser.FrontmatterFields(string(content))
+	if err != nil {
+		return false
+	}
+	agentName, _ := fields["name"].(string)
+	return agentName != "" && contains(names, strings.TrimSpace(agentName))
+}
+
 func isExcluded(relPath, fileName string, excludePatterns []string) bool {
 	for _, pattern := range excludePatterns {
 		if matched, err := filepath.Match(pattern, fileName); err == nil && matched {
@@ -360,9 +909,9 @@ func matchesIncludeExtensions(fileName string, extensions []string) bool {
 		return false
 	}
 
-	ext := filepath.Ext(fileName)
+	// Match by suffix so multi-part extensions such as .agent.md work
 	for _, allowedExt := range extensions {
-		if ext == allowedExt {
+		if allowedExt != "" && strings.HasSuffix(fileName, allowedExt) {
 			return true
 		}
 	}
@@ -405,8 +954,9 @@ func expandPath(path string) (string, error) {
 
 // SubagentsHandler handles subagents.sh marketplace
 type SubagentsHandler struct {
-	container *marketplace.Container
-	config    *config.Config
+	container  *marketplace.Container
+	config     *config.Config
+	categories map[string]FetchedCategory
 }
 
 func NewSubagentsHandler(cfg *config.Config) (*SubagentsHandler, error) {
@@ -418,6 +968,7 @@ func NewSubagentsHandler(cfg *config.Config) (*SubagentsHandler, error) {
 		BrowserHeadless: true,
 		BrowserTimeout:  30,
 		UserAgent:       "agent-manager/1.0",
+		Mode:            cfg.Settings.Marketplace.Mode,
 	}
 
 	container, err := marketplace.NewContainer(containerConfig)
@@ -432,12 +983,13 @@ func NewSubagentsHandler(cfg *config.Config) (*SubagentsHandler, error) {
 }
 
 // Fetch implements SourceHandler interface
-func (s *SubagentsHandler) Fetch(source config.Source, destDir string) (string, string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+func (s *SubagentsHandler) Fetch(ctx context.Context, source config.Source, destDir string) (string, string, error) {
+	// Listing is bounded as a whole; content downloads are bounded per agent
+	listCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
 	defer cancel()
 
 	// Override container config if source has custom settings
-	if source.Cache.Enabled || source.Cache.TTLHours > 0 || source.Cache.MaxSizeMB > 0 || source.MarketplaceURL != "" {
+	if source.Cache.Enabled || source.Cache.TTLHours > 0 || source.Cache.MaxSizeMB > 0 || source.MarketplaceURL != "" || source.Auth.HasHTTPOptions() {
 		containerConfig := marketplace.ContainerConfig{
 			BaseURL:         source.MarketplaceURL,
 			CacheEnabled:    source.Cache.Enabled,
@@ -446,8 +998,16 @@ func (s *SubagentsHandler) Fetch(source config.Source, destDir string) (string,
 			BrowserHeadless: true,
 			BrowserTimeout:  30,
 			UserAgent:       "agent-manager/1.0",
+			Headers:         source.Auth.Headers,
+			Mode:            s.config.Settings.Marketplace.Mode,
 		}
 
+		if source.Auth.UserAgent != "" {
+			containerConfig.UserAgent = source.Auth.UserAgent
+		}
+		if len(source.Auth.Headers) > 0 {
+			util.DebugPrintf("Using custom HTTP headers for %s: %s\n", source.Name, source.Auth.RedactedHeaders())
+		}
 		if containerConfig.BaseURL == "" {
 			containerConfig.BaseURL = "https://subagents.sh"
 		}
@@ -471,28 +1031,37 @@ func (s *SubagentsHandler) Fetch(source config.Source, destDir string) (string,
 	}
 
 	// Get marketplace data
-	categories, err := s.container.Service.GetCategories(ctx)
+	categories, err := s.container.Service.GetCategories(listCtx)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to fetch marketplace categories: %w", err)
 	}
 
 	// Get agents by category
 	var agents []marketplace.Agent
+	agentsByCategory := make(map[string][]marketplace.Agent)
+	categoryOf := make(map[string]string)
 	if category := source.Category; category != "" {
 		// Get agents for specific category
-		categoryAgents, err := s.container.Service.GetAgents(ctx, category)
+		categoryAgents, err := s.container.Service.GetAgents(listCtx, category)
 		if err != nil {
 			return "", "", fmt.Errorf("failed to fetch agents for category %s: %w", category, err)
 		}
 		agents = categoryAgents
+		for _, agent := range categoryAgents {
+			categoryOf[agent.ID] = category
+		}
 	} else {
 		// Get agents from all categories
 		for _, cat := range categories {
-			categoryAgents, err := s.container.Service.GetAgents(ctx, cat.Slug)
+			categoryAgents, err := s.container.Service.GetAgents(listCtx, cat.Slug)
 			if err != nil {
 				continue // Skip categories that fail
 			}
 			agents = append(agents, categoryAgents...)
+			agentsByCategory[cat.Slug] = categoryAgents
+			for _, agent := range categoryAgents {
+				categoryOf[agent.ID] = cat.Slug
+			}
 		}
 	}
 
@@ -500,6 +1069,21 @@ func (s *SubagentsHandler) Fetch(source config.Source, destDir string) (string,
 		return "", "", fmt.Errorf("no agents found for the specified criteria")
 	}
 
+	// Select agents by their listed metadata before downloading any content
+	if source.Filters.HasMetadataFilters() {
+		listed := len(agents)
+		agents = filterMarketplaceAgents(agents, source.Filters)
+		for slug, categoryAgents := range agentsByCategory {
+			agentsByCategory[slug] = filterMarketplaceAgents(categoryAgents, source.Filters)
+		}
+		if len(agents) == 0 {
+			return "", "", fmt.Errorf("none of the %d marketplace agents match the filters of %s", listed, source.Name)
+		}
+		if skipped := listed - len(agents); skipped > 0 {
+			fmt.Printf("Skipped %d of %d marketplace agents that do not match the filters of %s\n", skipped, listed, source.Name)
+		}
+	}
+
 	// Create temporary directory structure
 	sourcePath := filepath.Join(destDir, "agents")
 	if err := os.MkdirAll(sourcePath, 0755); err != nil {
@@ -514,29 +1098,82 @@ func (s *SubagentsHandler) Fetch(source config.Source, destDir string) (string,
 		defer pm.FinishProgress(progressID, true, "")
 	}
 
-	for _, agent := range agents {
-		content, err := s.container.Service.GetAgentContent(ctx, agent.ID)
-		if err != nil {
-			fmt.Printf("Warning: failed to get content for %s: %v\n", agent.Name, err)
+	timeout := source.AgentTimeout
+	if timeout <= 0 {
+		timeout = config.DefaultAgentTimeout
+	}
+	downloader := &agentDownloader{
+		timeout: timeout,
+		get:     s.container.Service.GetAgentContent,
+		done: func() {
 			if len(agents) > 1 {
 				pm.UpdateProgress(progressID, 1)
 			}
+		},
+	}
+	if s.config != nil && s.config.Metadata.TrackingFile != "" {
+		downloader.resumeDir = filepath.Join(ResumeRoot(s.config.Metadata.TrackingFile), source.Name)
+	}
+	contents, failures, err := downloader.download(ctx, agents)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to download %s: %w", source.Name, err)
+	}
+	if len(failures) == len(agents) {
+		return "", "", fmt.Errorf("failed to download all %d agents: %s: %s", len(agents), failures[0].Name, failures[0].Reason)
+	}
+	if len(failures) > 0 {
+		fmt.Printf("Warning: failed to download %d of %d agents (re-run to retry them):\n", len(failures), len(agents))
+		for _, failure := range failures {
+			fmt.Printf("  - %s: %s\n", failure.Name, failure.Reason)
+		}
+	}
+
+	written := make(map[string]bool, len(agents))
+	for _, agent := range agents {
+		content, ok := contents[agent.ID]
+		if !ok {
 			continue
 		}
 
 		// Write agent file
-		filename := fmt.Sprintf("%s.md", agent.Slug)
+		filename := agentFileName(agent.Slug, categoryOf[agent.ID], source.PreserveStructure)
 		agentPath := filepath.Join(sourcePath, filename)
+		if err := os.MkdirAll(filepath.Dir(agentPath), 0755); err != nil {
+			return "", "", fmt.Errorf("failed to create category directory: %w", err)
+		}
 
 		// Format content with proper frontmatter
 		formattedContent := s.formatAgentContent(agent, content)
 
+		// Scraped content is unbounded; keep skipped or failing agents off disk.
+		// Oversized agents in warn mode are reported when installed.
+		if s.config != nil && s.config.Settings.Limits.OnExceed != "warn" {
+			install, err := enforceFileLimit(s.config.Settings.Limits, filename, int64(len(formattedContent)))
+			if err != nil {
+				return "", "", err
+			}
+			if !install {
+				continue
+			}
+		}
+
 		if err := os.WriteFile(agentPath, []byte(formattedContent), 0644); err != nil {
 			return "", "", fmt.Errorf("failed to write agent %s: %w", agent.Name, err)
 		}
+		written[agent.ID] = true
+	}
 
-		if len(agents) > 1 {
-			pm.UpdateProgress(progressID, 1)
+	// Record per-category files so each category can be tracked separately
+	s.categories = make(map[string]FetchedCategory, len(agentsByCategory))
+	for slug, categoryAgents := range agentsByCategory {
+		fetched := FetchedCategory{Version: s.generateVersionHash(categoryAgents)}
+		for _, agent := range categoryAgents {
+			if written[agent.ID] {
+				fetched.Files = append(fetched.Files, agentFileName(agent.Slug, slug, source.PreserveStructure))
+			}
+		}
+		if len(fetched.Files) > 0 {
+			s.categories[slug] = fetched
 		}
 	}
 
@@ -546,11 +1183,54 @@ func (s *SubagentsHandler) Fetch(source config.Source, destDir string) (string,
 	return sourcePath, versionHash, nil
 }
 
+// filterMarketplaceAgents returns the agents whose listed rating, downloads
+// and tags pass the source's metadata filters
+func filterMarketplaceAgents(agents []marketplace.Agent, filters config.FilterConfig) []marketplace.Agent {
+	if !filters.HasMetadataFilters() {
+		return agents
+	}
+	var selected []marketplace.Agent
+	for _, agent := range agents {
+		reason := filters.MetadataMismatch(float64(agent.Rating), agent.Downloads, agent.Tags)
+		if reason != "" {
+			util.DebugPrintf("Skipping marketplace agent %s: %s\n", agent.Name, reason)
+			continue
+		}
+		selected = append(selected, agent)
+	}
+	return selected
+}
+
+// agentFileName returns the fetched file name of a marketplace agent, nested
+// under its category directory when the source preserves structure
+func agentFileName(slug, category string, preserveStructure bool) string {
+	if preserveStructure && category != "" {
+		return filepath.Join(category, slug+".md")
+	}
+	return slug + ".md"
+}
+
+// FetchedCategories implements CategorizedHandler; categories are only reported
+// when the source is not restricted to a single category
+func (s *SubagentsHandler) FetchedCategories() map[string]FetchedCategory {
+	return s.categories
+}
+
 // CheckUpdate implements SourceHandler interface
-func (s *SubagentsHandler) CheckUpdate(source config.Source, currentCommit string) (bool, string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+func (s *SubagentsHandler) CheckUpdate(ctx context.Context, source config.Source, currentCommit string) (bool, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
+	// Restrict the check to the configured category, if any
+	if category := source.Category; category != "" {
+		agents, err := s.container.Service.GetAgents(ctx, category)
+		if err != nil {
+			return false, "", fmt.Errorf("failed to check updates for category %s: %w", category, err)
+		}
+		newHash := s.generateVersionHash(filterMarketplaceAgents(agents, source.Filters))
+		return newHash != currentCommit, newHash, nil
+	}
+
 	categories, err := s.container.Service.GetCategories(ctx)
 	if err != nil {
 		return false, "", fmt.Errorf("failed to check marketplace updates: %w", err)
@@ -566,7 +1246,7 @@ func (s *SubagentsHandler) CheckUpdate(source config.Source, currentCommit strin
 		agents = append(agents, categoryAgents...)
 	}
 
-	newHash := s.generateVersionHash(agents)
+	newHash := s.generateVersionHash(filterMarketplaceAgents(agents, source.Filters))
 	hasUpdate := newHash != currentCommit
 
 	return hasUpdate, newHash, nil
@@ -574,6 +1254,12 @@ func (s *SubagentsHandler) CheckUpdate(source config.Source, currentCommit strin
 
 // Helper methods
 func (s *SubagentsHandler) formatAgentContent(agent marketplace.Agent, content string) string {
+	return FormatMarketplaceAgent(agent, content)
+}
+
+// FormatMarketplaceAgent returns the agent file written for a marketplace
+// agent: its content behind frontmatter recording where it came from
+func FormatMarketplaceAgent(agent marketplace.Agent, content string) string {
 	frontmatter := fmt.Sprintf(`---
 name: %s
 description: %s
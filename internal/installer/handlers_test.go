@@ -1,14 +1,23 @@
 package installer
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/credentials"
 	"github.com/pacphi/claude-code-agent-manager/internal/marketplace"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
 )
 
 func TestSubagentsHandler_FormatAgentContent(t *testing.T) {
@@ -121,6 +130,532 @@ func TestSubagentsHandler_GenerateVersionHash(t *testing.T) {
 	}
 }
 
+func TestGitHandler_FetchCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	handler := &GitHandler{}
+	source := config.Source{Name: "test", Type: "git", URL: "https://example.invalid/repo.git"}
+
+	if _, _, err := handler.Fetch(ctx, source, t.TempDir()); err == nil {
+		t.Error("Expected fetch with canceled context to fail")
+	}
+}
+
+func TestGitHubHandler_CheckUpdate(t *testing.T) {
+	const head = "0123456789abcdef0123456789abcdef01234567"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/commits/main" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Accept") != "application/vnd.github.sha" {
+			t.Errorf("Unexpected Accept header: %s", r.Header.Get("Accept"))
+		}
+		fmt.Fprint(w, head)
+	}))
+	defer server.Close()
+
+	handler := &GitHubHandler{apiURL: server.URL}
+	source := config.Source{Name: "test", Type: "github", Repository: "owner/repo", Branch: "main"}
+
+	hasUpdate, commit, err := handler.CheckUpdate(context.Background(), source, head)
+	if err != nil {
+		t.Fatalf("CheckUpdate failed: %v", err)
+	}
+	if hasUpdate || commit != head {
+		t.Errorf("Expected no update at %s, got hasUpdate=%v commit=%s", head, hasUpdate, commit)
+	}
+
+	hasUpdate, _, err = handler.CheckUpdate(context.Background(), source, "old")
+	if err != nil {
+		t.Fatalf("CheckUpdate failed: %v", err)
+	}
+	if !hasUpdate {
+		t.Error("Expected update when stored commit differs")
+	}
+}
+
+func TestGitHubHandler_CheckUpdateSendsHeaders(t *testing.T) {
+	const head = "0123456789abcdef0123456789abcdef01234567"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" || r.Header.Get("User-Agent") != "agent-manager-ci/1.0" {
+			http.Error(w, "missing headers", http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, head)
+	}))
+	defer server.Close()
+
+	handler := &GitHubHandler{apiURL: server.URL}
+	source := config.Source{
+		Name:       "test",
+		Type:       "github",
+		Repository: "owner/repo",
+		Auth: config.AuthConfig{
+			Headers:   map[string]string{"X-Api-Key": "secret"},
+			UserAgent: "agent-manager-ci/1.0",
+		},
+	}
+
+	commit, err := handler.remoteHead(context.Background(), source)
+	if err != nil || commit != head {
+		t.Errorf("remoteHead() = %s, %v; want %s", commit, err, head)
+	}
+}
+
+func TestGitAuthHeaders(t *testing.T) {
+	t.Setenv("TEST_GIT_TOKEN", "token-value")
+	source := config.Source{
+		Name: "test",
+		URL:  "https://git.example.com/repo.git",
+		Auth: config.AuthConfig{
+			Method:    "token",
+			TokenEnv:  "TEST_GIT_TOKEN",
+			Headers:   map[string]string{"X-Api-Key": "secret"},
+			UserAgent: "agent-manager-ci/1.0",
+		},
+	}
+
+	auth := gitAuth(source)
+	if auth == nil {
+		t.Fatal("Expected auth method for source with headers")
+	}
+	if strings.Contains(auth.String(), "secret") || strings.Contains(auth.String(), "token-value") {
+		t.Errorf("Expected masked header values, got %s", auth.String())
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, source.URL, nil)
+	auth.(*headerAuth).SetAuth(req)
+	if user, pass, ok := req.BasicAuth(); !ok || user != "token" || pass != "token-value" {
+		t.Errorf("Expected token basic auth, got %s/%s", user, pass)
+	}
+	if req.Header.Get("X-Api-Key") != "secret" || req.UserAgent() != "agent-manager-ci/1.0" {
+		t.Errorf("Expected custom headers, got %v", req.Header)
+	}
+
+	source.URL = "git@git.example.com:repo.git"
+	if gitAuth(source) != nil {
+		t.Error("Expected no HTTP auth for non-HTTPS source")
+	}
+}
+
+func TestGitAuthKeychain(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, ".gitconfig"), []byte("[credential]\n\thelper = store\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	source := config.Source{
+		Name:       "private",
+		Type:       "github",
+		Repository: "org/agents",
+		Auth:       config.AuthConfig{Method: "keychain", Helper: "git"},
+	}
+	store, err := credentials.New(source.Auth.Helper, SourceURL(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set(context.Background(), source.Name, "keychain-token"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	gitSource := (&GitHubHandler{}).gitSource(source)
+	if gitSource.Auth.Method != "keychain" {
+		t.Errorf("Expected keychain auth to be kept, got %s", gitSource.Auth.Method)
+	}
+	auth, ok := gitAuth(gitSource).(interface{ SetAuth(*http.Request) })
+	if !ok {
+		t.Fatalf("Expected HTTP auth for keychain source, got %v", gitAuth(gitSource))
+	}
+	req, _ := http.NewRequest(http.MethodGet, gitSource.URL, nil)
+	auth.SetAuth(req)
+	if _, pass, ok := req.BasicAuth(); !ok || pass != "keychain-token" {
+		t.Errorf("Expected the keychain token, got %q", pass)
+	}
+}
+
+func TestGitHandler_CheckUpdate(t *testing.T) {
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repo: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "agent.md"), []byte("agent"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Add("agent.md"); err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+	hash, err := worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	handler := &GitHandler{}
+	source := config.Source{Name: "test", Type: "git", URL: repoDir}
+
+	hasUpdate, commit, err := handler.CheckUpdate(context.Background(), source, hash.String())
+	if err != nil {
+		t.Fatalf("CheckUpdate failed: %v", err)
+	}
+	if hasUpdate || commit != hash.String() {
+		t.Errorf("Expected no update at %s, got hasUpdate=%v commit=%s", hash, hasUpdate, commit)
+	}
+
+	source.Branch = "missing"
+	if _, _, err := handler.CheckUpdate(context.Background(), source, hash.String()); err == nil {
+		t.Error("Expected error for missing branch")
+	}
+}
+
+func TestGitLabHandler_CheckUpdate(t *testing.T) {
+	const head = "0123456789abcdef0123456789abcdef01234567"
+	t.Setenv("TEST_GITLAB_TOKEN", "personal-token")
+	t.Setenv("CI_JOB_TOKEN", "job-token")
+
+	var tokenHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenHeader = r.Header.Get("PRIVATE-TOKEN") + r.Header.Get("JOB-TOKEN")
+		switch r.URL.EscapedPath() {
+		case "/projects/group%2Fsubgroup%2Fagents":
+			fmt.Fprint(w, `{"id": 42, "default_branch": "develop"}`)
+		case "/projects/group%2Fsubgroup%2Fagents/repository/commits/develop",
+			"/projects/group%2Fsubgroup%2Fagents/repository/commits/v1.2.0":
+			fmt.Fprintf(w, `{"id": %q, "short_id": "0123456"}`, head)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	handler := &GitLabHandler{apiURL: server.URL}
+	source := config.Source{
+		Name:       "test",
+		Type:       "gitlab",
+		Repository: "group/subgroup/agents",
+		Auth:       config.AuthConfig{Method: "token", TokenEnv: "TEST_GITLAB_TOKEN"},
+	}
+
+	hasUpdate, commit, err := handler.CheckUpdate(context.Background(), source, "old")
+	if err != nil {
+		t.Fatalf("CheckUpdate failed: %v", err)
+	}
+	if !hasUpdate || commit != head {
+		t.Errorf("Expected update to the default branch head %s, got hasUpdate=%v commit=%s", head, hasUpdate, commit)
+	}
+	if tokenHeader != "personal-token" {
+		t.Errorf("Expected the personal token, got %q", tokenHeader)
+	}
+
+	source.Tag = "v1.2.0"
+	source.Auth.TokenEnv = "CI_JOB_TOKEN"
+	if commit, err := handler.remoteHead(context.Background(), source); err != nil || commit != head {
+		t.Errorf("remoteHead() = %s, %v; want %s", commit, err, head)
+	}
+	if tokenHeader != "job-token" {
+		t.Errorf("Expected the job token, got %q", tokenHeader)
+	}
+
+	source.Tag = "missing"
+	if _, err := handler.remoteHead(context.Background(), source); err == nil {
+		t.Error("Expected error for missing tag")
+	}
+}
+
+func TestGitUsername(t *testing.T) {
+	tests := []struct {
+		source config.Source
+		want   string
+	}{
+		{source: config.Source{Type: "github"}, want: "token"},
+		{source: config.Source{Type: "gitlab", Auth: config.AuthConfig{TokenEnv: "GITLAB_TOKEN"}}, want: "oauth2"},
+		{source: config.Source{Type: "gitlab", Auth: config.AuthConfig{TokenEnv: "CI_JOB_TOKEN"}}, want: "gitlab-ci-token"},
+	}
+
+	for _, tt := range tests {
+		if got := gitUsername(tt.source); got != tt.want {
+			t.Errorf("gitUsername(%s, %s) = %q; want %q", tt.source.Type, tt.source.Auth.TokenEnv, got, tt.want)
+		}
+	}
+}
+
+func TestGitLabHandler_FetchTag(t *testing.T) {
+	// Serve group/subgroup/agents from a local repository
+	baseDir := t.TempDir()
+	repoDir := filepath.Join(baseDir, "group", "subgroup", "agents.git")
+	if err := os.MkdirAll(filepath.Join(repoDir, "agents"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repo: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	commitAgent := func(content string) plumbing.Hash {
+		if err := os.WriteFile(filepath.Join(repoDir, "agents", "agent.md"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add("agents/agent.md"); err != nil {
+			t.Fatalf("Failed to add file: %v", err)
+		}
+		hash, err := worktree.Commit(content, &git.CommitOptions{Author: signature})
+		if err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+		return hash
+	}
+
+	tagged := commitAgent("v1")
+	if _, err := repo.CreateTag("v1.0.0", tagged, &git.CreateTagOptions{Tagger: signature, Message: "v1.0.0"}); err != nil {
+		t.Fatalf("Failed to tag: %v", err)
+	}
+	commitAgent("v2")
+
+	handler := &GitLabHandler{gitURL: baseDir, apiURL: "http://127.0.0.1:0"}
+	source := config.Source{
+		Name:       "test",
+		Type:       "gitlab",
+		Repository: "group/subgroup/agents",
+		Tag:        "v1.0.0",
+		Paths:      config.PathConfig{Source: "agents"},
+	}
+
+	sourcePath, commit, err := handler.Fetch(context.Background(), source, t.TempDir())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if commit != tagged.String() {
+		t.Errorf("Expected the tagged commit %s, got %s", tagged, commit)
+	}
+	if content, err := os.ReadFile(filepath.Join(sourcePath, "agent.md")); err != nil || string(content) != "v1" {
+		t.Errorf("Expected the tagged agent, got %q, %v", content, err)
+	}
+
+	// Without API access the update check lists the remote refs
+	hasUpdate, commit, err := handler.CheckUpdate(context.Background(), source, tagged.String())
+	if err != nil {
+		t.Fatalf("CheckUpdate failed: %v", err)
+	}
+	if hasUpdate || commit != tagged.String() {
+		t.Errorf("Expected no update at the tag, got hasUpdate=%v commit=%s", hasUpdate, commit)
+	}
+}
+
+func TestGitHubBackend(t *testing.T) {
+	tests := []struct {
+		prefer      string
+		ghAvailable bool
+		want        string
+		wantErr     bool
+	}{
+		{prefer: "", ghAvailable: true, want: backendGH},
+		{prefer: "", ghAvailable: false, want: backendGoGit},
+		{prefer: "gh", ghAvailable: true, want: backendGH},
+		{prefer: "gh", ghAvailable: false, wantErr: true},
+		{prefer: "go-git", ghAvailable: true, want: backendGoGit},
+		{prefer: "svn", ghAvailable: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := githubBackend(tt.prefer, tt.ghAvailable)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("githubBackend(%q, %v): expected error", tt.prefer, tt.ghAvailable)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("githubBackend(%q, %v) = %q, %v; want %q", tt.prefer, tt.ghAvailable, got, err, tt.want)
+		}
+	}
+}
+
+func TestGitHubHandler_FetchBackendParity(t *testing.T) {
+	if !commandExists("git") {
+		t.Skip("git not installed")
+	}
+
+	// Serve owner/agents from a local repository for both backends
+	baseDir := t.TempDir()
+	repoDir := filepath.Join(baseDir, "owner", "agents.git")
+	if err := os.MkdirAll(filepath.Join(repoDir, "agents"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repo: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "agents", "agent.md"), []byte("agent"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Add("agents/agent.md"); err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+	hash, err := worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Failed to get HEAD: %v", err)
+	}
+
+	fakeGH(t, baseDir)
+
+	handler := &GitHubHandler{gitURL: baseDir}
+	for _, prefer := range []string{backendGH, backendGoGit} {
+		t.Run(prefer, func(t *testing.T) {
+			source := config.Source{
+				Name:       "test",
+				Type:       "github",
+				Repository: "owner/agents",
+				Branch:     head.Name().Short(),
+				Prefer:     prefer,
+				Paths:      config.PathConfig{Source: "agents"},
+			}
+			destDir := t.TempDir()
+
+			sourcePath, commit, err := handler.Fetch(context.Background(), source, destDir)
+			if err != nil {
+				t.Fatalf("Fetch failed: %v", err)
+			}
+			if commit != hash.String() {
+				t.Errorf("Expected commit %s, got %s", hash, commit)
+			}
+			if want := filepath.Join(destDir, "repo", "agents"); sourcePath != want {
+				t.Errorf("Expected source path %s, got %s", want, sourcePath)
+			}
+			if _, err := os.Stat(filepath.Join(sourcePath, "agent.md")); err != nil {
+				t.Errorf("Expected agent.md to be fetched: %v", err)
+			}
+
+			source.Branch = "missing"
+			if _, _, err := handler.Fetch(context.Background(), source, t.TempDir()); err == nil {
+				t.Error("Expected error for missing branch")
+			}
+		})
+	}
+}
+
+// fakeGH puts a stand-in gh on PATH that clones owner/repo from baseDir,
+// passing the flags after -- to git clone like gh does
+func fakeGH(t *testing.T, baseDir string) {
+	t.Helper()
+	binDir := t.TempDir()
+	script := fmt.Sprintf(`#!/bin/sh
+repo="$3"; dest="$4"; shift 4
+[ "$1" = "--" ] && shift
+exec git clone -q "$@" "file://%s/$repo.git" "$dest"
+`, filepath.ToSlash(baseDir))
+	if err := os.WriteFile(filepath.Join(binDir, "gh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestGitHubHandler_FetchShallowSparse(t *testing.T) {
+	if !commandExists("git") {
+		t.Skip("git not installed")
+	}
+
+	baseDir := t.TempDir()
+	repoDir := filepath.Join(baseDir, "owner", "agents.git")
+	repo, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init repo: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	var head plumbing.Hash
+	for n, file := range []string{"agents/team/first.md", "docs/guide.md", "agents/team/second.md"} {
+		if err := os.MkdirAll(filepath.Join(repoDir, filepath.Dir(file)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(repoDir, file), []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add(file); err != nil {
+			t.Fatalf("Failed to add file: %v", err)
+		}
+		head, err = worktree.Commit(fmt.Sprintf("commit %d", n), &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		if err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+	}
+	fakeGH(t, baseDir)
+
+	handler := &GitHubHandler{gitURL: "file://" + filepath.ToSlash(baseDir)}
+	for _, prefer := range []string{backendGH, backendGoGit} {
+		t.Run(prefer, func(t *testing.T) {
+			source := config.Source{
+				Name:       "test",
+				Type:       "github",
+				Repository: "owner/agents",
+				Prefer:     prefer,
+				Clone:      config.CloneConfig{Depth: 1, Sparse: true},
+				Paths:      config.PathConfig{Source: "agents/team"},
+			}
+			destDir := t.TempDir()
+
+			sourcePath, commit, err := handler.Fetch(context.Background(), source, destDir)
+			if err != nil {
+				t.Fatalf("Fetch failed: %v", err)
+			}
+			if commit != head.String() {
+				t.Errorf("Expected commit %s, got %s", head, commit)
+			}
+			for _, file := range []string{"first.md", "second.md"} {
+				if _, err := os.Stat(filepath.Join(sourcePath, file)); err != nil {
+					t.Errorf("Expected %s to be checked out: %v", file, err)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(destDir, "repo", "docs", "guide.md")); !os.IsNotExist(err) {
+				t.Errorf("Expected files outside paths.source not to be checked out: %v", err)
+			}
+
+			clone, err := git.PlainOpen(filepath.Join(destDir, "repo"))
+			if err != nil {
+				t.Fatalf("Failed to open clone: %v", err)
+			}
+			commits, err := clone.Log(&git.LogOptions{})
+			if err != nil {
+				t.Fatalf("Failed to read history: %v", err)
+			}
+			fetched := 0
+			_ = commits.ForEach(func(*object.Commit) error {
+				fetched++
+				return nil
+			})
+			if fetched != 1 {
+				t.Errorf("Expected a depth 1 clone to hold 1 commit, got %d", fetched)
+			}
+		})
+	}
+}
+
 func TestApplyFilters(t *testing.T) {
 	// Create a mock installer to test the applyFilters method
 	cfg := &config.Config{}
@@ -206,6 +741,61 @@ func TestApplyFilters(t *testing.T) {
 	}
 }
 
+func TestMatchesAgentNames(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "team"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"code-reviewer.md": "---\nname: code-reviewer\ndescription: Reviews\n---\nReview.",
+		"team/audit.md":    "---\nname: security-auditor\ndescription: Audits\n---\nAudit.",
+		"writer.md":        "---\nname: writer\ndescription: Writes\n---\nWrite.",
+	}
+	for rel, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, rel), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		relPath string
+		names   []string
+		want    bool
+	}{
+		{"writer.md", nil, true},
+		{"code-reviewer.md", []string{"code-reviewer"}, true},
+		{"code-reviewer.md", []string{"code-reviewer.md"}, true},
+		{"team/audit.md", []string{"team/audit"}, true},
+		{"team/audit.md", []string{"security-auditor"}, true},
+		{"writer.md", []string{"code-reviewer", "security-auditor"}, false},
+	}
+	for _, tt := range tests {
+		if got := matchesAgentNames(filepath.Join(dir, tt.relPath), tt.relPath, tt.names); got != tt.want {
+			t.Errorf("matchesAgentNames(%q, %v) = %v, want %v", tt.relPath, tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesIncludeExtensions(t *testing.T) {
+	tests := []struct {
+		fileName   string
+		extensions []string
+		want       bool
+	}{
+		{"agent.md", []string{".md"}, true},
+		{"agent.markdown", []string{".md"}, false},
+		{"reviewer.agent.md", []string{".agent.md"}, true},
+		{"reviewer.md", []string{".agent.md"}, false},
+		{"agent.md", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesIncludeExtensions(tt.fileName, tt.extensions); got != tt.want {
+			t.Errorf("matchesIncludeExtensions(%q, %v) = %v, want %v", tt.fileName, tt.extensions, got, tt.want)
+		}
+	}
+}
+
 // Helper functions for testing
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) && findInString(s, substr)
@@ -227,3 +817,142 @@ func startsWithFrontmatter(s string) bool {
 func startsWith(s, prefix string) bool {
 	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
 }
+
+func TestCheckTargetCollisions(t *testing.T) {
+	files := []string{"backend/reviewer.md", "frontend/reviewer.md", "Backend/Reviewer.md"}
+
+	if err := checkTargetCollisions(files[:2], true); err != nil {
+		t.Errorf("Expected namespaced files not to collide: %v", err)
+	}
+	if err := checkTargetCollisions(files, true); err == nil {
+		t.Error("Expected case-insensitive collision with preserve_structure")
+	}
+	if err := checkTargetCollisions(files, false); err != nil {
+		t.Errorf("Expected only a warning without preserve_structure: %v", err)
+	}
+}
+
+func TestAgentFileName(t *testing.T) {
+	if got := agentFileName("reviewer", "testing", false); got != "reviewer.md" {
+		t.Errorf("Expected flat file name, got %s", got)
+	}
+	if got := agentFileName("reviewer", "testing", true); got != filepath.Join("testing", "reviewer.md") {
+		t.Errorf("Expected file nested under category, got %s", got)
+	}
+}
+
+func TestFilterMarketplaceAgents(t *testing.T) {
+	agents := []marketplace.Agent{
+		{Name: "popular", Rating: 4.5, Downloads: 500, Tags: []string{"DevOps", "ci"}},
+		{Name: "unrated", Rating: 0, Downloads: 900, Tags: []string{"devops"}},
+		{Name: "new", Rating: 4.8, Downloads: 10, Tags: []string{"devops"}},
+		{Name: "offtopic", Rating: 5, Downloads: 1000, Tags: []string{"design"}},
+	}
+
+	if got := filterMarketplaceAgents(agents, config.FilterConfig{}); len(got) != len(agents) {
+		t.Errorf("Expected no filtering without metadata filters, got %d agents", len(got))
+	}
+
+	filters := config.FilterConfig{MinRating: 4.0, MinDownloads: 100, TagsAny: []string{"devops", "security"}}
+	got := filterMarketplaceAgents(agents, filters)
+	if len(got) != 1 || got[0].Name != "popular" {
+		t.Errorf("Expected only the popular devops agent, got %+v", got)
+	}
+}
+
+func TestGroupCategoryFiles(t *testing.T) {
+	fetched := map[string]FetchedCategory{
+		"development": {Version: "subagents-aaa", Files: []string{"go-expert.md", "py-expert.md"}},
+		"devops":      {Version: "subagents-bbb", Files: []string{"k8s-helper.md"}},
+		"empty":       {Version: "subagents-ccc", Files: []string{"skipped.md"}},
+	}
+	installed := map[string]tracker.FileInfo{
+		"/agents/go-expert.md":  {},
+		"/agents/py-expert.md":  {},
+		"/agents/k8s-helper.md": {},
+	}
+
+	categories := groupCategoryFiles(fetched, installed)
+
+	if len(categories) != 2 {
+		t.Fatalf("Expected 2 categories, got %d", len(categories))
+	}
+	if files := categories["development"].Files; len(files) != 2 || files[0] != "/agents/go-expert.md" {
+		t.Errorf("Unexpected development files: %v", files)
+	}
+	if commit := categories["devops"].SourceCommit; commit != "subagents-bbb" {
+		t.Errorf("Expected devops version subagents-bbb, got %s", commit)
+	}
+	if _, exists := categories["empty"]; exists {
+		t.Error("Categories without installed files should be omitted")
+	}
+	if groupCategoryFiles(nil, installed) != nil {
+		t.Error("Expected nil categories when none were fetched")
+	}
+}
+
+func TestEnforceFileLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		limits      config.LimitsConfig
+		size        int64
+		wantInstall bool
+		wantErr     bool
+	}{
+		{name: "no limit", limits: config.LimitsConfig{OnExceed: "fail"}, size: 1 << 20, wantInstall: true},
+		{name: "within limit", limits: config.LimitsConfig{MaxAgentFileKB: 4, OnExceed: "fail"}, size: 4096, wantInstall: true},
+		{name: "skip", limits: config.LimitsConfig{MaxAgentFileKB: 4, OnExceed: "skip"}, size: 4097},
+		{name: "warn", limits: config.LimitsConfig{MaxAgentFileKB: 4, OnExceed: "warn"}, size: 4097, wantInstall: true},
+		{name: "fail", limits: config.LimitsConfig{MaxAgentFileKB: 4, OnExceed: "fail"}, size: 4097, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			install, err := enforceFileLimit(tt.limits, "agent.md", tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("enforceFileLimit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if install != tt.wantInstall {
+				t.Errorf("enforceFileLimit() install = %v, want %v", install, tt.wantInstall)
+			}
+		})
+	}
+}
+
+func TestEnforceLicensePolicy(t *testing.T) {
+	allowMIT := []string{"MIT", "apache-2.0"}
+	tests := []struct {
+		name        string
+		policy      config.LicensePolicy
+		source      string
+		license     string
+		wantInstall bool
+		wantErr     bool
+	}{
+		{name: "no policy", policy: config.LicensePolicy{OnViolation: "fail"}, wantInstall: true},
+		{name: "allowed", policy: config.LicensePolicy{Allowed: allowMIT, OnViolation: "fail"}, license: "MIT", wantInstall: true},
+		{name: "case-insensitive expression", policy: config.LicensePolicy{Allowed: allowMIT, OnViolation: "fail"}, license: "GPL-3.0 OR Apache-2.0", wantInstall: true},
+		{name: "not allowed skipped", policy: config.LicensePolicy{Allowed: allowMIT, OnViolation: "skip"}, license: "GPL-3.0"},
+		{name: "missing required", policy: config.LicensePolicy{Require: true, OnViolation: "skip"}},
+		{name: "declared satisfies require", policy: config.LicensePolicy{Require: true, OnViolation: "fail"}, license: "Unlicense", wantInstall: true},
+		{name: "warn", policy: config.LicensePolicy{Allowed: allowMIT, OnViolation: "warn"}, license: "GPL-3.0", wantInstall: true},
+		{name: "fail", policy: config.LicensePolicy{Allowed: allowMIT, OnViolation: "fail"}, wantErr: true},
+		{name: "exempt source", policy: config.LicensePolicy{Allowed: allowMIT, ExemptSources: []string{"internal"}, OnViolation: "fail"}, source: "internal", wantInstall: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := tt.source
+			if source == "" {
+				source = "community"
+			}
+			install, err := enforceLicensePolicy(tt.policy, source, "agent.md", tt.license)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("enforceLicensePolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if install != tt.wantInstall {
+				t.Errorf("enforceLicensePolicy() install = %v, want %v", install, tt.wantInstall)
+			}
+		})
+	}
+}
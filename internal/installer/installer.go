@@ -1,16 +1,21 @@
 package installer
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/archive"
+	"github.com/pacphi/claude-code-agent-manager/internal/artifact"
 	"github.com/pacphi/claude-code-agent-manager/internal/config"
 	"github.com/pacphi/claude-code-agent-manager/internal/conflict"
 	"github.com/pacphi/claude-code-agent-manager/internal/progress"
+	"github.com/pacphi/claude-code-agent-manager/internal/quarantine"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
 	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
 	"github.com/pacphi/claude-code-agent-manager/internal/transformer"
@@ -22,14 +27,33 @@ type Options struct {
 	Verbose     bool
 	DryRun      bool
 	KeepBackups bool
+	// Apply allows changes to sources that set dry_run
+	Apply bool
+	// Diff adds the unified diff of each changed agent to plans and dry runs
+	Diff bool
 }
 
 // Installer manages agent installation
 type Installer struct {
-	config   *config.Config
-	tracker  *tracker.Tracker
-	resolver *conflict.Resolver
-	options  Options
+	config    *config.Config
+	tracker   *tracker.Tracker
+	resolver  *conflict.Resolver
+	options   Options
+	conflicts []conflict.Outcome
+	// unchanged are installed paths whose existing content already matched
+	unchanged []string
+	metrics   []SourceMetrics
+	planned   []string
+	agents    []*parser.AgentSpec
+	// versionChanges are the per-agent changes reported by updates
+	versionChanges []VersionChange
+	// changelogFrom holds the installed commit of sources being updated, and
+	// changelogs the commits install found since then
+	changelogFrom map[string]string
+	changelogs    map[string]*Changelog
+	// updateChecks holds whether each source checked by update still has
+	// an update available
+	updateChecks map[string]bool
 }
 
 // New creates a new installer instance
@@ -42,56 +66,255 @@ func New(cfg *config.Config, track *tracker.Tracker, resolver *conflict.Resolver
 	}
 }
 
+// Conflicts returns how each pre-existing file was resolved by installs run with this installer
+func (i *Installer) Conflicts() []conflict.Outcome {
+	return i.conflicts
+}
+
+// Metrics returns the per-phase metrics of each source installed with this installer
+func (i *Installer) Metrics() []SourceMetrics {
+	return i.metrics
+}
+
+// InstalledAgents returns the agent files written by this installer, already
+// parsed, so the query index can be updated without re-reading them
+func (i *Installer) InstalledAgents() []*parser.AgentSpec {
+	return i.agents
+}
+
+// UpdateChecks returns, for each source checked for updates with this
+// installer, whether an update is still available
+func (i *Installer) UpdateChecks() map[string]bool {
+	return i.updateChecks
+}
+
+// Planned returns the sources that were only planned because they set dry_run
+func (i *Installer) Planned() []string {
+	return i.planned
+}
+
+// planOnly reports whether changes to a source must only be planned because
+// it sets dry_run and the run was not started with --apply
+func (i *Installer) planOnly(source *config.Source) bool {
+	return source != nil && source.DryRun && !i.options.DryRun && !i.options.Apply
+}
+
+// plan runs an operation for a dry_run source in dry-run mode
+func (i *Installer) plan(sourceName string, run func(*Installer) error) error {
+	color.Yellow("[PLAN] %s has dry_run enabled; re-run with --apply to make changes\n", sourceName)
+
+	planner := *i
+	planner.options.DryRun = true
+	err := run(&planner)
+
+	i.conflicts, i.metrics = planner.conflicts, planner.metrics
+	i.planned = append(i.planned, sourceName)
+	return err
+}
+
+// findSource returns the configured source for a source or sub-source name
+func (i *Installer) findSource(sourceName string) *config.Source {
+	parent, _ := tracker.SplitSubSource(sourceName)
+	for idx := range i.config.Sources {
+		if name := i.config.Sources[idx].Name; name == sourceName || name == parent {
+			return &i.config.Sources[idx]
+		}
+	}
+	return nil
+}
+
 // InstallSource installs agents from a specific source
-func (i *Installer) InstallSource(source config.Source) error {
+func (i *Installer) InstallSource(ctx context.Context, source config.Source) error {
+	if i.planOnly(&source) {
+		return i.plan(source.Name, func(p *Installer) error { return p.InstallSource(ctx, source) })
+	}
 	if i.options.DryRun {
 		color.Yellow("[DRY RUN] Would install from source: %s\n", source.Name)
+		return i.dryRunInstall(ctx, source, false)
 	}
 
-	// Create temporary directory and fetch source
-	fetchedPath, commit, tempDir, err := i.fetchSource(source)
+	metrics := SourceMetrics{Source: source.Name}
+	installation, err := i.install(ctx, source, &metrics)
+	if err != nil || installation == nil {
+		return err
+	}
+
+	i.metrics = append(i.metrics, metrics)
+	if i.options.Verbose {
+		metrics.Print(os.Stdout)
+	}
+
+	// Save installation tracking
+	if !i.options.DryRun {
+		if err := i.tracker.RecordInstallation(source.Name, *installation); err != nil {
+			return fmt.Errorf("failed to record installation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// InstallAgents installs only the agents of source with the given names, such
+// as the members of a collection, merging them into the tracked installation
+// of the source so its other agents stay installed
+func (i *Installer) InstallAgents(ctx context.Context, source config.Source, names []string) error {
+	source.Filters.Agents = names
+	if i.planOnly(&source) {
+		return i.plan(source.Name, func(p *Installer) error { return p.InstallAgents(ctx, source, names) })
+	}
+	if i.options.DryRun {
+		color.Yellow("[DRY RUN] Would install %s from source: %s\n", strings.Join(names, ", "), source.Name)
+		return i.dryRunInstall(ctx, source, true)
+	}
+
+	metrics := SourceMetrics{Source: source.Name}
+	installation, err := i.install(ctx, source, &metrics)
+	if err != nil || installation == nil {
+		return err
+	}
+
+	i.metrics = append(i.metrics, metrics)
+	if i.options.Verbose {
+		metrics.Print(os.Stdout)
+	}
+
+	if !i.options.DryRun {
+		if err := i.tracker.MergeInstallation(source.Name, *installation); err != nil {
+			return fmt.Errorf("failed to record installation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// dryRunInstall prints how installing source would change the target
+// directory: the files added, changed and removed compared with the installed
+// ones. With merge the other installed files of the source are kept, so none
+// are reported as removed.
+func (i *Installer) dryRunInstall(ctx context.Context, source config.Source, merge bool) error {
+	if err := i.checkQuarantine(source.Name); err != nil {
+		return err
+	}
+	plan, err := i.PlanSource(ctx, source)
 	if err != nil {
 		return err
 	}
-	defer i.cleanupTempDir(tempDir)
+	if merge {
+		kept := plan.Files[:0]
+		for _, file := range plan.Files {
+			if file.Action != PlanRemoved {
+				kept = append(kept, file)
+			}
+		}
+		plan.Files = kept
+	}
+	printPlanFiles(plan, i.options.Verbose)
+	return nil
+}
+
+// checkQuarantine refuses to install a quarantined source
+func (i *Installer) checkQuarantine(sourceName string) error {
+	if quarantine.New(quarantine.DefaultDir(i.config.Metadata.TrackingFile)).IsQuarantined(sourceName) {
+		return fmt.Errorf("source %s is quarantined; run 'agent-manager unquarantine %s' to allow installation", sourceName, sourceName)
+	}
+	return nil
+}
+
+// install fetches and installs a source, returning the installation to track;
+// the installation is nil when no files matched the source filters
+func (i *Installer) install(ctx context.Context, source config.Source, metrics *SourceMetrics) (*tracker.Installation, error) {
+	start := time.Now()
+	conflictsBefore := len(i.conflicts)
+	unchangedBefore := len(i.unchanged)
+
+	if err := i.checkQuarantine(source.Name); err != nil {
+		return nil, err
+	}
+
+	// Create temporary directory and fetch source
+	phase := time.Now()
+	handler, fetchedPath, commit, tempDir, err := i.fetchSource(ctx, source)
+	metrics.Fetch = time.Since(phase)
+	if tempDir != "" {
+		defer i.cleanupTempDir(tempDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	i.collectChangelog(source, fetchedPath, commit)
 
 	// Apply filters and get files
+	phase = time.Now()
 	files, err := i.applyFilters(fetchedPath, source.Filters)
+	metrics.Filter = time.Since(phase)
 	if err != nil {
-		return fmt.Errorf("failed to apply filters: %w", err)
+		return nil, fmt.Errorf("failed to apply filters: %w", err)
 	}
 
 	if len(files) == 0 {
 		color.Yellow("No files matched the filters for source: %s\n", source.Name)
-		return nil
+		return nil, nil
 	}
 
 	// Prepare installation tracking
 	installation := tracker.Installation{
-		SourceCommit:  commit,
-		Files:         make(map[string]tracker.FileInfo),
-		Directories:   []string{},
-		DocsGenerated: []string{},
+		SourceCommit: commit,
+		ConfigHash:   i.config.SourceHash(source),
+		Files:        make(map[string]tracker.FileInfo),
+		Directories:  []string{},
+		Docs:         make(map[string]tracker.DocInfo),
 	}
 
 	// Apply transformations
+	phase = time.Now()
 	transformedFiles, err := i.applyTransformations(source, files, fetchedPath, &installation)
+	metrics.Transform = time.Since(phase)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// Refuse to let several files overwrite each other at the same target
+	if err := checkTargetCollisions(transformedFiles, source.PreserveStructure); err != nil {
+		return nil, err
 	}
 
 	// Install files
-	if err := i.installFiles(source, transformedFiles, fetchedPath, &installation); err != nil {
-		return err
+	phase = time.Now()
+	if err := i.installFiles(ctx, source, transformedFiles, fetchedPath, &installation); err != nil {
+		return nil, err
+	}
+	metrics.Copy = time.Since(phase)
+	unchanged := make(map[string]bool)
+	for _, path := range i.unchanged[unchangedBefore:] {
+		unchanged[path] = true
+	}
+	metrics.FilesUnchanged = len(unchanged)
+	metrics.FilesCopied = len(installation.Files) - len(unchanged)
+	for path, file := range installation.Files {
+		if !unchanged[path] {
+			metrics.BytesCopied += file.Size
+		}
+	}
+	metrics.ConflictsResolved = len(i.conflicts) - conflictsBefore
+
+	if mirrored, ok := handler.(MirroredHandler); ok {
+		installation.Mirror = mirrored.FetchedMirror()
+	}
+
+	// Track marketplace categories as sub-installations
+	if categorized, ok := handler.(CategorizedHandler); ok {
+		installation.Categories = groupCategoryFiles(categorized.FetchedCategories(), installation.Files)
 	}
 
 	// Run post-install actions
+	phase = time.Now()
 	if err := i.runPostInstallActions(source); err != nil {
-		return err
+		return nil, err
 	}
+	metrics.PostInstall = time.Since(phase)
 
 	// Extract agent metadata for query indexing
-	if !i.options.DryRun {
+	if !i.options.DryRun && source.ArtifactKind() == config.KindAgent {
 		agentMetadata := i.extractAgentMetadata(source.Name, transformedFiles, fetchedPath)
 		if len(agentMetadata) > 0 {
 			// Store agent metadata in installation
@@ -102,28 +325,70 @@ func (i *Installer) InstallSource(source config.Source) error {
 		}
 	}
 
-	// Save installation tracking
-	if !i.options.DryRun {
-		if err := i.tracker.RecordInstallation(source.Name, installation); err != nil {
-			return fmt.Errorf("failed to record installation: %w", err)
+	metrics.finish(start)
+	return &installation, nil
+}
+
+// checkTargetCollisions reports files that would install to the same target
+// path, comparing case-insensitively for case-insensitive filesystems. With
+// preserve_structure a collision is an error; otherwise it is a warning.
+func checkTargetCollisions(files []string, preserveStructure bool) error {
+	targets := make(map[string]string, len(files))
+	for _, file := range files {
+		key := strings.ToLower(filepath.Clean(file))
+		if other, ok := targets[key]; ok && other != file {
+			if preserveStructure {
+				return fmt.Errorf("target collision: %s and %s install to the same path", other, file)
+			}
+			color.Yellow("Warning: %s and %s install to the same path; set preserve_structure: true to fail on collisions\n", other, file)
+			continue
 		}
+		targets[key] = file
 	}
-
 	return nil
 }
 
+// groupCategoryFiles maps fetched category file names to their installed paths
+func groupCategoryFiles(fetched map[string]FetchedCategory, installed map[string]tracker.FileInfo) map[string]*tracker.CategoryInstallation {
+	if len(fetched) == 0 {
+		return nil
+	}
+
+	categories := make(map[string]*tracker.CategoryInstallation, len(fetched))
+	now := time.Now()
+	for slug, category := range fetched {
+		entry := &tracker.CategoryInstallation{
+			Timestamp:    now,
+			SourceCommit: category.Version,
+			Files:        []string{},
+		}
+		for _, name := range category.Files {
+			for path := range installed {
+				if path == name || strings.HasSuffix(path, string(filepath.Separator)+name) {
+					entry.Files = append(entry.Files, path)
+				}
+			}
+		}
+		if len(entry.Files) > 0 {
+			sort.Strings(entry.Files)
+			categories[slug] = entry
+		}
+	}
+	return categories
+}
+
 // fetchSource creates temp directory and fetches source content
-func (i *Installer) fetchSource(source config.Source) (string, string, string, error) {
+func (i *Installer) fetchSource(ctx context.Context, source config.Source) (SourceHandler, string, string, string, error) {
 	// Create temporary directory for cloning/copying
-	tempDir, err := os.MkdirTemp("", "agent-install-*")
+	tempDir, err := util.MkdirTemp("agent-install-*")
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to create temp directory: %w", err)
+		return nil, "", "", "", fmt.Errorf("failed to create temp directory: %w", err)
 	}
 
 	// Get source handler based on type
-	handler, err := i.getSourceHandler(source.Type)
+	handler, err := i.getSourceHandler(source)
 	if err != nil {
-		return "", "", tempDir, err
+		return nil, "", "", tempDir, err
 	}
 
 	// Fetch source to temp directory
@@ -131,12 +396,12 @@ func (i *Installer) fetchSource(source config.Source) (string, string, string, e
 		fmt.Printf("Fetching source %s...\n", source.Name)
 	}
 
-	fetchedPath, commit, err := handler.Fetch(source, tempDir)
+	fetchedPath, commit, err := handler.Fetch(ctx, source, tempDir)
 	if err != nil {
-		return "", "", tempDir, fmt.Errorf("failed to fetch source: %w", err)
+		return nil, "", "", tempDir, fmt.Errorf("failed to fetch source: %w", err)
 	}
 
-	return fetchedPath, commit, tempDir, nil
+	return handler, fetchedPath, commit, tempDir, nil
 }
 
 // cleanupTempDir removes temporary directory
@@ -160,8 +425,23 @@ func (i *Installer) applyTransformations(source config.Source, files []string, f
 		}
 	}
 
-	// Apply transformations
+	// Apply transformations, leaving docs generated for other sources alone
 	trans := transformer.New(i.config.Settings)
+	trans.SetDocOwner(func(path string) string {
+		owners, err := i.tracker.DocOwners(path)
+		if err != nil {
+			return ""
+		}
+		for _, owner := range owners {
+			if owner != source.Name {
+				return owner
+			}
+		}
+		return ""
+	})
+	trans.SetReadonly(func(path string) string {
+		return i.readonlyPattern(source.Name, path)
+	})
 	transformedFiles := files
 
 	for _, transform := range source.Transformations {
@@ -174,22 +454,42 @@ func (i *Installer) applyTransformations(source config.Source, files []string, f
 		if err != nil {
 			return nil, fmt.Errorf("transformation failed: %w", err)
 		}
+	}
 
-		// Track generated docs
-		if transform.Type == "extract_docs" {
-			for _, file := range transformedFiles {
-				if filepath.Dir(file) == i.config.Settings.DocsDir {
-					installation.DocsGenerated = append(installation.DocsGenerated, file)
-				}
-			}
+	// Track generated docs with their checksums
+	for _, doc := range trans.Docs() {
+		hash, err := util.FileSHA256(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to track doc %s: %w", doc, err)
+		}
+		info, err := os.Stat(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to track doc %s: %w", doc, err)
+		}
+		installation.Docs[doc] = tracker.DocInfo{Path: doc, Hash: hash, Size: info.Size()}
+	}
+	for _, docConflict := range trans.DocConflicts() {
+		if docConflict.Pattern != "" {
+			i.refuseReadonly(source.Name, docConflict.Path, docConflict.Pattern, "skip")
+			continue
 		}
+		color.Yellow("Warning: left doc %s alone: it was generated for source %s\n", docConflict.Path, docConflict.Owner)
+		i.conflicts = append(i.conflicts, conflict.Outcome{
+			Path:     docConflict.Path,
+			Source:   source.Name,
+			Strategy: "skip",
+			Action:   conflict.ActionSkipped,
+			Detail:   fmt.Sprintf("doc generated for source %s", docConflict.Owner),
+		})
 	}
 
 	return transformedFiles, nil
 }
 
-// installFiles copies files to target with conflict resolution
-func (i *Installer) installFiles(source config.Source, transformedFiles []string, fetchedPath string, installation *tracker.Installation) error {
+// installFiles copies files to target with conflict resolution. Conflicts are
+// resolved one file at a time before the copies run, concurrently when
+// settings.io allows it.
+func (i *Installer) installFiles(ctx context.Context, source config.Source, transformedFiles []string, fetchedPath string, installation *tracker.Installation) error {
 	targetDir := i.resolveTargetPath(source.Paths.Target)
 
 	// Get conflict strategy
@@ -206,75 +506,352 @@ func (i *Installer) installFiles(source config.Source, transformedFiles []string
 		defer pm.FinishProgress(progressID, true, "")
 	}
 
+	// Archived agents stay in the archive; keep tracking them so unarchive restores a tracked file
+	archived := make(map[string]*archive.Entry)
+	if entries, err := archive.New(archive.DefaultDir(i.config.Metadata.TrackingFile)).List(); err == nil {
+		for _, entry := range entries {
+			archived[entry.Original] = entry
+		}
+	}
+
+	kind := source.ArtifactKind()
+	extensions := i.config.Settings.Query.Index.Extensions
+	agentParser := &parser.Parser{Mode: i.config.Settings.Query.ParserMode, Extensions: extensions}
+	var files []*fileCopy
 	for _, relPath := range transformedFiles {
-		if err := i.installSingleFile(relPath, fetchedPath, targetDir, conflictStrategy, installation); err != nil {
+		dstPath := filepath.Join(targetDir, relPath)
+		if absPath, err := filepath.Abs(dstPath); err == nil && archived[absPath] != nil {
+			entry := archived[absPath]
+			if !i.options.DryRun {
+				installation.Files[dstPath] = tracker.FileInfo{Path: dstPath, Size: entry.Agent.FileSize, Modified: entry.Agent.ModTime}
+			}
+			if i.options.Verbose {
+				fmt.Printf("Skipped archived agent: %s\n", dstPath)
+			}
+			continue
+		}
+
+		if kind != config.KindAgent && artifact.IsArtifactFile(kind, relPath, extensions) {
+			if err := artifact.Validate(kind, filepath.Join(fetchedPath, relPath)); err != nil {
+				color.Yellow("Warning: skipping %s %s: %v\n", kind, relPath, err)
+				continue
+			}
+		}
+
+		if kind == config.KindAgent && parser.IsAgentFile(relPath, extensions) {
+			if info, err := os.Stat(filepath.Join(fetchedPath, relPath)); err == nil {
+				install, err := enforceFileLimit(i.config.Settings.Limits, relPath, info.Size())
+				if err != nil {
+					return err
+				}
+				if !install {
+					continue
+				}
+			}
+			install, err := enforceLicensePolicy(i.config.Settings.Licenses, source.Name, relPath, agentLicense(filepath.Join(fetchedPath, relPath)))
+			if err != nil {
+				return err
+			}
+			if !install {
+				continue
+			}
+		}
+
+		file, err := i.planFile(source.Name, relPath, fetchedPath, targetDir, conflictStrategy)
+		if err != nil {
 			return err
 		}
+		if file != nil {
+			files = append(files, file)
+			continue
+		}
+		i.reportInstalled(dstPath, pm, progressID, len(transformedFiles))
+	}
 
-		if i.options.Verbose {
-			dstPath := filepath.Join(targetDir, relPath)
-			fmt.Printf("Installed: %s\n", dstPath)
-		} else if !i.options.DryRun && len(transformedFiles) > 1 {
-			// Update progress bar
-			pm.UpdateProgress(progressID, 1)
+	if err := i.copyFiles(ctx, source, files); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := trackFile(file, installation); err != nil {
+			return err
+		}
+
+		// Claude Code runs statusline scripts directly
+		if kind == config.KindStatusline && artifact.IsArtifactFile(kind, file.relPath, extensions) {
+			if err := os.Chmod(file.dstPath, 0755); err != nil {
+				return fmt.Errorf("failed to make %s executable: %w", file.dstPath, err)
+			}
+		}
+
+		// Keep the parsed agent so the query index can be warmed after install;
+		// files that fail to parse are left for the next index update to report
+		if kind == config.KindAgent && parser.IsAgentFile(file.relPath, extensions) {
+			if agent, err := agentParser.ParseFile(file.dstPath); err == nil {
+				i.agents = append(i.agents, agent)
+			}
 		}
+
+		i.reportInstalled(file.dstPath, pm, progressID, len(transformedFiles))
 	}
 
 	return nil
 }
 
-// installSingleFile handles installation of a single file
-func (i *Installer) installSingleFile(relPath, fetchedPath, targetDir, conflictStrategy string, installation *tracker.Installation) error {
-	srcPath := filepath.Join(fetchedPath, relPath)
-	dstPath := filepath.Join(targetDir, relPath)
+// reportInstalled reports a file of a source with total files as handled
+func (i *Installer) reportInstalled(dstPath string, pm *progress.Manager, progressID string, total int) {
+	if i.options.Verbose {
+		fmt.Printf("Installed: %s\n", dstPath)
+	} else if !i.options.DryRun && total > 1 {
+		// Update progress bar
+		pm.UpdateProgress(progressID, 1)
+	}
+}
 
-	if !i.options.DryRun {
-		// Check if file already exists (pre-existing)
-		var wasPreExisting bool
-		if _, err := os.Stat(dstPath); err == nil {
-			wasPreExisting = true
-			// File exists, resolve conflict
-			resolved, err := i.resolver.Resolve(dstPath, srcPath, conflictStrategy)
-			if err != nil {
-				return fmt.Errorf("conflict resolution failed for %s: %w", dstPath, err)
-			}
-			if !resolved {
-				if i.options.Verbose {
-					fmt.Printf("Skipped: %s\n", dstPath)
-				}
-				return nil
+// enforceFileLimit applies settings.limits to an agent file of size bytes,
+// reporting whether the file should be installed
+func enforceFileLimit(limits config.LimitsConfig, name string, size int64) (bool, error) {
+	maxBytes := limits.MaxAgentFileBytes()
+	if maxBytes == 0 || size <= maxBytes {
+		return true, nil
+	}
+
+	sizeKB := (size + 1023) / 1024
+	switch limits.OnExceed {
+	case "skip":
+		fmt.Printf("Warning: skipping %s: %d KB exceeds max_agent_file_kb (%d KB)\n", name, sizeKB, limits.MaxAgentFileKB)
+		return false, nil
+	case "fail":
+		return false, fmt.Errorf("agent file %s is %d KB, exceeding max_agent_file_kb (%d KB)", name, sizeKB, limits.MaxAgentFileKB)
+	default:
+		fmt.Printf("Warning: %s is %d KB, exceeding max_agent_file_kb (%d KB)\n", name, sizeKB, limits.MaxAgentFileKB)
+		return true, nil
+	}
+}
+
+// enforceLicensePolicy applies settings.licenses to an agent file of source
+// declaring license, reporting whether the file should be installed
+func enforceLicensePolicy(policy config.LicensePolicy, sourceName, name, license string) (bool, error) {
+	violation := policy.Violation(sourceName, license)
+	if violation == "" {
+		return true, nil
+	}
+
+	switch policy.OnViolation {
+	case "warn":
+		fmt.Printf("Warning: %s from %s: %s\n", name, sourceName, violation)
+		return true, nil
+	case "fail":
+		return false, fmt.Errorf("agent file %s from %s violates the license policy: %s", name, sourceName, violation)
+	default:
+		fmt.Printf("Warning: skipping %s from %s: %s\n", name, sourceName, violation)
+		return false, nil
+	}
+}
+
+// agentLicense returns the license declared in an agent file's frontmatter,
+// or an empty string when it declares none or cannot be read
+func agentLicense(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	fields, err := parser.FrontmatterFields(string(content))
+	if err != nil {
+		return ""
+	}
+	if license, ok := fields["license"].(string); ok {
+		return strings.TrimSpace(license)
+	}
+	return ""
+}
+
+// fileCopy is a file installFiles decided to install
+type fileCopy struct {
+	relPath        string
+	srcPath        string
+	dstPath        string
+	replace        bool // false when a merge already wrote the combined content in place
+	wasPreExisting bool
+}
+
+// planFile resolves how a single file is installed, returning nil when the
+// file is left as it is
+func (i *Installer) planFile(sourceName, relPath, fetchedPath, targetDir, conflictStrategy string) (*fileCopy, error) {
+	if i.options.DryRun {
+		return nil, nil
+	}
+
+	file := &fileCopy{
+		relPath: relPath,
+		srcPath: filepath.Join(fetchedPath, relPath),
+		dstPath: filepath.Join(targetDir, relPath),
+		replace: true,
+	}
+	dstPath := file.dstPath
+
+	// Readonly paths are never written, whether or not the file exists yet
+	if pattern := i.readonlyPattern(sourceName, dstPath); pattern != "" {
+		i.refuseReadonly(sourceName, dstPath, pattern, conflictStrategy)
+		return nil, nil
+	}
+
+	// Check if file already exists (pre-existing)
+	if _, err := os.Stat(dstPath); err == nil {
+		owner, tracked, err := i.tracker.FindFile(dstPath)
+		if err != nil {
+			return nil, err
+		}
+		// A file this source installed before is not pre-existing, while a
+		// file no source installed, or one a source adopted, was authored
+		// outside agent-manager
+		file.wasPreExisting = owner != sourceName || tracked.WasPreExisting
+		authored := owner == "" || tracked.WasPreExisting
+		if authored && i.config.Settings.ProtectPreExisting {
+			i.conflicts = append(i.conflicts, conflict.Outcome{
+				Path:        dstPath,
+				Source:      sourceName,
+				Strategy:    conflictStrategy,
+				Action:      conflict.ActionProtected,
+				Detail:      "kept by settings.protect_pre_existing",
+				PreExisting: true,
+			})
+			if i.options.Verbose {
+				fmt.Printf("Protected: %s\n", dstPath)
 			}
+			return nil, nil
 		}
 
-		// Ensure parent directory exists
-		if err := os.MkdirAll(filepath.Dir(dstPath), 0750); err != nil {
-			return fmt.Errorf("failed to create directory: %w", err)
+		// File exists, resolve conflict
+		outcome, err := i.resolver.ResolveDetailed(dstPath, file.srcPath, conflictStrategy)
+		if err != nil {
+			return nil, fmt.Errorf("conflict resolution failed for %s: %w", dstPath, err)
 		}
-
-		// Copy file
-		if err := i.copyFile(srcPath, dstPath); err != nil {
-			return fmt.Errorf("failed to copy %s: %w", relPath, err)
+		outcome.Source = sourceName
+		outcome.PreExisting = authored
+		if outcome.Action == conflict.ActionUnchanged {
+			if i.options.Verbose {
+				fmt.Printf("Unchanged: %s\n", dstPath)
+			}
+			i.unchanged = append(i.unchanged, dstPath)
+		} else {
+			i.conflicts = append(i.conflicts, outcome)
 		}
+		if outcome.Action == conflict.ActionSkipped {
+			if i.options.Verbose {
+				fmt.Printf("Skipped: %s\n", dstPath)
+			}
+			return nil, nil
+		}
+		file.replace = outcome.Replaces()
+	}
 
-		// Track installed file
-		info, err := os.Stat(dstPath)
-		if err != nil {
-			return fmt.Errorf("failed to stat installed file %s: %w", dstPath, err)
+	return file, nil
+}
+
+// readonlyPattern returns the readonly_paths pattern protecting path from
+// sourceName, or "" when the source may write or delete it. Sources no longer
+// configured are still bound by settings.readonly_paths.
+func (i *Installer) readonlyPattern(sourceName, path string) string {
+	patterns := i.config.Settings.ReadonlyPaths
+	if source := i.findSource(sourceName); source != nil {
+		patterns = i.config.ReadonlyPaths(*source)
+	}
+	return config.MatchReadonly(patterns, path)
+}
+
+// refuseReadonly reports that sourceName would have written path, which
+// matches a readonly_paths pattern, and records the violation
+func (i *Installer) refuseReadonly(sourceName, path, pattern, conflictStrategy string) {
+	color.Yellow("Warning: not writing %s from %s: it matches readonly_paths pattern %q\n", path, sourceName, pattern)
+	i.conflicts = append(i.conflicts, conflict.Outcome{
+		Path:     path,
+		Source:   sourceName,
+		Strategy: conflictStrategy,
+		Action:   conflict.ActionReadonly,
+		Detail:   fmt.Sprintf("matches readonly_paths pattern %q", pattern),
+	})
+}
+
+// keepReadonly reports whether removing path for sourceName is refused
+// because it matches a readonly_paths pattern, warning when it is
+func (i *Installer) keepReadonly(sourceName, path string) bool {
+	pattern := i.readonlyPattern(sourceName, path)
+	if pattern == "" {
+		return false
+	}
+	color.Yellow("Warning: not removing %s: it matches readonly_paths pattern %q\n", path, pattern)
+	return true
+}
+
+// copyFiles copies the planned files on a bounded pool of workers, paced by
+// the io settings of source. The pool hands out one file at a time, so a slow
+// target holds back the copies still to start instead of queueing them.
+func (i *Installer) copyFiles(ctx context.Context, source config.Source, files []*fileCopy) error {
+	limits := i.config.Settings.IO.Override(source.IO)
+	workers := limits.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	throttle := util.NewThrottle(limits.FilesPerSecond, limits.MBPerSecond*1024*1024)
+
+	errs := make([]error, len(files))
+	err := util.ForEachParallel(ctx, len(files), workers, func(n int) {
+		file := files[n]
+		if !file.replace {
+			return
 		}
-		installation.Files[dstPath] = tracker.FileInfo{
-			Path:           dstPath,
-			Size:           info.Size(),
-			Modified:       info.ModTime(),
-			WasPreExisting: wasPreExisting,
+		var size int64
+		if info, err := os.Stat(file.srcPath); err == nil {
+			size = info.Size()
+		}
+		if err := throttle.Wait(ctx, size); err != nil {
+			errs[n] = err
+			return
 		}
 
-		// Track directory
-		dir := filepath.Dir(dstPath)
-		if !contains(installation.Directories, dir) {
-			installation.Directories = append(installation.Directories, dir)
+		// Ensure parent directory exists
+		if err := os.MkdirAll(filepath.Dir(file.dstPath), 0750); err != nil {
+			errs[n] = fmt.Errorf("failed to create directory: %w", err)
+			return
+		}
+		if err := i.copyFile(file.srcPath, file.dstPath); err != nil {
+			errs[n] = fmt.Errorf("failed to copy %s: %w", file.relPath, err)
 		}
+	})
+	for _, copyErr := range errs {
+		if copyErr != nil {
+			return copyErr
+		}
+	}
+	return err
+}
+
+// trackFile records an installed file with its checksum, so verify can tell
+// edits made outside agent-manager
+func trackFile(file *fileCopy, installation *tracker.Installation) error {
+	dstPath := file.dstPath
+	info, err := os.Stat(dstPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat installed file %s: %w", dstPath, err)
+	}
+	hash, err := util.FileSHA256(dstPath)
+	if err != nil {
+		return fmt.Errorf("failed to checksum installed file %s: %w", dstPath, err)
+	}
+	installation.Files[dstPath] = tracker.FileInfo{
+		Path:           dstPath,
+		Hash:           hash,
+		Size:           info.Size(),
+		Modified:       info.ModTime(),
+		WasPreExisting: file.wasPreExisting,
 	}
 
+	// Track directory
+	dir := filepath.Dir(dstPath)
+	if !contains(installation.Directories, dir) {
+		installation.Directories = append(installation.Directories, dir)
+	}
 	return nil
 }
 
@@ -299,12 +876,18 @@ func (i *Installer) runPostInstallActions(source config.Source) error {
 
 // UninstallSource removes agents from a specific source
 func (i *Installer) UninstallSource(sourceName string) error {
+	if i.planOnly(i.findSource(sourceName)) {
+		return i.plan(sourceName, func(p *Installer) error { return p.UninstallSource(sourceName) })
+	}
 	if i.options.DryRun {
 		color.Yellow("[DRY RUN] Would uninstall source: %s\n", sourceName)
 	}
 
 	installation, err := i.tracker.GetInstallation(sourceName)
 	if err != nil {
+		if parent, category := tracker.SplitSubSource(sourceName); category != "" {
+			return i.uninstallCategory(parent, category)
+		}
 		return fmt.Errorf("source not found: %s", sourceName)
 	}
 
@@ -340,6 +923,9 @@ func (i *Installer) UninstallSource(sourceName string) error {
 				}
 				continue
 			}
+			if i.keepReadonly(sourceName, path) {
+				continue
+			}
 
 			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 				color.Red("Failed to remove %s: %v\n", path, err)
@@ -372,8 +958,13 @@ func (i *Installer) UninstallSource(sourceName string) error {
 		}
 	}
 
-	// Remove documentation
-	for _, doc := range installation.DocsGenerated {
+	// Remove documentation, keeping docs other sources generated too and docs
+	// changed since they were generated
+	for _, doc := range installation.DocPaths() {
+		if reason := i.keepDoc(sourceName, doc, installation.Docs[doc]); reason != "" {
+			color.Yellow("Warning: keeping doc %s: %s\n", doc, reason)
+			continue
+		}
 		if !i.options.DryRun {
 			if err := os.Remove(doc); err != nil && !os.IsNotExist(err) {
 				color.Red("Failed to remove doc %s: %v\n", doc, err)
@@ -397,18 +988,157 @@ func (i *Installer) UninstallSource(sourceName string) error {
 		}
 	}
 
-	color.Green("✓ Uninstalled source: %s\n", sourceName)
+	color.Green("%s Uninstalled source: %s\n", util.Symbol("✓"), sourceName)
+	return nil
+}
+
+// keepDoc returns why uninstalling sourceName must not remove doc, or "" when
+// it may: another source generated the doc too, or its content no longer
+// matches the checksum recorded when it was generated
+func (i *Installer) keepDoc(sourceName, doc string, info tracker.DocInfo) string {
+	if pattern := i.readonlyPattern(sourceName, doc); pattern != "" {
+		return fmt.Sprintf("it matches readonly_paths pattern %q", pattern)
+	}
+	owners, err := i.tracker.DocOwners(doc)
+	if err != nil {
+		return err.Error()
+	}
+	for _, owner := range owners {
+		if owner != sourceName {
+			return fmt.Sprintf("it was also generated for source %s", owner)
+		}
+	}
+
+	if info.Hash == "" {
+		return ""
+	}
+	hash, err := util.FileSHA256(doc)
+	if err != nil {
+		return ""
+	}
+	if hash != info.Hash {
+		return "it changed since it was generated"
+	}
+	return ""
+}
+
+// UninstallAgent removes a single installed agent file and stops tracking it,
+// leaving the rest of its source installed. It returns the owning source.
+// A pre-existing file is only untracked. The next update of the source
+// installs the agent again.
+func (i *Installer) UninstallAgent(path string) (string, error) {
+	if i.options.DryRun {
+		sourceName, _, err := i.tracker.FindFile(path)
+		if err != nil {
+			return "", err
+		}
+		if sourceName == "" {
+			return "", fmt.Errorf("agent file is not tracked by any installed source: %s", path)
+		}
+		color.Yellow("[DRY RUN] Would uninstall %s from source %s\n", path, sourceName)
+		return sourceName, nil
+	}
+
+	sourceName, info, err := i.tracker.RemoveFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to update tracking: %w", err)
+	}
+	if sourceName == "" {
+		return "", fmt.Errorf("agent file is not tracked by any installed source: %s", path)
+	}
+
+	if info.WasPreExisting {
+		if i.options.Verbose {
+			fmt.Printf("Kept pre-existing file: %s\n", path)
+		}
+		return sourceName, nil
+	}
+	if i.keepReadonly(sourceName, path) {
+		return sourceName, nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return sourceName, fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	if i.options.Verbose {
+		fmt.Printf("Removed: %s\n", path)
+	}
+	return sourceName, nil
+}
+
+// uninstallCategory removes the files of a single marketplace category from a source
+func (i *Installer) uninstallCategory(sourceName, category string) error {
+	if i.options.DryRun {
+		color.Yellow("[DRY RUN] Would uninstall category: %s\n", tracker.SubSourceName(sourceName, category))
+		return nil
+	}
+
+	if err := i.removeCategory(sourceName, category); err != nil {
+		return err
+	}
+
+	color.Green("%s Uninstalled category: %s\n", util.Symbol("✓"), tracker.SubSourceName(sourceName, category))
+	return nil
+}
+
+// removeCategory deletes a category's installed files and its tracking record
+func (i *Installer) removeCategory(sourceName, category string) error {
+	installation, err := i.tracker.GetInstallation(sourceName)
+	if err != nil {
+		return fmt.Errorf("source not found: %s", sourceName)
+	}
+	entry, exists := installation.Categories[category]
+	if !exists {
+		return fmt.Errorf("category not found: %s", tracker.SubSourceName(sourceName, category))
+	}
+
+	dirs := make(map[string]bool)
+	for _, path := range entry.Files {
+		// Skip removing pre-existing files - they should remain after uninstall
+		if installation.Files[path].WasPreExisting {
+			if i.options.Verbose {
+				fmt.Printf("Kept pre-existing file: %s\n", path)
+			}
+			continue
+		}
+		if i.keepReadonly(sourceName, path) {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			color.Red("Failed to remove %s: %v\n", path, err)
+		} else if i.options.Verbose {
+			fmt.Printf("Removed: %s\n", path)
+		}
+		dirs[filepath.Dir(path)] = true
+	}
+
+	if _, err := i.tracker.RemoveCategory(sourceName, category); err != nil {
+		return fmt.Errorf("failed to update tracking: %w", err)
+	}
+
+	// Remove directories left empty, keeping those still tracked by the source
+	for dir := range dirs {
+		if isEmpty, err := isDirEmpty(dir); err == nil && isEmpty && !contains(installation.Directories, dir) {
+			if err := os.Remove(dir); err != nil && i.options.Verbose {
+				color.Yellow("Warning: failed to remove empty directory %s: %v", dir, err)
+			}
+		}
+	}
+
 	return nil
 }
 
 // UninstallAll removes all installed agents
-func (i *Installer) UninstallAll() error {
+func (i *Installer) UninstallAll(ctx context.Context) error {
 	installations, err := i.tracker.List()
 	if err != nil {
 		return fmt.Errorf("failed to list installations: %w", err)
 	}
 
 	for name := range installations {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("uninstall interrupted: %w", err)
+		}
 		if err := i.UninstallSource(name); err != nil {
 			color.Red("Failed to uninstall %s: %v\n", name, err)
 			if !i.config.Settings.ContinueOnError {
@@ -421,7 +1151,11 @@ func (i *Installer) UninstallAll() error {
 }
 
 // UpdateSource updates agents from a specific source
-func (i *Installer) UpdateSource(sourceName string) error {
+func (i *Installer) UpdateSource(ctx context.Context, sourceName string) error {
+	if i.planOnly(i.findSource(sourceName)) {
+		return i.plan(sourceName, func(p *Installer) error { return p.UpdateSource(ctx, sourceName) })
+	}
+
 	// Find source in config
 	var source *config.Source
 	for _, s := range i.config.Sources {
@@ -432,6 +1166,13 @@ func (i *Installer) UpdateSource(sourceName string) error {
 	}
 
 	if source == nil {
+		if parent, category := tracker.SplitSubSource(sourceName); category != "" {
+			for _, s := range i.config.Sources {
+				if s.Name == parent {
+					return i.updateCategory(ctx, s, category)
+				}
+			}
+		}
 		return fmt.Errorf("source not found in configuration: %s", sourceName)
 	}
 
@@ -439,66 +1180,257 @@ func (i *Installer) UpdateSource(sourceName string) error {
 	installation, err := i.tracker.GetInstallation(sourceName)
 	if err != nil {
 		// Not installed, do fresh install
-		return i.InstallSource(*source)
+		return i.InstallSource(ctx, *source)
 	}
 
 	// Get handler to check for updates
-	handler, err := i.getSourceHandler(source.Type)
+	handler, err := i.getSourceHandler(*source)
 	if err != nil {
 		return err
 	}
 
 	// Check if update is available
-	hasUpdate, newCommit, err := handler.CheckUpdate(*source, installation.SourceCommit)
+	hasUpdate, newCommit, err := handler.CheckUpdate(ctx, *source, installation.SourceCommit)
 	if err != nil {
 		return fmt.Errorf("failed to check for updates: %w", err)
 	}
+	checked := time.Now()
+
+	i.recordUpdateCheck(sourceName, hasUpdate)
 
 	if !hasUpdate {
-		color.Green("✓ %s is up to date\n", sourceName)
+		i.markChecked(sourceName, checked)
+		color.Green("%s %s is up to date\n", util.Symbol("✓"), sourceName)
 		return nil
 	}
 
 	if i.options.DryRun {
 		color.Yellow("[DRY RUN] Would update %s from %s to %s\n",
-			sourceName, installation.SourceCommit[:7], newCommit[:7])
+			sourceName, util.ShortCommit(installation.SourceCommit), util.ShortCommit(newCommit))
 		return nil
 	}
 
 	// Perform update by reinstalling
 	color.Blue("Updating %s...\n", sourceName)
 
-	// Backup current installation
-	if err := i.resolver.CreateBackup(sourceName); err != nil {
-		return fmt.Errorf("failed to create backup: %w", err)
+	before := i.agentVersions(installedPaths(installation.Files))
+	if i.changelogFrom == nil {
+		i.changelogFrom = make(map[string]string)
+	}
+	i.changelogFrom[sourceName] = installation.SourceCommit
+
+	if err := i.reinstall(ctx, *source); err != nil {
+		return err
+	}
+	i.markChecked(sourceName, checked)
+	i.recordUpdateCheck(sourceName, false)
+
+	color.Green("%s Updated %s to %s\n", util.Symbol("✓"), sourceName, util.ShortCommit(newCommit))
+	if changelog := i.changelogs[sourceName]; changelog != nil {
+		changelog.Print()
+		if err := appendChangelog(i.config.Metadata.LogFile, changelog); err != nil {
+			color.Yellow("Warning: failed to record changelog in audit log: %v\n", err)
+		}
+	}
+	if updated, err := i.tracker.GetInstallation(sourceName); err == nil {
+		i.reportVersionChanges(sourceName, versionChanges(before, i.agentVersions(installedPaths(updated.Files))))
+	}
+	return nil
+}
+
+// markChecked records when an installed source was last checked for updates;
+// failing to record it only costs the list command its "checked" column
+func (i *Installer) markChecked(sourceName string, at time.Time) {
+	if i.options.DryRun {
+		return
+	}
+	if err := i.tracker.MarkChecked(sourceName, at); err != nil && i.options.Verbose {
+		fmt.Printf("Warning: failed to record update check for %s: %v\n", sourceName, err)
+	}
+}
+
+// recordUpdateCheck records whether a checked source has an update available
+func (i *Installer) recordUpdateCheck(sourceName string, available bool) {
+	if i.updateChecks == nil {
+		i.updateChecks = make(map[string]bool)
+	}
+	i.updateChecks[sourceName] = available
+}
+
+// updateCategory updates a single marketplace category of an installed source
+func (i *Installer) updateCategory(ctx context.Context, source config.Source, category string) error {
+	name := tracker.SubSourceName(source.Name, category)
+
+	installation, err := i.tracker.GetInstallation(source.Name)
+	if err != nil {
+		return fmt.Errorf("source %s must be installed before updating %s", source.Name, name)
+	}
+
+	currentCommit := ""
+	entry, installed := installation.Categories[category]
+	if installed {
+		currentCommit = entry.SourceCommit
+	}
+
+	handler, err := i.getSourceHandler(source)
+	if err != nil {
+		return err
+	}
+
+	categorySource := source
+	categorySource.Category = category
+
+	hasUpdate, newCommit, err := handler.CheckUpdate(ctx, categorySource, currentCommit)
+	if err != nil {
+		return fmt.Errorf("failed to check for updates: %w", err)
+	}
+
+	i.recordUpdateCheck(name, hasUpdate)
+
+	if !hasUpdate {
+		color.Green("%s %s is up to date\n", util.Symbol("✓"), name)
+		return nil
+	}
+
+	if i.options.DryRun {
+		color.Yellow("[DRY RUN] Would update %s to %s\n", name, newCommit)
+		return nil
+	}
+
+	color.Blue("Updating %s...\n", name)
+
+	var before map[string]string
+	if installed {
+		before = i.agentVersions(entry.Files)
 	}
 
-	// Uninstall current version
-	if err := i.UninstallSource(sourceName); err != nil {
-		return fmt.Errorf("failed to uninstall old version: %w", err)
+	// Remove the current category files before reinstalling, keeping a copy
+	// to put back when the update fails
+	var backup *categoryBackup
+	if installed {
+		backup, err = backupCategory(installation, entry)
+		if err != nil {
+			return fmt.Errorf("failed to create backup: %w", err)
+		}
+		if err := i.removeCategory(source.Name, category); err != nil {
+			return fmt.Errorf("failed to uninstall old version: %w", err)
+		}
 	}
 
-	// Install new version
-	if err := i.InstallSource(*source); err != nil {
-		// Restore backup on failure
-		if restoreErr := i.resolver.RestoreBackup(sourceName); restoreErr != nil {
-			color.Yellow("Warning: failed to restore backup after installation failure: %v", restoreErr)
+	metrics := SourceMetrics{Source: name}
+	categoryInstallation, err := i.install(ctx, categorySource, &metrics)
+	if err == nil && categoryInstallation == nil {
+		err = fmt.Errorf("no files of %s match any more", name)
+	}
+	if err != nil {
+		if backup != nil {
+			if restoreErr := i.restoreCategory(source.Name, category, backup); restoreErr != nil {
+				color.Yellow("Warning: failed to restore backup after installation failure: %v\n", restoreErr)
+			}
 		}
 		return fmt.Errorf("failed to install update: %w", err)
 	}
+	i.metrics = append(i.metrics, metrics)
 
-	color.Green("✓ Updated %s to %s\n", sourceName, newCommit[:7])
+	if err := i.tracker.RecordCategory(source.Name, category, *categoryInstallation); err != nil {
+		return fmt.Errorf("failed to record installation: %w", err)
+	}
+	i.recordUpdateCheck(name, false)
+
+	color.Green("%s Updated %s to %s\n", util.Symbol("✓"), name, categoryInstallation.SourceCommit)
+	i.reportVersionChanges(name, versionChanges(before, i.agentVersions(installedPaths(categoryInstallation.Files))))
 	return nil
 }
 
+// categoryBackup holds the files and tracking of an installed category
+// while it is being updated
+type categoryBackup struct {
+	commit   string
+	files    map[string]tracker.FileInfo
+	contents map[string][]byte
+	modes    map[string]os.FileMode
+	metadata []tracker.AgentInfo
+}
+
+// backupCategory copies the files and tracking of a category of installation
+func backupCategory(installation *tracker.Installation, entry *tracker.CategoryInstallation) (*categoryBackup, error) {
+	backup := &categoryBackup{
+		commit:   entry.SourceCommit,
+		files:    make(map[string]tracker.FileInfo, len(entry.Files)),
+		contents: make(map[string][]byte, len(entry.Files)),
+		modes:    make(map[string]os.FileMode, len(entry.Files)),
+	}
+	fileNames := make(map[string]bool, len(entry.Files))
+	for _, path := range entry.Files {
+		backup.files[path] = installation.Files[path]
+		fileNames[filepath.Base(path)] = true
+
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		backup.contents[path] = content
+		backup.modes[path] = info.Mode().Perm()
+	}
+	for _, agent := range installation.AgentMetadata {
+		if fileNames[agent.FileName] {
+			backup.metadata = append(backup.metadata, agent)
+		}
+	}
+	return backup, nil
+}
+
+// restoreCategory puts back the files and tracking of a category removed
+// for an update that failed
+func (i *Installer) restoreCategory(sourceName, category string, backup *categoryBackup) error {
+	for path, content := range backup.contents {
+		dir := filepath.Dir(path)
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+		if err := os.WriteFile(path, content, backup.modes[path]); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", path, err)
+		}
+	}
+
+	return i.tracker.RecordCategory(sourceName, category, tracker.Installation{
+		SourceCommit:  backup.commit,
+		Files:         backup.files,
+		AgentMetadata: backup.metadata,
+	})
+}
+
 // Helper methods
 
-func (i *Installer) getSourceHandler(sourceType string) (SourceHandler, error) {
+// getSourceHandler returns the handler for a source, falling back to its mirrors when it has any
+func (i *Installer) getSourceHandler(source config.Source) (SourceHandler, error) {
+	handler, err := i.typeHandler(source.Type)
+	if err != nil || len(source.Mirrors) == 0 {
+		return handler, err
+	}
+	return newMirrorHandler(handler), nil
+}
+
+// typeHandler returns the handler for a source type
+func (i *Installer) typeHandler(sourceType string) (SourceHandler, error) {
 	switch sourceType {
 	case "github":
 		return &GitHubHandler{}, nil
 	case "git":
 		return &GitHandler{}, nil
+	case "gitlab":
+		return &GitLabHandler{}, nil
+	case "github-release":
+		return &GitHubReleaseHandler{}, nil
+	case "archive":
+		return &ArchiveHandler{}, nil
 	case "local":
 		return &LocalHandler{}, nil
 	case "subagents":
@@ -513,7 +1445,7 @@ func (i *Installer) resolveTargetPath(path string) string {
 	path = os.ExpandEnv(path)
 
 	// Expand home directory
-	if strings.HasPrefix(path, "~/") {
+	if util.IsHomePath(path) {
 		expandedPath, err := util.ExpandPath(path)
 		if err != nil {
 			// Log error but continue with original path as fallback
@@ -666,6 +1598,7 @@ func (i *Installer) extractAgentMetadata(sourceName string, files []string, base
 			FileName:       agentSpec.FileName,
 			FileSize:       agentSpec.FileSize,
 			ModTime:        agentSpec.ModTime,
+			Namespace:      parser.NamespaceOf(relPath),
 			Source:         sourceName,
 			InstalledAt:    time.Now(),
 		}
@@ -691,7 +1624,7 @@ func (i *Installer) preFilterMarkdownFiles(files []string) []string {
 	mdFiles := make([]string, 0, estimatedMdFiles)
 
 	for _, file := range files {
-		if strings.HasSuffix(file, ".md") {
+		if parser.IsAgentFile(file, i.config.Settings.Query.Index.Extensions) {
 			mdFiles = append(mdFiles, file)
 		}
 	}
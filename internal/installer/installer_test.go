@@ -0,0 +1,480 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/conflict"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+func TestInstallStatuslineSource(t *testing.T) {
+	dir := t.TempDir()
+	sourceDir := filepath.Join(dir, "src")
+	targetDir := filepath.Join(dir, "statusline")
+	if err := os.MkdirAll(sourceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"statusline.sh": "#!/bin/sh\necho ok\n",
+		"broken.sh":     "echo missing interpreter\n",
+		"README.md":     "# Statusline\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{
+		Settings: config.Settings{BaseDir: filepath.Join(dir, "agents"), ConflictStrategy: "overwrite", BackupDir: filepath.Join(dir, "backups")},
+		Metadata: config.Metadata{TrackingFile: filepath.Join(dir, ".installed.json")},
+	}
+	source := config.Source{
+		Name:    "statusline",
+		Type:    "local",
+		Kind:    config.KindStatusline,
+		Enabled: true,
+		Paths:   config.PathConfig{Source: sourceDir, Target: targetDir},
+	}
+	track := tracker.New(cfg.Metadata.TrackingFile)
+	inst := New(cfg, track, conflict.NewResolver("overwrite", cfg.Settings.BackupDir), Options{})
+
+	if err := inst.InstallSource(context.Background(), source); err != nil {
+		t.Fatalf("InstallSource() error = %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(targetDir, "statusline.sh"))
+	if err != nil {
+		t.Fatalf("Expected statusline script to be installed: %v", err)
+	}
+	if info.Mode()&0111 == 0 {
+		t.Errorf("Expected statusline script to be executable, got mode %v", info.Mode())
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "broken.sh")); !os.IsNotExist(err) {
+		t.Error("Expected invalid statusline script to be skipped")
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "README.md")); err != nil {
+		t.Errorf("Expected non-script files to be installed: %v", err)
+	}
+
+	installation, err := track.GetInstallation("statusline")
+	if err != nil {
+		t.Fatalf("Expected installation to be tracked: %v", err)
+	}
+	if len(installation.Files) != 2 || len(installation.AgentMetadata) != 0 {
+		t.Errorf("Expected 2 tracked files and no agent metadata, got %d files and %d agents",
+			len(installation.Files), len(installation.AgentMetadata))
+	}
+}
+
+func TestUninstallAgent(t *testing.T) {
+	dir := t.TempDir()
+	kept := filepath.Join(dir, "kept.md")
+	removed := filepath.Join(dir, "reviewer.md")
+	for _, path := range []string{kept, removed} {
+		if err := os.WriteFile(path, []byte("---\nname: x\n---\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	track := tracker.New(filepath.Join(dir, ".installed.json"))
+	if err := track.RecordInstallation("team", tracker.Installation{Files: map[string]tracker.FileInfo{
+		kept:    {Path: kept, WasPreExisting: true},
+		removed: {Path: removed},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	inst := New(&config.Config{}, track, nil, Options{})
+
+	sourceName, err := inst.UninstallAgent(removed)
+	if err != nil || sourceName != "team" {
+		t.Fatalf("UninstallAgent() = %q, %v", sourceName, err)
+	}
+	if _, err := os.Stat(removed); !os.IsNotExist(err) {
+		t.Error("Expected the agent file to be removed")
+	}
+
+	if _, err := inst.UninstallAgent(kept); err != nil {
+		t.Fatalf("UninstallAgent() error = %v", err)
+	}
+	if _, err := os.Stat(kept); err != nil {
+		t.Error("Expected a pre-existing file to be kept")
+	}
+	if files, _ := track.GetInstalledFiles("team"); len(files) != 0 {
+		t.Errorf("Expected no tracked files, got %v", files)
+	}
+
+	if _, err := inst.UninstallAgent(removed); err == nil {
+		t.Error("Expected an error for an untracked file")
+	}
+}
+
+func TestRestoreCategory(t *testing.T) {
+	dir := t.TempDir()
+	agent := filepath.Join(dir, "testing", "reviewer.md")
+	if err := os.MkdirAll(filepath.Dir(agent), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(agent, []byte("---\nname: reviewer\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	track := tracker.New(filepath.Join(dir, ".installed.json"))
+	if err := track.RecordInstallation("market", tracker.Installation{Files: map[string]tracker.FileInfo{}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.RecordCategory("market", "Testing", tracker.Installation{
+		SourceCommit:  "abc123",
+		Files:         map[string]tracker.FileInfo{agent: {Path: agent}},
+		AgentMetadata: []tracker.AgentInfo{{Name: "reviewer", FileName: "reviewer.md"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	inst := New(&config.Config{}, track, nil, Options{})
+
+	installation, _ := track.GetInstallation("market")
+	backup, err := backupCategory(installation, installation.Categories["Testing"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inst.removeCategory("market", "Testing"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(agent); !os.IsNotExist(err) {
+		t.Fatal("Expected the category files to be removed")
+	}
+
+	if err := inst.restoreCategory("market", "Testing", backup); err != nil {
+		t.Fatalf("restoreCategory() error = %v", err)
+	}
+	if content, err := os.ReadFile(agent); err != nil || string(content) != "---\nname: reviewer\n---\n" {
+		t.Errorf("Expected the agent file to be restored, got %q (%v)", content, err)
+	}
+	installation, _ = track.GetInstallation("market")
+	entry := installation.Categories["Testing"]
+	if entry == nil || entry.SourceCommit != "abc123" || len(entry.Files) != 1 || len(installation.AgentMetadata) != 1 {
+		t.Errorf("Expected the category tracking to be restored, got %+v", installation)
+	}
+}
+
+func TestGeneratedDocOwnership(t *testing.T) {
+	dir := t.TempDir()
+	docsDir := filepath.Join(dir, "docs")
+	doc := filepath.Join(docsDir, "PLANNING.md")
+
+	cfg := &config.Config{
+		Settings: config.Settings{BaseDir: filepath.Join(dir, "agents"), ConflictStrategy: "overwrite", BackupDir: filepath.Join(dir, "backups"), DocsDir: docsDir},
+		Metadata: config.Metadata{TrackingFile: filepath.Join(dir, ".installed.json")},
+	}
+	source := func(name string) config.Source {
+		sourceDir := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Join(sourceDir, "planning"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		for file, content := range map[string]string{
+			"planning/README.md":  "# Planning from " + name + "\n",
+			"planning/planner.md": "---\nname: " + name + "-planner\n---\n",
+		} {
+			if err := os.WriteFile(filepath.Join(sourceDir, file), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return config.Source{
+			Name:            name,
+			Type:            "local",
+			Enabled:         true,
+			Paths:           config.PathConfig{Source: sourceDir, Target: filepath.Join(dir, "agents", name)},
+			Transformations: []config.Transformation{{Type: "extract_docs"}},
+		}
+	}
+	track := tracker.New(cfg.Metadata.TrackingFile)
+	inst := New(cfg, track, conflict.NewResolver("overwrite", cfg.Settings.BackupDir), Options{})
+
+	for _, name := range []string{"first", "second"} {
+		if err := inst.InstallSource(context.Background(), source(name)); err != nil {
+			t.Fatalf("InstallSource(%s) error = %v", name, err)
+		}
+	}
+
+	// The second source leaves the first source's doc alone and reports it
+	if content, _ := os.ReadFile(doc); string(content) != "# Planning from first\n" {
+		t.Errorf("Expected the first source's doc kept, got %q", content)
+	}
+	installation, err := track.GetInstallation("first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := installation.Docs[doc]; info.Hash == "" || info.Size == 0 {
+		t.Errorf("Expected the doc tracked with its checksum, got %+v", installation.Docs)
+	}
+	conflicts := inst.Conflicts()
+	if len(conflicts) != 1 || conflicts[0].Path != doc || conflicts[0].Source != "second" || conflicts[0].Action != conflict.ActionSkipped {
+		t.Errorf("Expected the doc conflict reported, got %+v", conflicts)
+	}
+
+	if err := inst.UninstallSource("second"); err != nil {
+		t.Fatalf("UninstallSource(second) error = %v", err)
+	}
+	if _, err := os.Stat(doc); err != nil {
+		t.Error("Expected uninstalling the second source to keep the first source's doc")
+	}
+
+	// Docs changed since they were generated are kept
+	if err := os.WriteFile(doc, []byte("# Edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := inst.UninstallSource("first"); err != nil {
+		t.Fatalf("UninstallSource(first) error = %v", err)
+	}
+	if _, err := os.Stat(doc); err != nil {
+		t.Error("Expected an edited doc to be kept")
+	}
+}
+
+func TestUninstallRemovesOwnDocs(t *testing.T) {
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "docs", "planning.md")
+	legacy := filepath.Join(dir, "docs", "legacy.md")
+	if err := os.MkdirAll(filepath.Dir(doc), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{doc, legacy} {
+		if err := os.WriteFile(path, []byte("# Planning\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash, err := util.FileSHA256(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	track := tracker.New(filepath.Join(dir, ".installed.json"))
+	if err := track.RecordInstallation("team", tracker.Installation{
+		Files:         map[string]tracker.FileInfo{},
+		Docs:          map[string]tracker.DocInfo{doc: {Path: doc, Hash: hash, Size: 11}},
+		DocsGenerated: []string{legacy},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	inst := New(&config.Config{}, track, nil, Options{KeepBackups: true})
+
+	if err := inst.UninstallSource("team"); err != nil {
+		t.Fatalf("UninstallSource() error = %v", err)
+	}
+	for _, path := range []string{doc, legacy} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", path)
+		}
+	}
+}
+
+func TestProtectPreExisting(t *testing.T) {
+	dir := t.TempDir()
+	sourceDir := filepath.Join(dir, "src")
+	targetDir := filepath.Join(dir, "agents")
+	for _, d := range []string{sourceDir, targetDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"mine.md", "theirs.md"} {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), []byte("from source\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	authored := filepath.Join(targetDir, "mine.md")
+	if err := os.WriteFile(authored, []byte("authored by hand\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Settings: config.Settings{BaseDir: targetDir, ConflictStrategy: "overwrite", BackupDir: filepath.Join(dir, "backups"), ProtectPreExisting: true},
+		Metadata: config.Metadata{TrackingFile: filepath.Join(dir, ".installed.json")},
+	}
+	source := config.Source{
+		Name:    "local",
+		Type:    "local",
+		Enabled: true,
+		Paths:   config.PathConfig{Source: sourceDir, Target: targetDir},
+	}
+	track := tracker.New(cfg.Metadata.TrackingFile)
+
+	// The authored file is kept and reported; the other one is installed
+	inst := New(cfg, track, conflict.NewResolver("overwrite", cfg.Settings.BackupDir), Options{})
+	if err := inst.InstallSource(context.Background(), source); err != nil {
+		t.Fatalf("InstallSource() error = %v", err)
+	}
+	if content, _ := os.ReadFile(authored); string(content) != "authored by hand\n" {
+		t.Errorf("Expected the authored file to be kept, got %q", content)
+	}
+	outcomes := inst.Conflicts()
+	if len(outcomes) != 1 || outcomes[0].Path != authored || outcomes[0].Action != conflict.ActionProtected || !outcomes[0].PreExisting {
+		t.Errorf("Expected one protected pre-existing outcome for %s, got %+v", authored, outcomes)
+	}
+	installation, err := track.GetInstallation("local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := installation.Files[authored]; ok || len(installation.Files) != 1 {
+		t.Errorf("Expected only the installed file to be tracked, got %v", installation.Files)
+	}
+
+	// Installing again over the source's own file does not make it pre-existing
+	inst = New(cfg, track, conflict.NewResolver("overwrite", cfg.Settings.BackupDir), Options{})
+	if err := inst.InstallSource(context.Background(), source); err != nil {
+		t.Fatalf("InstallSource() error = %v", err)
+	}
+	installation, err = track.GetInstallation("local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	own := filepath.Join(targetDir, "theirs.md")
+	if file, ok := installation.Files[own]; !ok || file.WasPreExisting {
+		t.Errorf("Expected %s to stay tracked as installed by the source, got %+v", own, file)
+	}
+	for _, outcome := range inst.Conflicts() {
+		if outcome.Path == own && outcome.PreExisting {
+			t.Errorf("Expected the source's own file not to be reported as pre-existing")
+		}
+	}
+}
+
+func TestReadonlyPaths(t *testing.T) {
+	dir := t.TempDir()
+	sourceDir := filepath.Join(dir, "src")
+	targetDir := filepath.Join(dir, "agents")
+	for _, d := range []string{sourceDir, targetDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"CLAUDE.md", "settings.json", "agent.md"} {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), []byte("from source\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	userFile := filepath.Join(targetDir, "CLAUDE.md")
+	if err := os.WriteFile(userFile, []byte("user instructions\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Settings: config.Settings{BaseDir: targetDir, ConflictStrategy: "overwrite", BackupDir: filepath.Join(dir, "backups"), ReadonlyPaths: []string{"CLAUDE.md"}},
+		Metadata: config.Metadata{TrackingFile: filepath.Join(dir, ".installed.json")},
+	}
+	cfg.Sources = []config.Source{{
+		Name:    "local",
+		Type:    "local",
+		Enabled: true,
+		Paths:   config.PathConfig{Source: sourceDir, Target: targetDir},
+	}}
+	track := tracker.New(cfg.Metadata.TrackingFile)
+
+	// The readonly file is neither overwritten nor tracked, whatever the strategy
+	inst := New(cfg, track, conflict.NewResolver("overwrite", cfg.Settings.BackupDir), Options{})
+	if err := inst.InstallSource(context.Background(), cfg.Sources[0]); err != nil {
+		t.Fatalf("InstallSource() error = %v", err)
+	}
+	if content, _ := os.ReadFile(userFile); string(content) != "user instructions\n" {
+		t.Errorf("Expected the readonly file to be kept, got %q", content)
+	}
+	outcomes := inst.Conflicts()
+	if len(outcomes) != 1 || outcomes[0].Path != userFile || outcomes[0].Action != conflict.ActionReadonly {
+		t.Errorf("Expected one readonly outcome for %s, got %+v", userFile, outcomes)
+	}
+	installation, err := track.GetInstallation("local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := installation.Files[userFile]; ok || len(installation.Files) != 2 {
+		t.Errorf("Expected only the written files to be tracked, got %v", installation.Files)
+	}
+
+	// A file the source installed before its pattern was added is not removed
+	settings := filepath.Join(targetDir, "settings.json")
+	cfg.Sources[0].ReadonlyPaths = []string{"agents/settings.json"}
+	inst = New(cfg, track, conflict.NewResolver("overwrite", cfg.Settings.BackupDir), Options{})
+	if err := inst.UninstallSource("local"); err != nil {
+		t.Fatalf("UninstallSource() error = %v", err)
+	}
+	if _, err := os.Stat(settings); err != nil {
+		t.Errorf("Expected %s to be kept, got %v", settings, err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "agent.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected agent.md to be removed, got %v", err)
+	}
+}
+
+func TestInstallConcurrentThrottledCopies(t *testing.T) {
+	dir := t.TempDir()
+	sourceDir := filepath.Join(dir, "src")
+	targetDir := filepath.Join(dir, "agents")
+	if err := os.MkdirAll(filepath.Join(sourceDir, "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	const count = 12
+	for n := 0; n < count; n++ {
+		name := fmt.Sprintf("agent-%02d.md", n)
+		if n%2 == 0 {
+			name = filepath.Join("nested", name)
+		}
+		content := fmt.Sprintf("---\nname: agent-%02d\ndescription: test agent\n---\nbody\n", n)
+		if err := os.WriteFile(filepath.Join(sourceDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{
+		Settings: config.Settings{
+			BaseDir:          targetDir,
+			ConflictStrategy: "overwrite",
+			BackupDir:        filepath.Join(dir, "backups"),
+			IO:               config.IOConfig{Concurrency: 1, FilesPerSecond: 1},
+		},
+		Metadata: config.Metadata{TrackingFile: filepath.Join(dir, ".installed.json")},
+	}
+	// The source's own settings win over the global ones
+	source := config.Source{
+		Name:    "local",
+		Type:    "local",
+		Enabled: true,
+		Paths:   config.PathConfig{Source: sourceDir, Target: targetDir},
+		IO:      config.IOConfig{Concurrency: 4, FilesPerSecond: 200},
+	}
+	track := tracker.New(cfg.Metadata.TrackingFile)
+	inst := New(cfg, track, conflict.NewResolver("overwrite", cfg.Settings.BackupDir), Options{})
+
+	start := time.Now()
+	if err := inst.InstallSource(context.Background(), source); err != nil {
+		t.Fatalf("InstallSource() error = %v", err)
+	}
+	elapsed := time.Since(start)
+	if elapsed < 40*time.Millisecond {
+		t.Errorf("Expected %d copies at 200 files/sec to take at least 40ms, took %v", count, elapsed)
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("Expected the source io settings to replace the global rate, took %v", elapsed)
+	}
+
+	installation, err := track.GetInstallation("local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(installation.Files) != count || len(installation.AgentMetadata) != count {
+		t.Errorf("Expected %d tracked files and agents, got %d files and %d agents",
+			count, len(installation.Files), len(installation.AgentMetadata))
+	}
+	for path, file := range installation.Files {
+		hash, err := util.FileSHA256(path)
+		if err != nil || hash != file.Hash {
+			t.Errorf("Expected %s to be copied intact, got hash %s (%v), tracked %s", path, hash, err, file.Hash)
+		}
+	}
+}
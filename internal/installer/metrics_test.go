@@ -0,0 +1,49 @@
+package installer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSourceMetrics(t *testing.T) {
+	metrics := SourceMetrics{
+		Source:            "team",
+		Fetch:             1500 * time.Millisecond,
+		Copy:              500 * time.Millisecond,
+		FilesCopied:       10,
+		BytesCopied:       2048,
+		ConflictsResolved: 2,
+	}
+	metrics.finish(time.Now().Add(-3 * time.Second))
+
+	if metrics.FilesPerSecond != 20 {
+		t.Errorf("FilesPerSecond = %v, want 20", metrics.FilesPerSecond)
+	}
+	if metrics.Total < 3*time.Second {
+		t.Errorf("Total = %v, want at least 3s", metrics.Total)
+	}
+
+	content, err := json.Marshal(metrics)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]float64{"fetch_ms": 1500, "copy_ms": 500, "files_copied": 10, "conflicts_resolved": 2} {
+		if decoded[key] != want {
+			t.Errorf("%s = %v, want %v", key, decoded[key], want)
+		}
+	}
+
+	var out strings.Builder
+	metrics.Print(&out)
+	for _, want := range []string{"Metrics for team", "Fetch: 1.5s", "Copy: 10 files, 2048 bytes in 500ms (20.0 files/sec)", "Conflicts resolved: 2"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Output missing %q:\n%s", want, out.String())
+		}
+	}
+}
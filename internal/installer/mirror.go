@@ -0,0 +1,148 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+)
+
+// MirroredHandler is implemented by handlers that can report which mirror a
+// source was fetched from after a Fetch
+type MirroredHandler interface {
+	FetchedMirror() string
+}
+
+// mirrorHandler fetches a source from its primary location and falls back to
+// its mirrors in order, each attempt limited by the source's mirror timeout
+type mirrorHandler struct {
+	primary SourceHandler
+	mirror  SourceHandler // fetches a mirror URL as a git source
+	fetched string
+}
+
+// newMirrorHandler wraps primary with fallback to the source's mirrors
+func newMirrorHandler(primary SourceHandler) *mirrorHandler {
+	return &mirrorHandler{primary: primary, mirror: &GitHandler{}}
+}
+
+// FetchedMirror returns the mirror URL used by the last Fetch, or an empty
+// string when the primary location succeeded
+func (m *mirrorHandler) FetchedMirror() string {
+	return m.fetched
+}
+
+// Fetch tries the primary location and then each mirror. A source fetched
+// from a mirror must be at the same commit as every other location that can
+// be reached, so a stale or tampered mirror is never installed silently.
+func (m *mirrorHandler) Fetch(ctx context.Context, source config.Source, destDir string) (string, string, error) {
+	m.fetched = ""
+	var failures []string
+
+	path, commit, err := m.attempt(ctx, source, nil, filepath.Join(destDir, "primary"), m.primary.Fetch)
+	if err == nil {
+		return path, commit, nil
+	}
+	failures = append(failures, fmt.Sprintf("primary: %v", err))
+
+	for n := range source.Mirrors {
+		mirror := &source.Mirrors[n]
+		if ctx.Err() != nil {
+			break
+		}
+		color.Yellow("Warning: fetching %s failed; trying mirror %s\n", source.Name, mirror.URL)
+
+		attemptDir := filepath.Join(destDir, fmt.Sprintf("mirror-%d", n+1))
+		path, commit, err := m.attempt(ctx, mirrorSource(source, mirror), mirror, attemptDir, m.mirror.Fetch)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("mirror %s: %v", mirror.URL, err))
+			continue
+		}
+		if err := m.verify(ctx, source, mirror, commit); err != nil {
+			return "", "", err
+		}
+		m.fetched = mirror.URL
+		return path, commit, nil
+	}
+
+	if ctx.Err() != nil {
+		return "", "", fmt.Errorf("fetch of %s aborted: %w", source.Name, ctx.Err())
+	}
+	return "", "", fmt.Errorf("all %d locations failed: %s", len(failures), strings.Join(failures, "; "))
+}
+
+// attempt runs one fetch into its own directory under the attempt timeout
+func (m *mirrorHandler) attempt(ctx context.Context, source config.Source, mirror *config.Mirror, dir string,
+	fetch func(context.Context, config.Source, string) (string, string, error)) (string, string, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, source.AttemptTimeout(mirror))
+	defer cancel()
+	return fetch(attemptCtx, source, dir)
+}
+
+// verify compares commit, fetched from mirror, with the head of the primary
+// location and the other mirrors; locations that cannot be reached are skipped
+func (m *mirrorHandler) verify(ctx context.Context, source config.Source, used *config.Mirror, commit string) error {
+	check := func(name string, location config.Source, mirror *config.Mirror, handler SourceHandler) error {
+		checkCtx, cancel := context.WithTimeout(ctx, source.AttemptTimeout(mirror))
+		defer cancel()
+		_, head, err := handler.CheckUpdate(checkCtx, location, commit)
+		if err != nil || head == "" || head == commit {
+			return nil
+		}
+		return fmt.Errorf("mirror %s is at commit %s but %s is at %s; refusing to install inconsistent content", used.URL, commit, name, head)
+	}
+
+	if err := check("the primary location", source, nil, m.primary); err != nil {
+		return err
+	}
+	for n := range source.Mirrors {
+		mirror := &source.Mirrors[n]
+		if mirror.URL == used.URL {
+			continue
+		}
+		if err := check("mirror "+mirror.URL, mirrorSource(source, mirror), mirror, m.mirror); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckUpdate checks the primary location for updates, falling back to the mirrors
+func (m *mirrorHandler) CheckUpdate(ctx context.Context, source config.Source, currentCommit string) (bool, string, error) {
+	checkCtx, cancel := context.WithTimeout(ctx, source.AttemptTimeout(nil))
+	hasUpdate, commit, err := m.primary.CheckUpdate(checkCtx, source, currentCommit)
+	cancel()
+	if err == nil {
+		return hasUpdate, commit, nil
+	}
+
+	for n := range source.Mirrors {
+		mirror := &source.Mirrors[n]
+		if ctx.Err() != nil {
+			break
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, source.AttemptTimeout(mirror))
+		hasUpdate, commit, mirrorErr := m.mirror.CheckUpdate(checkCtx, mirrorSource(source, mirror), currentCommit)
+		cancel()
+		if mirrorErr == nil {
+			return hasUpdate, commit, nil
+		}
+	}
+	return false, "", err
+}
+
+// mirrorSource returns source as a git source fetched from mirror
+func mirrorSource(source config.Source, mirror *config.Mirror) config.Source {
+	mirrored := source
+	mirrored.Type = "git"
+	mirrored.URL = mirror.URL
+	mirrored.Mirrors = nil
+	return mirrored
+}
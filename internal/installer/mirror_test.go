@@ -0,0 +1,111 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+)
+
+// fakeHandler fetches and checks updates by URL, or by repository for the primary
+type fakeHandler struct {
+	heads map[string]string // location -> commit; missing locations fail
+	hang  map[string]bool   // locations that block until the attempt times out
+}
+
+func (f *fakeHandler) location(source config.Source) string {
+	if source.URL != "" {
+		return source.URL
+	}
+	return source.Repository
+}
+
+func (f *fakeHandler) Fetch(ctx context.Context, source config.Source, destDir string) (string, string, error) {
+	location := f.location(source)
+	if f.hang[location] {
+		<-ctx.Done()
+		return "", "", ctx.Err()
+	}
+	commit, ok := f.heads[location]
+	if !ok {
+		return "", "", errors.New("unreachable")
+	}
+	return filepath.Join(destDir, "repo"), commit, nil
+}
+
+func (f *fakeHandler) CheckUpdate(ctx context.Context, source config.Source, currentCommit string) (bool, string, error) {
+	commit, ok := f.heads[f.location(source)]
+	if !ok {
+		return false, "", errors.New("unreachable")
+	}
+	return commit != currentCommit, commit, nil
+}
+
+func TestMirrorHandler(t *testing.T) {
+	source := config.Source{
+		Name:          "team",
+		Type:          "github",
+		Repository:    "acme/agents",
+		MirrorTimeout: 50 * time.Millisecond,
+		Mirrors:       []config.Mirror{{URL: "https://one.example.com/agents.git"}, {URL: "https://two.example.com/agents.git"}},
+	}
+
+	tests := []struct {
+		name       string
+		heads      map[string]string
+		hang       map[string]bool
+		wantMirror string
+		wantErr    string
+	}{
+		{
+			name:  "primary succeeds",
+			heads: map[string]string{"acme/agents": "abc", "https://one.example.com/agents.git": "old"},
+		},
+		{
+			name:       "falls back in order",
+			heads:      map[string]string{"https://two.example.com/agents.git": "abc"},
+			wantMirror: "https://two.example.com/agents.git",
+		},
+		{
+			name:       "primary times out",
+			heads:      map[string]string{"https://one.example.com/agents.git": "abc"},
+			hang:       map[string]bool{"acme/agents": true},
+			wantMirror: "https://one.example.com/agents.git",
+		},
+		{
+			name:    "mirrors disagree",
+			heads:   map[string]string{"https://one.example.com/agents.git": "abc", "https://two.example.com/agents.git": "def"},
+			wantErr: "refusing to install inconsistent content",
+		},
+		{
+			name:    "all fail",
+			heads:   map[string]string{},
+			wantErr: "all 3 locations failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeHandler{heads: tt.heads, hang: tt.hang}
+			handler := &mirrorHandler{primary: fake, mirror: fake}
+
+			_, commit, err := handler.Fetch(context.Background(), source, t.TempDir())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Fetch() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if commit != "abc" || handler.FetchedMirror() != tt.wantMirror {
+				t.Errorf("Fetch() = %s from %q, want abc from %q", commit, handler.FetchedMirror(), tt.wantMirror)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/artifact"
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/pacphi/claude-code-agent-manager/internal/transformer"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+// Plan actions for sources and files
+const (
+	PlanAdded     = "added"
+	PlanRemoved   = "removed"
+	PlanChanged   = "changed"
+	PlanUnchanged = "unchanged"
+)
+
+// FileChange is how a planned install changes one target file
+type FileChange struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Agent  bool   `json:"agent"`
+	// Diff is the unified diff of a changed agent file, set when planning
+	// with Options.Diff
+	Diff string `json:"diff,omitempty"`
+}
+
+// SourcePlan is the difference between a source's installed files and what
+// installing it with the planned configuration would produce
+type SourcePlan struct {
+	Source string       `json:"source"`
+	Action string       `json:"action"`
+	Files  []FileChange `json:"files,omitempty"`
+	// Notes lists effects that could not be planned, such as custom scripts
+	Notes []string `json:"notes,omitempty"`
+	// ConfigChanged is set when the source was installed with a different
+	// configuration than the planned one
+	ConfigChanged bool `json:"config_changed,omitempty"`
+}
+
+// Changed returns the file changes other than unchanged files
+func (p *SourcePlan) Changed() []FileChange {
+	var changed []FileChange
+	for _, file := range p.Files {
+		if file.Action != PlanUnchanged {
+			changed = append(changed, file)
+		}
+	}
+	return changed
+}
+
+// PlanSource computes how installing source would change its installed files.
+// The source is fetched to a temporary directory; the target directory,
+// tracking data and docs are never modified.
+func (i *Installer) PlanSource(ctx context.Context, source config.Source) (*SourcePlan, error) {
+	installed, err := i.tracker.GetInstallation(source.Name)
+	if err != nil {
+		installed = nil
+	}
+
+	plan := &SourcePlan{Source: source.Name, Action: PlanChanged}
+	if installed == nil {
+		plan.Action = PlanAdded
+	}
+
+	_, fetchedPath, _, tempDir, err := i.fetchSource(ctx, source)
+	if tempDir != "" {
+		defer i.cleanupTempDir(tempDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := i.applyFilters(fetchedPath, source.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply filters: %w", err)
+	}
+	files, plan.Notes, err = i.planTransformations(source, files, fetchedPath)
+	if err != nil {
+		return nil, err
+	}
+
+	targetDir := i.resolveTargetPath(source.Paths.Target)
+	extensions := i.config.Settings.Query.Index.Extensions
+
+	tracked := make(map[string]bool)
+	if installed != nil {
+		for path := range installed.Files {
+			tracked[absPath(path)] = true
+		}
+	}
+
+	kind := source.ArtifactKind()
+	seen := make(map[string]bool, len(files))
+	for _, relPath := range files {
+		if kind != config.KindAgent && artifact.IsArtifactFile(kind, relPath, extensions) {
+			if err := artifact.Validate(kind, filepath.Join(fetchedPath, relPath)); err != nil {
+				plan.Notes = append(plan.Notes, fmt.Sprintf("%s %s would be skipped: %v", kind, relPath, err))
+				continue
+			}
+		}
+
+		dstPath := filepath.Join(targetDir, relPath)
+		seen[absPath(dstPath)] = true
+		if pattern := i.readonlyPattern(source.Name, dstPath); pattern != "" {
+			plan.Notes = append(plan.Notes, fmt.Sprintf("%s would not be written: it matches readonly_paths pattern %q", dstPath, pattern))
+			continue
+		}
+
+		change := FileChange{Path: dstPath, Agent: kind == config.KindAgent && parser.IsAgentFile(relPath, extensions)}
+		switch same, err := sameContent(filepath.Join(fetchedPath, relPath), dstPath); {
+		case err != nil:
+			return nil, err
+		case !tracked[absPath(dstPath)]:
+			change.Action = PlanAdded
+		case same:
+			change.Action = PlanUnchanged
+		default:
+			change.Action = PlanChanged
+			if i.options.Diff && change.Agent {
+				if change.Diff, err = fileDiff(dstPath, filepath.Join(fetchedPath, relPath)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		plan.Files = append(plan.Files, change)
+	}
+
+	if installed != nil {
+		var kept []string
+		for path := range installed.Files {
+			if seen[absPath(path)] {
+				continue
+			}
+			if pattern := i.readonlyPattern(source.Name, path); pattern != "" {
+				kept = append(kept, fmt.Sprintf("%s would not be removed: it matches readonly_paths pattern %q", path, pattern))
+				continue
+			}
+			plan.Files = append(plan.Files, FileChange{Path: path, Action: PlanRemoved, Agent: parser.IsAgentFile(path, extensions)})
+		}
+		sort.Strings(kept)
+		plan.Notes = append(plan.Notes, kept...)
+		// Installs recorded before config hashes were tracked cannot drift this way
+		plan.ConfigChanged = installed.ConfigHash != "" && installed.ConfigHash != i.config.SourceHash(source)
+		if len(plan.Changed()) == 0 && !plan.ConfigChanged {
+			plan.Action = PlanUnchanged
+		}
+	}
+
+	sort.Slice(plan.Files, func(a, b int) bool { return plan.Files[a].Path < plan.Files[b].Path })
+	return plan, nil
+}
+
+// PlanRemoval returns the plan for an installed source that the planned
+// configuration no longer installs
+func PlanRemoval(sourceName string, installed *tracker.Installation, extensions []string) *SourcePlan {
+	plan := &SourcePlan{Source: sourceName, Action: PlanRemoved}
+	for path := range installed.Files {
+		plan.Files = append(plan.Files, FileChange{Path: path, Action: PlanRemoved, Agent: parser.IsAgentFile(path, extensions)})
+	}
+	sort.Slice(plan.Files, func(a, b int) bool { return plan.Files[a].Path < plan.Files[b].Path })
+	return plan
+}
+
+// planTransformations applies the transformations that only affect the
+// fetched files and simulates the rest, returning notes for effects that
+// cannot be planned
+func (i *Installer) planTransformations(source config.Source, files []string, fetchedPath string) ([]string, []string, error) {
+	var notes []string
+	trans := transformer.New(i.config.Settings)
+	for _, transform := range source.Transformations {
+		switch transform.Type {
+		case "extract_docs":
+			// Extracted docs are written outside the target; only drop them from the files
+			pattern := transform.SourcePattern
+			if pattern == "" {
+				pattern = "*/README.md"
+			}
+			remaining := files[:0:0]
+			for _, file := range files {
+				if matched, _ := filepath.Match(pattern, file); !matched {
+					remaining = append(remaining, file)
+				}
+			}
+			files = remaining
+		case "custom_script":
+			notes = append(notes, fmt.Sprintf("custom_script %s is not run while planning", transform.Script))
+		default:
+			var err error
+			files, err = trans.Apply(files, transform, fetchedPath, i.resolveTargetPath(source.Paths.Target))
+			if err != nil {
+				return nil, nil, fmt.Errorf("transformation failed: %w", err)
+			}
+		}
+	}
+	return files, notes, nil
+}
+
+// sameContent reports whether the files at src and dst have identical content;
+// a missing dst is never the same
+func sameContent(src, dst string) (bool, error) {
+	want, err := os.ReadFile(src)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	have, err := os.ReadFile(dst)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", dst, err)
+	}
+	return bytes.Equal(want, have), nil
+}
+
+// fileDiff returns the unified diff from the installed file at dst to the
+// fetched file at src
+func fileDiff(dst, src string) (string, error) {
+	have, err := os.ReadFile(dst)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", dst, err)
+	}
+	want, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	return util.UnifiedDiff(string(have), string(want), dst, dst+" (source)"), nil
+}
+
+// printPlanFiles prints the files a dry run would add, change or remove,
+// with the diffs of changed agents, followed by a count of each
+func printPlanFiles(plan *SourcePlan, verbose bool) {
+	counts := make(map[string]int)
+	for _, file := range plan.Files {
+		counts[file.Action]++
+		switch file.Action {
+		case PlanAdded:
+			color.Green("    + %s\n", file.Path)
+		case PlanChanged:
+			color.Yellow("    ~ %s\n", file.Path)
+		case PlanRemoved:
+			color.Red("    - %s\n", file.Path)
+		default:
+			if verbose {
+				fmt.Printf("    = %s\n", file.Path)
+			}
+			continue
+		}
+		if file.Diff != "" {
+			for _, diffLine := range strings.Split(strings.TrimRight(file.Diff, "\n"), "\n") {
+				fmt.Printf("      %s\n", diffLine)
+			}
+		}
+	}
+	fmt.Printf("    %d to add, %d to change, %d to remove, %d unchanged\n",
+		counts[PlanAdded], counts[PlanChanged], counts[PlanRemoved], counts[PlanUnchanged])
+}
+
+// absPath returns the absolute form of path, or path itself when it cannot be resolved
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
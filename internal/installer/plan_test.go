@@ -0,0 +1,128 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/conflict"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+)
+
+func TestPlanSource(t *testing.T) {
+	dir := t.TempDir()
+	sourceDir := filepath.Join(dir, "src")
+	targetDir := filepath.Join(dir, "agents")
+	if err := os.MkdirAll(sourceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeAgent := func(name, description string) {
+		content := "---\nname: " + name + "\ndescription: " + description + "\n---\nPrompt\n"
+		if err := os.WriteFile(filepath.Join(sourceDir, name+".md"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeAgent("kept", "Unchanged")
+	writeAgent("edited", "Original")
+	writeAgent("dropped", "Removed later")
+
+	cfg := &config.Config{
+		Settings: config.Settings{BaseDir: targetDir, ConflictStrategy: "overwrite", BackupDir: filepath.Join(dir, "backups")},
+		Metadata: config.Metadata{TrackingFile: filepath.Join(dir, ".installed.json")},
+	}
+	source := config.Source{
+		Name:    "local",
+		Type:    "local",
+		Enabled: true,
+		Paths:   config.PathConfig{Source: sourceDir, Target: targetDir},
+	}
+	inst := New(cfg, tracker.New(cfg.Metadata.TrackingFile), conflict.NewResolver("overwrite", cfg.Settings.BackupDir), Options{})
+
+	plan, err := inst.PlanSource(context.Background(), source)
+	if err != nil {
+		t.Fatalf("PlanSource() error = %v", err)
+	}
+	if plan.Action != PlanAdded || len(plan.Changed()) != 3 {
+		t.Fatalf("Expected a new source with 3 added files, got %s with %+v", plan.Action, plan.Files)
+	}
+
+	if err := inst.InstallSource(context.Background(), source); err != nil {
+		t.Fatalf("InstallSource() error = %v", err)
+	}
+	plan, err = inst.PlanSource(context.Background(), source)
+	if err != nil {
+		t.Fatalf("PlanSource() error = %v", err)
+	}
+	if plan.Action != PlanUnchanged {
+		t.Errorf("Expected unchanged after install, got %s", plan.Action)
+	}
+
+	// A configuration change drifts the source even when no file differs,
+	// unlike changes to how it is fetched
+	reconfigured := source
+	reconfigured.ConflictStrategy = "skip"
+	plan, err = inst.PlanSource(context.Background(), reconfigured)
+	if err != nil {
+		t.Fatalf("PlanSource() error = %v", err)
+	}
+	if plan.Action != PlanChanged || !plan.ConfigChanged || len(plan.Changed()) != 0 {
+		t.Errorf("Expected a configuration-only change, got %s (config changed %v) with %+v", plan.Action, plan.ConfigChanged, plan.Changed())
+	}
+	refetched := source
+	refetched.MirrorTimeout = time.Minute
+	refetched.DryRun = true
+	if cfg.SourceHash(refetched) != cfg.SourceHash(source) {
+		t.Error("Expected fetch settings to be left out of the config hash")
+	}
+
+	writeAgent("edited", "Revised")
+	writeAgent("added", "New agent")
+	if err := os.Remove(filepath.Join(sourceDir, "dropped.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err = inst.PlanSource(context.Background(), source)
+	if err != nil {
+		t.Fatalf("PlanSource() error = %v", err)
+	}
+	want := map[string]string{
+		"added.md":   PlanAdded,
+		"edited.md":  PlanChanged,
+		"dropped.md": PlanRemoved,
+		"kept.md":    PlanUnchanged,
+	}
+	if plan.Action != PlanChanged || len(plan.Files) != len(want) {
+		t.Fatalf("Expected a changed source with %d files, got %s with %+v", len(want), plan.Action, plan.Files)
+	}
+	for _, file := range plan.Files {
+		if action := want[filepath.Base(file.Path)]; file.Action != action || !file.Agent {
+			t.Errorf("%s: got %s, want %s", file.Path, file.Action, action)
+		}
+	}
+
+	// With Diff, changed agents carry the diff from the installed content
+	differ := New(cfg, tracker.New(cfg.Metadata.TrackingFile), nil, Options{Diff: true})
+	plan, err = differ.PlanSource(context.Background(), source)
+	if err != nil {
+		t.Fatalf("PlanSource() error = %v", err)
+	}
+	for _, file := range plan.Files {
+		hasDiff := strings.Contains(file.Diff, "-description: Original") && strings.Contains(file.Diff, "+description: Revised")
+		if (file.Action == PlanChanged) != hasDiff {
+			t.Errorf("%s (%s): unexpected diff %q", file.Path, file.Action, file.Diff)
+		}
+	}
+
+	// Planning never touches the installed files
+	content, err := os.ReadFile(filepath.Join(targetDir, "edited.md"))
+	if err != nil || string(content) != "---\nname: edited\ndescription: Original\n---\nPrompt\n" {
+		t.Errorf("Expected the installed file to be unchanged, got %q (%v)", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "added.md")); !os.IsNotExist(err) {
+		t.Error("Expected planning not to install new files")
+	}
+}
@@ -0,0 +1,488 @@
+package installer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+const (
+	// maxReleaseAssetSize bounds the size of a downloaded release asset
+	maxReleaseAssetSize = 256 << 20
+	// maxChecksumsSize bounds the size of a downloaded sums file
+	maxChecksumsSize = 1 << 20
+	// maxExtractedSize bounds the total size of the files extracted from an asset
+	maxExtractedSize = 1 << 30
+)
+
+// checksumFiles are the sums file assets searched when a source names none;
+// "%s" stands for the asset name
+var checksumFiles = []string{"%s.sha256", "SHA256SUMS", "SHA256SUMS.txt", "sha256sums.txt", "checksums.txt"}
+
+// sha256Pattern matches a hex-encoded sha256 digest
+var sha256Pattern = regexp.MustCompile(`\b[0-9a-fA-F]{64}\b`)
+
+// GitHubReleaseHandler installs an archive attached to a GitHub release,
+// using the release tag as the source version
+type GitHubReleaseHandler struct {
+	apiURL string // overrides githubAPIURL when set
+}
+
+// githubRelease is the subset of the GitHub release API response used here
+type githubRelease struct {
+	TagName    string         `json:"tag_name"`
+	Body       string         `json:"body"`
+	Draft      bool           `json:"draft"`
+	Prerelease bool           `json:"prerelease"`
+	Assets     []releaseAsset `json:"assets"`
+}
+
+// releaseAsset is a file attached to a release
+type releaseAsset struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	URL  string `json:"url"`
+}
+
+// Fetch downloads the release asset, verifies its checksum and extracts it
+func (g *GitHubReleaseHandler) Fetch(ctx context.Context, source config.Source, destDir string) (string, string, error) {
+	if err := util.ValidatePath(destDir); err != nil {
+		return "", "", fmt.Errorf("invalid destination directory: %w", err)
+	}
+	release, err := g.release(ctx, source)
+	if err != nil {
+		return "", "", err
+	}
+	asset, err := release.asset(source.Release.Asset)
+	if err != nil {
+		return "", "", err
+	}
+	if asset.Size > maxReleaseAssetSize {
+		return "", "", fmt.Errorf("release asset %s is %d bytes, exceeding the %d byte limit", asset.Name, asset.Size, maxReleaseAssetSize)
+	}
+
+	archivePath := filepath.Join(destDir, filepath.Base(asset.Name))
+	digest, err := g.download(ctx, source, asset, archivePath, maxReleaseAssetSize)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+
+	expected, err := g.expectedChecksum(ctx, source, release, asset.Name)
+	switch {
+	case err != nil:
+		return "", "", err
+	case expected == "" && !source.Release.AllowUnverified:
+		return "", "", fmt.Errorf("no sha256 checksum published for %s in release %s; set release.allow_unverified to install it anyway", asset.Name, release.TagName)
+	case expected == "":
+		color.Yellow("Warning: installing unverified release asset %s of %s\n", asset.Name, source.Name)
+	case !strings.EqualFold(expected, digest):
+		return "", "", fmt.Errorf("checksum mismatch for %s: expected sha256 %s, got %s", asset.Name, expected, digest)
+	default:
+		util.DebugPrintf("Verified sha256 of %s: %s\n", asset.Name, digest)
+	}
+
+	extractDir := filepath.Join(destDir, "release")
+	if err := extractArchive(archivePath, extractDir); err != nil {
+		return "", "", fmt.Errorf("failed to extract %s: %w", asset.Name, err)
+	}
+	return archiveSourcePath(extractDir, source.Paths.Source), release.TagName, nil
+}
+
+// CheckUpdate compares the tag of the selected release against the installed one
+func (g *GitHubReleaseHandler) CheckUpdate(ctx context.Context, source config.Source, currentCommit string) (bool, string, error) {
+	release, err := g.release(ctx, source)
+	if err != nil {
+		return false, "", err
+	}
+	return release.TagName != currentCommit, release.TagName, nil
+}
+
+// release returns the release selected by the source: the one tagged
+// release.tag, or the latest published non-prerelease
+func (g *GitHubReleaseHandler) release(ctx context.Context, source config.Source) (*githubRelease, error) {
+	if err := util.ValidateRepository(source.Repository); err != nil {
+		return nil, fmt.Errorf("invalid repository: %w", err)
+	}
+	if err := util.ValidateBranch(source.Release.Tag); err != nil {
+		return nil, fmt.Errorf("invalid release tag: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/repos/%s/releases?per_page=100", g.baseURL(), source.Repository)
+	body, err := g.get(ctx, source, endpoint, "application/vnd.github+json", 8<<20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases of %s: %w", source.Repository, err)
+	}
+	var releases []githubRelease
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return nil, fmt.Errorf("failed to parse releases of %s: %w", source.Repository, err)
+	}
+
+	for n := range releases {
+		release := &releases[n]
+		if source.Release.Tag != "" {
+			if release.TagName == source.Release.Tag {
+				return release, nil
+			}
+			continue
+		}
+		// Releases are listed newest first
+		if !release.Draft && !release.Prerelease {
+			return release, nil
+		}
+	}
+	if source.Release.Tag != "" {
+		return nil, fmt.Errorf("release %s not found in %s", source.Release.Tag, source.Repository)
+	}
+	return nil, fmt.Errorf("no published release found in %s", source.Repository)
+}
+
+// asset returns the first asset whose name matches pattern
+func (r *githubRelease) asset(pattern string) (*releaseAsset, error) {
+	for n := range r.Assets {
+		if matched, _ := filepath.Match(pattern, r.Assets[n].Name); matched {
+			return &r.Assets[n], nil
+		}
+	}
+	return nil, fmt.Errorf("release %s has no asset matching %s", r.TagName, pattern)
+}
+
+// expectedChecksum returns the published sha256 of assetName: from the
+// configured sums file, else the release notes, else a conventionally named
+// sums file. It returns "" when none publishes one.
+func (g *GitHubReleaseHandler) expectedChecksum(ctx context.Context, source config.Source, release *githubRelease, assetName string) (string, error) {
+	if name := source.Release.Checksums; name != "" {
+		sums, err := release.asset(name)
+		if err != nil {
+			return "", err
+		}
+		checksum, err := g.sumsFileChecksum(ctx, source, sums, assetName)
+		if err != nil {
+			return "", err
+		}
+		if checksum == "" {
+			return "", fmt.Errorf("%s lists no checksum for %s", sums.Name, assetName)
+		}
+		return checksum, nil
+	}
+
+	if checksum := findChecksum(release.Body, assetName, false); checksum != "" {
+		return checksum, nil
+	}
+	for _, pattern := range checksumFiles {
+		name := pattern
+		if strings.Contains(pattern, "%s") {
+			name = fmt.Sprintf(pattern, assetName)
+		}
+		for n := range release.Assets {
+			if release.Assets[n].Name != name {
+				continue
+			}
+			checksum, err := g.sumsFileChecksum(ctx, source, &release.Assets[n], assetName)
+			if err != nil || checksum != "" {
+				return checksum, err
+			}
+		}
+	}
+	return "", nil
+}
+
+// sumsFileChecksum downloads a sums file and returns the checksum it lists for assetName
+func (g *GitHubReleaseHandler) sumsFileChecksum(ctx context.Context, source config.Source, sums *releaseAsset, assetName string) (string, error) {
+	body, err := g.get(ctx, source, g.assetURL(source, sums), "application/octet-stream", maxChecksumsSize)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", sums.Name, err)
+	}
+	// A per-asset sums file may hold the bare digest
+	return findChecksum(string(body), assetName, strings.HasSuffix(sums.Name, ".sha256")), nil
+}
+
+// findChecksum returns the sha256 listed for assetName in text, in sha256sum
+// format ("<digest>  <name>") or as any line naming the asset next to a
+// digest. With single set, a lone digest is accepted too.
+func findChecksum(text, assetName string, single bool) string {
+	var lone []string
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := scanner.Text()
+		digest := sha256Pattern.FindString(line)
+		if digest == "" {
+			continue
+		}
+		for _, field := range strings.FieldsFunc(line, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == '|' || r == '`' || r == '*' || r == ':' || r == '(' || r == ')'
+		}) {
+			if field == assetName || filepath.Base(field) == assetName {
+				return strings.ToLower(digest)
+			}
+		}
+		lone = append(lone, digest)
+	}
+	if single && len(lone) == 1 {
+		return strings.ToLower(lone[0])
+	}
+	return ""
+}
+
+// download writes the asset to path and returns its sha256
+func (g *GitHubReleaseHandler) download(ctx context.Context, source config.Source, asset *releaseAsset, path string, limit int64) (string, error) {
+	resp, err := g.request(ctx, source, g.assetURL(source, asset), "application/octet-stream")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return writeDownload(resp.Body, path, limit)
+}
+
+// writeDownload writes at most limit bytes of body to path and returns their
+// sha256; an empty path only hashes the body
+func writeDownload(body io.Reader, path string, limit int64) (string, error) {
+	dst := io.Discard
+	if path != "" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return "", fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		defer func() { _ = file.Close() }()
+		dst = file
+	}
+
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(dst, hash), io.LimitReader(body, limit+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if written > limit {
+		return "", fmt.Errorf("download exceeds the %d byte limit", limit)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// get fetches url and returns at most limit bytes of the response body
+func (g *GitHubReleaseHandler) get(ctx context.Context, source config.Source, url, accept string, limit int64) ([]byte, error) {
+	resp, err := g.request(ctx, source, url, accept)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("response exceeds the %d byte limit", limit)
+	}
+	return body, nil
+}
+
+// request sends an authenticated GET request and checks the response status.
+// Asset downloads redirect to storage on another host, where the HTTP client
+// drops the Authorization header.
+func (g *GitHubReleaseHandler) request(ctx context.Context, source config.Source, url, accept string) (*nethttp.Response, error) {
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if token := sourceToken(source); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	applyHTTPOptions(req, source.Auth)
+
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("request aborted: %w", ctx.Err())
+		}
+		return nil, fmt.Errorf("github API request failed: %w", err)
+	}
+	if resp.StatusCode != nethttp.StatusOK {
+		_ = resp.Body.Close()
+		if resp.StatusCode == nethttp.StatusNotFound && sourceToken(source) == "" {
+			return nil, fmt.Errorf("github API returned %s; private repositories need auth", resp.Status)
+		}
+		return nil, fmt.Errorf("github API returned %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// assetURL returns the API URL downloading an asset, which works for private repositories
+func (g *GitHubReleaseHandler) assetURL(source config.Source, asset *releaseAsset) string {
+	if asset.URL != "" && g.apiURL == "" {
+		return asset.URL
+	}
+	return fmt.Sprintf("%s/repos/%s/releases/assets/%d", g.baseURL(), source.Repository, asset.ID)
+}
+
+func (g *GitHubReleaseHandler) baseURL() string {
+	if g.apiURL != "" {
+		return g.apiURL
+	}
+	return githubAPIURL
+}
+
+// extractArchive unpacks a .zip, .tar.gz or .tgz archive into dir. Entries
+// escaping dir, links and special files are rejected or skipped.
+func extractArchive(archivePath, dir string) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	name := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return extractZip(archivePath, dir)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return extractTarGz(archivePath, dir)
+	default:
+		return fmt.Errorf("unsupported archive format: %s", filepath.Base(archivePath))
+	}
+}
+
+// extractZip unpacks a zip archive into dir
+func extractZip(archivePath, dir string) error {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+
+	var total int64
+	for _, entry := range reader.File {
+		target, err := archiveTarget(dir, entry.Name)
+		if err != nil {
+			return err
+		}
+		mode := entry.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0750); err != nil {
+				return err
+			}
+			continue
+		case !mode.IsRegular():
+			util.DebugPrintf("Skipping non-regular archive entry %s\n", entry.Name)
+			continue
+		}
+
+		src, err := entry.Open()
+		if err != nil {
+			return err
+		}
+		written, err := writeArchiveFile(target, src, maxExtractedSize-total)
+		_ = src.Close()
+		if err != nil {
+			return err
+		}
+		total += written
+	}
+	return nil
+}
+
+// extractTarGz unpacks a gzip-compressed tar archive into dir
+func extractTarGz(archivePath, dir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = gz.Close() }()
+
+	var total int64
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := archiveTarget(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0750); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			written, err := writeArchiveFile(target, reader, maxExtractedSize-total)
+			if err != nil {
+				return err
+			}
+			total += written
+		default:
+			util.DebugPrintf("Skipping non-regular archive entry %s\n", header.Name)
+		}
+	}
+}
+
+// archiveTarget resolves an archive entry name inside dir, rejecting names
+// that would escape it
+func archiveTarget(dir, name string) (string, error) {
+	cleaned := filepath.FromSlash(strings.TrimPrefix(name, "./"))
+	if filepath.IsAbs(cleaned) || !filepath.IsLocal(filepath.Clean(cleaned)) && filepath.Clean(cleaned) != "." {
+		return "", fmt.Errorf("archive entry escapes the extraction directory: %s", name)
+	}
+	return filepath.Join(dir, cleaned), nil
+}
+
+// writeArchiveFile writes an extracted file, failing once more than limit bytes are written
+func writeArchiveFile(target string, src io.Reader, limit int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+		return 0, err
+	}
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = dst.Close() }()
+
+	written, err := io.Copy(dst, io.LimitReader(src, limit+1))
+	if err != nil {
+		return written, err
+	}
+	if written > limit {
+		return written, fmt.Errorf("archive expands beyond the %d byte limit", maxExtractedSize)
+	}
+	return written, nil
+}
+
+// archiveSourcePath resolves sourcePath inside an extracted archive. Bundles
+// usually wrap their files in a versioned directory, so a lone top-level
+// directory is looked through when sourcePath is not found at the root.
+func archiveSourcePath(dir, sourcePath string) string {
+	path := filepath.Join(dir, sourcePath)
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return path
+	}
+	return filepath.Join(dir, entries[0].Name(), sourcePath)
+}
@@ -0,0 +1,267 @@
+package installer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+)
+
+// tarGz builds a gzip-compressed tar archive holding files
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// zipArchive builds a zip archive holding files
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// releaseServer serves a release listing and the given assets by id
+func releaseServer(t *testing.T, releases []githubRelease, assets map[int64][]byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Path == "/repos/owner/repo/releases" {
+			_ = json.NewEncoder(w).Encode(releases)
+			return
+		}
+		var id int64
+		if _, err := fmt.Sscanf(r.URL.Path, "/repos/owner/repo/releases/assets/%d", &id); err == nil {
+			if r.Header.Get("Accept") != "application/octet-stream" {
+				t.Errorf("Unexpected Accept header: %s", r.Header.Get("Accept"))
+			}
+			if data, ok := assets[id]; ok {
+				_, _ = w.Write(data)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func releaseSource() config.Source {
+	return config.Source{
+		Name:       "release",
+		Type:       "github-release",
+		Repository: "owner/repo",
+		Auth:       config.AuthConfig{TokenEnv: "RELEASE_TEST_TOKEN"},
+		Release:    config.ReleaseConfig{Asset: "agents-*.tar.gz"},
+		Paths:      config.PathConfig{Source: "agents"},
+	}
+}
+
+func TestGitHubReleaseHandler_Fetch(t *testing.T) {
+	t.Setenv("RELEASE_TEST_TOKEN", "secret")
+
+	archive := tarGz(t, map[string]string{"agents-1.2.0/agents/reviewer.md": "---\nname: reviewer\n---\n"})
+	releases := []githubRelease{
+		{TagName: "v2.0.0-rc1", Prerelease: true},
+		{
+			TagName: "v1.2.0",
+			Body:    fmt.Sprintf("## Checksums\n\n%s  agents-1.2.0.tar.gz\n", sha256Hex(archive)),
+			Assets:  []releaseAsset{{ID: 7, Name: "agents-1.2.0.tar.gz", Size: int64(len(archive))}},
+		},
+	}
+	server := releaseServer(t, releases, map[int64][]byte{7: archive})
+	handler := &GitHubReleaseHandler{apiURL: server.URL}
+
+	path, tag, err := handler.Fetch(context.Background(), releaseSource(), t.TempDir())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if tag != "v1.2.0" {
+		t.Errorf("Expected the latest published release, got %s", tag)
+	}
+	if _, err := os.Stat(filepath.Join(path, "reviewer.md")); err != nil {
+		t.Errorf("Expected the agent below the archive's top-level directory: %v", err)
+	}
+
+	hasUpdate, latest, err := handler.CheckUpdate(context.Background(), releaseSource(), "v1.1.0")
+	if err != nil {
+		t.Fatalf("CheckUpdate failed: %v", err)
+	}
+	if !hasUpdate || latest != "v1.2.0" {
+		t.Errorf("Expected an update to v1.2.0, got hasUpdate=%v tag=%s", hasUpdate, latest)
+	}
+}
+
+func TestGitHubReleaseHandler_FetchSumsFile(t *testing.T) {
+	t.Setenv("RELEASE_TEST_TOKEN", "secret")
+
+	archive := zipArchive(t, map[string]string{"agents/reviewer.md": "---\nname: reviewer\n---\n"})
+	sums := fmt.Sprintf("%s  other.zip\n%s  agents.zip\n", strings.Repeat("0", 64), sha256Hex(archive))
+	releases := []githubRelease{{
+		TagName: "v1.0.0",
+		Assets: []releaseAsset{
+			{ID: 1, Name: "agents.zip"},
+			{ID: 2, Name: "SHA256SUMS"},
+		},
+	}}
+	server := releaseServer(t, releases, map[int64][]byte{1: archive, 2: []byte(sums)})
+	handler := &GitHubReleaseHandler{apiURL: server.URL}
+
+	source := releaseSource()
+	source.Release = config.ReleaseConfig{Tag: "v1.0.0", Asset: "agents.zip"}
+	path, _, err := handler.Fetch(context.Background(), source, t.TempDir())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "reviewer.md")); err != nil {
+		t.Errorf("Expected the extracted agent: %v", err)
+	}
+}
+
+func TestGitHubReleaseHandler_FetchVerification(t *testing.T) {
+	t.Setenv("RELEASE_TEST_TOKEN", "secret")
+
+	archive := tarGz(t, map[string]string{"agents/reviewer.md": "reviewer"})
+	tests := []struct {
+		name            string
+		body            string
+		allowUnverified bool
+		wantErr         string
+	}{
+		{name: "mismatch", body: strings.Repeat("a", 64) + "  agents-1.0.0.tar.gz", wantErr: "checksum mismatch"},
+		{name: "unverified", wantErr: "no sha256 checksum"},
+		{name: "allow unverified", allowUnverified: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			releases := []githubRelease{{
+				TagName: "v1.0.0",
+				Body:    tt.body,
+				Assets:  []releaseAsset{{ID: 3, Name: "agents-1.0.0.tar.gz"}},
+			}}
+			server := releaseServer(t, releases, map[int64][]byte{3: archive})
+			handler := &GitHubReleaseHandler{apiURL: server.URL}
+
+			source := releaseSource()
+			source.Release.AllowUnverified = tt.allowUnverified
+			_, _, err := handler.Fetch(context.Background(), source, t.TempDir())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Fetch failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGitHubReleaseHandler_ReleaseNotFound(t *testing.T) {
+	t.Setenv("RELEASE_TEST_TOKEN", "secret")
+
+	server := releaseServer(t, []githubRelease{{TagName: "v1.0.0"}}, nil)
+	handler := &GitHubReleaseHandler{apiURL: server.URL}
+
+	source := releaseSource()
+	source.Release.Tag = "v9.9.9"
+	if _, _, err := handler.CheckUpdate(context.Background(), source, ""); err == nil {
+		t.Error("Expected an error for a missing release tag")
+	}
+}
+
+func TestExtractArchive_RejectsEscapingEntries(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"evil.tar.gz": tarGz(t, map[string]string{"../escaped.md": "x"}),
+		"evil.zip":    zipArchive(t, map[string]string{"../../escaped.md": "x"}),
+	} {
+		archivePath := filepath.Join(dir, name)
+		if err := os.WriteFile(archivePath, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := extractArchive(archivePath, filepath.Join(dir, "out")); err == nil {
+			t.Errorf("Expected %s to be rejected", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escaped.md")); !os.IsNotExist(err) {
+		t.Error("Expected no file to be written outside the extraction directory")
+	}
+}
+
+func TestFindChecksum(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+	tests := []struct {
+		name   string
+		text   string
+		single bool
+		want   string
+	}{
+		{name: "sha256sum format", text: digest + "  agents.zip", want: digest},
+		{name: "binary marker", text: digest + " *agents.zip", want: digest},
+		{name: "markdown table", text: "| `agents.zip` | `" + strings.ToUpper(digest) + "` |", want: digest},
+		{name: "other asset", text: digest + "  other.zip"},
+		{name: "bare digest", text: digest + "\n", single: true, want: digest},
+		{name: "bare digest in notes", text: digest + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findChecksum(tt.text, "agents.zip", tt.single); got != tt.want {
+				t.Errorf("findChecksum() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
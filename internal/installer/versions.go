@@ -0,0 +1,106 @@
+package installer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+)
+
+// VersionChange is an agent whose frontmatter version differs after an update.
+// From is empty for an added agent and To is empty for a removed one.
+type VersionChange struct {
+	Agent string `json:"agent"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+	// Added and Removed mark agents that only exist on one side of the update
+	Added   bool `json:"added,omitempty"`
+	Removed bool `json:"removed,omitempty"`
+}
+
+// String describes the change for an update summary
+func (c VersionChange) String() string {
+	switch {
+	case c.Added && c.To != "":
+		return fmt.Sprintf("%s: added (%s)", c.Agent, c.To)
+	case c.Added:
+		return c.Agent + ": added"
+	case c.Removed:
+		return c.Agent + ": removed"
+	}
+	return fmt.Sprintf("%s: %s -> %s", c.Agent, versionLabel(c.From), versionLabel(c.To))
+}
+
+func versionLabel(version string) string {
+	if version == "" {
+		return "unversioned"
+	}
+	return version
+}
+
+// agentVersions maps the agents among files to their frontmatter versions,
+// keyed by agent name
+func (i *Installer) agentVersions(files []string) map[string]string {
+	agentParser := &parser.Parser{Extensions: i.config.Settings.Query.Index.Extensions}
+	versions := make(map[string]string)
+	for _, path := range files {
+		if !parser.IsAgentFile(path, agentParser.Extensions) {
+			continue
+		}
+		agent, err := agentParser.ParseFile(path)
+		if err != nil || agent.Name == "" {
+			continue
+		}
+		versions[agent.Name] = agent.Version
+	}
+	return versions
+}
+
+// installedPaths returns the paths of tracked files
+func installedPaths(files map[string]tracker.FileInfo) []string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// versionChanges compares agent versions before and after an update
+func versionChanges(before, after map[string]string) []VersionChange {
+	var changes []VersionChange
+	for agent, from := range before {
+		to, ok := after[agent]
+		switch {
+		case !ok:
+			changes = append(changes, VersionChange{Agent: agent, From: from, Removed: true})
+		case to != from:
+			changes = append(changes, VersionChange{Agent: agent, From: from, To: to})
+		}
+	}
+	for agent, to := range after {
+		if _, ok := before[agent]; !ok {
+			changes = append(changes, VersionChange{Agent: agent, To: to, Added: true})
+		}
+	}
+	sort.Slice(changes, func(a, b int) bool { return changes[a].Agent < changes[b].Agent })
+	return changes
+}
+
+// reportVersionChanges prints the per-agent changes of an update and keeps
+// them for VersionChanges
+func (i *Installer) reportVersionChanges(sourceName string, changes []VersionChange) {
+	if len(changes) == 0 {
+		return
+	}
+	i.versionChanges = append(i.versionChanges, changes...)
+	fmt.Printf("Agent changes in %s:\n", sourceName)
+	for _, change := range changes {
+		fmt.Printf("  %s\n", change)
+	}
+}
+
+// VersionChanges returns the per-agent version changes of the updates run so far
+func (i *Installer) VersionChanges() []VersionChange {
+	return i.versionChanges
+}
@@ -0,0 +1,54 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+)
+
+func TestAgentVersions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"reviewer.md": "---\nname: reviewer\ndescription: Reviews\nversion: 1.2.0\n---\nPrompt\n",
+		"writer.md":   "---\nname: writer\ndescription: Writes\n---\nPrompt\n",
+		"notes.txt":   "version: 9.9.9",
+	}
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	inst := &Installer{config: &config.Config{}}
+	got := inst.agentVersions(paths)
+	want := map[string]string{"reviewer": "1.2.0", "writer": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("agentVersions() = %v, want %v", got, want)
+	}
+}
+
+func TestVersionChanges(t *testing.T) {
+	before := map[string]string{"reviewer": "1.2.0", "writer": "", "legacy": "0.9.0", "tester": "2.0.0"}
+	after := map[string]string{"reviewer": "1.3.0", "writer": "1.0.0", "planner": "0.1.0", "tester": "2.0.0"}
+
+	changes := versionChanges(before, after)
+	var got []string
+	for _, change := range changes {
+		got = append(got, change.String())
+	}
+	want := []string{
+		"legacy: removed",
+		"planner: added (0.1.0)",
+		"reviewer: 1.2.0 -> 1.3.0",
+		"writer: unversioned -> 1.0.0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("versionChanges() = %v, want %v", got, want)
+	}
+}
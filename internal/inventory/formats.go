@@ -0,0 +1,247 @@
+package inventory
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Output formats
+const (
+	FormatCycloneDX = "cyclonedx-json"
+	FormatSPDX      = "spdx-json"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{FormatCycloneDX, FormatSPDX}
+
+// toolName identifies agent-manager as the creator of inventory documents
+const toolName = "agent-manager"
+
+// licenseExpression matches SPDX license identifiers and simple expressions
+// such as "MIT" or "Apache-2.0 OR MIT"; other license text is kept as a name
+var licenseExpression = regexp.MustCompile(`^[A-Za-z0-9.+-]+( (AND|OR|WITH) [A-Za-z0-9.+-]+)*$`)
+
+// Encode renders items in the given format
+func Encode(format string, items []Item, now time.Time) ([]byte, error) {
+	var document interface{}
+	switch format {
+	case FormatCycloneDX:
+		document = cycloneDX(items, now)
+	case FormatSPDX:
+		document = spdx(items, now)
+	default:
+		return nil, fmt.Errorf("unsupported inventory format: %s (must be %s or %s)", format, FormatCycloneDX, FormatSPDX)
+	}
+
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal inventory: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// CycloneDX 1.5 document types, limited to the fields the inventory uses
+type (
+	cdxDocument struct {
+		BOMFormat    string         `json:"bomFormat"`
+		SpecVersion  string         `json:"specVersion"`
+		SerialNumber string         `json:"serialNumber"`
+		Version      int            `json:"version"`
+		Metadata     cdxMetadata    `json:"metadata"`
+		Components   []cdxComponent `json:"components"`
+	}
+	cdxMetadata struct {
+		Timestamp string   `json:"timestamp"`
+		Tools     cdxTools `json:"tools"`
+	}
+	cdxTools struct {
+		Components []cdxComponent `json:"components"`
+	}
+	cdxComponent struct {
+		Type               string        `json:"type"`
+		BOMRef             string        `json:"bom-ref,omitempty"`
+		Name               string        `json:"name"`
+		Version            string        `json:"version,omitempty"`
+		Description        string        `json:"description,omitempty"`
+		Hashes             []cdxHash     `json:"hashes,omitempty"`
+		Licenses           []cdxLicense  `json:"licenses,omitempty"`
+		ExternalReferences []cdxRef      `json:"externalReferences,omitempty"`
+		Properties         []cdxProperty `json:"properties,omitempty"`
+	}
+	cdxHash struct {
+		Alg     string `json:"alg"`
+		Content string `json:"content"`
+	}
+	cdxLicense struct {
+		Expression string          `json:"expression,omitempty"`
+		License    *cdxLicenseName `json:"license,omitempty"`
+	}
+	cdxLicenseName struct {
+		Name string `json:"name"`
+	}
+	cdxRef struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	}
+	cdxProperty struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+)
+
+// cycloneDX builds a CycloneDX BOM with one file component per agent
+func cycloneDX(items []Item, now time.Time) cdxDocument {
+	doc := cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: now.UTC().Format(time.RFC3339),
+			Tools:     cdxTools{Components: []cdxComponent{{Type: "application", Name: toolName}}},
+		},
+		Components: make([]cdxComponent, 0, len(items)),
+	}
+
+	for i, item := range items {
+		component := cdxComponent{
+			Type:        "file",
+			BOMRef:      fmt.Sprintf("agent-%d", i+1),
+			Name:        item.Name,
+			Version:     item.Version,
+			Description: item.Description,
+			Hashes:      []cdxHash{{Alg: "SHA-256", Content: item.SHA256}},
+			Properties: []cdxProperty{
+				{Name: toolName + ":source", Value: item.Source},
+				{Name: toolName + ":path", Value: item.Path},
+			},
+		}
+		if item.Commit != "" {
+			component.Properties = append(component.Properties, cdxProperty{Name: toolName + ":commit", Value: item.Commit})
+		}
+		if item.SourceURL != "" {
+			component.ExternalReferences = []cdxRef{{Type: "distribution", URL: item.SourceURL}}
+		}
+		switch {
+		case item.License == "":
+		case licenseExpression.MatchString(item.License):
+			component.Licenses = []cdxLicense{{Expression: item.License}}
+		default:
+			component.Licenses = []cdxLicense{{License: &cdxLicenseName{Name: item.License}}}
+		}
+		doc.Components = append(doc.Components, component)
+	}
+	return doc
+}
+
+// SPDX 2.3 document types, limited to the fields the inventory uses
+type (
+	spdxDocument struct {
+		SPDXVersion       string             `json:"spdxVersion"`
+		DataLicense       string             `json:"dataLicense"`
+		SPDXID            string             `json:"SPDXID"`
+		Name              string             `json:"name"`
+		DocumentNamespace string             `json:"documentNamespace"`
+		CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+		Packages          []spdxPackage      `json:"packages"`
+		Relationships     []spdxRelationship `json:"relationships"`
+	}
+	spdxCreationInfo struct {
+		Created  string   `json:"created"`
+		Creators []string `json:"creators"`
+	}
+	spdxPackage struct {
+		Name             string         `json:"name"`
+		SPDXID           string         `json:"SPDXID"`
+		VersionInfo      string         `json:"versionInfo,omitempty"`
+		PackageFileName  string         `json:"packageFileName"`
+		DownloadLocation string         `json:"downloadLocation"`
+		FilesAnalyzed    bool           `json:"filesAnalyzed"`
+		Checksums        []spdxChecksum `json:"checksums"`
+		LicenseConcluded string         `json:"licenseConcluded"`
+		LicenseDeclared  string         `json:"licenseDeclared"`
+		LicenseComments  string         `json:"licenseComments,omitempty"`
+		CopyrightText    string         `json:"copyrightText"`
+		Description      string         `json:"description,omitempty"`
+		SourceInfo       string         `json:"sourceInfo,omitempty"`
+	}
+	spdxChecksum struct {
+		Algorithm     string `json:"algorithm"`
+		ChecksumValue string `json:"checksumValue"`
+	}
+	spdxRelationship struct {
+		SPDXElementID      string `json:"spdxElementId"`
+		RelationshipType   string `json:"relationshipType"`
+		RelatedSPDXElement string `json:"relatedSpdxElement"`
+	}
+)
+
+// spdx builds an SPDX document describing one package per agent
+func spdx(items []Item, now time.Time) spdxDocument {
+	id := newUUID()
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              toolName + "-inventory",
+		DocumentNamespace: "https://github.com/pacphi/claude-code-agent-manager/inventory/" + id,
+		CreationInfo: spdxCreationInfo{
+			Created:  now.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + toolName},
+		},
+		Packages:      make([]spdxPackage, 0, len(items)),
+		Relationships: make([]spdxRelationship, 0, len(items)),
+	}
+
+	for i, item := range items {
+		pkg := spdxPackage{
+			Name:             item.Name,
+			SPDXID:           fmt.Sprintf("SPDXRef-Agent-%d", i+1),
+			VersionInfo:      item.Version,
+			PackageFileName:  item.Path,
+			DownloadLocation: "NOASSERTION",
+			Checksums: []spdxChecksum{
+				{Algorithm: "SHA1", ChecksumValue: item.SHA1},
+				{Algorithm: "SHA256", ChecksumValue: item.SHA256},
+			},
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  "NOASSERTION",
+			CopyrightText:    "NOASSERTION",
+			Description:      item.Description,
+			SourceInfo:       "installed by agent-manager source " + item.Source,
+		}
+		if item.SourceURL != "" {
+			pkg.DownloadLocation = item.SourceURL
+		}
+		switch {
+		case item.License == "":
+		case licenseExpression.MatchString(item.License):
+			pkg.LicenseDeclared = item.License
+		default:
+			pkg.LicenseComments = "declared license: " + item.License
+		}
+		if item.Commit != "" {
+			pkg.SourceInfo += " at commit " + item.Commit
+		}
+
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      doc.SPDXID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: pkg.SPDXID,
+		})
+	}
+	return doc
+}
+
+// newUUID returns a random version 4 UUID
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
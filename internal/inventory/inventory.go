@@ -0,0 +1,159 @@
+package inventory
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+)
+
+// Item is one installed agent in the inventory
+type Item struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Version is the agent's frontmatter version, or the source commit when it has none
+	Version   string `json:"version,omitempty"`
+	Commit    string `json:"commit,omitempty"`
+	Source    string `json:"source"`
+	SourceURL string `json:"source_url,omitempty"`
+	Path      string `json:"path"`
+	SHA256    string `json:"sha256"`
+	SHA1      string `json:"sha1"`
+	// License is the frontmatter license field, empty when the agent declares none
+	License string `json:"license,omitempty"`
+}
+
+// Collect builds the inventory of the installed agent files recorded in
+// installations, ordered by source and path. Sources that install other
+// artifact kinds are skipped, and files that no longer exist are reported
+// in missing rather than failing the inventory.
+func Collect(cfg *config.Config, installations map[string]*tracker.Installation) (items []Item, missing []string, err error) {
+	sources := make(map[string]config.Source, len(cfg.Sources))
+	for _, source := range cfg.Sources {
+		sources[source.Name] = source
+	}
+
+	names := make([]string, 0, len(installations))
+	for name := range installations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	extensions := cfg.Settings.Query.Index.Extensions
+	for _, name := range names {
+		source, configured := sources[name]
+		if configured && source.ArtifactKind() != config.KindAgent {
+			continue
+		}
+		installation := installations[name]
+
+		paths := make([]string, 0, len(installation.Files))
+		for path := range installation.Files {
+			if parser.IsAgentFile(path, extensions) {
+				paths = append(paths, path)
+			}
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			item, err := collectItem(path)
+			if os.IsNotExist(err) {
+				missing = append(missing, path)
+				continue
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			item.Source = name
+			item.Commit = installation.SourceCommit
+			if item.Version == "" {
+				item.Version = installation.SourceCommit
+			}
+			if configured {
+				item.SourceURL = SourceURL(source)
+			}
+			items = append(items, item)
+		}
+	}
+	return items, missing, nil
+}
+
+// collectItem hashes the agent file at path and reads its frontmatter
+func collectItem(path string) (Item, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Item{}, err
+	}
+
+	sum256 := sha256.Sum256(content)
+	sum1 := sha1.Sum(content) // SPDX requires a SHA1 checksum for every file
+	item := Item{
+		Name:   strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Path:   path,
+		SHA256: hex.EncodeToString(sum256[:]),
+		SHA1:   hex.EncodeToString(sum1[:]),
+	}
+
+	// Agents with unparseable frontmatter are still inventoried by file name
+	if fields, err := parser.FrontmatterFields(string(content)); err == nil {
+		if name := stringField(fields, "name"); name != "" {
+			item.Name = name
+		}
+		item.Description = stringField(fields, "description")
+		item.Version = stringField(fields, "version")
+		item.License = stringField(fields, "license")
+	}
+	return item, nil
+}
+
+// stringField returns a scalar frontmatter field as a string
+func stringField(fields map[string]interface{}, key string) string {
+	switch value := fields[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(value)
+	case map[string]interface{}, []interface{}:
+		return ""
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// SourceURL returns where a source's agents are downloaded from, or an empty
+// string when it has no URL
+func SourceURL(source config.Source) string {
+	switch source.Type {
+	case "github":
+		return "https://github.com/" + source.Repository
+	case "git", "archive":
+		return source.URL
+	case "gitlab":
+		if source.GitLabURL != "" {
+			return strings.TrimSuffix(source.GitLabURL, "/") + "/" + source.Repository
+		}
+		return "https://gitlab.com/" + source.Repository
+	case "github-release":
+		return "https://github.com/" + source.Repository + "/releases"
+	case "local":
+		if abs, err := filepath.Abs(source.Paths.Source); err == nil {
+			return "file://" + filepath.ToSlash(abs)
+		}
+		return ""
+	case "subagents":
+		if source.MarketplaceURL != "" {
+			return source.MarketplaceURL
+		}
+		return "https://subagents.sh"
+	default:
+		return ""
+	}
+}
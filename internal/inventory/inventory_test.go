@@ -0,0 +1,98 @@
+package inventory
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+)
+
+func TestCollectAndEncode(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	licensed := write("reviewer.md", "---\nname: code-reviewer\ndescription: Reviews code\nversion: 1.2.0\nlicense: MIT\n---\nPrompt\n")
+	unlicensed := write("writer.md", "---\nname: writer\ndescription: Writes docs\nlicense: see LICENSE file\n---\nPrompt\n")
+	style := write("style.md", "---\nname: terse\ndescription: Terse\n---\nBe brief\n")
+
+	cfg := &config.Config{Sources: []config.Source{
+		{Name: "team", Type: "github", Repository: "acme/agents"},
+		{Name: "styles", Type: "local", Kind: config.KindOutputStyle},
+	}}
+	installations := map[string]*tracker.Installation{
+		"team": {SourceCommit: "abc123", Files: map[string]tracker.FileInfo{
+			licensed:                        {},
+			unlicensed:                      {},
+			filepath.Join(dir, "gone.md"):   {},
+			filepath.Join(dir, "README.sh"): {},
+		}},
+		"styles": {Files: map[string]tracker.FileInfo{style: {}}},
+	}
+
+	items, missing, err := Collect(cfg, installations)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(items) != 2 || len(missing) != 1 {
+		t.Fatalf("Expected 2 agents and 1 missing file, got %+v and %v", items, missing)
+	}
+	reviewer, writer := items[0], items[1]
+	if reviewer.Name != "code-reviewer" || reviewer.Version != "1.2.0" || reviewer.License != "MIT" ||
+		reviewer.SourceURL != "https://github.com/acme/agents" || reviewer.Commit != "abc123" || len(reviewer.SHA256) != 64 {
+		t.Errorf("Unexpected reviewer item: %+v", reviewer)
+	}
+	if writer.Version != "abc123" {
+		t.Errorf("Expected the source commit as version without a frontmatter version, got %q", writer.Version)
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	data, err := Encode(FormatCycloneDX, items, now)
+	if err != nil {
+		t.Fatalf("Encode(cyclonedx) error = %v", err)
+	}
+	var bom cdxDocument
+	if err := json.Unmarshal(data, &bom); err != nil {
+		t.Fatalf("Invalid CycloneDX JSON: %v", err)
+	}
+	if bom.BOMFormat != "CycloneDX" || len(bom.Components) != 2 || bom.Metadata.Timestamp != "2024-05-01T12:00:00Z" {
+		t.Fatalf("Unexpected CycloneDX document: %s", data)
+	}
+	if licenses := bom.Components[0].Licenses; len(licenses) != 1 || licenses[0].Expression != "MIT" {
+		t.Errorf("Expected an MIT license expression, got %+v", licenses)
+	}
+	if licenses := bom.Components[1].Licenses; len(licenses) != 1 || licenses[0].License == nil || licenses[0].License.Name != "see LICENSE file" {
+		t.Errorf("Expected free-text license as a name, got %+v", licenses)
+	}
+
+	data, err = Encode(FormatSPDX, items, now)
+	if err != nil {
+		t.Fatalf("Encode(spdx) error = %v", err)
+	}
+	var doc spdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Invalid SPDX JSON: %v", err)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || len(doc.Packages) != 2 || len(doc.Relationships) != 2 {
+		t.Fatalf("Unexpected SPDX document: %s", data)
+	}
+	if pkg := doc.Packages[0]; pkg.LicenseDeclared != "MIT" || pkg.DownloadLocation != "https://github.com/acme/agents" || len(pkg.Checksums) != 2 {
+		t.Errorf("Unexpected SPDX package: %+v", pkg)
+	}
+	if pkg := doc.Packages[1]; pkg.LicenseDeclared != "NOASSERTION" || pkg.LicenseComments == "" {
+		t.Errorf("Expected free-text license in comments, got %+v", pkg)
+	}
+
+	if _, err := Encode("xml", items, now); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
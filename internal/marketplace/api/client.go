@@ -0,0 +1,335 @@
+// Package api reads the marketplace over plain HTTP, from the site's JSON
+// endpoints or the __NEXT_DATA__ embedded in its pages, so listing agents
+// needs neither a headless browser nor injected scripts.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/types"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+// ErrNoData is returned when neither the JSON endpoint nor the page carries
+// the requested data, e.g. after the site changed its layout
+var ErrNoData = errors.New("no marketplace data found in response")
+
+// maxResponseSize bounds each response read from the marketplace
+const maxResponseSize = 20 << 20
+
+// nextDataPattern matches the JSON state Next.js embeds in server rendered pages
+var nextDataPattern = regexp.MustCompile(`(?s)<script[^>]*id="__NEXT_DATA__"[^>]*>(.*?)</script>`)
+
+// Options configures the HTTP client
+type Options struct {
+	BaseURL   string
+	Timeout   time.Duration
+	UserAgent string
+	Headers   map[string]string // extra headers sent with every request
+}
+
+// Client fetches marketplace categories, agents and agent content over HTTP
+type Client struct {
+	baseURL   string
+	http      *http.Client
+	userAgent string
+	headers   map[string]string
+}
+
+// NewClient creates a marketplace HTTP client
+func NewClient(opts Options) *Client {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &Client{
+		baseURL:   strings.TrimSuffix(opts.BaseURL, "/"),
+		http:      &http.Client{Timeout: timeout},
+		userAgent: opts.UserAgent,
+		headers:   opts.Headers,
+	}
+}
+
+// Categories lists the marketplace categories
+func (c *Client) Categories(ctx context.Context) ([]types.Category, error) {
+	items, err := c.find(ctx, "categories", c.baseURL+"/api/categories", c.baseURL+"/categories")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch categories: %w", err)
+	}
+
+	var categories []types.Category
+	for _, item := range items {
+		category := types.Category{
+			Name:        util.GetString(item, "name"),
+			Description: util.GetString(item, "description"),
+			AgentCount:  firstInt(item, "agentCount", "agent_count", "count"),
+			URL:         c.absolute(util.GetString(item, "url")),
+			Slug:        util.GetString(item, "slug"),
+		}
+		if category.Name == "" {
+			continue
+		}
+		if category.Slug == "" {
+			category.Slug = util.ExtractSlugFromURL(category.URL)
+		}
+		if category.Slug == "" {
+			category.Slug = util.GenerateSlug(category.Name)
+		}
+		if category.URL == "" {
+			category.URL = fmt.Sprintf("%s/categories/%s", c.baseURL, category.Slug)
+		}
+		category.ID = category.Slug
+		categories = append(categories, category)
+	}
+	if len(categories) == 0 {
+		return nil, fmt.Errorf("failed to fetch categories: %w", ErrNoData)
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		return strings.ToLower(categories[i].Name) < strings.ToLower(categories[j].Name)
+	})
+	return categories, nil
+}
+
+// Agents lists the agents of a category
+func (c *Client) Agents(ctx context.Context, category string) ([]types.Agent, error) {
+	items, err := c.find(ctx, "agents",
+		fmt.Sprintf("%s/api/categories/%s/agents", c.baseURL, category),
+		fmt.Sprintf("%s/categories/%s", c.baseURL, category))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch agents of %s: %w", category, err)
+	}
+
+	var agents []types.Agent
+	for _, item := range items {
+		agent := types.Agent{
+			Name:        util.GetString(item, "name"),
+			Description: util.GetString(item, "description"),
+			Author:      util.GetString(item, "author"),
+			Rating:      util.GetFloat32(item, "rating"),
+			Downloads:   util.GetInt(item, "downloads"),
+			ContentURL:  c.absolute(util.GetString(item, "url")),
+			Category:    category,
+		}
+		if agent.Name == "" {
+			continue
+		}
+		agent.ID = util.GenerateSlug(agent.Name)
+		agent.Slug = agent.ID
+		if agent.ContentURL == "" {
+			slug := util.GetString(item, "slug")
+			if slug == "" {
+				slug = agent.Slug
+			}
+			agent.ContentURL = fmt.Sprintf("%s/agents/%s", c.baseURL, slug)
+		}
+		agents = append(agents, agent)
+	}
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("failed to fetch agents of %s: %w", category, ErrNoData)
+	}
+	return agents, nil
+}
+
+// Content returns the agent definition shown on an agent's detail page
+func (c *Client) Content(ctx context.Context, url string) (string, error) {
+	body, contentType, err := c.get(ctx, url)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch agent content: %w", err)
+	}
+
+	var data interface{}
+	if strings.Contains(contentType, "json") {
+		err = json.Unmarshal(body, &data)
+	} else {
+		data, err = nextData(body)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch agent content: %w", err)
+	}
+
+	// The definition is the longest content field; short ones are excerpts
+	var content string
+	walk(data, func(key string, value interface{}) bool {
+		if text, ok := value.(string); ok && isContentKey(key) && len(text) > len(content) {
+			content = text
+		}
+		return false
+	})
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("failed to fetch agent content: %w", ErrNoData)
+	}
+	return content, nil
+}
+
+// Ping checks the marketplace answers at all
+func (c *Client) Ping(ctx context.Context) error {
+	_, _, err := c.get(ctx, c.baseURL)
+	return err
+}
+
+// find returns the objects of the first array named key, read from the JSON
+// endpoint or, failing that, from the __NEXT_DATA__ of the page
+func (c *Client) find(ctx context.Context, key, endpoint, page string) ([]map[string]interface{}, error) {
+	var data interface{}
+	body, contentType, err := c.get(ctx, endpoint)
+	if err == nil && strings.Contains(contentType, "json") {
+		err = json.Unmarshal(body, &data)
+	}
+	if items := findArray(data, key); err == nil && len(items) > 0 {
+		return items, nil
+	}
+	util.DebugPrintf("No %s at %s (%v), reading %s\n", key, endpoint, err, page)
+
+	body, _, err = c.get(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = nextData(body); err != nil {
+		return nil, err
+	}
+	if items := findArray(data, key); len(items) > 0 {
+		return items, nil
+	}
+	return nil, ErrNoData
+}
+
+// get fetches url and returns its body and content type
+func (c *Client) get(ctx context.Context, url string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, "", fmt.Errorf("GET %s: %w", url, err)
+	}
+	return body, resp.Header.Get("Content-Type"), nil
+}
+
+// absolute resolves a site relative URL against the base URL
+func (c *Client) absolute(url string) string {
+	if strings.HasPrefix(url, "/") {
+		return c.baseURL + url
+	}
+	return url
+}
+
+// nextData decodes the __NEXT_DATA__ script of an HTML page
+func nextData(page []byte) (interface{}, error) {
+	match := nextDataPattern.FindSubmatch(page)
+	if match == nil {
+		return nil, ErrNoData
+	}
+	var data interface{}
+	if err := json.Unmarshal(match[1], &data); err != nil {
+		return nil, fmt.Errorf("invalid __NEXT_DATA__: %w", err)
+	}
+	return data, nil
+}
+
+// findArray returns the objects of the first non-empty array named key,
+// searched breadth first so page props win over nested references
+func findArray(data interface{}, key string) []map[string]interface{} {
+	queue := []interface{}{data}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		switch v := node.(type) {
+		case map[string]interface{}:
+			if items := objects(v[key]); len(items) > 0 {
+				return items
+			}
+			names := make([]string, 0, len(v))
+			for name := range v {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				queue = append(queue, v[name])
+			}
+		case []interface{}:
+			queue = append(queue, v...)
+		}
+	}
+	return nil
+}
+
+// objects returns the objects of a JSON array
+func objects(value interface{}) []map[string]interface{} {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	var items []map[string]interface{}
+	for _, item := range list {
+		if object, ok := item.(map[string]interface{}); ok {
+			items = append(items, object)
+		}
+	}
+	return items
+}
+
+// walk calls visit for every keyed value in data until visit returns true
+func walk(data interface{}, visit func(key string, value interface{}) bool) bool {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if visit(key, value) || walk(value, visit) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			if walk(value, visit) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isContentKey reports whether a field may hold an agent definition
+func isContentKey(key string) bool {
+	switch strings.ToLower(key) {
+	case "content", "definition", "markdown", "body":
+		return true
+	}
+	return false
+}
+
+// firstInt returns the first of keys set to a number in m
+func firstInt(m map[string]interface{}, keys ...string) int {
+	for _, key := range keys {
+		if n := util.GetInt(m, key); n != 0 {
+			return n
+		}
+	}
+	return 0
+}
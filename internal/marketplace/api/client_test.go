@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/categories", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"categories":[
+			{"name":"Web Development","slug":"web","agentCount":2},
+			{"name":"Data","url":"/categories/data","agent_count":"1"}]}}`))
+	})
+	// No JSON endpoint for agents, so they come from the page's __NEXT_DATA__
+	mux.HandleFunc("/categories/web", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><script id="__NEXT_DATA__" type="application/json">
+			{"props":{"pageProps":{"agents":[
+				{"name":"Frontend Dev","description":"Builds UIs","author":"a","rating":4.5},
+				{"name":"Api \u0026 Docs","url":"/agents/api-docs"}]}}}</script></body></html>`))
+	})
+	mux.HandleFunc("/agents/frontend-dev", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<script id="__NEXT_DATA__" type="application/json">
+			{"props":{"pageProps":{"agent":{"description":"short","content":"---\nname: frontend-dev\n---\nBuild UIs."}}}}</script>`))
+	})
+	mux.HandleFunc("/categories/empty", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body>client rendered</body></html>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewClient(Options{BaseURL: server.URL + "/", UserAgent: "test"})
+	ctx := context.Background()
+
+	categories, err := client.Categories(ctx)
+	if err != nil {
+		t.Fatalf("Categories() error = %v", err)
+	}
+	if len(categories) != 2 || categories[0].Slug != "data" || categories[0].AgentCount != 1 ||
+		categories[1].Slug != "web" || categories[1].URL != server.URL+"/categories/web" {
+		t.Errorf("Categories() = %+v", categories)
+	}
+
+	agents, err := client.Agents(ctx, "web")
+	if err != nil {
+		t.Fatalf("Agents() error = %v", err)
+	}
+	if len(agents) != 2 || agents[0].ID != "frontend-dev" || agents[0].Rating != 4.5 ||
+		agents[0].ContentURL != server.URL+"/agents/frontend-dev" ||
+		agents[1].Name != "Api & Docs" || agents[1].ContentURL != server.URL+"/agents/api-docs" {
+		t.Errorf("Agents() = %+v", agents)
+	}
+
+	content, err := client.Content(ctx, agents[0].ContentURL)
+	if err != nil {
+		t.Fatalf("Content() error = %v", err)
+	}
+	if !strings.HasPrefix(content, "---\nname: frontend-dev") {
+		t.Errorf("Content() = %q", content)
+	}
+
+	if _, err := client.Agents(ctx, "empty"); !errors.Is(err, ErrNoData) {
+		t.Errorf("Agents() of a page without data error = %v, want ErrNoData", err)
+	}
+	if _, err := client.Agents(ctx, "missing"); err == nil {
+		t.Error("Agents() of a missing page succeeded")
+	}
+}
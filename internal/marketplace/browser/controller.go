@@ -1,3 +1,5 @@
+//go:build !nobrowser
+
 package browser
 
 import (
@@ -6,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/chromedp"
 )
 
@@ -20,9 +23,9 @@ type ChromeController struct {
 
 // NewController creates a new browser controller
 func NewController(opts Options) (*ChromeController, error) {
-	execPath := findChromeExecutable()
-	if execPath == "" {
-		return nil, ErrChromeNotFound
+	execPath, _, err := Locate()
+	if err != nil {
+		return nil, err
 	}
 
 	allocOpts := buildChromeOptions(execPath, opts)
@@ -43,6 +46,7 @@ func NewController(opts Options) (*ChromeController, error) {
 // Navigate navigates to the specified URL
 func (c *ChromeController) Navigate(ctx context.Context, url string) error {
 	err := chromedp.Run(c.browserCtx,
+		c.extraHeaders(),
 		chromedp.Navigate(url),
 		chromedp.WaitReady("body"),
 		// Wait longer for dynamic content to load and retry if needed
@@ -56,6 +60,23 @@ func (c *ChromeController) Navigate(ctx context.Context, url string) error {
 	return nil
 }
 
+// extraHeaders sends the configured custom headers with every request of the page
+func (c *ChromeController) extraHeaders() chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if len(c.opts.Headers) == 0 {
+			return nil
+		}
+		headers := make(network.Headers, len(c.opts.Headers))
+		for name, value := range c.opts.Headers {
+			headers[name] = value
+		}
+		if err := network.Enable().Do(ctx); err != nil {
+			return err
+		}
+		return network.SetExtraHTTPHeaders(headers).Do(ctx)
+	})
+}
+
 // waitForContent waits for dynamic content to load on marketplace pages - HYBRID OPTIMIZED VERSION
 func (c *ChromeController) waitForContent(url string) chromedp.Action {
 	return chromedp.ActionFunc(func(ctx context.Context) error {
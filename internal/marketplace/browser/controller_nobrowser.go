@@ -0,0 +1,17 @@
+//go:build nobrowser
+
+package browser
+
+// NewController reports that the headless browser was left out of this build
+func NewController(opts Options) (Controller, error) {
+	_ = opts
+	return nil, ErrNotSupported
+}
+
+// supported reports whether this build includes the headless browser
+const supported = false
+
+// Locate reports that the headless browser was left out of this build
+func Locate() (string, *Runtime, error) {
+	return "", nil, ErrNotSupported
+}
@@ -1,3 +1,5 @@
+//go:build !nobrowser
+
 package browser
 
 import (
@@ -6,6 +8,31 @@ import (
 	"runtime"
 )
 
+// supported reports whether this build includes the headless browser
+const supported = true
+
+// Locate returns the browser executable the marketplace uses: the runtime
+// pinned by setup when there is one, otherwise a system Chrome or Chromium.
+// The pinned runtime is returned too, or nil when a system browser is used.
+func Locate() (string, *Runtime, error) {
+	if dir, err := RuntimeDir(); err == nil {
+		pinned, err := LoadRuntime(dir)
+		if err != nil {
+			return "", nil, err
+		}
+		if pinned != nil {
+			if err := pinned.Check(); err != nil {
+				return "", pinned, err
+			}
+			return pinned.Executable, pinned, nil
+		}
+	}
+	if path := findChromeExecutable(); path != "" {
+		return path, nil, nil
+	}
+	return "", nil, ErrChromeNotFound
+}
+
 // findChromeExecutable locates Chrome/Chromium/Brave executable
 func findChromeExecutable() string {
 	var candidates []string
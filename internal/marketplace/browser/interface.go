@@ -6,10 +6,13 @@ import (
 )
 
 var (
-	ErrChromeNotFound    = errors.New("chrome executable not found")
+	ErrChromeNotFound    = errors.New("chrome executable not found; install Chrome or Chromium, or run 'agent-manager marketplace setup'")
 	ErrBrowserClosed     = errors.New("browser context is closed")
 	ErrScriptExecution   = errors.New("script execution failed")
 	ErrNavigationTimeout = errors.New("navigation timeout")
+
+	// ErrNotSupported is returned by binaries built with the nobrowser tag
+	ErrNotSupported = errors.New("agent-manager was not built with marketplace support (nobrowser build)")
 )
 
 // Controller defines the interface for browser automation
@@ -26,6 +29,7 @@ type Options struct {
 	Headless     bool
 	Timeout      int // seconds
 	UserAgent    string
+	Headers      map[string]string // extra headers sent with every request
 	WindowWidth  int
 	WindowHeight int
 }
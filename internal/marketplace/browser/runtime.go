@@ -0,0 +1,297 @@
+package browser
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+// PinnedVersion is the Chrome for Testing headless shell version setup
+// installs unless another version is asked for
+const PinnedVersion = "131.0.6778.85"
+
+// DownloadBaseURL is where Chrome for Testing builds are downloaded from
+const DownloadBaseURL = "https://storage.googleapis.com/chrome-for-testing-public"
+
+// RuntimeDirEnv overrides the directory the managed browser runtime is kept in
+const RuntimeDirEnv = "AGENT_MANAGER_BROWSER_DIR"
+
+// runtimeFile records the pinned runtime inside the runtime directory
+const runtimeFile = "runtime.json"
+
+// maxRuntimeSize bounds the size of a downloaded or extracted runtime
+const maxRuntimeSize = 1 << 30
+
+// Runtime is a browser runtime installed by setup and pinned for the marketplace
+type Runtime struct {
+	Version     string    `json:"version"`
+	Platform    string    `json:"platform"`
+	Executable  string    `json:"executable"`
+	SHA256      string    `json:"sha256"` // of the archive it was installed from
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// SetupOptions configures installing the browser runtime
+type SetupOptions struct {
+	Version string // Chrome for Testing version; PinnedVersion when empty
+	Archive string // install from this local zip instead of downloading
+	SHA256  string // expected archive digest, checked when set
+	BaseURL string // DownloadBaseURL when empty
+	Client  *http.Client
+}
+
+// RuntimeDir returns the directory the managed browser runtime is kept in
+func RuntimeDir() (string, error) {
+	if dir := os.Getenv(RuntimeDirEnv); dir != "" {
+		return util.ExpandPath(dir)
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the user cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "agent-manager", "browser"), nil
+}
+
+// LoadRuntime reads the runtime pinned in dir; it returns nil when setup has
+// not been run
+func LoadRuntime(dir string) (*Runtime, error) {
+	data, err := os.ReadFile(filepath.Join(dir, runtimeFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read browser runtime: %w", err)
+	}
+	var pinned Runtime
+	if err := json.Unmarshal(data, &pinned); err != nil {
+		return nil, fmt.Errorf("invalid browser runtime file %s: %w", filepath.Join(dir, runtimeFile), err)
+	}
+	return &pinned, nil
+}
+
+// Check verifies the pinned executable is still in place
+func (r *Runtime) Check() error {
+	info, err := os.Stat(r.Executable)
+	if err != nil || info.IsDir() {
+		return fmt.Errorf("%w: pinned browser runtime %s is missing at %s; run 'agent-manager marketplace setup' to reinstall it",
+			ErrChromeNotFound, r.Version, r.Executable)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0100 == 0 {
+		return fmt.Errorf("pinned browser runtime %s is not executable: %s", r.Version, r.Executable)
+	}
+	return nil
+}
+
+// Platform returns the Chrome for Testing platform name of this system
+func Platform() (string, error) {
+	switch runtime.GOOS + "/" + runtime.GOARCH {
+	case "linux/amd64":
+		return "linux64", nil
+	case "darwin/arm64":
+		return "mac-arm64", nil
+	case "darwin/amd64":
+		return "mac-x64", nil
+	case "windows/amd64":
+		return "win64", nil
+	case "windows/386":
+		return "win32", nil
+	default:
+		return "", fmt.Errorf("no headless browser build for %s/%s; install Chrome or Chromium instead", runtime.GOOS, runtime.GOARCH)
+	}
+}
+
+// DownloadURL returns the download URL of the headless shell archive of a
+// version and platform
+func DownloadURL(baseURL, version, platform string) string {
+	return fmt.Sprintf("%s/%s/%s/chrome-headless-shell-%s.zip", strings.TrimRight(baseURL, "/"), version, platform, platform)
+}
+
+// Setup installs the headless shell into dir, from opts.Archive or by
+// downloading it, and pins it as the marketplace browser runtime. The pin is
+// replaced only once the new runtime is in place.
+func Setup(ctx context.Context, dir string, opts SetupOptions) (*Runtime, error) {
+	if !supported {
+		return nil, ErrNotSupported
+	}
+	version := opts.Version
+	if version == "" {
+		version = PinnedVersion
+	}
+	platform, err := Platform()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create browser runtime directory: %w", err)
+	}
+
+	archive := opts.Archive
+	if archive == "" {
+		baseURL := opts.BaseURL
+		if baseURL == "" {
+			baseURL = DownloadBaseURL
+		}
+		downloaded, err := downloadRuntime(ctx, opts.Client, DownloadURL(baseURL, version, platform), dir)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = os.Remove(downloaded) }()
+		archive = downloaded
+	}
+
+	digest, err := util.FileSHA256(archive)
+	if err != nil {
+		return nil, err
+	}
+	if opts.SHA256 != "" && !strings.EqualFold(opts.SHA256, digest) {
+		return nil, fmt.Errorf("browser runtime archive checksum mismatch: expected %s, got %s", opts.SHA256, digest)
+	}
+
+	executable := "chrome-headless-shell"
+	if runtime.GOOS == "windows" {
+		executable += ".exe"
+	}
+	executable = filepath.Join("chrome-headless-shell-"+platform, executable)
+
+	target := filepath.Join(dir, version+"-"+platform)
+	staging, err := os.MkdirTemp(dir, ".setup-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create browser runtime directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+	if err := extractRuntime(archive, staging); err != nil {
+		return nil, fmt.Errorf("failed to extract browser runtime: %w", err)
+	}
+	if _, err := os.Stat(filepath.Join(staging, executable)); err != nil {
+		return nil, fmt.Errorf("archive does not contain a headless shell for %s: %s is missing", platform, filepath.ToSlash(executable))
+	}
+	if err := os.RemoveAll(target); err != nil {
+		return nil, fmt.Errorf("failed to replace browser runtime: %w", err)
+	}
+	if err := os.Rename(staging, target); err != nil {
+		return nil, fmt.Errorf("failed to install browser runtime: %w", err)
+	}
+
+	pinned := &Runtime{
+		Version:     version,
+		Platform:    platform,
+		Executable:  filepath.Join(target, executable),
+		SHA256:      digest,
+		InstalledAt: time.Now().UTC(),
+	}
+	data, err := json.MarshalIndent(pinned, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode browser runtime: %w", err)
+	}
+	if err := util.WriteFileAtomic(filepath.Join(dir, runtimeFile), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to pin browser runtime: %w", err)
+	}
+	return pinned, nil
+}
+
+// downloadRuntime downloads url into a temporary file in dir
+func downloadRuntime(ctx context.Context, client *http.Client, url, dir string) (string, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to download browser runtime: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download browser runtime (use --archive to install a downloaded copy offline): %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download browser runtime from %s: %s", url, resp.Status)
+	}
+
+	file, err := os.CreateTemp(dir, ".download-*.zip")
+	if err != nil {
+		return "", fmt.Errorf("failed to download browser runtime: %w", err)
+	}
+	written, err := io.Copy(file, io.LimitReader(resp.Body, maxRuntimeSize+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && written > maxRuntimeSize {
+		err = fmt.Errorf("archive is larger than %d bytes", maxRuntimeSize)
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("failed to download browser runtime: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// extractRuntime unpacks a runtime zip into dir, keeping executable bits.
+// Entries escaping dir, links and special files are rejected or skipped.
+func extractRuntime(archive, dir string) error {
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+
+	var total int64
+	for _, entry := range reader.File {
+		name := filepath.FromSlash(entry.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("archive entry escapes the runtime directory: %s", entry.Name)
+		}
+		target := filepath.Join(dir, name)
+		mode := entry.Mode()
+		if mode.IsDir() {
+			if err := os.MkdirAll(target, 0750); err != nil {
+				return err
+			}
+			continue
+		}
+		if !mode.IsRegular() {
+			continue
+		}
+
+		written, err := extractRuntimeFile(entry, target, mode.Perm()&0755|0600, maxRuntimeSize-total)
+		if err != nil {
+			return err
+		}
+		total += written
+	}
+	return nil
+}
+
+// extractRuntimeFile writes one archive entry, failing once more than limit
+// bytes are written
+func extractRuntimeFile(entry *zip.File, target string, perm os.FileMode, limit int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+		return 0, err
+	}
+	src, err := entry.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = src.Close() }()
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return 0, err
+	}
+	written, err := io.Copy(dst, io.LimitReader(src, limit+1))
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && written > limit {
+		err = fmt.Errorf("archive expands beyond %d bytes", maxRuntimeSize)
+	}
+	return written, err
+}
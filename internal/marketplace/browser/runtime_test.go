@@ -0,0 +1,120 @@
+//go:build !nobrowser
+
+package browser
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeRuntimeZip writes a zip holding the given files, keyed by slash path
+func writeRuntimeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := zip.NewWriter(file)
+	for name, content := range files {
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		header.SetMode(0755)
+		entry, err := writer.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetup(t *testing.T) {
+	platform, err := Platform()
+	if err != nil {
+		t.Skip(err)
+	}
+	executable := "chrome-headless-shell"
+	if runtime.GOOS == "windows" {
+		executable += ".exe"
+	}
+	dir := t.TempDir()
+	archive := filepath.Join(t.TempDir(), "shell.zip")
+	writeRuntimeZip(t, archive, map[string]string{
+		"chrome-headless-shell-" + platform + "/" + executable: "#!/bin/sh\n",
+		"chrome-headless-shell-" + platform + "/libEGL.so":     "lib",
+	})
+
+	if _, err := Setup(context.Background(), dir, SetupOptions{Archive: archive, SHA256: strings.Repeat("0", 64)}); err == nil ||
+		!strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+
+	pinned, err := Setup(context.Background(), dir, SetupOptions{Archive: archive})
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	if pinned.Version != PinnedVersion || pinned.Platform != platform || len(pinned.SHA256) != 64 {
+		t.Errorf("Unexpected runtime: %+v", pinned)
+	}
+	if err := pinned.Check(); err != nil {
+		t.Errorf("Expected the pinned runtime to check out: %v", err)
+	}
+
+	loaded, err := LoadRuntime(dir)
+	if err != nil || loaded == nil || loaded.Executable != pinned.Executable {
+		t.Fatalf("Expected the pin to be saved, got %+v, %v", loaded, err)
+	}
+	t.Setenv(RuntimeDirEnv, dir)
+	if path, located, err := Locate(); err != nil || path != pinned.Executable || located == nil {
+		t.Errorf("Expected Locate to prefer the pinned runtime, got %s, %v", path, err)
+	}
+
+	if err := os.RemoveAll(filepath.Dir(pinned.Executable)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Locate(); !errors.Is(err, ErrChromeNotFound) || !strings.Contains(err.Error(), "marketplace setup") {
+		t.Errorf("Expected a missing pinned runtime to be reported, got %v", err)
+	}
+}
+
+func TestSetupRejectsBadArchives(t *testing.T) {
+	if _, err := Platform(); err != nil {
+		t.Skip(err)
+	}
+	dir := t.TempDir()
+
+	escaping := filepath.Join(t.TempDir(), "escaping.zip")
+	writeRuntimeZip(t, escaping, map[string]string{"../evil": "x"})
+	if _, err := Setup(context.Background(), dir, SetupOptions{Archive: escaping}); err == nil || !strings.Contains(err.Error(), "escapes") {
+		t.Errorf("Expected an escaping entry to be rejected, got %v", err)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.zip")
+	writeRuntimeZip(t, empty, map[string]string{"README": "nothing here"})
+	if _, err := Setup(context.Background(), dir, SetupOptions{Archive: empty}); err == nil || !strings.Contains(err.Error(), "does not contain") {
+		t.Errorf("Expected an archive without the shell to be rejected, got %v", err)
+	}
+	if pinned, _ := LoadRuntime(dir); pinned != nil {
+		t.Error("Expected nothing to be pinned after failed setups")
+	}
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	if _, err := Setup(context.Background(), dir, SetupOptions{Version: "1.0.0", BaseURL: server.URL}); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a failed download to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package browser
+
+import "context"
+
+// unavailableController stands in for a browser that could not be started,
+// failing every call with the reason
+type unavailableController struct {
+	err error
+}
+
+// Unavailable returns a Controller whose calls all fail with err, for
+// marketplaces read over HTTP that only need the browser as a fallback
+func Unavailable(err error) Controller {
+	return &unavailableController{err: err}
+}
+
+func (u *unavailableController) Navigate(ctx context.Context, url string) error {
+	return u.err
+}
+
+func (u *unavailableController) ExecuteScript(ctx context.Context, script string) (interface{}, error) {
+	return nil, u.err
+}
+
+func (u *unavailableController) WaitForElement(ctx context.Context, selector string) error {
+	return u.err
+}
+
+func (u *unavailableController) ScrollPage(ctx context.Context, offset int) error {
+	return u.err
+}
+
+func (u *unavailableController) Close() error {
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/pacphi/claude-code-agent-manager/internal/marketplace/api"
 	"github.com/pacphi/claude-code-agent-manager/internal/marketplace/browser"
 	"github.com/pacphi/claude-code-agent-manager/internal/marketplace/cache"
 	"github.com/pacphi/claude-code-agent-manager/internal/marketplace/extractors"
@@ -28,6 +29,10 @@ type ContainerConfig struct {
 	BrowserHeadless bool
 	BrowserTimeout  int
 	UserAgent       string
+	Headers         map[string]string
+	// Mode is auto, api or browser: whether the marketplace is read over
+	// HTTP, with the headless browser, or over HTTP with the browser as fallback
+	Mode string
 }
 
 // DefaultContainerConfig returns sensible defaults
@@ -40,27 +45,49 @@ func DefaultContainerConfig() ContainerConfig {
 		BrowserHeadless: true,
 		BrowserTimeout:  30,
 		UserAgent:       "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+		Mode:            service.ModeAuto,
 	}
 }
 
 // NewContainer creates a new dependency injection container
 func NewContainer(config ContainerConfig) (*Container, error) {
+	mode := config.Mode
+	if mode == "" {
+		mode = service.ModeAuto
+	}
+
 	// Create browser controller
 	browserOpts := browser.Options{
 		Headless:     config.BrowserHeadless,
 		Timeout:      config.BrowserTimeout,
 		UserAgent:    config.UserAgent,
+		Headers:      config.Headers,
 		WindowWidth:  1920,
 		WindowHeight: 1080,
 	}
 
-	util.DebugPrintf("Creating browser controller with options: %+v\n", browserOpts)
-	browserController, err := browser.NewController(browserOpts)
-	if err != nil {
-		util.DebugPrintf("Browser controller creation failed: %v\n", err)
-		return nil, fmt.Errorf("failed to create browser controller: %w", err)
+	// Header values may carry credentials, so only their count is logged
+	var browserController browser.Controller
+	if mode == service.ModeAPI {
+		browserController = browser.Unavailable(fmt.Errorf("%w: marketplace mode is api", ErrBrowserNotAvailable))
+	} else {
+		util.DebugPrintf("Creating browser controller (headless: %t, timeout: %ds, user agent: %q, headers: %d)\n",
+			browserOpts.Headless, browserOpts.Timeout, browserOpts.UserAgent, len(browserOpts.Headers))
+		controller, err := browser.NewController(browserOpts)
+		switch {
+		case err == nil:
+			browserController = controller
+			util.DebugPrintf("Browser controller created successfully\n")
+		case mode == service.ModeAuto:
+			// The HTTP client does not need the browser, so a missing one only
+			// fails the reads that fall back on it
+			util.DebugPrintf("Browser controller creation failed, using the marketplace API only: %v\n", err)
+			browserController = browser.Unavailable(err)
+		default:
+			util.DebugPrintf("Browser controller creation failed: %v\n", err)
+			return nil, fmt.Errorf("failed to create browser controller: %w", err)
+		}
 	}
-	util.DebugPrintf("Browser controller created successfully\n")
 
 	// Create cache manager
 	cacheConfig := cache.Config{
@@ -77,6 +104,14 @@ func NewContainer(config ContainerConfig) (*Container, error) {
 	// Create extractors
 	extractorFactory := extractors.NewFactory()
 	extractorSet := extractorFactory.CreateExtractorSet()
+	if mode != service.ModeBrowser {
+		extractorSet.API = api.NewClient(api.Options{
+			BaseURL:   config.BaseURL,
+			Timeout:   time.Duration(config.BrowserTimeout) * time.Second,
+			UserAgent: config.UserAgent,
+			Headers:   config.Headers,
+		})
+	}
 
 	// Create service
 	serviceConfig := service.Config{
@@ -85,6 +120,7 @@ func NewContainer(config ContainerConfig) (*Container, error) {
 		CacheTTL:       time.Duration(config.CacheTTLHours) * time.Hour,
 		RequestTimeout: time.Duration(config.BrowserTimeout) * time.Second,
 		UserAgent:      config.UserAgent,
+		Mode:           mode,
 	}
 
 	marketplaceService := service.NewMarketplaceService(
@@ -23,6 +23,7 @@ type MarketplaceService interface {
 	// Agents
 	GetAgents(ctx context.Context, category string) ([]types.Agent, error)
 	GetAgent(ctx context.Context, agentID string) (*types.Agent, error)
+	SearchAgents(ctx context.Context, query SearchQuery) ([]types.Agent, error)
 
 	// Content
 	GetAgentContent(ctx context.Context, agentID string) (string, error)
@@ -51,6 +52,24 @@ type ExtractorSet struct {
 	Categories CategoryExtractor
 	Agents     AgentExtractor
 	Content    ContentExtractor
+	// API reads the marketplace over HTTP; when set it is tried before the
+	// browser extractors unless the service runs in browser mode
+	API APIClient
+}
+
+// Marketplace modes select how the service reads the marketplace
+const (
+	ModeAuto    = "auto"    // HTTP first, the browser as fallback
+	ModeAPI     = "api"     // HTTP only
+	ModeBrowser = "browser" // the headless browser only
+)
+
+// APIClient reads marketplace data over plain HTTP, without the browser
+type APIClient interface {
+	Categories(ctx context.Context) ([]types.Category, error)
+	Agents(ctx context.Context, category string) ([]types.Agent, error)
+	Content(ctx context.Context, url string) (string, error)
+	Ping(ctx context.Context) error
 }
 
 // CategoryExtractor extracts category data
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -29,6 +30,8 @@ type Config struct {
 	CacheTTL       time.Duration
 	RequestTimeout time.Duration
 	UserAgent      string
+	// Mode is ModeAuto, ModeAPI or ModeBrowser; empty means ModeBrowser
+	Mode string
 }
 
 // NewMarketplaceService creates a new marketplace service
@@ -47,6 +50,21 @@ func NewMarketplaceService(
 	}
 }
 
+// useAPI reports whether the HTTP client is tried before the browser
+func (s *marketplaceService) useAPI() bool {
+	return s.extractors.API != nil && s.config.Mode != "" && s.config.Mode != ModeBrowser
+}
+
+// fallback decides what follows a failed HTTP read: nil lets the browser try
+// next in auto mode, otherwise the error is returned
+func (s *marketplaceService) fallback(ctx context.Context, err error) error {
+	if s.config.Mode == ModeAPI || ctx.Err() != nil {
+		return err
+	}
+	util.DebugPrintf("Marketplace API failed, falling back to the browser: %v\n", err)
+	return nil
+}
+
 // GetCategories retrieves all marketplace categories
 func (s *marketplaceService) GetCategories(ctx context.Context) ([]types.Category, error) {
 	util.DebugPrintf("GetCategories called\n")
@@ -67,6 +85,16 @@ func (s *marketplaceService) GetCategories(ctx context.Context) ([]types.Categor
 	var categories []types.Category
 
 	err := pm.WithSpinner("Fetching marketplace categories", func() error {
+		if s.useAPI() {
+			var apiErr error
+			if categories, apiErr = s.extractors.API.Categories(ctx); apiErr == nil {
+				return nil
+			}
+			if err := s.fallback(ctx, apiErr); err != nil {
+				return err
+			}
+		}
+
 		categoriesURL := fmt.Sprintf("%s/categories", s.baseURL)
 		util.DebugPrintf("Navigating to: %s\n", categoriesURL)
 		if err := s.browser.Navigate(ctx, categoriesURL); err != nil {
@@ -124,6 +152,16 @@ func (s *marketplaceService) GetAgents(ctx context.Context, category string) ([]
 	var agents []types.Agent
 
 	err := pm.WithSpinner(fmt.Sprintf("Fetching agents from %s", category), func() error {
+		if s.useAPI() {
+			var apiErr error
+			if agents, apiErr = s.extractors.API.Agents(ctx, category); apiErr == nil {
+				return nil
+			}
+			if err := s.fallback(ctx, apiErr); err != nil {
+				return err
+			}
+		}
+
 		// Navigate to category page
 		categoryURL := fmt.Sprintf("%s/categories/%s", s.baseURL, category)
 		if err := s.browser.Navigate(ctx, categoryURL); err != nil {
@@ -228,6 +266,108 @@ func (s *marketplaceService) GetAgent(ctx context.Context, agentID string) (*typ
 	return nil, ErrAgentNotFound
 }
 
+// SearchAgents finds the agents whose name, slug, description, author or
+// category contain every word of the query, in one category or across all of
+// them. Results are ranked by where the query matched (names first) and then
+// by SortBy, rating by default.
+func (s *marketplaceService) SearchAgents(ctx context.Context, query SearchQuery) ([]types.Agent, error) {
+	categories := []string{query.Category}
+	if query.Category == "" {
+		all, err := s.GetCategories(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get categories: %w", err)
+		}
+		categories = categories[:0]
+		for _, category := range all {
+			categories = append(categories, category.Slug)
+		}
+	}
+
+	words := strings.Fields(strings.ToLower(query.Query))
+	seen := make(map[string]bool)
+	var matches []types.Agent
+	rank := make(map[string]int)
+	for _, category := range categories {
+		agents, err := s.GetAgents(ctx, category)
+		if err != nil {
+			if query.Category != "" || ctx.Err() != nil {
+				return nil, err
+			}
+			util.DebugPrintf("Skipping category %s in search: %v\n", category, err)
+			continue
+		}
+		for _, agent := range agents {
+			if seen[agent.ID] || agent.Rating < query.MinRating {
+				continue
+			}
+			score, ok := searchScore(agent, words)
+			if !ok {
+				continue
+			}
+			seen[agent.ID] = true
+			rank[agent.ID] = score
+			matches = append(matches, agent)
+		}
+	}
+
+	// Names sort ascending and the other fields best first unless told otherwise
+	ascending := query.SortOrder == "asc" || (query.SortOrder == "" && query.SortBy == "name")
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if rank[a.ID] != rank[b.ID] {
+			return rank[a.ID] > rank[b.ID]
+		}
+		less, equal := compareAgents(a, b, query.SortBy)
+		if equal {
+			return a.Name < b.Name
+		}
+		if ascending {
+			return less
+		}
+		return !less
+	})
+
+	if query.Limit > 0 && len(matches) > query.Limit {
+		matches = matches[:query.Limit]
+	}
+	return matches, nil
+}
+
+// searchScore reports whether agent contains every word, scoring words found
+// in the name or slug above those only in the other fields
+func searchScore(agent types.Agent, words []string) (int, bool) {
+	name := strings.ToLower(agent.Name + " " + agent.Slug)
+	other := strings.ToLower(agent.Description + " " + agent.Author + " " + agent.Category + " " + strings.Join(agent.Tags, " "))
+	score := 0
+	for _, word := range words {
+		switch {
+		case strings.Contains(name, word):
+			score += 2
+		case strings.Contains(other, word):
+			score++
+		default:
+			return 0, false
+		}
+	}
+	return score, true
+}
+
+// compareAgents orders two agents by field (rating, downloads, name or
+// date), reporting whether a sorts before b in ascending order and whether
+// they are equal
+func compareAgents(a, b types.Agent, field string) (less, equal bool) {
+	switch field {
+	case "name":
+		return a.Name < b.Name, a.Name == b.Name
+	case "downloads":
+		return a.Downloads < b.Downloads, a.Downloads == b.Downloads
+	case "date":
+		return a.UpdatedAt.Before(b.UpdatedAt), a.UpdatedAt.Equal(b.UpdatedAt)
+	default:
+		return a.Rating < b.Rating, a.Rating == b.Rating
+	}
+}
+
 // GetAgentContent retrieves the full content/definition of an agent
 func (s *marketplaceService) GetAgentContent(ctx context.Context, agentID string) (string, error) {
 	agent, err := s.GetAgent(ctx, agentID)
@@ -246,6 +386,10 @@ func (s *marketplaceService) GetAgentContent(ctx context.Context, agentID string
 		// Try to find the actual agent detail page URL by navigation
 		detailURL, err = s.findAgentDetailURL(ctx, agent.Name)
 		if err != nil {
+			// A timed out or cancelled download must not pass for the agent's content
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
 			util.DebugPrintf("Failed to find agent detail URL for %s: %v\n", agentID, err)
 			return agent.Description, nil
 		}
@@ -261,7 +405,18 @@ func (s *marketplaceService) GetAgentContent(ctx context.Context, agentID string
 	}
 
 	// Use the content extractor to get the agent definition
-	content, err := s.extractors.Content.Extract(ctx, s.browser, detailURL)
+	var content string
+	if s.useAPI() {
+		if content, err = s.extractors.API.Content(ctx, detailURL); err != nil {
+			err = s.fallback(ctx, err)
+		}
+	}
+	if content == "" && err == nil {
+		content, err = s.extractors.Content.Extract(ctx, s.browser, detailURL)
+	}
+	if err != nil && ctx.Err() != nil {
+		return "", ctx.Err()
+	}
 	if err != nil {
 		// Log the error but don't fail completely
 		util.DebugPrintf("Failed to extract content for agent %s from URL %s: %v\n", agentID, detailURL, err)
@@ -278,6 +433,18 @@ func (s *marketplaceService) GetAgentContent(ctx context.Context, agentID string
 	return content, nil
 }
 
+// sleepContext waits for d, returning early with the context error when ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 // findAgentDetailURL attempts to find the actual detail page URL for an agent
 func (s *marketplaceService) findAgentDetailURL(ctx context.Context, agentName string) (string, error) {
 	// Strategy 1: Navigate and click approach for agents page
@@ -288,7 +455,9 @@ func (s *marketplaceService) findAgentDetailURL(ctx context.Context, agentName s
 
 	// Wait for page to load
 	util.DebugPrintf("Waiting for agents page to load...\n")
-	time.Sleep(3 * time.Second)
+	if err := sleepContext(ctx, 3*time.Second); err != nil {
+		return "", err
+	}
 
 	// Execute a script to find and click the agent card
 	clickScript := fmt.Sprintf(`
@@ -360,7 +529,9 @@ func (s *marketplaceService) findAgentDetailURL(ctx context.Context, agentName s
 	if result == "CLICKED" {
 		// Wait for navigation to complete
 		util.DebugPrintf("Waiting for navigation after click...\n")
-		time.Sleep(2 * time.Second)
+		if err := sleepContext(ctx, 2*time.Second); err != nil {
+			return "", err
+		}
 
 		// Get the current URL
 		getCurrentURLScript := `window.location.href`
@@ -402,6 +573,16 @@ func (s *marketplaceService) GetCacheStats() interface{} {
 
 // HealthCheck verifies the service is operational
 func (s *marketplaceService) HealthCheck(ctx context.Context) error {
+	if s.useAPI() {
+		err := s.extractors.API.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+		if err := s.fallback(ctx, err); err != nil {
+			return fmt.Errorf("marketplace unreachable: %w", err)
+		}
+	}
+
 	// Try to navigate to the base URL
 	if err := s.browser.Navigate(ctx, s.baseURL); err != nil {
 		return fmt.Errorf("marketplace unreachable: %w", err)
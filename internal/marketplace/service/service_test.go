@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/pacphi/claude-code-agent-manager/internal/marketplace/browser"
@@ -180,8 +181,140 @@ func TestMarketplaceService_GetAgents(t *testing.T) {
 	}
 }
 
+func TestMarketplaceService_Mode(t *testing.T) {
+	apiCategories := []types.Category{
+		{ID: "api", Name: "API", Slug: "api"},
+		{ID: "web", Name: "Web", Slug: "web"},
+	}
+
+	tests := []struct {
+		name           string
+		mode           string
+		apiErr         error
+		wantErr        bool
+		wantCategories int
+		wantNavigate   bool
+	}{
+		{name: "auto_uses_api", mode: ModeAuto, wantCategories: 2},
+		{name: "auto_falls_back_to_browser", mode: ModeAuto, apiErr: errors.New("no data"), wantCategories: 1, wantNavigate: true},
+		{name: "api_does_not_fall_back", mode: ModeAPI, apiErr: errors.New("no data"), wantErr: true},
+		{name: "browser_skips_api", mode: ModeBrowser, wantCategories: 1, wantNavigate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBrowser := browser.NewMockController()
+			mockCache := cache.NewMockManager()
+			mockCache.SetDisabled(true)
+
+			service := &marketplaceService{
+				browser: mockBrowser,
+				cache:   mockCache,
+				extractors: ExtractorSet{
+					Categories: &mockCategoryExtractor{},
+					API:        &mockAPIClient{categories: apiCategories, err: tt.apiErr},
+				},
+				baseURL: "https://test.com",
+				config:  Config{Mode: tt.mode},
+			}
+
+			categories, err := service.GetCategories(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(categories) != tt.wantCategories {
+				t.Errorf("Expected %d categories, got %d", tt.wantCategories, len(categories))
+			}
+			if navigated := len(mockBrowser.GetNavigateCalls()) > 0; navigated != tt.wantNavigate {
+				t.Errorf("Expected browser navigation %t, got %t", tt.wantNavigate, navigated)
+			}
+		})
+	}
+}
+
+func TestMarketplaceService_SearchAgents(t *testing.T) {
+	mockCache := cache.NewMockManager()
+	mockCache.SetDisabled(true)
+	service := &marketplaceService{
+		browser: browser.NewMockController(),
+		cache:   mockCache,
+		extractors: ExtractorSet{
+			Categories: &mockCategoryExtractor{categories: []types.Category{
+				{ID: "devops", Name: "DevOps", Slug: "devops"},
+				{ID: "cloud", Name: "Cloud", Slug: "cloud"},
+			}},
+			Agents: &mockAgentExtractor{agents: map[string][]types.Agent{
+				"devops": {
+					{ID: "terraform-expert", Slug: "terraform-expert", Name: "Terraform Expert", Rating: 4.1, Category: "devops"},
+					{ID: "ci-helper", Slug: "ci-helper", Name: "CI Helper", Description: "Runs terraform plans in CI", Rating: 4.9, Category: "devops"},
+				},
+				"cloud": {
+					{ID: "terraform-modules", Slug: "terraform-modules", Name: "Terraform Modules", Rating: 4.6, Downloads: 10, Category: "cloud"},
+					{ID: "aws-architect", Slug: "aws-architect", Name: "AWS Architect", Rating: 5, Category: "cloud"},
+				},
+			}},
+		},
+		baseURL: "https://test.com",
+	}
+
+	tests := []struct {
+		name  string
+		query SearchQuery
+		want  []string
+	}{
+		{"name matches rank first", SearchQuery{Query: "terraform"}, []string{"terraform-modules", "terraform-expert", "ci-helper"}},
+		{"all words must match", SearchQuery{Query: "terraform ci"}, []string{"ci-helper"}},
+		{"one category", SearchQuery{Query: "terraform", Category: "devops"}, []string{"terraform-expert", "ci-helper"}},
+		{"minimum rating and limit", SearchQuery{Query: "terraform", MinRating: 4.5, Limit: 1}, []string{"terraform-modules"}},
+		{"sorted by name", SearchQuery{Query: "terraform", SortBy: "name"}, []string{"terraform-expert", "terraform-modules", "ci-helper"}},
+		{"no match", SearchQuery{Query: "kubernetes"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agents, err := service.SearchAgents(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("SearchAgents() error = %v", err)
+			}
+			var got []string
+			for _, agent := range agents {
+				got = append(got, agent.Slug)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("SearchAgents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 // Mock implementations
 
+type mockAPIClient struct {
+	categories []types.Category
+	err        error
+}
+
+func (m *mockAPIClient) Categories(ctx context.Context) ([]types.Category, error) {
+	return m.categories, m.err
+}
+
+func (m *mockAPIClient) Agents(ctx context.Context, category string) ([]types.Agent, error) {
+	return nil, m.err
+}
+
+func (m *mockAPIClient) Content(ctx context.Context, url string) (string, error) {
+	return "", m.err
+}
+
+func (m *mockAPIClient) Ping(ctx context.Context) error {
+	return m.err
+}
+
 type mockCategoryExtractor struct {
 	categories []types.Category
 	err        error
@@ -0,0 +1,219 @@
+// Package metrics writes run metrics in the Prometheus text format for
+// node_exporter's textfile collector, and keeps the opt-in local usage record.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+// Metric names written to the textfile
+const (
+	lastRunTimestamp = "agent_manager_last_run_timestamp_seconds"
+	lastRunDuration  = "agent_manager_last_run_duration_seconds"
+	lastRunSuccess   = "agent_manager_last_run_success"
+	runsTotal        = "agent_manager_runs_total"
+	errorsTotal      = "agent_manager_errors_total"
+	agentsInstalled  = "agent_manager_agents_installed"
+	updateAvailable  = "agent_manager_update_available"
+)
+
+// sampleLine matches the single-label samples this package writes
+var sampleLine = regexp.MustCompile(`^(\w+)\{(\w+)="((?:[^"\\]|\\.)*)"\} (\S+)$`)
+
+// CommandStats are the metrics of one command
+type CommandStats struct {
+	LastRun  time.Time
+	Duration time.Duration
+	Success  bool
+	Runs     int
+	Errors   int
+}
+
+// Snapshot is the state written to the textfile. Values of earlier runs are
+// read back from the previous file, so counters keep counting and commands
+// not run this time keep their last values.
+type Snapshot struct {
+	Commands        map[string]*CommandStats
+	Installed       map[string]int
+	UpdateAvailable map[string]bool
+}
+
+// NewSnapshot creates an empty snapshot
+func NewSnapshot() *Snapshot {
+	return &Snapshot{
+		Commands:        make(map[string]*CommandStats),
+		Installed:       make(map[string]int),
+		UpdateAvailable: make(map[string]bool),
+	}
+}
+
+// Load reads the snapshot previously written to path; a missing file yields
+// an empty snapshot
+func Load(path string) (*Snapshot, error) {
+	snapshot := NewSnapshot()
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return snapshot, nil
+		}
+		return nil, fmt.Errorf("failed to read metrics file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		match := sampleLine.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if match == nil {
+			continue
+		}
+		value, err := strconv.ParseFloat(match[4], 64)
+		if err != nil {
+			continue
+		}
+		snapshot.set(match[1], unescapeLabel(match[3]), value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read metrics file: %w", err)
+	}
+	return snapshot, nil
+}
+
+// set restores one sample read from the textfile
+func (s *Snapshot) set(name, label string, value float64) {
+	switch name {
+	case agentsInstalled:
+		s.Installed[label] = int(value)
+		return
+	case updateAvailable:
+		s.UpdateAvailable[label] = value != 0
+		return
+	}
+
+	stats := s.command(label)
+	switch name {
+	case lastRunTimestamp:
+		stats.LastRun = time.Unix(0, int64(value*float64(time.Second)))
+	case lastRunDuration:
+		stats.Duration = time.Duration(value * float64(time.Second))
+	case lastRunSuccess:
+		stats.Success = value != 0
+	case runsTotal:
+		stats.Runs = int(value)
+	case errorsTotal:
+		stats.Errors = int(value)
+	}
+}
+
+// command returns the stats of a command, creating them if needed
+func (s *Snapshot) command(name string) *CommandStats {
+	stats, ok := s.Commands[name]
+	if !ok {
+		stats = &CommandStats{}
+		s.Commands[name] = stats
+	}
+	return stats
+}
+
+// RecordRun records a run of command that started at start and ended with runErr
+func (s *Snapshot) RecordRun(command string, start time.Time, duration time.Duration, runErr error) {
+	stats := s.command(command)
+	stats.LastRun = start
+	stats.Duration = duration
+	stats.Success = runErr == nil
+	stats.Runs++
+	if runErr != nil {
+		stats.Errors++
+	}
+}
+
+// Write writes the snapshot to path in the Prometheus text format. The file
+// is replaced atomically so the collector never reads a partial file.
+func (s *Snapshot) Write(path string) error {
+	var b strings.Builder
+	commands := sortedKeys(s.Commands)
+
+	family(&b, lastRunTimestamp, "gauge", "Unix time the command last ran.")
+	for _, name := range commands {
+		sample(&b, lastRunTimestamp, "command", name, float64(s.Commands[name].LastRun.UnixNano())/float64(time.Second))
+	}
+	family(&b, lastRunDuration, "gauge", "Duration of the command's last run in seconds.")
+	for _, name := range commands {
+		sample(&b, lastRunDuration, "command", name, s.Commands[name].Duration.Seconds())
+	}
+	family(&b, lastRunSuccess, "gauge", "Whether the command's last run succeeded.")
+	for _, name := range commands {
+		sample(&b, lastRunSuccess, "command", name, boolValue(s.Commands[name].Success))
+	}
+	family(&b, runsTotal, "counter", "Runs of the command.")
+	for _, name := range commands {
+		sample(&b, runsTotal, "command", name, float64(s.Commands[name].Runs))
+	}
+	family(&b, errorsTotal, "counter", "Runs of the command that failed.")
+	for _, name := range commands {
+		sample(&b, errorsTotal, "command", name, float64(s.Commands[name].Errors))
+	}
+	family(&b, agentsInstalled, "gauge", "Agent files installed from the source.")
+	for _, name := range sortedKeys(s.Installed) {
+		sample(&b, agentsInstalled, "source", name, float64(s.Installed[name]))
+	}
+	family(&b, updateAvailable, "gauge", "Whether the last update check found an update for the source.")
+	for _, name := range sortedKeys(s.UpdateAvailable) {
+		sample(&b, updateAvailable, "source", name, boolValue(s.UpdateAvailable[name]))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+	if err := util.WriteFileAtomic(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	return nil
+}
+
+// family writes the HELP and TYPE lines of a metric
+func family(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes one sample with a single label
+func sample(b *strings.Builder, name, label, value string, v float64) {
+	fmt.Fprintf(b, "%s{%s=\"%s\"} %s\n", name, label, escapeLabel(value), strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+// escapeLabel escapes a label value for the text format
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// unescapeLabel reverses escapeLabel
+func unescapeLabel(value string) string {
+	return strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\n`, "\n").Replace(value)
+}
+
+// boolValue returns 1 for true and 0 for false
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "textfile", "agent_manager.prom")
+
+	snapshot, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load of a missing file failed: %v", err)
+	}
+	start := time.Unix(1700000000, 500000000)
+	snapshot.RecordRun("install", start, 1500*time.Millisecond, nil)
+	snapshot.RecordRun("update", start, time.Second, errors.New("network down"))
+	snapshot.Installed["team"] = 3
+	snapshot.UpdateAvailable[`odd"name`] = true
+	if err := snapshot.Write(path); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# TYPE agent_manager_runs_total counter\n",
+		`agent_manager_last_run_timestamp_seconds{command="install"} 1.7000000005e+09`,
+		`agent_manager_last_run_duration_seconds{command="install"} 1.5`,
+		`agent_manager_last_run_success{command="update"} 0`,
+		`agent_manager_errors_total{command="update"} 1`,
+		`agent_manager_agents_installed{source="team"} 3`,
+		`agent_manager_update_available{source="odd\"name"} 1`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected %q in:\n%s", want, content)
+		}
+	}
+
+	// Counters keep counting across runs
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	reloaded.RecordRun("update", start, time.Second, nil)
+	update := reloaded.Commands["update"]
+	if update.Runs != 2 || update.Errors != 1 || !update.Success {
+		t.Errorf("Expected 2 runs, 1 error and a successful last run, got %+v", update)
+	}
+	if install := reloaded.Commands["install"]; !install.LastRun.Equal(start) || install.Duration != 1500*time.Millisecond {
+		t.Errorf("Expected the install run restored, got %+v", install)
+	}
+	if !reloaded.UpdateAvailable[`odd"name`] || reloaded.Installed["team"] != 3 {
+		t.Errorf("Expected source values restored, got %+v %+v", reloaded.UpdateAvailable, reloaded.Installed)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files left, got %d entries", len(entries))
+	}
+}
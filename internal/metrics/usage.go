@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+// CommandUsage aggregates the runs of one command. Only the command name,
+// durations and error categories are kept: never arguments, paths or messages.
+type CommandUsage struct {
+	Runs    int           `json:"runs"`
+	Errors  int           `json:"errors"`
+	Total   time.Duration `json:"total_ns"`
+	Max     time.Duration `json:"max_ns"`
+	LastRun time.Time     `json:"last_run"`
+	// ErrorCategories counts failed runs by the category of their error
+	ErrorCategories map[string]int `json:"error_categories,omitempty"`
+}
+
+// Average returns the mean duration of the command's runs
+func (c *CommandUsage) Average() time.Duration {
+	if c.Runs == 0 {
+		return 0
+	}
+	return c.Total / time.Duration(c.Runs)
+}
+
+// Usage is the local record of command invocations. It is only ever written
+// to a local file and is never sent anywhere.
+type Usage struct {
+	Since    time.Time                `json:"since"`
+	Commands map[string]*CommandUsage `json:"commands"`
+}
+
+// NewUsage creates an empty usage record
+func NewUsage() *Usage {
+	return &Usage{Commands: make(map[string]*CommandUsage)}
+}
+
+// LoadUsage reads the usage record at path; a missing file yields an empty
+// record
+func LoadUsage(path string) (*Usage, error) {
+	usage := NewUsage()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return usage, nil
+		}
+		return nil, fmt.Errorf("failed to read usage file: %w", err)
+	}
+	if err := json.Unmarshal(data, usage); err != nil {
+		return nil, fmt.Errorf("failed to parse usage file: %w", err)
+	}
+	if usage.Commands == nil {
+		usage.Commands = make(map[string]*CommandUsage)
+	}
+	return usage, nil
+}
+
+// Record adds a run of command that started at start and took duration.
+// category is empty for runs that succeeded.
+func (u *Usage) Record(command string, start time.Time, duration time.Duration, category string) {
+	if u.Since.IsZero() {
+		u.Since = start
+	}
+	stats, ok := u.Commands[command]
+	if !ok {
+		stats = &CommandUsage{}
+		u.Commands[command] = stats
+	}
+	stats.Runs++
+	stats.Total += duration
+	if duration > stats.Max {
+		stats.Max = duration
+	}
+	stats.LastRun = start
+	if category != "" {
+		stats.Errors++
+		if stats.ErrorCategories == nil {
+			stats.ErrorCategories = make(map[string]int)
+		}
+		stats.ErrorCategories[category]++
+	}
+}
+
+// Slowest returns the recorded command names, longest average run first and
+// ties in name order
+func (u *Usage) Slowest() []string {
+	names := sortedKeys(u.Commands)
+	sort.SliceStable(names, func(i, j int) bool {
+		return u.Commands[names[i]].Average() > u.Commands[names[j]].Average()
+	})
+	return names
+}
+
+// Write writes the usage record to path, replacing it atomically
+func (u *Usage) Write(path string) error {
+	data, err := json.MarshalIndent(u, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode usage: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create usage directory: %w", err)
+	}
+	if err := util.WriteFileAtomic(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUsageRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".agent-usage")
+
+	usage, err := LoadUsage(path)
+	if err != nil {
+		t.Fatalf("LoadUsage of a missing file failed: %v", err)
+	}
+	start := time.Unix(1700000000, 0)
+	usage.Record("query", start, 100*time.Millisecond, "")
+	usage.Record("install", start, 3*time.Second, "network")
+	usage.Record("install", start.Add(time.Hour), time.Second, "")
+	if err := usage.Write(path); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	reloaded, err := LoadUsage(path)
+	if err != nil {
+		t.Fatalf("LoadUsage failed: %v", err)
+	}
+	reloaded.Record("install", start.Add(2*time.Hour), 2*time.Second, "network")
+
+	install := reloaded.Commands["install"]
+	if install.Runs != 3 || install.Errors != 2 || install.Max != 3*time.Second || install.Average() != 2*time.Second {
+		t.Errorf("Expected 3 runs, 2 errors, 3s max and 2s average, got %+v", install)
+	}
+	if install.ErrorCategories["network"] != 2 {
+		t.Errorf("Expected 2 network errors, got %v", install.ErrorCategories)
+	}
+	if !reloaded.Since.Equal(start) {
+		t.Errorf("Expected the first run kept as the start of the record, got %v", reloaded.Since)
+	}
+	if got := reloaded.Slowest(); !reflect.DeepEqual(got, []string{"install", "query"}) {
+		t.Errorf("Expected slowest commands first, got %v", got)
+	}
+}
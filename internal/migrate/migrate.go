@@ -0,0 +1,247 @@
+// Package migrate moves state left behind by older versions to where the
+// current version expects it, so upgrading never loses tracking state.
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+// StateFileName is the file next to the tracking file recording the applied migrations
+const StateFileName = ".migrations.json"
+
+// Migration moves files from an older layout to the current one
+type Migration struct {
+	Version     int
+	Description string
+	// Moves returns the files to move for cfg. Moves whose source does not
+	// exist are ignored.
+	Moves func(cfg *config.Config) ([]Move, error)
+}
+
+// Move is a file moved by a migration
+type Move struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Copy leaves From in place, for files other layouts may still read
+	Copy bool `json:"copy,omitempty"`
+}
+
+// Applied records a migration applied to a layout
+type Applied struct {
+	Version     int       `json:"version"`
+	Description string    `json:"description"`
+	AppliedAt   time.Time `json:"applied_at"`
+	Moved       []Move    `json:"moved,omitempty"`
+	// Backup is the directory holding copies of the moved files
+	Backup string `json:"backup,omitempty"`
+}
+
+// State is the content of the migration state file
+type State struct {
+	Version int       `json:"version"`
+	Applied []Applied `json:"applied,omitempty"`
+}
+
+// Result reports what Run did, or would do in dry-run mode
+type Result struct {
+	Applied []Applied
+	// Kept lists files left in place because their destination already
+	// exists; they are never merged or overwritten
+	Kept []Move
+}
+
+// Migrations are the layout migrations, in version order. New migrations are
+// appended with the next version; released ones never change.
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "copy tracking state from ~/.agent-manager/state.json to metadata.tracking_file",
+		Moves:       legacyStateMoves,
+	},
+}
+
+// Latest returns the version of the newest migration
+func Latest() int {
+	return Migrations[len(Migrations)-1].Version
+}
+
+// StatePath returns the migration state file for cfg, next to the tracking file
+func StatePath(cfg *config.Config) string {
+	return filepath.Join(filepath.Dir(cfg.Metadata.TrackingFile), StateFileName)
+}
+
+// LoadState reads the migration state of cfg's layout. A layout without a
+// state file has had no migrations applied.
+func LoadState(cfg *config.Config) (*State, error) {
+	content, err := os.ReadFile(StatePath(cfg))
+	if os.IsNotExist(err) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration state: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse migration state %s: %w", StatePath(cfg), err)
+	}
+	return &state, nil
+}
+
+// Run applies the migrations newer than the recorded version. Each file is
+// copied into a backup directory under settings.backup_dir before it is
+// moved or copied. A file whose destination already exists is kept in place and
+// reported in Result.Kept. In dry-run mode nothing changes and the result
+// reports what would be moved.
+func Run(cfg *config.Config, dryRun bool) (*Result, error) {
+	return run(cfg, Migrations, dryRun)
+}
+
+func run(cfg *config.Config, migrations []Migration, dryRun bool) (*Result, error) {
+	state, err := LoadState(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	recorded := state.Version
+	for _, migration := range migrations {
+		if migration.Version <= state.Version {
+			continue
+		}
+		moves, err := migration.Moves(cfg)
+		if err != nil {
+			return result, fmt.Errorf("migration %d: %w", migration.Version, err)
+		}
+
+		applied := Applied{Version: migration.Version, Description: migration.Description, AppliedAt: time.Now()}
+		for _, move := range moves {
+			if _, err := os.Stat(move.From); os.IsNotExist(err) {
+				continue
+			}
+			if _, err := os.Stat(move.To); err == nil {
+				result.Kept = append(result.Kept, move)
+				continue
+			}
+			if !dryRun {
+				if applied.Backup == "" {
+					applied.Backup = filepath.Join(cfg.Settings.BackupDir, "migrations",
+						strconv.Itoa(migration.Version)+"-"+applied.AppliedAt.Format("20060102-150405"))
+				}
+				if err := moveFile(move, applied.Backup); err != nil {
+					return result, fmt.Errorf("migration %d: %w", migration.Version, err)
+				}
+			}
+			applied.Moved = append(applied.Moved, move)
+		}
+
+		state.Version = migration.Version
+		if len(applied.Moved) > 0 {
+			state.Applied = append(state.Applied, applied)
+			result.Applied = append(result.Applied, applied)
+		}
+	}
+
+	if dryRun || state.Version == recorded {
+		return result, nil
+	}
+	// A layout that has never been used has nothing to migrate and no
+	// directory to record its state in
+	if _, err := os.Stat(filepath.Dir(StatePath(cfg))); os.IsNotExist(err) && len(result.Applied) == 0 {
+		return result, nil
+	}
+	return result, saveState(cfg, state)
+}
+
+// moveFile backs up move.From into backupDir and moves it to move.To, or
+// copies it when move.Copy is set
+func moveFile(move Move, backupDir string) error {
+	content, err := os.ReadFile(move.From)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", move.From, err)
+	}
+	info, err := os.Stat(move.From)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", move.From, err)
+	}
+
+	if err := os.MkdirAll(backupDir, 0750); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(backupDir, filepath.Base(move.From)), content, 0600); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", move.From, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(move.To), 0750); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", move.To, err)
+	}
+	if move.Copy {
+		if err := os.WriteFile(move.To, content, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to copy %s to %s: %w", move.From, move.To, err)
+		}
+		return nil
+	}
+	// Rename fails across devices, such as from the home directory to a
+	// mounted project, so fall back to writing a copy
+	if err := os.Rename(move.From, move.To); err != nil {
+		if err := os.WriteFile(move.To, content, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to move %s to %s: %w", move.From, move.To, err)
+		}
+		if err := os.Remove(move.From); err != nil {
+			return fmt.Errorf("failed to remove %s after moving it: %w", move.From, err)
+		}
+	}
+	return nil
+}
+
+func saveState(cfg *config.Config, state *State) error {
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal migration state: %w", err)
+	}
+	path := StatePath(cfg)
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create migration state directory: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write migration state: %w", err)
+	}
+	return nil
+}
+
+// legacyStateMoves copies the tracking file older versions kept in
+// ~/.agent-manager/state.json, when it holds tracking data. The legacy file
+// was shared by every project, so it is only copied into a tracking file the
+// configuration names explicitly, never into the project default, and stays
+// in place for the other projects.
+func legacyStateMoves(cfg *config.Config) ([]Move, error) {
+	if !cfg.Metadata.TrackingFileConfigured() {
+		return nil, nil
+	}
+	legacy, err := util.ExpandPath("~/.agent-manager/state.json")
+	if err != nil {
+		return nil, nil // Without a home directory there is no legacy state
+	}
+	content, err := os.ReadFile(legacy)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", legacy, err)
+	}
+	var data struct {
+		Installations map[string]json.RawMessage `json:"installations"`
+	}
+	if err := json.Unmarshal(content, &data); err != nil || data.Installations == nil {
+		return nil, nil // Not tracking data; leave it alone
+	}
+	return []Move{{From: legacy, To: cfg.Metadata.TrackingFile, Copy: true}}, nil
+}
@@ -0,0 +1,187 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+const legacyState = `{"version": "1.0", "installations": {"team": {"files": {}}}}`
+
+// testConfig returns a configuration keeping its state under a temp project
+// and a home directory holding legacy state
+func testConfig(t *testing.T) (*config.Config, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	project := t.TempDir()
+	cfg := &config.Config{
+		Settings: config.Settings{BackupDir: filepath.Join(project, ".claude", "backups")},
+	}
+	// Decoded so the tracking file counts as explicitly configured
+	trackingFile := filepath.Join(project, ".claude", ".installed-agents.json")
+	if err := yaml.Unmarshal([]byte("tracking_file: "+trackingFile), &cfg.Metadata); err != nil {
+		t.Fatal(err)
+	}
+
+	legacy := filepath.Join(home, ".agent-manager", "state.json")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte(legacyState), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return cfg, legacy
+}
+
+func TestRun_CopiesLegacyTrackingState(t *testing.T) {
+	cfg, legacy := testConfig(t)
+
+	result, err := Run(cfg, true)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if len(result.Applied) != 1 {
+		t.Fatalf("Expected the dry run to report one migration, got %+v", result)
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Fatalf("Expected the dry run to leave the legacy state in place: %v", err)
+	}
+
+	result, err = Run(cfg, false)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Applied) != 1 || result.Applied[0].Backup == "" {
+		t.Fatalf("Expected one migration with a backup, got %+v", result)
+	}
+	content, err := os.ReadFile(cfg.Metadata.TrackingFile)
+	if err != nil || string(content) != legacyState {
+		t.Errorf("Expected the tracking state copied to the tracking file, got %q (%v)", content, err)
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("Expected the legacy state left in place for other projects: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(result.Applied[0].Backup, "state.json")); err != nil {
+		t.Errorf("Expected a backup of the legacy state: %v", err)
+	}
+
+	state, err := LoadState(cfg)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if state.Version != Latest() || len(state.Applied) != 1 {
+		t.Errorf("Expected the migration recorded, got %+v", state)
+	}
+
+	// Applied migrations never run again
+	if err := os.Remove(cfg.Metadata.TrackingFile); err != nil {
+		t.Fatal(err)
+	}
+	result, err = Run(cfg, false)
+	if err != nil || len(result.Applied) != 0 {
+		t.Errorf("Expected no migration to run again, got %+v (%v)", result, err)
+	}
+}
+
+func TestRun_SkipsDefaultTrackingFile(t *testing.T) {
+	cfg, legacy := testConfig(t)
+	cfg.Metadata = config.Metadata{TrackingFile: cfg.Metadata.TrackingFile}
+
+	result, err := Run(cfg, false)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Applied) != 0 {
+		t.Errorf("Expected nothing migrated into a defaulted tracking file, got %+v", result)
+	}
+	if _, err := os.Stat(cfg.Metadata.TrackingFile); !os.IsNotExist(err) {
+		t.Error("Expected no tracking file to be written")
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("Expected the legacy state left in place: %v", err)
+	}
+}
+
+func TestRun_KeepsExistingDestination(t *testing.T) {
+	cfg, legacy := testConfig(t)
+	current := `{"version": "1.0", "installations": {}}`
+	if err := os.MkdirAll(filepath.Dir(cfg.Metadata.TrackingFile), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfg.Metadata.TrackingFile, []byte(current), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Run(cfg, false)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Kept) != 1 || result.Kept[0].From != legacy {
+		t.Errorf("Expected the legacy state reported as kept, got %+v", result)
+	}
+	if content, _ := os.ReadFile(cfg.Metadata.TrackingFile); string(content) != current {
+		t.Error("Expected the current tracking file to be left untouched")
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("Expected the legacy state left in place: %v", err)
+	}
+}
+
+func TestRun_IgnoresUnrelatedFiles(t *testing.T) {
+	cfg, legacy := testConfig(t)
+	if err := os.WriteFile(legacy, []byte("not tracking data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Run(cfg, false)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Applied) != 0 {
+		t.Errorf("Expected nothing migrated, got %+v", result)
+	}
+	if _, err := os.Stat(StatePath(cfg)); !os.IsNotExist(err) {
+		t.Error("Expected no state file in a project that was never used")
+	}
+}
+
+func TestRun_OrdersMigrations(t *testing.T) {
+	cfg, _ := testConfig(t)
+	dir := filepath.Dir(cfg.Metadata.TrackingFile)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	oldest := filepath.Join(dir, "v1")
+	if err := os.WriteFile(oldest, []byte("state"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A file moved by one migration is picked up by the next
+	migrations := []Migration{
+		{Version: 1, Description: "first", Moves: func(*config.Config) ([]Move, error) {
+			return []Move{{From: oldest, To: filepath.Join(dir, "v2")}}, nil
+		}},
+		{Version: 2, Description: "second", Moves: func(*config.Config) ([]Move, error) {
+			return []Move{{From: filepath.Join(dir, "v2"), To: filepath.Join(dir, "v3")}}, nil
+		}},
+	}
+	result, err := run(cfg, migrations, false)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if len(result.Applied) != 2 {
+		t.Errorf("Expected both migrations applied, got %+v", result)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "v3")); err != nil || string(content) != "state" {
+		t.Errorf("Expected the file at its latest location, got %q (%v)", content, err)
+	}
+	if state, _ := LoadState(cfg); state.Version != 2 {
+		t.Errorf("Expected version 2 recorded, got %d", state.Version)
+	}
+}
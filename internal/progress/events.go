@@ -0,0 +1,105 @@
+package progress
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Progress output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Event types written in the JSON format
+const (
+	EventStart  = "start"
+	EventUpdate = "update"
+	EventFinish = "finish"
+)
+
+// Task kinds reported in start events
+const (
+	KindSpinner  = "spinner"
+	KindProgress = "progress"
+)
+
+// Event is one machine-readable progress event, written as a line of JSON.
+// Total is set for progress tasks only; Success is set on finish events.
+type Event struct {
+	Event   string    `json:"event"`
+	ID      string    `json:"id"`
+	Kind    string    `json:"kind,omitempty"`
+	Message string    `json:"message,omitempty"`
+	Current int       `json:"current"`
+	Total   int       `json:"total,omitempty"`
+	Success *bool     `json:"success,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// eventStream writes progress events as JSON lines and keeps the count of
+// each running task so update events carry absolute values
+type eventStream struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	tasks   map[string]*eventTask
+}
+
+// eventTask is the state of a task between its start and finish events
+type eventTask struct {
+	current int
+	total   int
+}
+
+// newEventStream creates an event stream writing to w
+func newEventStream(w io.Writer) *eventStream {
+	return &eventStream{
+		encoder: json.NewEncoder(w),
+		tasks:   make(map[string]*eventTask),
+	}
+}
+
+// start reports a task starting; a task started again under the same id
+// restarts its count
+func (s *eventStream) start(id, kind, message string, total int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tasks[id] = &eventTask{total: total}
+	s.write(Event{Event: EventStart, ID: id, Kind: kind, Message: message, Total: total})
+}
+
+// update reports progress on a running task; updates of unknown tasks are dropped
+func (s *eventStream) update(id string, increment int, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return
+	}
+	task.current += increment
+	s.write(Event{Event: EventUpdate, ID: id, Message: message, Current: task.current, Total: task.total})
+}
+
+// finish reports a task ending and forgets it
+func (s *eventStream) finish(id string, success bool, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return
+	}
+	delete(s.tasks, id)
+	s.write(Event{Event: EventFinish, ID: id, Message: message, Current: task.current, Total: task.total, Success: &success})
+}
+
+// write encodes one event; write errors are ignored so a closed consumer
+// never fails the command (must be called with mu held)
+func (s *eventStream) write(event Event) {
+	event.Time = time.Now().UTC()
+	_ = s.encoder.Encode(event)
+}
@@ -0,0 +1,58 @@
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONEvents(t *testing.T) {
+	var events, output bytes.Buffer
+	manager := New(Options{Enabled: true, Format: FormatJSON, Events: &events, Output: &output})
+
+	manager.StartProgress("copy", "Copying files", 3)
+	manager.UpdateProgress("copy", 1)
+	manager.UpdateProgress("copy", 2)
+	manager.UpdateDescription("copy", "Copying the last file")
+	manager.FinishProgress("copy", true, "Copied")
+	manager.UpdateProgress("copy", 1) // finished tasks report nothing
+	_ = manager.WithSpinner("Loading", func() error { return errors.New("boom") })
+
+	var got []Event
+	decoder := json.NewDecoder(&events)
+	for decoder.More() {
+		var event Event
+		require.NoError(t, decoder.Decode(&event))
+		assert.False(t, event.Time.IsZero())
+		got = append(got, event)
+	}
+	require.Len(t, got, 7)
+
+	assert.Equal(t, Event{Event: EventStart, ID: "copy", Kind: KindProgress, Message: "Copying files", Total: 3}, withoutTime(got[0]))
+	assert.Equal(t, 1, got[1].Current)
+	assert.Equal(t, 3, got[2].Current)
+	assert.Equal(t, "Copying the last file", got[3].Message)
+	assert.Equal(t, EventFinish, got[4].Event)
+	require.NotNil(t, got[4].Success)
+	assert.True(t, *got[4].Success)
+	assert.Equal(t, 3, got[4].Current)
+
+	assert.Equal(t, KindSpinner, got[5].Kind)
+	assert.Equal(t, got[5].ID, got[6].ID)
+	require.NotNil(t, got[6].Success)
+	assert.False(t, *got[6].Success)
+
+	// Events replace the progress bars on the human output
+	assert.Empty(t, output.String())
+}
+
+// withoutTime clears the time of an event for comparison
+func withoutTime(event Event) Event {
+	event.Time = time.Time{}
+	return event
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 	"github.com/schollz/progressbar/v3"
 )
 
@@ -23,6 +24,7 @@ type Manager struct {
 	output       io.Writer
 	lastCleanup  time.Time
 	cleanupQueue []string // Queue of IDs to cleanup
+	events       *eventStream
 }
 
 // Options configures the progress manager
@@ -32,6 +34,10 @@ type Options struct {
 	DryRun  bool
 	NoColor bool
 	Output  io.Writer
+	// Format is FormatText for progress bars or FormatJSON for events
+	// written to Events; the JSON format replaces the bars
+	Format string
+	Events io.Writer
 }
 
 // New creates a new progress manager
@@ -46,7 +52,14 @@ func New(opts Options) *Manager {
 		opts.Enabled = false
 	}
 
+	var events *eventStream
+	if opts.Format == FormatJSON && opts.Events != nil {
+		events = newEventStream(opts.Events)
+		opts.Enabled = false
+	}
+
 	return &Manager{
+		events:       events,
 		enabled:      opts.Enabled,
 		verbose:      opts.Verbose,
 		dryRun:       opts.DryRun,
@@ -61,6 +74,9 @@ func New(opts Options) *Manager {
 
 // StartSpinner starts an indeterminate progress spinner
 func (m *Manager) StartSpinner(id, description string) {
+	if m.events != nil {
+		m.events.start(id, KindSpinner, description, 0)
+	}
 	if !m.enabled || m.verbose {
 		// In verbose mode, just print the description
 		if m.verbose {
@@ -91,6 +107,9 @@ func (m *Manager) StartSpinner(id, description string) {
 
 // StopSpinner stops a spinner and optionally shows a completion message
 func (m *Manager) StopSpinner(id string, success bool, message string) {
+	if m.events != nil {
+		m.events.finish(id, success, message)
+	}
 	if !m.enabled {
 		if m.verbose && message != "" {
 			_, _ = fmt.Fprintf(m.output, "%s\n", message)
@@ -107,9 +126,9 @@ func (m *Manager) StopSpinner(id string, success bool, message string) {
 
 		if message != "" {
 			if success {
-				_, _ = fmt.Fprintf(m.output, "✓ %s\n", message)
+				_, _ = fmt.Fprintf(m.output, "%s %s\n", util.Symbol("✓"), message)
 			} else {
-				_, _ = fmt.Fprintf(m.output, "✗ %s\n", message)
+				_, _ = fmt.Fprintf(m.output, "%s %s\n", util.Symbol("✗"), message)
 			}
 		}
 	}
@@ -120,6 +139,9 @@ func (m *Manager) StopSpinner(id string, success bool, message string) {
 
 // StartProgress starts a determinate progress bar
 func (m *Manager) StartProgress(id, description string, total int) {
+	if m.events != nil {
+		m.events.start(id, KindProgress, description, total)
+	}
 	if !m.enabled || m.verbose {
 		if m.verbose {
 			_, _ = fmt.Fprintf(m.output, "%s (0/%d)\n", description, total)
@@ -157,6 +179,9 @@ func (m *Manager) StartProgress(id, description string, total int) {
 
 // UpdateProgress updates a progress bar
 func (m *Manager) UpdateProgress(id string, increment int) {
+	if m.events != nil {
+		m.events.update(id, increment, "")
+	}
 	if !m.enabled {
 		return
 	}
@@ -171,6 +196,9 @@ func (m *Manager) UpdateProgress(id string, increment int) {
 
 // UpdateDescription updates the description of a progress bar or spinner
 func (m *Manager) UpdateDescription(id, description string) {
+	if m.events != nil {
+		m.events.update(id, 0, description)
+	}
 	if !m.enabled {
 		if m.verbose {
 			_, _ = fmt.Fprintf(m.output, "%s\n", description)
@@ -190,6 +218,9 @@ func (m *Manager) UpdateDescription(id, description string) {
 
 // FinishProgress completes a progress bar
 func (m *Manager) FinishProgress(id string, success bool, message string) {
+	if m.events != nil {
+		m.events.finish(id, success, message)
+	}
 	if !m.enabled {
 		if m.verbose && message != "" {
 			_, _ = fmt.Fprintf(m.output, "%s\n", message)
@@ -206,9 +237,9 @@ func (m *Manager) FinishProgress(id string, success bool, message string) {
 
 		if message != "" {
 			if success {
-				_, _ = fmt.Fprintf(m.output, "✓ %s\n", message)
+				_, _ = fmt.Fprintf(m.output, "%s %s\n", util.Symbol("✓"), message)
 			} else {
-				_, _ = fmt.Fprintf(m.output, "✗ %s\n", message)
+				_, _ = fmt.Fprintf(m.output, "%s %s\n", util.Symbol("✗"), message)
 			}
 		}
 	}
@@ -0,0 +1,266 @@
+package quarantine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+// Store keeps quarantined sources and the files moved out of their install locations
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// Record describes a quarantined source
+type Record struct {
+	Source     string    `json:"source"`
+	Reason     string    `json:"reason,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	WasEnabled bool      `json:"was_enabled"`
+	Files      []File    `json:"files,omitempty"`
+	// Installation is the tracking entry removed on quarantine, restored on release
+	Installation *tracker.Installation `json:"installation,omitempty"`
+}
+
+// File maps an installed file to its location in the quarantine area
+type File struct {
+	Original string `json:"original"`
+	Stored   string `json:"stored"`
+}
+
+// manifest is the on-disk index of quarantined sources
+type manifest struct {
+	Sources map[string]*Record `json:"sources"`
+}
+
+// New creates a quarantine store rooted at dir
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// DefaultDir returns the quarantine directory kept next to the tracking file
+func DefaultDir(trackingFile string) string {
+	return filepath.Join(filepath.Dir(trackingFile), "quarantine")
+}
+
+// IsQuarantined reports whether a source, or the parent of a "source/category"
+// sub-source, is quarantined
+func (s *Store) IsQuarantined(sourceName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return false
+	}
+	parent, _ := tracker.SplitSubSource(sourceName)
+	return data.Sources[sourceName] != nil || data.Sources[parent] != nil
+}
+
+// List returns all quarantined sources
+func (s *Store) List() (map[string]*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return data.Sources, nil
+}
+
+// Quarantine moves the installed files of a source into the quarantine area
+// and records the source so it can be released later. When a file cannot be
+// moved or the record cannot be saved, the files already moved are put back,
+// so the source is either fully quarantined or left as it was.
+func (s *Store) Quarantine(sourceName string, installation *tracker.Installation, reason string, wasEnabled bool) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	if data.Sources[sourceName] != nil {
+		return nil, fmt.Errorf("source %s is already quarantined", sourceName)
+	}
+
+	record := &Record{
+		Source:       sourceName,
+		Reason:       reason,
+		Timestamp:    time.Now(),
+		WasEnabled:   wasEnabled,
+		Installation: installation,
+	}
+
+	if installation != nil {
+		paths := make([]string, 0, len(installation.Files))
+		for path := range installation.Files {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		filesDir := filepath.Join(s.dir, "files", safeName(sourceName))
+		for n, path := range paths {
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				continue
+			}
+			stored := filepath.Join(filesDir, fmt.Sprintf("%03d-%s", n, filepath.Base(path)))
+			if err := moveFile(path, stored); err != nil {
+				return nil, rollback(fmt.Errorf("failed to quarantine %s: %w", path, err), record.Files, true)
+			}
+			record.Files = append(record.Files, File{Original: path, Stored: stored})
+		}
+	}
+
+	data.Sources[sourceName] = record
+	if err := s.save(data); err != nil {
+		return nil, rollback(err, record.Files, true)
+	}
+	return record, nil
+}
+
+// Release moves a quarantined source's files back to their original locations
+// and removes it from quarantine. When a file cannot be restored or the
+// manifest cannot be saved, the files already restored are moved back into
+// quarantine, so the source stays quarantined.
+func (s *Store) Release(sourceName string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	record := data.Sources[sourceName]
+	if record == nil {
+		return nil, fmt.Errorf("source %s is not quarantined", sourceName)
+	}
+
+	for _, file := range record.Files {
+		if _, err := os.Stat(file.Original); err == nil {
+			return nil, fmt.Errorf("cannot restore %s: file already exists", file.Original)
+		}
+	}
+	for n, file := range record.Files {
+		if err := moveFile(file.Stored, file.Original); err != nil {
+			return nil, rollback(fmt.Errorf("failed to restore %s: %w", file.Original, err), record.Files[:n], false)
+		}
+	}
+
+	delete(data.Sources, sourceName)
+	if err := s.save(data); err != nil {
+		return nil, rollback(err, record.Files, false)
+	}
+	_ = os.RemoveAll(filepath.Join(s.dir, "files", safeName(sourceName)))
+	return record, nil
+}
+
+// AppendAuditLog appends a timestamped entry describing a quarantine action to logFile
+func AppendAuditLog(logFile, action string, record *Record) error {
+	if err := os.MkdirAll(filepath.Dir(logFile), 0750); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	entry := fmt.Sprintf("%s %s source=%s files=%d", time.Now().Format(time.RFC3339), action, record.Source, len(record.Files))
+	if record.Reason != "" {
+		entry += fmt.Sprintf(" reason=%q", record.Reason)
+	}
+	if _, err := fmt.Fprintln(f, entry); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// manifestPath returns the path of the quarantine manifest
+func (s *Store) manifestPath() string {
+	return filepath.Join(s.dir, "quarantine.json")
+}
+
+func (s *Store) load() (*manifest, error) {
+	data := &manifest{}
+	content, err := os.ReadFile(s.manifestPath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read quarantine manifest: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(content, data); err != nil {
+			return nil, fmt.Errorf("failed to parse quarantine manifest: %w", err)
+		}
+	}
+	if data.Sources == nil {
+		data.Sources = make(map[string]*Record)
+	}
+	return data, nil
+}
+
+func (s *Store) save(data *manifest) error {
+	if err := os.MkdirAll(s.dir, 0750); err != nil {
+		return fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal quarantine manifest: %w", err)
+	}
+
+	// Write atomically using temp file
+	tempFile := s.manifestPath() + ".tmp"
+	if err := os.WriteFile(tempFile, content, 0600); err != nil {
+		return fmt.Errorf("failed to write quarantine manifest: %w", err)
+	}
+	if err := os.Rename(tempFile, s.manifestPath()); err != nil {
+		_ = os.Remove(tempFile)
+		return fmt.Errorf("failed to save quarantine manifest: %w", err)
+	}
+	return nil
+}
+
+// rollback undoes the moves of files after err, in reverse order: quarantined
+// files go back to their original locations, restored ones back into
+// quarantine. A file that cannot be moved back is named in the returned error.
+func rollback(err error, files []File, quarantined bool) error {
+	for i := len(files) - 1; i >= 0; i-- {
+		src, dst := files[i].Original, files[i].Stored
+		if quarantined {
+			src, dst = dst, src
+		}
+		if moveErr := moveFile(src, dst); moveErr != nil {
+			err = fmt.Errorf("%w; also failed to move %s back to %s: %v", err, src, dst, moveErr)
+		}
+	}
+	return err
+}
+
+// moveFile renames src to dst, falling back to copy and delete across filesystems
+func moveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := util.NewFileManager().Copy(src, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// safeName converts a source name into a single path element
+func safeName(sourceName string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(sourceName)
+}
@@ -0,0 +1,151 @@
+package quarantine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+)
+
+func TestQuarantineAndRelease(t *testing.T) {
+	tempDir := t.TempDir()
+	agentPath := filepath.Join(tempDir, "agents", "reviewer.md")
+	if err := os.MkdirAll(filepath.Dir(agentPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(agentPath, []byte("agent"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	installation := &tracker.Installation{
+		Files: map[string]tracker.FileInfo{
+			agentPath:                            {Path: agentPath},
+			filepath.Join(tempDir, "missing.md"): {Path: "missing.md"},
+		},
+	}
+
+	store := New(filepath.Join(tempDir, "quarantine"))
+	record, err := store.Quarantine("community", installation, "compromised", true)
+	if err != nil {
+		t.Fatalf("Quarantine failed: %v", err)
+	}
+	if len(record.Files) != 1 {
+		t.Fatalf("Expected 1 moved file, got %d", len(record.Files))
+	}
+	if _, err := os.Stat(agentPath); !os.IsNotExist(err) {
+		t.Error("Expected agent file to be moved out of place")
+	}
+
+	if !store.IsQuarantined("community") || !store.IsQuarantined("community/testing") {
+		t.Error("Expected source and its categories to be quarantined")
+	}
+	if store.IsQuarantined("other") {
+		t.Error("Expected other sources not to be quarantined")
+	}
+	if _, err := store.Quarantine("community", nil, "", false); err == nil {
+		t.Error("Expected error quarantining twice")
+	}
+
+	released, err := store.Release("community")
+	if err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if !released.WasEnabled || released.Reason != "compromised" || released.Installation == nil {
+		t.Errorf("Unexpected released record: %+v", released)
+	}
+	if content, err := os.ReadFile(agentPath); err != nil || string(content) != "agent" {
+		t.Errorf("Expected agent file to be restored, got %q, %v", content, err)
+	}
+	if store.IsQuarantined("community") {
+		t.Error("Expected source to be released")
+	}
+	if _, err := store.Release("community"); err == nil {
+		t.Error("Expected error releasing a source that is not quarantined")
+	}
+}
+
+func TestQuarantineRollsBackPartialMoves(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]tracker.FileInfo{}
+	var paths []string
+	for _, name := range []string{"a.md", "b.md"} {
+		path := filepath.Join(tempDir, "agents", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files[path] = tracker.FileInfo{Path: path}
+		paths = append(paths, path)
+	}
+	installation := &tracker.Installation{Files: files}
+	store := New(filepath.Join(tempDir, "quarantine"))
+
+	// A directory in the way of the second file makes its move fail
+	blocker := filepath.Join(tempDir, "quarantine", "files", "community", "001-b.md", "x")
+	if err := os.MkdirAll(blocker, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Quarantine("community", installation, "", true); err == nil {
+		t.Fatal("Expected quarantine to fail")
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be moved back: %v", path, err)
+		}
+	}
+	if store.IsQuarantined("community") {
+		t.Error("Expected a failed quarantine not to be recorded")
+	}
+
+	// A stored file gone missing makes release fail and keeps the source quarantined
+	if err := os.RemoveAll(filepath.Join(tempDir, "quarantine", "files")); err != nil {
+		t.Fatal(err)
+	}
+	record, err := store.Quarantine("community", installation, "", true)
+	if err != nil {
+		t.Fatalf("Quarantine failed: %v", err)
+	}
+	if err := os.Remove(record.Files[1].Stored); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Release("community"); err == nil {
+		t.Fatal("Expected release to fail")
+	}
+	if _, err := os.Stat(paths[0]); !os.IsNotExist(err) {
+		t.Error("Expected the restored file to be moved back into quarantine")
+	}
+	if _, err := os.Stat(record.Files[0].Stored); err != nil {
+		t.Errorf("Expected the first file in quarantine: %v", err)
+	}
+	if !store.IsQuarantined("community") {
+		t.Error("Expected the source to stay quarantined")
+	}
+}
+
+func TestAppendAuditLog(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "logs", "installation.log")
+	record := &Record{Source: "community", Reason: "bad release", Files: []File{{}}}
+
+	if err := AppendAuditLog(logFile, "quarantine", record); err != nil {
+		t.Fatalf("AppendAuditLog failed: %v", err)
+	}
+	if err := AppendAuditLog(logFile, "unquarantine", record); err != nil {
+		t.Fatalf("AppendAuditLog failed: %v", err)
+	}
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log entries, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], `quarantine source=community files=1 reason="bad release"`) {
+		t.Errorf("Unexpected log entry: %s", lines[0])
+	}
+}
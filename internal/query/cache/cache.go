@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -33,6 +34,11 @@ type CacheManager struct {
 	mu      sync.RWMutex
 	stats   CacheStats
 	cleanup *time.Ticker
+	saveMu  sync.Mutex  // serializes saves
+	dirty   atomic.Bool // entries changed since the last save
+	done    chan struct{}
+	closed  sync.Once
+	persist sync.Once
 }
 
 // CacheStats tracks cache performance metrics
@@ -57,6 +63,7 @@ func NewCacheManager(path string, config Config) (*CacheManager, error) {
 		config:  config,
 		path:    path,
 		stats:   CacheStats{},
+		done:    make(chan struct{}),
 	}
 
 	// Try to load existing cache
@@ -95,6 +102,7 @@ func (cm *CacheManager) Set(key string, value interface{}) {
 	}
 
 	cm.stats.Size = len(cm.entries)
+	cm.dirty.Store(true)
 }
 
 // Get retrieves a value from the cache by key
@@ -130,6 +138,7 @@ func (cm *CacheManager) Clear() {
 
 	cm.entries = make(map[string]*Entry)
 	cm.stats.Size = 0
+	cm.dirty.Store(true)
 }
 
 // Stats returns cache performance statistics
@@ -153,116 +162,189 @@ func (cm *CacheManager) Stats() map[string]interface{} {
 	}
 }
 
-// Save persists the cache to disk
-func (cm *CacheManager) Save() error {
-	cm.mu.RLock()
-	defer cm.mu.RUnlock()
+// Usage describes a cache file on disk
+type Usage struct {
+	Entries int             // entries in the file
+	Expired int             // entries older than the TTL
+	Bytes   int64           // size of the file
+	Hits    int             // hits of the process that last saved the file
+	Misses  int             // misses of the process that last saved the file
+	Ages    []time.Duration // age of each entry
+}
 
-	// Create directory if it doesn't exist
-	if dir := filepath.Dir(cm.path); dir != "." {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create cache directory: %w", err)
+// Inspect reads the cache file at path without loading it, counting entries
+// older than ttl as expired. A missing file is an empty cache.
+func Inspect(path string, ttl time.Duration) (Usage, error) {
+	var usage Usage
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return usage, nil
+		}
+		return usage, fmt.Errorf("failed to read cache file: %w", err)
+	}
+
+	var data cacheFile
+	if err := json.Unmarshal(content, &data); err != nil || !validEntries(data.Entries) {
+		return usage, fmt.Errorf("cache file %s is corrupt", path)
+	}
+
+	now := time.Now()
+	usage.Entries = len(data.Entries)
+	usage.Bytes = int64(len(content))
+	usage.Hits = data.Stats.Hits
+	usage.Misses = data.Stats.Misses
+	for _, entry := range data.Entries {
+		age := now.Sub(entry.CreatedAt)
+		if age > ttl {
+			usage.Expired++
+		}
+		usage.Ages = append(usage.Ages, age)
+	}
+	return usage, nil
+}
+
+// GC removes expired entries and evicts the oldest ones until the cache holds
+// at most MaxSize entries and encodes to at most maxBytes (zero for no byte
+// limit), then saves it. It returns the number of entries removed.
+func (cm *CacheManager) GC(maxBytes int64) (int, error) {
+	cm.mu.Lock()
+	before := len(cm.entries)
+
+	now := time.Now()
+	for key, entry := range cm.entries {
+		if now.Sub(entry.CreatedAt) > cm.config.TTL {
+			delete(cm.entries, key)
+		}
+	}
+	for cm.config.MaxSize > 0 && len(cm.entries) > cm.config.MaxSize {
+		cm.evictOldest()
+	}
+	for maxBytes > 0 && len(cm.entries) > 0 {
+		data, err := json.MarshalIndent(cacheFile{Entries: cm.entries, Stats: cm.stats, Config: cm.config}, "", "  ")
+		if err != nil {
+			cm.mu.Unlock()
+			return 0, fmt.Errorf("failed to encode cache data: %w", err)
+		}
+		if int64(len(data)) <= maxBytes {
+			break
 		}
+		cm.evictOldest()
 	}
 
-	// Prepare data for serialization
-	data := struct {
-		Entries map[string]*Entry `json:"entries"`
-		Stats   CacheStats        `json:"stats"`
-		Config  Config            `json:"config"`
-	}{
+	removed := before - len(cm.entries)
+	cm.stats.Size = len(cm.entries)
+	cm.dirty.Store(true)
+	cm.mu.Unlock()
+
+	return removed, cm.Save()
+}
+
+// cacheFile is the on-disk representation of the cache
+type cacheFile struct {
+	Entries map[string]*Entry `json:"entries"`
+	Stats   CacheStats        `json:"stats"`
+	Config  Config            `json:"config"`
+}
+
+// Save persists the cache to disk atomically: entries are written to a unique
+// temporary file that replaces the cache file only once complete, so a crash
+// or a concurrent save never leaves a truncated cache behind
+func (cm *CacheManager) Save() error {
+	cm.saveMu.Lock()
+	defer cm.saveMu.Unlock()
+
+	// Serialize under the read lock, then write without holding it
+	cm.mu.RLock()
+	data, err := json.MarshalIndent(cacheFile{
 		Entries: cm.entries,
 		Stats:   cm.stats,
 		Config:  cm.config,
+	}, "", "  ")
+	cm.dirty.Store(false)
+	cm.mu.RUnlock()
+	if err != nil {
+		cm.dirty.Store(true)
+		return fmt.Errorf("failed to encode cache data: %w", err)
 	}
 
-	// Write to temporary file first, then rename (atomic operation)
-	tempPath := cm.path + ".tmp"
-	file, err := os.Create(tempPath)
+	// Create directory if it doesn't exist
+	dir := filepath.Dir(cm.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(dir, filepath.Base(cm.path)+".*.tmp")
 	if err != nil {
 		return fmt.Errorf("failed to create temp cache file: %w", err)
 	}
-
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(data); err != nil {
-		if closeErr := file.Close(); closeErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to close temp cache file during cleanup: %v\n", closeErr)
-		}
-		if removeErr := os.Remove(tempPath); removeErr != nil {
+	tempPath := file.Name()
+	discard := func() {
+		if removeErr := os.Remove(tempPath); removeErr != nil && !os.IsNotExist(removeErr) {
 			fmt.Fprintf(os.Stderr, "Warning: failed to remove temp cache file during cleanup: %v\n", removeErr)
 		}
-		return fmt.Errorf("failed to encode cache data: %w", err)
+	}
+
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		discard()
+		return fmt.Errorf("failed to write temp cache file: %w", err)
 	}
 
 	// Force sync to disk before closing (ensures data is written)
 	if err := file.Sync(); err != nil {
-		if closeErr := file.Close(); closeErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to close temp cache file during cleanup: %v\n", closeErr)
-		}
-		if removeErr := os.Remove(tempPath); removeErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to remove temp cache file during cleanup: %v\n", removeErr)
-		}
+		_ = file.Close()
+		discard()
 		return fmt.Errorf("failed to sync temp cache file: %w", err)
 	}
 
 	// Close file before rename (critical on Windows)
 	if err := file.Close(); err != nil {
-		if removeErr := os.Remove(tempPath); removeErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to remove temp cache file during cleanup: %v\n", removeErr)
-		}
+		discard()
 		return fmt.Errorf("failed to close temp cache file: %w", err)
 	}
 
 	// Atomic rename - handle Windows file locking issues
 	if err := atomicRename(tempPath, cm.path); err != nil {
-		if removeErr := os.Remove(tempPath); removeErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to remove temp cache file during cleanup: %v\n", removeErr)
-		}
+		discard()
 		return fmt.Errorf("failed to save cache file: %w", err)
 	}
 
 	return nil
 }
 
-// load reads the cache from disk
+// load reads the cache from disk. A corrupt cache file, such as one truncated
+// by an older non-atomic write, is removed so the cache starts empty.
 func (cm *CacheManager) load() error {
-	file, err := os.Open(cm.path)
+	content, err := os.ReadFile(cm.path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil // No cache file is OK
 		}
-		return fmt.Errorf("failed to open cache file: %w", err)
-	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to close cache file: %v\n", closeErr)
-		}
-	}()
-
-	var data struct {
-		Entries map[string]*Entry `json:"entries"`
-		Stats   CacheStats        `json:"stats"`
-		Config  Config            `json:"config"`
+		return fmt.Errorf("failed to read cache file: %w", err)
 	}
 
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&data); err != nil {
-		return fmt.Errorf("failed to decode cache file: %w", err)
+	var data cacheFile
+	if err := json.Unmarshal(content, &data); err != nil || !validEntries(data.Entries) {
+		fmt.Fprintf(os.Stderr, "Warning: cache file %s is corrupt, resetting it\n", cm.path)
+		if removeErr := os.Remove(cm.path); removeErr != nil && !os.IsNotExist(removeErr) {
+			return fmt.Errorf("failed to reset corrupt cache file: %w", removeErr)
+		}
+		return nil
 	}
 
 	// Filter out expired entries during load
 	now := time.Now()
-	validEntries := make(map[string]*Entry)
+	entries := make(map[string]*Entry)
 
 	for key, entry := range data.Entries {
 		if now.Sub(entry.CreatedAt) <= cm.config.TTL {
-			validEntries[key] = entry
+			entries[key] = entry
 		}
 	}
 
-	cm.entries = validEntries
-	cm.stats.Size = len(validEntries)
+	cm.entries = entries
+	cm.stats.Size = len(entries)
 	// Reset hit/miss stats on load
 	cm.stats.Hits = 0
 	cm.stats.Misses = 0
@@ -270,6 +352,16 @@ func (cm *CacheManager) load() error {
 	return nil
 }
 
+// validEntries reports whether decoded entries are well formed
+func validEntries(entries map[string]*Entry) bool {
+	for key, entry := range entries {
+		if entry == nil || entry.Key != key || entry.CreatedAt.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
 // evictOldest removes the oldest entry based on creation time, then access time
 func (cm *CacheManager) evictOldest() {
 	if len(cm.entries) == 0 {
@@ -318,7 +410,13 @@ func (cm *CacheManager) evictOldest() {
 
 // cleanupExpired removes expired entries periodically
 func (cm *CacheManager) cleanupExpired() {
-	for range cm.cleanup.C {
+	for {
+		select {
+		case <-cm.done:
+			return
+		case <-cm.cleanup.C:
+		}
+
 		cm.mu.Lock()
 
 		now := time.Now()
@@ -346,11 +444,39 @@ func (cm *CacheManager) cleanupExpired() {
 	}
 }
 
+// PersistEvery saves the cache in the background at the given interval
+// whenever it changed since the last save, so long-running processes keep
+// their cache across a crash. Only the first call has an effect; Close stops it.
+func (cm *CacheManager) PersistEvery(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	cm.persist.Do(func() {
+		ticker := time.NewTicker(interval)
+		go func() {
+			defer ticker.Stop()
+			for {
+				select {
+				case <-cm.done:
+					return
+				case <-ticker.C:
+					if cm.dirty.Load() {
+						if err := cm.Save(); err != nil {
+							fmt.Fprintf(os.Stderr, "Warning: failed to persist cache: %v\n", err)
+						}
+					}
+				}
+			}
+		}()
+	})
+}
+
 // Close shuts down the cache manager and saves to disk
 func (cm *CacheManager) Close() error {
 	if cm.cleanup != nil {
 		cm.cleanup.Stop()
 	}
+	cm.closed.Do(func() { close(cm.done) })
 
 	return cm.Save()
 }
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -313,4 +315,133 @@ func TestCacheManager_CorruptedFile(t *testing.T) {
 
 	stats := cm.Stats()
 	assert.Equal(t, 0, stats["size"])
+
+	// The corrupt file is reset rather than left behind
+	_, err = os.Stat(cachePath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCacheManager_MalformedEntries(t *testing.T) {
+	tempDir := t.TempDir()
+	cachePath := filepath.Join(tempDir, "cache.json")
+
+	// Valid JSON whose entries do not match their keys
+	err := os.WriteFile(cachePath, []byte(`{"entries":{"a":{"key":"b","value":1,"created_at":"2026-01-01T00:00:00Z"}}}`), 0644)
+	require.NoError(t, err)
+
+	cm, err := NewCacheManager(cachePath, Config{MaxSize: 10, TTL: time.Hour})
+	require.NoError(t, err)
+	assert.Nil(t, cm.Get("a"))
+
+	_, err = os.Stat(cachePath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCacheManager_ConcurrentSave(t *testing.T) {
+	tempDir := t.TempDir()
+	cachePath := filepath.Join(tempDir, "cache.json")
+
+	cm, err := NewCacheManager(cachePath, Config{MaxSize: 100, TTL: time.Hour})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			cm.Set(fmt.Sprintf("key-%d", id), id)
+			assert.NoError(t, cm.Save())
+		}(i)
+	}
+	wg.Wait()
+
+	// No temporary files are left behind and the saved cache loads cleanly
+	matches, err := filepath.Glob(filepath.Join(tempDir, "*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	loaded, err := NewCacheManager(cachePath, Config{MaxSize: 100, TTL: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, 10, loaded.Stats()["size"])
+}
+
+func TestCacheManager_PersistEvery(t *testing.T) {
+	tempDir := t.TempDir()
+	cachePath := filepath.Join(tempDir, "cache.json")
+
+	cm, err := NewCacheManager(cachePath, Config{MaxSize: 10, TTL: time.Hour})
+	require.NoError(t, err)
+	defer func() { _ = cm.Close() }()
+
+	cm.PersistEvery(10 * time.Millisecond)
+	cm.Set("background", "saved")
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(cachePath)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	loaded, err := NewCacheManager(cachePath, Config{MaxSize: 10, TTL: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, "saved", loaded.Get("background"))
+}
+
+func TestCacheManager_GC(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+
+	cm, err := NewCacheManager(cachePath, Config{MaxSize: 10, TTL: time.Hour})
+	require.NoError(t, err)
+	defer func() { _ = cm.Close() }()
+
+	for i := 0; i < 6; i++ {
+		cm.Set(fmt.Sprintf("key%d", i), strings.Repeat("x", 100))
+	}
+	cm.mu.Lock()
+	cm.entries["key0"].CreatedAt = time.Now().Add(-2 * time.Hour)
+	cm.mu.Unlock()
+
+	// The expired entry goes first, then the oldest until the file fits
+	removed, err := cm.GC(700)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, removed, 2)
+	assert.Nil(t, cm.Get("key0"))
+	assert.NotNil(t, cm.Get("key5"))
+
+	usage, err := Inspect(cachePath, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 6-removed, usage.Entries)
+	assert.Zero(t, usage.Expired)
+	assert.LessOrEqual(t, usage.Bytes, int64(700))
+	assert.Len(t, usage.Ages, usage.Entries)
+}
+
+func TestInspect(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+
+	usage, err := Inspect(cachePath, time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, usage.Entries)
+
+	cm, err := NewCacheManager(cachePath, Config{MaxSize: 10, TTL: time.Hour})
+	require.NoError(t, err)
+	cm.Set("key1", "value1")
+	cm.Set("key2", "value2")
+	cm.Get("key1")
+	cm.Get("missing")
+	require.NoError(t, cm.Close())
+
+	usage, err = Inspect(cachePath, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 2, usage.Entries)
+	assert.Equal(t, 1, usage.Hits)
+	assert.Equal(t, 1, usage.Misses)
+	assert.Greater(t, usage.Bytes, int64(0))
+
+	usage, err = Inspect(cachePath, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, usage.Expired)
+
+	require.NoError(t, os.WriteFile(cachePath, []byte("{"), 0644))
+	_, err = Inspect(cachePath, time.Hour)
+	assert.Error(t, err)
 }
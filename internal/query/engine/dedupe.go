@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+)
+
+// Deduplication modes for query results
+const (
+	DedupeAll       = "all"
+	DedupeEffective = "effective"
+)
+
+// SetSourcePriority sets the configured source order, highest priority first,
+// and the installing source of each tracked file. fileSources takes precedence
+// over the source recorded in the index, which may predate the last install.
+func (e *Engine) SetSourcePriority(order []string, fileSources map[string]string) {
+	e.sourceOrder = order
+	e.fileSources = make(map[string]string, len(fileSources))
+	for path, source := range fileSources {
+		e.fileSources[absPath(path)] = source
+	}
+}
+
+// Dedupe keeps only the agents Claude Code uses among agents. The copy used
+// is chosen among every indexed agent of the same name, so a result that is
+// hidden by a copy outside agents is dropped rather than promoted. Project
+// agents override user agents, then the copy from the highest-priority source
+// wins; files not installed by a configured source rank last.
+func (e *Engine) Dedupe(agents []*parser.AgentSpec) []*parser.AgentSpec {
+	rank := make(map[string]int, len(e.sourceOrder))
+	for i, name := range e.sourceOrder {
+		rank[name] = i
+	}
+	effective := Dedupe(e.currentIndex().GetAll(), func(agent *parser.AgentSpec) int {
+		source := agent.Source
+		if s, ok := e.fileSources[absPath(agent.FilePath)]; ok {
+			source = s
+		}
+		if r, ok := rank[source]; ok {
+			return r
+		}
+		return len(rank)
+	})
+
+	used := make(map[string]*parser.AgentSpec, len(effective))
+	for _, agent := range effective {
+		used[agent.FilePath] = agent
+	}
+	deduped := make([]*parser.AgentSpec, 0, len(agents))
+	for _, agent := range agents {
+		if kept, ok := used[agent.FilePath]; ok {
+			deduped = append(deduped, kept)
+		}
+	}
+	return deduped
+}
+
+// Dedupe keeps the best copy of each agent name, ordered by scope, then by
+// sourceRank (lower wins), then by file path. Results keep the order in which
+// each name first appears in agents.
+func Dedupe(agents []*parser.AgentSpec, sourceRank func(*parser.AgentSpec) int) []*parser.AgentSpec {
+	groups := make(map[string][]*parser.AgentSpec)
+	var names []string
+	for _, agent := range agents {
+		if _, ok := groups[agent.Name]; !ok {
+			names = append(names, agent.Name)
+		}
+		groups[agent.Name] = append(groups[agent.Name], agent)
+	}
+
+	deduped := make([]*parser.AgentSpec, 0, len(names))
+	for _, name := range names {
+		copies := groups[name]
+		if len(copies) == 1 {
+			deduped = append(deduped, copies[0])
+			continue
+		}
+
+		sort.SliceStable(copies, func(a, b int) bool {
+			if sa, sb := scopeRank(copies[a]), scopeRank(copies[b]); sa != sb {
+				return sa < sb
+			}
+			if ra, rb := sourceRank(copies[a]), sourceRank(copies[b]); ra != rb {
+				return ra < rb
+			}
+			return copies[a].FilePath < copies[b].FilePath
+		})
+
+		kept := *copies[0]
+		kept.Shadows = make([]string, 0, len(copies)-1)
+		for _, hidden := range copies[1:] {
+			kept.Shadows = append(kept.Shadows, hidden.FilePath)
+		}
+		deduped = append(deduped, &kept)
+	}
+	return deduped
+}
+
+// scopeRank orders copies the way Claude Code merges agent directories:
+// project agents first, then user agents
+func scopeRank(agent *parser.AgentSpec) int {
+	switch {
+	case agent.Scope == parser.ScopeProject:
+		return 0
+	case agent.Shadowed:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// absPath returns the absolute form of path, or path itself when it cannot be resolved
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -3,23 +3,45 @@ package engine
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/pacphi/claude-code-agent-manager/internal/query/cache"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/fuzzy"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/index"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 )
 
-// Engine handles agent queries with caching and advanced search capabilities
+// Engine handles agent queries with caching and advanced search capabilities.
+// Rebuilds construct a shadow index and swap it in atomically, so concurrent
+// queries always see either the complete old index or the complete new one.
 type Engine struct {
-	index  *index.IndexManager
-	cache  *cache.CacheManager
-	parser *parser.Parser
-	fuzzy  *fuzzy.FuzzyMatcher
+	index      atomic.Pointer[index.IndexManager]
+	generation atomic.Uint64 // incremented on every index swap; part of cache keys
+	cache      *cache.CacheManager
+	parser     *parser.Parser
+	fuzzy      *fuzzy.FuzzyMatcher
+	weights    map[string]float64 // relevance weights of the ranked fields; defaults when nil
+
+	// Source priorities used to pick the effective copy when deduplicating
+	sourceOrder []string
+	fileSources map[string]string
+
+	// Installation provenance joined into every index build; nil leaves the
+	// provenance recorded on the agents as it is
+	provenance map[string]Provenance
+
+	// autoPrune drops entries for deleted files before each query
+	autoPrune bool
 }
 
+// CacheMaxEntries bounds the number of query results the engine caches
+const CacheMaxEntries = 100
+
 // NewEngine creates a new query engine with the specified index and cache paths
 func NewEngine(indexPath, cachePath string) (*Engine, error) {
 	indexManager, err := index.NewIndexManager(indexPath)
@@ -28,19 +50,93 @@ func NewEngine(indexPath, cachePath string) (*Engine, error) {
 	}
 
 	cacheManager, err := cache.NewCacheManager(cachePath, cache.Config{
-		MaxSize: 100,
+		MaxSize: CacheMaxEntries,
 		TTL:     time.Hour,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create cache manager: %w", err)
 	}
 
-	return &Engine{
-		index:  indexManager,
+	e := &Engine{
 		cache:  cacheManager,
-		parser: parser.NewParserWithOptions(true), // Suppress warnings by default
+		parser: parser.NewParser(),
 		fuzzy:  fuzzy.NewFuzzyMatcher(0.7),
-	}, nil
+	}
+	e.index.Store(indexManager)
+	return e, nil
+}
+
+// currentIndex returns the live index; callers keep using the returned index
+// for the rest of an operation even if a rebuild swaps in a new one meanwhile
+func (e *Engine) currentIndex() *index.IndexManager {
+	return e.index.Load()
+}
+
+// swapIndex builds a shadow index from agents and the files that failed to
+// parse, and atomically makes it live. The previous index stays valid for
+// in-flight queries that already hold it.
+func (e *Engine) swapIndex(agents []*parser.AgentSpec, broken []parser.ParseFailure) *index.IndexManager {
+	current := e.currentIndex()
+	next := index.NewIndexManagerFromAgents(current.Path(), e.applyProvenance(agents))
+	next.SetBroken(broken)
+	next.SetLayout(current.Layout())
+	e.index.Store(next)
+	// Bump the generation after the swap so results cached against the old
+	// index can never be served for the new one
+	e.generation.Add(1)
+	e.cache.Clear()
+	return next
+}
+
+// ReloadIfChanged reloads the index from disk when another process saved a
+// newer generation of it since it was loaded, and reports whether it did
+func (e *Engine) ReloadIfChanged() (bool, error) {
+	current := e.currentIndex()
+	if current.Path() == "" {
+		return false, nil
+	}
+	saved, err := index.ReadGeneration(current.Path())
+	if err != nil {
+		return false, fmt.Errorf("failed to read index generation: %w", err)
+	}
+	if saved == 0 || saved == current.Generation() {
+		return false, nil
+	}
+
+	next, err := index.NewIndexManager(current.Path())
+	if err != nil {
+		return false, fmt.Errorf("failed to reload index: %w", err)
+	}
+	if next.Stale() {
+		return false, nil
+	}
+	// Swap like a rebuild does, so results cached for the old index are dropped
+	e.index.Store(next)
+	e.generation.Add(1)
+	e.cache.Clear()
+	return true, nil
+}
+
+// SetParseMode sets how malformed agent files are handled when updating the index
+func (e *Engine) SetParseMode(mode string) {
+	e.parser.Mode = mode
+}
+
+// SetExtensions sets the file extensions recognized as agent files
+func (e *Engine) SetExtensions(extensions []string) {
+	e.parser.Extensions = extensions
+}
+
+// SetWalkOptions sets how deep index updates walk agent directories and
+// whether they follow symlinked directories
+func (e *Engine) SetWalkOptions(opts util.WalkOptions) {
+	e.parser.Walk = opts
+}
+
+// SetWarningHandler sets the handler that receives warnings about agent files
+// skipped or recovered while updating the index
+func (e *Engine) SetWarningHandler(handler parser.WarningHandler) {
+	e.parser.OnWarning = handler
 }
 
 // QueryOptions provides filtering and configuration options for queries
@@ -50,7 +146,10 @@ type QueryOptions struct {
 	CustomTools bool            // Find agents with explicit tools only
 	Regex       bool            // Use regex pattern matching
 	Source      string          // Filter by installation source
+	Scope       string          // Filter by scope: user, project or effective
+	Dedupe      string          // Keep every copy of an agent (all) or only the one used (effective)
 	After       time.Time       // Filter agents installed after this time
+	Names       []string        // Filter to agents with these names, such as a collection's
 	Context     context.Context // For cancellation and timeouts
 }
 
@@ -68,6 +167,8 @@ func (e *Engine) QueryWithFuzzy(query string, opts QueryOptions) ([]*parser.Agen
 	default:
 	}
 
+	e.pruneBeforeQuery()
+
 	// Check cache first
 	cacheKey := e.buildCacheKey("fuzzy:"+query, opts)
 	if cached := e.cache.Get(cacheKey); cached != nil {
@@ -76,9 +177,10 @@ func (e *Engine) QueryWithFuzzy(query string, opts QueryOptions) ([]*parser.Agen
 		}
 	}
 
-	// Use fuzzy multi-field search for enhanced matching
-	allAgents := e.index.GetAll()
-	results := e.fuzzy.MultiFieldSearch(query, allAgents, nil, opts.Limit)
+	// Use fuzzy multi-field search for enhanced matching. Matches are ranked
+	// by field weight before filtering, so the limit applies in applyQueryFilters.
+	allAgents := e.currentIndex().GetAll()
+	results := e.Rank(query, e.fuzzy.MultiFieldSearch(query, allAgents, nil, 0))
 
 	// Apply additional filters
 	results = e.applyQueryFilters(results, opts)
@@ -103,6 +205,13 @@ func (e *Engine) Query(query string, opts QueryOptions) ([]*parser.AgentSpec, er
 	default:
 	}
 
+	// Report syntax errors as they are rather than as failed searches
+	if _, err := index.ParseExpr(query); err != nil {
+		return nil, err
+	}
+
+	e.pruneBeforeQuery()
+
 	// Check cache first
 	cacheKey := e.buildCacheKey(query, opts)
 	if cached := e.cache.Get(cacheKey); cached != nil {
@@ -111,17 +220,39 @@ func (e *Engine) Query(query string, opts QueryOptions) ([]*parser.AgentSpec, er
 		}
 	}
 
+	// Ranking, the scope filter and deduplication run after the search, so
+	// the limit is applied after them
+	ranked := strings.TrimSpace(query) != ""
+	postFilter := ranked || opts.Scope != "" || opts.Dedupe == DedupeEffective
+	limit := opts.Limit
+	if postFilter {
+		limit = 0
+	}
+
 	// Execute search - maintain original behavior unless explicitly using regex
-	results, err := e.index.Search(query, index.QueryOptions{
-		Limit:       opts.Limit,
+	results, err := e.currentIndex().Search(query, index.QueryOptions{
+		Limit:       limit,
 		NoTools:     opts.NoTools,
 		CustomTools: opts.CustomTools,
 		Source:      opts.Source,
 		After:       opts.After,
+		Names:       opts.Names,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
+	if postFilter {
+		if ranked {
+			results = e.Rank(query, results)
+		}
+		results = FilterScope(results, opts.Scope)
+		if opts.Dedupe == DedupeEffective {
+			results = e.Dedupe(results)
+		}
+		if opts.Limit > 0 && len(results) > opts.Limit {
+			results = results[:opts.Limit]
+		}
+	}
 
 	// Cache results
 	e.cache.Set(cacheKey, results)
@@ -129,52 +260,129 @@ func (e *Engine) Query(query string, opts QueryOptions) ([]*parser.AgentSpec, er
 	return results, nil
 }
 
-// QueryByField searches specific fields with the provided value
+// QueryByField searches specific fields with the provided value. A value
+// starting with = must equal the field rather than be contained in it.
 func (e *Engine) QueryByField(field, value string) ([]*parser.AgentSpec, error) {
+	e.pruneBeforeQuery()
 	field = strings.ToLower(strings.TrimSpace(field))
 	value = strings.TrimSpace(value)
 
+	if exact, ok := strings.CutPrefix(value, "="); ok {
+		term, err := index.FieldTerm(field, exact, true)
+		if err != nil {
+			return nil, err
+		}
+		var results []*parser.AgentSpec
+		for _, agent := range e.currentIndex().GetAll() {
+			if term.Match(agent) {
+				results = append(results, agent)
+			}
+		}
+		return results, nil
+	}
+
 	switch field {
 	case "name":
-		return e.index.SearchByName(value)
+		return e.currentIndex().SearchByName(value)
 	case "description":
-		return e.index.SearchByDescription(value)
+		return e.currentIndex().SearchByDescription(value)
 	case "content", "prompt":
-		return e.index.SearchByContent(value)
+		return e.currentIndex().SearchByContent(value)
 	case "tools":
 		tools := strings.Split(value, ",")
 		for i := range tools {
 			tools[i] = strings.TrimSpace(tools[i])
 		}
-		return e.index.SearchByTools(tools)
+		return e.currentIndex().SearchByTools(tools)
 	case "source":
-		return e.index.SearchBySource(value)
+		return e.currentIndex().SearchBySource(value)
+	case "version":
+		return e.currentIndex().SearchByVersion(value)
 	default:
 		return nil, fmt.Errorf("invalid field: %s", field)
 	}
 }
 
-// ShowAgent retrieves an agent by filename with fuzzy matching fallback
+// AmbiguousError is returned by ShowAgent when a filename matches agent
+// files in several directories, such as helper.md in two namespaces
+type AmbiguousError struct {
+	Name    string
+	Matches []*parser.AgentSpec
+}
+
+// Error implements the error interface
+func (e *AmbiguousError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%q matches %d agent files; pass a path suffix to pick one:", e.Name, len(e.Matches))
+	for _, agent := range e.Matches {
+		fmt.Fprintf(&b, "\n  %s  (%s)", UniqueSuffix(agent, e.Matches), agent.FilePath)
+	}
+	return b.String()
+}
+
+// UniqueSuffix returns the shortest trailing part of the path of agent, in
+// slash form, that no other of agents ends with, or the whole path
+func UniqueSuffix(agent *parser.AgentSpec, agents []*parser.AgentSpec) string {
+	path := filepath.ToSlash(agent.FilePath)
+	parts := strings.Split(path, "/")
+	for n := 1; n < len(parts); n++ {
+		suffix := strings.Join(parts[len(parts)-n:], "/")
+		unique := true
+		for _, other := range agents {
+			otherPath := filepath.ToSlash(other.FilePath)
+			if other != agent && (otherPath == suffix || strings.HasSuffix(otherPath, "/"+suffix)) {
+				unique = false
+				break
+			}
+		}
+		if unique {
+			return suffix
+		}
+	}
+	return path
+}
+
+// ShowAgent retrieves an agent by filename with fuzzy matching fallback. A
+// filename may be qualified by its namespace or any trailing part of its path,
+// such as team/helper.md; when it matches several different agent files an
+// *AmbiguousError listing them is returned.
 func (e *Engine) ShowAgent(filename string) (*parser.AgentSpec, error) {
 	filename = strings.TrimSpace(filename)
 	if filename == "" {
 		return nil, fmt.Errorf("filename cannot be empty")
 	}
+	e.pruneBeforeQuery()
 
-	// Try exact match first
-	if agent := e.index.GetByFilename(filename); agent != nil {
-		return agent, nil
-	}
+	idx := e.currentIndex()
 
-	// Try with .md extension if not present
-	if !strings.HasSuffix(filename, ".md") {
-		if agent := e.index.GetByFilename(filename + ".md"); agent != nil {
-			return agent, nil
+	// Try exact match first, then with each agent file extension if not present
+	candidates := []string{filename}
+	if !parser.IsAgentFile(filename, e.parser.Extensions) {
+		extensions := e.parser.Extensions
+		if len(extensions) == 0 {
+			extensions = parser.DefaultExtensions
+		}
+		for _, ext := range extensions {
+			candidates = append(candidates, filename+ext)
+		}
+	}
+	for _, candidate := range candidates {
+		matches := idx.GetAllByFilename(candidate)
+		if len(matches) == 0 && strings.Contains(filepath.ToSlash(candidate), "/") {
+			matches = idx.GetByPathSuffix(candidate)
+		}
+		switch {
+		case len(matches) == 0:
+			continue
+		case sameAgent(matches):
+			return matches[0], nil
+		default:
+			return nil, &AmbiguousError{Name: filename, Matches: matches}
 		}
 	}
 
 	// Fallback to fuzzy matching
-	agents := e.index.GetAll()
+	agents := idx.GetAll()
 	if match := e.fuzzy.FindBest(filename, agents); match != nil {
 		return match, nil
 	}
@@ -182,64 +390,368 @@ func (e *Engine) ShowAgent(filename string) (*parser.AgentSpec, error) {
 	return nil, fmt.Errorf("agent not found: %s", filename)
 }
 
+// sameAgent reports whether agents are all copies of one agent, as when a
+// directory holding installed agents is indexed along with their source
+func sameAgent(agents []*parser.AgentSpec) bool {
+	for _, agent := range agents[1:] {
+		if agent.Name != agents[0].Name || agent.Description != agents[0].Description || agent.Prompt != agents[0].Prompt {
+			return false
+		}
+	}
+	return true
+}
+
+// FindAgents returns every agent name could refer to: all agents whose name,
+// qualified name or filename (with or without extension) equals name, or whose
+// path ends with name when it contains a slash, such as agents of the same
+// name in different namespaces, or else the fuzzy matches of name, best
+// first. More than one result means name is ambiguous.
+func (e *Engine) FindAgents(name string) []*parser.AgentSpec {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+
+	agents := e.currentIndex().GetAll()
+	var exact []*parser.AgentSpec
+	for _, agent := range agents {
+		base := strings.TrimSuffix(agent.FileName, filepath.Ext(agent.FileName))
+		qualifiedFile := agent.FileName
+		if agent.Namespace != "" {
+			qualifiedFile = agent.Namespace + "/" + agent.FileName
+		}
+		switch name {
+		case agent.Name, agent.QualifiedName(), agent.FileName, base, qualifiedFile,
+			strings.TrimSuffix(qualifiedFile, filepath.Ext(qualifiedFile)):
+			exact = append(exact, agent)
+			continue
+		}
+		if strings.Contains(filepath.ToSlash(name), "/") {
+			path := filepath.ToSlash(agent.FilePath)
+			suffix := "/" + strings.TrimPrefix(filepath.ToSlash(name), "/")
+			if strings.HasSuffix(path, suffix) || strings.HasSuffix(strings.TrimSuffix(path, filepath.Ext(path)), suffix) {
+				exact = append(exact, agent)
+			}
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	return e.fuzzy.FindMultiple(name, agents, 0)
+}
+
+// ReloadAgent re-reads an indexed agent from disk. It returns the re-parsed
+// agent, or nil when the file no longer exists, and whether the index entry is
+// stale because the file's modification time or size changed since indexing.
+func (e *Engine) ReloadAgent(agent *parser.AgentSpec) (*parser.AgentSpec, bool, error) {
+	info, err := os.Stat(agent.FilePath)
+	if os.IsNotExist(err) {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to stat %s: %w", agent.FilePath, err)
+	}
+	stale := !info.ModTime().Equal(agent.ModTime) || info.Size() != agent.FileSize
+
+	fresh, err := e.parser.ParseFile(agent.FilePath)
+	if err != nil {
+		return nil, stale, fmt.Errorf("failed to parse %s: %w", agent.FilePath, err)
+	}
+
+	// Metadata that comes from indexing rather than the file itself
+	fresh.Namespace = agent.Namespace
+	fresh.Source = agent.Source
+	fresh.SourceCommit = agent.SourceCommit
+	fresh.InstalledAt = agent.InstalledAt
+	fresh.Scope = agent.Scope
+	fresh.Shadowed = agent.Shadowed
+	return fresh, stale, nil
+}
+
+// RefreshAgent replaces the index entry of the agent file at path with fresh,
+// adds fresh when the file was not indexed, or removes the entry when fresh is
+// nil, and saves the index
+func (e *Engine) RefreshAgent(path string, fresh *parser.AgentSpec) error {
+	current := e.currentIndex().GetAll()
+	agents := make([]*parser.AgentSpec, 0, len(current)+1)
+	replaced := false
+	for _, agent := range current {
+		if agent.FilePath != path {
+			agents = append(agents, agent)
+		} else if fresh != nil {
+			agents = append(agents, fresh)
+			replaced = true
+		}
+	}
+	if fresh != nil && !replaced {
+		agents = append(agents, fresh)
+	}
+	markShadowed(agents)
+
+	// The file either parsed or is gone, so it is no longer broken
+	var broken []parser.ParseFailure
+	for _, failure := range e.currentIndex().Broken() {
+		if failure.Path != path {
+			broken = append(broken, failure)
+		}
+	}
+
+	if err := e.swapIndex(agents, broken).Save(); err != nil {
+		return fmt.Errorf("failed to save index: %w", err)
+	}
+	return nil
+}
+
 // RebuildIndex rebuilds the search index from the specified directory
 func (e *Engine) RebuildIndex(dir string) error {
-	// Clear cache when rebuilding index
-	e.cache.Clear()
-
-	if err := e.index.Rebuild(dir); err != nil {
+	agents, broken, err := e.parseDirectory(dir)
+	if err != nil {
 		return err
 	}
 
 	// Save the rebuilt index to disk
-	return e.index.Save()
+	return e.swapIndex(agents, broken).Save()
 }
 
 // RebuildWithAgents rebuilds the index with a provided list of agents
 func (e *Engine) RebuildWithAgents(agents []*parser.AgentSpec) error {
-	// Clear cache when rebuilding index
-	e.cache.Clear()
+	e.swapIndex(agents, e.currentIndex().Broken())
+	return nil
+}
+
+// WarmAgents merges freshly parsed agents, such as those just written by an
+// install, into the index and saves it, so the next query does not re-parse
+// them. Agents outside every root are ignored, and indexed agents whose files
+// no longer exist are dropped.
+func (e *Engine) WarmAgents(agents []*parser.AgentSpec, roots []Root) error {
+	fresh := make(map[string]*parser.AgentSpec, len(agents))
+	var warmed []*parser.AgentSpec
+	for _, agent := range agents {
+		path := absPath(agent.FilePath)
+		if _, seen := fresh[path]; seen {
+			continue
+		}
+		for _, root := range roots {
+			rel, err := filepath.Rel(absPath(root.Dir), path)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			agent := *agent
+			agent.Scope = root.Scope
+			agent.Namespace = parser.NamespaceOf(rel)
+			fresh[path] = &agent
+			warmed = append(warmed, &agent)
+			break
+		}
+	}
+	if len(warmed) == 0 {
+		return nil
+	}
+
+	current := e.currentIndex()
+	var merged []*parser.AgentSpec
+	for _, agent := range current.GetAll() {
+		if _, replaced := fresh[absPath(agent.FilePath)]; replaced {
+			continue
+		}
+		if _, err := os.Stat(agent.FilePath); err == nil {
+			merged = append(merged, agent)
+		}
+	}
+	merged = append(merged, warmed...)
+	markShadowed(merged)
 
-	return e.index.RebuildWithAgents(agents)
+	// Files that now parse, or are gone, are no longer broken
+	var broken []parser.ParseFailure
+	for _, failure := range current.Broken() {
+		if _, parsed := fresh[absPath(failure.Path)]; parsed {
+			continue
+		}
+		if _, err := os.Stat(failure.Path); err == nil {
+			broken = append(broken, failure)
+		}
+	}
+
+	if err := e.swapIndex(merged, broken).Save(); err != nil {
+		return fmt.Errorf("failed to save index: %w", err)
+	}
+	return nil
 }
 
 // UpdateIndex updates the index with new or modified agents
 func (e *Engine) UpdateIndex(dir string) error {
 	// Parse agents from directory
-	agents, err := e.parser.ParseDirectory(dir)
+	agents, broken, err := e.parseDirectory(dir)
 	if err != nil {
 		return fmt.Errorf("failed to parse agents: %w", err)
 	}
 
-	// Rebuild index with all agents
-	if err := e.index.RebuildWithAgents(agents); err != nil {
-		return fmt.Errorf("failed to rebuild index: %w", err)
+	// Swap in an index with all agents and save it to disk
+	if err := e.swapIndex(agents, broken).Save(); err != nil {
+		return fmt.Errorf("failed to save index: %w", err)
 	}
 
-	// Save the index to disk
-	if err := e.index.Save(); err != nil {
+	return nil
+}
+
+// Root is an agents directory indexed with a scope label
+type Root struct {
+	Dir   string
+	Scope string
+}
+
+// UpdateIndexRoots updates the index with the agents of several directories,
+// labelling each agent with its root's scope. User agents overridden by a
+// project agent of the same name are marked shadowed, as Claude Code only
+// sees the project agent. Files that fail to parse are recorded as broken.
+func (e *Engine) UpdateIndexRoots(roots []Root) error {
+	var agents []*parser.AgentSpec
+	var broken []parser.ParseFailure
+	for _, root := range roots {
+		parsed, failures, err := e.parseDirectory(root.Dir)
+		if err != nil {
+			return fmt.Errorf("failed to parse agents in %s: %w", root.Dir, err)
+		}
+		for _, agent := range parsed {
+			agent.Scope = root.Scope
+		}
+		agents = append(agents, parsed...)
+		broken = append(broken, failures...)
+	}
+	markShadowed(agents)
+
+	if err := e.swapIndex(agents, broken).Save(); err != nil {
 		return fmt.Errorf("failed to save index: %w", err)
 	}
+	return nil
+}
 
-	// Clear cache to ensure fresh results
+// parseDirectory parses the agents in dir and reports the files that failed
+// to parse; in strict mode any failure is returned as a *parser.StrictError,
+// and otherwise each is also passed to the warning handler.
+// Indexed agents whose files are unchanged are reused rather than re-parsed.
+func (e *Engine) parseDirectory(dir string) ([]*parser.AgentSpec, []parser.ParseFailure, error) {
+	known := make(map[string]*parser.AgentSpec)
+	for _, agent := range e.currentIndex().GetAll() {
+		known[agent.FilePath] = agent
+	}
+	p := *e.parser
+	p.Known = known
+	agents, failures, err := p.ParseDirectoryReport(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if e.parser.Mode == parser.ModeStrict && len(failures) > 0 {
+		return nil, nil, &parser.StrictError{Failures: failures}
+	}
+	p.WarnFailures(failures)
+	return agents, failures, nil
+}
+
+// IndexSize breaks the saved index file down by component
+func (e *Engine) IndexSize() (*index.SizeReport, error) {
+	return e.currentIndex().SizeReport()
+}
+
+// CompactIndex drops orphaned and duplicate entries from the index and
+// rewrites the saved file in layout, which later saves keep using
+func (e *Engine) CompactIndex(layout index.Layout) (*index.CompactResult, error) {
+	result, err := e.currentIndex().Compact(layout)
+	if err != nil {
+		return nil, err
+	}
+	// Cached results may include dropped entries
+	e.generation.Add(1)
 	e.cache.Clear()
+	return result, nil
+}
 
-	return nil
+// PruneMissing drops the index entries whose files no longer exist and
+// reports them, without re-parsing any agent
+func (e *Engine) PruneMissing() (*index.PruneResult, error) {
+	result, err := e.currentIndex().PruneMissing()
+	if err != nil {
+		return nil, err
+	}
+	if result.Dropped() > 0 {
+		// Cached results may include dropped entries
+		e.generation.Add(1)
+		e.cache.Clear()
+	}
+	return result, nil
+}
+
+// MissingEntries reports the index entries whose files no longer exist,
+// without dropping them
+func (e *Engine) MissingEntries() *index.PruneResult {
+	return e.currentIndex().Missing()
+}
+
+// SetAutoPrune sets whether entries for deleted files are pruned before each
+// query, so agents removed outside agent-manager are not returned
+func (e *Engine) SetAutoPrune(enabled bool) {
+	e.autoPrune = enabled
+}
+
+// pruneBeforeQuery prunes missing entries when auto-pruning is enabled; a
+// failure to save only leaves them to the next prune
+func (e *Engine) pruneBeforeQuery() {
+	if e.autoPrune {
+		_, _ = e.PruneMissing()
+	}
+}
+
+// BrokenAgents returns the agent files that failed to parse when the index
+// was last built, so they can be reported without rescanning the directories
+func (e *Engine) BrokenAgents() []parser.ParseFailure {
+	return e.currentIndex().Broken()
+}
+
+// markShadowed flags user agents whose name is also used by a project agent
+func markShadowed(agents []*parser.AgentSpec) {
+	projectNames := make(map[string]bool)
+	for _, agent := range agents {
+		if agent.Scope == parser.ScopeProject {
+			projectNames[agent.Name] = true
+		}
+	}
+	for _, agent := range agents {
+		agent.Shadowed = agent.Scope == parser.ScopeUser && projectNames[agent.Name]
+	}
+}
+
+// ScopeEffective selects the agents Claude Code actually uses: all project
+// agents plus the user agents they do not shadow
+const ScopeEffective = "effective"
+
+// FilterScope returns the agents in scope: user, project or effective. An
+// empty scope returns every agent.
+func FilterScope(agents []*parser.AgentSpec, scope string) []*parser.AgentSpec {
+	if scope == "" {
+		return agents
+	}
+	filtered := make([]*parser.AgentSpec, 0, len(agents))
+	for _, agent := range agents {
+		if (scope == ScopeEffective && !agent.Shadowed) || agent.Scope == scope {
+			filtered = append(filtered, agent)
+		}
+	}
+	return filtered
 }
 
 // GetAllAgents returns all agents in the index
 func (e *Engine) GetAllAgents() []*parser.AgentSpec {
-	return e.index.GetAll()
+	return e.currentIndex().GetAll()
 }
 
 // GetStats returns statistics about the indexed agents
 func (e *Engine) GetStats() map[string]interface{} {
-	agents := e.index.GetAll()
+	agents := e.currentIndex().GetAll()
 
 	stats := map[string]interface{}{
 		"total_agents": len(agents),
 		"cache_stats":  e.cache.Stats(),
-		"index_stats":  e.index.Stats(),
+		"index_stats":  e.currentIndex().Stats(),
 	}
 
 	// Count by source
@@ -277,6 +789,12 @@ func (e *Engine) SaveCache() error {
 	return e.cache.Save()
 }
 
+// PersistCacheEvery saves the query cache in the background at the given
+// interval while it has unsaved changes, for long-running processes
+func (e *Engine) PersistCacheEvery(interval time.Duration) {
+	e.cache.PersistEvery(interval)
+}
+
 // GetCacheStats returns cache performance statistics
 func (e *Engine) GetCacheStats() map[string]interface{} {
 	return e.cache.Stats()
@@ -318,6 +836,9 @@ func (e *Engine) applyQueryFilters(agents []*parser.AgentSpec, opts QueryOptions
 		if opts.Source != "" && agent.Source != opts.Source {
 			continue
 		}
+		if !index.HasAnyName(agent, opts.Names) {
+			continue
+		}
 
 		// Apply tools filters
 		if opts.NoTools && !agent.ToolsInherited {
@@ -334,6 +855,10 @@ func (e *Engine) applyQueryFilters(agents []*parser.AgentSpec, opts QueryOptions
 
 		filtered = append(filtered, agent)
 	}
+	filtered = FilterScope(filtered, opts.Scope)
+	if opts.Dedupe == DedupeEffective {
+		filtered = e.Dedupe(filtered)
+	}
 
 	// Apply limit if not already handled by search
 	if opts.Limit > 0 && len(filtered) > opts.Limit {
@@ -347,7 +872,7 @@ func (e *Engine) applyQueryFilters(agents []*parser.AgentSpec, opts QueryOptions
 func (e *Engine) buildCacheKey(query string, opts QueryOptions) string {
 	var parts []string
 
-	parts = append(parts, fmt.Sprintf("q:%s", query))
+	parts = append(parts, fmt.Sprintf("g:%d", e.generation.Load()), fmt.Sprintf("q:%s", query))
 
 	if opts.Limit > 0 {
 		parts = append(parts, fmt.Sprintf("l:%d", opts.Limit))
@@ -369,9 +894,26 @@ func (e *Engine) buildCacheKey(query string, opts QueryOptions) string {
 		parts = append(parts, fmt.Sprintf("s:%s", opts.Source))
 	}
 
+	if opts.Scope != "" {
+		parts = append(parts, fmt.Sprintf("sc:%s", opts.Scope))
+	}
+
+	if len(opts.Names) > 0 {
+		parts = append(parts, fmt.Sprintf("n:%s", strings.Join(opts.Names, ",")))
+	}
+
+	if opts.Dedupe == DedupeEffective {
+		parts = append(parts, fmt.Sprintf("d:%s", strings.Join(e.sourceOrder, ",")))
+	}
+
 	if !opts.After.IsZero() {
 		parts = append(parts, fmt.Sprintf("a:%d", opts.After.Unix()))
 	}
 
+	// Cached results are ordered by relevance, so they depend on the weights
+	if e.weights != nil {
+		parts = append(parts, fmt.Sprintf("w:%g,%g,%g", e.weights[FieldName], e.weights[FieldDescription], e.weights[FieldContent]))
+	}
+
 	return strings.Join(parts, "|")
 }
@@ -1,8 +1,10 @@
 package engine
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -19,7 +21,7 @@ func TestNewEngine(t *testing.T) {
 	engine, err := NewEngine(indexPath, cachePath)
 	require.NoError(t, err)
 	assert.NotNil(t, engine)
-	assert.NotNil(t, engine.index)
+	assert.NotNil(t, engine.currentIndex())
 	assert.NotNil(t, engine.cache)
 	assert.NotNil(t, engine.parser)
 	assert.NotNil(t, engine.fuzzy)
@@ -61,7 +63,7 @@ func TestEngine_Query(t *testing.T) {
 	}
 
 	for _, agent := range agents {
-		engine.index.AddAgent(agent)
+		engine.currentIndex().AddAgent(agent)
 	}
 
 	tests := []struct {
@@ -118,6 +120,21 @@ func TestEngine_Query(t *testing.T) {
 			assert.GreaterOrEqual(t, len(results), tt.minCount)
 		})
 	}
+
+	t.Run("boolean operators", func(t *testing.T) {
+		results, err := engine.Query("data NOT tools:WebFetch", QueryOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "data-processor", results[0].Name)
+
+		results, err = engine.Query("(name:scraper OR name:reviewer) tools:Read", QueryOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "code-reviewer", results[0].Name)
+
+		_, err = engine.Query("data AND", QueryOptions{})
+		assert.ErrorContains(t, err, "invalid query: AND needs a term after it")
+	})
 }
 
 func TestEngine_QueryByField(t *testing.T) {
@@ -142,11 +159,12 @@ func TestEngine_QueryByField(t *testing.T) {
 			FileName:    "code-reviewer.md",
 			Prompt:      "You are a code review specialist",
 			Tools:       []string{"Read", "Write"},
+			Version:     "1.3.0",
 		},
 	}
 
 	for _, agent := range agents {
-		engine.index.AddAgent(agent)
+		engine.currentIndex().AddAgent(agent)
 	}
 
 	tests := []struct {
@@ -191,6 +209,13 @@ func TestEngine_QueryByField(t *testing.T) {
 			wantCount: 1,
 			wantErr:   false,
 		},
+		{
+			name:      "search by version",
+			field:     "version",
+			value:     "1.3.0",
+			wantCount: 1,
+			wantErr:   false,
+		},
 		{
 			name:      "invalid field",
 			field:     "invalid",
@@ -231,7 +256,7 @@ func TestEngine_ShowAgent(t *testing.T) {
 		FileName:    "test-agent.md",
 		Prompt:      "You are a test assistant",
 	}
-	engine.index.AddAgent(agent)
+	engine.currentIndex().AddAgent(agent)
 
 	tests := []struct {
 		name     string
@@ -275,6 +300,110 @@ func TestEngine_ShowAgent(t *testing.T) {
 	}
 }
 
+func TestEngine_FindAgents(t *testing.T) {
+	tempDir := t.TempDir()
+	engine, err := NewEngine(filepath.Join(tempDir, "index.json"), filepath.Join(tempDir, "cache"))
+	require.NoError(t, err)
+
+	idx := engine.currentIndex()
+	idx.AddAgent(&parser.AgentSpec{Name: "reviewer", FileName: "reviewer.md"})
+	idx.AddAgent(&parser.AgentSpec{Name: "reviewer", FileName: "reviewer.md", Namespace: "team"})
+	idx.AddAgent(&parser.AgentSpec{Name: "go-specialist", FileName: "go-specialist.md"})
+	idx.AddAgent(&parser.AgentSpec{Name: "go-specialists", FileName: "go-specialists.md"})
+
+	names := func(agents []*parser.AgentSpec) []string {
+		var result []string
+		for _, agent := range agents {
+			result = append(result, agent.QualifiedName())
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"reviewer", "team/reviewer"}, names(engine.FindAgents("reviewer")))
+	assert.Equal(t, []string{"team/reviewer"}, names(engine.FindAgents("team/reviewer.md")))
+	assert.Equal(t, []string{"go-specialist"}, names(engine.FindAgents("go-specialist")))
+	assert.Len(t, engine.FindAgents("go-specialis"), 2)
+	assert.Empty(t, engine.FindAgents("   "))
+}
+
+func TestEngine_ShowAgentAmbiguousFilename(t *testing.T) {
+	tempDir := t.TempDir()
+	engine, err := NewEngine(filepath.Join(tempDir, "index.json"), filepath.Join(tempDir, "cache"))
+	require.NoError(t, err)
+
+	idx := engine.currentIndex()
+	idx.AddAgent(&parser.AgentSpec{Name: "helper", Description: "Team helper", FileName: "helper.md", Namespace: "team", FilePath: "/agents/team/helper.md"})
+	idx.AddAgent(&parser.AgentSpec{Name: "helper", Description: "Ops helper", FileName: "helper.md", Namespace: "ops", FilePath: "/agents/ops/helper.md"})
+	idx.AddAgent(&parser.AgentSpec{Name: "writer", Description: "Writer", FileName: "writer.md", FilePath: "/agents/writer.md"})
+	idx.AddAgent(&parser.AgentSpec{Name: "writer", Description: "Writer", FileName: "writer.md", FilePath: "/src/writer.md"})
+
+	_, err = engine.ShowAgent("helper")
+	var ambiguous *AmbiguousError
+	require.ErrorAs(t, err, &ambiguous)
+	assert.Len(t, ambiguous.Matches, 2)
+	assert.Contains(t, err.Error(), "ops/helper.md  (/agents/ops/helper.md)")
+	assert.Contains(t, err.Error(), "team/helper.md  (/agents/team/helper.md)")
+
+	agent, err := engine.ShowAgent("team/helper")
+	require.NoError(t, err)
+	assert.Equal(t, "Team helper", agent.Description)
+	agent, err = engine.ShowAgent("agents/ops/helper.md")
+	require.NoError(t, err)
+	assert.Equal(t, "Ops helper", agent.Description)
+	assert.Len(t, engine.FindAgents("agents/ops/helper"), 1)
+
+	// Identical copies of one agent are not ambiguous
+	agent, err = engine.ShowAgent("writer.md")
+	require.NoError(t, err)
+	assert.Equal(t, "/agents/writer.md", agent.FilePath)
+}
+
+func TestEngine_ReloadAgent(t *testing.T) {
+	tempDir := t.TempDir()
+	agentsDir := filepath.Join(tempDir, "agents")
+	require.NoError(t, os.MkdirAll(agentsDir, 0755))
+
+	agentPath := filepath.Join(agentsDir, "reviewer.md")
+	require.NoError(t, os.WriteFile(agentPath, []byte("---\nname: reviewer\ndescription: Reviews code\n---\nReview the code"), 0644))
+
+	engine, err := NewEngine(filepath.Join(tempDir, "index.json"), filepath.Join(tempDir, "cache"))
+	require.NoError(t, err)
+	require.NoError(t, engine.UpdateIndex(agentsDir))
+
+	indexed, err := engine.ShowAgent("reviewer")
+	require.NoError(t, err)
+
+	fresh, stale, err := engine.ReloadAgent(indexed)
+	require.NoError(t, err)
+	assert.False(t, stale)
+	assert.Equal(t, "Reviews code", fresh.Description)
+
+	// Edit the file behind the index's back
+	require.NoError(t, os.WriteFile(agentPath, []byte("---\nname: reviewer\ndescription: Reviews pull requests\n---\nReview the pull request"), 0644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(agentPath, later, later))
+
+	fresh, stale, err = engine.ReloadAgent(indexed)
+	require.NoError(t, err)
+	assert.True(t, stale)
+	assert.Equal(t, "Reviews pull requests", fresh.Description)
+
+	require.NoError(t, engine.RefreshAgent(indexed.FilePath, fresh))
+	refreshed, err := engine.ShowAgent("reviewer")
+	require.NoError(t, err)
+	assert.Equal(t, "Reviews pull requests", refreshed.Description)
+
+	// A deleted file is stale and removed from the index on refresh
+	require.NoError(t, os.Remove(agentPath))
+	fresh, stale, err = engine.ReloadAgent(refreshed)
+	require.NoError(t, err)
+	assert.True(t, stale)
+	assert.Nil(t, fresh)
+
+	require.NoError(t, engine.RefreshAgent(refreshed.FilePath, nil))
+	assert.Empty(t, engine.GetAllAgents())
+}
+
 func TestEngine_WithCache(t *testing.T) {
 	tempDir := t.TempDir()
 	indexPath := filepath.Join(tempDir, "index.json")
@@ -290,7 +419,7 @@ func TestEngine_WithCache(t *testing.T) {
 		FileName:    "cached-agent.md",
 		Prompt:      "You are cached",
 	}
-	engine.index.AddAgent(agent)
+	engine.currentIndex().AddAgent(agent)
 
 	// First query should hit the index
 	results1, err := engine.Query("cached", QueryOptions{})
@@ -333,7 +462,7 @@ func TestEngine_QueryWithTimeFilter(t *testing.T) {
 	}
 
 	for _, agent := range agents {
-		engine.index.AddAgent(agent)
+		engine.currentIndex().AddAgent(agent)
 	}
 
 	// Query for agents installed after yesterday
@@ -392,6 +521,277 @@ You are an integration test assistant.`
 	assert.Contains(t, agent.Prompt, "integration test assistant")
 }
 
+func TestEngine_UpdateIndexRoots(t *testing.T) {
+	tempDir := t.TempDir()
+	userDir := filepath.Join(tempDir, "user")
+	projectDir := filepath.Join(tempDir, "project")
+
+	writeAgent := func(dir, name, description string) {
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		content := fmt.Sprintf("---\nname: %s\ndescription: %s\n---\n\nYou are a helper.", name, description)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name+".md"), []byte(content), 0644))
+	}
+	writeAgent(userDir, "reviewer", "User reviewer agent")
+	writeAgent(userDir, "writer", "User writer agent")
+	writeAgent(projectDir, "reviewer", "Project reviewer agent")
+
+	engine, err := NewEngine(filepath.Join(tempDir, "index.json"), filepath.Join(tempDir, "cache"))
+	require.NoError(t, err)
+
+	err = engine.UpdateIndexRoots([]Root{
+		{Dir: projectDir, Scope: parser.ScopeProject},
+		{Dir: userDir, Scope: parser.ScopeUser},
+		{Dir: filepath.Join(tempDir, "missing"), Scope: parser.ScopeUser},
+	})
+	require.NoError(t, err)
+
+	all := engine.GetAllAgents()
+	assert.Len(t, all, 3)
+
+	descriptions := func(agents []*parser.AgentSpec) []string {
+		var result []string
+		for _, agent := range agents {
+			result = append(result, agent.Description)
+		}
+		return result
+	}
+	assert.ElementsMatch(t, []string{"User reviewer agent", "User writer agent"}, descriptions(FilterScope(all, parser.ScopeUser)))
+	assert.ElementsMatch(t, []string{"Project reviewer agent"}, descriptions(FilterScope(all, parser.ScopeProject)))
+	assert.ElementsMatch(t, []string{"Project reviewer agent", "User writer agent"}, descriptions(FilterScope(all, ScopeEffective)))
+
+	results, err := engine.Query("reviewer", QueryOptions{Scope: ScopeEffective})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, parser.ScopeProject, results[0].Scope)
+
+	// Lookups by name resolve to the project agent Claude Code uses
+	agent, err := engine.ShowAgent("reviewer")
+	require.NoError(t, err)
+	assert.Equal(t, "Project reviewer agent", agent.Description)
+}
+
+func TestEngine_SetProvenance(t *testing.T) {
+	tempDir := t.TempDir()
+	agentsDir := filepath.Join(tempDir, "agents")
+	require.NoError(t, os.MkdirAll(agentsDir, 0755))
+	for _, name := range []string{"reviewer", "writer"} {
+		content := fmt.Sprintf("---\nname: %s\ndescription: The %s agent\n---\n\nYou are a helper.", name, name)
+		require.NoError(t, os.WriteFile(filepath.Join(agentsDir, name+".md"), []byte(content), 0644))
+	}
+	indexPath := filepath.Join(tempDir, "index.json")
+	installedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	engine, err := NewEngine(indexPath, filepath.Join(tempDir, "cache"))
+	require.NoError(t, err)
+	engine.SetProvenance(map[string]Provenance{
+		filepath.Join(agentsDir, "reviewer.md"): {Source: "team", Commit: "abc123", InstalledAt: installedAt},
+	})
+	require.NoError(t, engine.UpdateIndex(agentsDir))
+
+	reviewer, err := engine.ShowAgent("reviewer")
+	require.NoError(t, err)
+	assert.Equal(t, "team", reviewer.Source)
+	assert.Equal(t, "abc123", reviewer.SourceCommit)
+	assert.True(t, installedAt.Equal(reviewer.InstalledAt))
+	writer, err := engine.ShowAgent("writer")
+	require.NoError(t, err)
+	assert.Empty(t, writer.Source)
+
+	results, err := engine.Query("", QueryOptions{Source: "team"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "reviewer", results[0].Name)
+
+	// Provenance is saved with the index
+	reloaded, err := NewEngine(indexPath, filepath.Join(tempDir, "cache"))
+	require.NoError(t, err)
+	reviewer, err = reloaded.ShowAgent("reviewer")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", reviewer.SourceCommit)
+
+	// Agents reused from the index lose provenance once no longer tracked
+	reloaded.SetProvenance(map[string]Provenance{})
+	require.NoError(t, reloaded.UpdateIndex(agentsDir))
+	reviewer, err = reloaded.ShowAgent("reviewer")
+	require.NoError(t, err)
+	assert.Empty(t, reviewer.Source)
+	assert.Empty(t, reviewer.SourceCommit)
+	assert.True(t, reviewer.InstalledAt.IsZero())
+}
+
+func TestEngine_Dedupe(t *testing.T) {
+	tempDir := t.TempDir()
+	userDir := filepath.Join(tempDir, "user")
+	projectDir := filepath.Join(tempDir, "project")
+
+	writeAgent := func(dir, name, description string) string {
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		path := filepath.Join(dir, name+".md")
+		content := fmt.Sprintf("---\nname: %s\ndescription: %s\n---\n\nYou are a helper.", name, description)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	userReviewer := writeAgent(userDir, "reviewer", "User reviewer agent")
+	writeAgent(projectDir, "reviewer", "Project reviewer agent")
+	teamFormatter := writeAgent(filepath.Join(userDir, "team"), "formatter", "Team formatter agent")
+	communityFormatter := writeAgent(filepath.Join(userDir, "community"), "formatter", "Community formatter agent")
+
+	engine, err := NewEngine(filepath.Join(tempDir, "index.json"), filepath.Join(tempDir, "cache"))
+	require.NoError(t, err)
+	require.NoError(t, engine.UpdateIndexRoots([]Root{
+		{Dir: projectDir, Scope: parser.ScopeProject},
+		{Dir: userDir, Scope: parser.ScopeUser},
+	}))
+	engine.SetSourcePriority([]string{"team", "community"}, map[string]string{
+		teamFormatter:      "team",
+		communityFormatter: "community",
+	})
+
+	results, err := engine.Query("", QueryOptions{Dedupe: DedupeAll})
+	require.NoError(t, err)
+	assert.Len(t, results, 4)
+
+	results, err = engine.Query("", QueryOptions{Dedupe: DedupeEffective})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	byName := make(map[string]*parser.AgentSpec)
+	for _, agent := range results {
+		byName[agent.Name] = agent
+	}
+	assert.Equal(t, "Project reviewer agent", byName["reviewer"].Description)
+	assert.Equal(t, []string{userReviewer}, byName["reviewer"].Shadows)
+	assert.Equal(t, "Team formatter agent", byName["formatter"].Description)
+	assert.Equal(t, []string{communityFormatter}, byName["formatter"].Shadows)
+
+	// A matching copy that Claude Code does not use is dropped, not promoted
+	results, err = engine.Query("community", QueryOptions{Dedupe: DedupeEffective})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// Annotations are made on copies; the index is unchanged
+	for _, agent := range engine.GetAllAgents() {
+		assert.Empty(t, agent.Shadows)
+	}
+}
+
+func TestEngine_BrokenAgents(t *testing.T) {
+	tempDir := t.TempDir()
+	agentsDir := filepath.Join(tempDir, "agents")
+	require.NoError(t, os.MkdirAll(agentsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(agentsDir, "good.md"), []byte("---\nname: good\ndescription: Parses\n---\nPrompt"), 0644))
+	brokenPath := filepath.Join(agentsDir, "broken.md")
+	require.NoError(t, os.WriteFile(brokenPath, []byte("no frontmatter here"), 0644))
+
+	engine, err := NewEngine(filepath.Join(tempDir, "index.json"), filepath.Join(tempDir, "cache"))
+	require.NoError(t, err)
+	require.NoError(t, engine.UpdateIndexRoots([]Root{{Dir: agentsDir, Scope: parser.ScopeUser}}))
+
+	assert.Len(t, engine.GetAllAgents(), 1)
+	broken := engine.BrokenAgents()
+	require.Len(t, broken, 1)
+	assert.Equal(t, brokenPath, broken[0].Path)
+	assert.Contains(t, broken[0].Reason, "missing frontmatter")
+	assert.False(t, broken[0].ModTime.IsZero())
+
+	// Broken entries are read back from the saved index without rescanning
+	reopened, err := NewEngine(filepath.Join(tempDir, "index.json"), filepath.Join(tempDir, "cache"))
+	require.NoError(t, err)
+	assert.Len(t, reopened.BrokenAgents(), 1)
+
+	// Refreshing a repaired file clears its broken entry
+	require.NoError(t, os.WriteFile(brokenPath, []byte("---\nname: broken\ndescription: Repaired\n---\nPrompt"), 0644))
+	fresh, err := parser.NewParser().ParseFile(brokenPath)
+	require.NoError(t, err)
+	require.NoError(t, reopened.RefreshAgent(brokenPath, fresh))
+	assert.Empty(t, reopened.BrokenAgents())
+	assert.Len(t, reopened.GetAllAgents(), 2)
+
+	// Strict mode still fails the update
+	engine.SetParseMode(parser.ModeStrict)
+	require.NoError(t, os.WriteFile(brokenPath, []byte("no frontmatter here"), 0644))
+	var strictErr *parser.StrictError
+	assert.ErrorAs(t, engine.UpdateIndexRoots([]Root{{Dir: agentsDir, Scope: parser.ScopeUser}}), &strictErr)
+}
+
+func TestEngine_AutoPrune(t *testing.T) {
+	tempDir := t.TempDir()
+	agentsDir := filepath.Join(tempDir, "agents")
+	require.NoError(t, os.MkdirAll(agentsDir, 0755))
+	for _, name := range []string{"kept", "deleted"} {
+		content := "---\nname: " + name + "\ndescription: Reviews code\n---\nPrompt"
+		require.NoError(t, os.WriteFile(filepath.Join(agentsDir, name+".md"), []byte(content), 0644))
+	}
+
+	engine, err := NewEngine(filepath.Join(tempDir, "index.json"), filepath.Join(tempDir, "cache"))
+	require.NoError(t, err)
+	require.NoError(t, engine.UpdateIndex(agentsDir))
+	results, err := engine.Query("reviews", QueryOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	// Without auto-pruning the deleted agent is still returned, even from the cache
+	require.NoError(t, os.Remove(filepath.Join(agentsDir, "deleted.md")))
+	results, err = engine.Query("reviews", QueryOptions{})
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Len(t, engine.MissingEntries().Agents, 1)
+
+	engine.SetAutoPrune(true)
+	results, err = engine.Query("reviews", QueryOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "kept", results[0].Name)
+	_, err = engine.ShowAgent("deleted")
+	assert.Error(t, err)
+
+	// The pruned index was saved
+	reopened, err := NewEngine(filepath.Join(tempDir, "index.json"), filepath.Join(tempDir, "cache"))
+	require.NoError(t, err)
+	assert.Len(t, reopened.GetAllAgents(), 1)
+}
+
+func TestEngine_WarmAgents(t *testing.T) {
+	tempDir := t.TempDir()
+	agentsDir := filepath.Join(tempDir, "agents")
+	require.NoError(t, os.MkdirAll(filepath.Join(agentsDir, "team"), 0755))
+	existing := filepath.Join(agentsDir, "existing.md")
+	require.NoError(t, os.WriteFile(existing, []byte("---\nname: existing\ndescription: Indexed\n---\nPrompt"), 0644))
+	removed := filepath.Join(agentsDir, "removed.md")
+	require.NoError(t, os.WriteFile(removed, []byte("---\nname: removed\ndescription: Uninstalled\n---\nPrompt"), 0644))
+
+	roots := []Root{{Dir: agentsDir, Scope: parser.ScopeProject}}
+	engine, err := NewEngine(filepath.Join(tempDir, "index.json"), filepath.Join(tempDir, "cache"))
+	require.NoError(t, err)
+	require.NoError(t, engine.UpdateIndexRoots(roots))
+	require.Len(t, engine.GetAllAgents(), 2)
+
+	// An install writes a new agent and removes another
+	installed := filepath.Join(agentsDir, "team", "installed.md")
+	require.NoError(t, os.WriteFile(installed, []byte("---\nname: installed\ndescription: Just installed\n---\nPrompt"), 0644))
+	require.NoError(t, os.Remove(removed))
+	fresh, err := parser.NewParser().ParseFile(installed)
+	require.NoError(t, err)
+	outside, err := parser.NewParser().ParseFile(existing)
+	require.NoError(t, err)
+	outside.FilePath = filepath.Join(tempDir, "elsewhere.md")
+
+	require.NoError(t, engine.WarmAgents([]*parser.AgentSpec{fresh, outside}, roots))
+
+	reopened, err := NewEngine(filepath.Join(tempDir, "index.json"), filepath.Join(tempDir, "cache"))
+	require.NoError(t, err)
+	agents := reopened.GetAllAgents()
+	require.Len(t, agents, 2)
+	warmed := reopened.currentIndex().GetByFilename("installed.md")
+	require.NotNil(t, warmed)
+	assert.Equal(t, parser.ScopeProject, warmed.Scope)
+	assert.Equal(t, "team", warmed.Namespace)
+
+	// The next update reuses the warmed entry rather than parsing the file again
+	warmed.Description = "Reused from the index"
+	require.NoError(t, reopened.UpdateIndexRoots(roots))
+	assert.Equal(t, "Reused from the index", reopened.currentIndex().GetByFilename("installed.md").Description)
+}
+
 func TestQueryOptions_Validation(t *testing.T) {
 	tempDir := t.TempDir()
 	indexPath := filepath.Join(tempDir, "index.json")
@@ -418,7 +818,7 @@ func TestQueryOptions_Validation(t *testing.T) {
 	}
 
 	for _, agent := range agents {
-		engine.index.AddAgent(agent)
+		engine.currentIndex().AddAgent(agent)
 	}
 
 	// Test NoTools filter
@@ -438,3 +838,94 @@ func TestQueryOptions_Validation(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, results, 0)
 }
+
+func TestEngine_RebuildSwapsIndex(t *testing.T) {
+	tempDir := t.TempDir()
+	engine, err := NewEngine(filepath.Join(tempDir, "index.json"), filepath.Join(tempDir, "cache"))
+	require.NoError(t, err)
+
+	makeAgents := func(prefix string, n int) []*parser.AgentSpec {
+		agents := make([]*parser.AgentSpec, 0, n)
+		for i := 0; i < n; i++ {
+			name := fmt.Sprintf("%s-agent-%d", prefix, i)
+			agents = append(agents, &parser.AgentSpec{Name: name, Description: "Test agent", FileName: name + ".md"})
+		}
+		return agents
+	}
+
+	require.NoError(t, engine.RebuildWithAgents(makeAgents("old", 3)))
+	results, err := engine.Query("agent", QueryOptions{})
+	require.NoError(t, err)
+	assert.Len(t, results, 3)
+
+	// An index held by an in-flight query is unaffected by a rebuild
+	held := engine.currentIndex()
+	require.NoError(t, engine.RebuildWithAgents(makeAgents("new", 5)))
+	assert.Len(t, held.GetAll(), 3)
+
+	// Results cached before the swap are not served afterwards
+	results, err = engine.Query("agent", QueryOptions{})
+	require.NoError(t, err)
+	assert.Len(t, results, 5)
+
+	// Concurrent queries only ever see a complete index
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				n := len(engine.GetAllAgents())
+				if n != 3 && n != 5 {
+					t.Errorf("Saw partial index with %d agents", n)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		if i%2 == 0 {
+			require.NoError(t, engine.RebuildWithAgents(makeAgents("old", 3)))
+		} else {
+			require.NoError(t, engine.RebuildWithAgents(makeAgents("new", 5)))
+		}
+	}
+	wg.Wait()
+}
+
+func TestEngine_ReloadIfChanged(t *testing.T) {
+	tempDir := t.TempDir()
+	indexPath := filepath.Join(tempDir, "index.json")
+	agentsDir := filepath.Join(tempDir, "agents")
+	require.NoError(t, os.MkdirAll(agentsDir, 0755))
+	writeAgent := func(name string) {
+		content := fmt.Sprintf("---\nname: %s\ndescription: Agent %s\n---\nPrompt for %s.", name, name, name)
+		require.NoError(t, os.WriteFile(filepath.Join(agentsDir, name+".md"), []byte(content), 0644))
+	}
+
+	writeAgent("first")
+	reader, err := NewEngine(indexPath, filepath.Join(tempDir, "reader-cache"))
+	require.NoError(t, err)
+	require.NoError(t, reader.UpdateIndex(agentsDir))
+
+	reloaded, err := reader.ReloadIfChanged()
+	require.NoError(t, err)
+	assert.False(t, reloaded, "the reader's own save is not a newer index")
+
+	// Another process indexes a new agent
+	writeAgent("second")
+	writer, err := NewEngine(indexPath, filepath.Join(tempDir, "writer-cache"))
+	require.NoError(t, err)
+	require.NoError(t, writer.UpdateIndex(agentsDir))
+
+	results, err := reader.Query("second", QueryOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	reloaded, err = reader.ReloadIfChanged()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	results, err = reader.Query("second", QueryOptions{})
+	require.NoError(t, err)
+	assert.Len(t, results, 1, "cached results of the old index must not be served")
+}
@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+)
+
+// Provenance is where and when an agent file was installed
+type Provenance struct {
+	Source      string
+	Commit      string
+	InstalledAt time.Time
+}
+
+// SetProvenance sets the installation provenance of each tracked file, keyed
+// by path. Every index build afterwards records it on the indexed agents, and
+// clears the provenance of agents whose files are no longer tracked.
+func (e *Engine) SetProvenance(files map[string]Provenance) {
+	e.provenance = make(map[string]Provenance, len(files))
+	for path, provenance := range files {
+		e.provenance[absPath(path)] = provenance
+	}
+}
+
+// applyProvenance returns agents with their provenance joined in. Agents
+// whose provenance changes are copied, as the originals may still be held by
+// the previous index.
+func (e *Engine) applyProvenance(agents []*parser.AgentSpec) []*parser.AgentSpec {
+	if e.provenance == nil {
+		return agents
+	}
+	joined := make([]*parser.AgentSpec, len(agents))
+	for i, agent := range agents {
+		provenance := e.provenance[absPath(agent.FilePath)]
+		if agent.Source == provenance.Source && agent.SourceCommit == provenance.Commit &&
+			agent.InstalledAt.Equal(provenance.InstalledAt) {
+			joined[i] = agent
+			continue
+		}
+		copied := *agent
+		copied.Source = provenance.Source
+		copied.SourceCommit = provenance.Commit
+		copied.InstalledAt = provenance.InstalledAt
+		joined[i] = &copied
+	}
+	return joined
+}
@@ -0,0 +1,161 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/index"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+)
+
+// Ranked fields, in the order their scores are reported
+const (
+	FieldName        = "name"
+	FieldDescription = "description"
+	FieldContent     = "content"
+)
+
+// RankedFields are the fields search results are scored on
+var RankedFields = []string{FieldName, FieldDescription, FieldContent}
+
+// DefaultFieldWeights rank matches in the name above matches in the
+// description, and both above matches in the prompt body
+var DefaultFieldWeights = map[string]float64{FieldName: 3, FieldDescription: 2, FieldContent: 1}
+
+// FieldScore is one field's contribution to a relevance score
+type FieldScore struct {
+	Field   string  `json:"field"`
+	Weight  float64 `json:"weight"`
+	Matched int     `json:"matched"` // query terms found in the field
+	Exact   bool    `json:"exact,omitempty"`
+	Score   float64 `json:"score"`
+}
+
+// Score is an agent's relevance to a query, broken down by field
+type Score struct {
+	Agent  string       `json:"agent"`
+	Terms  int          `json:"terms"`
+	Total  float64      `json:"score"`
+	Fields []FieldScore `json:"fields,omitempty"`
+}
+
+// String returns a one-line breakdown of the score, such as
+// "4.00 = name 3×1/1 + content 1×1/1"
+func (s Score) String() string {
+	parts := make([]string, 0, len(s.Fields))
+	for _, field := range s.Fields {
+		part := fmt.Sprintf("%s %g×%d/%d", field.Field, field.Weight, field.Matched, s.Terms)
+		if field.Exact {
+			part += " exact"
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("%.2f (no literal match)", s.Total)
+	}
+	return fmt.Sprintf("%.2f = %s", s.Total, strings.Join(parts, " + "))
+}
+
+// SetFieldWeights sets the weights of the ranked fields. Fields missing from
+// weights keep their default weight.
+func (e *Engine) SetFieldWeights(weights map[string]float64) {
+	merged := make(map[string]float64, len(DefaultFieldWeights))
+	for field, weight := range DefaultFieldWeights {
+		merged[field] = weight
+	}
+	for field, weight := range weights {
+		merged[strings.ToLower(field)] = weight
+	}
+	e.weights = merged
+}
+
+// fieldWeights returns the configured field weights, or the defaults
+func (e *Engine) fieldWeights() map[string]float64 {
+	if e.weights == nil {
+		return DefaultFieldWeights
+	}
+	return e.weights
+}
+
+// ExplainScore scores agent against query. Each field scores its weight times
+// the fraction of query terms it contains, and a name equal to a one-term
+// query scores its weight again. A quoted phrase is one term, and a field
+// term only counts in its own field.
+func (e *Engine) ExplainScore(query string, agent *parser.AgentSpec) Score {
+	terms := rankedTerms(query)
+	score := Score{Agent: agent.QualifiedName(), Terms: len(terms)}
+	if len(terms) == 0 {
+		return score
+	}
+
+	weights := e.fieldWeights()
+	for _, field := range RankedFields {
+		weight := weights[field]
+		if weight == 0 {
+			continue
+		}
+		value := strings.ToLower(rankedValue(agent, field))
+		fieldScore := FieldScore{Field: field, Weight: weight}
+		for _, term := range terms {
+			if term.Field != "" && term.Field != field {
+				continue
+			}
+			if value == term.Value || (!term.Exact && strings.Contains(value, term.Value)) {
+				fieldScore.Matched++
+			}
+		}
+		if fieldScore.Matched == 0 {
+			continue
+		}
+		fieldScore.Score = weight * float64(fieldScore.Matched) / float64(len(terms))
+		if field == FieldName && len(terms) == 1 && value == terms[0].Value {
+			fieldScore.Exact = true
+			fieldScore.Score += weight
+		}
+		score.Total += fieldScore.Score
+		score.Fields = append(score.Fields, fieldScore)
+	}
+	return score
+}
+
+// rankedTerms returns the terms of query that can match a ranked field
+func rankedTerms(query string) []index.Term {
+	var terms []index.Term
+	for _, term := range index.ParseQuery(query) {
+		switch term.Field {
+		case "", FieldName, FieldDescription, FieldContent:
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// Rank orders agents by their score for query, highest first. Agents with
+// equal scores keep their order, so fuzzy matches without a literal match
+// stay in fuzzy relevance order after the literal matches.
+func (e *Engine) Rank(query string, agents []*parser.AgentSpec) []*parser.AgentSpec {
+	if strings.TrimSpace(query) == "" || len(agents) < 2 {
+		return agents
+	}
+	scores := make(map[*parser.AgentSpec]float64, len(agents))
+	for _, agent := range agents {
+		scores[agent] = e.ExplainScore(query, agent).Total
+	}
+	ranked := make([]*parser.AgentSpec, len(agents))
+	copy(ranked, agents)
+	sort.SliceStable(ranked, func(i, j int) bool { return scores[ranked[i]] > scores[ranked[j]] })
+	return ranked
+}
+
+// rankedValue returns the text of a ranked field
+func rankedValue(agent *parser.AgentSpec, field string) string {
+	switch field {
+	case FieldName:
+		return agent.Name
+	case FieldDescription:
+		return agent.Description
+	default:
+		return agent.Prompt
+	}
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rankedEngine(t *testing.T) *Engine {
+	t.Helper()
+	tempDir := t.TempDir()
+	engine, err := NewEngine(filepath.Join(tempDir, "index.json"), filepath.Join(tempDir, "cache"))
+	require.NoError(t, err)
+
+	// Indexed with the weakest match first
+	for _, agent := range []*parser.AgentSpec{
+		{Name: "planner", Description: "Plans work", FileName: "planner.md", Prompt: "Plan, then review the plan"},
+		{Name: "writer", Description: "Writes docs after review", FileName: "writer.md", Prompt: "You write"},
+		{Name: "reviewer", Description: "Reviews code", FileName: "reviewer.md", Prompt: "You review code"},
+	} {
+		engine.currentIndex().AddAgent(agent)
+	}
+	return engine
+}
+
+func names(agents []*parser.AgentSpec) []string {
+	result := make([]string, 0, len(agents))
+	for _, agent := range agents {
+		result = append(result, agent.Name)
+	}
+	return result
+}
+
+func TestEngine_QueryRanksByFieldWeight(t *testing.T) {
+	engine := rankedEngine(t)
+
+	results, err := engine.Query("review", QueryOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reviewer", "writer", "planner"}, names(results))
+
+	// The limit applies after ranking
+	results, err = engine.Query("review", QueryOptions{Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reviewer"}, names(results))
+
+	// Prompt matches outrank the rest once content weighs the most
+	engine.SetFieldWeights(map[string]float64{"content": 10})
+	results, err = engine.Query("review", QueryOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reviewer", "planner", "writer"}, names(results))
+}
+
+func TestEngine_ExplainScore(t *testing.T) {
+	engine := rankedEngine(t)
+	reviewer := engine.FindAgents("reviewer")[0]
+
+	score := engine.ExplainScore("reviewer", reviewer)
+	assert.Equal(t, 6.0, score.Total) // name 3, exact name 3
+	require.Len(t, score.Fields, 1)
+	assert.True(t, score.Fields[0].Exact)
+
+	score = engine.ExplainScore("review code", reviewer)
+	assert.Equal(t, 2, score.Terms)
+	assert.Equal(t, 1.5+2+1, score.Total) // name 1 of 2 terms, description and content both
+	assert.Equal(t, "4.50 = name 3×1/2 + description 2×2/2 + content 1×2/2", score.String())
+
+	engine.SetFieldWeights(map[string]float64{"name": 0})
+	score = engine.ExplainScore("reviewer", reviewer)
+	assert.Zero(t, score.Total)
+	assert.Equal(t, "0.00 (no literal match)", score.String())
+}
+
+func TestEngine_RankKeepsOrderOfTies(t *testing.T) {
+	engine := rankedEngine(t)
+	agents := engine.GetAllAgents()
+
+	assert.Equal(t, names(agents), names(engine.Rank("unmatched", agents)))
+	assert.Equal(t, names(agents), names(engine.Rank("", agents)))
+}
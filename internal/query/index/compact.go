@@ -0,0 +1,335 @@
+package index
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+)
+
+// largestEntries is how many of the biggest entries a size report lists
+const largestEntries = 5
+
+// Layout controls how the index is written to disk
+type Layout struct {
+	// Compact writes the index without indentation
+	Compact bool
+	// StripPrompts leaves prompt bodies out of the saved index; they are
+	// re-read from the agent files when the index is loaded
+	StripPrompts bool
+}
+
+// Layout returns how the index is written to disk
+func (im *IndexManager) Layout() Layout {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+
+	return im.layout
+}
+
+// SetLayout sets how the index is written to disk by the next save
+func (im *IndexManager) SetLayout(layout Layout) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	im.layout = layout
+}
+
+// encode renders the index in its layout (caller must hold a lock)
+func (im *IndexManager) encode() ([]byte, error) {
+	file := indexFile{
+		Version:         FormatVersion,
+		Generation:      im.generation,
+		Agents:          im.agents,
+		Broken:          im.broken,
+		Compact:         im.layout.Compact,
+		PromptsStripped: im.layout.StripPrompts,
+	}
+	if im.layout.StripPrompts {
+		file.Agents = make([]*parser.AgentSpec, len(im.agents))
+		for n, agent := range im.agents {
+			stripped := *agent
+			stripped.Prompt = ""
+			file.Agents[n] = &stripped
+		}
+	}
+	if im.layout.Compact {
+		return json.Marshal(file)
+	}
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// hydratePrompts re-reads the prompt bodies left out of a stripped index.
+// Agents whose files cannot be read keep an empty prompt.
+func hydratePrompts(agents []*parser.AgentSpec) {
+	for _, agent := range agents {
+		if agent.Prompt != "" || agent.FilePath == "" {
+			continue
+		}
+		if prompt, err := parser.ReadPrompt(agent.FilePath); err == nil {
+			agent.Prompt = prompt
+		}
+	}
+}
+
+// EntrySize is the size of one agent entry in the saved index
+type EntrySize struct {
+	FilePath string `json:"file_path"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// SizeReport breaks the saved index file down by component
+type SizeReport struct {
+	// FileBytes is the size of the index file on disk
+	FileBytes    int64 `json:"file_bytes"`
+	Prompts      int64 `json:"prompts"`
+	Descriptions int64 `json:"descriptions"`
+	// Metadata covers every other agent field
+	Metadata int64 `json:"metadata"`
+	// Broken covers the records of files that failed to parse
+	Broken int64 `json:"broken"`
+	// Formatting is indentation and other layout overhead
+	Formatting int64 `json:"formatting"`
+
+	Entries int `json:"entries"`
+	// Orphaned entries refer to agent files that no longer exist
+	Orphaned int `json:"orphaned"`
+	// Duplicates repeat the file path of an earlier entry
+	Duplicates int `json:"duplicates"`
+
+	Compact         bool        `json:"compact"`
+	PromptsStripped bool        `json:"prompts_stripped"`
+	Largest         []EntrySize `json:"largest"`
+}
+
+// SizeReport reads the saved index file and reports the bytes taken by each
+// component, along with the entries compaction would drop
+func (im *IndexManager) SizeReport() (*SizeReport, error) {
+	report := &SizeReport{}
+	if im.path == "" {
+		return report, nil
+	}
+	data, err := os.ReadFile(im.path)
+	if os.IsNotExist(err) {
+		return report, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	report.FileBytes = int64(len(data))
+
+	version, err := formatVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	loader, ok := loaders[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: version %d (supported up to %d)", ErrUnsupportedFormat, version, FormatVersion)
+	}
+	file, err := loader(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode index format version %d: %w", version, err)
+	}
+	report.Compact = file.Compact
+	report.PromptsStripped = file.PromptsStripped
+	report.Entries = len(file.Agents)
+
+	var content int64
+	for _, agent := range file.Agents {
+		entry := jsonSize(agent)
+		prompt := jsonSize(agent.Prompt)
+		description := jsonSize(agent.Description)
+		report.Prompts += prompt
+		report.Descriptions += description
+		report.Metadata += entry - prompt - description
+		report.Largest = append(report.Largest, EntrySize{FilePath: agent.FilePath, Bytes: entry})
+		content += entry
+	}
+	if len(file.Broken) > 0 {
+		report.Broken = jsonSize(file.Broken)
+	}
+	if formatting := report.FileBytes - content - report.Broken; formatting > 0 {
+		report.Formatting = formatting
+	}
+
+	sort.SliceStable(report.Largest, func(a, b int) bool { return report.Largest[a].Bytes > report.Largest[b].Bytes })
+	if len(report.Largest) > largestEntries {
+		report.Largest = report.Largest[:largestEntries]
+	}
+
+	deduped := dedupeEntries(file.Agents)
+	report.Duplicates = len(file.Agents) - len(deduped)
+	report.Orphaned = len(deduped) - len(existingEntries(deduped))
+	return report, nil
+}
+
+// jsonSize returns the encoded size of v in bytes
+func jsonSize(v interface{}) int64 {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return int64(len(data))
+}
+
+// CompactResult describes what a compaction removed
+type CompactResult struct {
+	Orphaned    int   `json:"orphaned"`
+	Duplicates  int   `json:"duplicates"`
+	Broken      int   `json:"broken"`
+	BytesBefore int64 `json:"bytes_before"`
+	BytesAfter  int64 `json:"bytes_after"`
+}
+
+// Compact drops entries for agent files that no longer exist, duplicate
+// entries for the same file and broken records for missing files, then saves
+// the index in layout
+func (im *IndexManager) Compact(layout Layout) (*CompactResult, error) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	result := &CompactResult{}
+	if info, err := os.Stat(im.path); err == nil {
+		result.BytesBefore = info.Size()
+	}
+
+	deduped := dedupeEntries(im.agents)
+	kept := existingEntries(deduped)
+	result.Duplicates = len(im.agents) - len(deduped)
+	result.Orphaned = len(deduped) - len(kept)
+
+	var broken []parser.ParseFailure
+	for _, failure := range im.broken {
+		if _, err := os.Stat(failure.Path); err == nil {
+			broken = append(broken, failure)
+		}
+	}
+	result.Broken = len(im.broken) - len(broken)
+
+	im.agents = kept
+	im.broken = broken
+	im.byName = make(map[string]*parser.AgentSpec)
+	im.byFile = make(map[string][]*parser.AgentSpec)
+	for _, agent := range kept {
+		im.addLookups(agent)
+	}
+	im.layout = layout
+
+	if err := im.save(); err != nil {
+		return nil, fmt.Errorf("failed to save index: %w", err)
+	}
+	if info, err := os.Stat(im.path); err == nil {
+		result.BytesAfter = info.Size()
+	}
+	return result, nil
+}
+
+// PruneResult lists the index entries whose files no longer exist
+type PruneResult struct {
+	Agents []*parser.AgentSpec
+	Broken []parser.ParseFailure
+}
+
+// Dropped returns the number of entries the prune dropped
+func (r *PruneResult) Dropped() int {
+	return len(r.Agents) + len(r.Broken)
+}
+
+// Missing returns the entries whose files no longer exist, without changing the index
+func (im *IndexManager) Missing() *PruneResult {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+
+	result, _, _ := im.partitionMissing()
+	return result
+}
+
+// PruneMissing drops the entries whose files no longer exist, such as agents
+// deleted outside agent-manager, and saves the index when any were dropped.
+// Unlike a rebuild it only stats the indexed paths.
+func (im *IndexManager) PruneMissing() (*PruneResult, error) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	result, agents, broken := im.partitionMissing()
+	if result.Dropped() == 0 {
+		return result, nil
+	}
+
+	im.agents = agents
+	im.broken = broken
+	im.byName = make(map[string]*parser.AgentSpec)
+	im.byFile = make(map[string][]*parser.AgentSpec)
+	for _, agent := range agents {
+		im.addLookups(agent)
+	}
+	if err := im.save(); err != nil {
+		return nil, fmt.Errorf("failed to save index: %w", err)
+	}
+	return result, nil
+}
+
+// partitionMissing splits the entries into those whose files are missing and
+// the agents and broken records to keep (caller must hold a lock)
+func (im *IndexManager) partitionMissing() (*PruneResult, []*parser.AgentSpec, []parser.ParseFailure) {
+	result := &PruneResult{}
+	var agents []*parser.AgentSpec
+	for _, agent := range im.agents {
+		if _, err := os.Stat(agent.FilePath); os.IsNotExist(err) {
+			result.Agents = append(result.Agents, agent)
+		} else {
+			agents = append(agents, agent)
+		}
+	}
+	var broken []parser.ParseFailure
+	for _, failure := range im.broken {
+		if _, err := os.Stat(failure.Path); os.IsNotExist(err) {
+			result.Broken = append(result.Broken, failure)
+		} else {
+			broken = append(broken, failure)
+		}
+	}
+	return result, agents, broken
+}
+
+// existingEntries returns the entries whose agent files exist
+func existingEntries(agents []*parser.AgentSpec) []*parser.AgentSpec {
+	var kept []*parser.AgentSpec
+	for _, agent := range agents {
+		if _, err := os.Stat(agent.FilePath); err == nil {
+			kept = append(kept, agent)
+		}
+	}
+	return kept
+}
+
+// dedupeEntries keeps the last entry for each file path, which is the most
+// recently indexed, in the order of first appearance
+func dedupeEntries(agents []*parser.AgentSpec) []*parser.AgentSpec {
+	last := make(map[string]int, len(agents))
+	for n, agent := range agents {
+		last[entryKey(agent)] = n
+	}
+	deduped := make([]*parser.AgentSpec, 0, len(last))
+	seen := make(map[string]bool, len(last))
+	for _, agent := range agents {
+		key := entryKey(agent)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, agents[last[key]])
+	}
+	return deduped
+}
+
+// entryKey identifies the file an entry was indexed from
+func entryKey(agent *parser.AgentSpec) string {
+	if abs, err := filepath.Abs(agent.FilePath); err == nil {
+		return abs
+	}
+	return agent.FilePath
+}
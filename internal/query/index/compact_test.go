@@ -0,0 +1,123 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+)
+
+func TestCompact(t *testing.T) {
+	tmpDir := t.TempDir()
+	indexPath := filepath.Join(tmpDir, "index.json")
+
+	reviewer := filepath.Join(tmpDir, "reviewer.md")
+	if err := os.WriteFile(reviewer, []byte("---\nname: reviewer\ndescription: Reviews\n---\nReview the diff carefully\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	im := NewIndexManagerFromAgents(indexPath, []*parser.AgentSpec{
+		{Name: "reviewer", FileName: "reviewer.md", FilePath: reviewer, Prompt: "stale prompt"},
+		{Name: "gone", FileName: "gone.md", FilePath: filepath.Join(tmpDir, "gone.md"), Prompt: strings.Repeat("x", 500)},
+		{Name: "reviewer", FileName: "reviewer.md", FilePath: reviewer, Prompt: "Review the diff carefully"},
+	})
+	im.SetBroken([]parser.ParseFailure{{Path: filepath.Join(tmpDir, "deleted.md"), Reason: "bad"}})
+	if err := im.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := im.SizeReport()
+	if err != nil {
+		t.Fatalf("SizeReport() error = %v", err)
+	}
+	if report.Entries != 3 || report.Orphaned != 1 || report.Duplicates != 1 {
+		t.Errorf("Unexpected entry counts %+v", report)
+	}
+	if report.Prompts < 500 || report.Formatting == 0 || report.Broken == 0 {
+		t.Errorf("Unexpected component sizes %+v", report)
+	}
+	if sum := report.Prompts + report.Descriptions + report.Metadata + report.Broken + report.Formatting; sum != report.FileBytes {
+		t.Errorf("Components add up to %d bytes, file has %d", sum, report.FileBytes)
+	}
+	if report.Largest[0].FilePath != filepath.Join(tmpDir, "gone.md") {
+		t.Errorf("Expected the largest entry first, got %+v", report.Largest)
+	}
+
+	result, err := im.Compact(Layout{Compact: true, StripPrompts: true})
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if result.Orphaned != 1 || result.Duplicates != 1 || result.Broken != 1 || result.BytesAfter >= result.BytesBefore {
+		t.Errorf("Unexpected compaction result %+v", result)
+	}
+	if agents := im.GetAll(); len(agents) != 1 || agents[0].Prompt != "Review the diff carefully" {
+		t.Errorf("Expected the latest reviewer entry to be kept, got %+v", agents)
+	}
+
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "Review the diff") || strings.Contains(string(data), "\n") {
+		t.Errorf("Expected a compact index without prompts, got %s", data)
+	}
+
+	// Stripped prompts are re-read from disk, and the layout is kept
+	reloaded, err := NewIndexManager(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agent := reloaded.GetByFilename("reviewer.md"); agent == nil || agent.Prompt != "Review the diff carefully" {
+		t.Errorf("Expected the prompt to be re-read from disk, got %+v", agent)
+	}
+	if layout := reloaded.Layout(); !layout.Compact || !layout.StripPrompts {
+		t.Errorf("Expected the layout to be kept, got %+v", layout)
+	}
+}
+
+func TestPruneMissing(t *testing.T) {
+	tmpDir := t.TempDir()
+	indexPath := filepath.Join(tmpDir, "index.json")
+
+	kept := filepath.Join(tmpDir, "kept.md")
+	if err := os.WriteFile(kept, []byte("---\nname: kept\n---\nPrompt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	im := NewIndexManagerFromAgents(indexPath, []*parser.AgentSpec{
+		{Name: "kept", FileName: "kept.md", FilePath: kept},
+		{Name: "gone", FileName: "gone.md", FilePath: filepath.Join(tmpDir, "gone.md")},
+	})
+	im.SetBroken([]parser.ParseFailure{{Path: filepath.Join(tmpDir, "deleted.md"), Reason: "bad"}})
+	if err := im.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if missing := im.Missing(); missing.Dropped() != 2 || len(im.GetAll()) != 2 {
+		t.Fatalf("Expected Missing to report 2 entries without dropping them, got %+v", missing)
+	}
+
+	result, err := im.PruneMissing()
+	if err != nil {
+		t.Fatalf("PruneMissing() error = %v", err)
+	}
+	if len(result.Agents) != 1 || result.Agents[0].Name != "gone" || len(result.Broken) != 1 {
+		t.Errorf("Unexpected prune result %+v", result)
+	}
+	if im.GetByFilename("gone.md") != nil || im.GetByFilename("kept.md") == nil || len(im.Broken()) != 0 {
+		t.Error("Expected only the missing entries to be dropped")
+	}
+
+	reloaded, err := NewIndexManager(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agents := reloaded.GetAll(); len(agents) != 1 {
+		t.Errorf("Expected the pruned index to be saved, got %d agents", len(agents))
+	}
+
+	if result, err := im.PruneMissing(); err != nil || result.Dropped() != 0 {
+		t.Errorf("Expected nothing left to prune, got %+v (%v)", result, err)
+	}
+}
@@ -0,0 +1,270 @@
+package index
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+)
+
+// Boolean operators of an Expr
+const (
+	OpAnd = "and"
+	OpOr  = "or"
+	OpNot = "not"
+)
+
+// tokenKind is the kind of one token of a query
+type tokenKind int
+
+const (
+	tokenTerm tokenKind = iota
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+// operators maps the words lexQuery reads as operators to their tokens
+var operators = map[string]tokenKind{
+	"AND": tokenAnd,
+	"OR":  tokenOr,
+	"NOT": tokenNot,
+}
+
+// token is one term, operator or parenthesis of a query
+type token struct {
+	kind tokenKind
+	term Term
+}
+
+// Expr is a parsed boolean query: a single term, or an operator applied to
+// its arguments. A nil Expr, from an empty query, matches every agent.
+type Expr struct {
+	Op   string  // empty for a term, else OpAnd, OpOr or OpNot
+	Term Term    // the term when Op is empty
+	Args []*Expr // the operands of the operator; NOT has one
+}
+
+// ParseExpr parses a query into a boolean expression. Terms are read as by
+// ParseQuery and joined with AND, OR and NOT, where terms next to each other
+// are joined with AND. NOT binds tightest and OR loosest, and parentheses
+// group, so name:go AND tools:Bash NOT source:local finds Go agents granted
+// Bash that were not installed from local sources.
+func ParseExpr(query string) (*Expr, error) {
+	p := &exprParser{tokens: lexQuery(query)}
+	if len(p.tokens) == 0 {
+		return nil, nil
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("invalid query: unmatched )")
+	}
+	return expr, nil
+}
+
+// exprParser is a recursive descent parser over the tokens of a query
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+// next returns the kind of the next token and whether there is one
+func (p *exprParser) next() (tokenKind, bool) {
+	if p.pos >= len(p.tokens) {
+		return 0, false
+	}
+	return p.tokens[p.pos].kind, true
+}
+
+// parseOr parses operands joined with OR
+func (p *exprParser) parseOr() (*Expr, error) {
+	operands, err := p.parseOperands(tokenOr, p.parseAnd)
+	if err != nil || len(operands) == 1 {
+		return first(operands), err
+	}
+	return &Expr{Op: OpOr, Args: operands}, nil
+}
+
+// parseAnd parses operands joined with AND or written next to each other
+func (p *exprParser) parseAnd() (*Expr, error) {
+	operands, err := p.parseOperands(tokenAnd, p.parseUnary)
+	if err != nil || len(operands) == 1 {
+		return first(operands), err
+	}
+	return &Expr{Op: OpAnd, Args: operands}, nil
+}
+
+// parseOperands parses operands with parse, separated by the operator op;
+// for AND, the operator may be left out
+func (p *exprParser) parseOperands(op tokenKind, parse func() (*Expr, error)) ([]*Expr, error) {
+	operand, err := parse()
+	if err != nil {
+		return nil, err
+	}
+	operands := []*Expr{operand}
+	for {
+		kind, ok := p.next()
+		switch {
+		case !ok:
+			return operands, nil
+		case kind == op:
+			p.pos++
+		case op == tokenAnd && (kind == tokenTerm || kind == tokenNot || kind == tokenOpen):
+		default:
+			return operands, nil
+		}
+		operand, err := parse()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+}
+
+// parseUnary parses a term, a negated operand or a parenthesized group
+func (p *exprParser) parseUnary() (*Expr, error) {
+	kind, ok := p.next()
+	switch {
+	case ok && kind == tokenTerm:
+		p.pos++
+		return &Expr{Term: p.tokens[p.pos-1].term}, nil
+	case ok && kind == tokenNot:
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Expr{Op: OpNot, Args: []*Expr{operand}}, nil
+	case ok && kind == tokenOpen:
+		p.pos++
+		group, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if kind, ok := p.next(); !ok || kind != tokenClose {
+			return nil, fmt.Errorf("invalid query: missing )")
+		}
+		p.pos++
+		return group, nil
+	}
+	return nil, fmt.Errorf("invalid query: %s", p.missingOperand())
+}
+
+// missingOperand describes why no operand starts at the parser's position
+func (p *exprParser) missingOperand() string {
+	previous := tokenOpen
+	if p.pos > 0 {
+		previous = p.tokens[p.pos-1].kind
+	}
+	kind, ok := p.next()
+	switch {
+	case previous != tokenOpen:
+		return operatorName(previous) + " needs a term after it"
+	case !ok:
+		return "missing )"
+	case kind == tokenClose && p.pos == 0:
+		return "unmatched )"
+	case kind == tokenClose:
+		return "empty ()"
+	default:
+		return operatorName(kind) + " needs a term before it"
+	}
+}
+
+// operatorName returns how an operator is written in a query
+func operatorName(kind tokenKind) string {
+	for name, op := range operators {
+		if op == kind {
+			return name
+		}
+	}
+	return ""
+}
+
+// first returns the only operand of a single-operand list
+func first(operands []*Expr) *Expr {
+	if len(operands) == 0 {
+		return nil
+	}
+	return operands[0]
+}
+
+// Match reports whether agent matches the expression
+func (e *Expr) Match(agent *parser.AgentSpec) bool {
+	if e == nil {
+		return true
+	}
+	switch e.Op {
+	case OpAnd:
+		for _, arg := range e.Args {
+			if !arg.Match(agent) {
+				return false
+			}
+		}
+		return true
+	case OpOr:
+		for _, arg := range e.Args {
+			if arg.Match(agent) {
+				return true
+			}
+		}
+		return false
+	case OpNot:
+		return !e.Args[0].Match(agent)
+	default:
+		return e.Term.Match(agent)
+	}
+}
+
+// Terms returns the terms an agent matching the expression may contain,
+// leaving out those under NOT
+func (e *Expr) Terms() []Term {
+	if e == nil {
+		return nil
+	}
+	switch e.Op {
+	case "":
+		return []Term{e.Term}
+	case OpNot:
+		return nil
+	}
+	var terms []Term
+	for _, arg := range e.Args {
+		terms = append(terms, arg.Terms()...)
+	}
+	return terms
+}
+
+// String returns the expression with every group parenthesized, as in
+// (name:go AND (NOT source:local))
+func (e *Expr) String() string {
+	if e == nil {
+		return ""
+	}
+	switch e.Op {
+	case "":
+		value := e.Term.Value
+		if e.Term.Phrase {
+			value = `"` + value + `"`
+		}
+		if e.Term.Exact {
+			value = "=" + value
+		}
+		if e.Term.Field != "" {
+			value = e.Term.Field + ":" + value
+		}
+		return value
+	case OpNot:
+		return "(NOT " + e.Args[0].String() + ")"
+	}
+	parts := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		parts[i] = arg.String()
+	}
+	return "(" + strings.Join(parts, " "+strings.ToUpper(e.Op)+" ") + ")"
+}
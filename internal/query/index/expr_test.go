@@ -0,0 +1,108 @@
+package index
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestParseExpr tests operator precedence, grouping and syntax errors
+func TestParseExpr(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected string
+		err      string
+	}{
+		{"", "", ""},
+		{"go", "go", ""},
+		{"go expert", "(go AND expert)", ""},
+		{"name:go AND tools:Bash NOT source:local", "(name:go AND tools:bash AND (NOT source:local))", ""},
+		{"a OR b c", "(a OR (b AND c))", ""},
+		{"(a OR b) c", "((a OR b) AND c)", ""},
+		{"NOT (a OR b)", "(NOT (a OR b))", ""},
+		{`"AND" or and`, `("and" AND or AND and)`, ""},
+		{`description:"code (review)" OR name:=go-expert`, `(description:"code (review)" OR name:=go-expert)`, ""},
+		{"tools:Bash)", "", "unmatched )"},
+		{"(a OR b", "", "missing )"},
+		{"()", "", "empty ()"},
+		{"a AND", "", "AND needs a term after it"},
+		{"OR a", "", "OR needs a term before it"},
+		{"a NOT", "", "NOT needs a term after it"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			expr, err := ParseExpr(tc.query)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("ParseExpr(%q) error = %v, want %q", tc.query, err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseExpr(%q) failed: %v", tc.query, err)
+			}
+			if got := expr.String(); got != tc.expected {
+				t.Errorf("ParseExpr(%q) = %s, want %s", tc.query, got, tc.expected)
+			}
+		})
+	}
+
+	// Negated terms are not terms a match contains
+	terms := ParseQuery("review NOT draft")
+	if !reflect.DeepEqual(terms, []Term{{Value: "review"}}) {
+		t.Errorf("Expected only the positive term, got %+v", terms)
+	}
+	if !IsStructured("review OR audit") || !IsStructured("(review)") {
+		t.Error("Expected operators and groups to make a query structured")
+	}
+}
+
+// TestSearch_BooleanOperators tests searches combining terms with operators
+func TestSearch_BooleanOperators(t *testing.T) {
+	im, err := NewIndexManager(filepath.Join(t.TempDir(), "test-index.json"))
+	if err != nil {
+		t.Fatalf("NewIndexManager failed: %v", err)
+	}
+	local := createTestAgent("go-local", "Golang helper", []string{"Bash"}, "Go")
+	local.Source = "local"
+	im.AddAgent(local)
+	im.AddAgent(createTestAgent("go-expert", "Golang expert", []string{"Bash", "Read"}, "Go"))
+	im.AddAgent(createTestAgent("go-reader", "Golang reader", []string{"Read"}, "Go"))
+	im.AddAgent(createTestAgent("python-pro", "Python expert", []string{"Bash"}, "Python"))
+
+	testCases := []struct {
+		query    string
+		expected []string
+	}{
+		{"name:go AND tools:Bash NOT source:local", []string{"go-expert"}},
+		{"name:go tools:bash", []string{"go-expert", "go-local"}},
+		{"name:python OR name:reader", []string{"go-reader", "python-pro"}},
+		{"(name:python OR name:reader) tools:bash", []string{"python-pro"}},
+		{"NOT name:go", []string{"python-pro"}},
+		{`NOT description:"golang"`, []string{"python-pro"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			results, err := im.Search(tc.query, QueryOptions{})
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			names := []string{}
+			for _, result := range results {
+				names = append(names, result.Name)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tc.expected) {
+				t.Errorf("Search(%q) = %v, want %v", tc.query, names, tc.expected)
+			}
+		})
+	}
+
+	if _, err := im.Search("(name:go", QueryOptions{}); err == nil {
+		t.Error("Expected an error for an unbalanced query")
+	}
+}
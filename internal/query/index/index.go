@@ -1,23 +1,78 @@
 package index
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 )
 
+// FormatVersion is the version of the persisted index format. Bump it when the
+// saved layout or the meaning of a field changes and register a loader for the
+// previous version in loaders so existing indexes are migrated.
+const FormatVersion = 2
+
+// ErrUnsupportedFormat is returned when a persisted index has a format version
+// this build cannot read; such an index is discarded and rebuilt
+var ErrUnsupportedFormat = errors.New("unsupported index format")
+
+// indexFile is the persisted index layout from format version 1 on. Version
+// 2 added the layout flags; an empty prompt may then mean a stripped one.
+type indexFile struct {
+	Version int `json:"version"`
+	// Generation counts the saves of the index, so processes holding it
+	// loaded can tell that another process saved a newer one. It is written
+	// right after the version so readGeneration only decodes the header.
+	Generation uint64              `json:"generation,omitempty"`
+	Agents     []*parser.AgentSpec `json:"agents"`
+	// Broken lists the agent files that failed to parse when the index was built
+	Broken []parser.ParseFailure `json:"broken,omitempty"`
+	// Compact and PromptsStripped record the layout the index was saved with
+	Compact         bool `json:"compact,omitempty"`
+	PromptsStripped bool `json:"prompts_stripped,omitempty"`
+}
+
+// loaders decode each supported format version into the current layout.
+// Version 0 is the unversioned bare JSON array written by earlier releases.
+var loaders = map[int]func(data []byte) (*indexFile, error){
+	0: func(data []byte) (*indexFile, error) {
+		var agents []*parser.AgentSpec
+		err := json.Unmarshal(data, &agents)
+		return &indexFile{Agents: agents}, err
+	},
+	1: decodeIndexFile,
+	2: decodeIndexFile,
+}
+
+// decodeIndexFile decodes the versioned index layout
+func decodeIndexFile(data []byte) (*indexFile, error) {
+	var file indexFile
+	err := json.Unmarshal(data, &file)
+	return &file, err
+}
+
 // IndexManager manages agent indices
 type IndexManager struct {
 	mu     sync.RWMutex
 	agents []*parser.AgentSpec
 	byName map[string]*parser.AgentSpec
-	byFile map[string]*parser.AgentSpec
+	byFile map[string][]*parser.AgentSpec
+	broken []parser.ParseFailure
 	path   string
+	stale  bool
+	layout Layout
+	// generation is the generation of the index last loaded or saved
+	generation uint64
 }
 
 // QueryOptions for searches
@@ -28,6 +83,21 @@ type QueryOptions struct {
 	Regex       bool
 	Source      string
 	After       time.Time
+	Names       []string // Only agents with one of these names, as AgentSpec.HasName matches
+}
+
+// HasAnyName reports whether agent has one of names; every agent does when
+// names is empty
+func HasAnyName(agent *parser.AgentSpec, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	for _, name := range names {
+		if agent.HasName(name) {
+			return true
+		}
+	}
+	return false
 }
 
 // NewIndexManager creates a new index manager
@@ -35,36 +105,110 @@ func NewIndexManager(path string) (*IndexManager, error) {
 	im := &IndexManager{
 		agents: make([]*parser.AgentSpec, 0),
 		byName: make(map[string]*parser.AgentSpec),
-		byFile: make(map[string]*parser.AgentSpec),
+		byFile: make(map[string][]*parser.AgentSpec),
 		path:   path,
 	}
 
-	// Load existing index if available
-	if err := im.load(); err != nil {
-		// Start with empty index if load fails
-		fmt.Fprintf(os.Stderr, "Warning: failed to load index from %s: %v\n", path, err)
+	// Load existing index if available; an unreadable index is treated as
+	// stale so callers rebuild it instead of querying wrong results
+	if err := im.load(); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Warning: rebuilding stale index %s: %v\n", path, err)
+		im.stale = true
 	}
 
 	return im, nil
 }
 
+// NewIndexManagerFromAgents creates an index manager holding agents without
+// loading anything from disk; used to build a shadow index before swapping it in
+func NewIndexManagerFromAgents(path string, agents []*parser.AgentSpec) *IndexManager {
+	im := &IndexManager{
+		agents: agents,
+		byName: make(map[string]*parser.AgentSpec, len(agents)),
+		byFile: make(map[string][]*parser.AgentSpec, len(agents)),
+		path:   path,
+	}
+	for _, agent := range agents {
+		im.addLookups(agent)
+	}
+	return im
+}
+
+// Stale reports whether the persisted index could not be loaded and must be rebuilt
+func (im *IndexManager) Stale() bool {
+	return im.stale
+}
+
+// Path returns the file the index is saved to
+func (im *IndexManager) Path() string {
+	return im.path
+}
+
+// Generation returns the generation of the index last loaded from or saved to disk
+func (im *IndexManager) Generation() uint64 {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+	return im.generation
+}
+
 // AddAgent adds an agent to the index
 func (im *IndexManager) AddAgent(agent *parser.AgentSpec) {
 	im.mu.Lock()
 	defer im.mu.Unlock()
 
 	im.agents = append(im.agents, agent)
-	im.byName[agent.Name] = agent
-	im.byFile[agent.FileName] = agent
+	im.addLookups(agent)
+}
+
+// addLookups registers an agent in the name and file lookup maps, under both
+// its plain and namespace-qualified names (caller must hold the write lock).
+// Files keep every agent sharing a name, such as helper.md in two namespaces.
+func (im *IndexManager) addLookups(agent *parser.AgentSpec) {
+	set := func(lookup map[string]*parser.AgentSpec, key string) {
+		// A shadowed user agent never hides the project agent overriding it
+		if existing := lookup[key]; existing != nil && agent.Shadowed && !existing.Shadowed {
+			return
+		}
+		lookup[key] = agent
+	}
+	add := func(key string) {
+		var kept []*parser.AgentSpec
+		for _, existing := range im.byFile[key] {
+			if existing.FilePath == agent.FilePath && existing.QualifiedName() == agent.QualifiedName() {
+				continue // re-added, as when an entry is refreshed
+			}
+			if existing.QualifiedName() == agent.QualifiedName() && existing.Shadowed != agent.Shadowed {
+				// Only the project agent overriding a user agent is looked up
+				if agent.Shadowed {
+					return
+				}
+				continue
+			}
+			kept = append(kept, existing)
+		}
+		im.byFile[key] = append(kept, agent)
+	}
+
+	set(im.byName, agent.Name)
+	add(agent.FileName)
+	if agent.Namespace != "" {
+		set(im.byName, agent.QualifiedName())
+		add(agent.Namespace + "/" + agent.FileName)
+	}
 }
 
-// Search performs a simple text search
+// Search performs a text search; agents must match the query as parsed by
+// ParseExpr, so every term unless the query uses OR or NOT
 func (im *IndexManager) Search(query string, opts QueryOptions) ([]*parser.AgentSpec, error) {
+	expr, err := ParseExpr(query)
+	if err != nil {
+		return nil, err
+	}
+
 	im.mu.RLock()
 	defer im.mu.RUnlock()
 
 	var results []*parser.AgentSpec
-	query = strings.ToLower(query)
 
 	for _, agent := range im.agents {
 		// Apply filters
@@ -72,6 +216,10 @@ func (im *IndexManager) Search(query string, opts QueryOptions) ([]*parser.Agent
 			continue
 		}
 
+		if !HasAnyName(agent, opts.Names) {
+			continue
+		}
+
 		if !opts.After.IsZero() && agent.InstalledAt.Before(opts.After) {
 			continue
 		}
@@ -84,11 +232,8 @@ func (im *IndexManager) Search(query string, opts QueryOptions) ([]*parser.Agent
 			continue
 		}
 
-		// Search in fields
-		if query == "" || // Empty query matches all
-			strings.Contains(strings.ToLower(agent.Name), query) ||
-			strings.Contains(strings.ToLower(agent.Description), query) ||
-			strings.Contains(strings.ToLower(agent.Prompt), query) {
+		// Search in fields; an empty query matches all
+		if expr.Match(agent) {
 			results = append(results, agent)
 
 			if opts.Limit > 0 && len(results) >= opts.Limit {
@@ -202,12 +347,63 @@ func (im *IndexManager) SearchBySource(source string) ([]*parser.AgentSpec, erro
 	return results, nil
 }
 
-// GetByFilename retrieves agent by filename
+// SearchByVersion finds agents declaring the given frontmatter version
+func (im *IndexManager) SearchByVersion(version string) ([]*parser.AgentSpec, error) {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+
+	var results []*parser.AgentSpec
+
+	for _, agent := range im.agents {
+		if agent.Version == version {
+			results = append(results, agent)
+		}
+	}
+
+	return results, nil
+}
+
+// GetByFilename retrieves agent by filename; when several agents share the
+// filename the first by path is returned, see GetAllByFilename
 func (im *IndexManager) GetByFilename(filename string) *parser.AgentSpec {
+	if matches := im.GetAllByFilename(filename); len(matches) > 0 {
+		return matches[0]
+	}
+	return nil
+}
+
+// GetAllByFilename returns every agent with the given filename, or
+// namespace-qualified filename such as team/helper.md, ordered by path
+func (im *IndexManager) GetAllByFilename(filename string) []*parser.AgentSpec {
 	im.mu.RLock()
 	defer im.mu.RUnlock()
 
-	return im.byFile[filename]
+	matches := append([]*parser.AgentSpec(nil), im.byFile[filename]...)
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].FilePath < matches[j].FilePath })
+	return matches
+}
+
+// GetByPathSuffix returns every agent whose file path ends with suffix at a
+// directory boundary, so project/team/helper.md is matched by team/helper.md,
+// ordered by path
+func (im *IndexManager) GetByPathSuffix(suffix string) []*parser.AgentSpec {
+	suffix = strings.TrimPrefix(filepath.ToSlash(suffix), "/")
+	if suffix == "" {
+		return nil
+	}
+
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+
+	var matches []*parser.AgentSpec
+	for _, agent := range im.agents {
+		path := filepath.ToSlash(agent.FilePath)
+		if path == suffix || strings.HasSuffix(path, "/"+suffix) {
+			matches = append(matches, agent)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].FilePath < matches[j].FilePath })
+	return matches
 }
 
 // GetAll returns all agents
@@ -223,7 +419,7 @@ func (im *IndexManager) GetAll() []*parser.AgentSpec {
 // Rebuild rebuilds the index from a directory
 func (im *IndexManager) Rebuild(dir string) error {
 	p := parser.NewParser()
-	agents, err := p.ParseDirectory(dir)
+	agents, failures, err := p.ParseDirectoryReport(dir)
 	if err != nil {
 		return err
 	}
@@ -232,12 +428,12 @@ func (im *IndexManager) Rebuild(dir string) error {
 	defer im.mu.Unlock()
 
 	im.agents = agents
+	im.broken = failures
 	im.byName = make(map[string]*parser.AgentSpec)
-	im.byFile = make(map[string]*parser.AgentSpec)
+	im.byFile = make(map[string][]*parser.AgentSpec)
 
 	for _, agent := range agents {
-		im.byName[agent.Name] = agent
-		im.byFile[agent.FileName] = agent
+		im.addLookups(agent)
 	}
 
 	return nil
@@ -250,21 +446,39 @@ func (im *IndexManager) RebuildWithAgents(agents []*parser.AgentSpec) error {
 
 	im.agents = agents
 	im.byName = make(map[string]*parser.AgentSpec)
-	im.byFile = make(map[string]*parser.AgentSpec)
+	im.byFile = make(map[string][]*parser.AgentSpec)
 
 	for _, agent := range agents {
-		im.byName[agent.Name] = agent
-		im.byFile[agent.FileName] = agent
+		im.addLookups(agent)
 	}
 
 	return nil
 }
 
-// Save saves the index to disk
-func (im *IndexManager) Save() error {
+// SetBroken records the agent files that failed to parse, replacing any
+// previously recorded failures
+func (im *IndexManager) SetBroken(failures []parser.ParseFailure) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	im.broken = failures
+}
+
+// Broken returns the agent files that failed to parse when the index was built
+func (im *IndexManager) Broken() []parser.ParseFailure {
 	im.mu.RLock()
 	defer im.mu.RUnlock()
 
+	result := make([]parser.ParseFailure, len(im.broken))
+	copy(result, im.broken)
+	return result
+}
+
+// Save saves the index to disk
+func (im *IndexManager) Save() error {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
 	return im.save()
 }
 
@@ -279,24 +493,64 @@ func (im *IndexManager) load() error {
 		return err // File doesn't exist or can't be read
 	}
 
-	var agents []*parser.AgentSpec
-	if err := json.Unmarshal(data, &agents); err != nil {
+	version, err := formatVersion(data)
+	if err != nil {
 		return err
 	}
+	loader, ok := loaders[version]
+	if !ok {
+		return fmt.Errorf("%w: version %d (supported up to %d)", ErrUnsupportedFormat, version, FormatVersion)
+	}
+	file, err := loader(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode index format version %d: %w", version, err)
+	}
+	agents := file.Agents
+	im.generation = file.Generation
+	im.layout = Layout{Compact: file.Compact, StripPrompts: file.PromptsStripped}
+	if file.PromptsStripped {
+		hydratePrompts(agents)
+	}
 
 	// Rebuild internal maps
 	im.agents = agents
+	im.broken = file.Broken
 	im.byName = make(map[string]*parser.AgentSpec)
-	im.byFile = make(map[string]*parser.AgentSpec)
+	im.byFile = make(map[string][]*parser.AgentSpec)
 
 	for _, agent := range agents {
-		im.byName[agent.Name] = agent
-		im.byFile[agent.FileName] = agent
+		im.addLookups(agent)
+	}
+
+	// Migrate older formats by rewriting the index in the current one
+	if version != FormatVersion {
+		if err := im.save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to migrate index %s to format version %d: %v\n", im.path, FormatVersion, err)
+		}
 	}
 
 	return nil
 }
 
+// formatVersion returns the format version of persisted index data
+func formatVersion(data []byte) (int, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return 0, nil
+	}
+
+	var header struct {
+		Version *int `json:"version"`
+	}
+	if err := json.Unmarshal(trimmed, &header); err != nil {
+		return 0, fmt.Errorf("failed to parse index: %w", err)
+	}
+	if header.Version == nil {
+		return 0, fmt.Errorf("%w: missing format version", ErrUnsupportedFormat)
+	}
+	return *header.Version, nil
+}
+
 // Stats returns index statistics
 func (im *IndexManager) Stats() map[string]interface{} {
 	im.mu.RLock()
@@ -306,19 +560,78 @@ func (im *IndexManager) Stats() map[string]interface{} {
 		"total_agents":  len(im.agents),
 		"indexed_names": len(im.byName),
 		"indexed_files": len(im.byFile),
+		"broken_files":  len(im.broken),
 	}
 }
 
-// save saves the index to disk (private helper)
+// save saves the index to disk (private helper). Saves from several
+// processes are serialized by a lock file next to the index, each one takes
+// the next generation, and the index is replaced by a rename so readers never
+// see a partly written file.
 func (im *IndexManager) save() error {
 	if im.path == "" {
 		return nil // No path specified
 	}
 
-	data, err := json.MarshalIndent(im.agents, "", "  ")
+	lock, err := util.LockFile(im.path + ".lock")
 	if err != nil {
 		return err
 	}
+	defer func() { _ = lock.Unlock() }()
 
-	return os.WriteFile(im.path, data, 0644)
+	saved, err := ReadGeneration(im.path)
+	if err != nil {
+		saved = 0 // an unreadable index is replaced
+	}
+	im.generation = max(im.generation, saved) + 1
+
+	data, err := im.encode()
+	if err != nil {
+		return err
+	}
+
+	return util.WriteFileAtomic(im.path, data, 0644)
+}
+
+// ReadGeneration returns the generation of the index saved at path without
+// decoding its agents; a missing index, or one saved before generations were
+// recorded, has generation 0
+func ReadGeneration(path string) (uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer func() { _ = file.Close() }()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return 0, nil // the unversioned array layout, or not an index
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read index generation: %w", err)
+		}
+		switch key {
+		case "version":
+			var version int
+			if err := decoder.Decode(&version); err != nil {
+				return 0, fmt.Errorf("failed to read index generation: %w", err)
+			}
+		case "generation":
+			var generation uint64
+			if err := decoder.Decode(&generation); err != nil {
+				return 0, fmt.Errorf("failed to read index generation: %w", err)
+			}
+			return generation, nil
+		default:
+			// The generation follows the version, so any other field means
+			// the index has none
+			return 0, nil
+		}
+	}
+	return 0, nil
 }
@@ -1,6 +1,7 @@
 package index
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -389,6 +390,71 @@ func TestGetByFilename(t *testing.T) {
 	}
 }
 
+// TestGetByFilename_Namespaced tests lookups of agents sharing a file name in different namespaces
+func TestGetByFilename_Namespaced(t *testing.T) {
+	im, err := NewIndexManager(filepath.Join(t.TempDir(), "test-index.json"))
+	if err != nil {
+		t.Fatalf("NewIndexManager failed: %v", err)
+	}
+
+	backend := createTestAgent("reviewer", "Backend reviewer", nil, "prompt")
+	backend.Namespace = "backend"
+	frontend := createTestAgent("reviewer", "Frontend reviewer", nil, "prompt")
+	frontend.Namespace = "frontend"
+	if err := im.RebuildWithAgents([]*parser.AgentSpec{backend, frontend}); err != nil {
+		t.Fatalf("RebuildWithAgents failed: %v", err)
+	}
+
+	if got := im.GetByFilename("backend/reviewer.md"); got != backend {
+		t.Errorf("Expected backend agent, got %v", got)
+	}
+	if got := im.GetByFilename("frontend/reviewer.md"); got != frontend {
+		t.Errorf("Expected frontend agent, got %v", got)
+	}
+	if len(im.GetAll()) != 2 {
+		t.Errorf("Expected both agents to be indexed, got %d", len(im.GetAll()))
+	}
+}
+
+// TestGetAllByFilename tests lookups of agent files sharing a name in nested directories
+func TestGetAllByFilename(t *testing.T) {
+	im, err := NewIndexManager(filepath.Join(t.TempDir(), "test-index.json"))
+	if err != nil {
+		t.Fatalf("NewIndexManager failed: %v", err)
+	}
+
+	team := createTestAgent("helper", "Team helper", nil, "prompt")
+	team.Namespace = "team"
+	team.FilePath = "/agents/team/helper.md"
+	ops := createTestAgent("helper", "Ops helper", nil, "prompt")
+	ops.Namespace = "ops/deploy"
+	ops.FilePath = "/agents/ops/deploy/helper.md"
+	if err := im.RebuildWithAgents([]*parser.AgentSpec{team, ops}); err != nil {
+		t.Fatalf("RebuildWithAgents failed: %v", err)
+	}
+
+	matches := im.GetAllByFilename("helper.md")
+	if len(matches) != 2 || matches[0] != ops || matches[1] != team {
+		t.Fatalf("Expected both helpers ordered by path, got %v", matches)
+	}
+	if got := im.GetByFilename("helper.md"); got != ops {
+		t.Errorf("Expected the first helper by path, got %v", got)
+	}
+	if got := im.GetAllByFilename("team/helper.md"); len(got) != 1 || got[0] != team {
+		t.Errorf("Expected the namespace to select the team helper, got %v", got)
+	}
+
+	if got := im.GetByPathSuffix("deploy/helper.md"); len(got) != 1 || got[0] != ops {
+		t.Errorf("Expected a path suffix to select the ops helper, got %v", got)
+	}
+	if got := im.GetByPathSuffix("/helper.md"); len(got) != 2 {
+		t.Errorf("Expected the file name alone to match both helpers, got %v", got)
+	}
+	if got := im.GetByPathSuffix("loy/helper.md"); len(got) != 0 {
+		t.Errorf("Expected suffixes to match whole directory names, got %v", got)
+	}
+}
+
 // TestGetAll tests retrieving all agents
 func TestGetAll(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -661,3 +727,150 @@ func TestIndexConsistency(t *testing.T) {
 		}
 	}
 }
+
+// TestFormatVersionMigration tests loading legacy and unsupported index formats
+func TestFormatVersionMigration(t *testing.T) {
+	tmpDir := t.TempDir()
+	indexPath := filepath.Join(tmpDir, "index.json")
+
+	// Unversioned bare array written by earlier releases
+	legacy := `[{"name": "legacy-agent", "description": "Old format", "file_name": "legacy-agent.md"}]`
+	if err := os.WriteFile(indexPath, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	im, err := NewIndexManager(indexPath)
+	if err != nil {
+		t.Fatalf("NewIndexManager failed: %v", err)
+	}
+	if im.Stale() || im.GetByFilename("legacy-agent.md") == nil {
+		t.Fatal("Expected legacy index to load")
+	}
+
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, err := formatVersion(data); err != nil || version != FormatVersion {
+		t.Errorf("Expected index to be migrated to version %d, got %d (%v)", FormatVersion, version, err)
+	}
+
+	// Reloading the migrated index keeps its agents
+	im, _ = NewIndexManager(indexPath)
+	if im.GetByFilename("legacy-agent.md") == nil {
+		t.Error("Expected migrated index to load")
+	}
+
+	// A newer format is reported stale and not loaded
+	if err := os.WriteFile(indexPath, []byte(`{"version": 99, "agents": [{"name": "future"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	im, _ = NewIndexManager(indexPath)
+	if !im.Stale() || len(im.GetAll()) != 0 {
+		t.Error("Expected unsupported index format to be stale and empty")
+	}
+	if err := im.load(); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+	}
+
+	// A missing index is simply empty
+	im, _ = NewIndexManager(filepath.Join(tmpDir, "missing.json"))
+	if im.Stale() {
+		t.Error("Expected missing index not to be stale")
+	}
+}
+
+// TestBrokenEntriesPersisted tests that parse failures survive a save and load
+func TestBrokenEntriesPersisted(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "index.json")
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	im := NewIndexManagerFromAgents(indexPath, []*parser.AgentSpec{createTestAgent("good", "Parses", nil, "Prompt")})
+	im.SetBroken([]parser.ParseFailure{{Path: "/agents/bad.md", Reason: "invalid agent format: missing frontmatter", ModTime: modTime}})
+	if err := im.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := NewIndexManager(indexPath)
+	if err != nil {
+		t.Fatalf("NewIndexManager failed: %v", err)
+	}
+	broken := loaded.Broken()
+	if len(broken) != 1 || broken[0].Path != "/agents/bad.md" || !broken[0].ModTime.Equal(modTime) {
+		t.Errorf("Expected the broken entry to be loaded, got %+v", broken)
+	}
+	if len(loaded.GetAll()) != 1 {
+		t.Errorf("Expected 1 agent, got %d", len(loaded.GetAll()))
+	}
+	if stats := loaded.Stats(); stats["broken_files"] != 1 {
+		t.Errorf("Expected broken_files 1, got %v", stats["broken_files"])
+	}
+}
+
+func TestConcurrentSaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+
+	// Managers with separate state stand in for separate processes
+	const writers, saves = 4, 10
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			agents := make([]*parser.AgentSpec, 0, 50)
+			for n := 0; n < 50; n++ {
+				agents = append(agents, createTestAgent(fmt.Sprintf("writer%d-agent%d", w, n), "description", nil, "prompt"))
+			}
+			im := NewIndexManagerFromAgents(path, agents)
+			for n := 0; n < saves; n++ {
+				if err := im.Save(); err != nil {
+					t.Errorf("Save failed: %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	loaded, err := NewIndexManager(path)
+	if err != nil || loaded.Stale() {
+		t.Fatalf("Expected a readable index after concurrent saves, got stale=%v (%v)", loaded.Stale(), err)
+	}
+	if got := len(loaded.GetAll()); got != 50 {
+		t.Errorf("Expected one writer's 50 agents, got %d", got)
+	}
+	if got := loaded.Generation(); got != writers*saves {
+		t.Errorf("Expected generation %d after every save, got %d", writers*saves, got)
+	}
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".index.json.*"))
+	if len(matches) != 0 {
+		t.Errorf("Expected no temporary files left, got %v", matches)
+	}
+}
+
+func TestReadGeneration(t *testing.T) {
+	dir := t.TempDir()
+
+	if generation, err := ReadGeneration(filepath.Join(dir, "missing.json")); err != nil || generation != 0 {
+		t.Errorf("Expected generation 0 for a missing index, got %d (%v)", generation, err)
+	}
+
+	legacy := filepath.Join(dir, "legacy.json")
+	if err := os.WriteFile(legacy, []byte(`[{"name":"old"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if generation, err := ReadGeneration(legacy); err != nil || generation != 0 {
+		t.Errorf("Expected generation 0 for an unversioned index, got %d (%v)", generation, err)
+	}
+
+	path := filepath.Join(dir, "index.json")
+	im := NewIndexManagerFromAgents(path, []*parser.AgentSpec{createTestAgent("a", "d", nil, "p")})
+	for n := 0; n < 3; n++ {
+		if err := im.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if generation, err := ReadGeneration(path); err != nil || generation != 3 || im.Generation() != 3 {
+		t.Errorf("Expected generation 3 on disk and in memory, got %d and %d (%v)", generation, im.Generation(), err)
+	}
+}
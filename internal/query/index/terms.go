@@ -0,0 +1,230 @@
+package index
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+)
+
+// Query fields a term can be restricted to with field:value
+var queryFields = map[string]bool{
+	"name":        true,
+	"description": true,
+	"content":     true,
+	"prompt":      true,
+	"tools":       true,
+	"source":      true,
+	"version":     true,
+}
+
+// Term is one part of a search query. A term without a field matches when the
+// name, description or content contains it; a field term matches that field
+// only, by substring or, with field:=value, by case-insensitive equality.
+type Term struct {
+	Field  string // empty, or one of the query fields
+	Value  string // lowercased text to match
+	Exact  bool   // the field must equal Value rather than contain it
+	Phrase bool   // Value was quoted and may hold several words
+}
+
+// ParseQuery returns the terms an agent matching query contains: the terms
+// of ParseExpr, leaving out those under NOT. Words are separate terms, a
+// quoted "multi word phrase" is one term, and name:value, name:=value and
+// name:"quoted phrase" restrict a term to a field. A word whose prefix is not
+// a query field, such as a URL, is an ordinary word. An unterminated quote
+// runs to the end of the query. A query with a syntax error yields all its
+// terms.
+func ParseQuery(query string) []Term {
+	expr, err := ParseExpr(query)
+	if err != nil {
+		var terms []Term
+		for _, token := range lexQuery(query) {
+			if token.kind == tokenTerm {
+				terms = append(terms, token.term)
+			}
+		}
+		return terms
+	}
+	return expr.Terms()
+}
+
+// lexQuery splits a query into terms, the operators AND, OR and NOT, and
+// parentheses. Operators are only recognised in upper case and unquoted, so
+// "and" and "or" stay ordinary words.
+func lexQuery(query string) []token {
+	var tokens []token
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+		switch runes[i] {
+		case '(':
+			tokens = append(tokens, token{kind: tokenOpen})
+			i++
+			continue
+		case ')':
+			tokens = append(tokens, token{kind: tokenClose})
+			i++
+			continue
+		}
+
+		var term Term
+		if field, rest, ok := fieldPrefix(runes[i:]); ok {
+			term.Field = field
+			i += len(runes[i:]) - len(rest)
+			if len(rest) > 0 && rest[0] == '=' {
+				term.Exact = true
+				i++
+			}
+		}
+
+		var value string
+		if i < len(runes) && runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			value = string(runes[i+1 : end])
+			term.Phrase = true
+			i = end + 1
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != ')' {
+				end++
+			}
+			value = string(runes[i:end])
+			i = end
+		}
+
+		if term.Field == "" && !term.Phrase {
+			if kind, ok := operators[value]; ok {
+				tokens = append(tokens, token{kind: kind})
+				continue
+			}
+		}
+
+		term.Value = strings.ToLower(strings.TrimSpace(value))
+		if term.Value == "" {
+			continue
+		}
+		if term.Field == "prompt" {
+			term.Field = "content"
+		}
+		tokens = append(tokens, token{kind: tokenTerm, term: term})
+	}
+	return tokens
+}
+
+// FieldTerm returns the term matching one field by substring or, when exact,
+// by case-insensitive equality
+func FieldTerm(field, value string, exact bool) (Term, error) {
+	field = strings.ToLower(strings.TrimSpace(field))
+	if !queryFields[field] {
+		return Term{}, fmt.Errorf("invalid field: %s", field)
+	}
+	if field == "prompt" {
+		field = "content"
+	}
+	return Term{Field: field, Value: strings.ToLower(strings.TrimSpace(value)), Exact: exact}, nil
+}
+
+// fieldPrefix returns the field of a token starting with field: and the rest
+// of the token after the colon
+func fieldPrefix(token []rune) (string, []rune, bool) {
+	for i, r := range token {
+		if r == ':' {
+			field := strings.ToLower(string(token[:i]))
+			if !queryFields[field] {
+				return "", nil, false
+			}
+			return field, token[i+1:], true
+		}
+		if !unicode.IsLetter(r) {
+			return "", nil, false
+		}
+	}
+	return "", nil, false
+}
+
+// IsStructured reports whether a query uses quoted phrases, field terms,
+// operators or groups, which call for literal matching rather than fuzzy
+// matching of its words
+func IsStructured(query string) bool {
+	for _, token := range lexQuery(query) {
+		if token.kind != tokenTerm || token.term.Phrase || token.term.Field != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchTerms reports whether agent matches every term
+func MatchTerms(agent *parser.AgentSpec, terms []Term) bool {
+	for _, term := range terms {
+		if !term.Match(agent) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match reports whether agent matches the term
+func (t Term) Match(agent *parser.AgentSpec) bool {
+	switch t.Field {
+	case "":
+		return t.matchText(agent.Name) || t.matchText(agent.Description) || t.matchText(agent.Prompt)
+	case "name":
+		return t.matchText(agent.Name) || (agent.Namespace != "" && t.matchText(agent.QualifiedName()))
+	case "description":
+		return t.matchText(agent.Description)
+	case "content":
+		return t.matchText(agent.Prompt)
+	case "source":
+		return t.matchText(agent.Source)
+	case "version":
+		return t.matchText(agent.Version)
+	case "tools":
+		return t.matchTools(agent)
+	default:
+		return false
+	}
+}
+
+// matchText matches one field value by substring or, for exact terms, equality
+func (t Term) matchText(value string) bool {
+	value = strings.ToLower(value)
+	if t.Exact {
+		return value == t.Value
+	}
+	return strings.Contains(value, t.Value)
+}
+
+// matchTools matches agents granting every tool of a comma-separated list;
+// agents inheriting all tools never match
+func (t Term) matchTools(agent *parser.AgentSpec) bool {
+	if agent.ToolsInherited {
+		return false
+	}
+	tools := agent.GetToolsAsSlice()
+	for _, want := range strings.Split(t.Value, ",") {
+		want = strings.TrimSpace(want)
+		if want == "" {
+			continue
+		}
+		found := false
+		for _, tool := range tools {
+			if strings.EqualFold(tool, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
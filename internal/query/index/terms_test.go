@@ -0,0 +1,84 @@
+package index
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// TestParseQuery tests splitting queries into words, phrases and field terms
+func TestParseQuery(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected []Term
+	}{
+		{"", nil},
+		{"Go  expert", []Term{{Value: "go"}, {Value: "expert"}}},
+		{`"Pull Request review" go`, []Term{{Value: "pull request review", Phrase: true}, {Value: "go"}}},
+		{"name:=go-expert", []Term{{Field: "name", Value: "go-expert", Exact: true}}},
+		{`description:"code review" prompt:tests`, []Term{
+			{Field: "description", Value: "code review", Phrase: true},
+			{Field: "content", Value: "tests"},
+		}},
+		{"https://example.com", []Term{{Value: "https://example.com"}}},
+		{`"unterminated phrase`, []Term{{Value: "unterminated phrase", Phrase: true}}},
+		{`name: ""`, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			if terms := ParseQuery(tc.query); !reflect.DeepEqual(terms, tc.expected) {
+				t.Errorf("ParseQuery(%q) = %+v, want %+v", tc.query, terms, tc.expected)
+			}
+		})
+	}
+
+	if IsStructured("pull request review") || !IsStructured(`"pull request"`) || !IsStructured("source:team") {
+		t.Error("Expected only phrases and field terms to make a query structured")
+	}
+}
+
+// TestSearch_PhrasesAndExactFields tests phrase and exact field matching in searches
+func TestSearch_PhrasesAndExactFields(t *testing.T) {
+	im, err := NewIndexManager(filepath.Join(t.TempDir(), "test-index.json"))
+	if err != nil {
+		t.Fatalf("NewIndexManager failed: %v", err)
+	}
+	im.AddAgent(createTestAgent("reviewer", "Pull request review assistant", []string{"Read", "Bash"}, "Review pull requests"))
+	im.AddAgent(createTestAgent("requester", "Files a pull of every review request", nil, "Requests"))
+	im.AddAgent(createTestAgent("go-expert", "Golang expert", []string{"Read"}, "Go"))
+	im.AddAgent(createTestAgent("go-expert-v2", "Golang expert, revised", []string{"Read"}, "Go"))
+
+	testCases := []struct {
+		query    string
+		expected []string
+	}{
+		{"pull request review", []string{"requester", "reviewer"}},
+		{`"pull request review"`, []string{"reviewer"}},
+		{"name:go-expert", []string{"go-expert", "go-expert-v2"}},
+		{"name:=go-expert", []string{"go-expert"}},
+		{"name:=GO-EXPERT", []string{"go-expert"}},
+		{`description:"review request"`, []string{"requester"}},
+		{`description:"review pull"`, []string{}},
+		{"tools:bash,read review", []string{"reviewer"}},
+		{"source:=test-source golang", []string{"go-expert", "go-expert-v2"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			results, err := im.Search(tc.query, QueryOptions{})
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			names := []string{}
+			for _, result := range results {
+				names = append(names, result.Name)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tc.expected) {
+				t.Errorf("Search(%q) = %v, want %v", tc.query, names, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Encodings reported for agent files that were not plain UTF-8
+const (
+	EncodingUTF8BOM     = "utf-8 with BOM"
+	EncodingUTF16LE     = "utf-16le"
+	EncodingUTF16BE     = "utf-16be"
+	EncodingWindows1252 = "windows-1252"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// DecodeContent returns agent file content as UTF-8 without a byte order
+// mark, along with the encoding it was converted from, or "" when it already
+// was plain UTF-8. Files with a UTF-16 byte order mark are transcoded, and
+// other invalid UTF-8 is read as Windows-1252, the superset of latin-1 that
+// Windows editors save.
+func DecodeContent(content []byte) ([]byte, string) {
+	switch {
+	case bytes.HasPrefix(content, bomUTF8):
+		return content[len(bomUTF8):], EncodingUTF8BOM
+	case bytes.HasPrefix(content, bomUTF16LE):
+		return transcode(content, unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), EncodingUTF16LE)
+	case bytes.HasPrefix(content, bomUTF16BE):
+		return transcode(content, unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), EncodingUTF16BE)
+	case !utf8.Valid(content):
+		return transcode(content, charmap.Windows1252, EncodingWindows1252)
+	default:
+		return content, ""
+	}
+}
+
+// transcode decodes content to UTF-8, returning it unchanged when it cannot be decoded
+func transcode(content []byte, enc encoding.Encoding, name string) ([]byte, string) {
+	decoded, err := enc.NewDecoder().Bytes(content)
+	if err != nil {
+		return content, ""
+	}
+	return decoded, name
+}
@@ -0,0 +1,350 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetFrontmatterField sets a top-level frontmatter field in raw agent content,
+// replacing any existing value (including multi-line values) or appending the
+// field when it is missing. The prompt body is left untouched.
+func SetFrontmatterField(content, key string, value interface{}) (string, error) {
+	parts := strings.SplitN(content, "---", 3)
+	if len(parts) < 3 {
+		return "", fmt.Errorf("invalid agent format: missing frontmatter")
+	}
+
+	encoded, err := yaml.Marshal(map[string]interface{}{key: value})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	replacement := strings.TrimRight(string(encoded), "\n")
+
+	lines := strings.Split(parts[1], "\n")
+	result := make([]string, 0, len(lines)+1)
+	replaced := false
+
+	for idx := 0; idx < len(lines); idx++ {
+		line := lines[idx]
+		if !replaced && isFrontmatterKey(line, key) {
+			result = append(result, replacement)
+			replaced = true
+
+			// Skip continuation lines belonging to the old value
+			for idx+1 < len(lines) && isContinuationLine(lines[idx+1]) {
+				idx++
+			}
+			continue
+		}
+		result = append(result, line)
+	}
+
+	if !replaced {
+		// Insert before the trailing newline that precedes the closing delimiter
+		insertAt := len(result)
+		if insertAt > 0 && strings.TrimSpace(result[insertAt-1]) == "" {
+			insertAt--
+		}
+		result = append(result[:insertAt], append([]string{replacement}, result[insertAt:]...)...)
+	}
+
+	return parts[0] + "---" + strings.Join(result, "\n") + "---" + parts[2], nil
+}
+
+// isFrontmatterKey reports whether line declares the given top-level key
+func isFrontmatterKey(line, key string) bool {
+	return strings.HasPrefix(line, key+":")
+}
+
+// isContinuationLine reports whether line continues the previous key's value
+func isContinuationLine(line string) bool {
+	if line == "" {
+		return false
+	}
+	return line[0] == ' ' || line[0] == '\t' || strings.HasPrefix(line, "- ")
+}
+
+// FrontmatterFields decodes the frontmatter of raw agent content into a generic map
+func FrontmatterFields(content string) (map[string]interface{}, error) {
+	parts := strings.SplitN(content, "---", 3)
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("invalid agent format: missing frontmatter")
+	}
+
+	fields := make(map[string]interface{})
+	if err := yaml.Unmarshal([]byte(parts[1]), &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
+	}
+	return fields, nil
+}
+
+// canonicalKeyOrder is the order of the frontmatter fields Claude Code reads,
+// followed by the fields agent-manager reads; other fields follow sorted by name
+var canonicalKeyOrder = []string{"name", "description", "tools", "model", "color", "version", "license"}
+
+// Canonical returns agent content in its canonical installable form: UTF-8,
+// frontmatter re-encoded with two-space indentation and its top-level keys in
+// canonical order, and the prompt body byte for byte as written. Canonical
+// content is returned unchanged, so an exported agent reinstalls byte-stably.
+func Canonical(content []byte) ([]byte, error) {
+	content, _ = DecodeContent(content)
+	parts := strings.SplitN(string(content), "---", 3)
+	if len(parts) < 3 || strings.TrimSpace(parts[0]) != "" {
+		return nil, fmt.Errorf("invalid agent format: missing frontmatter")
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(parts[1]), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
+	}
+	if len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid agent format: frontmatter is not a mapping")
+	}
+	sortFrontmatterKeys(doc.Content[0])
+
+	var encoded strings.Builder
+	encoder := yaml.NewEncoder(&encoded)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+
+	return []byte("---\n" + encoded.String() + "---" + parts[2]), nil
+}
+
+// sortFrontmatterKeys orders the key/value pairs of a frontmatter mapping:
+// known fields first in canonical order, then the others by name
+func sortFrontmatterKeys(mapping *yaml.Node) {
+	rank := func(key string) int {
+		for n, known := range canonicalKeyOrder {
+			if key == known {
+				return n
+			}
+		}
+		return len(canonicalKeyOrder)
+	}
+
+	pairs := make([][2]*yaml.Node, 0, len(mapping.Content)/2)
+	for n := 0; n+1 < len(mapping.Content); n += 2 {
+		pairs = append(pairs, [2]*yaml.Node{mapping.Content[n], mapping.Content[n+1]})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		ri, rj := rank(pairs[i][0].Value), rank(pairs[j][0].Value)
+		if ri != rj {
+			return ri < rj
+		}
+		return ri == len(canonicalKeyOrder) && pairs[i][0].Value < pairs[j][0].Value
+	})
+
+	mapping.Content = mapping.Content[:0]
+	for _, pair := range pairs {
+		mapping.Content = append(mapping.Content, pair[0], pair[1])
+	}
+}
+
+// Frontmatter edit operators
+const (
+	EditSet     = "="
+	EditAppend  = "+="
+	EditRemove  = "-="
+	EditReplace = "~="
+)
+
+// FrontmatterEdit describes a single change to a frontmatter field
+type FrontmatterEdit struct {
+	Key   string
+	Op    string
+	Value string
+
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// listFields are frontmatter fields that hold lists and accept += and -=
+var listFields = map[string]bool{
+	"tools": true,
+}
+
+// ParseFrontmatterEdit parses an edit expression such as "tools+=Grep",
+// "model=sonnet" or "description~=s/foo/bar/"
+func ParseFrontmatterEdit(expr string) (FrontmatterEdit, error) {
+	idx := strings.Index(expr, "=")
+	if idx <= 0 {
+		return FrontmatterEdit{}, fmt.Errorf("invalid edit %q: expected key=value, key+=value, key-=value or key~=s/old/new/", expr)
+	}
+
+	edit := FrontmatterEdit{Key: expr[:idx], Op: EditSet, Value: expr[idx+1:]}
+	switch edit.Key[len(edit.Key)-1] {
+	case '+':
+		edit.Op = EditAppend
+	case '-':
+		edit.Op = EditRemove
+	case '~':
+		edit.Op = EditReplace
+	}
+	if edit.Op != EditSet {
+		edit.Key = edit.Key[:len(edit.Key)-1]
+	}
+	edit.Key = strings.TrimSpace(edit.Key)
+
+	if !frontmatterKeyPattern.MatchString(edit.Key) {
+		return FrontmatterEdit{}, fmt.Errorf("invalid field name %q", edit.Key)
+	}
+	if edit.Key == "name" {
+		return FrontmatterEdit{}, fmt.Errorf("name cannot be bulk edited; use the rename command")
+	}
+	if (edit.Op == EditAppend || edit.Op == EditRemove) && !listFields[edit.Key] {
+		return FrontmatterEdit{}, fmt.Errorf("%s is only supported for list fields (tools), not %s", edit.Op, edit.Key)
+	}
+
+	if edit.Op == EditReplace {
+		pattern, replacement, err := parseSubstitution(edit.Value)
+		if err != nil {
+			return FrontmatterEdit{}, fmt.Errorf("invalid substitution for %s: %w", edit.Key, err)
+		}
+		edit.pattern = pattern
+		edit.replacement = replacement
+	}
+
+	return edit, nil
+}
+
+// frontmatterKeyPattern matches valid top-level frontmatter keys
+var frontmatterKeyPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// parseSubstitution parses a sed-style s/pattern/replacement/ expression
+func parseSubstitution(expr string) (*regexp.Regexp, string, error) {
+	if len(expr) < 4 || expr[0] != 's' {
+		return nil, "", fmt.Errorf("expected s/pattern/replacement/")
+	}
+
+	delim := expr[1:2]
+	parts := strings.Split(expr[2:], delim)
+	if len(parts) != 3 || parts[2] != "" {
+		return nil, "", fmt.Errorf("expected s%spattern%sreplacement%s", delim, delim, delim)
+	}
+
+	pattern, err := regexp.Compile(parts[0])
+	if err != nil {
+		return nil, "", err
+	}
+	return pattern, parts[1], nil
+}
+
+// ApplyFrontmatterEdits applies edits to raw agent content and returns the new content
+func ApplyFrontmatterEdits(content string, edits []FrontmatterEdit) (string, error) {
+	for _, edit := range edits {
+		fields, err := FrontmatterFields(content)
+		if err != nil {
+			return "", err
+		}
+
+		value, err := edit.apply(fields[edit.Key])
+		if err != nil {
+			return "", err
+		}
+
+		content, err = SetFrontmatterField(content, edit.Key, value)
+		if err != nil {
+			return "", err
+		}
+	}
+	return content, nil
+}
+
+// apply computes the new value of a field from its current value
+func (e FrontmatterEdit) apply(current interface{}) (interface{}, error) {
+	value, err := e.applyValue(current)
+	if err != nil {
+		return nil, err
+	}
+
+	// Preserve the comma-separated style when the field was written that way
+	if list, ok := value.([]string); ok {
+		if _, wasString := current.(string); wasString {
+			return strings.Join(list, ", "), nil
+		}
+	}
+	return value, nil
+}
+
+// applyValue computes the new value of a field according to the edit operator
+func (e FrontmatterEdit) applyValue(current interface{}) (interface{}, error) {
+	switch e.Op {
+	case EditAppend:
+		list := toStringList(current)
+		for _, item := range splitList(e.Value) {
+			if !containsString(list, item) {
+				list = append(list, item)
+			}
+		}
+		return list, nil
+
+	case EditRemove:
+		remove := splitList(e.Value)
+		list := make([]string, 0)
+		for _, item := range toStringList(current) {
+			if !containsString(remove, item) {
+				list = append(list, item)
+			}
+		}
+		return list, nil
+
+	case EditReplace:
+		str, ok := current.(string)
+		if !ok && current != nil {
+			return nil, fmt.Errorf("%s is not a text field", e.Key)
+		}
+		return e.pattern.ReplaceAllString(str, e.replacement), nil
+
+	default:
+		if listFields[e.Key] {
+			return splitList(e.Value), nil
+		}
+		return e.Value, nil
+	}
+}
+
+// toStringList converts a list or comma-separated frontmatter value to a string slice
+func toStringList(value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			list = append(list, fmt.Sprint(item))
+		}
+		return list
+	case string:
+		return splitList(v)
+	default:
+		return []string{}
+	}
+}
+
+// splitList splits a comma-separated value into trimmed, non-empty items
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// containsString reports whether list contains item
+func containsString(list []string, item string) bool {
+	for _, existing := range list {
+		if existing == item {
+			return true
+		}
+	}
+	return false
+}
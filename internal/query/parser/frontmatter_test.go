@@ -0,0 +1,182 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetFrontmatterField(t *testing.T) {
+	content := `---
+name: old-agent
+description: Handles things
+tools:
+  - Read
+  - Write
+---
+
+Prompt mentioning name: old-agent`
+
+	t.Run("replace scalar", func(t *testing.T) {
+		result, err := SetFrontmatterField(content, "name", "new-agent")
+		if err != nil {
+			t.Fatalf("SetFrontmatterField failed: %v", err)
+		}
+		if !strings.Contains(result, "\nname: new-agent\n") {
+			t.Errorf("Expected updated name, got:\n%s", result)
+		}
+		if !strings.HasSuffix(result, "Prompt mentioning name: old-agent") {
+			t.Errorf("Expected prompt body to be untouched, got:\n%s", result)
+		}
+	})
+
+	t.Run("replace multi-line value", func(t *testing.T) {
+		result, err := SetFrontmatterField(content, "tools", []string{"Grep"})
+		if err != nil {
+			t.Fatalf("SetFrontmatterField failed: %v", err)
+		}
+		if strings.Contains(result, "- Write") {
+			t.Errorf("Expected old tool list to be removed, got:\n%s", result)
+		}
+		if !strings.Contains(result, "tools:\n    - Grep\n---") {
+			t.Errorf("Expected new tool list, got:\n%s", result)
+		}
+	})
+
+	t.Run("append missing field", func(t *testing.T) {
+		result, err := SetFrontmatterField(content, "version", "1.0.0")
+		if err != nil {
+			t.Fatalf("SetFrontmatterField failed: %v", err)
+		}
+		if !strings.Contains(result, "  - Write\nversion: 1.0.0\n---") {
+			t.Errorf("Expected appended field, got:\n%s", result)
+		}
+	})
+
+	t.Run("quotes values when needed", func(t *testing.T) {
+		result, err := SetFrontmatterField(content, "description", "Reviews: code")
+		if err != nil {
+			t.Fatalf("SetFrontmatterField failed: %v", err)
+		}
+		if !strings.Contains(result, `description: 'Reviews: code'`) {
+			t.Errorf("Expected quoted description, got:\n%s", result)
+		}
+	})
+
+	t.Run("missing frontmatter", func(t *testing.T) {
+		if _, err := SetFrontmatterField("no frontmatter here", "name", "x"); err == nil {
+			t.Error("Expected error for content without frontmatter")
+		}
+	})
+}
+
+func TestParseFrontmatterEdit(t *testing.T) {
+	tests := []struct {
+		expr    string
+		key     string
+		op      string
+		value   string
+		wantErr bool
+	}{
+		{expr: "tools+=Grep", key: "tools", op: EditAppend, value: "Grep"},
+		{expr: "tools-=Bash,Write", key: "tools", op: EditRemove, value: "Bash,Write"},
+		{expr: "model=sonnet", key: "model", op: EditSet, value: "sonnet"},
+		{expr: "description~=s/foo/bar/", key: "description", op: EditReplace, value: "s/foo/bar/"},
+		{expr: "name=other", wantErr: true},
+		{expr: "description+=x", wantErr: true},
+		{expr: "description~=foo", wantErr: true},
+		{expr: "=value", wantErr: true},
+		{expr: "novalue", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			edit, err := ParseFrontmatterEdit(tt.expr)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.expr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFrontmatterEdit(%q) failed: %v", tt.expr, err)
+			}
+			if edit.Key != tt.key || edit.Op != tt.op || edit.Value != tt.value {
+				t.Errorf("Got %+v, want key=%s op=%s value=%s", edit, tt.key, tt.op, tt.value)
+			}
+		})
+	}
+}
+
+func TestApplyFrontmatterEdits(t *testing.T) {
+	content := `---
+name: agent
+description: Handles foo requests
+tools: Read, Bash
+---
+Prompt about foo`
+
+	var edits []FrontmatterEdit
+	for _, expr := range []string{"tools+=Grep,Read", "tools-=Bash", "description~=s/foo/bar/", "model=sonnet"} {
+		edit, err := ParseFrontmatterEdit(expr)
+		if err != nil {
+			t.Fatalf("ParseFrontmatterEdit(%q) failed: %v", expr, err)
+		}
+		edits = append(edits, edit)
+	}
+
+	result, err := ApplyFrontmatterEdits(content, edits)
+	if err != nil {
+		t.Fatalf("ApplyFrontmatterEdits failed: %v", err)
+	}
+
+	want := `---
+name: agent
+description: Handles bar requests
+tools: Read, Grep
+model: sonnet
+---
+Prompt about foo`
+	if result != want {
+		t.Errorf("Unexpected result:\n%s\nwant:\n%s", result, want)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	prompt := "\n\nYou review code.\n\n---\n\nKeep the  spacing,   and trailing space  \n"
+	content := "---\r\nmodel: sonnet\r\nx-team: platform # owner\r\ntools: [Read, Grep]\r\n" +
+		"description: \"Reviews code: correctness, style and \\\"tests\\\" in a very long sentence that keeps going well past eighty columns\"\r\n" +
+		"name: reviewer\r\nalpha: 1\r\n---" + prompt
+
+	canonical, err := Canonical([]byte(content))
+	if err != nil {
+		t.Fatalf("Canonical failed: %v", err)
+	}
+
+	want := "---\n" +
+		"name: reviewer\n" +
+		"description: \"Reviews code: correctness, style and \\\"tests\\\" in a very long sentence that keeps going well past eighty columns\"\n" +
+		"tools: [Read, Grep]\n" +
+		"model: sonnet\n" +
+		"alpha: 1\n" +
+		"x-team: platform # owner\n" +
+		"---" + prompt
+	if string(canonical) != want {
+		t.Errorf("Unexpected canonical form:\n%q\nwant:\n%q", canonical, want)
+	}
+
+	again, err := Canonical(canonical)
+	if err != nil || string(again) != string(canonical) {
+		t.Errorf("Expected canonical content to be stable, got:\n%q, %v", again, err)
+	}
+
+	spec, err := parseContent(canonical)
+	if err != nil || spec.Name != "reviewer" || len(spec.GetToolsAsSlice()) != 2 {
+		t.Errorf("Expected canonical content to parse, got %+v, %v", spec, err)
+	}
+
+	for _, invalid := range []string{"no frontmatter", "intro\n---\nname: x\n---\nprompt", "---\n- a\n---\nprompt"} {
+		if _, err := Canonical([]byte(invalid)); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
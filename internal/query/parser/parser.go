@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 	"gopkg.in/yaml.v3"
 )
 
@@ -54,6 +56,8 @@ type AgentSpec struct {
 	Name        string        `yaml:"name" json:"name"`
 	Description string        `yaml:"description" json:"description"`
 	Tools       FlexibleTools `yaml:"tools,omitempty" json:"tools,omitempty"`
+	License     string        `yaml:"license,omitempty" json:"license,omitempty"`
+	Version     string        `yaml:"version,omitempty" json:"version,omitempty"`
 
 	// Derived fields
 	ToolsInherited bool   `json:"tools_inherited"`
@@ -64,10 +68,61 @@ type AgentSpec struct {
 	FileName string    `json:"file_name"`
 	FileSize int64     `json:"file_size"`
 	ModTime  time.Time `json:"mod_time"`
+	// Namespace is the subdirectory of the agents directory containing the file
+	Namespace string `json:"namespace,omitempty"`
+	// Encoding is the encoding the file was converted from when it was not
+	// plain UTF-8, such as EncodingUTF8BOM
+	Encoding string `json:"encoding,omitempty"`
 
 	// Installation metadata
-	Source      string    `json:"source,omitempty"`
-	InstalledAt time.Time `json:"installed_at,omitempty"`
+	Source       string    `json:"source,omitempty"`
+	SourceCommit string    `json:"source_commit,omitempty"`
+	InstalledAt  time.Time `json:"installed_at,omitempty"`
+	// Archived marks metadata kept for an agent moved to the archive
+	Archived bool `json:"archived,omitempty"`
+
+	// Scope is the kind of agents directory the agent was indexed from
+	Scope string `json:"scope,omitempty"`
+	// Shadowed marks a user agent overridden by a project agent of the same name
+	Shadowed bool `json:"shadowed,omitempty"`
+	// Shadows lists the files of same-named copies hidden by this agent in
+	// deduplicated query results
+	Shadows []string `json:"shadows,omitempty"`
+}
+
+// Agent scopes, matching the agent directories Claude Code merges
+const (
+	ScopeUser    = "user"
+	ScopeProject = "project"
+)
+
+// QualifiedName returns the agent name prefixed with its namespace, if any
+func (a *AgentSpec) QualifiedName() string {
+	if a.Namespace == "" {
+		return a.Name
+	}
+	return a.Namespace + "/" + a.Name
+}
+
+// HasName reports whether name refers to the agent: its name, qualified name,
+// or file name with or without extension, plain or namespace-qualified
+func (a *AgentSpec) HasName(name string) bool {
+	base := strings.TrimSuffix(a.FileName, filepath.Ext(a.FileName))
+	switch name {
+	case a.Name, a.QualifiedName(), a.FileName, base:
+		return true
+	}
+	return a.Namespace != "" && (name == a.Namespace+"/"+a.FileName || name == a.Namespace+"/"+base)
+}
+
+// NamespaceOf returns the namespace for an agent file at relPath relative to
+// the agents directory: its directory in slash form, or "" at the top level
+func NamespaceOf(relPath string) string {
+	dir := filepath.ToSlash(filepath.Dir(relPath))
+	if dir == "." {
+		return ""
+	}
+	return dir
 }
 
 // GetToolsAsSlice returns tools as []string for compatibility with existing code
@@ -75,9 +130,85 @@ func (a *AgentSpec) GetToolsAsSlice() []string {
 	return a.Tools.GetTools()
 }
 
+// Parse modes controlling how malformed agent files are handled
+const (
+	ModeLenient = "lenient" // skip malformed files (default)
+	ModeStrict  = "strict"  // fail the operation when any file is malformed
+	ModeRecover = "recover" // try to repair unterminated frontmatter before skipping
+)
+
+// ParseModes lists the supported parse modes
+var ParseModes = []string{ModeLenient, ModeStrict, ModeRecover}
+
+// DefaultExtensions are the agent file extensions used when none are configured
+var DefaultExtensions = []string{".md"}
+
+// IsAgentFile reports whether path ends with one of the given agent file
+// extensions (case-insensitive), using DefaultExtensions when none are given
+func IsAgentFile(path string, extensions []string) bool {
+	if len(extensions) == 0 {
+		extensions = DefaultExtensions
+	}
+	lower := strings.ToLower(path)
+	for _, ext := range extensions {
+		if ext != "" && strings.HasSuffix(lower, strings.ToLower(ext)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Warning is a non-fatal problem met while parsing agent files, such as a
+// file that was skipped or frontmatter that had to be recovered
+type Warning struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// String formats the warning for display
+func (w Warning) String() string {
+	if w.Path == "" {
+		return w.Message
+	}
+	return w.Path + ": " + w.Message
+}
+
+// WarningHandler receives parse warnings as they are found
+type WarningHandler func(Warning)
+
 // Parser extracts agent specifications
 type Parser struct {
-	SuppressWarnings bool
+	// OnWarning receives non-fatal problems; they are dropped when nil
+	OnWarning  WarningHandler
+	Mode       string
+	Extensions []string // agent file extensions; DefaultExtensions when empty
+	// Known holds previously parsed agents by file path; ParseDirectoryReport
+	// reuses them for files whose size and modification time are unchanged
+	Known map[string]*AgentSpec
+	// Walk bounds the depth of directory walks and whether they follow symlinks
+	Walk util.WalkOptions
+}
+
+// ParseFailure describes a file that could not be parsed as an agent
+type ParseFailure struct {
+	Path    string    `json:"path"`
+	Reason  string    `json:"reason"`
+	ModTime time.Time `json:"mod_time,omitempty"`
+}
+
+// StrictError is returned in strict mode when one or more files fail to parse
+type StrictError struct {
+	Failures []ParseFailure
+}
+
+// Error implements the error interface
+func (e *StrictError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d agent file(s) failed to parse:", len(e.Failures))
+	for _, failure := range e.Failures {
+		fmt.Fprintf(&b, "\n  %s: %s", failure.Path, failure.Reason)
+	}
+	return b.String()
 }
 
 // NewParser creates a new parser
@@ -85,10 +216,17 @@ func NewParser() *Parser {
 	return &Parser{}
 }
 
-// NewParserWithOptions creates a new parser with options
-func NewParserWithOptions(suppressWarnings bool) *Parser {
+// NewParserWithOptions creates a new parser that passes warnings to onWarning
+func NewParserWithOptions(onWarning WarningHandler) *Parser {
 	return &Parser{
-		SuppressWarnings: suppressWarnings,
+		OnWarning: onWarning,
+	}
+}
+
+// warn passes a warning about path to the warning handler, if any
+func (p *Parser) warn(path, format string, args ...interface{}) {
+	if p.OnWarning != nil {
+		p.OnWarning(Warning{Path: path, Message: fmt.Sprintf(format, args...)})
 	}
 }
 
@@ -99,6 +237,51 @@ func (p *Parser) ParseFile(path string) (*AgentSpec, error) {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	spec, err := p.parseContentAt(path, content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add file metadata
+	spec.FilePath = path
+	spec.FileName = filepath.Base(path)
+
+	if info, err := os.Stat(path); err == nil {
+		spec.FileSize = info.Size()
+		spec.ModTime = info.ModTime()
+	}
+
+	return spec, nil
+}
+
+// ParseContent extracts an agent spec from raw file content without file
+// metadata. Content that is not plain UTF-8 is decoded first, and the spec
+// records the original encoding.
+func (p *Parser) ParseContent(content []byte) (*AgentSpec, error) {
+	return p.parseContentAt("", content)
+}
+
+// parseContentAt parses content read from path, which names the file in warnings
+func (p *Parser) parseContentAt(path string, content []byte) (*AgentSpec, error) {
+	content, encoding := DecodeContent(content)
+	spec, err := parseContent(content)
+	if err != nil && p.Mode == ModeRecover {
+		if recovered, ok := RecoverFrontmatter(string(content)); ok {
+			if recoveredSpec, recoverErr := parseContent([]byte(recovered)); recoverErr == nil {
+				p.warn(path, "recovered unterminated frontmatter")
+				spec, err = recoveredSpec, nil
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	spec.Encoding = encoding
+	return spec, nil
+}
+
+// parseContent parses well-formed agent content
+func parseContent(content []byte) (*AgentSpec, error) {
 	// Split frontmatter and content
 	parts := strings.SplitN(string(content), "---", 3)
 	if len(parts) < 3 {
@@ -119,45 +302,123 @@ func (p *Parser) ParseFile(path string) (*AgentSpec, error) {
 		spec.ToolsInherited = true
 	}
 
-	// Add file metadata
-	spec.FilePath = path
-	spec.FileName = filepath.Base(path)
+	return &spec, nil
+}
 
-	if info, err := os.Stat(path); err == nil {
-		spec.FileSize = info.Size()
-		spec.ModTime = info.ModTime()
+// RecoverFrontmatter closes unterminated frontmatter by inserting the closing
+// delimiter after the last line that looks like YAML. It reports false when the
+// content does not open with a delimiter or is already terminated.
+func RecoverFrontmatter(content string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	if len(lines) < 2 || strings.TrimSpace(lines[0]) != "---" {
+		return "", false
+	}
+	if strings.Contains(strings.Join(lines[1:], "\n"), "---") {
+		return "", false
 	}
 
-	return &spec, nil
+	end := 1
+	for end < len(lines) && isYAMLLine(lines[end]) {
+		end++
+	}
+	if end == 1 {
+		return "", false
+	}
+
+	recovered := append(append(append([]string{}, lines[:end]...), "---"), lines[end:]...)
+	return strings.Join(recovered, "\n"), true
 }
 
-// ParseDirectory parses all agents in a directory
+// yamlKeyPattern matches a top-level "key:" frontmatter line
+var yamlKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*:(\s|$)`)
+
+// isYAMLLine reports whether line plausibly belongs to a frontmatter block
+func isYAMLLine(line string) bool {
+	if yamlKeyPattern.MatchString(line) {
+		return true
+	}
+	return line != "" && (line[0] == ' ' || line[0] == '\t' || strings.HasPrefix(line, "- "))
+}
+
+// ParseDirectory parses all agents in a directory. Malformed files are skipped
+// and passed to the warning handler, unless the parser is in strict mode, in
+// which case a *StrictError is returned.
 func (p *Parser) ParseDirectory(dir string) ([]*AgentSpec, error) {
+	agents, failures, err := p.ParseDirectoryReport(dir)
+	if err != nil {
+		return agents, err
+	}
+	if p.Mode == ModeStrict && len(failures) > 0 {
+		return nil, &StrictError{Failures: failures}
+	}
+	p.WarnFailures(failures)
+	return agents, nil
+}
+
+// WarnFailures passes each parse failure to the warning handler as a skipped file
+func (p *Parser) WarnFailures(failures []ParseFailure) {
+	for _, failure := range failures {
+		p.warn(failure.Path, "skipped: %s", failure.Reason)
+	}
+}
+
+// ParseDirectoryReport parses all agents in a directory and reports every file
+// that could not be parsed along with the reason. Failures are returned rather
+// than passed to the warning handler; other warnings still are.
+func (p *Parser) ParseDirectoryReport(dir string) ([]*AgentSpec, []ParseFailure, error) {
 	var agents []*AgentSpec
+	var failures []ParseFailure
 
-	walkErr := filepath.Walk(dir, func(path string, info os.FileInfo, walkFuncErr error) error {
+	walkErr := util.Walk(dir, p.Walk, func(path string, info os.FileInfo, walkFuncErr error) error {
 		if walkFuncErr != nil {
-			// Log error but continue processing other files
-			if !p.SuppressWarnings {
-				fmt.Fprintf(os.Stderr, "Warning: error accessing %s: %v\n", path, walkFuncErr)
+			// A missing root directory simply has no agents; other errors
+			// are reported and the walk continues with the remaining files
+			if path != dir || !os.IsNotExist(walkFuncErr) {
+				failures = append(failures, ParseFailure{Path: path, Reason: walkFuncErr.Error()})
 			}
 			return nil
 		}
 
-		if strings.HasSuffix(path, ".md") {
-			agent, parseErr := p.ParseFile(path)
+		if !info.IsDir() && IsAgentFile(path, p.Extensions) {
+			agent, parseErr := p.parseKnown(path, info)
 			if parseErr != nil {
-				// Log error but continue parsing other files
-				if !p.SuppressWarnings {
-					fmt.Fprintf(os.Stderr, "Warning: error parsing %s: %v\n", path, parseErr)
-				}
+				// Report the file but continue parsing the others
+				failures = append(failures, ParseFailure{Path: path, Reason: parseErr.Error(), ModTime: info.ModTime()})
 				return nil
 			}
+			if relPath, err := filepath.Rel(dir, path); err == nil {
+				agent.Namespace = NamespaceOf(relPath)
+			}
 			agents = append(agents, agent)
 		}
 
 		return nil
 	})
 
-	return agents, walkErr
+	return agents, failures, walkErr
+}
+
+// parseKnown returns a copy of the known agent for path when the file is
+// unchanged, and parses the file otherwise
+func (p *Parser) parseKnown(path string, info os.FileInfo) (*AgentSpec, error) {
+	if known := p.Known[path]; known != nil && known.FileSize == info.Size() && known.ModTime.Equal(info.ModTime()) {
+		agent := *known
+		return &agent, nil
+	}
+	return p.ParseFile(path)
+}
+
+// ReadPrompt reads the prompt body of the agent file at path, without parsing
+// its frontmatter
+func ReadPrompt(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	content, _ = DecodeContent(content)
+	parts := strings.SplitN(string(content), "---", 3)
+	if len(parts) < 3 {
+		return "", fmt.Errorf("invalid agent format: missing frontmatter")
+	}
+	return strings.TrimSpace(parts[2]), nil
 }
@@ -1,13 +1,17 @@
 package parser
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 	"gopkg.in/yaml.v3"
 )
 
@@ -125,6 +129,32 @@ Simple agent prompt.`
 	}
 }
 
+// TestParseFile_Version tests that numeric-looking versions keep their text
+func TestParseFile_Version(t *testing.T) {
+	content := `---
+name: versioned-agent
+description: Agent with a version
+version: 1.10
+---
+
+Versioned agent prompt.`
+
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "versioned-agent.md")
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	agent, err := NewParser().ParseFile(testFile)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	if agent.Version != "1.10" {
+		t.Errorf("Expected version '1.10', got '%s'", agent.Version)
+	}
+}
+
 // TestParseFile_EmptyTools tests parsing of agent with empty tools array
 func TestParseFile_EmptyTools(t *testing.T) {
 	content := `---
@@ -294,6 +324,70 @@ Instructions with accented characters: naïve café.`
 	}
 }
 
+// TestParseFile_Encodings tests that BOM-prefixed, UTF-16 and latin-1 files
+// are decoded and their encoding recorded
+func TestParseFile_Encodings(t *testing.T) {
+	content := "---\nname: cafe-agent\ndescription: Caf\u00e9 reviewer\n---\nR\u00e9sum\u00e9 the diff"
+	utf16 := func(order string) []byte {
+		var out []byte
+		if order == "le" {
+			out = []byte{0xFF, 0xFE}
+		} else {
+			out = []byte{0xFE, 0xFF}
+		}
+		for _, r := range content {
+			if order == "le" {
+				out = append(out, byte(r), byte(r>>8))
+			} else {
+				out = append(out, byte(r>>8), byte(r))
+			}
+		}
+		return out
+	}
+	latin1 := make([]byte, 0, len(content))
+	for _, r := range content {
+		latin1 = append(latin1, byte(r))
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		encoding string
+	}{
+		{"utf-8", []byte(content), ""},
+		{"utf-8 with BOM", append([]byte{0xEF, 0xBB, 0xBF}, content...), EncodingUTF8BOM},
+		{"utf-16le", utf16("le"), EncodingUTF16LE},
+		{"utf-16be", utf16("be"), EncodingUTF16BE},
+		{"latin-1", latin1, EncodingWindows1252},
+	}
+
+	tmpDir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(tmpDir, strings.ReplaceAll(tt.name, " ", "-")+".md")
+			if err := os.WriteFile(testFile, tt.data, 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			agent, err := NewParser().ParseFile(testFile)
+			if err != nil {
+				t.Fatalf("ParseFile failed: %v", err)
+			}
+			if agent.Name != "cafe-agent" || agent.Description != "Caf\u00e9 reviewer" || agent.Prompt != "R\u00e9sum\u00e9 the diff" {
+				t.Errorf("Content not decoded correctly: %+v", agent)
+			}
+			if agent.Encoding != tt.encoding {
+				t.Errorf("Expected encoding %q, got %q", tt.encoding, agent.Encoding)
+			}
+
+			prompt, err := ReadPrompt(testFile)
+			if err != nil || prompt != agent.Prompt {
+				t.Errorf("ReadPrompt() = %q, %v; want %q", prompt, err, agent.Prompt)
+			}
+		})
+	}
+}
+
 // TestParseFile_NonexistentFile tests parsing of file that doesn't exist
 func TestParseFile_NonexistentFile(t *testing.T) {
 	parser := NewParser()
@@ -805,3 +899,240 @@ func TestFlexibleTools_DirectUnmarshal(t *testing.T) {
 		})
 	}
 }
+
+// TestParseDirectory_Modes tests strict and recover handling of malformed files
+func TestParseDirectory_Modes(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"valid.md":        "---\nname: valid\ndescription: Valid agent\n---\nPrompt",
+		"unterminated.md": "---\nname: unterminated\ndescription: Missing closing delimiter\n\nPrompt text",
+		"broken.md":       "no frontmatter at all",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	t.Run("lenient", func(t *testing.T) {
+		p := NewParser()
+		agents, failures, err := p.ParseDirectoryReport(tmpDir)
+		if err != nil {
+			t.Fatalf("ParseDirectoryReport failed: %v", err)
+		}
+		if len(agents) != 1 || len(failures) != 2 {
+			t.Errorf("Expected 1 agent and 2 failures, got %d and %d", len(agents), len(failures))
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		p := NewParser()
+		p.Mode = ModeStrict
+		_, err := p.ParseDirectory(tmpDir)
+
+		var strictErr *StrictError
+		if !errors.As(err, &strictErr) {
+			t.Fatalf("Expected StrictError, got %v", err)
+		}
+		if len(strictErr.Failures) != 2 || !strings.Contains(err.Error(), "broken.md") {
+			t.Errorf("Expected offending files in error, got: %v", err)
+		}
+	})
+
+	t.Run("recover", func(t *testing.T) {
+		p := NewParser()
+		p.Mode = ModeRecover
+		agents, failures, err := p.ParseDirectoryReport(tmpDir)
+		if err != nil {
+			t.Fatalf("ParseDirectoryReport failed: %v", err)
+		}
+		if len(agents) != 2 || len(failures) != 1 {
+			t.Errorf("Expected 2 agents and 1 failure, got %d and %d", len(agents), len(failures))
+		}
+		for _, agent := range agents {
+			if agent.Name == "unterminated" && agent.Prompt != "Prompt text" {
+				t.Errorf("Expected recovered prompt, got %q", agent.Prompt)
+			}
+		}
+	})
+}
+
+// TestParseDirectory_Warnings tests that skipped and recovered files are
+// passed to the warning handler
+func TestParseDirectory_Warnings(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"valid.md":        "---\nname: valid\ndescription: Valid agent\n---\nPrompt",
+		"unterminated.md": "---\nname: unterminated\ndescription: Missing closing delimiter\n\nPrompt text",
+		"broken.md":       "no frontmatter at all",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	var warnings []Warning
+	p := NewParserWithOptions(func(w Warning) {
+		warnings = append(warnings, w)
+	})
+	p.Mode = ModeRecover
+
+	if _, failures, err := p.ParseDirectoryReport(tmpDir); err != nil || len(failures) != 1 {
+		t.Fatalf("Expected 1 failure, got %v (err: %v)", failures, err)
+	}
+	if len(warnings) != 1 || filepath.Base(warnings[0].Path) != "unterminated.md" {
+		t.Fatalf("Expected only the recovery warning from ParseDirectoryReport, got %v", warnings)
+	}
+
+	warnings = nil
+	if _, err := p.ParseDirectory(tmpDir); err != nil {
+		t.Fatalf("ParseDirectory failed: %v", err)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("Expected recovery and skip warnings, got %v", warnings)
+	}
+	skipped := warnings[1]
+	if filepath.Base(skipped.Path) != "broken.md" || !strings.HasPrefix(skipped.Message, "skipped:") {
+		t.Errorf("Expected broken.md to be reported as skipped, got %v", skipped)
+	}
+}
+
+// TestRecoverFrontmatter tests auto-closing of unterminated frontmatter
+func TestRecoverFrontmatter(t *testing.T) {
+	recovered, ok := RecoverFrontmatter("---\nname: a\ntools:\n  - Read\nBody line")
+	if !ok {
+		t.Fatal("Expected frontmatter to be recoverable")
+	}
+	if recovered != "---\nname: a\ntools:\n  - Read\n---\nBody line" {
+		t.Errorf("Unexpected recovered content: %q", recovered)
+	}
+
+	if _, ok := RecoverFrontmatter("---\nname: a\n---\nBody"); ok {
+		t.Error("Terminated frontmatter should not be modified")
+	}
+	if _, ok := RecoverFrontmatter("no frontmatter"); ok {
+		t.Error("Content without opening delimiter should not be recovered")
+	}
+}
+
+// TestParseDirectory_Extensions tests parsing with configured agent file extensions
+func TestParseDirectory_Extensions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	content := "---\nname: %s\ndescription: Test agent\n---\nPrompt"
+	for _, name := range []string{"one.md", "two.markdown", "three.agent.md", "notes.txt"} {
+		agentName := strings.SplitN(name, ".", 2)[0]
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(fmt.Sprintf(content, agentName)), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	p := NewParser()
+	agents, err := p.ParseDirectory(tmpDir)
+	if err != nil {
+		t.Fatalf("ParseDirectory failed: %v", err)
+	}
+	if len(agents) != 2 {
+		t.Errorf("Expected 2 agents with default extensions, got %d", len(agents))
+	}
+
+	p.Extensions = []string{".md", ".markdown"}
+	agents, err = p.ParseDirectory(tmpDir)
+	if err != nil {
+		t.Fatalf("ParseDirectory failed: %v", err)
+	}
+	if len(agents) != 3 {
+		t.Errorf("Expected 3 agents with .markdown enabled, got %d", len(agents))
+	}
+}
+
+// TestParseDirectory_Namespaces tests that nested agents get their directory as namespace
+func TestParseDirectory_Namespaces(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	content := "---\nname: reviewer\ndescription: Test agent\n---\nPrompt"
+	for _, rel := range []string{"reviewer.md", "backend/reviewer.md", "frontend/web/reviewer.md"} {
+		path := filepath.Join(tmpDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", rel, err)
+		}
+	}
+
+	agents, err := NewParser().ParseDirectory(tmpDir)
+	if err != nil {
+		t.Fatalf("ParseDirectory failed: %v", err)
+	}
+
+	var names []string
+	for _, agent := range agents {
+		names = append(names, agent.QualifiedName())
+	}
+	sort.Strings(names)
+	want := []string{"backend/reviewer", "frontend/web/reviewer", "reviewer"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected qualified names %v, got %v", want, names)
+	}
+}
+
+func TestParseDirectory_WalkLimits(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	content := "---\nname: reviewer\ndescription: Test agent\n---\nPrompt"
+	deep := filepath.Join(tmpDir, "a", "b", "reviewer.md")
+	if err := os.MkdirAll(filepath.Dir(deep), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(deep, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(tmpDir, filepath.Join(tmpDir, "a", "loop")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	p := NewParser()
+	p.Walk = util.WalkOptions{FollowSymlinks: true}
+	agents, failures, err := p.ParseDirectoryReport(tmpDir)
+	if err != nil {
+		t.Fatalf("ParseDirectoryReport failed: %v", err)
+	}
+	if len(agents) != 1 || len(failures) != 0 {
+		t.Errorf("Expected the symlink loop walked once, got %d agents and failures %v", len(agents), failures)
+	}
+
+	p.Walk = util.WalkOptions{MaxDepth: 1}
+	agents, failures, err = p.ParseDirectoryReport(tmpDir)
+	if err != nil {
+		t.Fatalf("ParseDirectoryReport failed: %v", err)
+	}
+	if len(agents) != 0 || len(failures) != 1 || !strings.Contains(failures[0].Reason, util.ErrMaxDepth.Error()) {
+		t.Errorf("Expected the directory beyond max depth reported as a failure, got %d agents and failures %v", len(agents), failures)
+	}
+}
+
+// TestIsAgentFile tests extension matching for agent files
+func TestIsAgentFile(t *testing.T) {
+	tests := []struct {
+		path       string
+		extensions []string
+		want       bool
+	}{
+		{"agent.md", nil, true},
+		{"agent.MD", nil, true},
+		{"agent.markdown", nil, false},
+		{"agent.markdown", []string{".md", ".markdown"}, true},
+		{"reviewer.agent.md", []string{".agent.md"}, true},
+		{"reviewer.md", []string{".agent.md"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsAgentFile(tt.path, tt.extensions); got != tt.want {
+			t.Errorf("IsAgentFile(%q, %v) = %v, want %v", tt.path, tt.extensions, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Package server exposes the query index over a small read-only HTTP JSON API.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/engine"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/index"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+)
+
+// Server answers index queries with the query engine
+type Server struct {
+	engine *engine.Engine
+}
+
+// AgentList is the response of the agent listing and search endpoints
+type AgentList struct {
+	Count  int                 `json:"count"`
+	Agents []*parser.AgentSpec `json:"agents"`
+}
+
+// Error is the response of a failed request
+type Error struct {
+	Error string `json:"error"`
+	// Matches lists the qualified names an ambiguous agent name refers to
+	Matches []string `json:"matches,omitempty"`
+}
+
+// New creates a server backed by queryEngine
+func New(queryEngine *engine.Engine) *Server {
+	return &Server{engine: queryEngine}
+}
+
+// Handler returns the HTTP handler serving:
+//
+//	GET /agents          every indexed agent, without prompt bodies
+//	GET /agents/{name}   one agent, by name, qualified name or file name
+//	GET /search?q=       agents matching a query, without prompt bodies
+//
+// The listing endpoints accept limit and source parameters. An index saved
+// by another process since the last request is reloaded first.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /agents", s.listAgents)
+	mux.HandleFunc("GET /agents/{name...}", s.getAgent)
+	mux.HandleFunc("GET /search", s.search)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := s.engine.ReloadIfChanged(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) listAgents(w http.ResponseWriter, r *http.Request) {
+	opts, err := queryOptions(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Error{Error: err.Error()})
+		return
+	}
+
+	var agents []*parser.AgentSpec
+	for _, agent := range s.engine.GetAllAgents() {
+		if opts.Source != "" && agent.Source != opts.Source {
+			continue
+		}
+		agents = append(agents, agent)
+		if opts.Limit > 0 && len(agents) == opts.Limit {
+			break
+		}
+	}
+	writeJSON(w, http.StatusOK, agentList(agents))
+}
+
+func (s *Server) getAgent(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	matches := s.engine.FindAgents(name)
+	switch len(matches) {
+	case 0:
+		writeJSON(w, http.StatusNotFound, Error{Error: fmt.Sprintf("agent not found: %s", name)})
+	case 1:
+		writeJSON(w, http.StatusOK, matches[0])
+	default:
+		names := make([]string, 0, len(matches))
+		for _, agent := range matches {
+			names = append(names, agent.QualifiedName())
+		}
+		writeJSON(w, http.StatusConflict, Error{Error: fmt.Sprintf("agent name is ambiguous: %s", name), Matches: names})
+	}
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeJSON(w, http.StatusBadRequest, Error{Error: "missing query parameter q"})
+		return
+	}
+	opts, err := queryOptions(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Error{Error: err.Error()})
+		return
+	}
+
+	// Multi-word queries use multi-field fuzzy matching, as the query command
+	// does, unless they use phrases, fields or operators
+	var results []*parser.AgentSpec
+	if len(strings.Fields(query)) > 1 && !index.IsStructured(query) {
+		results, err = s.engine.QueryWithFuzzy(query, opts)
+	} else {
+		results, err = s.engine.Query(query, opts)
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Error{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, agentList(results))
+}
+
+// queryOptions reads the limit and source parameters of a request
+func queryOptions(r *http.Request) (engine.QueryOptions, error) {
+	opts := engine.QueryOptions{Context: r.Context(), Source: r.URL.Query().Get("source")}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return opts, fmt.Errorf("invalid limit: %s", value)
+		}
+		opts.Limit = limit
+	}
+	return opts, nil
+}
+
+// agentList returns agents without their prompt bodies, which are only
+// served for a single agent
+func agentList(agents []*parser.AgentSpec) AgentList {
+	list := AgentList{Count: len(agents), Agents: make([]*parser.AgentSpec, 0, len(agents))}
+	for _, agent := range agents {
+		summary := *agent
+		summary.Prompt = ""
+		list.Agents = append(list.Agents, &summary)
+	}
+	return list
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/engine"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	dir := t.TempDir()
+	queryEngine, err := engine.NewEngine(filepath.Join(dir, "index.json"), filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := queryEngine.RebuildWithAgents([]*parser.AgentSpec{
+		{Name: "code-reviewer", Description: "Reviews Go code", Prompt: "Review it", FileName: "code-reviewer.md", Source: "team"},
+		{Name: "deployer", Description: "Deploys services", Prompt: "Deploy it", FileName: "deployer.md", Source: "ops"},
+		{Name: "deployer", Description: "Deploys staging", Prompt: "Stage it", FileName: "deployer.md", Namespace: "staging", Source: "ops"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(New(queryEngine).Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(t *testing.T, url string, wantStatus int, v interface{}) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("GET %s returned %d, want %d", url, resp.StatusCode, wantStatus)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Unexpected Content-Type %q", ct)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("Failed to decode response of %s: %v", url, err)
+	}
+}
+
+func TestListAgents(t *testing.T) {
+	server := newTestServer(t)
+
+	var list AgentList
+	get(t, server.URL+"/agents", http.StatusOK, &list)
+	if list.Count != 3 || len(list.Agents) != 3 {
+		t.Fatalf("Expected 3 agents, got %+v", list)
+	}
+	for _, agent := range list.Agents {
+		if agent.Prompt != "" {
+			t.Errorf("Expected listings without prompts, got %q for %s", agent.Prompt, agent.Name)
+		}
+	}
+
+	get(t, server.URL+"/agents?source=ops&limit=1", http.StatusOK, &list)
+	if list.Count != 1 || list.Agents[0].Source != "ops" {
+		t.Errorf("Expected one ops agent, got %+v", list)
+	}
+
+	var failure Error
+	get(t, server.URL+"/agents?limit=-1", http.StatusBadRequest, &failure)
+}
+
+func TestGetAgent(t *testing.T) {
+	server := newTestServer(t)
+
+	var agent parser.AgentSpec
+	get(t, server.URL+"/agents/code-reviewer", http.StatusOK, &agent)
+	if agent.Name != "code-reviewer" || agent.Prompt != "Review it" {
+		t.Errorf("Expected the full agent, got %+v", agent)
+	}
+
+	get(t, server.URL+"/agents/staging/deployer", http.StatusOK, &agent)
+	if agent.Namespace != "staging" {
+		t.Errorf("Expected the namespaced agent, got %+v", agent)
+	}
+
+	var failure Error
+	get(t, server.URL+"/agents/deployer", http.StatusConflict, &failure)
+	if len(failure.Matches) != 2 {
+		t.Errorf("Expected both deployers to be listed, got %+v", failure)
+	}
+	get(t, server.URL+"/agents/zzzzzzzz", http.StatusNotFound, &failure)
+}
+
+func TestSearch(t *testing.T) {
+	server := newTestServer(t)
+
+	var list AgentList
+	get(t, server.URL+"/search?q=reviewer", http.StatusOK, &list)
+	if list.Count == 0 || list.Agents[0].Name != "code-reviewer" {
+		t.Errorf("Expected code-reviewer first, got %+v", list)
+	}
+
+	var failure Error
+	get(t, server.URL+"/search", http.StatusBadRequest, &failure)
+}
+
+func TestReadOnly(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, err := http.Post(server.URL+"/agents", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected POST to be rejected, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,144 @@
+package stats
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+)
+
+// Cache persists per-agent validation results between stats runs so that only
+// new or modified agents are revalidated
+type Cache struct {
+	path string
+}
+
+// cacheData is the on-disk format of the stats cache
+type cacheData struct {
+	Generation string `json:"generation"`
+	// Validator identifies the validation settings the results were computed
+	// with; results computed with other settings are never reused
+	Validator   string                      `json:"validator,omitempty"`
+	Validations map[string]*AgentValidation `json:"validations"`
+}
+
+// CacheResult describes how much of a calculation was served from the cache
+type CacheResult struct {
+	Hit         bool // the agent set is unchanged since the cache was written
+	Reused      int  // agents whose validation results were reused
+	Recomputed  int  // agents that had to be validated again
+	Generation  string
+	Invalidated bool // the cache file could not be read and was ignored
+}
+
+// NewCache creates a stats cache stored at path
+func NewCache(path string) *Cache {
+	return &Cache{path: path}
+}
+
+// AgentSignature identifies an agent revision by path, name, size and modification time
+func AgentSignature(agent *parser.AgentSpec) string {
+	return fmt.Sprintf("%s|%s|%d|%d", agent.FilePath, agent.Name, agent.FileSize, agent.ModTime.UnixNano())
+}
+
+// Generation returns a fingerprint of an agent set and total file count; it
+// changes whenever any agent is added, removed or modified
+func Generation(agents []*parser.AgentSpec, totalFiles int) string {
+	signatures := make([]string, 0, len(agents))
+	for _, agent := range agents {
+		signatures = append(signatures, AgentSignature(agent))
+	}
+	sort.Strings(signatures)
+
+	hasher := sha256.New()
+	for _, signature := range signatures {
+		hasher.Write([]byte(signature))
+		hasher.Write([]byte{'\n'})
+	}
+	fmt.Fprintf(hasher, "total:%d", totalFiles)
+	return fmt.Sprintf("%x", hasher.Sum(nil))[:16]
+}
+
+// Prepare seeds the calculator with cached validation results for agents that
+// have not changed since the last run
+func (sc *Cache) Prepare(calc *Calculator) CacheResult {
+	result := CacheResult{Generation: Generation(calc.agents, calc.totalFiles)}
+
+	data, err := sc.load()
+	if err != nil {
+		result.Invalidated = !os.IsNotExist(err)
+		result.Recomputed = len(calc.agents)
+		return result
+	}
+
+	if data.Validator != calc.validatorKey() {
+		result.Recomputed = len(calc.agents)
+		return result
+	}
+	result.Hit = data.Generation == result.Generation
+
+	calc.validations = make(map[string]*AgentValidation, len(calc.agents))
+	for _, agent := range calc.agents {
+		signature := AgentSignature(agent)
+		if cached, ok := data.Validations[signature]; ok && cached != nil {
+			calc.validations[signature] = cached
+			result.Reused++
+		} else {
+			result.Recomputed++
+		}
+	}
+
+	return result
+}
+
+// Save writes the calculator's validation results to the cache, dropping
+// entries for agents that no longer exist
+func (sc *Cache) Save(calc *Calculator) error {
+	data := cacheData{
+		Generation:  Generation(calc.agents, calc.totalFiles),
+		Validator:   calc.validatorKey(),
+		Validations: make(map[string]*AgentValidation, len(calc.agents)),
+	}
+	for _, agent := range calc.agents {
+		data.Validations[AgentSignature(agent)] = calc.validation(agent)
+	}
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode stats cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(sc.path), 0750); err != nil {
+		return fmt.Errorf("failed to create stats cache directory: %w", err)
+	}
+
+	// Write atomically using temp file
+	tempFile := sc.path + ".tmp"
+	if err := os.WriteFile(tempFile, content, 0600); err != nil {
+		return fmt.Errorf("failed to write stats cache: %w", err)
+	}
+	if err := os.Rename(tempFile, sc.path); err != nil {
+		_ = os.Remove(tempFile)
+		return fmt.Errorf("failed to save stats cache: %w", err)
+	}
+
+	return nil
+}
+
+// load reads the cache file
+func (sc *Cache) load() (*cacheData, error) {
+	content, err := os.ReadFile(sc.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var data cacheData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse stats cache: %w", err)
+	}
+	return &data, nil
+}
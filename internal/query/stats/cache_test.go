@@ -0,0 +1,106 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_PrepareAndSave(t *testing.T) {
+	modTime := time.Now()
+	agents := []*parser.AgentSpec{
+		{Name: "valid-agent", Description: "A valid test agent", Prompt: "Prompt", FilePath: "/a.md", ModTime: modTime},
+		{Name: "Invalid Name", Description: "An invalid test agent", Prompt: "Prompt", FilePath: "/b.md", ModTime: modTime},
+	}
+
+	cache := NewCache(filepath.Join(t.TempDir(), ".agent-stats"))
+
+	// First run has nothing cached
+	calc := NewCalculatorWithTotal(agents, 2)
+	result := cache.Prepare(calc)
+	assert.False(t, result.Hit)
+	assert.Equal(t, 2, result.Recomputed)
+	expected := calc.Calculate()
+	require.NoError(t, cache.Save(calc))
+
+	// Unchanged agents are served entirely from the cache
+	calc = NewCalculatorWithTotal(agents, 2)
+	result = cache.Prepare(calc)
+	assert.True(t, result.Hit)
+	assert.Equal(t, 2, result.Reused)
+	assert.Equal(t, expected.OrphanedAgents, calc.Calculate().OrphanedAgents)
+
+	// A modified agent is the only one recomputed
+	changed := *agents[1]
+	changed.Name = "fixed-agent"
+	changed.ModTime = modTime.Add(time.Second)
+	calc = NewCalculatorWithTotal([]*parser.AgentSpec{agents[0], &changed}, 2)
+	result = cache.Prepare(calc)
+	assert.False(t, result.Hit)
+	assert.Equal(t, 1, result.Reused)
+	assert.Equal(t, 1, result.Recomputed)
+	assert.Equal(t, 0, calc.Calculate().OrphanedAgents)
+}
+
+func TestCache_ValidatorChange(t *testing.T) {
+	agents := []*parser.AgentSpec{
+		{Name: "deployer", Description: "Deploys things", Prompt: "Prompt", Tools: parser.FlexibleTools{"Kubectl"}, FilePath: "/a.md"},
+	}
+	cache := NewCache(filepath.Join(t.TempDir(), ".agent-stats"))
+
+	calc := NewCalculator(agents)
+	cache.Prepare(calc)
+	require.NoError(t, cache.Save(calc))
+
+	// Results computed without tool checks are not reused with them
+	v := validator.NewValidator()
+	calc = NewCalculator(agents)
+	calc.SetToolValidator(v)
+	result := cache.Prepare(calc)
+	assert.False(t, result.Hit)
+	assert.Equal(t, 0, result.Reused)
+	assert.Contains(t, calc.GetValidationReport()["common_warnings"], "Unknown tool Kubectl")
+	require.NoError(t, cache.Save(calc))
+
+	// Nor with a different tool list
+	v.SetAllowedTools([]string{"Kubectl"})
+	calc = NewCalculator(agents)
+	calc.SetToolValidator(v)
+	result = cache.Prepare(calc)
+	assert.Equal(t, 0, result.Reused)
+	assert.NotContains(t, calc.GetValidationReport()["common_warnings"], "Unknown tool Kubectl")
+	require.NoError(t, cache.Save(calc))
+
+	// The same settings reuse the results
+	calc = NewCalculator(agents)
+	calc.SetToolValidator(v)
+	result = cache.Prepare(calc)
+	assert.True(t, result.Hit)
+	assert.Equal(t, 1, result.Reused)
+}
+
+func TestCache_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".agent-stats")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0600))
+
+	calc := NewCalculator([]*parser.AgentSpec{{Name: "agent", FilePath: "/a.md"}})
+	result := NewCache(path).Prepare(calc)
+
+	assert.True(t, result.Invalidated)
+	assert.Equal(t, 1, result.Recomputed)
+}
+
+func TestGeneration(t *testing.T) {
+	a := &parser.AgentSpec{Name: "a", FilePath: "/a.md"}
+	b := &parser.AgentSpec{Name: "b", FilePath: "/b.md"}
+
+	assert.Equal(t, Generation([]*parser.AgentSpec{a, b}, 2), Generation([]*parser.AgentSpec{b, a}, 2))
+	assert.NotEqual(t, Generation([]*parser.AgentSpec{a, b}, 2), Generation([]*parser.AgentSpec{a, b}, 3))
+	assert.NotEqual(t, Generation([]*parser.AgentSpec{a}, 1), Generation([]*parser.AgentSpec{b}, 1))
+}
@@ -0,0 +1,106 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+)
+
+// Untracked is the source reported for indexed agents no tracked source installed
+const Untracked = "(untracked)"
+
+// SourceStats summarizes what one installed source contributes to the index
+type SourceStats struct {
+	Source string `json:"source"`
+	// Agents counts the source's agents found in the index
+	Agents int `json:"agents"`
+	// Invalid counts agents that fail validation, and Broken files that failed to parse
+	Invalid int `json:"invalid"`
+	Broken  int `json:"broken"`
+	// Files counts the tracked files, of which Missing are no longer on disk
+	Files     int       `json:"files"`
+	Missing   int       `json:"missing"`
+	DiskBytes int64     `json:"disk_bytes"`
+	Commit    string    `json:"commit,omitempty"`
+	Updated   time.Time `json:"updated,omitempty"`
+}
+
+// CalculateTrackedSourceStats joins the indexed agents and parse failures with
+// the tracked installations, attributing each file to the source that
+// installed it. Sources are ordered by name, followed by Untracked when some
+// indexed agents belong to no source.
+func (c *Calculator) CalculateTrackedSourceStats(installations map[string]*tracker.Installation, broken []parser.ParseFailure) []*SourceStats {
+	bySource := make(map[string]*SourceStats, len(installations))
+	owners := make(map[string]string)
+	for name, installation := range installations {
+		entry := &SourceStats{
+			Source:  name,
+			Files:   len(installation.Files),
+			Commit:  installation.SourceCommit,
+			Updated: installation.Timestamp,
+		}
+		for _, category := range installation.Categories {
+			if category.Timestamp.After(entry.Updated) {
+				entry.Updated = category.Timestamp
+			}
+		}
+		for path := range installation.Files {
+			owners[absPath(path)] = name
+			info, err := os.Stat(path)
+			if err != nil {
+				entry.Missing++
+				continue
+			}
+			entry.DiskBytes += info.Size()
+		}
+		bySource[name] = entry
+	}
+
+	untracked := &SourceStats{Source: Untracked}
+	owner := func(path string) *SourceStats {
+		if entry, ok := bySource[owners[absPath(path)]]; ok {
+			return entry
+		}
+		return untracked
+	}
+	for _, agent := range c.agents {
+		entry := owner(agent.FilePath)
+		entry.Agents++
+		if c.validation(agent).Error != "" {
+			entry.Invalid++
+		}
+		if entry == untracked {
+			untracked.Files++
+			untracked.DiskBytes += agent.FileSize
+		}
+	}
+	for _, failure := range broken {
+		entry := owner(failure.Path)
+		entry.Broken++
+		if entry == untracked {
+			untracked.Files++
+		}
+	}
+
+	result := make([]*SourceStats, 0, len(bySource)+1)
+	for _, entry := range bySource {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(a, b int) bool { return result[a].Source < result[b].Source })
+	if untracked.Files > 0 {
+		result = append(result, untracked)
+	}
+	return result
+}
+
+// absPath returns the absolute form of path, or path itself when it cannot be resolved
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,71 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/tracker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculator_CalculateTrackedSourceStats(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	reviewer := write("reviewer.md", "reviewer agent")
+	invalid := write("invalid.md", "x")
+	brokenFile := write("broken.md", "no frontmatter")
+	manual := write("manual.md", "manual")
+	missing := filepath.Join(dir, "missing.md")
+
+	installed := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	installations := map[string]*tracker.Installation{
+		"team": {
+			Timestamp:    installed,
+			SourceCommit: "0123456789abcdef",
+			Files: map[string]tracker.FileInfo{
+				reviewer:   {Path: reviewer},
+				invalid:    {Path: invalid},
+				brokenFile: {Path: brokenFile},
+				missing:    {Path: missing},
+			},
+			Categories: map[string]*tracker.CategoryInstallation{
+				"review": {Timestamp: installed.Add(time.Hour)},
+			},
+		},
+		"empty": {Timestamp: installed, Files: map[string]tracker.FileInfo{}},
+	}
+
+	calc := NewCalculator([]*parser.AgentSpec{
+		{Name: "reviewer", Description: "Reviews code", Prompt: "Review", FilePath: reviewer},
+		{Name: "", FilePath: invalid},
+		{Name: "manual", Description: "Manual agent", FilePath: manual, FileSize: 6},
+	})
+	result := calc.CalculateTrackedSourceStats(installations, []parser.ParseFailure{{Path: brokenFile, Reason: "bad"}})
+
+	require.Len(t, result, 3)
+	assert.Equal(t, "empty", result[0].Source)
+	assert.Equal(t, 0, result[0].Agents)
+
+	team := result[1]
+	assert.Equal(t, "team", team.Source)
+	assert.Equal(t, 2, team.Agents)
+	assert.Equal(t, 1, team.Invalid)
+	assert.Equal(t, 1, team.Broken)
+	assert.Equal(t, 4, team.Files)
+	assert.Equal(t, 1, team.Missing)
+	assert.Equal(t, int64(len("reviewer agent")+len("x")+len("no frontmatter")), team.DiskBytes)
+	assert.Equal(t, "0123456789abcdef", team.Commit)
+	assert.Equal(t, installed.Add(time.Hour), team.Updated, "expected the latest category update")
+
+	assert.Equal(t, Untracked, result[2].Source)
+	assert.Equal(t, 1, result[2].Agents)
+	assert.Equal(t, int64(6), result[2].DiskBytes)
+}
@@ -1,14 +1,28 @@
 package stats
 
 import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
 	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
 	"github.com/pacphi/claude-code-agent-manager/internal/query/validator"
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
 )
 
 // Calculator computes agent statistics
 type Calculator struct {
-	agents     []*parser.AgentSpec
-	totalFiles int // Total number of .md files (including unparseable ones)
+	agents      []*parser.AgentSpec
+	totalFiles  int                         // Total number of .md files (including unparseable ones)
+	validations map[string]*AgentValidation // Validation results keyed by agent signature
+	tools       *validator.Validator        // Reports unknown tools when set
+}
+
+// AgentValidation holds the validation outcome for a single agent
+type AgentValidation struct {
+	Error    string   `json:"error,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // NewCalculator creates a new stats calculator
@@ -27,10 +41,35 @@ func NewCalculatorWithTotal(agents []*parser.AgentSpec, totalFiles int) *Calcula
 	}
 }
 
+// SetToolValidator makes validation warn about requested tools v does not
+// know, as settings.query.validation.check_tool_validity does for validate;
+// nil skips the check
+func (c *Calculator) SetToolValidator(v *validator.Validator) {
+	c.tools = v
+}
+
+// validatorKey identifies the validation settings of the calculator: empty
+// without a tool validator, otherwise a fingerprint of its allowed tools
+func (c *Calculator) validatorKey() string {
+	if c.tools == nil {
+		return ""
+	}
+	hasher := sha256.New()
+	for _, tool := range c.tools.AllowedTools() {
+		hasher.Write([]byte(tool))
+		hasher.Write([]byte{'\n'})
+	}
+	return fmt.Sprintf("%x", hasher.Sum(nil))[:16]
+}
+
+// NoLicense is the ByLicense key counting agents that declare no license
+const NoLicense = "none"
+
 // Statistics holds computed statistics
 type Statistics struct {
 	TotalAgents    int                 `json:"total_agents"`
 	BySource       map[string]int      `json:"by_source"`
+	ByLicense      map[string]int      `json:"by_license"`
 	Coverage       CoverageStats       `json:"coverage"`
 	ToolUsage      ToolStats           `json:"tool_usage"`
 	Duplicates     map[string][]string `json:"duplicates"`
@@ -58,6 +97,7 @@ func (c *Calculator) Calculate() *Statistics {
 	stats := &Statistics{
 		TotalAgents: len(c.agents),
 		BySource:    make(map[string]int),
+		ByLicense:   make(map[string]int),
 		Duplicates:  make(map[string][]string),
 	}
 
@@ -68,6 +108,15 @@ func (c *Calculator) Calculate() *Statistics {
 		}
 	}
 
+	// Count by declared license
+	for _, agent := range c.agents {
+		license := agent.License
+		if license == "" {
+			license = NoLicense
+		}
+		stats.ByLicense[license]++
+	}
+
 	// Calculate coverage metrics
 	stats.Coverage = c.calculateCoverage()
 
@@ -86,9 +135,8 @@ func (c *Calculator) Calculate() *Statistics {
 	}
 
 	// Count orphaned (invalid) agents
-	validator := validator.NewValidator()
 	for _, agent := range c.agents {
-		if err := validator.Validate(agent); err != nil {
+		if c.validation(agent).Error != "" {
 			stats.OrphanedAgents++
 		}
 	}
@@ -96,6 +144,78 @@ func (c *Calculator) Calculate() *Statistics {
 	return stats
 }
 
+// validation returns the validation result for an agent, validating it on first use
+func (c *Calculator) validation(agent *parser.AgentSpec) *AgentValidation {
+	if c.validations == nil {
+		c.validations = make(map[string]*AgentValidation, len(c.agents))
+	}
+
+	signature := AgentSignature(agent)
+	if result, ok := c.validations[signature]; ok {
+		return result
+	}
+
+	result := c.validate(agent)
+	c.validations[signature] = result
+	return result
+}
+
+// validate validates a single agent
+func (c *Calculator) validate(agent *parser.AgentSpec) *AgentValidation {
+	v := validator.NewValidator()
+	result := &AgentValidation{Warnings: v.ValidateWithReport(agent).Warnings}
+	if err := v.Validate(agent); err != nil {
+		result.Error = err.Error()
+	}
+	if c.tools != nil {
+		for _, tool := range c.tools.UnknownTools(agent.GetToolsAsSlice()) {
+			result.Warnings = append(result.Warnings, "Unknown tool "+tool)
+		}
+	}
+	return result
+}
+
+// Unvalidated returns the agents that have no validation result yet, such as
+// those not served from the cache
+func (c *Calculator) Unvalidated() []*parser.AgentSpec {
+	var pending []*parser.AgentSpec
+	seen := make(map[string]bool)
+	for _, agent := range c.agents {
+		signature := AgentSignature(agent)
+		if _, ok := c.validations[signature]; ok || seen[signature] {
+			continue
+		}
+		seen[signature] = true
+		pending = append(pending, agent)
+	}
+	return pending
+}
+
+// ValidateAll validates the unvalidated agents on a bounded pool of workers
+// (one per CPU when workers is not positive), calling progress after each
+// agent. Results are stored by agent signature and reports aggregate them in
+// agent order, so they do not depend on scheduling.
+func (c *Calculator) ValidateAll(ctx context.Context, workers int, progress func(int)) error {
+	pending := c.Unvalidated()
+	results := make([]*AgentValidation, len(pending))
+	err := util.ForEachParallel(ctx, len(pending), workers, func(i int) {
+		results[i] = c.validate(pending[i])
+		if progress != nil {
+			progress(1)
+		}
+	})
+
+	if c.validations == nil {
+		c.validations = make(map[string]*AgentValidation, len(c.agents))
+	}
+	for i, agent := range pending {
+		if results[i] != nil {
+			c.validations[AgentSignature(agent)] = results[i]
+		}
+	}
+	return err
+}
+
 // calculateCoverage computes field coverage metrics
 func (c *Calculator) calculateCoverage() CoverageStats {
 	coverage := CoverageStats{}
@@ -176,6 +296,8 @@ func (c *Calculator) CalculateSourceStats() map[string]*Statistics {
 	result := make(map[string]*Statistics)
 	for source, agents := range sourceGroups {
 		calc := NewCalculator(agents)
+		calc.validations = c.validations // Share validation results across groups
+		calc.tools = c.tools
 		result[source] = calc.Calculate()
 	}
 
@@ -228,9 +350,22 @@ func (c *Calculator) GetTopTools(limit int) []struct {
 	return result
 }
 
+// LargestAgents returns agents ordered by file size, largest first
+func (c *Calculator) LargestAgents(limit int) []*parser.AgentSpec {
+	agents := make([]*parser.AgentSpec, len(c.agents))
+	copy(agents, c.agents)
+	sort.SliceStable(agents, func(i, j int) bool {
+		return agents[i].FileSize > agents[j].FileSize
+	})
+
+	if limit > 0 && len(agents) > limit {
+		agents = agents[:limit]
+	}
+	return agents
+}
+
 // GetValidationReport provides detailed validation results
 func (c *Calculator) GetValidationReport() map[string]interface{} {
-	validator := validator.NewValidator()
 	validCount := 0
 	invalidCount := 0
 	errors := make(map[string]int)
@@ -238,16 +373,15 @@ func (c *Calculator) GetValidationReport() map[string]interface{} {
 
 	// Validate parsed agents
 	for _, agent := range c.agents {
-		if err := validator.Validate(agent); err != nil {
+		result := c.validation(agent)
+		if result.Error != "" {
 			invalidCount++
-			errors[err.Error()]++
+			errors[result.Error]++
 		} else {
 			validCount++
 		}
 
-		// Get detailed report for warnings
-		report := validator.ValidateWithReport(agent)
-		for _, warning := range report.Warnings {
+		for _, warning := range result.Warnings {
 			warnings[warning]++
 		}
 	}
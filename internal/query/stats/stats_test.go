@@ -1,10 +1,14 @@
 package stats
 
 import (
+	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
+	"github.com/pacphi/claude-code-agent-manager/internal/query/validator"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -352,3 +356,83 @@ func TestCalculator_RealWorldScenario(t *testing.T) {
 	}
 	assert.Equal(t, expectedTools, stats.ToolUsage.ToolDistribution)
 }
+
+func TestCalculator_LargestAgents(t *testing.T) {
+	agents := []*parser.AgentSpec{
+		{Name: "small", FileSize: 100},
+		{Name: "large", FileSize: 9000},
+		{Name: "medium", FileSize: 2048},
+	}
+
+	calc := NewCalculator(agents)
+
+	largest := calc.LargestAgents(2)
+	assert.Len(t, largest, 2)
+	assert.Equal(t, "large", largest[0].Name)
+	assert.Equal(t, "medium", largest[1].Name)
+
+	// The calculator's own ordering is left untouched
+	assert.Equal(t, "small", calc.agents[0].Name)
+	assert.Len(t, calc.LargestAgents(0), 3)
+}
+
+func TestCalculator_ByLicense(t *testing.T) {
+	agents := []*parser.AgentSpec{
+		{Name: "a", License: "MIT"},
+		{Name: "b", License: "MIT"},
+		{Name: "c"},
+	}
+
+	stats := NewCalculator(agents).Calculate()
+	assert.Equal(t, map[string]int{"MIT": 2, NoLicense: 1}, stats.ByLicense)
+}
+
+func TestCalculator_ValidateAll(t *testing.T) {
+	var agents []*parser.AgentSpec
+	for i := 0; i < 50; i++ {
+		agent := &parser.AgentSpec{Name: fmt.Sprintf("agent-%d", i), Description: "Agent", FilePath: fmt.Sprintf("/agents/agent-%d.md", i)}
+		if i%3 == 0 {
+			agent.Name = fmt.Sprintf("Invalid_%d", i)
+		} else {
+			agent.Prompt = "You are a helpful agent"
+		}
+		agents = append(agents, agent)
+	}
+
+	sequential := NewCalculator(agents)
+	want := sequential.GetValidationReport()
+
+	parallel := NewCalculator(agents)
+	assert.Len(t, parallel.Unvalidated(), len(agents))
+	var progressed int
+	var mu sync.Mutex
+	err := parallel.ValidateAll(context.Background(), 4, func(n int) {
+		mu.Lock()
+		progressed += n
+		mu.Unlock()
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, len(agents), progressed)
+	assert.Empty(t, parallel.Unvalidated())
+	assert.Equal(t, want, parallel.GetValidationReport())
+	assert.Equal(t, sequential.Calculate(), parallel.Calculate())
+}
+
+func TestCalculator_ToolValidator(t *testing.T) {
+	agents := []*parser.AgentSpec{
+		{Name: "deployer", Description: "Deploys things", Prompt: "You deploy services", Tools: parser.FlexibleTools{"Read", "Kubectl"}, FilePath: "/agents/deployer.md"},
+	}
+
+	calc := NewCalculator(agents)
+	assert.NotContains(t, calc.GetValidationReport()["common_warnings"], "Unknown tool Kubectl")
+
+	v := validator.NewValidator()
+	calc = NewCalculator(agents)
+	calc.SetToolValidator(v)
+	assert.Contains(t, calc.GetValidationReport()["common_warnings"], "Unknown tool Kubectl")
+
+	v.SetAllowedTools([]string{"Read", "Kubectl"})
+	calc = NewCalculator(agents)
+	calc.SetToolValidator(v)
+	assert.NotContains(t, calc.GetValidationReport()["common_warnings"], "Unknown tool Kubectl")
+}
@@ -0,0 +1,99 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// claudeToolsFile is the type declaration file shipped with the Claude Code
+// package that declares the input schema of every built-in tool
+const claudeToolsFile = "sdk-tools.d.ts"
+
+// toolInputPattern matches a tool input schema declaration such as
+// "export interface BashInput"
+var toolInputPattern = regexp.MustCompile(`export (?:interface|type) (\w+)Input\b`)
+
+// schemaToolNames maps schema names that differ from the tool names agents use
+var schemaToolNames = map[string]string{
+	"Agent":            "Task",
+	"FileRead":         "Read",
+	"FileWrite":        "Write",
+	"FileEdit":         "Edit",
+	"FileMultiEdit":    "MultiEdit",
+	"ListMcpResources": "ListMcpResourcesTool",
+	"ReadMcpResource":  "ReadMcpResourceTool",
+}
+
+// ClaudeTools returns the tool names of the locally installed Claude Code,
+// read from the tool schemas shipped with its package
+func ClaudeTools() ([]string, error) {
+	path, err := findClaudeToolsFile()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	tools := parseClaudeTools(string(content))
+	if len(tools) == 0 {
+		return nil, fmt.Errorf("no tool schemas found in %s", path)
+	}
+	return tools, nil
+}
+
+// parseClaudeTools extracts tool names from tool schema declarations
+func parseClaudeTools(content string) []string {
+	seen := make(map[string]bool)
+	var tools []string
+	for _, match := range toolInputPattern.FindAllStringSubmatch(content, -1) {
+		name := match[1]
+		if mapped, ok := schemaToolNames[name]; ok {
+			name = mapped
+		}
+		if name == "Tool" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		tools = append(tools, name)
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// findClaudeToolsFile locates the tool schema file of the Claude Code package,
+// starting from the claude executable on PATH and the local install location
+func findClaudeToolsFile() (string, error) {
+	var candidates []string
+	if executable, err := exec.LookPath("claude"); err == nil {
+		if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+			executable = resolved
+		}
+		// Walk up from the executable to the package root
+		for dir := filepath.Dir(executable); ; dir = filepath.Dir(dir) {
+			candidates = append(candidates,
+				filepath.Join(dir, claudeToolsFile),
+				filepath.Join(dir, "node_modules", "@anthropic-ai", "claude-code", claudeToolsFile),
+				filepath.Join(dir, "lib", "node_modules", "@anthropic-ai", "claude-code", claudeToolsFile))
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates,
+			filepath.Join(home, ".claude", "local", "node_modules", "@anthropic-ai", "claude-code", claudeToolsFile))
+	}
+
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("Claude Code installation with %s not found; install it or configure settings.query.validation.allowed_tools", claudeToolsFile)
+}
@@ -0,0 +1,100 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Permissions holds the tool permission rules from Claude Code settings files
+type Permissions struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+	Ask   []string `json:"ask"`
+}
+
+// settingsFile is the subset of a Claude Code settings file read for validation
+type settingsFile struct {
+	Permissions Permissions `json:"permissions"`
+}
+
+// DefaultSettingsPaths returns the user and project settings files in the
+// order Claude Code applies them
+func DefaultSettingsPaths() []string {
+	var paths []string
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".claude", "settings.json"))
+	}
+	return append(paths,
+		filepath.Join(".claude", "settings.json"),
+		filepath.Join(".claude", "settings.local.json"),
+	)
+}
+
+// LoadPermissions merges the permission rules of the given settings files,
+// skipping files that do not exist
+func LoadPermissions(paths ...string) (*Permissions, error) {
+	merged := &Permissions{}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read settings %s: %w", path, err)
+		}
+
+		var settings settingsFile
+		if err := json.Unmarshal(content, &settings); err != nil {
+			return nil, fmt.Errorf("failed to parse settings %s: %w", path, err)
+		}
+		merged.Allow = append(merged.Allow, settings.Permissions.Allow...)
+		merged.Deny = append(merged.Deny, settings.Permissions.Deny...)
+		merged.Ask = append(merged.Ask, settings.Permissions.Ask...)
+	}
+	return merged, nil
+}
+
+// CheckTools returns a message for each requested tool that the permission
+// rules deny outright, restrict to some uses, or gate behind approval
+func (p *Permissions) CheckTools(tools []string) []string {
+	var messages []string
+	for _, tool := range tools {
+		if rule, whole := matchRule(p.Deny, tool); rule != "" {
+			if whole {
+				messages = append(messages, fmt.Sprintf("tool %s is denied by permissions.deny rule %q; remove it from the agent's tools or from the deny list", tool, rule))
+			} else {
+				messages = append(messages, fmt.Sprintf("tool %s is restricted by permissions.deny rule %q; uses matching the rule will be blocked", tool, rule))
+			}
+			continue
+		}
+		if rule, _ := matchRule(p.Ask, tool); rule != "" {
+			messages = append(messages, fmt.Sprintf("tool %s requires approval under permissions.ask rule %q", tool, rule))
+		}
+	}
+	return messages
+}
+
+// matchRule finds the first rule applying to tool and reports whether it
+// covers every use of the tool rather than a specifier such as Bash(curl:*)
+func matchRule(rules []string, tool string) (string, bool) {
+	var partial string
+	for _, rule := range rules {
+		name, specifier, scoped := strings.Cut(rule, "(")
+		specifier = strings.TrimSuffix(specifier, ")")
+
+		// A rule for an MCP server covers every tool it provides
+		if name != tool && !(strings.HasPrefix(name, "mcp__") && strings.HasPrefix(tool, name+"__")) {
+			continue
+		}
+		if !scoped || specifier == "" || specifier == "*" {
+			return rule, true
+		}
+		if partial == "" {
+			partial = rule
+		}
+	}
+	return partial, false
+}
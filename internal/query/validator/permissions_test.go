@@ -0,0 +1,75 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoadPermissions tests merging rules from several settings files
+func TestLoadPermissions(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user.json")
+	project := filepath.Join(dir, "project.json")
+	if err := os.WriteFile(user, []byte(`{"permissions": {"deny": ["WebFetch"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(project, []byte(`{"model": "x", "permissions": {"allow": ["Read"], "ask": ["Bash"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	permissions, err := LoadPermissions(user, filepath.Join(dir, "missing.json"), project)
+	if err != nil {
+		t.Fatalf("LoadPermissions failed: %v", err)
+	}
+	if len(permissions.Deny) != 1 || len(permissions.Allow) != 1 || len(permissions.Ask) != 1 {
+		t.Errorf("Unexpected merged permissions: %+v", permissions)
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPermissions(invalid); err == nil {
+		t.Error("Expected error for malformed settings")
+	}
+}
+
+// TestPermissionsCheckTools tests messages for denied, restricted and gated tools
+func TestPermissionsCheckTools(t *testing.T) {
+	permissions := &Permissions{
+		Allow: []string{"Read"},
+		Deny:  []string{"WebFetch", "Bash(curl:*)", "mcp__github", "Write(*)"},
+		Ask:   []string{"Edit"},
+	}
+
+	tests := []struct {
+		tool string
+		want string
+	}{
+		{tool: "Read"},
+		{tool: "Grep"},
+		{tool: "WebFetch", want: `tool WebFetch is denied by permissions.deny rule "WebFetch"`},
+		{tool: "Write", want: `tool Write is denied by permissions.deny rule "Write(*)"`},
+		{tool: "Bash", want: `tool Bash is restricted by permissions.deny rule "Bash(curl:*)"`},
+		{tool: "mcp__github__create_issue", want: `denied by permissions.deny rule "mcp__github"`},
+		{tool: "mcp__githubby__search"},
+		{tool: "Edit", want: `tool Edit requires approval under permissions.ask rule "Edit"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			messages := permissions.CheckTools([]string{tt.tool})
+			if tt.want == "" {
+				if len(messages) != 0 {
+					t.Errorf("Expected no messages, got %v", messages)
+				}
+				return
+			}
+			if len(messages) != 1 || !strings.Contains(messages[0], tt.want) {
+				t.Errorf("Expected message containing %q, got %v", tt.want, messages)
+			}
+		})
+	}
+}
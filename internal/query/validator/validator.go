@@ -3,10 +3,19 @@ package validator
 import (
 	"fmt"
 	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/pacphi/claude-code-agent-manager/internal/query/parser"
 )
 
+// DefaultAllowedTools are the built-in Claude Code tools known to the validator
+var DefaultAllowedTools = []string{
+	"Read", "Write", "Edit", "MultiEdit", "NotebookEdit",
+	"Task", "Bash", "BashOutput", "KillShell", "Grep", "Glob", "LS",
+	"WebFetch", "WebSearch", "TodoWrite", "ExitPlanMode", "SlashCommand",
+}
+
 // Validator validates agent specifications
 type Validator struct {
 	namePattern *regexp.Regexp
@@ -15,24 +24,52 @@ type Validator struct {
 
 // NewValidator creates a new validator
 func NewValidator() *Validator {
-	return &Validator{
+	v := &Validator{
 		// Allow lowercase letters, numbers, hyphens, and dots (for versions like 4.8)
 		namePattern: regexp.MustCompile("^[a-z][a-z0-9.-]*$"),
-		validTools: map[string]bool{
-			// Core Claude Code tools
-			"Read":      true,
-			"Write":     true,
-			"Edit":      true,
-			"MultiEdit": true,
-			"Task":      true,
-			"Bash":      true,
-			"Grep":      true,
-			"Glob":      true,
-			"WebFetch":  true,
-			"WebSearch": true,
-			// Note: Custom/domain-specific tools are now allowed
-		},
 	}
+	v.SetAllowedTools(nil)
+	return v
+}
+
+// SetAllowedTools replaces the known tool names; an empty list restores
+// DefaultAllowedTools
+func (v *Validator) SetAllowedTools(tools []string) {
+	if len(tools) == 0 {
+		tools = DefaultAllowedTools
+	}
+	v.validTools = make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		v.validTools[strings.TrimSpace(tool)] = true
+	}
+}
+
+// AllowedTools returns the known tool names, sorted
+func (v *Validator) AllowedTools() []string {
+	tools := make([]string, 0, len(v.validTools))
+	for tool := range v.validTools {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// UnknownTools returns the requested tools missing from the allowed list.
+// MCP tools (mcp__server__tool) are never reported and rule specifiers such as
+// Bash(git:*) are checked by their tool name.
+func (v *Validator) UnknownTools(tools []string) []string {
+	var unknown []string
+	for _, tool := range tools {
+		name := strings.TrimSpace(tool)
+		if i := strings.Index(name, "("); i > 0 {
+			name = name[:i]
+		}
+		if name == "" || strings.HasPrefix(name, "mcp__") || v.validTools[name] {
+			continue
+		}
+		unknown = append(unknown, tool)
+	}
+	return unknown
 }
 
 // Validate checks if an agent spec is valid
@@ -119,6 +156,11 @@ func (v *Validator) ValidateWithReport(spec *parser.AgentSpec) *ValidationReport
 		report.Valid = false
 	}
 
+	// Files that are not plain UTF-8 are decoded, but other tools may misread them
+	if spec.Encoding != "" {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("Encoded as %s instead of UTF-8", spec.Encoding))
+	}
+
 	report.Coverage = float64(fieldsPresent) / float64(totalFields) * 100
 
 	return report
@@ -209,6 +209,26 @@ func TestValidateWithReport_ValidAgent(t *testing.T) {
 	}
 }
 
+// TestValidateWithReport_Encoding tests that agents decoded from another encoding are flagged
+func TestValidateWithReport_Encoding(t *testing.T) {
+	validator := NewValidator()
+
+	spec := &parser.AgentSpec{
+		Name:        "windows-agent",
+		Description: "Saved by a Windows editor",
+		Prompt:      "Prompt content.",
+		Encoding:    parser.EncodingUTF8BOM,
+	}
+
+	report := validator.ValidateWithReport(spec)
+	if !report.Valid {
+		t.Errorf("Expected the agent to stay valid, got errors: %v", report.Errors)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], parser.EncodingUTF8BOM) {
+		t.Errorf("Expected an encoding warning, got: %v", report.Warnings)
+	}
+}
+
 // TestValidateWithReport_PartialAgent tests detailed validation report for partial agent
 func TestValidateWithReport_PartialAgent(t *testing.T) {
 	validator := NewValidator()
@@ -448,3 +468,42 @@ func TestValidateWithReport_AllFieldsPopulated(t *testing.T) {
 		t.Errorf("Report.Coverage should be between 0 and 100, got %.1f", report.Coverage)
 	}
 }
+
+// TestUnknownTools tests checking tools against the allowed list
+func TestUnknownTools(t *testing.T) {
+	validator := NewValidator()
+
+	unknown := validator.UnknownTools([]string{"Read", "NotebookEdit", "TodoWrite", "Bash(git:*)", "mcp__github__create_issue", "jira"})
+	if len(unknown) != 1 || unknown[0] != "jira" {
+		t.Errorf("Expected only jira to be unknown, got %v", unknown)
+	}
+
+	validator.SetAllowedTools([]string{"Read", "jira"})
+	unknown = validator.UnknownTools([]string{"Read", "jira", "Write"})
+	if len(unknown) != 1 || unknown[0] != "Write" {
+		t.Errorf("Expected Write to be unknown with a custom list, got %v", unknown)
+	}
+
+	validator.SetAllowedTools(nil)
+	if unknown := validator.UnknownTools([]string{"Write"}); len(unknown) != 0 {
+		t.Errorf("Expected empty list to restore defaults, got %v", unknown)
+	}
+}
+
+// TestParseClaudeTools tests reading tool names from Claude Code tool schemas
+func TestParseClaudeTools(t *testing.T) {
+	content := `
+export type ToolInputSchemas = AgentInput | BashInput | FileEditInput;
+export interface AgentInput { description: string; }
+export interface BashInput { command: string; }
+export interface FileEditInput { file_path: string; }
+export interface FileReadInput { file_path: string; }
+export interface NotebookEditInput { notebook_path: string; }
+export interface BashInput { duplicate: true; }
+`
+	tools := parseClaudeTools(content)
+	expected := []string{"Bash", "Edit", "NotebookEdit", "Read", "Task"}
+	if strings.Join(tools, ",") != strings.Join(expected, ",") {
+		t.Errorf("parseClaudeTools() = %v, want %v", tools, expected)
+	}
+}
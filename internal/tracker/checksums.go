@@ -0,0 +1,151 @@
+package tracker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+// Changes to installed files found by CheckFiles
+const (
+	FileModified = "modified"
+	FileDeleted  = "deleted"
+	FileAdded    = "added"
+)
+
+// FileChange is an installed file changed outside agent-manager
+type FileChange struct {
+	Source string `json:"source"`
+	Path   string `json:"path"`
+	Change string `json:"change"`
+}
+
+// CheckFiles compares the files of the named installations, or of every
+// installation when none are named, with what was recorded when they were
+// written. Files whose checksum differs are modified, or whose size differs
+// when they were tracked without a checksum. Untracked files in the
+// directories of an installation are added; hidden files are ignored.
+// Changes are ordered by source and path.
+func (t *Tracker) CheckFiles(sources ...string) ([]FileChange, error) {
+	installations, err := t.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tracking data: %w", err)
+	}
+
+	names := append([]string(nil), sources...)
+	if len(names) == 0 {
+		for name := range installations {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	// Added files are checked against every installation, so a file another
+	// source installed in a shared directory is not reported
+	tracked := make(map[string]bool)
+	for _, installation := range installations {
+		for path := range installation.Files {
+			tracked[absolutePath(path)] = true
+		}
+		for _, doc := range installation.DocPaths() {
+			tracked[absolutePath(doc)] = true
+		}
+	}
+
+	var changes []FileChange
+	scanned := make(map[string]bool)
+	for _, name := range names {
+		installation, ok := installations[name]
+		if !ok {
+			return nil, fmt.Errorf("source not installed: %s", name)
+		}
+
+		paths := make([]string, 0, len(installation.Files))
+		for path := range installation.Files {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			if change := checkFile(installation.Files[path]); change != "" {
+				changes = append(changes, FileChange{Source: name, Path: path, Change: change})
+			}
+		}
+
+		for _, dir := range installation.Directories {
+			if scanned[absolutePath(dir)] {
+				continue
+			}
+			scanned[absolutePath(dir)] = true
+			added, err := untrackedFiles(dir, tracked)
+			if err != nil {
+				return nil, err
+			}
+			for _, path := range added {
+				changes = append(changes, FileChange{Source: name, Path: path, Change: FileAdded})
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Source != changes[j].Source {
+			return changes[i].Source < changes[j].Source
+		}
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// checkFile returns how a tracked file changed since it was written, or ""
+func checkFile(info FileInfo) string {
+	stat, err := os.Stat(info.Path)
+	if err != nil {
+		return FileDeleted
+	}
+	if info.Hash == "" {
+		if stat.Size() != info.Size {
+			return FileModified
+		}
+		return ""
+	}
+	hash, err := util.FileSHA256(info.Path)
+	if err != nil || hash != info.Hash {
+		return FileModified
+	}
+	return ""
+}
+
+// untrackedFiles returns the regular files directly in dir that are not
+// tracked; a missing directory has none
+func untrackedFiles(dir string, tracked map[string]bool) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") || !entry.Type().IsRegular() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if !tracked[absolutePath(path)] {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// absolutePath returns the absolute form of path, or path itself when it has none
+func absolutePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,103 @@
+package tracker
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pacphi/claude-code-agent-manager/internal/util"
+)
+
+func TestCheckFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	baseDir := filepath.Join(tempDir, "agents")
+	tracker := New(filepath.Join(tempDir, "tracking.json"))
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tracked := func(path string) FileInfo {
+		t.Helper()
+		hash, err := util.FileSHA256(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return FileInfo{Path: path, Hash: hash, Size: int64(len("original"))}
+	}
+
+	edited := filepath.Join(baseDir, "edited.md")
+	deleted := filepath.Join(baseDir, "deleted.md")
+	intact := filepath.Join(baseDir, "intact.md")
+	legacy := filepath.Join(baseDir, "legacy.md")
+	other := filepath.Join(baseDir, "other.md")
+	for _, path := range []string{edited, deleted, intact, legacy, other} {
+		write(path, "original")
+	}
+	files := map[string]FileInfo{
+		edited:  tracked(edited),
+		deleted: tracked(deleted),
+		intact:  tracked(intact),
+		// Tracked by an older version without a checksum
+		legacy: {Path: legacy, Size: int64(len("original"))},
+	}
+	if err := tracker.RecordInstallation("source", Installation{Files: files, Directories: []string{baseDir}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.RecordInstallation("another", Installation{Files: map[string]FileInfo{other: tracked(other)}, Directories: []string{baseDir}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if changes, err := tracker.CheckFiles(); err != nil || len(changes) != 0 {
+		t.Fatalf("CheckFiles() before any change = %v, %v", changes, err)
+	}
+
+	write(edited, "changed!") // same size, different content
+	write(legacy, "changed and longer")
+	if err := os.Remove(deleted); err != nil {
+		t.Fatal(err)
+	}
+	added := filepath.Join(baseDir, "added.md")
+	write(added, "hand-written")
+	write(filepath.Join(baseDir, ".agent-index"), "ignored")
+
+	changes, err := tracker.CheckFiles("source")
+	if err != nil {
+		t.Fatalf("CheckFiles() error = %v", err)
+	}
+	want := []FileChange{
+		{Source: "source", Path: added, Change: FileAdded},
+		{Source: "source", Path: deleted, Change: FileDeleted},
+		{Source: "source", Path: edited, Change: FileModified},
+		{Source: "source", Path: legacy, Change: FileModified},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("CheckFiles() = %+v, want %+v", changes, want)
+	}
+
+	// Edits made by agent-manager are recorded and no longer reported
+	if err := tracker.RefreshFiles([]string{edited, legacy, added}); err != nil {
+		t.Fatalf("RefreshFiles() error = %v", err)
+	}
+	changes, err = tracker.CheckFiles("source")
+	if err != nil {
+		t.Fatalf("CheckFiles() error = %v", err)
+	}
+	want = []FileChange{
+		{Source: "source", Path: added, Change: FileAdded},
+		{Source: "source", Path: deleted, Change: FileDeleted},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("CheckFiles() after refresh = %+v, want %+v", changes, want)
+	}
+
+	if _, err := tracker.CheckFiles("missing"); err == nil {
+		t.Error("Expected an error for a source that is not installed")
+	}
+}
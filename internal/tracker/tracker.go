@@ -1,10 +1,17 @@
 package tracker
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,28 +21,97 @@ import (
 // Tracker manages installation tracking
 type Tracker struct {
 	filePath string
+	key      []byte // signs written entries and verifies loaded ones when set
 	mu       sync.RWMutex
 }
 
+// Integrity states of a tracked installation in a signed tracker
+const (
+	IntegrityValid    = "valid"
+	IntegrityUnsigned = "unsigned"
+	IntegrityModified = "modified"
+)
+
+// signingKeySize is the size of generated tracker signing keys
+const signingKeySize = 32
+
+// IntegrityIssue is a tracked installation whose signature does not verify
+type IntegrityIssue struct {
+	Source  string
+	Problem string // IntegrityUnsigned or IntegrityModified
+}
+
 // Installation represents an installed source
 type Installation struct {
-	Timestamp     time.Time           `json:"timestamp"`
-	SourceCommit  string              `json:"source_commit,omitempty"`
-	Files         map[string]FileInfo `json:"files"`
-	Directories   []string            `json:"directories"`
-	DocsGenerated []string            `json:"docs_generated,omitempty"`
-	AgentMetadata []AgentInfo         `json:"agent_metadata,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+	SourceCommit string    `json:"source_commit,omitempty"`
+	// ConfigHash is the config.SourceHash of the configuration the source was installed with
+	ConfigHash string `json:"config_hash,omitempty"`
+	// LastChecked is when update last checked the source for changes
+	LastChecked time.Time           `json:"last_checked,omitempty"`
+	Files       map[string]FileInfo `json:"files"`
+	Directories []string            `json:"directories"`
+	// Docs are the documentation files written by extract_docs transformations
+	Docs map[string]DocInfo `json:"docs,omitempty"`
+	// DocsGenerated lists docs tracked by older versions, without checksums
+	DocsGenerated []string    `json:"docs_generated,omitempty"`
+	AgentMetadata []AgentInfo `json:"agent_metadata,omitempty"`
+
+	// Mirror is the mirror URL the source was fetched from when its primary location failed
+	Mirror string `json:"mirror,omitempty"`
+
+	// Categories tracks marketplace categories installed as part of this source
+	Categories map[string]*CategoryInstallation `json:"categories,omitempty"`
+
+	// Signature is the HMAC-SHA256 of the entry written by a signed tracker
+	Signature string `json:"signature,omitempty"`
+
+	// integrity is the verification result of an entry loaded by a signed
+	// tracker; entries that did not verify keep their signature when saved
+	integrity string
+}
+
+// CategoryInstallation represents a marketplace category installed within a source
+type CategoryInstallation struct {
+	Timestamp    time.Time `json:"timestamp"`
+	SourceCommit string    `json:"source_commit,omitempty"`
+	Files        []string  `json:"files"`
 }
 
 // FileInfo contains information about an installed file
 type FileInfo struct {
-	Path           string    `json:"path"`
+	Path string `json:"path"`
+	// Hash is the sha256 of the content written; files tracked by older
+	// versions have none
 	Hash           string    `json:"hash,omitempty"`
 	Size           int64     `json:"size"`
 	Modified       time.Time `json:"modified"`
 	WasPreExisting bool      `json:"was_pre_existing,omitempty"`
 }
 
+// DocInfo records a documentation file generated for a source
+type DocInfo struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"` // sha256 of the content written
+	Size int64  `json:"size"`
+}
+
+// DocPaths returns the docs generated for the installation, including docs
+// tracked by older versions, in order
+func (inst *Installation) DocPaths() []string {
+	paths := make([]string, 0, len(inst.Docs)+len(inst.DocsGenerated))
+	for path := range inst.Docs {
+		paths = append(paths, path)
+	}
+	for _, path := range inst.DocsGenerated {
+		if _, ok := inst.Docs[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 // AgentInfo contains metadata about an installed agent
 type AgentInfo struct {
 	Name           string    `json:"name"`
@@ -46,6 +122,7 @@ type AgentInfo struct {
 	FileName       string    `json:"file_name"`
 	FileSize       int64     `json:"file_size"`
 	ModTime        time.Time `json:"mod_time"`
+	Namespace      string    `json:"namespace,omitempty"`
 	Source         string    `json:"source"`
 	InstalledAt    time.Time `json:"installed_at"`
 }
@@ -64,6 +141,132 @@ func New(filePath string) *Tracker {
 	}
 }
 
+// NewSigned creates a tracker that signs the entries it writes with key and
+// verifies the entries it loads. Entries that are unsigned or fail to verify
+// are never re-signed implicitly, so tampering stays detectable until the
+// source is reinstalled or the entry is accepted with Resign.
+func NewSigned(filePath string, key []byte) *Tracker {
+	return &Tracker{
+		filePath: filePath,
+		key:      key,
+	}
+}
+
+// LoadSigningKey reads the hex encoded tracker signing key at path, creating
+// a random key readable only by the user when the file does not exist
+func LoadSigningKey(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		key := make([]byte, signingKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, fmt.Errorf("failed to create signing key directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+			return nil, fmt.Errorf("failed to write signing key: %w", err)
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key %s: %w", path, err)
+	}
+	if len(key) < signingKeySize {
+		return nil, fmt.Errorf("invalid signing key %s: must be at least %d bytes", path, signingKeySize)
+	}
+	return key, nil
+}
+
+// Signed reports whether the tracker signs and verifies its entries
+func (t *Tracker) Signed() bool {
+	return t.key != nil
+}
+
+// Verify returns the installations whose signature is missing or does not
+// match their content, ordered by source; an unsigned tracker reports none
+func (t *Tracker) Verify() ([]IntegrityIssue, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.key == nil {
+		return nil, nil
+	}
+	data, err := t.load()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load tracking data: %w", err)
+	}
+
+	var issues []IntegrityIssue
+	for name, installation := range data.Installations {
+		if installation.integrity != IntegrityValid {
+			issues = append(issues, IntegrityIssue{Source: name, Problem: installation.integrity})
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Source < issues[j].Source })
+	return issues, nil
+}
+
+// Resign signs the installations of sources as they are now, accepting any
+// changes made to them outside agent-manager
+func (t *Tracker) Resign(sources []string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.key == nil {
+		return fmt.Errorf("tracker signing is not enabled")
+	}
+	data, err := t.load()
+	if err != nil {
+		return fmt.Errorf("failed to load tracking data: %w", err)
+	}
+	for _, name := range sources {
+		installation, exists := data.Installations[name]
+		if !exists {
+			return fmt.Errorf("installation not found: %s", name)
+		}
+		installation.integrity = ""
+	}
+	return t.save(data)
+}
+
+// sign returns the signature of the installation of sourceName; the name is
+// signed along with the entry so entries cannot be swapped between sources
+func (t *Tracker) sign(sourceName string, installation *Installation) (string, error) {
+	unsigned := *installation
+	unsigned.Signature = ""
+	payload, err := json.Marshal(&unsigned)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, t.key)
+	mac.Write([]byte(sourceName))
+	mac.Write([]byte{0})
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// verify returns the integrity state of the installation of sourceName
+func (t *Tracker) verify(sourceName string, installation *Installation) string {
+	if installation.Signature == "" {
+		return IntegrityUnsigned
+	}
+	want, err := t.sign(sourceName, installation)
+	if err != nil || !hmac.Equal([]byte(want), []byte(installation.Signature)) {
+		return IntegrityModified
+	}
+	return IntegrityValid
+}
+
 // RecordInstallation records a new installation
 func (t *Tracker) RecordInstallation(sourceName string, installation Installation) error {
 	t.mu.Lock()
@@ -92,6 +295,25 @@ func (t *Tracker) RecordInstallation(sourceName string, installation Installatio
 	return t.save(data)
 }
 
+// MarkChecked records that the installed source was checked for updates at
+func (t *Tracker) MarkChecked(sourceName string, at time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := t.load()
+	if err != nil {
+		return fmt.Errorf("failed to load tracking data: %w", err)
+	}
+	installation, exists := data.Installations[sourceName]
+	if !exists {
+		return fmt.Errorf("installation not found: %s", sourceName)
+	}
+
+	installation.LastChecked = at
+	data.LastUpdated = time.Now()
+	return t.save(data)
+}
+
 // GetInstallation retrieves installation information for a source
 func (t *Tracker) GetInstallation(sourceName string) (*Installation, error) {
 	t.mu.RLock()
@@ -198,8 +420,549 @@ func (t *Tracker) UpdateFile(sourceName, filePath string, info FileInfo) error {
 	return t.save(data)
 }
 
+// RenameFile moves a tracked file entry to a new path and updates the matching
+// agent metadata. It returns the owning source name, or an empty string if the
+// file is not tracked by any installation.
+func (t *Tracker) RenameFile(oldPath, newPath, newName string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := t.load()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to load tracking data: %w", err)
+	}
+
+	for sourceName, installation := range data.Installations {
+		trackedPath, found := findTrackedPath(installation.Files, oldPath)
+		if !found {
+			continue
+		}
+
+		info := installation.Files[trackedPath]
+		delete(installation.Files, trackedPath)
+
+		// Keep the same path style (relative or absolute) the source was installed with
+		renamedPath := filepath.Join(filepath.Dir(trackedPath), filepath.Base(newPath))
+		info.Path = renamedPath
+		refreshFileInfo(&info)
+		installation.Files[renamedPath] = info
+
+		absOld, _ := filepath.Abs(oldPath)
+		for idx := range installation.AgentMetadata {
+			agent := &installation.AgentMetadata[idx]
+			if describesFile(*agent, absOld) {
+				agent.Name = newName
+				agent.FileName = filepath.Base(newPath)
+				agent.FilePath = filepath.Join(filepath.Dir(agent.FilePath), agent.FileName)
+			}
+		}
+
+		data.LastUpdated = time.Now()
+		if err := t.save(data); err != nil {
+			return "", err
+		}
+		return sourceName, nil
+	}
+
+	return "", nil
+}
+
+// RefreshFiles records the current size, modification time and checksum of
+// the tracked files among paths, after agent-manager changed their content.
+// Untracked paths are ignored.
+func (t *Tracker) RefreshFiles(paths []string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := t.load()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to load tracking data: %w", err)
+	}
+
+	refreshed := false
+	for _, path := range paths {
+		for _, installation := range data.Installations {
+			trackedPath, found := findTrackedPath(installation.Files, path)
+			if !found {
+				continue
+			}
+			info := installation.Files[trackedPath]
+			refreshFileInfo(&info)
+			installation.Files[trackedPath] = info
+			refreshed = true
+		}
+	}
+	if !refreshed {
+		return nil
+	}
+
+	data.LastUpdated = time.Now()
+	return t.save(data)
+}
+
+// refreshFileInfo updates a tracked file's size, modification time and
+// checksum from disk, leaving it alone when the file cannot be read
+func refreshFileInfo(info *FileInfo) {
+	stat, err := os.Stat(info.Path)
+	if err != nil {
+		return
+	}
+	hash, err := util.FileSHA256(info.Path)
+	if err != nil {
+		return
+	}
+	info.Size, info.Modified, info.Hash = stat.Size(), stat.ModTime(), hash
+}
+
+// FindFile returns the source tracking the file at path and its tracking entry.
+// The source name is empty if no installation tracks the file.
+func (t *Tracker) FindFile(path string) (string, FileInfo, error) {
+	installations, err := t.List()
+	if err != nil {
+		return "", FileInfo{}, fmt.Errorf("failed to load tracking data: %w", err)
+	}
+	for sourceName, installation := range installations {
+		if trackedPath, found := findTrackedPath(installation.Files, path); found {
+			return sourceName, installation.Files[trackedPath], nil
+		}
+	}
+	return "", FileInfo{}, nil
+}
+
+// DocOwners returns the sources whose installations generated the doc at
+// path, in order
+func (t *Tracker) DocOwners(path string) ([]string, error) {
+	installations, err := t.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tracking data: %w", err)
+	}
+
+	absPath, _ := filepath.Abs(path)
+	var owners []string
+	for sourceName, installation := range installations {
+		for _, doc := range installation.DocPaths() {
+			if absDoc, _ := filepath.Abs(doc); doc == path || absDoc == absPath {
+				owners = append(owners, sourceName)
+				break
+			}
+		}
+	}
+	sort.Strings(owners)
+	return owners, nil
+}
+
+// RemoveFile stops tracking a single file, dropping it from its installation,
+// any category listing it, and the agent metadata. It returns the owning
+// source and the removed entry; the source name is empty if the file is not
+// tracked by any installation.
+func (t *Tracker) RemoveFile(path string) (string, FileInfo, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := t.load()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", FileInfo{}, nil
+		}
+		return "", FileInfo{}, fmt.Errorf("failed to load tracking data: %w", err)
+	}
+
+	for sourceName, installation := range data.Installations {
+		trackedPath, found := findTrackedPath(installation.Files, path)
+		if !found {
+			continue
+		}
+
+		info := installation.Files[trackedPath]
+		delete(installation.Files, trackedPath)
+
+		for _, category := range installation.Categories {
+			files := category.Files[:0]
+			for _, file := range category.Files {
+				if file != trackedPath {
+					files = append(files, file)
+				}
+			}
+			category.Files = files
+		}
+
+		absPath, _ := filepath.Abs(path)
+		metadata := installation.AgentMetadata[:0]
+		for _, agent := range installation.AgentMetadata {
+			if describesFile(agent, absPath) {
+				continue
+			}
+			metadata = append(metadata, agent)
+		}
+		installation.AgentMetadata = metadata
+
+		data.LastUpdated = time.Now()
+		if err := t.save(data); err != nil {
+			return "", FileInfo{}, err
+		}
+		return sourceName, info, nil
+	}
+
+	return "", FileInfo{}, nil
+}
+
+// ManualSource is the synthetic source that adopted hand-written files are tracked under
+const ManualSource = "manual"
+
+// MarketplaceSource is the synthetic source that agents installed one at a
+// time from the marketplace are tracked under
+const MarketplaceSource = "marketplace"
+
+// Orphans returns the files under baseDir that no installation tracks, such as
+// hand-written agents or leftovers from removed sources. Hidden files and
+// directories, which hold the index and cache, are ignored.
+func (t *Tracker) Orphans(baseDir string) ([]string, error) {
+	installations, err := t.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tracking data: %w", err)
+	}
+
+	tracked := make(map[string]bool)
+	for _, installation := range installations {
+		for path := range installation.Files {
+			if absPath, err := filepath.Abs(path); err == nil {
+				tracked[absPath] = true
+			}
+		}
+	}
+
+	var orphans []string
+	err = filepath.WalkDir(baseDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == baseDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if path != baseDir && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		if absPath, err := filepath.Abs(path); err == nil && !tracked[absPath] {
+			orphans = append(orphans, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", baseDir, err)
+	}
+
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+// AdoptFiles starts tracking untracked files under sourceName, creating the
+// installation if needed, so they are managed like installed files
+func (t *Tracker) AdoptFiles(sourceName string, paths []string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := t.load()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to load tracking data: %w", err)
+		}
+		data = &TrackingData{
+			Version:       "1.0",
+			Installations: make(map[string]*Installation),
+		}
+	}
+
+	installation, exists := data.Installations[sourceName]
+	if !exists {
+		installation = &Installation{
+			Timestamp:   time.Now(),
+			Files:       make(map[string]FileInfo),
+			Directories: []string{},
+		}
+		data.Installations[sourceName] = installation
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		hash, err := util.FileSHA256(path)
+		if err != nil {
+			return err
+		}
+		installation.Files[path] = FileInfo{
+			Path:           path,
+			Hash:           hash,
+			Size:           info.Size(),
+			Modified:       info.ModTime(),
+			WasPreExisting: true,
+		}
+		if dir := filepath.Dir(path); !containsPath(installation.Directories, dir) {
+			installation.Directories = append(installation.Directories, dir)
+		}
+	}
+
+	data.LastUpdated = time.Now()
+	return t.save(data)
+}
+
+// AddFile tracks a single file under sourceName, creating the installation
+// if needed
+func (t *Tracker) AddFile(sourceName string, file FileInfo) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := t.load()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to load tracking data: %w", err)
+		}
+		data = &TrackingData{
+			Version:       "1.0",
+			Installations: make(map[string]*Installation),
+		}
+	}
+
+	installation, exists := data.Installations[sourceName]
+	if !exists {
+		installation = &Installation{
+			Timestamp:   time.Now(),
+			Files:       make(map[string]FileInfo),
+			Directories: []string{},
+		}
+		data.Installations[sourceName] = installation
+	}
+
+	installation.Files[file.Path] = file
+	if dir := filepath.Dir(file.Path); !containsPath(installation.Directories, dir) {
+		installation.Directories = append(installation.Directories, dir)
+	}
+
+	data.LastUpdated = time.Now()
+	return t.save(data)
+}
+
+// RemoveCategory removes a category sub-installation from a source along with
+// its files and agent metadata, returning the removed category
+func (t *Tracker) RemoveCategory(sourceName, category string) (*CategoryInstallation, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := t.load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tracking data: %w", err)
+	}
+
+	installation, exists := data.Installations[sourceName]
+	if !exists {
+		return nil, fmt.Errorf("installation not found: %s", sourceName)
+	}
+
+	removed, exists := installation.Categories[category]
+	if !exists {
+		return nil, fmt.Errorf("category not found: %s", SubSourceName(sourceName, category))
+	}
+
+	fileNames := make(map[string]bool, len(removed.Files))
+	for _, path := range removed.Files {
+		delete(installation.Files, path)
+		fileNames[filepath.Base(path)] = true
+	}
+
+	metadata := installation.AgentMetadata[:0]
+	for _, agent := range installation.AgentMetadata {
+		if !fileNames[agent.FileName] {
+			metadata = append(metadata, agent)
+		}
+	}
+	installation.AgentMetadata = metadata
+
+	delete(installation.Categories, category)
+	data.LastUpdated = time.Now()
+
+	if err := t.save(data); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// RecordCategory records a category sub-installation within an existing source,
+// merging its files and agent metadata into the source installation
+func (t *Tracker) RecordCategory(sourceName, category string, installation Installation) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := t.load()
+	if err != nil {
+		return fmt.Errorf("failed to load tracking data: %w", err)
+	}
+
+	parent, exists := data.Installations[sourceName]
+	if !exists {
+		return fmt.Errorf("installation not found: %s", sourceName)
+	}
+
+	if parent.Files == nil {
+		parent.Files = make(map[string]FileInfo)
+	}
+	if parent.Categories == nil {
+		parent.Categories = make(map[string]*CategoryInstallation)
+	}
+
+	entry := &CategoryInstallation{
+		Timestamp:    time.Now(),
+		SourceCommit: installation.SourceCommit,
+		Files:        make([]string, 0, len(installation.Files)),
+	}
+	for path, info := range installation.Files {
+		parent.Files[path] = info
+		entry.Files = append(entry.Files, path)
+	}
+	sort.Strings(entry.Files)
+
+	for _, dir := range installation.Directories {
+		if !containsPath(parent.Directories, dir) {
+			parent.Directories = append(parent.Directories, dir)
+		}
+	}
+	parent.AgentMetadata = append(parent.AgentMetadata, installation.AgentMetadata...)
+	parent.Categories[category] = entry
+	data.LastUpdated = time.Now()
+
+	return t.save(data)
+}
+
+// MergeInstallation records part of a source installation, such as the
+// agents of a collection, merging its files, directories, docs and agent
+// metadata into the installation of sourceName, which is created when missing
+func (t *Tracker) MergeInstallation(sourceName string, installation Installation) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := t.load()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to load tracking data: %w", err)
+		}
+		data = &TrackingData{
+			Version:       "1.0",
+			Installations: make(map[string]*Installation),
+		}
+	}
+
+	parent, exists := data.Installations[sourceName]
+	if !exists {
+		installation.Timestamp = time.Now()
+		data.Installations[sourceName] = &installation
+		data.LastUpdated = time.Now()
+		return t.save(data)
+	}
+
+	if parent.Files == nil {
+		parent.Files = make(map[string]FileInfo)
+	}
+	for path, info := range installation.Files {
+		parent.Files[path] = info
+	}
+	for _, dir := range installation.Directories {
+		if !containsPath(parent.Directories, dir) {
+			parent.Directories = append(parent.Directories, dir)
+		}
+	}
+	if len(installation.Docs) > 0 && parent.Docs == nil {
+		parent.Docs = make(map[string]DocInfo)
+	}
+	for path, info := range installation.Docs {
+		parent.Docs[path] = info
+	}
+
+	// Agent metadata of reinstalled files replaces the previous entries
+	replaced := make(map[string]bool, len(installation.AgentMetadata))
+	for _, agent := range installation.AgentMetadata {
+		replaced[agent.Namespace+"/"+agent.FileName] = true
+	}
+	merged := make([]AgentInfo, 0, len(parent.AgentMetadata)+len(installation.AgentMetadata))
+	for _, agent := range parent.AgentMetadata {
+		if !replaced[agent.Namespace+"/"+agent.FileName] {
+			merged = append(merged, agent)
+		}
+	}
+	parent.AgentMetadata = append(merged, installation.AgentMetadata...)
+
+	parent.SourceCommit = installation.SourceCommit
+	parent.Timestamp = time.Now()
+	data.LastUpdated = time.Now()
+	return t.save(data)
+}
+
+// SubSourceName returns the name used to address a category within a source
+func SubSourceName(sourceName, category string) string {
+	return sourceName + "/" + category
+}
+
+// SplitSubSource splits a "source/category" name into its parts; category is
+// empty when name does not address a category
+func SplitSubSource(name string) (string, string) {
+	idx := strings.LastIndex(name, "/")
+	if idx <= 0 || idx == len(name)-1 {
+		return name, ""
+	}
+	return name[:idx], name[idx+1:]
+}
+
 // Private methods
 
+// containsPath reports whether paths contains path
+func containsPath(paths []string, path string) bool {
+	for _, existing := range paths {
+		if existing == path {
+			return true
+		}
+	}
+	return false
+}
+
+// describesFile reports whether agent metadata describes the file at absPath:
+// by its full path, or by file name for metadata recorded without a path
+func describesFile(agent AgentInfo, absPath string) bool {
+	if agent.FilePath == "" {
+		return agent.FileName == filepath.Base(absPath)
+	}
+	agentPath, _ := filepath.Abs(agent.FilePath)
+	return agentPath == absPath
+}
+
+// findTrackedPath finds the tracked key referring to the same file as path
+func findTrackedPath(files map[string]FileInfo, path string) (string, bool) {
+	if _, ok := files[path]; ok {
+		return path, true
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	for tracked := range files {
+		if absTracked, err := filepath.Abs(tracked); err == nil && absTracked == absPath {
+			return tracked, true
+		}
+	}
+	return "", false
+}
+
 func (t *Tracker) load() (*TrackingData, error) {
 	// Check if file exists
 	if _, err := os.Stat(t.filePath); os.IsNotExist(err) {
@@ -223,10 +986,30 @@ func (t *Tracker) load() (*TrackingData, error) {
 		data.Installations = make(map[string]*Installation)
 	}
 
+	if t.key != nil {
+		for name, installation := range data.Installations {
+			installation.integrity = t.verify(name, installation)
+		}
+	}
+
 	return &data, nil
 }
 
 func (t *Tracker) save(data *TrackingData) error {
+	// Sign new and verified entries, leaving the others flagged
+	if t.key != nil {
+		for name, installation := range data.Installations {
+			if installation.integrity != "" && installation.integrity != IntegrityValid {
+				continue
+			}
+			signature, err := t.sign(name, installation)
+			if err != nil {
+				return fmt.Errorf("failed to sign installation %s: %w", name, err)
+			}
+			installation.Signature = signature
+		}
+	}
+
 	// Ensure parent directory exists
 	dir := filepath.Dir(t.filePath)
 	if err := os.MkdirAll(dir, 0750); err != nil {
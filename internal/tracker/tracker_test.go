@@ -3,6 +3,7 @@ package tracker
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -142,3 +143,381 @@ func TestList(t *testing.T) {
 		t.Errorf("Expected %d installations, got %d", len(sources), len(installations))
 	}
 }
+
+func TestRenameFile(t *testing.T) {
+	tempDir := t.TempDir()
+	tracker := New(filepath.Join(tempDir, "tracking.json"))
+
+	oldPath := filepath.Join(tempDir, "agents", "old-agent.md")
+	newPath := filepath.Join(tempDir, "agents", "new-agent.md")
+	namesake := filepath.Join(tempDir, "agents", "team", "old-agent.md")
+
+	installation := Installation{
+		Files: map[string]FileInfo{
+			oldPath:  {Path: oldPath, Size: 10},
+			namesake: {Path: namesake, Size: 10},
+		},
+		AgentMetadata: []AgentInfo{
+			{Name: "old-agent", FileName: "old-agent.md", FilePath: oldPath},
+			{Name: "old-agent", FileName: "old-agent.md", FilePath: namesake, Namespace: "team"},
+		},
+	}
+	if err := tracker.RecordInstallation("test-source", installation); err != nil {
+		t.Fatalf("RecordInstallation() error = %v", err)
+	}
+
+	sourceName, err := tracker.RenameFile(oldPath, newPath, "new-agent")
+	if err != nil {
+		t.Fatalf("RenameFile() error = %v", err)
+	}
+	if sourceName != "test-source" {
+		t.Errorf("Expected owning source test-source, got %q", sourceName)
+	}
+
+	retrieved, err := tracker.GetInstallation("test-source")
+	if err != nil {
+		t.Fatalf("GetInstallation() error = %v", err)
+	}
+	if _, exists := retrieved.Files[oldPath]; exists {
+		t.Error("Old path should no longer be tracked")
+	}
+	if info, exists := retrieved.Files[newPath]; !exists || info.Path != newPath {
+		t.Errorf("Expected new path to be tracked, got %+v", retrieved.Files)
+	}
+	if agent := retrieved.AgentMetadata[0]; agent.Name != "new-agent" || agent.FileName != "new-agent.md" {
+		t.Errorf("Expected agent metadata to be renamed, got %+v", agent)
+	}
+	if agent := retrieved.AgentMetadata[1]; agent.Name != "old-agent" || agent.FilePath != namesake {
+		t.Errorf("Expected the agent of the same name in another directory untouched, got %+v", agent)
+	}
+
+	sourceName, err = tracker.RenameFile(filepath.Join(tempDir, "untracked.md"), newPath, "x")
+	if err != nil || sourceName != "" {
+		t.Errorf("Expected untracked file to be ignored, got %q, %v", sourceName, err)
+	}
+}
+
+func TestRemoveFile(t *testing.T) {
+	tempDir := t.TempDir()
+	tracker := New(filepath.Join(tempDir, "tracking.json"))
+
+	reviewer := filepath.Join(tempDir, "agents", "reviewer.md")
+	teamReviewer := filepath.Join(tempDir, "agents", "team", "reviewer.md")
+
+	installation := Installation{
+		Files: map[string]FileInfo{
+			reviewer:     {Path: reviewer, Size: 10},
+			teamReviewer: {Path: teamReviewer, Size: 20, WasPreExisting: true},
+		},
+		AgentMetadata: []AgentInfo{
+			{Name: "reviewer", FileName: "reviewer.md", FilePath: reviewer},
+			{Name: "reviewer", FileName: "reviewer.md", FilePath: teamReviewer, Namespace: "team"},
+		},
+		Categories: map[string]*CategoryInstallation{
+			"review": {Files: []string{reviewer, teamReviewer}},
+		},
+	}
+	if err := tracker.RecordInstallation("test-source", installation); err != nil {
+		t.Fatalf("RecordInstallation() error = %v", err)
+	}
+
+	sourceName, info, err := tracker.FindFile(teamReviewer)
+	if err != nil || sourceName != "test-source" || !info.WasPreExisting {
+		t.Errorf("FindFile() = %q, %+v, %v", sourceName, info, err)
+	}
+
+	sourceName, info, err = tracker.RemoveFile(teamReviewer)
+	if err != nil {
+		t.Fatalf("RemoveFile() error = %v", err)
+	}
+	if sourceName != "test-source" || info.Size != 20 {
+		t.Errorf("RemoveFile() = %q, %+v", sourceName, info)
+	}
+
+	retrieved, err := tracker.GetInstallation("test-source")
+	if err != nil {
+		t.Fatalf("GetInstallation() error = %v", err)
+	}
+	if _, exists := retrieved.Files[teamReviewer]; exists || len(retrieved.Files) != 1 {
+		t.Errorf("Expected only %s to be tracked, got %+v", reviewer, retrieved.Files)
+	}
+	if len(retrieved.AgentMetadata) != 1 || retrieved.AgentMetadata[0].FilePath != reviewer {
+		t.Errorf("Expected the namespaced agent metadata to be dropped, got %+v", retrieved.AgentMetadata)
+	}
+	if files := retrieved.Categories["review"].Files; len(files) != 1 || files[0] != reviewer {
+		t.Errorf("Expected the category to list only %s, got %v", reviewer, files)
+	}
+
+	sourceName, _, err = tracker.RemoveFile(teamReviewer)
+	if err != nil || sourceName != "" {
+		t.Errorf("Expected an untracked file to be ignored, got %q, %v", sourceName, err)
+	}
+}
+
+func TestCategories(t *testing.T) {
+	tempDir := t.TempDir()
+	tracker := New(filepath.Join(tempDir, "tracking.json"))
+
+	devPath := filepath.Join(tempDir, "agents", "go-expert.md")
+	opsPath := filepath.Join(tempDir, "agents", "k8s-helper.md")
+
+	installation := Installation{
+		Files: map[string]FileInfo{
+			devPath: {Path: devPath},
+			opsPath: {Path: opsPath},
+		},
+		AgentMetadata: []AgentInfo{
+			{Name: "go-expert", FileName: "go-expert.md"},
+			{Name: "k8s-helper", FileName: "k8s-helper.md"},
+		},
+		Categories: map[string]*CategoryInstallation{
+			"development": {SourceCommit: "v1", Files: []string{devPath}},
+			"devops":      {SourceCommit: "v1", Files: []string{opsPath}},
+		},
+	}
+	if err := tracker.RecordInstallation("market", installation); err != nil {
+		t.Fatalf("RecordInstallation() error = %v", err)
+	}
+
+	removed, err := tracker.RemoveCategory("market", "devops")
+	if err != nil {
+		t.Fatalf("RemoveCategory() error = %v", err)
+	}
+	if len(removed.Files) != 1 || removed.Files[0] != opsPath {
+		t.Errorf("Expected removed category files [%s], got %v", opsPath, removed.Files)
+	}
+
+	retrieved, err := tracker.GetInstallation("market")
+	if err != nil {
+		t.Fatalf("GetInstallation() error = %v", err)
+	}
+	if _, exists := retrieved.Files[opsPath]; exists {
+		t.Error("Removed category file should no longer be tracked")
+	}
+	if len(retrieved.AgentMetadata) != 1 || retrieved.AgentMetadata[0].Name != "go-expert" {
+		t.Errorf("Expected only go-expert metadata to remain, got %+v", retrieved.AgentMetadata)
+	}
+
+	err = tracker.RecordCategory("market", "devops", Installation{
+		SourceCommit: "v2",
+		Files:        map[string]FileInfo{opsPath: {Path: opsPath}},
+	})
+	if err != nil {
+		t.Fatalf("RecordCategory() error = %v", err)
+	}
+
+	retrieved, err = tracker.GetInstallation("market")
+	if err != nil {
+		t.Fatalf("GetInstallation() error = %v", err)
+	}
+	if category := retrieved.Categories["devops"]; category == nil || category.SourceCommit != "v2" {
+		t.Errorf("Expected devops category at v2, got %+v", category)
+	}
+	if len(retrieved.Files) != 2 {
+		t.Errorf("Expected 2 tracked files, got %d", len(retrieved.Files))
+	}
+
+	if _, err := tracker.RemoveCategory("market", "missing"); err == nil {
+		t.Error("Expected error removing unknown category")
+	}
+}
+
+func TestMergeInstallation(t *testing.T) {
+	tempDir := t.TempDir()
+	tracker := New(filepath.Join(tempDir, "tracking.json"))
+
+	reviewer := filepath.Join(tempDir, "agents", "code-reviewer.md")
+	writer := filepath.Join(tempDir, "agents", "writer.md")
+
+	// Merging into a source that is not installed records it
+	if err := tracker.MergeInstallation("team", Installation{
+		SourceCommit:  "v1",
+		Files:         map[string]FileInfo{reviewer: {Path: reviewer, Size: 1}},
+		Directories:   []string{filepath.Dir(reviewer)},
+		AgentMetadata: []AgentInfo{{Name: "code-reviewer", FileName: "code-reviewer.md", Description: "old"}},
+	}); err != nil {
+		t.Fatalf("MergeInstallation() error = %v", err)
+	}
+
+	if err := tracker.MergeInstallation("team", Installation{
+		SourceCommit:  "v2",
+		Files:         map[string]FileInfo{writer: {Path: writer}, reviewer: {Path: reviewer, Size: 2}},
+		Directories:   []string{filepath.Dir(writer)},
+		AgentMetadata: []AgentInfo{{Name: "writer", FileName: "writer.md"}, {Name: "code-reviewer", FileName: "code-reviewer.md", Description: "new"}},
+	}); err != nil {
+		t.Fatalf("MergeInstallation() error = %v", err)
+	}
+
+	installation, err := tracker.GetInstallation("team")
+	if err != nil {
+		t.Fatalf("GetInstallation() error = %v", err)
+	}
+	if len(installation.Files) != 2 || installation.Files[reviewer].Size != 2 {
+		t.Errorf("Expected both files with the reviewer replaced, got %+v", installation.Files)
+	}
+	if len(installation.Directories) != 1 {
+		t.Errorf("Expected the shared directory once, got %v", installation.Directories)
+	}
+	if len(installation.AgentMetadata) != 2 {
+		t.Fatalf("Expected one metadata entry per agent, got %+v", installation.AgentMetadata)
+	}
+	for _, agent := range installation.AgentMetadata {
+		if agent.Name == "code-reviewer" && agent.Description != "new" {
+			t.Errorf("Expected the reviewer metadata to be replaced, got %+v", agent)
+		}
+	}
+	if installation.SourceCommit != "v2" {
+		t.Errorf("Expected the merged commit, got %s", installation.SourceCommit)
+	}
+}
+
+func TestOrphansAndAdoptFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	baseDir := filepath.Join(tempDir, "agents")
+	tracker := New(filepath.Join(tempDir, "tracking.json"))
+
+	// A missing base directory has no orphans
+	orphans, err := tracker.Orphans(baseDir)
+	if err != nil || len(orphans) != 0 {
+		t.Fatalf("Orphans() on missing dir = %v, %v", orphans, err)
+	}
+
+	installed := filepath.Join(baseDir, "installed.md")
+	handWritten := filepath.Join(baseDir, "team", "custom.md")
+	for _, path := range []string{installed, handWritten, filepath.Join(baseDir, ".agent-index"), filepath.Join(baseDir, ".agent-cache", "entry")} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("content"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tracker.RecordInstallation("source", Installation{Files: map[string]FileInfo{installed: {Path: installed}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	orphans, err = tracker.Orphans(baseDir)
+	if err != nil {
+		t.Fatalf("Orphans() error = %v", err)
+	}
+	if len(orphans) != 1 || orphans[0] != handWritten {
+		t.Fatalf("Orphans() = %v, want [%s]", orphans, handWritten)
+	}
+
+	if err := tracker.AdoptFiles(ManualSource, orphans); err != nil {
+		t.Fatalf("AdoptFiles() error = %v", err)
+	}
+	manual, err := tracker.GetInstallation(ManualSource)
+	if err != nil {
+		t.Fatalf("GetInstallation(manual) error = %v", err)
+	}
+	if info, ok := manual.Files[handWritten]; !ok || !info.WasPreExisting || info.Size != int64(len("content")) {
+		t.Errorf("Adopted file not tracked as expected: %+v", manual.Files)
+	}
+
+	orphans, err = tracker.Orphans(baseDir)
+	if err != nil || len(orphans) != 0 {
+		t.Errorf("Orphans() after adopt = %v, %v", orphans, err)
+	}
+}
+
+func TestSplitSubSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		category string
+	}{
+		{"market/devops", "market", "devops"},
+		{"market", "market", ""},
+		{"market/", "market/", ""},
+		{"/devops", "/devops", ""},
+	}
+
+	for _, tt := range tests {
+		source, category := SplitSubSource(tt.name)
+		if source != tt.source || category != tt.category {
+			t.Errorf("SplitSubSource(%q) = %q, %q; want %q, %q", tt.name, source, category, tt.source, tt.category)
+		}
+	}
+
+	if got := SubSourceName("market", "devops"); got != "market/devops" {
+		t.Errorf("SubSourceName() = %q", got)
+	}
+}
+
+func TestSignedTracker(t *testing.T) {
+	dir := t.TempDir()
+	trackingFile := filepath.Join(dir, "tracking.json")
+
+	// Entries recorded before signing was enabled stay readable
+	if err := New(trackingFile).RecordInstallation("legacy", Installation{SourceCommit: "old"}); err != nil {
+		t.Fatalf("RecordInstallation failed: %v", err)
+	}
+
+	keyFile := filepath.Join(dir, "keys", "tracker.key")
+	key, err := LoadSigningKey(keyFile)
+	if err != nil {
+		t.Fatalf("LoadSigningKey failed: %v", err)
+	}
+	if info, err := os.Stat(keyFile); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("Expected a key readable only by the user, got %v, %v", info, err)
+	}
+	if again, err := LoadSigningKey(keyFile); err != nil || string(again) != string(key) {
+		t.Fatalf("Expected the stored key to be read back, got %v", err)
+	}
+
+	track := NewSigned(trackingFile, key)
+	installation := Installation{
+		SourceCommit: "abc123",
+		Files: map[string]FileInfo{
+			"agents/reviewer.md": {Path: "agents/reviewer.md", Hash: "sha", Size: 10, Modified: time.Now()},
+		},
+		Directories: []string{"agents"},
+	}
+	if err := track.RecordInstallation("team", installation); err != nil {
+		t.Fatalf("RecordInstallation failed: %v", err)
+	}
+	if err := track.AddFile("team", FileInfo{Path: "agents/planner.md", Hash: "sha2", Size: 20, Modified: time.Now()}); err != nil {
+		t.Fatalf("AddFile failed: %v", err)
+	}
+
+	issues, err := track.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0] != (IntegrityIssue{Source: "legacy", Problem: IntegrityUnsigned}) {
+		t.Fatalf("Expected only the legacy entry to be flagged, got %+v", issues)
+	}
+
+	// Tamper with the recorded commit by hand
+	content, err := os.ReadFile(trackingFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(content), `"abc123"`, `"evil456"`, 1)
+	if err := os.WriteFile(trackingFile, []byte(tampered), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes made through the tracker do not launder the tampered entry
+	if err := track.MarkChecked("team", time.Now()); err != nil {
+		t.Fatalf("MarkChecked failed: %v", err)
+	}
+	issues, _ = track.Verify()
+	if len(issues) != 2 || issues[1] != (IntegrityIssue{Source: "team", Problem: IntegrityModified}) {
+		t.Fatalf("Expected the tampered entry to be flagged, got %+v", issues)
+	}
+	if other, _ := New(trackingFile).Verify(); len(other) != 0 {
+		t.Errorf("Expected an unsigned tracker to report nothing, got %+v", other)
+	}
+	if wrongKey, _ := NewSigned(trackingFile, make([]byte, signingKeySize)).Verify(); len(wrongKey) != 2 {
+		t.Errorf("Expected entries signed with another key to be flagged, got %+v", wrongKey)
+	}
+
+	if err := track.Resign([]string{"legacy", "team"}); err != nil {
+		t.Fatalf("Resign failed: %v", err)
+	}
+	if issues, _ := track.Verify(); len(issues) != 0 {
+		t.Errorf("Expected accepted entries to verify, got %+v", issues)
+	}
+}
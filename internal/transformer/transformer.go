@@ -17,6 +17,20 @@ import (
 // Transformer handles file transformations
 type Transformer struct {
 	settings config.Settings
+	// docOwner names another source owning a doc path, or returns ""
+	docOwner func(path string) string
+	// readonly returns the readonly_paths pattern matching a doc path, or ""
+	readonly     func(path string) string
+	docs         []string
+	docConflicts []DocConflict
+}
+
+// DocConflict is a doc that extract_docs left alone because another source
+// owns it or it matches a readonly_paths pattern
+type DocConflict struct {
+	Path    string
+	Owner   string
+	Pattern string
 }
 
 // New creates a new transformer
@@ -26,6 +40,29 @@ func New(settings config.Settings) *Transformer {
 	}
 }
 
+// SetDocOwner sets the lookup of the source owning a doc path, when that is
+// not the source being installed. extract_docs leaves docs with an owner alone.
+func (t *Transformer) SetDocOwner(owner func(path string) string) {
+	t.docOwner = owner
+}
+
+// SetReadonly sets the lookup of the readonly_paths pattern matching a doc
+// path. extract_docs never writes docs matching one.
+func (t *Transformer) SetReadonly(readonly func(path string) string) {
+	t.readonly = readonly
+}
+
+// Docs returns the docs written by extract_docs transformations
+func (t *Transformer) Docs() []string {
+	return t.docs
+}
+
+// DocConflicts returns the docs extract_docs left alone because another
+// source owns them or they are readonly
+func (t *Transformer) DocConflicts() []DocConflict {
+	return t.docConflicts
+}
+
 // Apply applies a transformation to files
 func (t *Transformer) Apply(files []string, transform config.Transformation, sourcePath, targetPath string) ([]string, error) {
 	switch transform.Type {
@@ -138,11 +175,25 @@ func (t *Transformer) extractDocs(files []string, transform config.Transformatio
 			docName := t.transformDocName(categoryName, transform.Naming)
 			docPath := filepath.Join(docsPath, docName+".md")
 
+			if t.readonly != nil {
+				if pattern := t.readonly(docPath); pattern != "" {
+					t.docConflicts = append(t.docConflicts, DocConflict{Path: docPath, Pattern: pattern})
+					continue
+				}
+			}
+			if t.docOwner != nil {
+				if owner := t.docOwner(docPath); owner != "" {
+					t.docConflicts = append(t.docConflicts, DocConflict{Path: docPath, Owner: owner})
+					continue
+				}
+			}
+
 			// Copy the file
 			srcFile := filepath.Join(sourcePath, file)
 			if err := t.copyFile(srcFile, docPath); err != nil {
 				return nil, fmt.Errorf("failed to extract doc %s: %w", file, err)
 			}
+			t.docs = append(t.docs, docPath)
 
 			// Note: extracted doc is written directly to project root
 		} else {
@@ -0,0 +1,24 @@
+package util
+
+import (
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// UnifiedDiff returns a unified diff between two texts, or an empty string if they are equal
+func UnifiedDiff(before, after, fromName, toName string) string {
+	if before == after {
+		return ""
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(before),
+		B:        difflib.SplitLines(after),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  2,
+	})
+	if err != nil {
+		return ""
+	}
+	return diff
+}
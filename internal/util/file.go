@@ -1,6 +1,8 @@
 package util
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
@@ -134,17 +136,13 @@ func (fm *FileManager) Remove(path string) error {
 
 	path = filepath.Clean(path)
 
-	// Normalize for cross-platform comparison
-	normalizedPath := strings.ToLower(strings.ReplaceAll(path, "\\", "/"))
-
-	// Critical system directories (normalized to forward slashes)
-	criticalPaths := []string{
-		"/", "/bin", "/sbin", "/usr", "/etc", "/proc", "/sys", "/dev", "/boot", "/root",
-		"c:/", "c:/windows", "c:/program files", "c:/program files (x86)",
-		"c:/users/all users", "c:/documents and settings",
+	// Critical system directories, drive roots and the system directories
+	// themselves, matched with Windows paths normalized to forward slashes
+	normalizedPath := normalizePath(path)
+	if isSystemPath(normalizedPath, true) {
+		return fmt.Errorf("refusing to remove system path: %s", path)
 	}
-
-	for _, criticalPath := range criticalPaths {
+	for _, criticalPath := range []string{"/bin", "/sbin", "/usr"} {
 		if normalizedPath == criticalPath || strings.HasPrefix(normalizedPath, criticalPath+"/") {
 			return fmt.Errorf("refusing to remove system path: %s", path)
 		}
@@ -191,7 +189,7 @@ func ExpandPath(path string) (string, error) {
 		return "", fmt.Errorf("invalid path: %w", err)
 	}
 
-	if !strings.HasPrefix(path, "~/") {
+	if !IsHomePath(path) {
 		return path, nil
 	}
 
@@ -203,6 +201,32 @@ func ExpandPath(path string) (string, error) {
 	return filepath.Join(home, path[2:]), nil
 }
 
+// IsHomePath reports whether path starts with "~/" or, on Windows, "~\\"
+func IsHomePath(path string) bool {
+	return len(path) >= 2 && path[0] == '~' && (path[1] == '/' || path[1] == filepath.Separator)
+}
+
+// FileSHA256 returns the hex-encoded sha256 digest of a file's content
+func FileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// SHA256Hex returns the hex-encoded sha256 of content, as FileSHA256 does for a file
+func SHA256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
 // atomicRename performs an atomic rename with proper Windows compatibility
 // It uses a retry mechanism to handle file locking issues on Windows
 func atomicRename(oldPath, newPath string) error {
@@ -0,0 +1,154 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// plainOutput is set when output must be free of color and unicode decorations
+var plainOutput atomic.Bool
+
+// absoluteTimes is set when timestamps are printed as exact local times
+// instead of relative to now
+var absoluteTimes atomic.Bool
+
+// TimeLayout is the layout of exact timestamps in text output
+const TimeLayout = "2006-01-02 15:04:05"
+
+// plainSymbols maps decorative symbols to their ASCII equivalents for plain output
+var plainSymbols = map[string]string{
+	"✓": "[OK]",
+	"✗": "[ERROR]",
+	"⚠": "[WARN]",
+	"ℹ": "[INFO]",
+	"●": "-",
+	"⭐": "*",
+	"½": "+",
+	"═": "=",
+	"×": "x",
+}
+
+// SetPlainOutput enables or disables plain (ASCII-only) output
+func SetPlainOutput(plain bool) {
+	plainOutput.Store(plain)
+}
+
+// IsPlainOutput returns true if plain output mode is enabled
+func IsPlainOutput() bool {
+	return plainOutput.Load()
+}
+
+// Symbol returns the symbol itself, or its ASCII equivalent in plain output mode
+func Symbol(s string) string {
+	if !IsPlainOutput() {
+		return s
+	}
+	if plain, ok := plainSymbols[s]; ok {
+		return plain
+	}
+	return s
+}
+
+// PlainText replaces all known decorative symbols in text when plain output mode is enabled
+func PlainText(text string) string {
+	if !IsPlainOutput() {
+		return text
+	}
+	for symbol, plain := range plainSymbols {
+		text = strings.ReplaceAll(text, symbol, plain)
+	}
+	return text
+}
+
+// ColorDisabled returns true if color must be disabled by flag or the NO_COLOR convention
+func ColorDisabled(noColorFlag bool) bool {
+	if noColorFlag || IsPlainOutput() {
+		return true
+	}
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// ColorForced returns true if CLICOLOR_FORCE requests color even without a terminal
+func ColorForced() bool {
+	force := os.Getenv("CLICOLOR_FORCE")
+	return force != "" && force != "0"
+}
+
+// SetAbsoluteTimes enables or disables exact timestamps in text output
+func SetAbsoluteTimes(absolute bool) {
+	absoluteTimes.Store(absolute)
+}
+
+// IsAbsoluteTimes returns true if timestamps are printed as exact values
+func IsAbsoluteTimes() bool {
+	return absoluteTimes.Load()
+}
+
+// FormatTime renders t for text output: relative to now, such as "3 days
+// ago", or as an exact local time when absolute times are enabled. The zero
+// time renders as "never".
+func FormatTime(t time.Time) string {
+	return FormatTimeAt(t, time.Now())
+}
+
+// FormatTimeAt is FormatTime with an explicit current time, so the rows of a
+// listing are all relative to the same moment
+func FormatTimeAt(t, now time.Time) string {
+	switch {
+	case t.IsZero():
+		return "never"
+	case IsAbsoluteTimes():
+		return t.Local().Format(TimeLayout)
+	default:
+		return RelativeTime(t, now)
+	}
+}
+
+// RelativeTime renders how long before or after now t is, such as
+// "3 days ago" or "in 2 hours"; differences under a minute are "just now"
+func RelativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	if d < 0 {
+		if -d < time.Minute {
+			return "just now"
+		}
+		return "in " + HumanDuration(-d)
+	}
+	if d < time.Minute {
+		return "just now"
+	}
+	return HumanDuration(d) + " ago"
+}
+
+// HumanDuration renders d in its largest whole unit, such as "1 minute" or
+// "3 days"; months are 30 days and years 365 days
+func HumanDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	const day = 24 * time.Hour
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * day},
+		{"month", 30 * day},
+		{"week", 7 * day},
+		{"day", day},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+		{"second", time.Second},
+	}
+	for _, unit := range units {
+		if n := int64(d / unit.size); n > 0 {
+			if n == 1 {
+				return "1 " + unit.name
+			}
+			return fmt.Sprintf("%d %ss", n, unit.name)
+		}
+	}
+	return "0 seconds"
+}
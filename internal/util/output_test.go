@@ -0,0 +1,105 @@
+package util
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSymbol(t *testing.T) {
+	defer SetPlainOutput(false)
+
+	SetPlainOutput(false)
+	if got := Symbol("✓"); got != "✓" {
+		t.Errorf("Symbol() = %q, want unicode symbol", got)
+	}
+
+	SetPlainOutput(true)
+	tests := map[string]string{
+		"✓": "[OK]",
+		"✗": "[ERROR]",
+		"⚠": "[WARN]",
+		"ℹ": "[INFO]",
+		"x": "x",
+	}
+	for in, want := range tests {
+		if got := Symbol(in); got != want {
+			t.Errorf("Symbol(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if got := PlainText("═══ Title ═══"); got != "=== Title ===" {
+		t.Errorf("PlainText() = %q", got)
+	}
+	if got := PlainText("4.00 = name 3×1/1"); got != "4.00 = name 3x1/1" {
+		t.Errorf("PlainText() = %q", got)
+	}
+}
+
+func TestColorDisabled(t *testing.T) {
+	defer SetPlainOutput(false)
+
+	t.Setenv("NO_COLOR", "")
+	if ColorDisabled(false) {
+		t.Error("Expected colors enabled without flags or NO_COLOR")
+	}
+	if !ColorDisabled(true) {
+		t.Error("Expected --no-color to disable colors")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if !ColorDisabled(false) {
+		t.Error("Expected NO_COLOR to disable colors")
+	}
+
+	t.Setenv("NO_COLOR", "")
+	SetPlainOutput(true)
+	if !ColorDisabled(false) {
+		t.Error("Expected plain output to disable colors")
+	}
+}
+
+func TestColorForced(t *testing.T) {
+	t.Setenv("CLICOLOR_FORCE", "1")
+	if !ColorForced() {
+		t.Error("Expected CLICOLOR_FORCE=1 to force colors")
+	}
+
+	t.Setenv("CLICOLOR_FORCE", "0")
+	if ColorForced() {
+		t.Error("Expected CLICOLOR_FORCE=0 not to force colors")
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	defer SetAbsoluteTimes(false)
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for want, t0 := range map[string]time.Time{
+		"never":            {},
+		"just now":         now.Add(-10 * time.Second),
+		"1 minute ago":     now.Add(-90 * time.Second),
+		"5 minutes ago":    now.Add(-5 * time.Minute),
+		"3 hours ago":      now.Add(-3 * time.Hour),
+		"2 days ago":       now.Add(-50 * time.Hour),
+		"2 weeks ago":      now.Add(-15 * 24 * time.Hour),
+		"3 months ago":     now.Add(-95 * 24 * time.Hour),
+		"1 year ago":       now.Add(-400 * 24 * time.Hour),
+		"in 2 hours":       now.Add(2*time.Hour + time.Minute),
+		"just now (ahead)": now.Add(5 * time.Second),
+	} {
+		want = strings.TrimSuffix(want, " (ahead)")
+		if got := FormatTimeAt(t0, now); got != want {
+			t.Errorf("FormatTimeAt(%v) = %q, want %q", now.Sub(t0), got, want)
+		}
+	}
+
+	SetAbsoluteTimes(true)
+	installed := now.Add(-50 * time.Hour)
+	if got, want := FormatTimeAt(installed, now), installed.Local().Format(TimeLayout); got != want {
+		t.Errorf("FormatTimeAt() with absolute times = %q, want %q", got, want)
+	}
+	if got := FormatTimeAt(time.Time{}, now); got != "never" {
+		t.Errorf("FormatTimeAt() of the zero time = %q, want never", got)
+	}
+}
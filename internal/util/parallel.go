@@ -0,0 +1,57 @@
+package util
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Workers returns the worker count to use for n items when workers were
+// requested: runtime.NumCPU() when workers is not positive, and never more
+// than n
+func Workers(workers, n int) int {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+	return workers
+}
+
+// ForEachParallel calls fn for every index in [0, n) on a bounded pool of
+// Workers(workers, n) goroutines, returning when all calls have finished or
+// ctx is done. Results are only deterministic when fn writes nothing but
+// state owned by its index, such as results[i], and callers aggregate them
+// in index order afterwards.
+func ForEachParallel(ctx context.Context, n, workers int, fn func(i int)) error {
+	if n == 0 {
+		return ctx.Err()
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < Workers(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	return err
+}
@@ -0,0 +1,65 @@
+package util
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForEachParallel(t *testing.T) {
+	const n = 100
+	var running, peak atomic.Int32
+	results := make([]int, n)
+
+	err := ForEachParallel(context.Background(), n, 3, func(i int) {
+		current := running.Add(1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		results[i] = i * i
+		running.Add(-1)
+	})
+	if err != nil {
+		t.Fatalf("ForEachParallel failed: %v", err)
+	}
+
+	for i, result := range results {
+		if result != i*i {
+			t.Fatalf("Expected every index processed once, index %d has %d", i, result)
+		}
+	}
+	if peak.Load() > 3 {
+		t.Errorf("Expected at most 3 concurrent calls, saw %d", peak.Load())
+	}
+}
+
+func TestForEachParallel_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+
+	err := ForEachParallel(ctx, 1000, 1, func(i int) {
+		if calls.Add(1) == 5 {
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Errorf("Expected the cancellation reported, got %v", err)
+	}
+	if calls.Load() >= 1000 {
+		t.Error("Expected cancellation to stop handing out work")
+	}
+}
+
+func TestWorkers(t *testing.T) {
+	if got := Workers(8, 3); got != 3 {
+		t.Errorf("Expected no more workers than items, got %d", got)
+	}
+	if got := Workers(0, 1000); got < 1 {
+		t.Errorf("Expected at least one worker by default, got %d", got)
+	}
+}
@@ -1,22 +1,26 @@
 package util
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	pathpkg "path"
 	"path/filepath"
 	"regexp"
 	"strings"
 )
 
-// ValidatePath checks for path traversal attacks and validates path format
+// ValidatePath checks for path traversal attacks and validates path format.
+// Both forward slashes and backslashes are treated as separators, so Windows
+// paths are checked the same way on every platform.
 func ValidatePath(path string) error {
 	if path == "" {
 		return fmt.Errorf("path cannot be empty")
 	}
 
 	// Check for path traversal attempts
-	if strings.Contains(path, "..") {
+	if HasTraversal(path) {
 		return fmt.Errorf("path traversal detected: %s", path)
 	}
 
@@ -25,35 +29,81 @@ func ValidatePath(path string) error {
 		return fmt.Errorf("null byte detected in path: %s", path)
 	}
 
-	// Normalize path for cross-platform comparison
-	cleanPath := filepath.Clean(path)
-	normalizedPath := strings.ToLower(strings.ReplaceAll(cleanPath, "\\", "/"))
-
-	// Comprehensive dangerous patterns (normalized to forward slashes)
-	dangerousPatterns := []string{
-		"/etc/",
-		"/proc/",
-		"/sys/",
-		"/dev/",
-		"/boot/",
-		"/root/",
-		"/var/log/",
-		"c:/windows/",
-		"c:/program files/",
-		"c:/program files (x86)/",
-		"c:/users/all users/",
-		"c:/documents and settings/",
-	}
-
-	for _, pattern := range dangerousPatterns {
-		if strings.HasPrefix(normalizedPath, pattern) {
-			return fmt.Errorf("access to system path denied: %s", path)
-		}
+	if isSystemPath(normalizePath(path), false) {
+		return fmt.Errorf("access to system path denied: %s", path)
 	}
 
 	return nil
 }
 
+// HasTraversal reports whether any element of path is "..", splitting on
+// both forward slashes and backslashes. Names that merely contain two dots,
+// such as "v1..2.md", are not traversal.
+func HasTraversal(path string) bool {
+	for _, elem := range strings.FieldsFunc(path, isSeparator) {
+		if elem == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// isSeparator reports whether r separates path elements on any platform
+func isSeparator(r rune) bool {
+	return r == '/' || r == '\\'
+}
+
+// unixSystemPaths are system directories that are never read or written
+var unixSystemPaths = []string{
+	"/etc", "/proc", "/sys", "/dev", "/boot", "/root", "/var/log",
+}
+
+// windowsSystemPaths are system directories on any drive, without the drive letter
+var windowsSystemPaths = []string{
+	"/windows", "/program files", "/program files (x86)",
+	"/users/all users", "/documents and settings",
+}
+
+// normalizePath returns path lower-cased and cleaned with forward slashes,
+// without the \\?\ and \\.\ prefixes of Win32 device paths, so Unix and Windows
+// system paths can be matched with the same patterns
+func normalizePath(path string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(path, "\\", "/"))
+	for _, prefix := range []string{"//?/", "//./"} {
+		normalized = strings.TrimPrefix(normalized, prefix)
+	}
+	return pathpkg.Clean(normalized)
+}
+
+// splitDrive splits a normalized path into its drive letter, such as "c:",
+// and the rest of the path
+func splitDrive(normalized string) (string, string) {
+	if len(normalized) >= 2 && normalized[1] == ':' && normalized[0] >= 'a' && normalized[0] <= 'z' {
+		return normalized[:2], normalized[2:]
+	}
+	return "", normalized
+}
+
+// isSystemPath reports whether a normalized path is inside a system
+// directory; with roots, the system directories and drive roots themselves
+// count too
+func isSystemPath(normalized string, roots bool) bool {
+	drive, rest := splitDrive(normalized)
+	patterns := unixSystemPaths
+	if drive != "" {
+		patterns = windowsSystemPaths
+	}
+	if roots && (rest == "/" || rest == "" && drive != "") {
+		return true
+	}
+	for _, pattern := range patterns {
+		if rest == pattern && roots || strings.HasPrefix(rest, pattern+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidateRepository validates repository names to prevent injection
 func ValidateRepository(repo string) error {
 	if repo == "" {
@@ -90,7 +140,7 @@ func ValidateScriptPath(scriptPath string) error {
 	cleanPath := filepath.Clean(scriptPath)
 
 	// Check for path traversal attempts
-	if strings.Contains(cleanPath, "..") {
+	if HasTraversal(cleanPath) {
 		return fmt.Errorf("path traversal detected in script path: %s", scriptPath)
 	}
 
@@ -104,11 +154,7 @@ func ValidateScriptPath(scriptPath string) error {
 	// Check if script is in an allowed directory
 	isAllowed := false
 	for _, dir := range allowedDirs {
-		// Resolve absolute paths for comparison
-		absDir, _ := filepath.Abs(dir)
-		absScript, _ := filepath.Abs(cleanPath)
-
-		if strings.HasPrefix(absScript, absDir) {
+		if IsWithin(dir, cleanPath) {
 			isAllowed = true
 			break
 		}
@@ -117,9 +163,7 @@ func ValidateScriptPath(scriptPath string) error {
 	if !isAllowed {
 		// Check if script exists in working directory's scripts folder
 		cwd, _ := os.Getwd()
-		localScriptsDir := filepath.Join(cwd, "scripts")
-		absScript, _ := filepath.Abs(cleanPath)
-		if strings.HasPrefix(absScript, localScriptsDir) {
+		if IsWithin(filepath.Join(cwd, "scripts"), cleanPath) {
 			isAllowed = true
 		}
 	}
@@ -141,6 +185,26 @@ func ValidateScriptPath(scriptPath string) error {
 	return nil
 }
 
+// IsWithin reports whether path is dir or inside it, after resolving both to
+// absolute paths. Unlike a string prefix check, a sibling such as
+// "scripts-old" is not inside "scripts", and on Windows the comparison
+// ignores case and separator style as the file system does.
+func IsWithin(dir, path string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return false
+	}
+	return !HasTraversal(rel) && !filepath.IsAbs(rel)
+}
+
 // ValidateBranch validates git branch names
 func ValidateBranch(branch string) error {
 	if branch == "" {
@@ -166,6 +230,11 @@ func ValidateBranch(branch string) error {
 
 // SecureCommand creates a secure exec.Cmd with validated arguments
 func SecureCommand(name string, args ...string) (*exec.Cmd, error) {
+	return SecureCommandContext(context.Background(), name, args...)
+}
+
+// SecureCommandContext is like SecureCommand but kills the process when ctx is done
+func SecureCommandContext(ctx context.Context, name string, args ...string) (*exec.Cmd, error) {
 	// Validate command name
 	if name == "" {
 		return nil, fmt.Errorf("command name cannot be empty")
@@ -177,6 +246,9 @@ func SecureCommand(name string, args ...string) (*exec.Cmd, error) {
 		"gh":   true,
 		"bash": true,
 		"sh":   true,
+		// OS keychain tools used by the credentials package
+		"security":    true,
+		"secret-tool": true,
 	}
 
 	if !allowedCommands[name] {
@@ -203,7 +275,7 @@ func SecureCommand(name string, args ...string) (*exec.Cmd, error) {
 	}
 
 	// Create command with validated arguments
-	cmd := exec.Command(name, args...)
+	cmd := exec.CommandContext(ctx, name, args...)
 
 	// Set secure environment - remove dangerous variables
 	cmd.Env = getSecureEnv()
@@ -265,5 +337,18 @@ func getSecureEnv() []string {
 	return secureEnv
 }
 
+// SSHAgentEnv returns the SSH agent variables of the environment, which
+// getSecureEnv drops. Commands that push over SSH, such as publish, add them
+// so git can authenticate with the user's agent.
+func SSHAgentEnv() []string {
+	var env []string
+	for _, envVar := range []string{"SSH_AUTH_SOCK", "SSH_AGENT_PID"} {
+		if value := getEnvVar(envVar); value != "" {
+			env = append(env, fmt.Sprintf("%s=%s", envVar, value))
+		}
+	}
+	return env
+}
+
 // getEnvVar safely gets environment variable
 var getEnvVar = os.Getenv
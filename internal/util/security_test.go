@@ -58,6 +58,31 @@ func TestValidatePath(t *testing.T) {
 			path:    "C:\\Program Files\\sensitive",
 			wantErr: true,
 		},
+		{
+			name:    "windows system path on another drive",
+			path:    "D:\\Windows\\System32\\drivers",
+			wantErr: true,
+		},
+		{
+			name:    "windows device path",
+			path:    `\\?\C:\Windows\System32`,
+			wantErr: true,
+		},
+		{
+			name:    "windows path traversal",
+			path:    "agents\\..\\..\\secrets",
+			wantErr: true,
+		},
+		{
+			name:    "valid windows project path",
+			path:    `C:\Users\dev\project\.claude\agents\reviewer.md`,
+			wantErr: false,
+		},
+		{
+			name:    "name containing two dots",
+			path:    ".claude/agents/v1..2.md",
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -70,6 +95,41 @@ func TestValidatePath(t *testing.T) {
 	}
 }
 
+func TestHasTraversal(t *testing.T) {
+	tests := map[string]bool{
+		"../etc":              true,
+		"agents/../../x":      true,
+		`agents\..\x`:         true,
+		"..":                  true,
+		"agents/v1..2.md":     false,
+		"..hidden/agent.md":   false,
+		`C:\Users\dev\agents`: false,
+	}
+	for path, want := range tests {
+		if got := HasTraversal(path); got != want {
+			t.Errorf("HasTraversal(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestIsWithin(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{dir, true},
+		{filepath.Join(dir, "scripts", "a.sh"), true},
+		{dir + "-old", false},
+		{filepath.Join(dir, "..", "other"), false},
+	}
+	for _, tt := range tests {
+		if got := IsWithin(dir, tt.path); got != tt.want {
+			t.Errorf("IsWithin(%q, %q) = %v, want %v", dir, tt.path, got, tt.want)
+		}
+	}
+}
+
 func TestValidateRepository(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -433,3 +493,18 @@ func TestGetSecureEnv(t *testing.T) {
 		t.Error("DATABASE_PASSWORD should not be in secure environment")
 	}
 }
+
+func TestSSHAgentEnv(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "/tmp/ssh-agent.sock")
+	t.Setenv("SSH_AGENT_PID", "")
+
+	env := SSHAgentEnv()
+	if len(env) != 1 || env[0] != "SSH_AUTH_SOCK=/tmp/ssh-agent.sock" {
+		t.Errorf("Expected only SSH_AUTH_SOCK, got %v", env)
+	}
+	for _, e := range getSecureEnv() {
+		if strings.HasPrefix(e, "SSH_AUTH_SOCK=") {
+			t.Error("SSH_AUTH_SOCK should not be in the default secure environment")
+		}
+	}
+}
@@ -0,0 +1,58 @@
+//go:build windows
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsHomePath_Windows(t *testing.T) {
+	for path, want := range map[string]bool{
+		`~\.claude\agents`: true,
+		"~/.claude/agents": true,
+		`C:\Users\dev`:     false,
+		"~agents":          false,
+	} {
+		if got := IsHomePath(path); got != want {
+			t.Errorf("IsHomePath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestExpandPath_Windows(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	got, err := ExpandPath(`~\.claude\agents`)
+	if err != nil {
+		t.Fatalf("ExpandPath() error = %v", err)
+	}
+	if want := filepath.Join(home, ".claude", "agents"); got != want {
+		t.Errorf("ExpandPath() = %q, want %q", got, want)
+	}
+}
+
+func TestIsWithin_Windows(t *testing.T) {
+	dir := t.TempDir()
+	// Windows paths are case-insensitive and accept either separator
+	if !IsWithin(dir, filepath.ToSlash(filepath.Join(dir, "Scripts", "a.ps1"))) {
+		t.Error("Expected a forward-slash path inside dir to be within it")
+	}
+	if !IsWithin(`C:\Project\Scripts`, `c:\project\scripts\run.sh`) {
+		t.Error("Expected the comparison to ignore case")
+	}
+}
+
+func TestValidatePath_Windows(t *testing.T) {
+	for _, path := range []string{`C:\Windows\System32`, `\\?\C:\Windows`, `d:\program files\app`} {
+		if err := ValidatePath(path); err == nil {
+			t.Errorf("Expected ValidatePath(%q) to fail", path)
+		}
+	}
+	if err := ValidatePath(`C:\Users\dev\project\.claude\agents`); err != nil {
+		t.Errorf("ValidatePath() error = %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package util
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TempDirMarker is the file in each temp directory agent-manager creates,
+// holding the PID of the process that created it
+const TempDirMarker = ".agent-manager.pid"
+
+// DefaultTempCleanupAge is how old a temp directory left behind by a crashed
+// run must be before it is removed
+const DefaultTempCleanupAge = 24 * time.Hour
+
+// tempDirPrefixes are the name prefixes of the temp directories agent-manager creates
+var tempDirPrefixes = []string{"agent-install-", "agent-explain-", "agent-manager-"}
+
+// StaleTempDir is a temp directory left behind by a run that is no longer running
+type StaleTempDir struct {
+	Path    string
+	PID     int
+	Created time.Time
+	Size    int64
+}
+
+// MkdirTemp creates a temp directory like os.MkdirTemp and marks it with the
+// PID of this process, so a directory left behind by a crashed run can be
+// told apart from one in use
+func MkdirTemp(pattern string) (string, error) {
+	dir, err := os.MkdirTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	marker := filepath.Join(dir, TempDirMarker)
+	if err := os.WriteFile(marker, []byte(strconv.Itoa(os.Getpid())), 0600); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// FindStaleTempDirs returns the marked temp directories in the system temp
+// directory that were created more than maxAge ago by a process that is no
+// longer running, oldest first. Unmarked directories are never returned.
+func FindStaleTempDirs(maxAge time.Duration) ([]StaleTempDir, error) {
+	root := os.TempDir()
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", root, err)
+	}
+
+	var stale []StaleTempDir
+	for _, entry := range entries {
+		if !entry.IsDir() || !hasTempDirPrefix(entry.Name()) {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		marker, err := os.Lstat(filepath.Join(dir, TempDirMarker))
+		if err != nil || !marker.Mode().IsRegular() || time.Since(marker.ModTime()) < maxAge {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, TempDirMarker))
+		if err != nil {
+			continue
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+		if err != nil || pid <= 0 || pid == os.Getpid() || processAlive(pid) {
+			continue
+		}
+		stale = append(stale, StaleTempDir{Path: dir, PID: pid, Created: marker.ModTime(), Size: dirSize(dir)})
+	}
+
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Created.Before(stale[j].Created) })
+	return stale, nil
+}
+
+// RemoveStaleTempDirs removes the stale temp directories and returns the
+// bytes reclaimed, stopping at the first directory that cannot be removed
+func RemoveStaleTempDirs(dirs []StaleTempDir) (int64, error) {
+	var reclaimed int64
+	for _, dir := range dirs {
+		if err := os.RemoveAll(dir.Path); err != nil {
+			return reclaimed, fmt.Errorf("failed to remove %s: %w", dir.Path, err)
+		}
+		reclaimed += dir.Size
+	}
+	return reclaimed, nil
+}
+
+// hasTempDirPrefix reports whether name is named like an agent-manager temp directory
+func hasTempDirPrefix(name string) bool {
+	for _, prefix := range tempDirPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// dirSize returns the total size of the regular files below dir
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
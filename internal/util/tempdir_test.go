@@ -0,0 +1,86 @@
+package util
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// exitedPID returns the PID of a process that has already exited
+func exitedPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to run helper process: %v", err)
+	}
+	return cmd.Process.Pid
+}
+
+// markTempDir creates dir in the temp directory with a marker holding pid, dated age ago
+func markTempDir(t *testing.T, name string, pid int, age time.Duration) string {
+	t.Helper()
+	dir := filepath.Join(os.TempDir(), name)
+	if err := os.MkdirAll(filepath.Join(dir, "repo"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "repo", "agent.md"), make([]byte, 100), 0600); err != nil {
+		t.Fatal(err)
+	}
+	marker := filepath.Join(dir, TempDirMarker)
+	if err := os.WriteFile(marker, []byte(strconv.Itoa(pid)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	created := time.Now().Add(-age)
+	if err := os.Chtimes(marker, created, created); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestStaleTempDirs(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("TMP", os.Getenv("TMPDIR"))
+	t.Setenv("TEMP", os.Getenv("TMPDIR"))
+
+	own, err := MkdirTemp("agent-install-*")
+	if err != nil {
+		t.Fatalf("MkdirTemp failed: %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(own, TempDirMarker)); err != nil || string(content) != strconv.Itoa(os.Getpid()) {
+		t.Fatalf("Expected the temp directory to be marked with this PID, got %q, %v", content, err)
+	}
+
+	dead := exitedPID(t)
+	crashed := markTempDir(t, "agent-install-crashed", dead, 48*time.Hour)
+	recent := markTempDir(t, "agent-manager-import-recent", dead, time.Minute)
+	running := markTempDir(t, "agent-explain-running", os.Getpid(), 48*time.Hour)
+	foreign := markTempDir(t, "other-tool-crashed", dead, 48*time.Hour)
+	unmarked := filepath.Join(os.TempDir(), "agent-install-unmarked")
+	if err := os.Mkdir(unmarked, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	stale, err := FindStaleTempDirs(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("FindStaleTempDirs failed: %v", err)
+	}
+	if len(stale) != 1 || stale[0].Path != crashed || stale[0].PID != dead || stale[0].Size != 100+int64(len(strconv.Itoa(dead))) {
+		t.Fatalf("Expected only the crashed directory to be stale, got %+v", stale)
+	}
+
+	reclaimed, err := RemoveStaleTempDirs(stale)
+	if err != nil || reclaimed != stale[0].Size {
+		t.Errorf("RemoveStaleTempDirs() = %d, %v", reclaimed, err)
+	}
+	if _, err := os.Stat(crashed); !os.IsNotExist(err) {
+		t.Error("Expected the crashed directory to be removed")
+	}
+	for _, dir := range []string{own, recent, running, foreign, unmarked} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("Expected %s to be kept: %v", dir, err)
+		}
+	}
+}
@@ -15,3 +15,11 @@ func CleanText(text string) string {
 	cleaned = strings.ReplaceAll(cleaned, "\t", " ")
 	return cleaned
 }
+
+// ShortCommit abbreviates a commit hash to the 7 characters git shows
+func ShortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
@@ -0,0 +1,79 @@
+package util
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Throttle paces operations, such as file copies, to at most a number of
+// operations and bytes per second. Each operation reserves its share of both
+// budgets when it starts, so concurrent callers are spread out evenly rather
+// than released in bursts. A nil Throttle never waits.
+type Throttle struct {
+	mu          sync.Mutex
+	opInterval  time.Duration
+	bytesPerSec float64
+	nextOp      time.Time
+	nextByte    time.Time
+}
+
+// NewThrottle creates a throttle limited to opsPerSecond operations and
+// bytesPerSecond bytes, where 0 disables a limit. It returns nil when both
+// limits are disabled.
+func NewThrottle(opsPerSecond, bytesPerSecond float64) *Throttle {
+	if opsPerSecond <= 0 && bytesPerSecond <= 0 {
+		return nil
+	}
+	t := &Throttle{bytesPerSec: bytesPerSecond}
+	if opsPerSecond > 0 {
+		t.opInterval = time.Duration(float64(time.Second) / opsPerSecond)
+	}
+	return t
+}
+
+// Wait blocks until an operation on size bytes may start, or ctx is done
+func (t *Throttle) Wait(ctx context.Context, size int64) error {
+	if t == nil {
+		return ctx.Err()
+	}
+
+	delay := t.reserve(time.Now(), size)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reserve books an operation on size bytes and returns how long after now it may start
+func (t *Throttle) reserve(now time.Time, size int64) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	start := now
+	if t.opInterval > 0 {
+		slot := later(now, t.nextOp)
+		t.nextOp = slot.Add(t.opInterval)
+		start = later(start, slot)
+	}
+	if t.bytesPerSec > 0 {
+		slot := later(now, t.nextByte)
+		t.nextByte = slot.Add(time.Duration(float64(size) / t.bytesPerSec * float64(time.Second)))
+		start = later(start, slot)
+	}
+	return start.Sub(now)
+}
+
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,64 @@
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewThrottle_Disabled(t *testing.T) {
+	throttle := NewThrottle(0, 0)
+	if throttle != nil {
+		t.Fatalf("Expected no throttle without limits, got %+v", throttle)
+	}
+	if err := throttle.Wait(context.Background(), 1<<30); err != nil {
+		t.Errorf("Expected a nil throttle not to wait, got %v", err)
+	}
+}
+
+func TestThrottle_Reserve(t *testing.T) {
+	now := time.Now()
+
+	files := NewThrottle(10, 0)
+	for i := 0; i < 3; i++ {
+		want := time.Duration(i) * 100 * time.Millisecond
+		if got := files.reserve(now, 0); got != want {
+			t.Errorf("Expected file %d to start after %v, got %v", i, want, got)
+		}
+	}
+
+	bytes := NewThrottle(0, 1000)
+	if got := bytes.reserve(now, 500); got != 0 {
+		t.Errorf("Expected the first copy to start at once, got %v", got)
+	}
+	if got := bytes.reserve(now, 2000); got != 500*time.Millisecond {
+		t.Errorf("Expected the second copy to wait for the first 500 bytes, got %v", got)
+	}
+	if got := bytes.reserve(now, 0); got != 2500*time.Millisecond {
+		t.Errorf("Expected the third copy to wait for 2500 bytes, got %v", got)
+	}
+
+	// The stricter limit decides when an operation starts
+	both := NewThrottle(10, 1000)
+	both.reserve(now, 1000)
+	if got := both.reserve(now, 0); got != time.Second {
+		t.Errorf("Expected the byte limit to dominate, got %v", got)
+	}
+
+	// Budget left unused while idle is not saved up for a burst
+	if got := both.reserve(now.Add(time.Minute), 0); got != 0 {
+		t.Errorf("Expected an idle throttle to start at once, got %v", got)
+	}
+}
+
+func TestThrottle_WaitCancelled(t *testing.T) {
+	throttle := NewThrottle(0.001, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := throttle.Wait(ctx, 0); err != nil {
+		t.Fatalf("Expected the first operation to start at once, got %v", err)
+	}
+	cancel()
+	if err := throttle.Wait(ctx, 0); err == nil {
+		t.Error("Expected a cancelled wait to fail")
+	}
+}
@@ -0,0 +1,125 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DefaultMaxWalkDepth is how many directory levels below the root Walk
+// descends when no limit is configured
+const DefaultMaxWalkDepth = 32
+
+// ErrMaxDepth is passed to the walk function for directories nested deeper
+// than the walk's depth limit, which are not descended into
+var ErrMaxDepth = errors.New("directory exceeds maximum walk depth")
+
+// WalkOptions controls how Walk descends a directory tree
+type WalkOptions struct {
+	// MaxDepth is how many directory levels below the root are walked;
+	// DefaultMaxWalkDepth when zero and unlimited when negative
+	MaxDepth int
+	// FollowSymlinks descends into symlinked directories. Each directory is
+	// walked once, however many links lead to it, so symlink loops terminate.
+	FollowSymlinks bool
+}
+
+// Walk walks the tree rooted at root like filepath.Walk, calling fn for each
+// file and directory in lexical order, with two safeguards against
+// accidental or malicious layouts. Directories deeper than opts.MaxDepth are
+// reported to fn with an error wrapping ErrMaxDepth and are not descended
+// into; fn may return nil to skip them and continue. Symlinked directories are
+// reported as symlinks and not descended into unless opts.FollowSymlinks is
+// set, in which case a directory already walked, such as one a link loops
+// back to, is skipped. A symlinked root is always followed.
+func Walk(root string, opts WalkOptions, fn filepath.WalkFunc) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		w := &walker{opts: opts, fn: fn}
+		err = w.walk(root, info, 0)
+	}
+	if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+type walker struct {
+	opts WalkOptions
+	fn   filepath.WalkFunc
+	// visited holds the directories walked so far, compared with os.SameFile
+	// so the same device and inode is never walked twice
+	visited []os.FileInfo
+}
+
+func (w *walker) maxDepth() int {
+	if w.opts.MaxDepth == 0 {
+		return DefaultMaxWalkDepth
+	}
+	return w.opts.MaxDepth
+}
+
+func (w *walker) walk(path string, info os.FileInfo, depth int) error {
+	if !info.IsDir() {
+		return w.fn(path, info, nil)
+	}
+	if limit := w.maxDepth(); limit >= 0 && depth > limit {
+		return w.fn(path, info, fmt.Errorf("%w (%d)", ErrMaxDepth, limit))
+	}
+	for _, seen := range w.visited {
+		if os.SameFile(seen, info) {
+			DebugPrintf("Skipping %s: directory already walked\n", path)
+			return nil
+		}
+	}
+	w.visited = append(w.visited, info)
+
+	if err := w.fn(path, info, nil); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		// As with filepath.Walk, fn sees the directory again with the read error
+		if err := w.fn(path, info, err); err != nil && !errors.Is(err, filepath.SkipDir) {
+			return err
+		}
+		return nil
+	}
+
+	for _, entry := range entries {
+		child := filepath.Join(path, entry.Name())
+		childInfo, err := w.info(child, entry)
+		if err != nil {
+			if err := w.fn(child, nil, err); err != nil && !errors.Is(err, filepath.SkipDir) {
+				return err
+			}
+			continue
+		}
+		if err := w.walk(child, childInfo, depth+1); err != nil {
+			if !errors.Is(err, filepath.SkipDir) {
+				return err
+			}
+			// SkipDir from a file skips the rest of its directory
+			if !childInfo.IsDir() {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// info returns the file info Walk reports for entry: the link target's for a
+// followed symlink to a directory, and the entry's own otherwise
+func (w *walker) info(path string, entry os.DirEntry) (os.FileInfo, error) {
+	info, err := entry.Info()
+	if err != nil || !w.opts.FollowSymlinks || info.Mode()&os.ModeSymlink == 0 {
+		return info, err
+	}
+	if target, err := os.Stat(path); err == nil && target.IsDir() {
+		return target, nil
+	}
+	return info, nil
+}
@@ -0,0 +1,130 @@
+package util
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// walkFiles returns the files Walk reports below root, relative to root, and
+// the directories reported with an error
+func walkFiles(t *testing.T, root string, opts WalkOptions) ([]string, []string) {
+	t.Helper()
+	var files, failed []string
+	err := Walk(root, opts, func(path string, info os.FileInfo, err error) error {
+		rel, _ := filepath.Rel(root, path)
+		if err != nil {
+			failed = append(failed, filepath.ToSlash(rel))
+			return nil
+		}
+		if !info.IsDir() {
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	return files, failed
+}
+
+func writeWalkFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWalk_SymlinkLoop(t *testing.T) {
+	root := t.TempDir()
+	writeWalkFile(t, filepath.Join(root, "team", "reviewer.md"))
+	if err := os.Symlink(root, filepath.Join(root, "team", "loop")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	shared := t.TempDir()
+	writeWalkFile(t, filepath.Join(shared, "planner.md"))
+	if err := os.Symlink(shared, filepath.Join(root, "shared")); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := walkFiles(t, root, WalkOptions{})
+	if want := []string{"shared", "team/loop", "team/reviewer.md"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("Without following, expected links reported as files %v, got %v", want, files)
+	}
+
+	files, failed := walkFiles(t, root, WalkOptions{FollowSymlinks: true})
+	if want := []string{"shared/planner.md", "team/reviewer.md"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("Expected each directory walked once %v, got %v", want, files)
+	}
+	if len(failed) > 0 {
+		t.Errorf("Expected no errors, got %v", failed)
+	}
+}
+
+func TestWalk_MaxDepth(t *testing.T) {
+	root := t.TempDir()
+	writeWalkFile(t, filepath.Join(root, "top.md"))
+	writeWalkFile(t, filepath.Join(root, "a", "b", "deep.md"))
+	writeWalkFile(t, filepath.Join(root, "a", "b", "c", "deeper.md"))
+
+	tests := []struct {
+		name       string
+		maxDepth   int
+		wantFiles  []string
+		wantFailed []string
+	}{
+		{name: "limited", maxDepth: 1, wantFiles: []string{"top.md"}, wantFailed: []string{"a/b"}},
+		{name: "at limit", maxDepth: 2, wantFiles: []string{"a/b/deep.md", "top.md"}, wantFailed: []string{"a/b/c"}},
+		{name: "default", wantFiles: []string{"a/b/c/deeper.md", "a/b/deep.md", "top.md"}},
+		{name: "unlimited", maxDepth: -1, wantFiles: []string{"a/b/c/deeper.md", "a/b/deep.md", "top.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, failed := walkFiles(t, root, WalkOptions{MaxDepth: tt.maxDepth})
+			if !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("Expected files %v, got %v", tt.wantFiles, files)
+			}
+			if !reflect.DeepEqual(failed, tt.wantFailed) {
+				t.Errorf("Expected depth errors for %v, got %v", tt.wantFailed, failed)
+			}
+		})
+	}
+}
+
+func TestWalk_MaxDepthError(t *testing.T) {
+	root := t.TempDir()
+	writeWalkFile(t, filepath.Join(root, "a", "b", "deep.md"))
+
+	err := Walk(root, WalkOptions{MaxDepth: 1}, func(path string, info os.FileInfo, err error) error {
+		return err
+	})
+	if !errors.Is(err, ErrMaxDepth) {
+		t.Errorf("Expected ErrMaxDepth to stop the walk, got %v", err)
+	}
+}
+
+func TestWalk_SymlinkedRootAndMissingRoot(t *testing.T) {
+	target := t.TempDir()
+	writeWalkFile(t, filepath.Join(target, "reviewer.md"))
+	link := filepath.Join(t.TempDir(), "agents")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if files, _ := walkFiles(t, link, WalkOptions{}); !reflect.DeepEqual(files, []string{"reviewer.md"}) {
+		t.Errorf("Expected a symlinked root to be followed, got %v", files)
+	}
+
+	err := Walk(filepath.Join(target, "missing"), WalkOptions{}, func(path string, info os.FileInfo, err error) error {
+		return err
+	})
+	if !os.IsNotExist(err) {
+		t.Errorf("Expected the missing root reported to the walk function, got %v", err)
+	}
+}
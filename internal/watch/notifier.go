@@ -0,0 +1,118 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Notifier reports the file trees that changed using file system
+// notifications. Every directory below a root is watched, directories
+// created later are added as they appear, and version control directories
+// are skipped.
+type Notifier struct {
+	watcher *fsnotify.Watcher
+	roots   map[string]string // root of each watched source
+}
+
+// NewNotifier watches the given source roots. Roots that cannot be watched,
+// such as missing ones, are returned so they can be polled instead.
+func NewNotifier(roots map[string]string) (*Notifier, map[string]string, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	n := &Notifier{
+		watcher: watcher,
+		roots:   make(map[string]string, len(roots)),
+	}
+	unwatched := make(map[string]string)
+	for source, root := range roots {
+		if err := n.addTree(root); err != nil {
+			unwatched[source] = root
+			continue
+		}
+		n.roots[source] = filepath.Clean(root)
+	}
+	return n, unwatched, nil
+}
+
+// addTree watches root and every directory below it
+func (n *Notifier) addTree(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && vcsDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		return n.watcher.Add(path)
+	})
+}
+
+// source returns the watched source whose tree contains path, preferring
+// the deepest root when roots are nested
+func (n *Notifier) source(path string) string {
+	var match, matchRoot string
+	for source, root := range n.roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		for _, part := range strings.Split(rel, string(filepath.Separator)) {
+			if vcsDirs[part] {
+				return ""
+			}
+		}
+		if len(root) > len(matchRoot) {
+			match, matchRoot = source, root
+		}
+	}
+	return match
+}
+
+// Run passes each changed source to notify until ctx is done. Permission
+// changes alone are not changes.
+func (n *Notifier) Run(ctx context.Context, notify func(string)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-n.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			source := n.source(event.Name)
+			if source == "" {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					_ = n.addTree(event.Name)
+				}
+			}
+			notify(source)
+		case _, ok := <-n.watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// Close stops watching
+func (n *Notifier) Close() error {
+	return n.watcher.Close()
+}
@@ -0,0 +1,75 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNotifierReportsChangedTrees(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "agents")
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+
+	n, unwatched, err := NewNotifier(map[string]string{"local": root, "gone": missing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = n.Close() }()
+	if len(unwatched) != 1 || unwatched["gone"] != missing {
+		t.Fatalf("Expected the missing root to be left for polling, got %v", unwatched)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan string, 16)
+	go n.Run(ctx, func(source string) { changed <- source })
+
+	expect := func(what string) {
+		t.Helper()
+		select {
+		case source := <-changed:
+			if source != "local" {
+				t.Fatalf("Expected %s to notify local, got %s", what, source)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected %s to notify local", what)
+		}
+	}
+	drain := func() {
+		for {
+			select {
+			case <-changed:
+			case <-time.After(200 * time.Millisecond):
+				return
+			}
+		}
+	}
+
+	// Changes inside version control directories are ignored
+	if err := os.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte("ref"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case source := <-changed:
+		t.Fatalf("Expected a change inside .git to be ignored, got %s", source)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Directories created after the start are watched too
+	nested := filepath.Join(root, "nested")
+	if err := os.Mkdir(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	expect("creating a directory")
+	drain()
+	if err := os.WriteFile(filepath.Join(nested, "agent.md"), []byte("v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expect("adding a nested file")
+}
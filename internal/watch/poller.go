@@ -0,0 +1,97 @@
+package watch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// vcsDirs are version control directories, which hold no agents and churn
+// on every fetch or commit
+var vcsDirs = map[string]bool{".git": true, ".hg": true, ".svn": true}
+
+// Fingerprint summarizes a file tree by the path, size, mode and modification
+// time of every entry, so any edit, addition or removal below root changes it.
+// Version control directories are skipped.
+func Fingerprint(root string) (string, error) {
+	hash := sha256.New()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != root && vcsDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00%o\x00%d\n", filepath.ToSlash(rel), info.Size(), info.Mode(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint %s: %w", root, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Poller reports the file trees whose fingerprint changed between polls. A
+// tree that cannot be read counts as empty, so removing and restoring it are
+// both changes.
+type Poller struct {
+	roots  map[string]string // root of each watched source
+	prints map[string]string // fingerprint of each root at the last poll
+}
+
+// NewPoller creates a poller for the given source roots, taking their
+// current fingerprints as the baseline
+func NewPoller(roots map[string]string) *Poller {
+	p := &Poller{
+		roots:  roots,
+		prints: make(map[string]string, len(roots)),
+	}
+	for source, root := range roots {
+		p.prints[source], _ = Fingerprint(root)
+	}
+	return p
+}
+
+// Poll returns the sources whose tree changed since the last poll, ordered by name
+func (p *Poller) Poll() []string {
+	var changed []string
+	for source, root := range p.roots {
+		fingerprint, _ := Fingerprint(root)
+		if fingerprint != p.prints[source] {
+			p.prints[source] = fingerprint
+			changed = append(changed, source)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Run polls every interval until ctx is done, passing each changed source to notify
+func (p *Poller) Run(ctx context.Context, interval time.Duration, notify func(string)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, source := range p.Poll() {
+			notify(source)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPollerReportsChangedTrees(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a")
+	b := filepath.Join(dir, "b")
+	for _, root := range []string{a, b} {
+		if err := os.MkdirAll(filepath.Join(root, "nested"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, "nested", "agent.md"), []byte("v1"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := NewPoller(map[string]string{"a": a, "b": b})
+	if changed := p.Poll(); len(changed) != 0 {
+		t.Fatalf("Expected no changes before any edit, got %v", changed)
+	}
+
+	// A nested edit changes the tree even though the root's mtime does not
+	file := filepath.Join(a, "nested", "agent.md")
+	if err := os.WriteFile(file, []byte("v2 longer"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed := p.Poll(); !reflect.DeepEqual(changed, []string{"a"}) {
+		t.Fatalf("Expected a nested edit to change a, got %v", changed)
+	}
+	if changed := p.Poll(); len(changed) != 0 {
+		t.Fatalf("Expected a change to be reported once, got %v", changed)
+	}
+
+	// Touching a file without changing its size is still a change
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(file, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(b, "new.md"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed := p.Poll(); !reflect.DeepEqual(changed, []string{"a", "b"}) {
+		t.Fatalf("Expected a touch and an addition to change a and b, got %v", changed)
+	}
+
+	// Removing and restoring a tree are both changes
+	if err := os.RemoveAll(b); err != nil {
+		t.Fatal(err)
+	}
+	if changed := p.Poll(); !reflect.DeepEqual(changed, []string{"b"}) {
+		t.Fatalf("Expected removing b to change it, got %v", changed)
+	}
+	if err := os.MkdirAll(b, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if changed := p.Poll(); !reflect.DeepEqual(changed, []string{"b"}) {
+		t.Fatalf("Expected restoring b to change it, got %v", changed)
+	}
+}
+
+func TestFingerprintSkipsVCSDirectories(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".git", "objects"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	before, err := Fingerprint(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".git", "objects", "pack"), []byte("fetched"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	after, err := Fingerprint(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before != after {
+		t.Error("Expected a change inside .git not to change the fingerprint")
+	}
+}
+
+func TestFingerprintMissingRoot(t *testing.T) {
+	if _, err := Fingerprint(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("Expected an error for a missing root")
+	}
+}
@@ -0,0 +1,152 @@
+package watch
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Batch is one reinstall of a source, covering every change coalesced into it
+type Batch struct {
+	Source  string
+	Changes int
+	// First is when the first of the coalesced changes arrived
+	First time.Time
+}
+
+// Scheduler turns bursts of change events into paced reinstalls. Changes to
+// a source are coalesced into one batch that is due once the source has been
+// quiet for the debounce period, and never sooner than the minimum interval
+// after the previous reinstall of that source finished. Notify may be called
+// from any goroutine.
+type Scheduler struct {
+	debounce    time.Duration
+	minInterval time.Duration
+	now         func() time.Time
+	wake        chan struct{}
+
+	mu      sync.Mutex
+	pending map[string]*Batch
+	changed map[string]time.Time // latest change of each pending source
+	lastRun map[string]time.Time // when the last reinstall of each source finished
+}
+
+// NewScheduler creates a scheduler with the given quiet period and minimum
+// interval between reinstalls of a source
+func NewScheduler(debounce, minInterval time.Duration) *Scheduler {
+	return &Scheduler{
+		debounce:    debounce,
+		minInterval: minInterval,
+		now:         time.Now,
+		wake:        make(chan struct{}, 1),
+		pending:     make(map[string]*Batch),
+		changed:     make(map[string]time.Time),
+		lastRun:     make(map[string]time.Time),
+	}
+}
+
+// Notify records a change to source, postponing its reinstall until the
+// source has been quiet for the debounce period
+func (s *Scheduler) Notify(source string) {
+	s.mu.Lock()
+	now := s.now()
+	batch := s.pending[source]
+	if batch == nil {
+		batch = &Batch{Source: source, First: now}
+		s.pending[source] = batch
+	}
+	batch.Changes++
+	s.changed[source] = now
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Due removes and returns the batches that are due, ordered by source, and
+// how long until the next pending batch is due; the wait is zero when
+// nothing else is pending
+func (s *Scheduler) Due() ([]Batch, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var due []Batch
+	var wait time.Duration
+	for source, batch := range s.pending {
+		remaining := s.dueAt(source).Sub(now)
+		if remaining <= 0 {
+			due = append(due, *batch)
+			delete(s.pending, source)
+			delete(s.changed, source)
+			continue
+		}
+		if wait == 0 || remaining < wait {
+			wait = remaining
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].Source < due[j].Source })
+	return due, wait
+}
+
+// Done records that a reinstall of source finished, starting its minimum interval
+func (s *Scheduler) Done(source string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastRun[source] = s.now()
+}
+
+// Pending returns the number of sources waiting to be reinstalled
+func (s *Scheduler) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+// dueAt returns when the pending batch of source is due
+func (s *Scheduler) dueAt(source string) time.Time {
+	at := s.changed[source].Add(s.debounce)
+	if last, ok := s.lastRun[source]; ok {
+		if next := last.Add(s.minInterval); next.After(at) {
+			at = next
+		}
+	}
+	return at
+}
+
+// Run passes due batches to reinstall until ctx is done. Reinstalls run one
+// at a time, so changes made while a source is reinstalled are coalesced
+// into its next batch.
+func (s *Scheduler) Run(ctx context.Context, reinstall func(context.Context, Batch)) {
+	timer := time.NewTimer(0)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		due, wait := s.Due()
+		for _, batch := range due {
+			if ctx.Err() != nil {
+				return
+			}
+			reinstall(ctx, batch)
+			s.Done(batch.Source)
+		}
+		if len(due) > 0 {
+			continue
+		}
+
+		if wait > 0 {
+			timer.Reset(wait)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
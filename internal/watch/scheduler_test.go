@@ -0,0 +1,106 @@
+package watch
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable clock for schedulers under test
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// newTestScheduler creates a scheduler driven by a fake clock
+func newTestScheduler(debounce, minInterval time.Duration) (*Scheduler, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := NewScheduler(debounce, minInterval)
+	s.now = clock.Now
+	return s, clock
+}
+
+func TestSchedulerDebouncesAndCoalesces(t *testing.T) {
+	s, clock := newTestScheduler(500*time.Millisecond, 0)
+
+	// An editor save storm: ten events 100ms apart
+	for n := 0; n < 10; n++ {
+		s.Notify("local")
+		clock.Advance(100 * time.Millisecond)
+	}
+	if due, wait := s.Due(); len(due) != 0 || wait != 400*time.Millisecond {
+		t.Fatalf("Expected nothing due until the source is quiet, got %v, wait %v", due, wait)
+	}
+
+	clock.Advance(400 * time.Millisecond)
+	due, wait := s.Due()
+	if len(due) != 1 || due[0].Source != "local" || due[0].Changes != 10 || wait != 0 {
+		t.Fatalf("Expected one batch coalescing 10 changes, got %+v, wait %v", due, wait)
+	}
+	if s.Pending() != 0 {
+		t.Error("Expected the batch to be removed once due")
+	}
+}
+
+func TestSchedulerMinInterval(t *testing.T) {
+	s, clock := newTestScheduler(time.Second, 10*time.Second)
+
+	s.Notify("local")
+	clock.Advance(time.Second)
+	if due, _ := s.Due(); len(due) != 1 {
+		t.Fatalf("Expected the first change to be due after the quiet period, got %v", due)
+	}
+	s.Done("local")
+
+	// A change right after the reinstall waits out the minimum interval
+	clock.Advance(2 * time.Second)
+	s.Notify("local")
+	s.Notify("other")
+	clock.Advance(time.Second)
+	due, wait := s.Due()
+	if len(due) != 1 || due[0].Source != "other" || wait != 7*time.Second {
+		t.Fatalf("Expected only the other source to be due, got %+v, wait %v", due, wait)
+	}
+
+	clock.Advance(7 * time.Second)
+	if due, _ := s.Due(); len(due) != 1 || due[0].Source != "local" || due[0].Changes != 1 {
+		t.Fatalf("Expected the source to be due after the minimum interval, got %+v", due)
+	}
+}
+
+func TestSchedulerRun(t *testing.T) {
+	s := NewScheduler(20*time.Millisecond, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var batches []Batch
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx, func(ctx context.Context, batch Batch) {
+			mu.Lock()
+			batches = append(batches, batch)
+			mu.Unlock()
+			cancel()
+		})
+		close(done)
+	}()
+
+	for n := 0; n < 5; n++ {
+		s.Notify("local")
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Run to reinstall the source")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 1 || batches[0].Changes != 5 {
+		t.Errorf("Expected one reinstall for 5 changes, got %+v", batches)
+	}
+}
@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -433,7 +434,7 @@ func installAgents(t *testing.T, cfg *config.Config, testDir string) {
 	inst := installer.New(cfg, track, resolver, installer.Options{})
 
 	// Install the test source
-	err = inst.InstallSource(cfg.Sources[0])
+	err = inst.InstallSource(context.Background(), cfg.Sources[0])
 	require.NoError(t, err)
 }
 